@@ -0,0 +1,115 @@
+package core
+
+import (
+	"fmt"
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"io"
+)
+
+// core/KeywordTokenizer.java
+
+/* Default read buffer size */
+const DEFAULT_BUFFER_SIZE = 256
+
+/* Emits the entire input as a single token. */
+type KeywordTokenizer struct {
+	*Tokenizer
+	done        bool
+	finalOffset int
+	termAtt     CharTermAttribute
+	offsetAtt   OffsetAttribute
+}
+
+func NewKeywordTokenizer(input io.RuneReader) *KeywordTokenizer {
+	return NewKeywordTokenizerWithSize(input, DEFAULT_BUFFER_SIZE)
+}
+
+func NewKeywordTokenizerWithSize(input io.RuneReader, bufferSize int) *KeywordTokenizer {
+	assert2(bufferSize > 0, "bufferSize must be > 0")
+	ans := &KeywordTokenizer{Tokenizer: NewTokenizer(input)}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	ans.offsetAtt = ans.Attributes().Add("OffsetAttribute").(OffsetAttribute)
+	ans.termAtt.ResizeBuffer(bufferSize)
+	return ans
+}
+
+func (t *KeywordTokenizer) IncrementToken() (bool, error) {
+	if t.done {
+		return false, nil
+	}
+	t.Attributes().Clear()
+	t.done = true
+	upto := 0
+	buffer := t.termAtt.Buffer()
+	for {
+		c, _, err := t.Input.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return false, err
+		}
+		if upto == len(buffer) {
+			buffer = t.termAtt.ResizeBuffer(upto + 1)
+		}
+		buffer[upto] = c
+		upto++
+	}
+	t.termAtt.SetLength(upto)
+	t.finalOffset = t.CorrectOffset(upto)
+	t.offsetAtt.SetOffset(t.CorrectOffset(0), t.finalOffset)
+	return true, nil
+}
+
+func (t *KeywordTokenizer) End() error {
+	if err := t.Tokenizer.End(); err != nil {
+		return err
+	}
+	// set final offset
+	t.offsetAtt.SetOffset(t.finalOffset, t.finalOffset)
+	return nil
+}
+
+func (t *KeywordTokenizer) Reset() error {
+	if err := t.Tokenizer.Reset(); err != nil {
+		return err
+	}
+	t.done = false
+	return nil
+}
+
+// core/KeywordTokenizerFactory.java
+
+func init() {
+	RegisterTokenizerFactory("keyword", func(args map[string]string) (TokenizerFactory, error) {
+		return NewKeywordTokenizerFactory(args)
+	})
+}
+
+/* Factory for KeywordTokenizer. */
+type KeywordTokenizerFactory struct {
+	*AbstractAnalysisFactory
+}
+
+/* Creates a new KeywordTokenizerFactory. */
+func NewKeywordTokenizerFactory(args map[string]string) (*KeywordTokenizerFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &KeywordTokenizerFactory{base}, nil
+}
+
+func (f *KeywordTokenizerFactory) Create(input io.RuneReader) TokenizerService {
+	return NewKeywordTokenizer(input)
+}
+
+func assert2(ok bool, msg string, args ...interface{}) {
+	if !ok {
+		panic(fmt.Sprintf(msg, args...))
+	}
+}
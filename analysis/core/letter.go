@@ -0,0 +1,64 @@
+package core
+
+import (
+	. "github.com/gzg1984/golucene/analysis/util"
+	"github.com/gzg1984/golucene/core/util"
+	"io"
+	"unicode"
+)
+
+// core/LetterTokenizer.java
+
+/*
+A LetterTokenizer is a tokenizer that divides text at non-letters.
+That's to say, it defines tokens as maximal strings of adjacent
+letters, as defined by unicode.IsLetter() predicate.
+
+Note: this does a decent job for most European languages, but does a
+terrible job for some Asian languages, where words are not separated
+by spaces.
+*/
+type LetterTokenizer struct {
+	*CharTokenizer
+}
+
+/* Construct a new LetterTokenizer. */
+func NewLetterTokenizer(matchVersion util.Version, in io.RuneReader) *LetterTokenizer {
+	ans := new(LetterTokenizer)
+	ans.CharTokenizer = NewCharTokenizer(ans, matchVersion, in)
+	return ans
+}
+
+/* Collects only characters which satisfy unicode.IsLetter(). */
+func (t *LetterTokenizer) IsTokenChar(c rune) bool {
+	return unicode.IsLetter(c)
+}
+
+// core/LetterTokenizerFactory.java
+
+func init() {
+	RegisterTokenizerFactory("letter", func(args map[string]string) (TokenizerFactory, error) {
+		return NewLetterTokenizerFactory(args)
+	})
+}
+
+/* Factory for LetterTokenizer. */
+type LetterTokenizerFactory struct {
+	*AbstractAnalysisFactory
+}
+
+/* Creates a new LetterTokenizerFactory. */
+func NewLetterTokenizerFactory(args map[string]string) (*LetterTokenizerFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &LetterTokenizerFactory{base}, nil
+}
+
+func (f *LetterTokenizerFactory) Create(input io.RuneReader) TokenizerService {
+	return NewLetterTokenizer(f.LuceneMatchVersion, input)
+}
@@ -46,3 +46,32 @@ func (f *LowerCaseFilter) IncrementToken() (bool, error) {
 	}
 	return false, nil
 }
+
+// core/LowerCaseFilterFactory.java
+
+func init() {
+	RegisterTokenFilterFactory("lowercase", func(args map[string]string) (TokenFilterFactory, error) {
+		return NewLowerCaseFilterFactory(args)
+	})
+}
+
+/* Factory for LowerCaseFilter. */
+type LowerCaseFilterFactory struct {
+	*AbstractAnalysisFactory
+}
+
+/* Creates a new LowerCaseFilterFactory. */
+func NewLowerCaseFilterFactory(args map[string]string) (*LowerCaseFilterFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &LowerCaseFilterFactory{base}, nil
+}
+
+func (f *LowerCaseFilterFactory) Create(input TokenStream) TokenStream {
+	return NewLowerCaseFilter(f.LuceneMatchVersion, input)
+}
@@ -53,3 +53,40 @@ func (f *StopFilter) Accept() bool {
 	_, ok := f.stopWords[term]
 	return !ok
 }
+
+// core/StopFilterFactory.java
+
+func init() {
+	RegisterTokenFilterFactory("stop", func(args map[string]string) (TokenFilterFactory, error) {
+		return NewStopFilterFactory(args)
+	})
+}
+
+/*
+Factory for StopFilter. The stop words are given by the "words" arg
+as a comma separated list, and default to ENGLISH_STOP_WORDS_SET.
+*/
+type StopFilterFactory struct {
+	*AbstractAnalysisFactory
+	stopWords map[string]bool
+}
+
+/* Creates a new StopFilterFactory. */
+func NewStopFilterFactory(args map[string]string) (*StopFilterFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	stopWords := base.GetSet("words")
+	if stopWords == nil {
+		stopWords = ENGLISH_STOP_WORDS_SET
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &StopFilterFactory{base, stopWords}, nil
+}
+
+func (f *StopFilterFactory) Create(input TokenStream) TokenStream {
+	return NewStopFilter(f.LuceneMatchVersion, input, f.stopWords)
+}
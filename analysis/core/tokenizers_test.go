@@ -0,0 +1,32 @@
+package core
+
+import (
+	. "github.com/gzg1984/golucene/core/analysis"
+	"github.com/gzg1984/golucene/core/util"
+	. "github.com/gzg1984/golucene/test_framework/analysis"
+	"strings"
+	"testing"
+)
+
+func TestWhitespaceTokenizer(t *testing.T) {
+	var ts TokenStream = NewWhitespaceTokenizer(util.VERSION_LATEST, strings.NewReader("What's  thé\tmatter-with U.S.?"))
+	AssertTokenStreamContents(t, ts, []string{"What's", "thé", "matter-with", "U.S.?"},
+		[]int{0, 8, 12, 24}, []int{6, 11, 23, 29}, nil, []int{1, 1, 1, 1})
+
+	ts = NewWhitespaceTokenizer(util.VERSION_LATEST, strings.NewReader(" \t "))
+	AssertTokenStreamContents(t, ts, nil, nil, nil, nil, nil)
+}
+
+func TestLetterTokenizer(t *testing.T) {
+	var ts TokenStream = NewLetterTokenizer(util.VERSION_LATEST, strings.NewReader("I'm 2 häppy, Sam"))
+	AssertTokenStreamContents(t, ts, []string{"I", "m", "häppy", "Sam"},
+		[]int{0, 2, 6, 13}, []int{1, 3, 11, 16}, nil, nil)
+}
+
+func TestKeywordTokenizer(t *testing.T) {
+	var ts TokenStream = NewKeywordTokenizerWithSize(strings.NewReader(" New York, NY "), 2)
+	AssertTokenStreamContents(t, ts, []string{" New York, NY "}, []int{0}, []int{14}, nil, []int{1})
+
+	ts = NewKeywordTokenizer(strings.NewReader(""))
+	AssertTokenStreamContents(t, ts, []string{""}, []int{0}, []int{0}, nil, nil)
+}
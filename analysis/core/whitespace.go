@@ -0,0 +1,56 @@
+package core
+
+import (
+	. "github.com/gzg1984/golucene/analysis/util"
+	"github.com/gzg1984/golucene/core/util"
+	"io"
+	"unicode"
+)
+
+// core/WhitespaceTokenizer.java
+
+/* A tokenizer that divides text at whitespace characters. */
+type WhitespaceTokenizer struct {
+	*CharTokenizer
+}
+
+/* Constructs a new WhitespaceTokenizer. */
+func NewWhitespaceTokenizer(matchVersion util.Version, in io.RuneReader) *WhitespaceTokenizer {
+	ans := new(WhitespaceTokenizer)
+	ans.CharTokenizer = NewCharTokenizer(ans, matchVersion, in)
+	return ans
+}
+
+/* Collects only characters which do not satisfy unicode.IsSpace(). */
+func (t *WhitespaceTokenizer) IsTokenChar(c rune) bool {
+	return !unicode.IsSpace(c)
+}
+
+// core/WhitespaceTokenizerFactory.java
+
+func init() {
+	RegisterTokenizerFactory("whitespace", func(args map[string]string) (TokenizerFactory, error) {
+		return NewWhitespaceTokenizerFactory(args)
+	})
+}
+
+/* Factory for WhitespaceTokenizer. */
+type WhitespaceTokenizerFactory struct {
+	*AbstractAnalysisFactory
+}
+
+/* Creates a new WhitespaceTokenizerFactory. */
+func NewWhitespaceTokenizerFactory(args map[string]string) (*WhitespaceTokenizerFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &WhitespaceTokenizerFactory{base}, nil
+}
+
+func (f *WhitespaceTokenizerFactory) Create(input io.RuneReader) TokenizerService {
+	return NewWhitespaceTokenizer(f.LuceneMatchVersion, input)
+}
@@ -0,0 +1,195 @@
+package custom
+
+import (
+	"errors"
+	"fmt"
+	_ "github.com/gzg1984/golucene/analysis/core"
+	_ "github.com/gzg1984/golucene/analysis/en"
+	_ "github.com/gzg1984/golucene/analysis/fr"
+	_ "github.com/gzg1984/golucene/analysis/miscellaneous"
+	_ "github.com/gzg1984/golucene/analysis/shingle"
+	_ "github.com/gzg1984/golucene/analysis/snowball"
+	_ "github.com/gzg1984/golucene/analysis/standard"
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	"io"
+	"sort"
+)
+
+// custom/CustomAnalyzer.java
+
+/*
+An Analyzer that builds its TokenStream by chaining a tokenizer and
+token filters, all created by the factories registered by name. The
+factories of the analysis packages of GoLucene are registered by
+importing this package.
+
+It's built either with a builder:
+
+	analyzer, err := NewCustomAnalyzerBuilder().
+		WithTokenizer("standard", nil).
+		AddTokenFilter("lowercase", nil).
+		AddTokenFilter("length", map[string]string{"min": "2", "max": "20"}).
+		Build()
+
+or from a map, e.g. decoded from JSON:
+
+	analyzer, err := NewCustomAnalyzerFromMap(map[string]interface{}{
+		"tokenizer": "standard",
+		"filters":   []interface{}{"lowercase", "asciifolding"},
+	})
+*/
+type CustomAnalyzer struct {
+	*AnalyzerImpl
+	tokenizer    TokenizerFactory
+	tokenFilters []TokenFilterFactory
+}
+
+func newCustomAnalyzer(tokenizer TokenizerFactory, tokenFilters []TokenFilterFactory) *CustomAnalyzer {
+	ans := &CustomAnalyzer{
+		AnalyzerImpl: NewAnalyzer(),
+		tokenizer:    tokenizer,
+		tokenFilters: tokenFilters,
+	}
+	ans.Spi = ans
+	return ans
+}
+
+/* Returns the tokenizer that is used in this analyzer. */
+func (a *CustomAnalyzer) TokenizerFactory() TokenizerFactory {
+	return a.tokenizer
+}
+
+/* Returns the list of token filters that are used in this analyzer. */
+func (a *CustomAnalyzer) TokenFilterFactories() []TokenFilterFactory {
+	return a.tokenFilters
+}
+
+func (a *CustomAnalyzer) CreateComponents(fieldName string, reader io.RuneReader) *TokenStreamComponents {
+	tk := a.tokenizer.Create(reader)
+	var ts TokenStream = tk
+	for _, filter := range a.tokenFilters {
+		ts = filter.Create(ts)
+	}
+	return NewTokenStreamComponents(tk, ts)
+}
+
+/*
+Builder for CustomAnalyzer. The first error of the factories is
+returned by Build().
+*/
+type CustomAnalyzerBuilder struct {
+	tokenizer    TokenizerFactory
+	tokenFilters []TokenFilterFactory
+	err          error
+}
+
+/* Returns a builder for custom analyzers. */
+func NewCustomAnalyzerBuilder() *CustomAnalyzerBuilder {
+	return new(CustomAnalyzerBuilder)
+}
+
+/* Uses the given tokenizer, looked up by name, with the given args. */
+func (b *CustomAnalyzerBuilder) WithTokenizer(name string, args map[string]string) *CustomAnalyzerBuilder {
+	if b.err == nil {
+		b.tokenizer, b.err = TokenizerFactoryForName(name, args)
+	}
+	return b
+}
+
+/* Adds the given token filter, looked up by name, with the given args. */
+func (b *CustomAnalyzerBuilder) AddTokenFilter(name string, args map[string]string) *CustomAnalyzerBuilder {
+	if b.err == nil {
+		var filter TokenFilterFactory
+		if filter, b.err = TokenFilterFactoryForName(name, args); b.err == nil {
+			b.tokenFilters = append(b.tokenFilters, filter)
+		}
+	}
+	return b
+}
+
+/* Builds the analyzer. */
+func (b *CustomAnalyzerBuilder) Build() (*CustomAnalyzer, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.tokenizer == nil {
+		return nil, errors.New("You have to set a tokenizer.")
+	}
+	return newCustomAnalyzer(b.tokenizer, b.tokenFilters), nil
+}
+
+/*
+Builds an analyzer from a map, such as decoded from JSON. The
+"tokenizer" key is required, and "filters" is an optional list. Each
+component is either given by its name, or by a map whose "type" is
+the name, and whose other entries are the args of the factory, e.g.
+
+	{"tokenizer": "whitespace",
+	 "filters": ["lowercase", {"type": "length", "min": 2, "max": 20}]}
+*/
+func NewCustomAnalyzerFromMap(config map[string]interface{}) (*CustomAnalyzer, error) {
+	for key := range config {
+		if key != "tokenizer" && key != "filters" {
+			return nil, errors.New(fmt.Sprintf("Unknown analyzer key: %v", key))
+		}
+	}
+	b := NewCustomAnalyzerBuilder()
+	name, args, err := component(config["tokenizer"])
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid tokenizer: %v", err))
+	}
+	b.WithTokenizer(name, args)
+
+	var filters []interface{}
+	switch v := config["filters"].(type) {
+	case nil:
+	case []interface{}:
+		filters = v
+	case []string:
+		for _, name := range v {
+			filters = append(filters, name)
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("Invalid filters: expect a list, got %v", v))
+	}
+	for _, filter := range filters {
+		name, args, err := component(filter)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid filter: %v", err))
+		}
+		b.AddTokenFilter(name, args)
+	}
+	return b.Build()
+}
+
+/* Returns the name and the args of a component of an analyzer map. */
+func component(v interface{}) (name string, args map[string]string, err error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil, nil
+	case map[string]interface{}:
+		args = make(map[string]string)
+		for k, arg := range v {
+			args[k] = fmt.Sprint(arg)
+		}
+	case map[string]string:
+		args = make(map[string]string)
+		for k, arg := range v {
+			args[k] = arg
+		}
+	default:
+		return "", nil, errors.New(fmt.Sprintf("expect a name or a map, got %v", v))
+	}
+	name, ok := args["type"]
+	if !ok {
+		keys := make([]string, 0, len(args))
+		for k := range args {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return "", nil, errors.New(fmt.Sprintf("missing type in %v", keys))
+	}
+	delete(args, "type")
+	return name, args, nil
+}
@@ -0,0 +1,83 @@
+package custom
+
+import (
+	"encoding/json"
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/test_framework/analysis"
+	. "github.com/gzg1984/gounit"
+	"strings"
+	"testing"
+)
+
+func TestCustomAnalyzerFromMap(t *testing.T) {
+	var config map[string]interface{}
+	err := json.Unmarshal([]byte(`{"tokenizer":"standard","filters":["lowercase","asciifolding"]}`), &config)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	a, err := NewCustomAnalyzerFromMap(config)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	AssertAnalyzesTo(t, a, "Crème Brûlée for Zoë", []string{"creme", "brulee", "for", "zoe"})
+	// the components are reused
+	AssertAnalyzesTo(t, a, "Über", []string{"uber"})
+
+	err = json.Unmarshal([]byte(`{"tokenizer": {"type": "whitespace"}, "filters": [
+		{"type": "length", "min": 2, "max": 5},
+		{"type": "Shingle", "outputUnigrams": false, "tokenSeparator": "+"}]}`), &config)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	a, err = NewCustomAnalyzerFromMap(config)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	AssertAnalyzesTo(t, a, "a bb ccc dddddd ee", []string{"_+bb", "bb+ccc", "ccc+_", "_+ee"})
+
+	a, err = NewCustomAnalyzerFromMap(map[string]interface{}{
+		"tokenizer": "whitespace",
+		"filters":   []string{"stop", "keywordmarker", "truncate"},
+	})
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	AssertAnalyzesTo(t, a, "the elephants were dancing", []string{"eleph", "were", "danci"})
+}
+
+func TestCustomAnalyzerBuilder(t *testing.T) {
+	a, err := NewCustomAnalyzerBuilder().
+		WithTokenizer("keyword", nil).
+		AddTokenFilter("trim", nil).
+		AddTokenFilter("lowercase", nil).
+		AddTokenFilter("keywordmarker", map[string]string{"pattern": "new .*"}).
+		AddTokenFilter("truncate", map[string]string{"prefixLength": "3"}).
+		Build()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	AssertAnalyzesTo(t, a, "  New York ", []string{"new york"})
+	AssertAnalyzesTo(t, a, " Boston", []string{"bos"})
+
+	It(t).Should("expect the standard components to be registered, got %v", AvailableTokenizers()).Verify(
+		strings.Join(AvailableTokenizers(), ",") == "keyword,letter,standard,whitespace")
+	It(t).Should("expect the standard components to be registered, got %v", AvailableTokenFilters()).Verify(
+		strings.Join(AvailableTokenFilters(), ",") ==
+			"asciifolding,elision,englishpossessive,keywordmarker,length,lowercase,porterstem,"+
+				"shingle,snowballporter,standard,stop,trim,truncate")
+}
+
+func TestCustomAnalyzerErrors(t *testing.T) {
+	for _, c := range []struct {
+		config   map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"filters": []string{"lowercase"}}, "Invalid tokenizer"},
+		{map[string]interface{}{"tokenizer": "nope"}, "does not exist"},
+		{map[string]interface{}{"tokenizer": "standard", "filters": []string{"nope"}}, "does not exist"},
+		{map[string]interface{}{"tokenizer": "standard", "filter": []string{"lowercase"}}, "Unknown analyzer key"},
+		{map[string]interface{}{"tokenizer": "standard", "filters": "lowercase"}, "expect a list"},
+		{map[string]interface{}{"tokenizer": map[string]interface{}{"maxTokenLength": 5}}, "missing type"},
+		{map[string]interface{}{"tokenizer": map[string]interface{}{"type": "whitespace", "foo": 1}}, "Unknown parameters: [foo]"},
+		{map[string]interface{}{"tokenizer": "whitespace", "filters": []interface{}{
+			map[string]interface{}{"type": "length", "min": 2}}}, "missing parameter 'max'"},
+		{map[string]interface{}{"tokenizer": "whitespace", "filters": []interface{}{
+			map[string]interface{}{"type": "length", "min": 3, "max": 2}}}, "invalid length range"},
+		{map[string]interface{}{"tokenizer": "whitespace", "filters": []interface{}{
+			map[string]interface{}{"type": "truncate", "prefixLength": "x"}}}, "must be an integer"},
+		{map[string]interface{}{"tokenizer": "whitespace", "filters": []interface{}{
+			map[string]interface{}{"type": "shingle", "minShingleSize": 3}}}, "Invalid minShingleSize"},
+	} {
+		a, err := NewCustomAnalyzerFromMap(c.config)
+		It(t).Should("expect an error for %v", c.config).Assert(err != nil && a == nil)
+		It(t).Should("expect %q in error, got %v", c.expected, err).Verify(strings.Contains(err.Error(), c.expected))
+	}
+}
@@ -0,0 +1,100 @@
+package de
+
+import (
+	. "github.com/gzg1984/golucene/analysis/core"
+	"github.com/gzg1984/golucene/analysis/miscellaneous"
+	"github.com/gzg1984/golucene/analysis/snowball"
+	"github.com/gzg1984/golucene/analysis/standard"
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	"io"
+)
+
+// de/GermanAnalyzer.java
+
+var defaultStopSet = SnowballWordSet(`
+ | A German stop word list, from http://snowball.tartarus.org
+aber alle allem allen aller alles als also am an ander andere anderem anderen
+anderer anderes anderm andern anderr anders auch auf aus bei bin bis bist da
+damit dann der den des dem die das daß derselbe derselben denselben desselben
+demselben dieselbe dieselben dasselbe dazu dein deine deinem deinen deiner
+deines denn derer dessen dich dir du dies diese diesem diesen dieser dieses
+doch dort durch ein eine einem einen einer eines einig einige einigem einigen
+einiger einiges einmal er ihn ihm es etwas euer eure eurem euren eurer eures
+für gegen gewesen hab habe haben hat hatte hatten hier hin hinter ich mich mir
+ihr ihre ihrem ihren ihrer ihres euch im in indem ins ist jede jedem jeden
+jeder jedes jene jenem jenen jener jenes jetzt kann kein keine keinem keinen
+keiner keines können könnte machen man manche manchem manchen mancher manches
+mein meine meinem meinen meiner meines mit muss musste nach nicht nichts noch
+nun nur ob oder ohne sehr sein seine seinem seinen seiner seines selbst sich
+sie ihnen sind so solche solchem solchen solcher solches soll sollte sondern
+sonst über um und uns unse unsem unsen unser unses unter viel vom von vor
+während war waren warst was weg weil weiter welche welchem welchen welcher
+welches wenn werde werden wie wieder will wir wird wirst wo wollen wollte
+würde würden zu zum zur zwar zwischen
+`)
+
+/* Returns a set of default German-stopwords */
+func DefaultStopSet() map[string]bool {
+	return defaultStopSet
+}
+
+/*
+Analyzer for German language.
+
+Supports an external list of stopwords (words that will not be
+indexed at all) and an external list of exclusions (word that will
+not be stemmed, but indexed). A default set of stopwords is used
+unless an alternative list is specified, but the exclusion list is
+empty by default.
+
+NOTE: This class uses the same Version dependent settings as
+StandardAnalyzer.
+*/
+type GermanAnalyzer struct {
+	*StopwordAnalyzerBase
+	exclusionSet map[string]bool
+}
+
+/* Builds an analyzer with the default stop words: DefaultStopSet(). */
+func NewGermanAnalyzer() *GermanAnalyzer {
+	return NewGermanAnalyzerWithStopWords(DefaultStopSet())
+}
+
+/* Builds an analyzer with the given stop words. */
+func NewGermanAnalyzerWithStopWords(stopwords map[string]bool) *GermanAnalyzer {
+	return NewGermanAnalyzerWithStemExclusion(stopwords, nil)
+}
+
+/* Builds an analyzer with the given stop words and stem exclusion words. */
+func NewGermanAnalyzerWithStemExclusion(stopwords, stemExclusionSet map[string]bool) *GermanAnalyzer {
+	ans := &GermanAnalyzer{
+		StopwordAnalyzerBase: NewStopwordAnalyzerBaseWithStopWords(stopwords),
+		exclusionSet:         make(map[string]bool),
+	}
+	for k, v := range stemExclusionSet {
+		ans.exclusionSet[k] = v
+	}
+	ans.Spi = ans
+	return ans
+}
+
+/*
+Creates TokenStreamComponents used to tokenize all the text in the
+provided reader: StandardTokenizer filtered with StandardFilter,
+LowerCaseFilter, StopFilter, SetKeywordMarkerFilter if a stem
+exclusion set is provided, and SnowballFilter with the German
+stemmer.
+*/
+func (a *GermanAnalyzer) CreateComponents(fieldName string, reader io.RuneReader) *TokenStreamComponents {
+	version := a.Version()
+	source := standard.NewStandardTokenizer(version, reader)
+	var result TokenStream = standard.NewStandardFilter(version, source)
+	result = NewLowerCaseFilter(version, result)
+	result = NewStopFilter(version, result, a.StopwordSet())
+	if len(a.exclusionSet) > 0 {
+		result = miscellaneous.NewSetKeywordMarkerFilter(result, a.exclusionSet)
+	}
+	result = snowball.NewSnowballFilter(result, snowball.GermanStemmer{})
+	return NewTokenStreamComponents(source, result)
+}
@@ -0,0 +1,15 @@
+package de
+
+import (
+	. "github.com/gzg1984/golucene/test_framework/analysis"
+	"testing"
+)
+
+func TestGermanAnalyzer(t *testing.T) {
+	a := NewGermanAnalyzer()
+	AssertAnalyzesTo(t, a, "Die Häuser der Kinder sind schön", []string{"haus", "kind", "schon"})
+	AssertAnalyzesTo(t, a, "Straße", []string{"strass"})
+
+	a = NewGermanAnalyzerWithStemExclusion(DefaultStopSet(), map[string]bool{"häuser": true})
+	AssertAnalyzesTo(t, a, "Häuser Kinder", []string{"häuser", "kind"})
+}
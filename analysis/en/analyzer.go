@@ -0,0 +1,71 @@
+package en
+
+import (
+	. "github.com/gzg1984/golucene/analysis/core"
+	"github.com/gzg1984/golucene/analysis/miscellaneous"
+	"github.com/gzg1984/golucene/analysis/standard"
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	"io"
+)
+
+// en/EnglishAnalyzer.java
+
+/* Returns an unmodifiable instance of the default stop words set. */
+func DefaultStopSet() map[string]bool {
+	return standard.STOP_WORDS_SET
+}
+
+/* Analyzer for English. */
+type EnglishAnalyzer struct {
+	*StopwordAnalyzerBase
+	stemExclusionSet map[string]bool
+}
+
+/* Builds an analyzer with the default stop words: DefaultStopSet(). */
+func NewEnglishAnalyzer() *EnglishAnalyzer {
+	return NewEnglishAnalyzerWithStopWords(DefaultStopSet())
+}
+
+/* Builds an analyzer with the given stop words. */
+func NewEnglishAnalyzerWithStopWords(stopwords map[string]bool) *EnglishAnalyzer {
+	return NewEnglishAnalyzerWithStemExclusion(stopwords, nil)
+}
+
+/*
+Builds an analyzer with the given stop words. If a non-empty stem
+exclusion set is provided this analyzer will add a
+SetKeywordMarkerFilter before stemming.
+*/
+func NewEnglishAnalyzerWithStemExclusion(stopwords, stemExclusionSet map[string]bool) *EnglishAnalyzer {
+	ans := &EnglishAnalyzer{
+		StopwordAnalyzerBase: NewStopwordAnalyzerBaseWithStopWords(stopwords),
+		stemExclusionSet:     make(map[string]bool),
+	}
+	for k, v := range stemExclusionSet {
+		ans.stemExclusionSet[k] = v
+	}
+	ans.Spi = ans
+	return ans
+}
+
+/*
+Creates TokenStreamComponents used to tokenize all the text in the
+provided reader: StandardTokenizer filtered with StandardFilter,
+EnglishPossessiveFilter, LowerCaseFilter, StopFilter,
+SetKeywordMarkerFilter if a stem exclusion set is provided and
+PorterStemFilter.
+*/
+func (a *EnglishAnalyzer) CreateComponents(fieldName string, reader io.RuneReader) *TokenStreamComponents {
+	version := a.Version()
+	source := standard.NewStandardTokenizer(version, reader)
+	var result TokenStream = standard.NewStandardFilter(version, source)
+	result = NewEnglishPossessiveFilter(version, result)
+	result = NewLowerCaseFilter(version, result)
+	result = NewStopFilter(version, result, a.StopwordSet())
+	if len(a.stemExclusionSet) > 0 {
+		result = miscellaneous.NewSetKeywordMarkerFilter(result, a.stemExclusionSet)
+	}
+	result = NewPorterStemFilter(result)
+	return NewTokenStreamComponents(source, result)
+}
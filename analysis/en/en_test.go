@@ -0,0 +1,53 @@
+package en
+
+import (
+	"github.com/gzg1984/golucene/analysis/core"
+	"github.com/gzg1984/golucene/analysis/miscellaneous"
+	. "github.com/gzg1984/golucene/core/analysis"
+	"github.com/gzg1984/golucene/core/util"
+	. "github.com/gzg1984/golucene/test_framework/analysis"
+	. "github.com/gzg1984/gounit"
+	"strings"
+	"testing"
+)
+
+func TestPorterStemmer(t *testing.T) {
+	stemmer := NewPorterStemmer()
+	for _, v := range [][2]string{
+		{"caresses", "caress"}, {"ponies", "poni"}, {"ties", "ti"}, {"caress", "caress"}, {"cats", "cat"},
+		{"feed", "feed"}, {"agreed", "agre"}, {"plastered", "plaster"}, {"motoring", "motor"},
+		{"sing", "sing"}, {"conflated", "conflat"}, {"troubled", "troubl"}, {"sized", "size"},
+		{"hopping", "hop"}, {"tanned", "tan"}, {"falling", "fall"}, {"hissing", "hiss"},
+		{"fizzed", "fizz"}, {"failing", "fail"}, {"filing", "file"}, {"happy", "happi"},
+		{"relational", "relat"}, {"generalization", "gener"}, {"running", "run"}, {"runs", "run"},
+		{"as", "as"},
+	} {
+		stem := stemmer.StemString(v[0])
+		It(t).Should("expect %v -> %v, got %v", v[0], v[1], stem).Verify(stem == v[1])
+	}
+}
+
+func whitespace(text string) TokenStream {
+	return core.NewWhitespaceTokenizer(util.VERSION_LATEST, strings.NewReader(text))
+}
+
+func TestPorterStemFilter(t *testing.T) {
+	var ts TokenStream = miscellaneous.NewSetKeywordMarkerFilter(
+		whitespace("running runs jogging"), map[string]bool{"jogging": true})
+	ts = NewPorterStemFilter(ts)
+	AssertTokenStreamContents(t, ts, []string{"run", "run", "jogging"}, []int{0, 8, 13}, []int{7, 12, 20}, nil, nil)
+}
+
+func TestEnglishPossessiveFilter(t *testing.T) {
+	ts := NewEnglishPossessiveFilter(util.VERSION_LATEST, whitespace("dog's dog’s DOG'S dogs' s"))
+	AssertTokenStreamContents(t, ts, []string{"dog", "dog", "DOG", "dogs'", "s"}, nil, nil, nil, nil)
+}
+
+func TestEnglishAnalyzer(t *testing.T) {
+	a := NewEnglishAnalyzer()
+	AssertAnalyzesTo(t, a, "The dog's owners were happily running", []string{"dog", "owner", "were", "happili", "run"})
+	AssertAnalyzesTo(t, a, "Runs", []string{"run"})
+
+	a = NewEnglishAnalyzerWithStemExclusion(DefaultStopSet(), map[string]bool{"running": true})
+	AssertAnalyzesTo(t, a, "running runs", []string{"running", "run"})
+}
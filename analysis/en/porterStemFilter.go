@@ -0,0 +1,89 @@
+package en
+
+import (
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+)
+
+// en/PorterStemFilter.java
+
+/*
+Transforms the token stream as per the Porter stemming algorithm.
+Note: the input to the stemming filter must already be in lower case,
+so you will need to use LowerCaseFilter or LowerCaseTokenizer farther
+down the Tokenizer chain in order for this to work properly!
+
+To use this filter with other analyzers, you'll want to write an
+Analyzer class that sets up the TokenStream chain as you want it. To
+use this with LowerCaseTokenizer, for example, you'd write an analyzer
+like this:
+
+	func (a *MyAnalyzer) CreateComponents(fieldName string, reader io.RuneReader) *TokenStreamComponents {
+		source := NewLowerCaseTokenizer(version, reader)
+		return NewTokenStreamComponents(source, NewPorterStemFilter(source))
+	}
+
+Note: This filter is aware of the KeywordAttribute. To prevent
+certain terms from being passed to the stemmer
+KeywordAttribute.IsKeyword() should be set to true in a previous
+TokenStream.
+*/
+type PorterStemFilter struct {
+	*TokenFilter
+	input      TokenStream
+	stemmer    *PorterStemmer
+	termAtt    CharTermAttribute
+	keywordAtt KeywordAttribute
+}
+
+func NewPorterStemFilter(in TokenStream) *PorterStemFilter {
+	ans := &PorterStemFilter{
+		TokenFilter: NewTokenFilter(in),
+		input:       in,
+		stemmer:     NewPorterStemmer(),
+	}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	ans.keywordAtt = ans.Attributes().Add("KeywordAttribute").(KeywordAttribute)
+	return ans
+}
+
+func (f *PorterStemFilter) IncrementToken() (bool, error) {
+	ok, err := f.input.IncrementToken()
+	if !ok || err != nil {
+		return false, err
+	}
+	if !f.keywordAtt.IsKeyword() && f.stemmer.Stem(f.termAtt.Buffer()[:f.termAtt.Length()]) {
+		f.termAtt.CopyBuffer(f.stemmer.ResultBuffer()[:f.stemmer.ResultLength()])
+	}
+	return true, nil
+}
+
+// en/PorterStemFilterFactory.java
+
+func init() {
+	RegisterTokenFilterFactory("porterstem", func(args map[string]string) (TokenFilterFactory, error) {
+		return NewPorterStemFilterFactory(args)
+	})
+}
+
+/* Factory for PorterStemFilter. */
+type PorterStemFilterFactory struct {
+	*AbstractAnalysisFactory
+}
+
+/* Creates a new PorterStemFilterFactory. */
+func NewPorterStemFilterFactory(args map[string]string) (*PorterStemFilterFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &PorterStemFilterFactory{base}, nil
+}
+
+func (f *PorterStemFilterFactory) Create(input TokenStream) TokenStream {
+	return NewPorterStemFilter(input)
+}
@@ -0,0 +1,74 @@
+package en
+
+import (
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"github.com/gzg1984/golucene/core/util"
+)
+
+// en/EnglishPossessiveFilter.java
+
+/*
+TokenFilter that removes possessives (trailing 's) from words. U+2019
+RIGHT SINGLE QUOTATION MARK and U+FF07 FULLWIDTH APOSTROPHE are also
+treated as quotation marks, as Lucene Java does as of 3.6.
+*/
+type EnglishPossessiveFilter struct {
+	*TokenFilter
+	input   TokenStream
+	termAtt CharTermAttribute
+}
+
+func NewEnglishPossessiveFilter(version util.Version, input TokenStream) *EnglishPossessiveFilter {
+	ans := &EnglishPossessiveFilter{
+		TokenFilter: NewTokenFilter(input),
+		input:       input,
+	}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	return ans
+}
+
+func (f *EnglishPossessiveFilter) IncrementToken() (bool, error) {
+	ok, err := f.input.IncrementToken()
+	if !ok || err != nil {
+		return false, err
+	}
+	buffer := f.termAtt.Buffer()
+	bufferLength := f.termAtt.Length()
+	if bufferLength >= 2 &&
+		(buffer[bufferLength-2] == '\'' || buffer[bufferLength-2] == '’' || buffer[bufferLength-2] == '＇') &&
+		(buffer[bufferLength-1] == 's' || buffer[bufferLength-1] == 'S') {
+		f.termAtt.SetLength(bufferLength - 2) // Strip last 2 characters off
+	}
+	return true, nil
+}
+
+// en/EnglishPossessiveFilterFactory.java
+
+func init() {
+	RegisterTokenFilterFactory("englishpossessive", func(args map[string]string) (TokenFilterFactory, error) {
+		return NewEnglishPossessiveFilterFactory(args)
+	})
+}
+
+/* Factory for EnglishPossessiveFilter. */
+type EnglishPossessiveFilterFactory struct {
+	*AbstractAnalysisFactory
+}
+
+/* Creates a new EnglishPossessiveFilterFactory. */
+func NewEnglishPossessiveFilterFactory(args map[string]string) (*EnglishPossessiveFilterFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &EnglishPossessiveFilterFactory{base}, nil
+}
+
+func (f *EnglishPossessiveFilterFactory) Create(input TokenStream) TokenStream {
+	return NewEnglishPossessiveFilter(f.LuceneMatchVersion, input)
+}
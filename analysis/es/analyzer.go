@@ -0,0 +1,107 @@
+package es
+
+import (
+	. "github.com/gzg1984/golucene/analysis/core"
+	"github.com/gzg1984/golucene/analysis/miscellaneous"
+	"github.com/gzg1984/golucene/analysis/snowball"
+	"github.com/gzg1984/golucene/analysis/standard"
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	"io"
+)
+
+// es/SpanishAnalyzer.java
+
+var defaultStopSet = SnowballWordSet(`
+ | A Spanish stop word list, from http://snowball.tartarus.org
+de la que el en y a los del se las por un para con no una su al lo como más
+pero sus le ya o este sí porque esta entre cuando muy sin sobre también me
+hasta hay donde quien desde todo nos durante todos uno les ni contra otros
+ese eso ante ellos e esto mí antes algunos qué unos yo otro otras otra él
+tanto esa estos mucho quienes nada muchos cual poco ella estar estas algunas
+algo nosotros mi mis tú te ti tu tus ellas nosotras vosotros vosotras os mío
+mía míos mías tuyo tuya tuyos tuyas suyo suya suyos suyas nuestro nuestra
+nuestros nuestras vuestro vuestra vuestros vuestras esos esas
+ | forms of estar, to be (not including the infinitive):
+estoy estás está estamos estáis están esté estés estemos estéis estén estaré
+estarás estará estaremos estaréis estarán estaría estarías estaríamos
+estaríais estarían estaba estabas estábamos estabais estaban estuve estuviste
+estuvo estuvimos estuvisteis estuvieron estuviera estuvieras estuviéramos
+estuvierais estuvieran estuviese estuvieses estuviésemos estuvieseis
+estuviesen estando estado estada estados estadas estad
+ | forms of haber, to have (not including the infinitive):
+he has ha hemos habéis han haya hayas hayamos hayáis hayan habré habrás habrá
+habremos habréis habrán habría habrías habríamos habríais habrían había
+habías habíamos habíais habían hube hubiste hubo hubimos hubisteis hubieron
+hubiera hubieras hubiéramos hubierais hubieran hubiese hubieses hubiésemos
+hubieseis hubiesen habiendo habido habida habidos habidas
+ | forms of ser, to be (not including the infinitive):
+soy eres es somos sois son sea seas seamos seáis sean seré serás será seremos
+seréis serán sería serías seríamos seríais serían era eras éramos erais eran
+fui fuiste fue fuimos fuisteis fueron fuera fueras fuéramos fuerais fueran
+fuese fueses fuésemos fueseis fuesen siendo sido
+ | forms of tener, to have (not including the infinitive):
+tengo tienes tiene tenemos tenéis tienen tenga tengas tengamos tengáis tengan
+tendré tendrás tendrá tendremos tendréis tendrán tendría tendrías tendríamos
+tendríais tendrían tenía tenías teníamos teníais tenían tuve tuviste tuvo
+tuvimos tuvisteis tuvieron tuviera tuvieras tuviéramos tuvierais tuvieran
+tuviese tuvieses tuviésemos tuvieseis tuviesen teniendo tenido tenida tenidos
+tenidas tened
+`)
+
+/* Returns an unmodifiable instance of the default stop words set. */
+func DefaultStopSet() map[string]bool {
+	return defaultStopSet
+}
+
+/* Analyzer for Spanish. */
+type SpanishAnalyzer struct {
+	*StopwordAnalyzerBase
+	stemExclusionSet map[string]bool
+}
+
+/* Builds an analyzer with the default stop words: DefaultStopSet(). */
+func NewSpanishAnalyzer() *SpanishAnalyzer {
+	return NewSpanishAnalyzerWithStopWords(DefaultStopSet())
+}
+
+/* Builds an analyzer with the given stop words. */
+func NewSpanishAnalyzerWithStopWords(stopwords map[string]bool) *SpanishAnalyzer {
+	return NewSpanishAnalyzerWithStemExclusion(stopwords, nil)
+}
+
+/*
+Builds an analyzer with the given stop words. If a non-empty stem
+exclusion set is provided this analyzer will add a
+SetKeywordMarkerFilter before stemming.
+*/
+func NewSpanishAnalyzerWithStemExclusion(stopwords, stemExclusionSet map[string]bool) *SpanishAnalyzer {
+	ans := &SpanishAnalyzer{
+		StopwordAnalyzerBase: NewStopwordAnalyzerBaseWithStopWords(stopwords),
+		stemExclusionSet:     make(map[string]bool),
+	}
+	for k, v := range stemExclusionSet {
+		ans.stemExclusionSet[k] = v
+	}
+	ans.Spi = ans
+	return ans
+}
+
+/*
+Creates TokenStreamComponents used to tokenize all the text in the
+provided reader: StandardTokenizer filtered with StandardFilter,
+LowerCaseFilter, StopFilter, SetKeywordMarkerFilter if a stem
+exclusion set is provided and SnowballFilter with the Spanish stemmer.
+*/
+func (a *SpanishAnalyzer) CreateComponents(fieldName string, reader io.RuneReader) *TokenStreamComponents {
+	version := a.Version()
+	source := standard.NewStandardTokenizer(version, reader)
+	var result TokenStream = standard.NewStandardFilter(version, source)
+	result = NewLowerCaseFilter(version, result)
+	result = NewStopFilter(version, result, a.StopwordSet())
+	if len(a.stemExclusionSet) > 0 {
+		result = miscellaneous.NewSetKeywordMarkerFilter(result, a.stemExclusionSet)
+	}
+	result = snowball.NewSnowballFilter(result, snowball.SpanishStemmer{})
+	return NewTokenStreamComponents(source, result)
+}
@@ -0,0 +1,14 @@
+package es
+
+import (
+	. "github.com/gzg1984/golucene/test_framework/analysis"
+	"testing"
+)
+
+func TestSpanishAnalyzer(t *testing.T) {
+	a := NewSpanishAnalyzer()
+	AssertAnalyzesTo(t, a, "Los niños estaban corriendo rápidamente", []string{"niñ", "corr", "rapid"})
+
+	a = NewSpanishAnalyzerWithStemExclusion(DefaultStopSet(), map[string]bool{"niños": true})
+	AssertAnalyzesTo(t, a, "Los niños", []string{"niños"})
+}
@@ -0,0 +1,103 @@
+package fr
+
+import (
+	. "github.com/gzg1984/golucene/analysis/core"
+	"github.com/gzg1984/golucene/analysis/miscellaneous"
+	"github.com/gzg1984/golucene/analysis/snowball"
+	"github.com/gzg1984/golucene/analysis/standard"
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	"io"
+)
+
+// fr/FrenchAnalyzer.java
+
+/* Default set of articles for ElisionFilter */
+var DEFAULT_ARTICLES = map[string]bool{
+	"l": true, "m": true, "t": true, "qu": true, "n": true, "s": true, "j": true, "d": true,
+	"c": true, "jusqu": true, "quoiqu": true, "lorsqu": true, "puisqu": true,
+}
+
+var defaultStopSet = SnowballWordSet(`
+ | A French stop word list, from http://snowball.tartarus.org
+au aux avec ce ces dans de des du elle en et eux il je la le leur lui ma mais
+me même mes moi mon ne nos notre nous on ou par pas pour qu que qui sa se ses
+son sur ta te tes toi ton tu un une vos votre vous
+ | single letter forms
+c d j l à m n s t y
+ | forms of être (not including the infinitive):
+été étée étées étés étant suis es est sommes êtes sont serai seras sera serons
+serez seront serais serait serions seriez seraient étais était étions étiez
+étaient fus fut fûmes fûtes furent sois soit soyons soyez soient fusse fusses
+fût fussions fussiez fussent
+ | forms of avoir (not including the infinitive):
+ayant eu eue eues eus ai as avons avez ont aurai auras aura aurons aurez
+auront aurais aurait aurions auriez auraient avais avait avions aviez avaient
+eut eûmes eûtes eurent aie aies ait ayons ayez aient eusse eusses eût eussions
+eussiez eussent
+ | Later additions (from Jean-Christophe Deschamps)
+ceci cela celà cet cette ici ils les leurs quel quels quelle quelles sans soi
+`)
+
+/* Returns an unmodifiable instance of the default stop words set. */
+func DefaultStopSet() map[string]bool {
+	return defaultStopSet
+}
+
+/*
+Analyzer for French language.
+
+Supports an external list of stopwords (words that will not be
+indexed at all) and an external list of exclusions (word that will
+not be stemmed, but indexed). A default set of stopwords is used
+unless an alternative list is specified, but the exclusion list is
+empty by default.
+*/
+type FrenchAnalyzer struct {
+	*StopwordAnalyzerBase
+	excltable map[string]bool
+}
+
+/* Builds an analyzer with the default stop words: DefaultStopSet(). */
+func NewFrenchAnalyzer() *FrenchAnalyzer {
+	return NewFrenchAnalyzerWithStopWords(DefaultStopSet())
+}
+
+/* Builds an analyzer with the given stop words. */
+func NewFrenchAnalyzerWithStopWords(stopwords map[string]bool) *FrenchAnalyzer {
+	return NewFrenchAnalyzerWithStemExclusion(stopwords, nil)
+}
+
+/* Builds an analyzer with the given stop words and stem exclusion words. */
+func NewFrenchAnalyzerWithStemExclusion(stopwords, stemExclusionSet map[string]bool) *FrenchAnalyzer {
+	ans := &FrenchAnalyzer{
+		StopwordAnalyzerBase: NewStopwordAnalyzerBaseWithStopWords(stopwords),
+		excltable:            make(map[string]bool),
+	}
+	for k, v := range stemExclusionSet {
+		ans.excltable[k] = v
+	}
+	ans.Spi = ans
+	return ans
+}
+
+/*
+Creates TokenStreamComponents used to tokenize all the text in the
+provided reader: StandardTokenizer filtered with StandardFilter,
+ElisionFilter, LowerCaseFilter, StopFilter, SetKeywordMarkerFilter if
+a stem exclusion set is provided, and SnowballFilter with the French
+stemmer.
+*/
+func (a *FrenchAnalyzer) CreateComponents(fieldName string, reader io.RuneReader) *TokenStreamComponents {
+	version := a.Version()
+	source := standard.NewStandardTokenizer(version, reader)
+	var result TokenStream = standard.NewStandardFilter(version, source)
+	result = NewElisionFilter(result, DEFAULT_ARTICLES)
+	result = NewLowerCaseFilter(version, result)
+	result = NewStopFilter(version, result, a.StopwordSet())
+	if len(a.excltable) > 0 {
+		result = miscellaneous.NewSetKeywordMarkerFilter(result, a.excltable)
+	}
+	result = snowball.NewSnowballFilter(result, snowball.FrenchStemmer{})
+	return NewTokenStreamComponents(source, result)
+}
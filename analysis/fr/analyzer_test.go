@@ -0,0 +1,24 @@
+package fr
+
+import (
+	"github.com/gzg1984/golucene/analysis/core"
+	"github.com/gzg1984/golucene/core/util"
+	. "github.com/gzg1984/golucene/test_framework/analysis"
+	"strings"
+	"testing"
+)
+
+func TestElisionFilter(t *testing.T) {
+	ts := NewElisionFilter(core.NewWhitespaceTokenizer(util.VERSION_LATEST,
+		strings.NewReader("L'avion qu’il aujourd'hui l'")), DEFAULT_ARTICLES)
+	AssertTokenStreamContents(t, ts, []string{"avion", "il", "aujourd'hui", ""}, nil, nil, nil, nil)
+}
+
+func TestFrenchAnalyzer(t *testing.T) {
+	a := NewFrenchAnalyzer()
+	AssertAnalyzesTo(t, a, "L'avion des enfants était continuellement majestueux",
+		[]string{"avion", "enfant", "continuel", "majestu"})
+
+	a = NewFrenchAnalyzerWithStemExclusion(DefaultStopSet(), map[string]bool{"enfants": true})
+	AssertAnalyzesTo(t, a, "Les enfants", []string{"enfants"})
+}
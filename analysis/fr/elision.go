@@ -0,0 +1,89 @@
+package fr
+
+import (
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"strings"
+)
+
+// util/ElisionFilter.java
+
+/*
+Removes elisions from a TokenStream. For example, "l'avion" (the
+plane) will be tokenized as "avion" (plane). The articles are matched
+ignoring case.
+*/
+type ElisionFilter struct {
+	*TokenFilter
+	input    TokenStream
+	articles map[string]bool
+	termAtt  CharTermAttribute
+}
+
+/* Constructs an elision filter with a set of stop words */
+func NewElisionFilter(input TokenStream, articles map[string]bool) *ElisionFilter {
+	ans := &ElisionFilter{
+		TokenFilter: NewTokenFilter(input),
+		input:       input,
+		articles:    articles,
+	}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	return ans
+}
+
+/* Increments the TokenStream with a CharTermAttribute without elisioned start */
+func (f *ElisionFilter) IncrementToken() (bool, error) {
+	ok, err := f.input.IncrementToken()
+	if !ok || err != nil {
+		return false, err
+	}
+	termBuffer := f.termAtt.Buffer()[:f.termAtt.Length()]
+	for i, ch := range termBuffer {
+		if ch == '\'' || ch == '’' {
+			// An apostrophe has been found. If the prefix is an article strip it off.
+			if f.articles[strings.ToLower(string(termBuffer[:i]))] {
+				f.termAtt.CopyBuffer(termBuffer[i+1:])
+			}
+			break
+		}
+	}
+	return true, nil
+}
+
+// util/ElisionFilterFactory.java
+
+func init() {
+	RegisterTokenFilterFactory("elision", func(args map[string]string) (TokenFilterFactory, error) {
+		return NewElisionFilterFactory(args)
+	})
+}
+
+/*
+Factory for ElisionFilter. The "articles" arg is a comma separated
+list, and defaults to DEFAULT_ARTICLES.
+*/
+type ElisionFilterFactory struct {
+	*AbstractAnalysisFactory
+	articles map[string]bool
+}
+
+/* Creates a new ElisionFilterFactory. */
+func NewElisionFilterFactory(args map[string]string) (*ElisionFilterFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	articles := base.GetSet("articles")
+	if articles == nil {
+		articles = DEFAULT_ARTICLES
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &ElisionFilterFactory{base, articles}, nil
+}
+
+func (f *ElisionFilterFactory) Create(input TokenStream) TokenStream {
+	return NewElisionFilter(input, f.articles)
+}
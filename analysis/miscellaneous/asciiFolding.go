@@ -0,0 +1,155 @@
+package miscellaneous
+
+import (
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"github.com/gzg1984/golucene/core/util"
+)
+
+// miscellaneous/ASCIIFoldingFilter.java
+
+/*
+This class converts alphabetic, numeric, and symbolic Unicode
+characters which are not in the first 127 ASCII characters (the
+"Basic Latin" Unicode block) into their ASCII equivalents, if one
+exists.
+
+Characters from the following Unicode blocks are converted; however,
+only those characters with reasonable ASCII alternatives are
+converted: Latin-1 Supplement, Latin Extended-A, Latin Extended-B,
+IPA Extensions, Latin Extended Additional, General Punctuation,
+Superscripts and Subscripts, Number Forms, Enclosed Alphanumerics,
+Alphabetic Presentation Forms (Latin ligatures) and Halfwidth and
+Fullwidth Forms.
+
+For example, 'à' will be replaced by 'a'.
+
+If preserveOriginal is true, the original token is emitted at the
+same position right after the folded one, unless they are equal.
+*/
+type ASCIIFoldingFilter struct {
+	*TokenFilter
+	input            TokenStream
+	output           []rune
+	preserveOriginal bool
+	state            *util.AttributeState
+	termAtt          CharTermAttribute
+	posIncAtt        PositionIncrementAttribute
+}
+
+/* Create a new ASCIIFoldingFilter. */
+func NewASCIIFoldingFilter(input TokenStream, preserveOriginal bool) *ASCIIFoldingFilter {
+	ans := &ASCIIFoldingFilter{
+		TokenFilter:      NewTokenFilter(input),
+		input:            input,
+		preserveOriginal: preserveOriginal,
+	}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	ans.posIncAtt = ans.Attributes().Add("PositionIncrementAttribute").(PositionIncrementAttribute)
+	return ans
+}
+
+/* Does the filter preserve the original tokens? */
+func (f *ASCIIFoldingFilter) IsPreserveOriginal() bool {
+	return f.preserveOriginal
+}
+
+func (f *ASCIIFoldingFilter) IncrementToken() (bool, error) {
+	if f.state != nil {
+		assert2(f.preserveOriginal, "state should only be captured if preserveOriginal is true")
+		f.Attributes().RestoreState(f.state)
+		f.posIncAtt.SetPositionIncrement(0)
+		f.state = nil
+		return true, nil
+	}
+	ok, err := f.input.IncrementToken()
+	if !ok || err != nil {
+		return false, err
+	}
+	buffer := f.termAtt.Buffer()[:f.termAtt.Length()]
+	// If no characters actually require rewriting then we just return
+	// token as-is:
+	for _, c := range buffer {
+		if c >= 0x0080 {
+			f.foldToASCII(buffer)
+			break
+		}
+	}
+	return true, nil
+}
+
+func (f *ASCIIFoldingFilter) Reset() error {
+	if err := f.TokenFilter.Reset(); err != nil {
+		return err
+	}
+	f.state = nil
+	return nil
+}
+
+/*
+Converts characters above ASCII to their ASCII equivalents. For
+example, accents are removed from accented characters.
+*/
+func (f *ASCIIFoldingFilter) foldToASCII(input []rune) {
+	f.output = FoldToASCII(f.output[:0], input)
+	if f.preserveOriginal && string(f.output) != string(input) {
+		f.state = f.Attributes().CaptureState()
+	}
+	f.termAtt.CopyBuffer(f.output)
+}
+
+/*
+Appends the ASCII equivalents of the characters of input to output,
+and returns the extended slice. Characters without ASCII equivalent
+are appended as they are.
+*/
+func FoldToASCII(output, input []rune) []rune {
+	for _, c := range input {
+		// Quick test: if it's not in range then just keep current character
+		if c < 0x0080 {
+			output = append(output, c)
+		} else if folded, ok := asciiFoldings[c]; ok {
+			for _, ch := range folded {
+				output = append(output, ch)
+			}
+		} else {
+			output = append(output, c)
+		}
+	}
+	return output
+}
+
+// miscellaneous/ASCIIFoldingFilterFactory.java
+
+func init() {
+	RegisterTokenFilterFactory("asciifolding", func(args map[string]string) (TokenFilterFactory, error) {
+		return NewASCIIFoldingFilterFactory(args)
+	})
+}
+
+/* Factory for ASCIIFoldingFilter. */
+type ASCIIFoldingFilterFactory struct {
+	*AbstractAnalysisFactory
+	preserveOriginal bool
+}
+
+/* Creates a new ASCIIFoldingFilterFactory. */
+func NewASCIIFoldingFilterFactory(args map[string]string) (*ASCIIFoldingFilterFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	preserveOriginal, err := base.GetBool("preserveOriginal", false)
+	if err != nil {
+		return nil, err
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &ASCIIFoldingFilterFactory{base, preserveOriginal}, nil
+}
+
+func (f *ASCIIFoldingFilterFactory) Create(input TokenStream) TokenStream {
+	return NewASCIIFoldingFilter(input, f.preserveOriginal)
+}
@@ -0,0 +1,147 @@
+package miscellaneous
+
+import (
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"regexp"
+)
+
+// miscellaneous/KeywordMarkerFilter.java
+
+type KeywordMarkerFilterSPI interface {
+	// Returns true iff the current token is a keyword
+	IsKeyword() bool
+}
+
+/*
+Marks terms as keywords via the KeywordAttribute. Subclasses decide
+which terms are keywords by implementing IsKeyword().
+*/
+type KeywordMarkerFilter struct {
+	*TokenFilter
+	spi        KeywordMarkerFilterSPI
+	input      TokenStream
+	keywordAtt KeywordAttribute
+}
+
+/* Creates a new KeywordMarkerFilter. */
+func NewKeywordMarkerFilter(spi KeywordMarkerFilterSPI, in TokenStream) *KeywordMarkerFilter {
+	ans := &KeywordMarkerFilter{
+		TokenFilter: NewTokenFilter(in),
+		spi:         spi,
+		input:       in,
+	}
+	ans.keywordAtt = ans.Attributes().Add("KeywordAttribute").(KeywordAttribute)
+	return ans
+}
+
+func (f *KeywordMarkerFilter) IncrementToken() (bool, error) {
+	ok, err := f.input.IncrementToken()
+	if !ok || err != nil {
+		return false, err
+	}
+	if f.spi.IsKeyword() {
+		f.keywordAtt.SetKeyword(true)
+	}
+	return true, nil
+}
+
+// miscellaneous/SetKeywordMarkerFilter.java
+
+/* Marks terms as keywords via the KeywordAttribute, if they are in a set. */
+type SetKeywordMarkerFilter struct {
+	*KeywordMarkerFilter
+	keywordSet map[string]bool
+	termAtt    CharTermAttribute
+}
+
+/*
+Create a new SetKeywordMarkerFilter, that marks the current token as
+a keyword if the tokens term buffer is contained in the given set via
+the KeywordAttribute.
+*/
+func NewSetKeywordMarkerFilter(in TokenStream, keywordSet map[string]bool) *SetKeywordMarkerFilter {
+	ans := &SetKeywordMarkerFilter{keywordSet: keywordSet}
+	ans.KeywordMarkerFilter = NewKeywordMarkerFilter(ans, in)
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	return ans
+}
+
+func (f *SetKeywordMarkerFilter) IsKeyword() bool {
+	return f.keywordSet[string(f.termAtt.Buffer()[:f.termAtt.Length()])]
+}
+
+// miscellaneous/PatternKeywordMarkerFilter.java
+
+/* Marks terms as keywords via the KeywordAttribute, if they match a pattern. */
+type PatternKeywordMarkerFilter struct {
+	*KeywordMarkerFilter
+	pattern *regexp.Regexp
+	termAtt CharTermAttribute
+}
+
+/*
+Create a new PatternKeywordMarkerFilter, that marks the current token
+as a keyword if the tokens term buffer matches the provided pattern
+entirely via the KeywordAttribute.
+*/
+func NewPatternKeywordMarkerFilter(in TokenStream, pattern *regexp.Regexp) *PatternKeywordMarkerFilter {
+	ans := &PatternKeywordMarkerFilter{
+		// the whole term must match, as Java's Matcher.matches() does
+		pattern: regexp.MustCompile("^(?:" + pattern.String() + ")$"),
+	}
+	ans.KeywordMarkerFilter = NewKeywordMarkerFilter(ans, in)
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	return ans
+}
+
+func (f *PatternKeywordMarkerFilter) IsKeyword() bool {
+	return f.pattern.MatchString(string(f.termAtt.Buffer()[:f.termAtt.Length()]))
+}
+
+// miscellaneous/KeywordMarkerFilterFactory.java
+
+func init() {
+	RegisterTokenFilterFactory("keywordmarker", func(args map[string]string) (TokenFilterFactory, error) {
+		return NewKeywordMarkerFilterFactory(args)
+	})
+}
+
+/*
+Factory for KeywordMarkerFilter. The keywords are given by the
+"protected" arg as a comma separated list, and/or by the "pattern"
+arg as a regular expression matching whole terms.
+*/
+type KeywordMarkerFilterFactory struct {
+	*AbstractAnalysisFactory
+	protectedWords map[string]bool
+	pattern        *regexp.Regexp
+}
+
+/* Creates a new KeywordMarkerFilterFactory. */
+func NewKeywordMarkerFilterFactory(args map[string]string) (*KeywordMarkerFilterFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	protectedWords := base.GetSet("protected")
+	pattern, err := base.GetPattern("pattern")
+	if err != nil {
+		return nil, err
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &KeywordMarkerFilterFactory{base, protectedWords, pattern}, nil
+}
+
+func (f *KeywordMarkerFilterFactory) Create(input TokenStream) TokenStream {
+	if f.pattern != nil {
+		input = NewPatternKeywordMarkerFilter(input, f.pattern)
+	}
+	if f.protectedWords != nil {
+		input = NewSetKeywordMarkerFilter(input, f.protectedWords)
+	}
+	return input
+}
@@ -0,0 +1,89 @@
+package miscellaneous
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"github.com/gzg1984/golucene/core/util"
+)
+
+// miscellaneous/LengthFilter.java
+
+/*
+Removes words that are too long or too short from the stream.
+
+Note: Length is calculated as the number of runes.
+*/
+type LengthFilter struct {
+	*FilteringTokenFilter
+	min, max int
+	termAtt  CharTermAttribute
+}
+
+/*
+Create a new LengthFilter. This will filter out tokens whose
+CharTermAttribute is either too short (Length() < min) or too long
+(Length() > max).
+*/
+func NewLengthFilter(version util.Version, in TokenStream, min, max int) *LengthFilter {
+	assert2(min >= 0, "minimum length must be greater than or equal to zero")
+	assert2(min <= max, "maximum length must not be greater than minimum length")
+	ans := &LengthFilter{min: min, max: max}
+	ans.FilteringTokenFilter = NewFilteringTokenFilter(ans, version, in)
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	return ans
+}
+
+func (f *LengthFilter) Accept() bool {
+	length := f.termAtt.Length()
+	return length >= f.min && length <= f.max
+}
+
+// miscellaneous/LengthFilterFactory.java
+
+func init() {
+	RegisterTokenFilterFactory("length", func(args map[string]string) (TokenFilterFactory, error) {
+		return NewLengthFilterFactory(args)
+	})
+}
+
+/* Factory for LengthFilter. The "min" and "max" args are required. */
+type LengthFilterFactory struct {
+	*AbstractAnalysisFactory
+	min, max int
+}
+
+/* Creates a new LengthFilterFactory. */
+func NewLengthFilterFactory(args map[string]string) (*LengthFilterFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	min, err := base.RequireInt("min")
+	if err != nil {
+		return nil, err
+	}
+	max, err := base.RequireInt("max")
+	if err != nil {
+		return nil, err
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	if min < 0 || min > max {
+		return nil, errors.New(fmt.Sprintf("Configuration Error: invalid length range [%v, %v]", min, max))
+	}
+	return &LengthFilterFactory{base, min, max}, nil
+}
+
+func (f *LengthFilterFactory) Create(input TokenStream) TokenStream {
+	return NewLengthFilter(f.LuceneMatchVersion, input, f.min, f.max)
+}
+
+func assert2(ok bool, msg string, args ...interface{}) {
+	if !ok {
+		panic(fmt.Sprintf(msg, args...))
+	}
+}
@@ -0,0 +1,59 @@
+package miscellaneous
+
+import (
+	"github.com/gzg1984/golucene/analysis/core"
+	. "github.com/gzg1984/golucene/core/analysis"
+	"github.com/gzg1984/golucene/core/util"
+	. "github.com/gzg1984/golucene/test_framework/analysis"
+	. "github.com/gzg1984/gounit"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func whitespace(text string) TokenStream {
+	return core.NewWhitespaceTokenizer(util.VERSION_LATEST, strings.NewReader(text))
+}
+
+func TestASCIIFoldingFilter(t *testing.T) {
+	ts := NewASCIIFoldingFilter(whitespace(
+		"Des mot clés À LA CHAÎNE À Á Â Ã Ä Å Æ Ç È É Ê Ë Ì Í Î Ï Ĳ Ð Ñ Ò Ó Ô Õ Ö Ø Œ Þ Ù Ú Û Ü Ý Ÿ "+
+			"à á â ã ä å æ ç è é ê ë ì í î ï ĳ ð ñ ò ó ô õ ö ø œ ß þ ù ú û ü ý ÿ ﬁ ﬂ"), false)
+	AssertTokenStreamContents(t, ts, []string{
+		"Des", "mot", "cles", "A", "LA", "CHAINE", "A", "A", "A", "A", "A", "A", "AE", "C", "E", "E", "E", "E",
+		"I", "I", "I", "I", "IJ", "D", "N", "O", "O", "O", "O", "O", "O", "OE", "TH", "U", "U", "U", "U", "Y", "Y",
+		"a", "a", "a", "a", "a", "a", "ae", "c", "e", "e", "e", "e", "i", "i", "i", "i", "ij", "d", "n",
+		"o", "o", "o", "o", "o", "o", "oe", "ss", "th", "u", "u", "u", "u", "y", "y", "fi", "fl"},
+		nil, nil, nil, nil)
+
+	// the original token follows the folded one at the same position
+	ts = NewASCIIFoldingFilter(whitespace("café au łait 中文"), true)
+	AssertTokenStreamContents(t, ts, []string{"cafe", "café", "au", "lait", "łait", "中文"},
+		[]int{0, 0, 5, 8, 8, 13}, []int{4, 4, 7, 12, 12, 15}, nil, []int{1, 0, 1, 1, 0, 1})
+
+	folded := string(FoldToASCII(nil, []rune("½ “quoted” ｆｕｌｌ ⑴ Straße")))
+	It(t).Should("expect folded text, got %q", folded).Verify(folded == `1/2 "quoted" full (1) Strasse`)
+}
+
+func TestLengthFilter(t *testing.T) {
+	ts := NewLengthFilter(util.VERSION_LATEST, whitespace("short toolong evenmuchlongertext a ab toolong foo"), 2, 6)
+	AssertTokenStreamContents(t, ts, []string{"short", "ab", "foo"}, nil, nil, nil, []int{1, 4, 2})
+}
+
+func TestTrimFilter(t *testing.T) {
+	ts := NewTrimFilter(util.VERSION_LATEST, core.NewKeywordTokenizer(strings.NewReader(" \t a b \n")))
+	AssertTokenStreamContents(t, ts, []string{"a b"}, []int{0}, []int{8}, nil, nil)
+
+	ts = NewTrimFilter(util.VERSION_LATEST, core.NewKeywordTokenizer(strings.NewReader("  ")))
+	AssertTokenStreamContents(t, ts, []string{""}, nil, nil, nil, nil)
+}
+
+func TestKeywordMarkerAndTruncateFilters(t *testing.T) {
+	var ts TokenStream = NewSetKeywordMarkerFilter(whitespace("abcdefgh protected ab"), map[string]bool{"protected": true})
+	ts = NewTruncateTokenFilter(ts, 5)
+	AssertTokenStreamContents(t, ts, []string{"abcde", "protected", "ab"}, nil, nil, nil, nil)
+
+	ts = NewPatternKeywordMarkerFilter(whitespace("x12345678 12345678"), regexp.MustCompile("[0-9]+"))
+	ts = NewTruncateTokenFilter(ts, 5)
+	AssertTokenStreamContents(t, ts, []string{"x1234", "12345678"}, nil, nil, nil, nil)
+}
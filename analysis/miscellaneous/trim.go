@@ -0,0 +1,92 @@
+package miscellaneous
+
+import (
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"github.com/gzg1984/golucene/core/util"
+	"unicode"
+)
+
+// miscellaneous/TrimFilter.java
+
+/*
+Trims leading and trailing whitespace from Tokens in the stream.
+
+As of Lucene 4.4, this filter does not support updateOffsets=true
+anymore, as it can lead to broken token streams.
+*/
+type TrimFilter struct {
+	*TokenFilter
+	input   TokenStream
+	termAtt CharTermAttribute
+}
+
+/* Create a new TrimFilter. */
+func NewTrimFilter(version util.Version, in TokenStream) *TrimFilter {
+	ans := &TrimFilter{
+		TokenFilter: NewTokenFilter(in),
+		input:       in,
+	}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	return ans
+}
+
+func (f *TrimFilter) IncrementToken() (bool, error) {
+	ok, err := f.input.IncrementToken()
+	if !ok || err != nil {
+		return false, err
+	}
+
+	termBuffer := f.termAtt.Buffer()
+	length := f.termAtt.Length()
+	if length == 0 {
+		return true, nil
+	}
+	start, end := 0, length
+	// eat the first characters
+	for start < end && unicode.IsSpace(termBuffer[start]) {
+		start++
+	}
+	// eat the end characters
+	for end > start && unicode.IsSpace(termBuffer[end-1]) {
+		end--
+	}
+	if start > 0 || end < length {
+		if start < end {
+			f.termAtt.CopyBuffer(termBuffer[start:end])
+		} else {
+			f.termAtt.SetEmpty()
+		}
+	}
+	return true, nil
+}
+
+// miscellaneous/TrimFilterFactory.java
+
+func init() {
+	RegisterTokenFilterFactory("trim", func(args map[string]string) (TokenFilterFactory, error) {
+		return NewTrimFilterFactory(args)
+	})
+}
+
+/* Factory for TrimFilter. */
+type TrimFilterFactory struct {
+	*AbstractAnalysisFactory
+}
+
+/* Creates a new TrimFilterFactory. */
+func NewTrimFilterFactory(args map[string]string) (*TrimFilterFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &TrimFilterFactory{base}, nil
+}
+
+func (f *TrimFilterFactory) Create(input TokenStream) TokenStream {
+	return NewTrimFilter(f.LuceneMatchVersion, input)
+}
@@ -0,0 +1,356 @@
+package shingle
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"github.com/gzg1984/golucene/core/util"
+	"strings"
+)
+
+// shingle/ShingleFilter.java
+
+const (
+	// default maximum shingle size is 2.
+	DEFAULT_MAX_SHINGLE_SIZE = 2
+	// default minimum shingle size is 2.
+	DEFAULT_MIN_SHINGLE_SIZE = 2
+	// default token type attribute value is "shingle"
+	DEFAULT_TOKEN_TYPE = "shingle"
+	// The default string to use when joining adjacent tokens to form a shingle
+	DEFAULT_TOKEN_SEPARATOR = " "
+	// The default string to insert for position increments > 1
+	DEFAULT_FILLER_TOKEN = "_"
+)
+
+/* An input token buffered in the shingle window. */
+type inputToken struct {
+	state                  *util.AttributeState
+	term                   []rune
+	startOffset, endOffset int
+	posInc                 int
+	filler                 bool
+}
+
+/*
+A ShingleFilter constructs shingles (token n-grams) from a token
+stream. In other words, it creates combinations of tokens as a single
+token.
+
+For example, the sentence "please divide this sentence into shingles"
+might be tokenized into shingles "please divide", "divide this",
+"this sentence", "sentence into", and "into shingles".
+
+This filter handles position increments > 1 by inserting filler
+tokens (tokens with termtext "_"). It does not handle a position
+increment of 0.
+*/
+type ShingleFilter struct {
+	*TokenFilter
+	input TokenStream
+
+	// The string to use when joining adjacent tokens to form a shingle
+	tokenSeparator string
+	// The string to insert for each position at which there is no
+	// token (i.e., when position increment is greater than one).
+	fillerToken string
+	// By default, we output unigrams (individual tokens) as well as
+	// shingles (token n-grams).
+	outputUnigrams bool
+	// By default, we don't override behavior of outputUnigrams.
+	outputUnigramsIfNoShingles bool
+	// maximum and minimum shingle sizes (number of tokens)
+	maxShingleSize, minShingleSize int
+	tokenType                      string
+
+	// the input tokens from the current position on
+	window    []*inputToken
+	exhausted bool
+	started   bool
+	// true if no shingles can be built from the whole input, and
+	// outputUnigramsIfNoShingles is set
+	unigramsOnly bool
+	// the size of the next gram to output at the current position,
+	// or 0 to move to the next position
+	gramSize int
+	// position increment not emitted yet
+	posIncPending int
+
+	termAtt   CharTermAttribute
+	offsetAtt OffsetAttribute
+	posIncAtt PositionIncrementAttribute
+	posLenAtt PositionLengthAttribute
+	typeAtt   TypeAttribute
+}
+
+/* Constructs a ShingleFilter with the specified shingle size from the TokenStream input. */
+func NewShingleFilter(input TokenStream, minShingleSize, maxShingleSize int) *ShingleFilter {
+	ans := &ShingleFilter{
+		TokenFilter:    NewTokenFilter(input),
+		input:          input,
+		tokenSeparator: DEFAULT_TOKEN_SEPARATOR,
+		fillerToken:    DEFAULT_FILLER_TOKEN,
+		outputUnigrams: true,
+		tokenType:      DEFAULT_TOKEN_TYPE,
+	}
+	ans.SetMaxShingleSize(maxShingleSize)
+	ans.SetMinShingleSize(minShingleSize)
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	ans.offsetAtt = ans.Attributes().Add("OffsetAttribute").(OffsetAttribute)
+	ans.posIncAtt = ans.Attributes().Add("PositionIncrementAttribute").(PositionIncrementAttribute)
+	ans.posLenAtt = ans.Attributes().Add("PositionLengthAttribute").(PositionLengthAttribute)
+	ans.typeAtt = ans.Attributes().Add("TypeAttribute").(TypeAttribute)
+	return ans
+}
+
+/* Set the type of the shingle tokens produced by this filter. (default: "shingle") */
+func (f *ShingleFilter) SetTokenType(tokenType string) {
+	f.tokenType = tokenType
+}
+
+/* Shall the output stream contain the input tokens (unigrams) as well as shingles? (default: true.) */
+func (f *ShingleFilter) SetOutputUnigrams(outputUnigrams bool) {
+	f.outputUnigrams = outputUnigrams
+}
+
+/*
+Shall we override the behavior of outputUnigrams==false for those
+times when no shingles are available (because there are fewer than
+minShingleSize tokens in the input stream)? (default: false.)
+
+Note that if outputUnigrams==true, then unigrams are always output,
+regardless of whether any shingles are available.
+*/
+func (f *ShingleFilter) SetOutputUnigramsIfNoShingles(outputUnigramsIfNoShingles bool) {
+	f.outputUnigramsIfNoShingles = outputUnigramsIfNoShingles
+}
+
+/* Set the max shingle size (default: 2) */
+func (f *ShingleFilter) SetMaxShingleSize(maxShingleSize int) {
+	assert2(maxShingleSize >= 2, "Max shingle size must be >= 2")
+	f.maxShingleSize = maxShingleSize
+}
+
+/*
+Set the min shingle size (default: 2).
+
+This method requires that the passed in minShingleSize is not greater
+than maxShingleSize, so make sure that maxShingleSize is set before
+calling this method.
+
+The unigram output option is independent of the min shingle size.
+*/
+func (f *ShingleFilter) SetMinShingleSize(minShingleSize int) {
+	assert2(minShingleSize >= 2, "Min shingle size must be >= 2")
+	assert2(minShingleSize <= f.maxShingleSize, "Min shingle size must be <= max shingle size")
+	f.minShingleSize = minShingleSize
+}
+
+/* Sets the string to use when joining adjacent tokens to form a shingle */
+func (f *ShingleFilter) SetTokenSeparator(tokenSeparator string) {
+	f.tokenSeparator = tokenSeparator
+}
+
+/* Sets the string to insert for each position at which there is no token (i.e., when position increment is greater than one). */
+func (f *ShingleFilter) SetFillerToken(fillerToken string) {
+	f.fillerToken = fillerToken
+}
+
+func (f *ShingleFilter) IncrementToken() (bool, error) {
+	for {
+		if f.gramSize == 0 { // move to the next position
+			if f.started {
+				f.window = f.window[1:]
+			}
+			if err := f.fill(); err != nil {
+				return false, err
+			}
+			if !f.started {
+				f.started = true
+				f.unigramsOnly = !f.outputUnigrams && f.outputUnigramsIfNoShingles &&
+					f.exhausted && len(f.window) < f.minShingleSize
+			}
+			if len(f.window) == 0 {
+				return false, nil
+			}
+			f.posIncPending += f.window[0].posInc
+			f.gramSize = 1
+		}
+
+		n := f.gramSize
+		if n == 1 {
+			f.gramSize = f.minShingleSize
+			if (f.outputUnigrams || f.unigramsOnly) && !f.window[0].filler {
+				f.emit(1)
+				return true, nil
+			}
+			continue
+		}
+		if f.unigramsOnly || n > f.maxShingleSize || n > len(f.window) {
+			f.gramSize = 0
+			continue
+		}
+		f.gramSize++
+		if f.fillersOnly(n) {
+			continue
+		}
+		f.emit(n)
+		return true, nil
+	}
+}
+
+func (f *ShingleFilter) fillersOnly(n int) bool {
+	for _, token := range f.window[:n] {
+		if !token.filler {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Reads input tokens until the window holds maxShingleSize tokens, or
+the input is exhausted. A filler token is inserted for each missing
+position, up to maxShingleSize-1 of them.
+*/
+func (f *ShingleFilter) fill() error {
+	for !f.exhausted && len(f.window) < f.maxShingleSize {
+		ok, err := f.input.IncrementToken()
+		if err != nil {
+			return err
+		} else if !ok {
+			f.exhausted = true
+			break
+		}
+		posInc := f.posIncAtt.PositionIncrement()
+		startOffset := f.offsetAtt.StartOffset()
+		if posInc > 1 {
+			fillers := posInc - 1
+			if fillers > f.maxShingleSize-1 {
+				fillers = f.maxShingleSize - 1
+			}
+			for i := 0; i < fillers; i++ {
+				f.window = append(f.window, &inputToken{
+					term:        []rune(f.fillerToken),
+					startOffset: startOffset,
+					endOffset:   startOffset,
+					posInc:      1,
+					filler:      true,
+				})
+			}
+			posInc -= fillers
+		}
+		f.window = append(f.window, &inputToken{
+			state:       f.Attributes().CaptureState(),
+			term:        append([]rune(nil), f.termAtt.Buffer()[:f.termAtt.Length()]...),
+			startOffset: startOffset,
+			endOffset:   f.offsetAtt.EndOffset(),
+			posInc:      posInc,
+		})
+	}
+	return nil
+}
+
+/* Sets the attributes to the n-gram of the first n tokens of the window. */
+func (f *ShingleFilter) emit(n int) {
+	grams := f.window[:n]
+	for _, token := range grams {
+		if !token.filler {
+			f.Attributes().RestoreState(token.state)
+			break
+		}
+	}
+	if n > 1 {
+		terms := make([]string, n)
+		for i, token := range grams {
+			terms[i] = string(token.term)
+		}
+		f.termAtt.CopyBuffer([]rune(strings.Join(terms, f.tokenSeparator)))
+		f.typeAtt.SetType(f.tokenType)
+	}
+	f.offsetAtt.SetOffset(grams[0].startOffset, grams[n-1].endOffset)
+	f.posIncAtt.SetPositionIncrement(f.posIncPending)
+	f.posIncPending = 0
+	f.posLenAtt.SetPositionLength(n)
+}
+
+func (f *ShingleFilter) Reset() error {
+	if err := f.TokenFilter.Reset(); err != nil {
+		return err
+	}
+	f.window = nil
+	f.exhausted, f.started, f.unigramsOnly = false, false, false
+	f.gramSize, f.posIncPending = 0, 0
+	return nil
+}
+
+// shingle/ShingleFilterFactory.java
+
+func init() {
+	RegisterTokenFilterFactory("shingle", func(args map[string]string) (TokenFilterFactory, error) {
+		return NewShingleFilterFactory(args)
+	})
+}
+
+/* Factory for ShingleFilter. */
+type ShingleFilterFactory struct {
+	*AbstractAnalysisFactory
+	minShingleSize, maxShingleSize             int
+	outputUnigrams, outputUnigramsIfNoShingles bool
+	tokenSeparator, fillerToken                string
+}
+
+/* Creates a new ShingleFilterFactory. */
+func NewShingleFilterFactory(args map[string]string) (*ShingleFilterFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	ans := &ShingleFilterFactory{AbstractAnalysisFactory: base}
+	if ans.maxShingleSize, err = base.GetInt("maxShingleSize", DEFAULT_MAX_SHINGLE_SIZE); err != nil {
+		return nil, err
+	}
+	if ans.maxShingleSize < 2 {
+		return nil, errors.New(fmt.Sprintf("Invalid maxShingleSize (%v) - must be at least 2", ans.maxShingleSize))
+	}
+	if ans.minShingleSize, err = base.GetInt("minShingleSize", DEFAULT_MIN_SHINGLE_SIZE); err != nil {
+		return nil, err
+	}
+	if ans.minShingleSize < 2 {
+		return nil, errors.New(fmt.Sprintf("Invalid minShingleSize (%v) - must be at least 2", ans.minShingleSize))
+	}
+	if ans.minShingleSize > ans.maxShingleSize {
+		return nil, errors.New(fmt.Sprintf(
+			"Invalid minShingleSize (%v) - must be no greater than maxShingleSize (%v)",
+			ans.minShingleSize, ans.maxShingleSize))
+	}
+	if ans.outputUnigrams, err = base.GetBool("outputUnigrams", true); err != nil {
+		return nil, err
+	}
+	if ans.outputUnigramsIfNoShingles, err = base.GetBool("outputUnigramsIfNoShingles", false); err != nil {
+		return nil, err
+	}
+	ans.tokenSeparator = base.Get("tokenSeparator", DEFAULT_TOKEN_SEPARATOR)
+	ans.fillerToken = base.Get("fillerToken", DEFAULT_FILLER_TOKEN)
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (f *ShingleFilterFactory) Create(input TokenStream) TokenStream {
+	ans := NewShingleFilter(input, f.minShingleSize, f.maxShingleSize)
+	ans.SetOutputUnigrams(f.outputUnigrams)
+	ans.SetOutputUnigramsIfNoShingles(f.outputUnigramsIfNoShingles)
+	ans.SetTokenSeparator(f.tokenSeparator)
+	ans.SetFillerToken(f.fillerToken)
+	return ans
+}
+
+func assert2(ok bool, msg string, args ...interface{}) {
+	if !ok {
+		panic(fmt.Sprintf(msg, args...))
+	}
+}
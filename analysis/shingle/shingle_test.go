@@ -0,0 +1,57 @@
+package shingle
+
+import (
+	"github.com/gzg1984/golucene/analysis/core"
+	. "github.com/gzg1984/golucene/core/analysis"
+	"github.com/gzg1984/golucene/core/util"
+	. "github.com/gzg1984/golucene/test_framework/analysis"
+	"strings"
+	"testing"
+)
+
+func whitespace(text string) TokenStream {
+	return core.NewWhitespaceTokenizer(util.VERSION_LATEST, strings.NewReader(text))
+}
+
+func TestBiGramFilter(t *testing.T) {
+	ts := NewShingleFilter(whitespace("please divide this sentence"), 2, 2)
+	AssertTokenStreamContents(t, ts,
+		[]string{"please", "please divide", "divide", "divide this", "this", "this sentence", "sentence"},
+		[]int{0, 0, 7, 7, 14, 14, 19},
+		[]int{6, 13, 13, 18, 18, 27, 27},
+		[]string{"word", "shingle", "word", "shingle", "word", "shingle", "word"},
+		[]int{1, 0, 1, 0, 1, 0, 1})
+}
+
+func TestTriGramFilterWithoutUnigrams(t *testing.T) {
+	ts := NewShingleFilter(whitespace("please divide this sentence"), 2, 3)
+	ts.SetOutputUnigrams(false)
+	ts.SetTokenSeparator("+")
+	AssertTokenStreamContents(t, ts,
+		[]string{"please+divide", "please+divide+this", "divide+this", "divide+this+sentence", "this+sentence"},
+		[]int{0, 0, 7, 7, 14},
+		[]int{13, 18, 18, 27, 27},
+		nil,
+		[]int{1, 0, 1, 0, 1})
+}
+
+func TestShingleFilterWithFillers(t *testing.T) {
+	var ts TokenStream = core.NewStopFilter(util.VERSION_LATEST,
+		whitespace("please divide this sentence"), map[string]bool{"this": true})
+	ts = NewShingleFilter(ts, 2, 2)
+	AssertTokenStreamContents(t, ts,
+		[]string{"please", "please divide", "divide", "divide _", "_ sentence", "sentence"},
+		nil, nil, nil,
+		[]int{1, 0, 1, 0, 1, 1})
+}
+
+func TestOutputUnigramsIfNoShingles(t *testing.T) {
+	ts := NewShingleFilter(whitespace("please"), 2, 2)
+	ts.SetOutputUnigrams(false)
+	AssertTokenStreamContents(t, ts, nil, nil, nil, nil, nil)
+
+	ts = NewShingleFilter(whitespace("please"), 2, 2)
+	ts.SetOutputUnigrams(false)
+	ts.SetOutputUnigramsIfNoShingles(true)
+	AssertTokenStreamContents(t, ts, []string{"please"}, nil, nil, []string{"word"}, []int{1})
+}
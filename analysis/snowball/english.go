@@ -0,0 +1,231 @@
+package snowball
+
+// org/tartarus/snowball/ext/EnglishStemmer.java
+
+func init() {
+	RegisterSnowballProgram("English", func() SnowballProgram { return EnglishStemmer{} })
+}
+
+/*
+The English (Porter2) stemming algorithm, a revision of the original
+Porter algorithm implemented by en.PorterStemmer.
+*/
+type EnglishStemmer struct{}
+
+var englishExceptions1 = map[string]string{
+	// special changes
+	"skis": "ski", "skies": "sky", "dying": "die", "lying": "lie", "tying": "tie",
+	// special -LY cases
+	"idly": "idl", "gently": "gentl", "ugly": "ugli", "early": "earli", "only": "onli", "singly": "singl",
+	// invariant forms
+	"sky": "sky", "news": "news", "howe": "howe",
+	// not plural forms
+	"atlas": "atlas", "cosmos": "cosmos", "bias": "bias", "andes": "andes",
+}
+
+// invariant after step 1a
+var englishExceptions2 = map[string]bool{
+	"inning": true, "outing": true, "canning": true, "herring": true,
+	"earring": true, "proceed": true, "exceed": true, "succeed": true,
+}
+
+var englishStep2 = map[string]string{
+	"tional": "tion", "enci": "ence", "anci": "ance", "abli": "able", "entli": "ent",
+	"izer": "ize", "ization": "ize", "ational": "ate", "ation": "ate", "ator": "ate",
+	"alism": "al", "aliti": "al", "alli": "al", "fulness": "ful", "ousli": "ous",
+	"ousness": "ous", "iveness": "ive", "iviti": "ive", "biliti": "ble", "bli": "ble",
+	"ogi": "og", "fulli": "ful", "lessli": "less", "li": "",
+}
+
+var englishStep3 = map[string]string{
+	"tional": "tion", "ational": "ate", "alize": "al", "icate": "ic", "iciti": "ic",
+	"ical": "ic", "ful": "", "ness": "", "ative": "",
+}
+
+var englishStep4 = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ism", "ate", "iti", "ous", "ive", "ize", "ion",
+}
+
+func isEnglishVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+	return false
+}
+
+/* Returns true iff the word ends with a short syllable. */
+func endsWithShortSyllable(w []rune) bool {
+	n := len(w)
+	if n == 2 {
+		return isEnglishVowel(w[0]) && !isEnglishVowel(w[1])
+	}
+	return n > 2 && !isEnglishVowel(w[n-3]) && isEnglishVowel(w[n-2]) && !isEnglishVowel(w[n-1]) &&
+		w[n-1] != 'w' && w[n-1] != 'x' && w[n-1] != 'Y'
+}
+
+func keys(m map[string]string) []string {
+	ans := make([]string, 0, len(m))
+	for k := range m {
+		ans = append(ans, k)
+	}
+	return ans
+}
+
+var (
+	englishStep2Suffixes = keys(englishStep2)
+	englishStep3Suffixes = keys(englishStep3)
+)
+
+func (s EnglishStemmer) Stem(word []rune) []rune {
+	if len(word) <= 2 {
+		return append([]rune(nil), word...)
+	}
+	if stem, ok := englishExceptions1[string(word)]; ok {
+		return []rune(stem)
+	}
+
+	w := append([]rune(nil), word...)
+	if w[0] == '\'' {
+		w = w[1:]
+	}
+	// mark the y's that are consonants
+	for i, r := range w {
+		if r == 'y' && (i == 0 || isEnglishVowel(w[i-1])) {
+			w[i] = 'Y'
+		}
+	}
+
+	var r1 int
+	switch {
+	case hasPrefix(w, "gener"), hasPrefix(w, "arsen"):
+		r1 = 5
+	case hasPrefix(w, "commun"):
+		r1 = 6
+	default:
+		r1 = region(w, 0, isEnglishVowel)
+	}
+	r2 := region(w, r1, isEnglishVowel)
+
+	// step 0: apostrophes
+	if suffix := longestSuffix(w, "'", "'s", "'s'"); suffix != "" {
+		w = w[:start(w, suffix)]
+	}
+
+	// step 1a: plurals
+	switch suffix := longestSuffix(w, "sses", "ied", "ies", "us", "ss", "s"); suffix {
+	case "sses":
+		w = w[:len(w)-2]
+	case "ied", "ies":
+		if len(w) > 4 {
+			w = w[:len(w)-2]
+		} else {
+			w = w[:len(w)-1]
+		}
+	case "s":
+		if len(w) > 2 && containsVowel(w[:len(w)-2], isEnglishVowel) {
+			w = w[:len(w)-1]
+		}
+	}
+	if englishExceptions2[string(w)] {
+		return w
+	}
+
+	// step 1b: -ed and -ing
+	switch suffix := longestSuffix(w, "eed", "eedly", "ed", "edly", "ing", "ingly"); suffix {
+	case "eed", "eedly":
+		if p := start(w, suffix); p >= r1 {
+			w = replace(w, len(suffix), "ee")
+		}
+	case "ed", "edly", "ing", "ingly":
+		if p := start(w, suffix); containsVowel(w[:p], isEnglishVowel) {
+			w = w[:p]
+			switch {
+			case hasSuffix(w, "at"), hasSuffix(w, "bl"), hasSuffix(w, "iz"):
+				w = append(w, 'e')
+			case endsWithDouble(w):
+				w = w[:len(w)-1]
+			case r1 >= len(w) && endsWithShortSyllable(w):
+				w = append(w, 'e')
+			}
+		}
+	}
+
+	// step 1c: y to i
+	if n := len(w); n > 2 && (w[n-1] == 'y' || w[n-1] == 'Y') && !isEnglishVowel(w[n-2]) {
+		w[n-1] = 'i'
+	}
+
+	// step 2
+	if suffix := longestSuffix(w, englishStep2Suffixes...); suffix != "" {
+		if p := start(w, suffix); p >= r1 {
+			switch suffix {
+			case "ogi":
+				if p > 0 && w[p-1] == 'l' {
+					w = replace(w, len(suffix), englishStep2[suffix])
+				}
+			case "li":
+				if p > 0 && isValidLiEnding(w[p-1]) {
+					w = w[:p]
+				}
+			default:
+				w = replace(w, len(suffix), englishStep2[suffix])
+			}
+		}
+	}
+
+	// step 3
+	if suffix := longestSuffix(w, englishStep3Suffixes...); suffix != "" {
+		if p := start(w, suffix); p >= r1 && (suffix != "ative" || p >= r2) {
+			w = replace(w, len(suffix), englishStep3[suffix])
+		}
+	}
+
+	// step 4
+	if suffix := longestSuffix(w, englishStep4...); suffix != "" {
+		if p := start(w, suffix); p >= r2 && (suffix != "ion" || p > 0 && (w[p-1] == 's' || w[p-1] == 't')) {
+			w = w[:p]
+		}
+	}
+
+	// step 5
+	if n := len(w); n > 0 && w[n-1] == 'e' {
+		if n-1 >= r2 || n-1 >= r1 && !endsWithShortSyllable(w[:n-1]) {
+			w = w[:n-1]
+		}
+	} else if n > 1 && w[n-1] == 'l' && w[n-2] == 'l' && n-1 >= r2 {
+		w = w[:n-1]
+	}
+
+	for i, r := range w {
+		if r == 'Y' {
+			w[i] = 'y'
+		}
+	}
+	return w
+}
+
+func hasPrefix(w []rune, prefix string) bool {
+	return len(w) >= len(prefix) && string(w[:len(prefix)]) == prefix
+}
+
+func endsWithDouble(w []rune) bool {
+	n := len(w)
+	if n < 2 || w[n-1] != w[n-2] {
+		return false
+	}
+	switch w[n-1] {
+	case 'b', 'd', 'f', 'g', 'm', 'n', 'p', 'r', 't':
+		return true
+	}
+	return false
+}
+
+func isValidLiEnding(r rune) bool {
+	switch r {
+	case 'c', 'd', 'e', 'g', 'h', 'k', 'm', 'n', 'r', 't':
+		return true
+	}
+	return false
+}
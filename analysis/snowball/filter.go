@@ -0,0 +1,111 @@
+package snowball
+
+import (
+	"fmt"
+	"github.com/gzg1984/golucene/analysis/miscellaneous"
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+)
+
+// snowball/SnowballFilter.java
+
+/*
+A filter that stems words using a Snowball stemmer. Available stemmers
+are listed by AvailableSnowballPrograms().
+
+NOTE: SnowballFilter expects lowercased text.
+
+Note: This filter is aware of the KeywordAttribute. To prevent certain
+terms from being passed to the stemmer KeywordAttribute.IsKeyword()
+should be set to true in a previous TokenStream.
+*/
+type SnowballFilter struct {
+	*TokenFilter
+	input      TokenStream
+	stemmer    SnowballProgram
+	termAtt    CharTermAttribute
+	keywordAtt KeywordAttribute
+}
+
+func NewSnowballFilter(input TokenStream, stemmer SnowballProgram) *SnowballFilter {
+	ans := &SnowballFilter{
+		TokenFilter: NewTokenFilter(input),
+		input:       input,
+		stemmer:     stemmer,
+	}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	ans.keywordAtt = ans.Attributes().Add("KeywordAttribute").(KeywordAttribute)
+	return ans
+}
+
+/*
+Construct the named stemming filter, e.g. "English". Panics if there
+is no stemmer of the given name.
+*/
+func NewSnowballFilterForName(in TokenStream, name string) *SnowballFilter {
+	stemmer, err := SnowballProgramForName(name)
+	assert2(err == nil, "Invalid stemmer class specified: %v", err)
+	return NewSnowballFilter(in, stemmer)
+}
+
+/* Returns the next input Token, after being stemmed */
+func (f *SnowballFilter) IncrementToken() (bool, error) {
+	ok, err := f.input.IncrementToken()
+	if !ok || err != nil {
+		return false, err
+	}
+	if !f.keywordAtt.IsKeyword() {
+		f.termAtt.CopyBuffer(f.stemmer.Stem(f.termAtt.Buffer()[:f.termAtt.Length()]))
+	}
+	return true, nil
+}
+
+// snowball/SnowballPorterFilterFactory.java
+
+func init() {
+	RegisterTokenFilterFactory("snowballporter", func(args map[string]string) (TokenFilterFactory, error) {
+		return NewSnowballPorterFilterFactory(args)
+	})
+}
+
+/*
+Factory for SnowballFilter, with configurable language. The
+"language" arg defaults to "English". Words given by the "protected"
+arg, as a comma separated list, are not stemmed.
+*/
+type SnowballPorterFilterFactory struct {
+	*AbstractAnalysisFactory
+	language       string
+	protectedWords map[string]bool
+}
+
+/* Creates a new SnowballPorterFilterFactory. */
+func NewSnowballPorterFilterFactory(args map[string]string) (*SnowballPorterFilterFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	language := base.Get("language", "English")
+	if _, err = SnowballProgramForName(language); err != nil {
+		return nil, err
+	}
+	protectedWords := base.GetSet("protected")
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &SnowballPorterFilterFactory{base, language, protectedWords}, nil
+}
+
+func (f *SnowballPorterFilterFactory) Create(input TokenStream) TokenStream {
+	if f.protectedWords != nil {
+		input = miscellaneous.NewSetKeywordMarkerFilter(input, f.protectedWords)
+	}
+	return NewSnowballFilterForName(input, f.language)
+}
+
+func assert2(ok bool, msg string, args ...interface{}) {
+	if !ok {
+		panic(fmt.Sprintf(msg, args...))
+	}
+}
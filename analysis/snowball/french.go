@@ -0,0 +1,322 @@
+package snowball
+
+import (
+	"unicode"
+)
+
+// org/tartarus/snowball/ext/FrenchStemmer.java
+
+func init() {
+	RegisterSnowballProgram("French", func() SnowballProgram { return FrenchStemmer{} })
+}
+
+/* The French stemming algorithm of Snowball. */
+type FrenchStemmer struct{}
+
+func isFrenchVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'y', 'â', 'à', 'ë', 'é', 'ê', 'è', 'ï', 'î', 'ô', 'û', 'ù':
+		return true
+	}
+	return false
+}
+
+func (s FrenchStemmer) Stem(word []rune) []rune {
+	w := append([]rune(nil), word...)
+	n := len(w)
+	// u or i between vowels, y next to a vowel, and u after q are put
+	// into upper case, so that they are treated as consonants
+	for i, r := range w {
+		switch r {
+		case 'u', 'i':
+			if i > 0 && i < n-1 && isFrenchVowel(w[i-1]) && isFrenchVowel(w[i+1]) ||
+				r == 'u' && i > 0 && w[i-1] == 'q' {
+				w[i] = unicode.ToUpper(r)
+			}
+		case 'y':
+			if i > 0 && isFrenchVowel(w[i-1]) || i < n-1 && isFrenchVowel(w[i+1]) {
+				w[i] = 'Y'
+			}
+		}
+	}
+
+	rv := n
+	switch {
+	case n > 2 && isFrenchVowel(w[0]) && isFrenchVowel(w[1]):
+		rv = 3
+	case hasPrefix(w, "par"), hasPrefix(w, "col"), hasPrefix(w, "tap"):
+		rv = 3
+	default:
+		for i := 1; i < n; i++ {
+			if isFrenchVowel(w[i]) {
+				rv = i + 1
+				break
+			}
+		}
+	}
+	r1 := region(w, 0, isFrenchVowel)
+	r2 := region(w, r1, isFrenchVowel)
+
+	w, ok := frenchStandardSuffix(w, rv, r1, r2)
+	if !ok {
+		if w, ok = frenchIVerbSuffix(w, rv); !ok {
+			w, ok = frenchVerbSuffix(w, rv, r2)
+		}
+	}
+	if ok {
+		// step 3
+		if n := len(w); n > 0 && w[n-1] == 'Y' {
+			w[n-1] = 'i'
+		} else if n > 0 && w[n-1] == 'ç' {
+			w[n-1] = 'c'
+		}
+	} else {
+		w = frenchResidualSuffix(w, rv, r2)
+	}
+
+	// step 5: undouble
+	if suffix := longestSuffix(w, "enn", "onn", "ett", "ell", "eill"); suffix != "" {
+		w = w[:len(w)-1]
+	}
+	// step 6: un-accent
+	i := len(w) - 1
+	for i >= 0 && !isFrenchVowel(w[i]) {
+		i--
+	}
+	if i >= 0 && i < len(w)-1 && (w[i] == 'é' || w[i] == 'è') {
+		w[i] = 'e'
+	}
+
+	for i, r := range w {
+		switch r {
+		case 'I', 'U', 'Y':
+			w[i] = unicode.ToLower(r)
+		}
+	}
+	return w
+}
+
+/*
+Step 1: standard suffix removal. Returns false if the verb suffixes
+are to be removed next, even though the word may have been changed.
+*/
+func frenchStandardSuffix(w []rune, rv, r1, r2 int) ([]rune, bool) {
+	suffix := longestSuffix(w,
+		"ance", "iqUe", "isme", "able", "iste", "eux", "ances", "iqUes", "ismes", "ables", "istes",
+		"atrice", "ateur", "ation", "atrices", "ateurs", "ations",
+		"logie", "logies", "usion", "ution", "usions", "utions", "ence", "ences",
+		"ement", "ements", "ité", "ités", "if", "ive", "ifs", "ives",
+		"eaux", "aux", "euse", "euses", "issement", "issements",
+		"amment", "emment", "ment", "ments")
+	p := start(w, suffix)
+	switch suffix {
+	case "":
+		return w, false
+	case "ance", "iqUe", "isme", "able", "iste", "eux", "ances", "iqUes", "ismes", "ables", "istes":
+		if p < r2 {
+			return w, false
+		}
+		return w[:p], true
+	case "atrice", "ateur", "ation", "atrices", "ateurs", "ations":
+		if p < r2 {
+			return w, false
+		}
+		w = w[:p]
+		if hasSuffix(w, "ic") {
+			if p-2 >= r2 {
+				w = w[:p-2]
+			} else {
+				w = replace(w, 2, "iqU")
+			}
+		}
+		return w, true
+	case "logie", "logies":
+		if p < r2 {
+			return w, false
+		}
+		return replace(w, len(w)-p, "log"), true
+	case "usion", "ution", "usions", "utions":
+		if p < r2 {
+			return w, false
+		}
+		return replace(w, len(w)-p, "u"), true
+	case "ence", "ences":
+		if p < r2 {
+			return w, false
+		}
+		return replace(w, len(w)-p, "ent"), true
+	case "ement", "ements":
+		if p < rv {
+			return w, false
+		}
+		w = w[:p]
+		switch suffix := longestSuffix(w, "iv", "eus", "abl", "iqU", "ièr", "Ièr"); suffix {
+		case "iv":
+			if p-2 >= r2 {
+				w = w[:p-2]
+				if hasSuffix(w, "at") && p-4 >= r2 {
+					w = w[:p-4]
+				}
+			}
+		case "eus":
+			if p-3 >= r2 {
+				w = w[:p-3]
+			} else if p-3 >= r1 {
+				w = replace(w, 3, "eux")
+			}
+		case "abl", "iqU":
+			if p-3 >= r2 {
+				w = w[:p-3]
+			}
+		case "ièr", "Ièr":
+			if p-3 >= rv {
+				w = replace(w, 3, "i")
+			}
+		}
+		return w, true
+	case "ité", "ités":
+		if p < r2 {
+			return w, false
+		}
+		w = w[:p]
+		switch suffix := longestSuffix(w, "abil", "ic", "iv"); suffix {
+		case "abil":
+			if p-4 >= r2 {
+				w = w[:p-4]
+			} else {
+				w = replace(w, 4, "abl")
+			}
+		case "ic":
+			if p-2 >= r2 {
+				w = w[:p-2]
+			} else {
+				w = replace(w, 2, "iqU")
+			}
+		case "iv":
+			if p-2 >= r2 {
+				w = w[:p-2]
+			}
+		}
+		return w, true
+	case "if", "ive", "ifs", "ives":
+		if p < r2 {
+			return w, false
+		}
+		w = w[:p]
+		if hasSuffix(w, "at") && p-2 >= r2 {
+			w = w[:p-2]
+			if hasSuffix(w, "ic") {
+				if p-4 >= r2 {
+					w = w[:p-4]
+				} else {
+					w = replace(w, 2, "iqU")
+				}
+			}
+		}
+		return w, true
+	case "eaux":
+		return replace(w, 4, "eau"), true
+	case "aux":
+		if p < r1 {
+			return w, false
+		}
+		return replace(w, 3, "al"), true
+	case "euse", "euses":
+		if p >= r2 {
+			return w[:p], true
+		} else if p >= r1 {
+			return replace(w, len(w)-p, "eux"), true
+		}
+		return w, false
+	case "issement", "issements":
+		if p >= r1 && !isFrenchVowel(w[p-1]) {
+			return w[:p], true
+		}
+		return w, false
+	case "amment":
+		if p >= rv {
+			w = replace(w, 6, "ant")
+		}
+		return w, false
+	case "emment":
+		if p >= rv {
+			w = replace(w, 6, "ent")
+		}
+		return w, false
+	default: // "ment", "ments"
+		if p-1 >= rv && isFrenchVowel(w[p-1]) {
+			w = w[:p]
+		}
+		return w, false
+	}
+}
+
+/* Step 2a: verb suffixes beginning with i. */
+func frenchIVerbSuffix(w []rune, rv int) ([]rune, bool) {
+	suffix := longestSuffixFrom(w, rv,
+		"îmes", "ît", "îtes", "i", "ie", "ies", "ir", "ira", "irai", "iraIent", "irais", "irait",
+		"iras", "irent", "irez", "iriez", "irions", "irons", "iront", "is", "issaIent", "issais",
+		"issait", "issant", "issante", "issantes", "issants", "isse", "issent", "isses", "issez",
+		"issiez", "issions", "issons", "it")
+	if p := start(w, suffix); suffix != "" && p-1 >= rv && !isFrenchVowel(w[p-1]) {
+		return w[:p], true
+	}
+	return w, false
+}
+
+/* Step 2b: other verb suffixes. */
+func frenchVerbSuffix(w []rune, rv, r2 int) ([]rune, bool) {
+	suffix := longestSuffixFrom(w, rv,
+		"ions",
+		"é", "ée", "ées", "és", "èrent", "er", "era", "erai", "eraIent", "erais", "erait",
+		"eras", "erez", "eriez", "erions", "erons", "eront", "ez", "iez",
+		"âmes", "ât", "âtes", "a", "ai", "aIent", "ais", "ait", "ant", "ante", "antes", "ants",
+		"as", "asse", "assent", "asses", "assiez", "assions")
+	p := start(w, suffix)
+	switch suffix {
+	case "":
+		return w, false
+	case "ions":
+		if p < r2 {
+			return w, false
+		}
+		return w[:p], true
+	case "é", "ée", "ées", "és", "èrent", "er", "era", "erai", "eraIent", "erais", "erait",
+		"eras", "erez", "eriez", "erions", "erons", "eront", "ez", "iez":
+		return w[:p], true
+	default:
+		w = w[:p]
+		if p-1 >= rv && w[p-1] == 'e' {
+			w = w[:p-1]
+		}
+		return w, true
+	}
+}
+
+/* Step 4: residual suffixes. */
+func frenchResidualSuffix(w []rune, rv, r2 int) []rune {
+	if n := len(w); n > 1 && w[n-1] == 's' {
+		switch w[n-2] {
+		case 'a', 'i', 'o', 'u', 'è', 's':
+		default:
+			w = w[:n-1]
+		}
+	}
+	suffix := longestSuffixFrom(w, rv, "ion", "ier", "ière", "Ier", "Ière", "e", "ë")
+	p := start(w, suffix)
+	switch suffix {
+	case "ion":
+		if p >= r2 && p-1 >= rv && (w[p-1] == 's' || w[p-1] == 't') {
+			w = w[:p]
+		}
+	case "ier", "ière", "Ier", "Ière":
+		w = replace(w, len(w)-p, "i")
+	case "e":
+		w = w[:p]
+	case "ë":
+		if p-2 >= rv && hasSuffix(w[:p], "gu") {
+			w = w[:p]
+		}
+	}
+	return w
+}
@@ -0,0 +1,133 @@
+package snowball
+
+import (
+	"unicode"
+)
+
+// org/tartarus/snowball/ext/GermanStemmer.java
+
+func init() {
+	RegisterSnowballProgram("German", func() SnowballProgram { return GermanStemmer{} })
+}
+
+/* The German stemming algorithm of Snowball. */
+type GermanStemmer struct{}
+
+func isGermanVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'y', 'ä', 'ö', 'ü':
+		return true
+	}
+	return false
+}
+
+func isGermanSEnding(r rune) bool {
+	switch r {
+	case 'b', 'd', 'f', 'g', 'h', 'k', 'l', 'm', 'n', 'r', 't':
+		return true
+	}
+	return false
+}
+
+func isGermanStEnding(r rune) bool {
+	return r != 'r' && isGermanSEnding(r)
+}
+
+func (s GermanStemmer) Stem(word []rune) []rune {
+	w := make([]rune, 0, len(word)+1)
+	for _, r := range word {
+		if r == 'ß' {
+			w = append(w, 's', 's')
+		} else {
+			w = append(w, r)
+		}
+	}
+	// u and y between vowels are put into upper case
+	for i := 1; i < len(w)-1; i++ {
+		if (w[i] == 'u' || w[i] == 'y') && isGermanVowel(w[i-1]) && isGermanVowel(w[i+1]) {
+			w[i] = unicode.ToUpper(w[i])
+		}
+	}
+
+	r1, r2 := len(w), len(w)
+	if len(w) >= 3 {
+		r1 = region(w, 0, isGermanVowel)
+		r2 = region(w, r1, isGermanVowel)
+		if r1 < 3 { // the region before R1 contains at least 3 letters
+			r1 = 3
+		}
+	}
+
+	// step 1
+	switch suffix := longestSuffix(w, "em", "ern", "er", "e", "en", "es", "s"); suffix {
+	case "em", "ern", "er":
+		if p := start(w, suffix); p >= r1 {
+			w = w[:p]
+		}
+	case "e", "en", "es":
+		if p := start(w, suffix); p >= r1 {
+			w = w[:p]
+			if hasSuffix(w, "niss") {
+				w = w[:len(w)-1]
+			}
+		}
+	case "s":
+		if p := start(w, suffix); p >= r1 && p > 0 && isGermanSEnding(w[p-1]) {
+			w = w[:p]
+		}
+	}
+
+	// step 2
+	switch suffix := longestSuffix(w, "en", "er", "est", "st"); suffix {
+	case "en", "er", "est":
+		if p := start(w, suffix); p >= r1 {
+			w = w[:p]
+		}
+	case "st":
+		if p := start(w, suffix); p >= r1 && p > 3 && isGermanStEnding(w[p-1]) {
+			w = w[:p]
+		}
+	}
+
+	// step 3: d-suffixes
+	suffix := longestSuffix(w, "end", "ung", "ig", "ik", "isch", "lich", "heit", "keit")
+	if p := start(w, suffix); suffix != "" && p >= r2 {
+		switch suffix {
+		case "end", "ung":
+			w = w[:p]
+			if hasSuffix(w, "ig") && p-2 >= r2 && !hasSuffix(w[:p-2], "e") {
+				w = w[:p-2]
+			}
+		case "ig", "ik", "isch":
+			if !hasSuffix(w[:p], "e") {
+				w = w[:p]
+			}
+		case "lich", "heit":
+			w = w[:p]
+			if (hasSuffix(w, "er") || hasSuffix(w, "en")) && p-2 >= r1 {
+				w = w[:p-2]
+			}
+		case "keit":
+			w = w[:p]
+			if suffix := longestSuffix(w, "lich", "ig"); suffix != "" && start(w, suffix) >= r2 {
+				w = w[:start(w, suffix)]
+			}
+		}
+	}
+
+	for i, r := range w {
+		switch r {
+		case 'U':
+			w[i] = 'u'
+		case 'Y':
+			w[i] = 'y'
+		case 'ä':
+			w[i] = 'a'
+		case 'ö':
+			w[i] = 'o'
+		case 'ü':
+			w[i] = 'u'
+		}
+	}
+	return w
+}
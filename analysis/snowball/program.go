@@ -0,0 +1,124 @@
+package snowball
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// org/tartarus/snowball/SnowballProgram.java
+
+/*
+A stemmer of the Snowball family. Lucene Java uses the code generated
+by the Snowball compiler; the stemmers of GoLucene are hand written
+after the algorithms described on http://snowball.tartarus.org, and
+share the helpers of this file instead of the among/slice machinery.
+
+Stem() returns the stem of the given word, which must be in lower
+case. The given word is never modified.
+*/
+type SnowballProgram interface {
+	Stem(word []rune) []rune
+}
+
+var allPrograms = make(map[string]func() SnowballProgram)
+
+/* Registers a stemmer by language name. Names are case insensitive. */
+func RegisterSnowballProgram(name string, ctor func() SnowballProgram) {
+	allPrograms[strings.ToLower(name)] = ctor
+}
+
+/* Returns a new stemmer of the given language, e.g. "English". */
+func SnowballProgramForName(name string) (SnowballProgram, error) {
+	ctor, ok := allPrograms[strings.ToLower(name)]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(
+			"A Snowball stemmer with name '%v' does not exist. Available stemmers: %v",
+			name, AvailableSnowballPrograms()))
+	}
+	return ctor(), nil
+}
+
+/* Returns a list of all available stemmer names, sorted. */
+func AvailableSnowballPrograms() []string {
+	names := make([]string, 0, len(allPrograms))
+	for name := range allPrograms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/* Returns true iff word ends with suffix. */
+func hasSuffix(word []rune, suffix string) bool {
+	i := len(word)
+	for len(suffix) > 0 {
+		r, size := utf8.DecodeLastRuneInString(suffix)
+		if i--; i < 0 || word[i] != r {
+			return false
+		}
+		suffix = suffix[:len(suffix)-size]
+	}
+	return true
+}
+
+/*
+Returns the longest of the suffixes that word ends with, or "" if
+none. Like the among command of Snowball, a shorter suffix is never
+tried once a longer one matched, even if the longer one is then
+rejected by its region.
+*/
+func longestSuffix(word []rune, suffixes ...string) string {
+	return longestSuffixFrom(word, 0, suffixes...)
+}
+
+/*
+Returns the longest of the suffixes that word ends with, and that
+starts at or after limit, or "" if none.
+*/
+func longestSuffixFrom(word []rune, limit int, suffixes ...string) string {
+	var ans string
+	n := 0
+	for _, suffix := range suffixes {
+		if l := utf8.RuneCountInString(suffix); l > n && len(word)-l >= limit && hasSuffix(word, suffix) {
+			ans, n = suffix, l
+		}
+	}
+	return ans
+}
+
+/* Returns the position where the given suffix of word starts. */
+func start(word []rune, suffix string) int {
+	return len(word) - utf8.RuneCountInString(suffix)
+}
+
+/* Replaces the last n runes of word with s. */
+func replace(word []rune, n int, s string) []rune {
+	return append(word[:len(word)-n], []rune(s)...)
+}
+
+/*
+Returns the start of the region after the first non-vowel following
+a vowel, looking from the given position: R1 when from is 0, and R2
+when from is R1. Returns len(word) if there is no such non-vowel.
+*/
+func region(word []rune, from int, isVowel func(rune) bool) int {
+	for i := from + 1; i < len(word); i++ {
+		if !isVowel(word[i]) && isVowel(word[i-1]) {
+			return i + 1
+		}
+	}
+	return len(word)
+}
+
+/* Returns true iff word contains a vowel. */
+func containsVowel(word []rune, isVowel func(rune) bool) bool {
+	for _, r := range word {
+		if isVowel(r) {
+			return true
+		}
+	}
+	return false
+}
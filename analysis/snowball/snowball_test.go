@@ -0,0 +1,86 @@
+package snowball
+
+import (
+	"github.com/gzg1984/golucene/analysis/core"
+	"github.com/gzg1984/golucene/analysis/miscellaneous"
+	. "github.com/gzg1984/golucene/core/analysis"
+	"github.com/gzg1984/golucene/core/util"
+	. "github.com/gzg1984/golucene/test_framework/analysis"
+	. "github.com/gzg1984/gounit"
+	"strings"
+	"testing"
+)
+
+func assertStems(t *testing.T, language string, samples [][2]string) {
+	stemmer, err := SnowballProgramForName(language)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	for _, v := range samples {
+		stem := string(stemmer.Stem([]rune(v[0])))
+		It(t).Should("expect %v -> %v in %v, got %v", v[0], v[1], language, stem).Verify(stem == v[1])
+	}
+}
+
+func TestEnglishStemmer(t *testing.T) {
+	assertStems(t, "English", [][2]string{
+		{"consign", "consign"}, {"consigned", "consign"}, {"consigning", "consign"}, {"consignment", "consign"},
+		{"consistency", "consist"}, {"generously", "generous"}, {"generalization", "general"},
+		{"running", "run"}, {"happiness", "happi"}, {"knightly", "knight"}, {"relational", "relat"},
+		{"cries", "cri"}, {"ties", "tie"}, {"gaps", "gap"}, {"gas", "gas"}, {"kiwis", "kiwi"},
+		{"hoped", "hope"}, {"agreed", "agre"}, {"fluently", "fluentli"}, {"abandoned", "abandon"},
+		{"dying", "die"}, {"skies", "sky"}, {"news", "news"}, {"succeeded", "succeed"}, {"by", "by"},
+	})
+}
+
+func TestGermanStemmer(t *testing.T) {
+	assertStems(t, "german", [][2]string{
+		{"aufeinanderfolgenden", "aufeinanderfolg"}, {"aufeinanderfolgende", "aufeinanderfolg"},
+		{"häuser", "haus"}, {"katzen", "katz"}, {"laufen", "lauf"}, {"läuft", "lauft"},
+		{"kategorischen", "kategor"}, {"kenntnisse", "kenntnis"}, {"abenteuerlichkeit", "abenteuer"},
+		{"straße", "strass"}, {"kinder", "kind"}, {"bücher", "buch"}, {"freundlich", "freundlich"},
+	})
+}
+
+func TestFrenchStemmer(t *testing.T) {
+	assertStems(t, "French", [][2]string{
+		{"majestueusement", "majestu"}, {"continuellement", "continuel"}, {"abandonnée", "abandon"},
+		{"abandonner", "abandon"}, {"nationales", "national"}, {"chevaux", "cheval"},
+		{"accompagnaient", "accompagn"}, {"action", "action"}, {"finissons", "fin"},
+		{"mangeront", "mang"}, {"heureuse", "heureux"}, {"lentement", "lent"},
+		{"généralement", "général"}, {"qualité", "qualit"},
+	})
+}
+
+func TestSpanishStemmer(t *testing.T) {
+	assertStems(t, "Spanish", [][2]string{
+		{"chicas", "chic"}, {"corriendo", "corr"}, {"nacionales", "nacional"}, {"cantaban", "cant"},
+		{"rápidamente", "rapid"}, {"comiéndoselo", "com"}, {"libros", "libr"}, {"niños", "niñ"},
+		{"felicidad", "felic"}, {"perezosamente", "perez"}, {"arqueología", "arqueolog"},
+		{"torrencial", "torrencial"},
+	})
+}
+
+func whitespace(text string) TokenStream {
+	return core.NewWhitespaceTokenizer(util.VERSION_LATEST, strings.NewReader(text))
+}
+
+func TestSnowballFilter(t *testing.T) {
+	var ts TokenStream = miscellaneous.NewSetKeywordMarkerFilter(
+		whitespace("häuser kinder"), map[string]bool{"kinder": true})
+	ts = NewSnowballFilterForName(ts, "German")
+	AssertTokenStreamContents(t, ts, []string{"haus", "kinder"}, []int{0, 7}, []int{6, 13}, nil, nil)
+}
+
+func TestSnowballPorterFilterFactory(t *testing.T) {
+	f, err := NewSnowballPorterFilterFactory(map[string]string{"language": "Spanish", "protected": "niños"})
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	AssertTokenStreamContents(t, f.Create(whitespace("chicas niños")), []string{"chic", "niños"}, nil, nil, nil, nil)
+
+	f, err = NewSnowballPorterFilterFactory(nil)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	AssertTokenStreamContents(t, f.Create(whitespace("generously")), []string{"generous"}, nil, nil, nil, nil)
+
+	_, err = NewSnowballPorterFilterFactory(map[string]string{"language": "Klingon"})
+	It(t).Should("expect an error for an unknown language").Assert(err != nil)
+	It(t).Should("expect the available stemmers in error, got %v", err).Verify(
+		strings.Contains(err.Error(), "[english french german spanish]"))
+}
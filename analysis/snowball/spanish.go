@@ -0,0 +1,223 @@
+package snowball
+
+// org/tartarus/snowball/ext/SpanishStemmer.java
+
+func init() {
+	RegisterSnowballProgram("Spanish", func() SnowballProgram { return SpanishStemmer{} })
+}
+
+/* The Spanish stemming algorithm of Snowball. */
+type SpanishStemmer struct{}
+
+func isSpanishVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'á', 'é', 'í', 'ó', 'ú', 'ü':
+		return true
+	}
+	return false
+}
+
+var spanishUnaccented = map[rune]rune{'á': 'a', 'é': 'e', 'í': 'i', 'ó': 'o', 'ú': 'u'}
+
+func (s SpanishStemmer) Stem(word []rune) []rune {
+	w := append([]rune(nil), word...)
+	n := len(w)
+
+	// RV is the region after the next vowel if the second letter is a
+	// consonant, after the next consonant if the first two letters are
+	// vowels, and after the third letter otherwise.
+	rv := n
+	if n >= 2 {
+		switch v0, v1 := isSpanishVowel(w[0]), isSpanishVowel(w[1]); {
+		case !v1:
+			rv = spanishGopast(w, 2, true)
+		case v0:
+			rv = spanishGopast(w, 2, false)
+		case n >= 3:
+			rv = 3
+		}
+	}
+	r1 := region(w, 0, isSpanishVowel)
+	r2 := region(w, r1, isSpanishVowel)
+
+	w = spanishAttachedPronoun(w, rv)
+	w, ok := spanishStandardSuffix(w, r1, r2)
+	if !ok {
+		if w, ok = spanishYVerbSuffix(w, rv); !ok {
+			w = spanishVerbSuffix(w, rv, r2)
+		}
+	}
+	w = spanishResidualSuffix(w, rv)
+
+	for i, r := range w {
+		if c, ok := spanishUnaccented[r]; ok {
+			w[i] = c
+		}
+	}
+	return w
+}
+
+/*
+Returns the position after the first vowel, or non-vowel, at or after
+from, or len(w) if there is none.
+*/
+func spanishGopast(w []rune, from int, vowel bool) int {
+	for i := from; i < len(w); i++ {
+		if isSpanishVowel(w[i]) == vowel {
+			return i + 1
+		}
+	}
+	return len(w)
+}
+
+/* Step 0: attached pronoun. */
+func spanishAttachedPronoun(w []rune, rv int) []rune {
+	suffix := longestSuffix(w, "me", "se", "sela", "selo", "selas", "selos",
+		"la", "le", "lo", "las", "les", "los", "nos")
+	if suffix == "" {
+		return w
+	}
+	stem := w[:start(w, suffix)]
+	verb := longestSuffix(stem, "iéndo", "ándo", "ár", "ér", "ír", "ando", "iendo", "ar", "er", "ir", "yendo")
+	p := start(stem, verb)
+	if verb == "" || p < rv {
+		return w
+	}
+	switch verb {
+	case "iéndo", "ándo", "ár", "ér", "ír":
+		for i := p; i < len(stem); i++ {
+			if c, ok := spanishUnaccented[stem[i]]; ok {
+				stem[i] = c
+			}
+		}
+		return stem
+	case "yendo":
+		if p > 0 && stem[p-1] == 'u' {
+			return stem
+		}
+		return w
+	default:
+		return stem
+	}
+}
+
+/* Step 1: standard suffix removal. */
+func spanishStandardSuffix(w []rune, r1, r2 int) ([]rune, bool) {
+	suffix := longestSuffix(w,
+		"anza", "anzas", "ico", "ica", "icos", "icas", "ismo", "ismos", "able", "ables", "ible", "ibles",
+		"ista", "istas", "oso", "osa", "osos", "osas", "amiento", "amientos", "imiento", "imientos",
+		"adora", "ador", "ación", "adoras", "adores", "aciones", "ante", "antes", "ancia", "ancias",
+		"logía", "logías", "ución", "uciones", "encia", "encias",
+		"amente", "mente", "idad", "idades", "iva", "ivo", "ivas", "ivos")
+	p := start(w, suffix)
+	switch suffix {
+	case "":
+		return w, false
+	case "amente":
+		if p < r1 {
+			return w, false
+		}
+		w = w[:p]
+		switch suffix := longestSuffix(w, "iv", "os", "ic", "ad"); suffix {
+		case "iv":
+			if p-2 >= r2 {
+				w = w[:p-2]
+				if hasSuffix(w, "at") && p-4 >= r2 {
+					w = w[:p-4]
+				}
+			}
+		case "os", "ic", "ad":
+			if p-2 >= r2 {
+				w = w[:p-2]
+			}
+		}
+		return w, true
+	}
+
+	// all other suffixes are in R2
+	if p < r2 {
+		return w, false
+	}
+	switch suffix {
+	case "adora", "ador", "ación", "adoras", "adores", "aciones", "ante", "antes", "ancia", "ancias":
+		w = w[:p]
+		if hasSuffix(w, "ic") && p-2 >= r2 {
+			w = w[:p-2]
+		}
+	case "logía", "logías":
+		w = replace(w, len(w)-p, "log")
+	case "ución", "uciones":
+		w = replace(w, len(w)-p, "u")
+	case "encia", "encias":
+		w = replace(w, len(w)-p, "ente")
+	case "mente":
+		w = w[:p]
+		if suffix := longestSuffix(w, "ante", "able", "ible"); suffix != "" && p-4 >= r2 {
+			w = w[:p-4]
+		}
+	case "idad", "idades":
+		w = w[:p]
+		if suffix := longestSuffix(w, "abil", "ic", "iv"); suffix != "" && start(w, suffix) >= r2 {
+			w = w[:start(w, suffix)]
+		}
+	case "iva", "ivo", "ivas", "ivos":
+		w = w[:p]
+		if hasSuffix(w, "at") && p-2 >= r2 {
+			w = w[:p-2]
+		}
+	default:
+		w = w[:p]
+	}
+	return w, true
+}
+
+/* Step 2a: verb suffixes beginning with y. */
+func spanishYVerbSuffix(w []rune, rv int) ([]rune, bool) {
+	suffix := longestSuffixFrom(w, rv,
+		"ya", "ye", "yan", "yen", "yeron", "yendo", "yo", "yó", "yas", "yes", "yais", "yamos")
+	if p := start(w, suffix); suffix != "" && p > 0 && w[p-1] == 'u' {
+		return w[:p], true
+	}
+	return w, false
+}
+
+/* Step 2b: other verb suffixes. */
+func spanishVerbSuffix(w []rune, rv, r2 int) []rune {
+	suffix := longestSuffixFrom(w, rv,
+		"en", "es", "éis", "emos",
+		"arían", "arías", "arán", "arás", "aríais", "aría", "aréis", "aríamos", "aremos", "ará", "aré",
+		"erían", "erías", "erán", "erás", "eríais", "ería", "eréis", "eríamos", "eremos", "erá", "eré",
+		"irían", "irías", "irán", "irás", "iríais", "iría", "iréis", "iríamos", "iremos", "irá", "iré",
+		"aba", "ada", "ida", "ía", "ara", "iera", "ad", "ed", "id", "ase", "iese", "aste", "iste",
+		"an", "aban", "ían", "aran", "ieran", "asen", "iesen", "aron", "ieron", "ado", "ido",
+		"ando", "iendo", "ió", "ar", "er", "ir", "as", "abas", "adas", "idas", "ías", "aras",
+		"ieras", "ases", "ieses", "ís", "áis", "abais", "íais", "arais", "ierais", "aseis",
+		"ieseis", "asteis", "isteis", "ados", "idos", "amos", "ábamos", "íamos", "imos",
+		"áramos", "iéramos", "iésemos", "ásemos")
+	p := start(w, suffix)
+	switch suffix {
+	case "":
+	case "en", "es", "éis", "emos":
+		w = w[:p]
+		if hasSuffix(w, "gu") {
+			w = w[:p-1]
+		}
+	default:
+		w = w[:p]
+	}
+	return w
+}
+
+/* Step 3: residual suffix. */
+func spanishResidualSuffix(w []rune, rv int) []rune {
+	suffix := longestSuffix(w, "os", "a", "o", "á", "í", "ó", "e", "é")
+	p := start(w, suffix)
+	if suffix == "" || p < rv {
+		return w
+	}
+	w = w[:p]
+	if (suffix == "e" || suffix == "é") && hasSuffix(w, "gu") && p-1 >= rv {
+		w = w[:p-1]
+	}
+	return w
+}
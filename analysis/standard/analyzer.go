@@ -48,9 +48,9 @@ func NewStandardAnalyzer() *StandardAnalyzer {
 
 func (a *StandardAnalyzer) CreateComponents(fieldName string, reader io.RuneReader) *TokenStreamComponents {
 	version := a.Version()
-	src := newStandardTokenizer(version, reader)
+	src := NewStandardTokenizer(version, reader)
 	src.maxTokenLength = a.maxTokenLength
-	var tok TokenStream = newStandardFilter(version, src)
+	var tok TokenStream = NewStandardFilter(version, src)
 	tok = NewLowerCaseFilter(version, tok)
 	tok = NewStopFilter(version, tok, a.stopWordSet)
 	ans := NewTokenStreamComponents(src, tok)
@@ -0,0 +1,69 @@
+package standard
+
+import (
+	. "github.com/gzg1984/golucene/analysis/util"
+	. "github.com/gzg1984/golucene/core/analysis"
+	"io"
+)
+
+// standard/StandardTokenizerFactory.java
+
+func init() {
+	RegisterTokenizerFactory("standard", func(args map[string]string) (TokenizerFactory, error) {
+		return NewStandardTokenizerFactory(args)
+	})
+	RegisterTokenFilterFactory("standard", func(args map[string]string) (TokenFilterFactory, error) {
+		return NewStandardFilterFactory(args)
+	})
+}
+
+/* Factory for StandardTokenizer. */
+type StandardTokenizerFactory struct {
+	*AbstractAnalysisFactory
+	maxTokenLength int
+}
+
+/* Creates a new StandardTokenizerFactory. */
+func NewStandardTokenizerFactory(args map[string]string) (*StandardTokenizerFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	maxTokenLength, err := base.GetInt("maxTokenLength", DEFAULT_MAX_TOKEN_LENGTH)
+	if err != nil {
+		return nil, err
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &StandardTokenizerFactory{base, maxTokenLength}, nil
+}
+
+func (f *StandardTokenizerFactory) Create(input io.RuneReader) TokenizerService {
+	ans := NewStandardTokenizer(f.LuceneMatchVersion, input)
+	ans.maxTokenLength = f.maxTokenLength
+	return ans
+}
+
+// standard/StandardFilterFactory.java
+
+/* Factory for StandardFilter. */
+type StandardFilterFactory struct {
+	*AbstractAnalysisFactory
+}
+
+/* Creates a new StandardFilterFactory. */
+func NewStandardFilterFactory(args map[string]string) (*StandardFilterFactory, error) {
+	base, err := NewAbstractAnalysisFactory(args)
+	if err != nil {
+		return nil, err
+	}
+	if err = base.UnknownArgs(); err != nil {
+		return nil, err
+	}
+	return &StandardFilterFactory{base}, nil
+}
+
+func (f *StandardFilterFactory) Create(input TokenStream) TokenStream {
+	return NewStandardFilter(f.LuceneMatchVersion, input)
+}
@@ -14,7 +14,8 @@ type StandardFilter struct {
 	input        TokenStream
 }
 
-func newStandardFilter(matchVersion util.Version, in TokenStream) *StandardFilter {
+/* Construct filtering in. */
+func NewStandardFilter(matchVersion util.Version, in TokenStream) *StandardFilter {
 	return &StandardFilter{
 		TokenFilter:  NewTokenFilter(in),
 		matchVersion: matchVersion,
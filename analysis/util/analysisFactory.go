@@ -0,0 +1,270 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	. "github.com/gzg1984/golucene/core/analysis"
+	"github.com/gzg1984/golucene/core/util"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// util/AbstractAnalysisFactory.java
+
+/* The name of the argument of the version to match. */
+const LUCENE_MATCH_VERSION_PARAM = "luceneMatchVersion"
+
+/*
+Abstract parent class for analysis factories TokenizerFactory and
+TokenFilterFactory.
+
+The typical lifecycle for a factory consumer is to create the factory
+via its constructor, or via XXXFactoryForName(), then call Create() as
+many times as needed.
+
+A factory consumes the arguments it knows while being constructed,
+and must return UnknownArgs() as error if any are left.
+*/
+type AbstractAnalysisFactory struct {
+	// The original args, before any processing
+	originalArgs map[string]string
+	// the luceneVersion arg
+	LuceneMatchVersion util.Version
+	// the args not consumed yet
+	args map[string]string
+}
+
+/* Initialize this factory via a set of key-value pairs. */
+func NewAbstractAnalysisFactory(args map[string]string) (*AbstractAnalysisFactory, error) {
+	ans := &AbstractAnalysisFactory{
+		originalArgs:       make(map[string]string),
+		LuceneMatchVersion: util.VERSION_LATEST,
+		args:               make(map[string]string),
+	}
+	for k, v := range args {
+		ans.originalArgs[k] = v
+		ans.args[k] = v
+	}
+	if v, ok := ans.args[LUCENE_MATCH_VERSION_PARAM]; ok {
+		delete(ans.args, LUCENE_MATCH_VERSION_PARAM)
+		var err error
+		if ans.LuceneMatchVersion, err = util.ParseVersion(v); err != nil {
+			return nil, err
+		}
+	}
+	return ans, nil
+}
+
+/* Returns the original args, before any processing. */
+func (f *AbstractAnalysisFactory) OriginalArgs() map[string]string {
+	return f.originalArgs
+}
+
+/* Returns an error listing the args not consumed, if any. */
+func (f *AbstractAnalysisFactory) UnknownArgs() error {
+	if len(f.args) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(f.args))
+	for name := range f.args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return errors.New(fmt.Sprintf("Unknown parameters: %v", names))
+}
+
+/* Consumes the arg of the given name, or returns an error if it's missing. */
+func (f *AbstractAnalysisFactory) Require(name string) (string, error) {
+	v, ok := f.args[name]
+	if !ok {
+		return "", errors.New(fmt.Sprintf("Configuration Error: missing parameter '%v'", name))
+	}
+	delete(f.args, name)
+	return v, nil
+}
+
+/* Consumes the arg of the given name, or returns defaultVal if it's missing. */
+func (f *AbstractAnalysisFactory) Get(name, defaultVal string) string {
+	v, ok := f.args[name]
+	if !ok {
+		return defaultVal
+	}
+	delete(f.args, name)
+	return v
+}
+
+/* Consumes the int arg of the given name, which must exist. */
+func (f *AbstractAnalysisFactory) RequireInt(name string) (int, error) {
+	s, err := f.Require(name)
+	if err != nil {
+		return 0, err
+	}
+	return f.parseInt(name, s)
+}
+
+/* Consumes the int arg of the given name, or returns defaultVal if it's missing. */
+func (f *AbstractAnalysisFactory) GetInt(name string, defaultVal int) (int, error) {
+	s, ok := f.args[name]
+	if !ok {
+		return defaultVal, nil
+	}
+	delete(f.args, name)
+	return f.parseInt(name, s)
+}
+
+func (f *AbstractAnalysisFactory) parseInt(name, s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("Configuration Error: '%v' must be an integer, got '%v'", name, s))
+	}
+	return n, nil
+}
+
+/* Consumes the bool arg of the given name, or returns defaultVal if it's missing. */
+func (f *AbstractAnalysisFactory) GetBool(name string, defaultVal bool) (bool, error) {
+	s, ok := f.args[name]
+	if !ok {
+		return defaultVal, nil
+	}
+	delete(f.args, name)
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, errors.New(fmt.Sprintf("Configuration Error: '%v' must be true or false, got '%v'", name, s))
+	}
+	return b, nil
+}
+
+/*
+Consumes the arg of the given name as a comma separated list of
+words, or returns nil if it's missing. Lucene Java reads word lists
+from resource files instead.
+*/
+func (f *AbstractAnalysisFactory) GetSet(name string) map[string]bool {
+	s, ok := f.args[name]
+	if !ok {
+		return nil
+	}
+	delete(f.args, name)
+	set := make(map[string]bool)
+	for _, word := range strings.Split(s, ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			set[word] = true
+		}
+	}
+	return set
+}
+
+/* Consumes the regular expression arg of the given name, or returns nil if it's missing. */
+func (f *AbstractAnalysisFactory) GetPattern(name string) (*regexp.Regexp, error) {
+	s, ok := f.args[name]
+	if !ok {
+		return nil, nil
+	}
+	delete(f.args, name)
+	p, err := regexp.Compile(s)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Configuration Error: '%v' can not be parsed: %v", name, err))
+	}
+	return p, nil
+}
+
+// util/TokenizerFactory.java
+
+/* A Tokenizer, as created by a TokenizerFactory. */
+type TokenizerService interface {
+	TokenStream
+	SetReader(io.RuneReader) error
+}
+
+/* Abstract parent class for analysis factories that create Tokenizer instances. */
+type TokenizerFactory interface {
+	// Creates a TokenStream of the specified input
+	Create(input io.RuneReader) TokenizerService
+}
+
+var allTokenizers = make(map[string]func(map[string]string) (TokenizerFactory, error))
+
+/*
+Registers the constructor of a TokenizerFactory by name, to work
+around Lucene Java's SPI mechanism. Names are case insensitive.
+*/
+func RegisterTokenizerFactory(name string, ctor func(args map[string]string) (TokenizerFactory, error)) {
+	allTokenizers[strings.ToLower(name)] = ctor
+}
+
+/* Looks up a tokenizer by name, and creates its factory from args. */
+func TokenizerFactoryForName(name string, args map[string]string) (TokenizerFactory, error) {
+	ctor, ok := allTokenizers[strings.ToLower(name)]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(
+			"A TokenizerFactory with name '%v' does not exist. Available TokenizerFactories: %v",
+			name, AvailableTokenizers()))
+	}
+	f, err := ctor(args)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+/* Returns a list of all available tokenizer names, sorted. */
+func AvailableTokenizers() []string {
+	names := make([]string, 0, len(allTokenizers))
+	for name := range allTokenizers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// util/TokenFilterFactory.java
+
+/* Abstract parent class for analysis factories that create TokenFilter instances. */
+type TokenFilterFactory interface {
+	// Transform the specified input TokenStream
+	Create(input TokenStream) TokenStream
+}
+
+var allTokenFilters = make(map[string]func(map[string]string) (TokenFilterFactory, error))
+
+/*
+Registers the constructor of a TokenFilterFactory by name, to work
+around Lucene Java's SPI mechanism. Names are case insensitive.
+*/
+func RegisterTokenFilterFactory(name string, ctor func(args map[string]string) (TokenFilterFactory, error)) {
+	allTokenFilters[strings.ToLower(name)] = ctor
+}
+
+/* Looks up a token filter by name, and creates its factory from args. */
+func TokenFilterFactoryForName(name string, args map[string]string) (TokenFilterFactory, error) {
+	ctor, ok := allTokenFilters[strings.ToLower(name)]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf(
+			"A TokenFilterFactory with name '%v' does not exist. Available TokenFilterFactories: %v",
+			name, AvailableTokenFilters()))
+	}
+	f, err := ctor(args)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+/* Returns a list of all available token filter names, sorted. */
+func AvailableTokenFilters() []string {
+	names := make([]string, 0, len(allTokenFilters))
+	for name := range allTokenFilters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func assert(ok bool) {
+	if !ok {
+		panic("assert fail")
+	}
+}
@@ -0,0 +1,116 @@
+package util
+
+import (
+	. "github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"github.com/gzg1984/golucene/core/util"
+	"io"
+)
+
+// util/CharTokenizer.java
+
+/* Maximum length of a token; longer tokens are split. */
+const MAX_WORD_LEN = 255
+
+type CharTokenizerSPI interface {
+	// Returns true iff a codepoint should be included in a token. This
+	// tokenizer generates as tokens adjacent sequences of codepoints
+	// which satisfy this predicate. Codepoints for which this is false
+	// are used to define token boundaries and are not included in
+	// tokens.
+	IsTokenChar(c rune) bool
+	// Called on each token character to normalize it before it is added
+	// to the token. The default implementation does nothing. Subclasses
+	// may use this to, e.g., lowercase tokens.
+	Normalize(c rune) rune
+}
+
+/*
+An abstract base class for simple, character-oriented tokenizers.
+Subclasses must implement IsTokenChar(), and may override Normalize().
+
+Offsets are counted in runes of the input.
+*/
+type CharTokenizer struct {
+	*Tokenizer
+	spi         CharTokenizerSPI
+	offset      int
+	finalOffset int
+	termAtt     CharTermAttribute
+	offsetAtt   OffsetAttribute
+}
+
+/* Creates a new CharTokenizer instance. */
+func NewCharTokenizer(spi CharTokenizerSPI,
+	matchVersion util.Version, input io.RuneReader) *CharTokenizer {
+
+	ans := &CharTokenizer{
+		Tokenizer: NewTokenizer(input),
+		spi:       spi,
+	}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	ans.offsetAtt = ans.Attributes().Add("OffsetAttribute").(OffsetAttribute)
+	return ans
+}
+
+func (t *CharTokenizer) Normalize(c rune) rune {
+	return c
+}
+
+func (t *CharTokenizer) IncrementToken() (bool, error) {
+	t.Attributes().Clear()
+	length, start := 0, -1 // this variable is always initialized
+	buffer := t.termAtt.Buffer()
+	for {
+		c, _, err := t.Input.ReadRune()
+		if err == io.EOF {
+			if length > 0 {
+				break
+			}
+			t.finalOffset = t.CorrectOffset(t.offset)
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		t.offset++
+
+		if t.spi.IsTokenChar(c) { // if it's a token char
+			if length == 0 { // start of token
+				assert(start == -1)
+				start = t.offset - 1
+			} else if length >= len(buffer) {
+				buffer = t.termAtt.ResizeBuffer(length + 1)
+			}
+			buffer[length] = t.spi.Normalize(c) // buffer it, normalized
+			length++
+			if length >= MAX_WORD_LEN { // buffer overflow!
+				break
+			}
+		} else if length > 0 { // at non-Letter w/ chars
+			break // return 'em
+		}
+	}
+
+	t.termAtt.SetLength(length)
+	assert(start != -1)
+	t.finalOffset = t.CorrectOffset(start + length)
+	t.offsetAtt.SetOffset(t.CorrectOffset(start), t.finalOffset)
+	return true, nil
+}
+
+func (t *CharTokenizer) End() error {
+	if err := t.Tokenizer.End(); err != nil {
+		return err
+	}
+	// set final offset
+	t.offsetAtt.SetOffset(t.finalOffset, t.finalOffset)
+	return nil
+}
+
+func (t *CharTokenizer) Reset() error {
+	if err := t.Tokenizer.Reset(); err != nil {
+		return err
+	}
+	t.offset, t.finalOffset = 0, 0
+	return nil
+}
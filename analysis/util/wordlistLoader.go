@@ -0,0 +1,26 @@
+package util
+
+import (
+	"strings"
+)
+
+// util/WordlistLoader.java
+
+/*
+Parses stopwords in the Snowball format: several words may be on a
+single line, separated by whitespace, and the character '|' starts a
+comment that runs to the end of the line. Lucene Java reads them from
+a file; GoLucene keeps the built-in word lists in the source.
+*/
+func SnowballWordSet(text string) map[string]bool {
+	ans := make(map[string]bool)
+	for _, line := range strings.Split(text, "\n") {
+		if i := strings.IndexRune(line, '|'); i >= 0 {
+			line = line[:i]
+		}
+		for _, word := range strings.Fields(line) {
+			ans[word] = true
+		}
+	}
+	return ans
+}
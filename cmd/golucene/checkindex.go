@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	"io"
+	"strings"
+	"time"
+)
+
+// How long to wait before fixing the index, so that the user can
+// still interrupt.
+var fixDelay = 5 * time.Second
+
+var errNotClean = errors.New("problems were detected with the index")
+
+/* The segments of repeated -segment flags. */
+type segmentsFlag []string
+
+func (f *segmentsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *segmentsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func runCheckIndex(args []string, out io.Writer) error {
+	fs := newFlagSet("checkindex", "<index dir>")
+	fix := fs.Bool("fix", false,
+		"actually write a new segments_N file, removing any problematic segments; "+
+			"the documents of these segments are permanently lost, so always make a backup "+
+			"copy of your index first, and never run this on an index being written to")
+	crossCheck := fs.Bool("crossCheckTermVectors", false,
+		"verify that term vectors match postings; THIS IS VERY SLOW!")
+	var segments segmentsFlag
+	fs.Var(&segments, "segment",
+		"only check the specified segment; can be repeated, e.g. '-segment _2 -segment _a'; can't be used with -fix")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	if *fix && len(segments) > 0 {
+		return errors.New("cannot specify both -fix and -segment")
+	}
+
+	dir, err := openIndex(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	fmt.Fprintf(out, "\nOpening index @ %v\n\n", fs.Arg(0))
+	checker := index.NewCheckIndex(dir, *crossCheck, out)
+	var onlySegments []string
+	if len(segments) > 0 {
+		onlySegments = segments
+	}
+	status, err := checker.CheckIndex(onlySegments)
+	if err != nil {
+		return err
+	}
+	if status.MissingSegments {
+		return errNotClean
+	}
+	if status.Clean {
+		return nil
+	}
+
+	if !*fix {
+		fmt.Fprintf(out, "WARNING: would write new segments file, and %v documents would be lost, if -fix were specified\n\n",
+			status.TotLoseDocCount)
+		return errNotClean
+	}
+	fmt.Fprintf(out, "WARNING: %v documents will be lost\n\n", status.TotLoseDocCount)
+	fmt.Fprintf(out, "NOTE: will write new segments file in %v; this will remove %v docs from the index. YOU WILL LOSE DATA. THIS IS YOUR LAST CHANCE TO CTRL+C!\n",
+		fixDelay, status.TotLoseDocCount)
+	time.Sleep(fixDelay)
+	fmt.Fprintln(out, "Writing...")
+	return checker.FixIndex(status)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	"io"
+	"strconv"
+)
+
+func runDoc(args []string, out io.Writer) error {
+	fs := newFlagSet("doc", "<index dir> <doc id>")
+	if err := parseFlags(fs, args, 2); err != nil {
+		return err
+	}
+	docID, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		return fmt.Errorf("invalid doc id %q", fs.Arg(1))
+	}
+
+	dir, reader, err := openReader(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	defer reader.Close()
+
+	if docID < 0 || docID >= reader.MaxDoc() {
+		return fmt.Errorf("doc %v is out of bounds (maxDoc=%v)", docID, reader.MaxDoc())
+	}
+	leaves := reader.Leaves()
+	leaf := leaves[index.SubIndex(docID, leaves)]
+	if liveDocs := leaf.Reader().(index.AtomicReader).LiveDocs(); liveDocs != nil && !liveDocs.At(docID-leaf.DocBase) {
+		fmt.Fprintf(out, "doc %v is deleted\n", docID)
+	}
+
+	doc, err := reader.Document(docID)
+	if err != nil {
+		return err
+	}
+	if len(doc.Fields()) == 0 {
+		fmt.Fprintf(out, "doc %v has no stored fields\n", docID)
+	}
+	for _, field := range doc.Fields() {
+		if value := field.BinaryValue(); value != nil {
+			fmt.Fprintf(out, "%v: [% x]\n", field.Name(), value)
+		} else {
+			fmt.Fprintf(out, "%v: %v\n", field.Name(), field.StringValue())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+Command golucene inspects and checks indexes from the command line.
+
+Usage:
+
+	golucene <command> [flags] <index dir> [arguments]
+
+The commands are:
+
+	checkindex  check the index for corruption, and optionally fix it
+	segments    list the segments of the latest commit
+	terms       dump the terms of a field with their doc freqs
+	doc         print the stored fields of a document
+	search      search with a query parsed by the classic query parser
+
+Run "golucene <command> -h" for the flags of each command.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	_ "github.com/gzg1984/golucene/core/codec/lucene71"
+	"github.com/gzg1984/golucene/core/index"
+	"github.com/gzg1984/golucene/core/search"
+	"github.com/gzg1984/golucene/core/store"
+	"io"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, out io.Writer) error
+}
+
+var commands = []*command{
+	{"checkindex", "check the index for corruption, and optionally fix it", runCheckIndex},
+	{"segments", "list the segments of the latest commit", runSegments},
+	{"terms", "dump the terms of a field with their doc freqs", runTerms},
+	{"doc", "print the stored fields of a document", runDoc},
+	{"search", "search with a query parsed by the classic query parser", runSearch},
+}
+
+// Returned when the arguments don't match the usage of a command,
+// after the usage is printed.
+var errUsage = errors.New("invalid arguments")
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: golucene <command> [flags] <index dir> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "The commands are:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-10v  %v\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, `Run "golucene <command> -h" for the flags of each command.`)
+}
+
+func main() {
+	index.DefaultSimilarity = func() index.Similarity {
+		return search.NewDefaultSimilarity()
+	}
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+		switch err := cmd.run(os.Args[2:], os.Stdout); err {
+		case nil:
+			os.Exit(0)
+		case flag.ErrHelp, errUsage:
+			os.Exit(2)
+		default:
+			fmt.Fprintf(os.Stderr, "golucene %v: %v\n", cmd.name, err)
+			os.Exit(1)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "golucene: unknown command %q\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+/* Returns the flag set of a command, printing its usage on errors. */
+func newFlagSet(name, arguments string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: golucene %v [flags] %v\n", name, arguments)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+/* Parses the flags of a command, which takes nArgs arguments at least. */
+func parseFlags(fs *flag.FlagSet, args []string, nArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < nArgs {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+/* Opens the directory of an existing index. */
+func openIndex(path string) (store.Directory, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	} else if !fi.IsDir() {
+		return nil, fmt.Errorf("%v is not a directory", path)
+	}
+	dir, err := store.OpenFSDirectory(path)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := index.IsIndexExists(dir)
+	if err == nil && !ok {
+		err = fmt.Errorf("no index found in %v", path)
+	}
+	if err != nil {
+		dir.Close()
+		return nil, err
+	}
+	return dir, nil
+}
+
+/* Opens a reader of the latest commit of the index in path. */
+func openReader(path string) (store.Directory, index.IndexReader, error) {
+	dir, err := openIndex(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, err := index.OpenDirectoryReader(dir)
+	if err != nil {
+		dir.Close()
+		return nil, nil, err
+	}
+	return dir, reader, nil
+}
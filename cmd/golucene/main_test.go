@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	std "github.com/gzg1984/golucene/analysis/standard"
+	docu "github.com/gzg1984/golucene/core/document"
+	"github.com/gzg1984/golucene/core/index"
+	"github.com/gzg1984/golucene/core/search"
+	"github.com/gzg1984/golucene/core/store"
+	"github.com/gzg1984/golucene/core/util"
+	. "github.com/gzg1984/gounit"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/*
+Indexes 10 documents in 2 segments, and deletes doc2 of the first
+segment. The body of doc i is red, green or blue for i%3.
+*/
+func newTestIndex(t *testing.T) string {
+	index.DefaultSimilarity = func() index.Similarity {
+		return search.NewDefaultSimilarity()
+	}
+
+	path := t.TempDir()
+	directory, err := store.OpenFSDirectory(path)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	colors := []string{"red", "green", "blue"}
+	for i := 0; i < 10; i++ {
+		d := docu.NewDocument()
+		d.Add(docu.NewFieldFromString("id", fmt.Sprintf("doc%v", i), docu.STRING_FIELD_TYPE_STORED))
+		d.Add(docu.NewTextFieldFromString("contents", fmt.Sprintf("the quick %v fox", colors[i%3]), docu.STORE_YES))
+		err = writer.AddDocument(d.Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		if i == 4 {
+			err = writer.Commit()
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+		}
+	}
+	err = writer.DeleteDocuments(index.NewTerm("id", "doc2"))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	return path
+}
+
+func runCommand(run func([]string, io.Writer) error, args ...string) (string, error) {
+	var buf bytes.Buffer
+	err := run(args, &buf)
+	return buf.String(), err
+}
+
+func contains(t *testing.T, out string, expected ...string) {
+	for _, s := range expected {
+		It(t).Should("expect %q in:\n%v", s, out).Verify(strings.Contains(out, s))
+	}
+}
+
+func TestSegments(t *testing.T) {
+	path := newTestIndex(t)
+	out, err := runCommand(runSegments, path)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	contains(t, out, "segments_", ": 2 segments", "docCount=5 delCount=1 numDocs=4",
+		"docCount=5 delCount=0 numDocs=5", "codec=Lucene", "source = flush", ".si ")
+}
+
+func TestTerms(t *testing.T) {
+	path := newTestIndex(t)
+	out, err := runCommand(runTerms, path, "contents")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect terms in order, got:\n%v", out).Verify(
+		out == "blue\t3\nfox\t10\ngreen\t3\nquick\t10\nred\t4\n")
+
+	out, err = runCommand(runTerms, "-prefix", "gr", "-n", "1", path, "contents")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect only green, got:\n%v", out).Verify(out == "green\t3\n")
+
+	out, err = runCommand(runTerms, path, "missing")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	contains(t, out, "no terms in field missing")
+}
+
+func TestDoc(t *testing.T) {
+	path := newTestIndex(t)
+	out, err := runCommand(runDoc, path, "6")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect the stored fields of doc6, got:\n%v", out).Verify(
+		out == "id: doc6\ncontents: the quick red fox\n")
+
+	out, err = runCommand(runDoc, path, "2")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	contains(t, out, "doc 2 is deleted", "id: doc2")
+
+	_, err = runCommand(runDoc, path, "10")
+	It(t).Should("expect doc 10 to be out of bounds").Verify(err != nil)
+	_, err = runCommand(runDoc, path, "x")
+	It(t).Should("expect an invalid doc id").Verify(err != nil)
+}
+
+func TestSearch(t *testing.T) {
+	path := newTestIndex(t)
+	out, err := runCommand(runSearch, "-show", "id", path, "red", "gr*")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	contains(t, out, "Query: red gr*", "Found 7 hit(s).", "id: doc0", "id: doc9", "id: doc7", "weight(contents:red in ")
+	It(t).Should("expect deleted doc2 not to be found, got:\n%v", out).Verify(!strings.Contains(out, "id: doc2"))
+
+	out, err = runCommand(runSearch, "-explain=false", "-n", "1", "-field", "id", path, "doc*")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	contains(t, out, "Query: doc*", "Found 9 hit(s).", "1. doc=")
+	It(t).Should("expect a single hit without explanation, got:\n%v", out).Verify(
+		!strings.Contains(out, "2. doc=") && !strings.Contains(out, "weight("))
+
+	_, err = runCommand(runSearch, path, "red\"")
+	It(t).Should("expect a parse error").Verify(err != nil)
+	_, err = runCommand(runSearch, path, "(red")
+	It(t).Should("expect an unclosed group to be an error").Verify(err != nil)
+}
+
+func TestCheckIndexCommand(t *testing.T) {
+	path := newTestIndex(t)
+	out, err := runCommand(runCheckIndex, path)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	contains(t, out, "No problems were detected with this index.")
+
+	_, err = runCommand(runCheckIndex, "-fix", "-segment", "_0", path)
+	It(t).Should("expect -fix and -segment to be exclusive").Verify(err != nil)
+	_, err = runCommand(runCheckIndex, filepath.Join(path, "missing"))
+	It(t).Should("expect no index to be found").Verify(err != nil)
+
+	// flip a byte of the largest file of the first segment
+	names, err := filepath.Glob(filepath.Join(path, "_0.*"))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	var name string
+	var data []byte
+	for _, n := range names {
+		b, err := os.ReadFile(n)
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		if len(b) > len(data) {
+			name, data = n, b
+		}
+	}
+	data[len(data)/2] ^= 0xff
+	err = os.WriteFile(name, data, 0644)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	out, err = runCommand(runCheckIndex, "-segment", "_1", path)
+	It(t).Should("expect _1 to be clean: %v\n%v", err, out).Verify(err == nil)
+	out, err = runCommand(runCheckIndex, path)
+	It(t).Should("expect the corruption to be reported").Verify(err == errNotClean)
+	contains(t, out, "4 documents would be lost, if -fix were specified")
+
+	fixDelay = 0
+	out, err = runCommand(runCheckIndex, "-fix", path)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	contains(t, out, "Writing...", "4 documents were lost")
+	out, err = runCommand(runCheckIndex, path)
+	It(t).Should("expect the fixed index to be clean: %v\n%v", err, out).Verify(err == nil)
+}
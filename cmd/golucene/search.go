@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	std "github.com/gzg1984/golucene/analysis/standard"
+	"github.com/gzg1984/golucene/core/search"
+	"github.com/gzg1984/golucene/core/util"
+	"github.com/gzg1984/golucene/queryparser/classic"
+	"io"
+	"strings"
+)
+
+func runSearch(args []string, out io.Writer) error {
+	fs := newFlagSet("search", "<index dir> <query>")
+	field := fs.String("field", "contents", "default field of the terms of the query")
+	n := fs.Int("n", 10, "number of top hits to print")
+	explain := fs.Bool("explain", true, "print how the score of each hit is computed")
+	show := fs.String("show", "", "stored field to print with each hit")
+	if err := parseFlags(fs, args, 2); err != nil {
+		return err
+	}
+
+	dir, reader, err := openReader(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	defer reader.Close()
+
+	q, err := parseQuery(*field, strings.Join(fs.Args()[1:], " "))
+	if err != nil {
+		return err
+	}
+
+	searcher := search.NewIndexSearcher(reader)
+	topDocs, err := searcher.Search(q, nil, *n)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Query: %v\n", q.ToString(*field))
+	fmt.Fprintf(out, "Found %v hit(s).\n", topDocs.TotalHits)
+	for i, hit := range topDocs.ScoreDocs {
+		fmt.Fprintf(out, "\n%v. doc=%v score=%v\n", i+1, hit.Doc, hit.Score)
+		if *show != "" {
+			doc, err := reader.Document(hit.Doc)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "    %v: %v\n", *show, doc.Get(*show))
+		}
+		if *explain {
+			exp, err := searcher.Explain(q, hit.Doc)
+			if err != nil {
+				return err
+			}
+			for _, line := range strings.Split(strings.TrimRight(fmt.Sprint(exp), "\n"), "\n") {
+				fmt.Fprintf(out, "    %v\n", line)
+			}
+		}
+	}
+	return nil
+}
+
+/*
+Parses the query with the classic query parser. The parser panics on
+the syntax it doesn't support yet, which is reported as an error.
+*/
+func parseQuery(field, query string) (q search.Query, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			q, err = nil, fmt.Errorf("Cannot parse '%v': %v", query, r)
+		}
+	}()
+	parser := classic.NewQueryParser(util.VERSION_LATEST, field, std.NewStandardAnalyzer())
+	return parser.Parse(query)
+}
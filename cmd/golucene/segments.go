@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	"io"
+	"sort"
+)
+
+func runSegments(args []string, out io.Writer) error {
+	fs := newFlagSet("segments", "<index dir>")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+
+	dir, err := openIndex(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	sis := &index.SegmentInfos{}
+	if err = sis.ReadAll(dir); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%v: %v segments\n", sis.SegmentsFileName(), len(sis.Segments))
+	for _, info := range sis.Segments {
+		size, err := info.SizeInBytes()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\n%v: codec=%v version=%v compound=%v\n",
+			info.Info.Name, info.Info.Codec(), info.Info.Version(), info.Info.IsCompoundFile())
+		fmt.Fprintf(out, "    docCount=%v delCount=%v numDocs=%v delGen=%v\n",
+			info.Info.DocCount(), info.DelCount(), info.Info.DocCount()-info.DelCount(), info.DelGen())
+
+		if diagnostics := info.Info.Diagnostics(); len(diagnostics) > 0 {
+			fmt.Fprintln(out, "    diagnostics:")
+			keys := make([]string, 0, len(diagnostics))
+			for k := range diagnostics {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Fprintf(out, "        %v = %v\n", k, diagnostics[k])
+			}
+		}
+
+		fmt.Fprintf(out, "    files (%v bytes):\n", size)
+		files := info.Files()
+		sort.Strings(files)
+		for _, file := range files {
+			n, err := info.Info.Dir.FileLength(file)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "        %-12v %v\n", file, n)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/index/model"
+	"io"
+	"unicode/utf8"
+)
+
+func runTerms(args []string, out io.Writer) error {
+	fs := newFlagSet("terms", "<index dir> <field>")
+	limit := fs.Int("n", 0, "print at most n terms; 0 prints all of them")
+	prefix := fs.String("prefix", "", "only print the terms starting with prefix")
+	if err := parseFlags(fs, args, 2); err != nil {
+		return err
+	}
+
+	dir, reader, err := openReader(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	defer reader.Close()
+
+	count := 0
+	err = mergeTerms(reader, fs.Arg(1), []byte(*prefix), func(term []byte, docFreq int) bool {
+		fmt.Fprintf(out, "%v\t%v\n", termToString(term), docFreq)
+		count++
+		return *limit <= 0 || count < *limit
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		fmt.Fprintf(out, "no terms in field %v\n", fs.Arg(1))
+	}
+	return nil
+}
+
+/* Returns the term as text if it is, or as hex bytes otherwise. */
+func termToString(term []byte) string {
+	if utf8.Valid(term) {
+		return string(term)
+	}
+	return fmt.Sprintf("[% x]", term)
+}
+
+type leafTerms struct {
+	termsEnum TermsEnum
+	term      []byte
+}
+
+/* Queue of the terms enums of the segments, by their current term. */
+type termsQueue []*leafTerms
+
+func (q termsQueue) Len() int            { return len(q) }
+func (q termsQueue) Less(i, j int) bool  { return bytes.Compare(q[i].term, q[j].term) < 0 }
+func (q termsQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *termsQueue) Push(x interface{}) { *q = append(*q, x.(*leafTerms)) }
+
+func (q *termsQueue) Pop() interface{} {
+	n := len(*q)
+	top := (*q)[n-1]
+	*q = (*q)[:n-1]
+	return top
+}
+
+/*
+Visits the terms of field starting with prefix, in order, merged over
+all the segments of reader. The doc freq of a term is the sum of its
+doc freqs in the segments, deleted documents included. Stops when
+visit returns false.
+*/
+func mergeTerms(reader index.IndexReader, field string, prefix []byte,
+	visit func(term []byte, docFreq int) bool) error {
+
+	var queue termsQueue
+	for _, ctx := range reader.Leaves() {
+		terms := ctx.Reader().(index.AtomicReader).Terms(field)
+		if terms == nil {
+			continue
+		}
+		termsEnum := terms.Iterator(nil)
+		var term []byte
+		if len(prefix) > 0 {
+			status, err := termsEnum.SeekCeil(prefix)
+			if err != nil {
+				return err
+			} else if status == SEEK_STATUS_END {
+				continue
+			}
+			term = termsEnum.Term()
+		} else {
+			var err error
+			if term, err = termsEnum.Next(); err != nil {
+				return err
+			} else if term == nil {
+				continue
+			}
+		}
+		queue = append(queue, &leafTerms{termsEnum, term})
+	}
+	heap.Init(&queue)
+
+	for len(queue) > 0 {
+		term := append([]byte(nil), queue[0].term...)
+		if !bytes.HasPrefix(term, prefix) {
+			return nil
+		}
+		docFreq := 0
+		for len(queue) > 0 && bytes.Equal(queue[0].term, term) {
+			top := queue[0]
+			n, err := top.termsEnum.DocFreq()
+			if err != nil {
+				return err
+			}
+			docFreq += n
+			if top.term, err = top.termsEnum.Next(); err != nil {
+				return err
+			}
+			if top.term == nil {
+				heap.Pop(&queue)
+			} else {
+				heap.Fix(&queue, 0)
+			}
+		}
+		if !visit(term, docFreq) {
+			return nil
+		}
+	}
+	return nil
+}
@@ -36,10 +36,25 @@ func (f *CachingTokenFilter) IncrementToken() (bool, error) {
 	return true, nil
 }
 
-func (f *CachingTokenFilter) Reset() {
+/*
+Rewinds the iterator to the beginning of the cached list.
+
+Note that this does not call Reset() on the wrapped tokenstream ever,
+even the first time. You should Reset() the inner tokenstream before
+wrapping it with CachingTokenFilter.
+*/
+func (f *CachingTokenFilter) Reset() error {
 	if f.cache != nil {
 		f.cacheIdx = 0
 	}
+	return nil
+}
+
+func (f *CachingTokenFilter) End() error {
+	if f.finalState != nil {
+		f.Attributes().RestoreState(f.finalState)
+	}
+	return nil
 }
 
 func (f *CachingTokenFilter) fillCache() error {
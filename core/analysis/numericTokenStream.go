@@ -0,0 +1,245 @@
+package analysis
+
+import (
+	"fmt"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"github.com/gzg1984/golucene/core/util"
+)
+
+// analysis/NumericTokenStream.java
+
+/*
+Expert: This stream is used by IntField, LongField, FloatField and
+DoubleField to index numeric values for efficient range filtering
+and sorting using NumericRangeQuery and NumericRangeFilter. Values
+are indexed as trie terms at multiple precisions: each value produces
+one token per precisionStep bits, the full precision one first.
+
+Most users don't need this stream directly: IntField, LongField,
+FloatField and DoubleField create it for indexed numeric values, with
+the precisionStep of their FieldType. To index a value by hand:
+
+	stream := analysis.NewNumericTokenStreamWithStep(precisionStep).SetIntValue(value)
+
+The precisionStep must be >= 1; a value larger than the size of the
+type (e.g. math.MaxInt32) indexes only the full precision term.
+*/
+type NumericTokenStream struct {
+	*TokenStreamImpl
+	numericAtt    NumericTermAttribute
+	typeAtt       TypeAttribute
+	posIncrAtt    PositionIncrementAttribute
+	valSize       int // valSize==0 means not initialized
+	precisionStep int
+}
+
+const (
+	// The full precision token gets this token type assigned.
+	TOKEN_TYPE_FULL_PREC = "fullPrecNumeric"
+	// The lower precision tokens gets this token type assigned.
+	TOKEN_TYPE_LOWER_PREC = "lowerPrecNumeric"
+)
+
+/*
+Creates a token stream for numeric values using the default
+precisionStep NUMERIC_PRECISION_STEP_DEFAULT (16). The stream is not
+yet initialized, before using set a value using the various
+Set???Value() methods.
+*/
+func NewNumericTokenStream() *NumericTokenStream {
+	return NewNumericTokenStreamWithStep(util.NUMERIC_PRECISION_STEP_DEFAULT)
+}
+
+/*
+Creates a token stream for numeric values with the specified
+precisionStep. The stream is not yet initialized, before using set a
+value using the various Set???Value() methods.
+*/
+func NewNumericTokenStreamWithStep(precisionStep int) *NumericTokenStream {
+	assert2(precisionStep >= 1, "precisionStep must be >=1")
+	ans := &NumericTokenStream{
+		TokenStreamImpl: NewTokenStreamWithFactory(NUMERIC_ATTRIBUTE_FACTORY),
+		precisionStep:   precisionStep,
+	}
+	ans.numericAtt = ans.Attributes().Add("NumericTermAttribute").(NumericTermAttribute)
+	ans.typeAtt = ans.Attributes().Add("TypeAttribute").(TypeAttribute)
+	ans.posIncrAtt = ans.Attributes().Add("PositionIncrementAttribute").(PositionIncrementAttribute)
+	ans.numericAtt.SetShift(-precisionStep)
+	return ans
+}
+
+// Initializes the token stream with the supplied int64 value.
+func (ts *NumericTokenStream) SetLongValue(value int64) *NumericTokenStream {
+	ts.valSize = 64
+	ts.numericAtt.Init(value, ts.valSize, ts.precisionStep, -ts.precisionStep)
+	return ts
+}
+
+// Initializes the token stream with the supplied int32 value.
+func (ts *NumericTokenStream) SetIntValue(value int32) *NumericTokenStream {
+	ts.valSize = 32
+	ts.numericAtt.Init(int64(value), ts.valSize, ts.precisionStep, -ts.precisionStep)
+	return ts
+}
+
+// Initializes the token stream with the supplied float64 value.
+func (ts *NumericTokenStream) SetDoubleValue(value float64) *NumericTokenStream {
+	ts.valSize = 64
+	ts.numericAtt.Init(util.DoubleToSortableLong(value), ts.valSize, ts.precisionStep, -ts.precisionStep)
+	return ts
+}
+
+// Initializes the token stream with the supplied float32 value.
+func (ts *NumericTokenStream) SetFloatValue(value float32) *NumericTokenStream {
+	ts.valSize = 32
+	ts.numericAtt.Init(int64(util.FloatToSortableInt(value)), ts.valSize, ts.precisionStep, -ts.precisionStep)
+	return ts
+}
+
+func (ts *NumericTokenStream) Reset() error {
+	assert2(ts.valSize != 0, "call Set???Value() before usage")
+	ts.numericAtt.SetShift(-ts.precisionStep)
+	return nil
+}
+
+func (ts *NumericTokenStream) IncrementToken() (bool, error) {
+	assert2(ts.valSize != 0, "call Set???Value() before usage")
+
+	// this will only clear all other attributes in this TokenStream
+	ts.Attributes().Clear()
+
+	shift := ts.numericAtt.IncShift()
+	if shift == 0 {
+		ts.typeAtt.SetType(TOKEN_TYPE_FULL_PREC)
+		ts.posIncrAtt.SetPositionIncrement(1)
+	} else {
+		ts.typeAtt.SetType(TOKEN_TYPE_LOWER_PREC)
+		ts.posIncrAtt.SetPositionIncrement(0)
+	}
+	return shift < ts.valSize, nil
+}
+
+// Returns the precision step.
+func (ts *NumericTokenStream) PrecisionStep() int {
+	return ts.precisionStep
+}
+
+func (ts *NumericTokenStream) String() string {
+	// We override default because it can throw cryptic "illegal shift
+	// value"
+	return fmt.Sprintf("NumericTokenStream(precisionStep=%v valueSize=%v shift=%v)",
+		ts.precisionStep, ts.numericAtt.ValueSize(), ts.numericAtt.Shift())
+}
+
+/*
+Expert: Use this attribute to get the details of the currently
+generated token.
+*/
+type NumericTermAttribute interface {
+	util.Attribute
+	// Returns current shift value, undefined before first token
+	Shift() int
+	// Returns current token's raw value as int64 with all Shift()
+	// applied, undefined before first token
+	RawValue() int64
+	// Returns value size in bits (32 for float32, int32; 64 for
+	// float64, int64)
+	ValueSize() int
+	// Don't call this method!
+	Init(value int64, valSize, precisionStep, shift int)
+	// Don't call this method!
+	SetShift(shift int)
+	// Don't call this method!
+	IncShift() int
+}
+
+/*
+Implementation of NumericTermAttribute, which also encodes the
+current term as TermToBytesRefAttribute.
+*/
+type NumericTermAttributeImpl struct {
+	value                           int64
+	valueSize, shift, precisionStep int
+	bytes                           *util.BytesRefBuilder
+}
+
+func newNumericTermAttributeImpl() util.AttributeImpl {
+	return &NumericTermAttributeImpl{bytes: util.NewBytesRefBuilder()}
+}
+
+func (a *NumericTermAttributeImpl) Interfaces() []string {
+	return []string{"NumericTermAttribute", "TermToBytesRefAttribute"}
+}
+
+func (a *NumericTermAttributeImpl) BytesRef() *util.BytesRef {
+	return a.bytes.Get()
+}
+
+func (a *NumericTermAttributeImpl) FillBytesRef() {
+	assert2(a.valueSize == 64 || a.valueSize == 32, "valueSize must be 32 or 64")
+	if a.valueSize == 64 {
+		util.LongToPrefixCoded(a.value, a.shift, a.bytes)
+	} else {
+		util.IntToPrefixCoded(int32(a.value), a.shift, a.bytes)
+	}
+}
+
+func (a *NumericTermAttributeImpl) Shift() int         { return a.shift }
+func (a *NumericTermAttributeImpl) SetShift(shift int) { a.shift = shift }
+
+func (a *NumericTermAttributeImpl) IncShift() int {
+	a.shift += a.precisionStep
+	return a.shift
+}
+
+func (a *NumericTermAttributeImpl) RawValue() int64 {
+	return a.value &^ ((int64(1) << uint(a.shift)) - 1)
+}
+
+func (a *NumericTermAttributeImpl) ValueSize() int { return a.valueSize }
+
+func (a *NumericTermAttributeImpl) Init(value int64, valueSize, precisionStep, shift int) {
+	a.value = value
+	a.valueSize = valueSize
+	a.precisionStep = precisionStep
+	a.shift = shift
+}
+
+func (a *NumericTermAttributeImpl) Clear() {
+	// this attribute has no contents to clear! we keep it untouched as
+	// it's fully controlled by outer class.
+}
+
+func (a *NumericTermAttributeImpl) Clone() util.AttributeImpl {
+	ans := newNumericTermAttributeImpl().(*NumericTermAttributeImpl)
+	a.CopyTo(ans)
+	return ans
+}
+
+func (a *NumericTermAttributeImpl) CopyTo(target util.AttributeImpl) {
+	target.(NumericTermAttribute).Init(a.value, a.valueSize, a.precisionStep, a.shift)
+}
+
+// Refuses CharTermAttribute, which would break the numeric encoding.
+type numericDelegateAttributeFactory struct {
+	delegate util.AttributeFactory
+}
+
+func (f *numericDelegateAttributeFactory) Create(name string) util.AttributeImpl {
+	assert2(name != "CharTermAttribute", "NumericTokenStream does not support CharTermAttribute.")
+	return f.delegate.Create(name)
+}
+
+/*
+AttributeFactory used by NumericTokenStream: it creates
+NumericTermAttributeImpl for the numeric and term attributes, and
+refuses CharTermAttribute.
+*/
+var NUMERIC_ATTRIBUTE_FACTORY = assembleAttributeFactory(
+	&numericDelegateAttributeFactory{DEFAULT_ATTRIBUTE_FACTORY},
+	map[string]bool{
+		"NumericTermAttribute":    true,
+		"TermToBytesRefAttribute": true,
+	},
+	newNumericTermAttributeImpl,
+)
@@ -145,6 +145,13 @@ func NewTokenStream() *TokenStreamImpl {
 	}
 }
 
+/* A TokenStream using the supplied AttributeFactory for creating new Attribute instances. */
+func NewTokenStreamWithFactory(factory util.AttributeFactory) *TokenStreamImpl {
+	return &TokenStreamImpl{
+		atts: util.NewAttributeSourceWith(factory),
+	}
+}
+
 /* A TokenStream that uses the same attributes as the supplied one. */
 func NewTokenStreamWith(input *util.AttributeSource) *TokenStreamImpl {
 	return &TokenStreamImpl{
@@ -16,7 +16,19 @@ type CharTermAttribute interface {
 	//
 	// NOTE: the returned buffer may be larger than the valid Length().
 	Buffer() []rune
+	// Grows the termBuffer to at least size newSize, preserving the
+	// existing content.
+	ResizeBuffer(newSize int) []rune
 	Length() int
+	// Set number of valid runes (length of the term) in the termBuffer
+	// slice. Use this to truncate the termBuffer or to synchronize with
+	// external manipulation of the termBuffer.
+	//
+	// Note: to grow the size of the slice, use ResizeBuffer(int) first.
+	SetLength(length int) CharTermAttribute
+	// Sets the length of the termBuffer to zero. Use this method before
+	// appending contents.
+	SetEmpty() CharTermAttribute
 	// Appends teh specified string to this character sequence.
 	//
 	// The character of the string argument are appended, in order,
@@ -56,6 +68,17 @@ func (a *CharTermAttributeImpl) Buffer() []rune {
 	return a.termBuffer
 }
 
+func (a *CharTermAttributeImpl) ResizeBuffer(newSize int) []rune {
+	if len(a.termBuffer) < newSize {
+		// not big enough: create a new slice with slight over allocation
+		// and preserve content
+		newBuffer := make([]rune, util.Oversize(newSize, util.NUM_BYTES_CHAR))
+		copy(newBuffer, a.termBuffer)
+		a.termBuffer = newBuffer
+	}
+	return a.termBuffer
+}
+
 func (a *CharTermAttributeImpl) growTermBuffer(newSize int) {
 	if len(a.termBuffer) < newSize {
 		// not big enough: create a new slice with slight over allocation:
@@ -76,6 +99,18 @@ func (a *CharTermAttributeImpl) Length() int {
 	return a.termLength
 }
 
+func (a *CharTermAttributeImpl) SetLength(length int) CharTermAttribute {
+	assert2(length >= 0 && length <= len(a.termBuffer),
+		"length %v exceeds the size of the termBuffer (%v)", length, len(a.termBuffer))
+	a.termLength = length
+	return a
+}
+
+func (a *CharTermAttributeImpl) SetEmpty() CharTermAttribute {
+	a.termLength = 0
+	return a
+}
+
 func (a *CharTermAttributeImpl) AppendString(s string) CharTermAttribute {
 	if s == "" { // needed for Appendable compliance
 		return a.appendNil()
@@ -19,6 +19,8 @@ func (fac *DefaultAttributeFactory) Create(name string) util.AttributeImpl {
 		return newTypeAttributeImpl()
 	case "PayloadAttribute":
 		return newPayloadAttributeImpl()
+	case "KeywordAttribute":
+		return newKeywordAttributeImpl()
 	}
 	panic(fmt.Sprintf("not supported yet: %v", name))
 }
@@ -0,0 +1,55 @@
+package tokenattributes
+
+import (
+	"github.com/gzg1984/golucene/core/util"
+)
+
+/*
+This attribute can be used to mark a token as a keyword. Keyword
+aware TokenStreams can decide to modify a token based on the return
+value of IsKeyword() if the token is modified. Stemming filters for
+instance can use this attribute to conditionally skip a term if
+IsKeyword() returns true.
+*/
+type KeywordAttribute interface {
+	util.Attribute
+	// Returns true if the current token is a keyword, otherwise false.
+	IsKeyword() bool
+	// Marks the current token as keyword if set to true.
+	SetKeyword(isKeyword bool)
+}
+
+/* Default implementation of KeywordAttribute. */
+type KeywordAttributeImpl struct {
+	keyword bool
+}
+
+func newKeywordAttributeImpl() util.AttributeImpl {
+	return new(KeywordAttributeImpl)
+}
+
+func (a *KeywordAttributeImpl) Interfaces() []string {
+	return []string{"KeywordAttribute"}
+}
+
+func (a *KeywordAttributeImpl) IsKeyword() bool {
+	return a.keyword
+}
+
+func (a *KeywordAttributeImpl) SetKeyword(isKeyword bool) {
+	a.keyword = isKeyword
+}
+
+func (a *KeywordAttributeImpl) Clear() {
+	a.keyword = false
+}
+
+func (a *KeywordAttributeImpl) Clone() util.AttributeImpl {
+	return &KeywordAttributeImpl{
+		keyword: a.keyword,
+	}
+}
+
+func (a *KeywordAttributeImpl) CopyTo(target util.AttributeImpl) {
+	target.(KeywordAttribute).SetKeyword(a.keyword)
+}
@@ -52,7 +52,7 @@ func (a *OffsetAttributeImpl) SetOffset(startOffset, endOffset int) {
 		"startOffset must be non-negative, and endOffset must be >= startOffset, startOffset=%v,endOffset=%v",
 		startOffset, endOffset)
 	a.startOffset = startOffset
-	a.endOffset = a.endOffset
+	a.endOffset = endOffset
 }
 
 func (a *OffsetAttributeImpl) EndOffset() int {
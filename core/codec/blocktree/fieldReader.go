@@ -3,6 +3,7 @@ package blocktree
 import (
 	. "github.com/gzg1984/golucene/core/index/model"
 	"github.com/gzg1984/golucene/core/store"
+	"github.com/gzg1984/golucene/core/util/automaton"
 	"github.com/gzg1984/golucene/core/util/fst"
 )
 
@@ -79,3 +80,9 @@ func (r *FieldReader) SumDocFreq() int64 {
 func (r *FieldReader) DocCount() int {
 	return int(r.docCount)
 }
+
+func (r *FieldReader) Intersect(compiled *automaton.CompiledAutomaton, startTerm []byte) (TermsEnum, error) {
+	assert2(compiled.Type == automaton.AUTOMATON_TYPE_NORMAL,
+		"please use AutomatonQuery.TermsEnum instead")
+	return newIntersectTermsEnum(r, compiled, startTerm)
+}
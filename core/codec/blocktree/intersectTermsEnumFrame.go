@@ -0,0 +1,333 @@
+package blocktree
+
+import (
+	. "github.com/gzg1984/golucene/core/codec/spi"
+	. "github.com/gzg1984/golucene/core/index/model"
+	"github.com/gzg1984/golucene/core/store"
+	"github.com/gzg1984/golucene/core/util"
+	"github.com/gzg1984/golucene/core/util/automaton"
+	"github.com/gzg1984/golucene/core/util/fst"
+)
+
+// blocktree/IntersectTermsEnumFrame.java
+
+type intersectTermsEnumFrame struct {
+	ord       int
+	fp        int64
+	fpOrig    int64
+	fpEnd     int64
+	lastSubFP int64
+
+	// State in automaton
+	state int
+
+	metaDataUpto int
+
+	suffixBytes    []byte
+	suffixesReader store.ByteArrayDataInput
+
+	statBytes   []byte
+	statsReader store.ByteArrayDataInput
+
+	floorData       []byte
+	floorDataReader store.ByteArrayDataInput
+
+	// Length of prefix shared by all terms in this block
+	prefix int
+
+	// Number of entries (term or sub-block) in this block
+	entCount int
+
+	// Which term we will next read
+	nextEnt int
+
+	// True if this block is either not a floor block,
+	// or, it's the last sub-block of a floor block
+	isLastInFloor bool
+
+	// True if all entries are terms
+	isLeafBlock bool
+
+	numFollowFloorBlocks int
+	nextFloorLabel       int
+
+	transition       *automaton.Transition
+	curTransitionMax int
+	transitionIndex  int
+	transitionCount  int
+
+	arc *fst.Arc
+
+	termState *BlockTermState
+
+	// metadata buffer, holding monotonic values
+	longs []int64
+	// metadata buffer, holding general values
+	bytes       []byte
+	bytesReader *store.ByteArrayDataInput
+
+	// Cumulative output so far
+	outputPrefix interface{}
+
+	startBytePos int
+	suffix       int
+
+	ite *IntersectTermsEnum
+}
+
+func newIntersectTermsEnumFrame(ite *IntersectTermsEnum, ord int) *intersectTermsEnumFrame {
+	f := &intersectTermsEnumFrame{
+		suffixBytes: make([]byte, 128),
+		statBytes:   make([]byte, 64),
+		floorData:   make([]byte, 32),
+		transition:  automaton.NewTransition(),
+		ite:         ite,
+		ord:         ord,
+		longs:       make([]int64, ite.fr.longsSize),
+	}
+	f.termState = ite.fr.parent.postingsReader.NewTermState()
+	f.termState.TotalTermFreq = -1
+	return f
+}
+
+func (f *intersectTermsEnumFrame) loadNextFloorBlock() error {
+	assert(f.numFollowFloorBlocks > 0)
+	// fmt.Printf("    loadNextFloorBlock trans=%v\n", f.transition)
+
+	for {
+		code, _ := f.floorDataReader.ReadVLong() // no error
+		f.fp = f.fpOrig + int64(uint64(code)>>1)
+		f.numFollowFloorBlocks--
+		// fmt.Printf("    skip floor block2!  nextFloorLabel=%x vs target=%x newFP=%v numFollowFloorBlocks=%v\n",
+		// 	f.nextFloorLabel, f.transition.Min(), f.fp, f.numFollowFloorBlocks)
+		if f.numFollowFloorBlocks != 0 {
+			b, _ := f.floorDataReader.ReadByte() // no error
+			f.nextFloorLabel = int(b)
+		} else {
+			f.nextFloorLabel = 256
+		}
+		// fmt.Printf("    nextFloorLabel=%x\n", f.nextFloorLabel)
+		if f.numFollowFloorBlocks == 0 || f.nextFloorLabel > f.transition.Min() {
+			break
+		}
+	}
+
+	return f.load(nil)
+}
+
+func (f *intersectTermsEnumFrame) setState(state int) {
+	a := f.ite.compiledAutomaton.Automaton
+	f.state = state
+	f.transitionIndex = 0
+	f.transitionCount = a.NumTransitions(state)
+	if f.transitionCount != 0 {
+		a.InitTransition(state, f.transition)
+		a.NextTransition(f.transition)
+		f.curTransitionMax = f.transition.Max()
+	} else {
+		f.curTransitionMax = -1
+	}
+}
+
+func (f *intersectTermsEnumFrame) load(frameIndexData []byte) (err error) {
+	// fmt.Printf("    load fp=%v fpOrig=%v frameIndexData=%v trans=%v state=%v\n",
+	// 	f.fp, f.fpOrig, frameIndexData, f.transition, f.state)
+
+	if frameIndexData != nil && f.transitionCount != 0 {
+		// Floor frame
+		if len(f.floorData) < len(frameIndexData) {
+			f.floorData = make([]byte, util.Oversize(len(frameIndexData), 1))
+		}
+		copy(f.floorData, frameIndexData)
+		f.floorDataReader.Reset(f.floorData[:len(frameIndexData)])
+		// Skip first long -- has redundant fp, hasTerms
+		// flag, isFloor flag
+		code, _ := f.floorDataReader.ReadVLong() // no error
+		if (code & BTT_OUTPUT_FLAG_IS_FLOOR) != 0 {
+			f.numFollowFloorBlocks, _ = asInt(f.floorDataReader.ReadVInt())
+			b, _ := f.floorDataReader.ReadByte()
+			f.nextFloorLabel = int(b)
+			// fmt.Printf("    numFollowFloorBlocks=%v nextFloorLabel=%x\n",
+			// 	f.numFollowFloorBlocks, f.nextFloorLabel)
+
+			// If current state is accept, we must process
+			// first block in case it has empty suffix:
+			if !f.ite.runAutomaton.IsAccept(f.state) {
+				// Maybe skip floor blocks:
+				assert2(f.transitionIndex == 0, "transitionIndex=%v", f.transitionIndex)
+				for f.numFollowFloorBlocks != 0 && f.nextFloorLabel <= f.transition.Min() {
+					code, _ = f.floorDataReader.ReadVLong()
+					f.fp = f.fpOrig + int64(uint64(code)>>1)
+					f.numFollowFloorBlocks--
+					// fmt.Printf("    skip floor block!  nextFloorLabel=%x vs target=%x newFP=%v numFollowFloorBlocks=%v\n",
+					// 	f.nextFloorLabel, f.transition.Min(), f.fp, f.numFollowFloorBlocks)
+					if f.numFollowFloorBlocks != 0 {
+						b, _ = f.floorDataReader.ReadByte()
+						f.nextFloorLabel = int(b)
+					} else {
+						f.nextFloorLabel = 256
+					}
+				}
+			}
+		}
+	}
+
+	in := f.ite.in
+	in.Seek(f.fp)
+	code, err := asInt(in.ReadVInt())
+	if err != nil {
+		return err
+	}
+	f.entCount = int(uint(code) >> 1)
+	assert(f.entCount > 0)
+	f.isLastInFloor = (code & 1) != 0
+
+	// term suffixes:
+	if code, err = asInt(in.ReadVInt()); err != nil {
+		return err
+	}
+	f.isLeafBlock = (code & 1) != 0
+	numBytes := int(uint(code) >> 1)
+	// fmt.Printf("      entCount=%v lastInFloor?=%v leafBlock?=%v numSuffixBytes=%v\n",
+	// 	f.entCount, f.isLastInFloor, f.isLeafBlock, numBytes)
+	if len(f.suffixBytes) < numBytes {
+		f.suffixBytes = make([]byte, util.Oversize(numBytes, 1))
+	}
+	if err = in.ReadBytes(f.suffixBytes[:numBytes]); err != nil {
+		return err
+	}
+	f.suffixesReader.Reset(f.suffixBytes[:numBytes])
+
+	// stats
+	if numBytes, err = asInt(in.ReadVInt()); err != nil {
+		return err
+	}
+	if len(f.statBytes) < numBytes {
+		f.statBytes = make([]byte, util.Oversize(numBytes, 1))
+	}
+	if err = in.ReadBytes(f.statBytes[:numBytes]); err != nil {
+		return err
+	}
+	f.statsReader.Reset(f.statBytes[:numBytes])
+	f.metaDataUpto = 0
+
+	f.termState.TermBlockOrd = 0
+	f.nextEnt = 0
+
+	// metadata
+	if numBytes, err = asInt(in.ReadVInt()); err != nil {
+		return err
+	}
+	if f.bytes == nil {
+		f.bytes = make([]byte, util.Oversize(numBytes, 1))
+		f.bytesReader = store.NewEmptyByteArrayDataInput()
+	} else if len(f.bytes) < numBytes {
+		f.bytes = make([]byte, util.Oversize(numBytes, 1))
+	}
+	if err = in.ReadBytes(f.bytes[:numBytes]); err != nil {
+		return err
+	}
+	f.bytesReader.Reset(f.bytes[:numBytes])
+
+	if !f.isLastInFloor {
+		// Sub-blocks of a single floor block are always
+		// written one after another -- tail recurse:
+		f.fpEnd = in.FilePointer()
+	}
+	return nil
+}
+
+// TODO: maybe add scanToLabel; should give perf boost
+
+func (f *intersectTermsEnumFrame) next() bool {
+	if f.isLeafBlock {
+		return f.nextLeaf()
+	}
+	return f.nextNonLeaf()
+}
+
+// Decodes next entry; returns true if it's a sub-block
+func (f *intersectTermsEnumFrame) nextLeaf() bool {
+	// fmt.Printf("  frame.next ord=%v nextEnt=%v entCount=%v\n", f.ord, f.nextEnt, f.entCount)
+	assert2(f.nextEnt != -1 && f.nextEnt < f.entCount,
+		"nextEnt=%v entCount=%v fp=%v", f.nextEnt, f.entCount, f.fp)
+	f.nextEnt++
+	f.suffix, _ = asInt(f.suffixesReader.ReadVInt()) // no error
+	f.startBytePos = f.suffixesReader.Position()
+	f.suffixesReader.SkipBytes(int64(f.suffix))
+	return false
+}
+
+func (f *intersectTermsEnumFrame) nextNonLeaf() bool {
+	// fmt.Printf("  frame.next ord=%v nextEnt=%v entCount=%v\n", f.ord, f.nextEnt, f.entCount)
+	assert2(f.nextEnt != -1 && f.nextEnt < f.entCount,
+		"nextEnt=%v entCount=%v fp=%v", f.nextEnt, f.entCount, f.fp)
+	f.nextEnt++
+	code, _ := f.suffixesReader.ReadVInt() // no error
+	f.suffix = int(uint32(code) >> 1)
+	f.startBytePos = f.suffixesReader.Position()
+	f.suffixesReader.SkipBytes(int64(f.suffix))
+	if (code & 1) == 0 {
+		// A normal term
+		f.termState.TermBlockOrd++
+		return false
+	}
+	// A sub-block; make sub-FP absolute:
+	subCode, _ := f.suffixesReader.ReadVLong() // no error
+	f.lastSubFP = f.fp - subCode
+	return true
+}
+
+func (f *intersectTermsEnumFrame) getTermBlockOrd() int {
+	if f.isLeafBlock {
+		return f.nextEnt
+	}
+	return f.termState.TermBlockOrd
+}
+
+func (f *intersectTermsEnumFrame) decodeMetaData() (err error) {
+	// lazily catch up on metadata decode:
+	limit := f.getTermBlockOrd()
+	absolute := f.metaDataUpto == 0
+	assert(limit > 0)
+
+	// TODO: better API would be "jump straight to term=N"???
+	for f.metaDataUpto < limit {
+		// TODO: we could make "tiers" of metadata, ie,
+		// decode docFreq/totalTF but don't decode postings
+		// metadata; this way caller could get
+		// docFreq/totalTF w/o paying decode cost for
+		// postings
+
+		// TODO: if docFreq were bulk decoded we could
+		// just skipN here:
+
+		// stats
+		if f.termState.DocFreq, err = asInt(f.statsReader.ReadVInt()); err != nil {
+			return err
+		}
+		if f.ite.fr.fieldInfo.IndexOptions() != INDEX_OPT_DOCS_ONLY {
+			var n int64
+			if n, err = f.statsReader.ReadVLong(); err != nil {
+				return err
+			}
+			f.termState.TotalTermFreq = int64(f.termState.DocFreq) + n
+		}
+
+		// metadata
+		for i := 0; i < f.ite.fr.longsSize; i++ {
+			if f.longs[i], err = f.bytesReader.ReadVLong(); err != nil {
+				return err
+			}
+		}
+		if err = f.ite.fr.parent.postingsReader.DecodeTerm(f.longs,
+			f.bytesReader, f.ite.fr.fieldInfo, f.termState, absolute); err != nil {
+			return err
+		}
+
+		f.metaDataUpto++
+		absolute = false
+	}
+	f.termState.TermBlockOrd = f.metaDataUpto
+	return nil
+}
@@ -127,7 +127,7 @@ func (e *SegmentTermsEnum) getArc(ord int) *fst.Arc {
 }
 
 func (e *SegmentTermsEnum) Comparator() sort.Interface {
-	panic("not implemented yet")
+	return util.BytesRefs(nil) // terms are in UTF-8 sorted as unicode order
 }
 
 // Pushes a frame we seek'd to
@@ -187,7 +187,7 @@ func (e *SegmentTermsEnum) SeekExact(target []byte) (ok bool, err error) {
 	// fmt.Printf("BTTR.seekExact seg=%v target=%v:%v current=%v (exists?=%v) validIndexPrefix=%v\n",
 	// 	e.fr.parent.segment, e.fr.fieldInfo.Name, brToString(target),
 	// 	brToString(e.term.bytes), e.termExists, e.validIndexPrefix)
-	e.printSeekState()
+	// e.printSeekState()
 
 	var arc *fst.Arc
 	var targetUpto int
@@ -238,10 +238,9 @@ func (e *SegmentTermsEnum) SeekExact(target []byte) (ok bool, err error) {
 			arc = e.arcs[1+targetUpto]
 			assert2(arc.Label == int(target[targetUpto]),
 				"arc.label=%c targetLabel=%c", arc.Label, target[targetUpto])
-			panic("not implemented yet")
-			// if arc.Output != noOutputs {
-			// 	output = e.fstOutputs.Add(output, arc.Output).([]byte)
-			// }
+			if !fst.CompareFSTValue(arc.Output, noOutput) {
+				output = fstOutputs.Add(output, arc.Output)
+			}
 			if arc.IsFinal() {
 				lastFrame = e.stack[1+lastFrame.ord]
 			}
@@ -413,8 +412,218 @@ func (e *SegmentTermsEnum) SeekExact(target []byte) (ok bool, err error) {
 	}
 }
 
-func (e *SegmentTermsEnum) SeekCeil(text []byte) SeekStatus {
-	panic("not implemented yet")
+func (e *SegmentTermsEnum) SeekCeil(target []byte) (SeekStatus, error) {
+	assert2(e.fr.index != nil, "terms index was not loaded")
+
+	e.term.Grow(1 + len(target))
+
+	e.eof = false
+	// fmt.Printf("BTTR.seekCeil seg=%v target=%v:%v current=%v (exists?=%v) validIndexPrefix=%v\n",
+	// 	e.fr.parent.segment, e.fr.fieldInfo.Name, brToString(target),
+	// 	brToString(e.term.Bytes()[:e.term.Length()]), e.termExists, e.validIndexPrefix)
+	// e.printSeekState()
+
+	var arc *fst.Arc
+	var targetUpto int
+	var output interface{}
+	var err error
+
+	e.targetBeforeCurrentLength = e.currentFrame.ord
+
+	if e.currentFrame.ord != e.staticFrame.ord {
+		// We are already seek'd; find the common
+		// prefix of new seek term vs current term and
+		// re-use the corresponding seek state.  For
+		// example, if app first seeks to foobar, then
+		// seeks to foobaz, we can re-use the seek state
+		// for the first 5 bytes.
+
+		// fmt.Printf("  re-use current seek state validIndexPrefix=%v\n", e.validIndexPrefix)
+
+		arc = e.arcs[0]
+		assert(arc.IsFinal())
+		output = arc.Output
+		targetUpto = 0
+
+		lastFrame := e.stack[0]
+		assert(e.validIndexPrefix <= e.term.Length())
+
+		targetLimit := len(target)
+		if e.validIndexPrefix < targetLimit {
+			targetLimit = e.validIndexPrefix
+		}
+
+		cmp := 0
+
+		// First compare up to valid seek frames:
+		for targetUpto < targetLimit {
+			cmp = int(e.term.At(targetUpto)) - int(target[targetUpto])
+			if cmp != 0 {
+				break
+			}
+
+			arc = e.arcs[1+targetUpto]
+			assert2(arc.Label == int(target[targetUpto]),
+				"arc.label=%c targetLabel=%c", arc.Label, target[targetUpto])
+			if !fst.CompareFSTValue(arc.Output, noOutput) {
+				output = fstOutputs.Add(output, arc.Output)
+			}
+			if arc.IsFinal() {
+				lastFrame = e.stack[1+lastFrame.ord]
+			}
+			targetUpto++
+		}
+
+		if cmp == 0 {
+			targetUptoMid := targetUpto
+
+			// Second compare the rest of the term, but
+			// don't save arc/output/frame; we only do this
+			// to find out if the target term is before,
+			// equal or after the current term
+			targetLimit2 := len(target)
+			if e.term.Length() < targetLimit2 {
+				targetLimit2 = e.term.Length()
+			}
+			for targetUpto < targetLimit2 {
+				cmp = int(e.term.At(targetUpto)) - int(target[targetUpto])
+				if cmp != 0 {
+					break
+				}
+				targetUpto++
+			}
+
+			if cmp == 0 {
+				cmp = e.term.Length() - len(target)
+			}
+			targetUpto = targetUptoMid
+		}
+
+		if cmp < 0 {
+			// Common case: target term is after current
+			// term, ie, app is seeking multiple terms
+			// in sorted order
+			// fmt.Printf("  target is after current (shares prefixLen=%v); clear frame.scanned ord=%v\n", targetUpto, lastFrame.ord)
+			e.currentFrame = lastFrame
+		} else if cmp > 0 {
+			// Uncommon case: target term
+			// is before current term; this means we can
+			// keep the currentFrame but we must rewind it
+			// (so we scan from the start)
+			e.targetBeforeCurrentLength = 0
+			// fmt.Printf("  target is before current (shares prefixLen=%v); rewind frame ord=%v\n", targetUpto, lastFrame.ord)
+			e.currentFrame = lastFrame
+			e.currentFrame.rewind()
+		} else {
+			// Target is exactly the same as current term
+			assert(e.term.Length() == len(target))
+			if e.termExists {
+				// fmt.Println("  target is same as current; return FOUND")
+				return SEEK_STATUS_FOUND, nil
+			} else {
+				// fmt.Println("  target is same as current but term doesn't exist")
+			}
+		}
+	} else {
+		e.targetBeforeCurrentLength = -1
+		arc = e.fr.index.FirstArc(e.arcs[0])
+
+		// Empty string prefix must have an output (block) in the index!
+		assert(arc.IsFinal() && arc.Output != nil)
+
+		// fmt.Println("    no seek state; push root frame")
+
+		output = arc.Output
+
+		e.currentFrame = e.staticFrame
+
+		targetUpto = 0
+		if e.currentFrame, err = e.pushFrame(arc, fstOutputs.Add(output, arc.NextFinalOutput).([]byte), 0); err != nil {
+			return 0, err
+		}
+	}
+
+	// fmt.Printf("  start index loop targetUpto=%v output=%v currentFrame.ord=%v targetBeforeCurrentLength=%v\n",
+	// 	targetUpto, output, e.currentFrame.ord, e.targetBeforeCurrentLength)
+
+	for targetUpto < len(target) {
+		targetLabel := int(target[targetUpto])
+		nextArc, err := e.fr.index.FindTargetArc(targetLabel, arc, e.getArc(1+targetUpto), e.fstReader)
+		if err != nil {
+			return 0, err
+		}
+		if nextArc == nil {
+			// Index is exhausted
+			// fmt.Printf("    index: index exhausted label=%c %x\n", targetLabel, targetLabel)
+
+			e.validIndexPrefix = e.currentFrame.prefix
+
+			e.currentFrame.scanToFloorFrame(target)
+
+			if err = e.currentFrame.loadBlock(); err != nil {
+				return 0, err
+			}
+
+			return e.scanToCeil(target)
+		} else {
+			// Follow this arc
+			e.term.Set(targetUpto, byte(targetLabel))
+			arc = nextArc
+			// aggregate output as we go:
+			assert(arc.Output != nil)
+			if !fst.CompareFSTValue(arc.Output, noOutput) {
+				output = fstOutputs.Add(output, arc.Output)
+			}
+			targetUpto++
+
+			if arc.IsFinal() {
+				// fmt.Println("    arc is final!")
+				if e.currentFrame, err = e.pushFrame(arc,
+					fstOutputs.Add(output, arc.NextFinalOutput).([]byte),
+					targetUpto); err != nil {
+					return 0, err
+				}
+				// fmt.Printf("    curFrame.ord=%v hasTerms=%v\n", e.currentFrame.ord, e.currentFrame.hasTerms)
+			}
+		}
+	}
+
+	e.validIndexPrefix = e.currentFrame.prefix
+
+	e.currentFrame.scanToFloorFrame(target)
+
+	if err = e.currentFrame.loadBlock(); err != nil {
+		return 0, err
+	}
+
+	return e.scanToCeil(target)
+}
+
+// Scans the current frame to the ceiling term of target; if the block
+// has no such term, positions to the next term after it instead.
+func (e *SegmentTermsEnum) scanToCeil(target []byte) (SeekStatus, error) {
+	status, err := e.currentFrame.scanToTerm(target, false)
+	if err != nil {
+		return 0, err
+	}
+	if status != SEEK_STATUS_END {
+		// fmt.Printf("  return %v term=%v\n", status, e.term)
+		return status, nil
+	}
+
+	e.term.Copy(target)
+	e.termExists = false
+
+	term, err := e.Next()
+	if err != nil {
+		return 0, err
+	}
+	if term != nil {
+		// fmt.Printf("  return NOT_FOUND term=%v\n", brToString(term))
+		return SEEK_STATUS_NOT_FOUND, nil
+	}
+	// fmt.Println("  return END")
+	return SEEK_STATUS_END, nil
 }
 
 func (e *SegmentTermsEnum) printSeekState() {
@@ -505,12 +714,108 @@ func (e *SegmentTermsEnum) printSeekState() {
 }
 
 func (e *SegmentTermsEnum) Next() (buf []byte, err error) {
-	panic("not implemented yet")
+	if e.in == nil {
+		// Fresh TermsEnum; seek to first term:
+		var arc *fst.Arc
+		if e.fr.index != nil {
+			arc = e.fr.index.FirstArc(e.arcs[0])
+			// Empty string prefix must have an output in the index!
+			assert(arc.IsFinal())
+		}
+		if e.currentFrame, err = e.pushFrame(arc, e.fr.rootCode, 0); err != nil {
+			return nil, err
+		}
+		if err = e.currentFrame.loadBlock(); err != nil {
+			return nil, err
+		}
+	}
+
+	e.targetBeforeCurrentLength = e.currentFrame.ord
+
+	assert(!e.eof)
+	// fmt.Printf("BTTR.next seg=%v term=%v termExists?=%v field=%v termBlockOrd=%v validIndexPrefix=%v\n",
+	// 	e.fr.parent.segment, brToString(e.term.Bytes()[:e.term.Length()]), e.termExists,
+	// 	e.fr.fieldInfo.Name, e.currentFrame.state.TermBlockOrd, e.validIndexPrefix)
+	// e.printSeekState()
+
+	if e.currentFrame == e.staticFrame {
+		// If seek was previously called and the term was cached, or
+		// seek(TermState) was called, usually caller is just going to
+		// pull a D/&PEnum or get docFreq, etc. But, if they then call
+		// next(), this method catches up all internal state so next()
+		// works properly:
+		// fmt.Printf("  re-seek to pending term=%v\n", e.term)
+		ok, err := e.SeekExact(copyBytes(nil, e.term.Bytes()[:e.term.Length()]))
+		if err != nil {
+			return nil, err
+		}
+		assert(ok)
+	}
+
+	// Pop finished blocks
+	for e.currentFrame.nextEnt == e.currentFrame.entCount {
+		if !e.currentFrame.isLastInFloor {
+			if err = e.currentFrame.loadNextFloorBlock(); err != nil {
+				return nil, err
+			}
+		} else {
+			// fmt.Println("  pop frame")
+			if e.currentFrame.ord == 0 {
+				// fmt.Println("  return nil")
+				e.eof = true
+				e.term.SetLength(0)
+				e.validIndexPrefix = 0
+				e.currentFrame.rewind()
+				e.termExists = false
+				return nil, nil
+			}
+			lastFP := e.currentFrame.fpOrig
+			e.currentFrame = e.stack[e.currentFrame.ord-1]
+
+			if e.currentFrame.nextEnt == -1 || e.currentFrame.lastSubFP != lastFP {
+				// We popped into a frame that's not loaded yet or not
+				// scan'd to the right entry
+				e.currentFrame.scanToFloorFrame(e.term.Bytes()[:e.term.Length()])
+				if err = e.currentFrame.loadBlock(); err != nil {
+					return nil, err
+				}
+				e.currentFrame.scanToSubBlock(lastFP)
+			}
+
+			// Note that the seek state (last seek) has been invalidated
+			// beyond this depth
+			if e.currentFrame.prefix < e.validIndexPrefix {
+				e.validIndexPrefix = e.currentFrame.prefix
+			}
+			// fmt.Printf("  reset validIndexPrefix=%v\n", e.validIndexPrefix)
+		}
+	}
+
+	for {
+		if e.currentFrame.next() {
+			// Push to new block:
+			// fmt.Println("  push frame")
+			if e.currentFrame, err = e.pushFrameAt(nil, e.currentFrame.lastSubFP, e.term.Length()); err != nil {
+				return nil, err
+			}
+			// This is a "next" frame -- even if it's floor'd we must
+			// pretend it isn't so we don't try to scan to the right
+			// floor frame:
+			e.currentFrame.isFloor = false
+			if err = e.currentFrame.loadBlock(); err != nil {
+				return nil, err
+			}
+		} else {
+			// fmt.Printf("  return term=%v currentFrame.ord=%v\n",
+			// 	brToString(e.term.Bytes()[:e.term.Length()]), e.currentFrame.ord)
+			return e.term.Bytes()[:e.term.Length()], nil
+		}
+	}
 }
 
 func (e *SegmentTermsEnum) Term() []byte {
 	assert(!e.eof)
-	return e.term.Bytes()
+	return e.term.Bytes()[:e.term.Length()]
 }
 
 func assert(ok bool) {
@@ -552,8 +857,17 @@ func (e *SegmentTermsEnum) DocsByFlags(skipDocs util.Bits, reuse DocsEnum, flags
 	return e.fr.parent.postingsReader.Docs(e.fr.fieldInfo, e.currentFrame.state, skipDocs, reuse, flags)
 }
 
-func (e *SegmentTermsEnum) DocsAndPositionsByFlags(skipDocs util.Bits, reuse DocsAndPositionsEnum, flags int) DocsAndPositionsEnum {
-	panic("not implemented yet")
+func (e *SegmentTermsEnum) DocsAndPositionsByFlags(skipDocs util.Bits, reuse DocsAndPositionsEnum, flags int) (DocsAndPositionsEnum, error) {
+	if e.fr.fieldInfo.IndexOptions() < INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS {
+		// Positions were not indexed:
+		return nil, nil
+	}
+
+	assert(!e.eof)
+	if err := e.currentFrame.decodeMetaData(); err != nil {
+		return nil, err
+	}
+	return e.fr.parent.postingsReader.DocsAndPositions(e.fr.fieldInfo, e.currentFrame.state, skipDocs, reuse, flags)
 }
 
 func (e *SegmentTermsEnum) SeekExactFromLast(target []byte, otherState TermState) error {
@@ -241,13 +241,54 @@ func (f *segmentTermsEnumFrame) next() bool {
 	return f.nextNonLeaf()
 }
 
+func (f *segmentTermsEnumFrame) loadNextFloorBlock() error {
+	// fmt.Printf("    loadNextFloorBlock fp=%v fpEnd=%v\n", f.fp, f.fpEnd)
+	assert2(f.arc == nil || f.isFloor, "arc=%v isFloor=%v", f.arc, f.isFloor)
+	f.fp = f.fpEnd
+	f.nextEnt = -1
+	return f.loadBlock()
+}
+
 // Decodes next entry; returns true if it's a sub-block
 func (f *segmentTermsEnumFrame) nextLeaf() bool {
-	panic("not implemented yet")
+	// fmt.Printf("  frame.next ord=%v nextEnt=%v entCount=%v\n", f.ord, f.nextEnt, f.entCount)
+	assert2(f.nextEnt != -1 && f.nextEnt < f.entCount,
+		"nextEnt=%v entCount=%v fp=%v", f.nextEnt, f.entCount, f.fp)
+	f.nextEnt++
+	f.suffix, _ = asInt(f.suffixesReader.ReadVInt()) // no error
+	f.startBytePos = f.suffixesReader.Position()
+	f.ste.term.SetLength(f.prefix + f.suffix)
+	f.ste.term.Grow(f.ste.term.Length())
+	f.suffixesReader.ReadBytes(f.ste.term.Bytes()[f.prefix : f.prefix+f.suffix]) // no error
+	// A normal term
+	f.ste.termExists = true
+	return false
 }
 
 func (f *segmentTermsEnumFrame) nextNonLeaf() bool {
-	panic("not implemented yet")
+	// fmt.Printf("  frame.next ord=%v nextEnt=%v entCount=%v\n", f.ord, f.nextEnt, f.entCount)
+	assert2(f.nextEnt != -1 && f.nextEnt < f.entCount,
+		"nextEnt=%v entCount=%v fp=%v", f.nextEnt, f.entCount, f.fp)
+	f.nextEnt++
+	code, _ := f.suffixesReader.ReadVInt() // no error
+	f.suffix = int(uint32(code) >> 1)
+	f.startBytePos = f.suffixesReader.Position()
+	f.ste.term.SetLength(f.prefix + f.suffix)
+	f.ste.term.Grow(f.ste.term.Length())
+	f.suffixesReader.ReadBytes(f.ste.term.Bytes()[f.prefix : f.prefix+f.suffix]) // no error
+	if (code & 1) == 0 {
+		// A normal term
+		f.ste.termExists = true
+		f.subCode = 0
+		f.state.TermBlockOrd++
+		return false
+	}
+	// A sub-block; make sub-FP absolute:
+	f.ste.termExists = false
+	f.subCode, _ = f.suffixesReader.ReadVLong() // no error
+	f.lastSubFP = f.fp - f.subCode
+	// fmt.Printf("    lastSubFP=%v\n", f.lastSubFP)
+	return true
 }
 
 // TODO: make this array'd so we can do bin search?
@@ -261,10 +302,10 @@ func (f *segmentTermsEnumFrame) scanToFloorFrame(target []byte) {
 	}
 
 	targetLabel := int(target[f.prefix])
-	fmt.Printf("    scanToFloorFrame fpOrig=%v targetLabel=%x vs nextFloorLabel=%x numFollowFloorBlocks=%v\n",
-		f.fpOrig, targetLabel, f.nextFloorLabel, f.numFollowFloorBlocks)
+	// fmt.Printf("    scanToFloorFrame fpOrig=%v targetLabel=%x vs nextFloorLabel=%x numFollowFloorBlocks=%v\n",
+	// 	f.fpOrig, targetLabel, f.nextFloorLabel, f.numFollowFloorBlocks)
 	if targetLabel < f.nextFloorLabel {
-		fmt.Println("      already on correct block")
+		// fmt.Println("      already on correct block")
 		return
 	}
 
@@ -283,20 +324,59 @@ func (f *segmentTermsEnumFrame) scanToFloorFrame(target []byte) {
 
 		if f.isLastInFloor {
 			f.nextFloorLabel = 256
-			fmt.Printf("        stop!  last block nextFloorLabel=%x\n", f.nextFloorLabel)
+			// fmt.Printf("        stop!  last block nextFloorLabel=%x\n", f.nextFloorLabel)
 			break
 		} else {
-			panic("niy")
+			b, _ := f.floorDataReader.ReadByte() // ignore error
+			f.nextFloorLabel = int(b)
+			// fmt.Printf("        nextFloorLabel=%x\n", f.nextFloorLabel)
+			if targetLabel < f.nextFloorLabel {
+				// fmt.Println("        stop!")
+				break
+			}
 		}
 	}
 
 	if newFP != f.fp {
 		// Force re-load of the block:
-		fmt.Printf("      force switch to fp=%v oldFP=%v\n", newFP, f.fp)
+		// fmt.Printf("      force switch to fp=%v oldFP=%v\n", newFP, f.fp)
 		f.nextEnt = -1
 		f.fp = newFP
 	} else {
-		//
+		// fmt.Printf("      stay on same fp=%v\n", newFP)
+	}
+}
+
+/*
+Scans to sub-block that has this target fp; only called by next();
+NOTE: does not set startBytePos/suffix as a side effect
+*/
+func (f *segmentTermsEnumFrame) scanToSubBlock(subFP int64) {
+	assert(!f.isLeafBlock)
+	// fmt.Printf("  scanToSubBlock fp=%v subFP=%v entCount=%v lastSubFP=%v\n",
+	// 	f.fp, subFP, f.entCount, f.lastSubFP)
+	if f.lastSubFP == subFP {
+		// fmt.Println("    already positioned")
+		return
+	}
+	assert2(subFP < f.fp, "fp=%v subFP=%v", f.fp, subFP)
+	targetSubCode := f.fp - subFP
+	// fmt.Printf("    targetSubCode=%v\n", targetSubCode)
+	for {
+		assert(f.nextEnt < f.entCount)
+		f.nextEnt++
+		code, _ := f.suffixesReader.ReadVInt() // no error
+		f.suffixesReader.SkipBytes(int64(uint32(code) >> 1))
+		if (code & 1) != 0 {
+			subCode, _ := f.suffixesReader.ReadVLong() // no error
+			if targetSubCode == subCode {
+				// fmt.Println("        match!")
+				f.lastSubFP = subFP
+				return
+			}
+		} else {
+			f.state.TermBlockOrd++
+		}
 	}
 }
 
@@ -481,7 +561,7 @@ func (f *segmentTermsEnumFrame) scanToTermLeaf(target []byte, exactOnly bool) (s
 	// to the foo* block, but the last term in this block
 	// was fooz (and, eg, first term in the next block will
 	// bee fop).
-	fmt.Println("      block end")
+	// fmt.Println("      block end")
 	if exactOnly {
 		f.fillTerm()
 	}
@@ -497,14 +577,18 @@ func (f *segmentTermsEnumFrame) scanToTermLeaf(target []byte, exactOnly bool) (s
 func (f *segmentTermsEnumFrame) scanToTermNonLeaf(target []byte,
 	exactOnly bool) (status SeekStatus, err error) {
 
-	fmt.Printf(
-		"    scanToTermNonLeaf: block fp=%v prefix=%v nextEnt=%v (of %v) target=%v term=%v",
-		f.fp, f.prefix, f.nextEnt, f.entCount, brToString(target), "" /*brToString(term)*/)
+	// fmt.Printf(
+	// 	"    scanToTermNonLeaf: block fp=%v prefix=%v nextEnt=%v (of %v) target=%v term=%v",
+	// 	f.fp, f.prefix, f.nextEnt, f.entCount, brToString(target), "" /*brToString(term)*/)
 
 	assert(f.nextEnt != -1)
 
 	if f.nextEnt == f.entCount {
-		panic("not implemented yet")
+		if exactOnly {
+			f.fillTerm()
+			f.ste.termExists = f.subCode == 0
+		}
+		return SEEK_STATUS_END, nil
 	}
 
 	assert(f.prefixMatches(target))
@@ -571,10 +655,26 @@ func (f *segmentTermsEnumFrame) scanToTermNonLeaf(target []byte,
 				f.fillTerm()
 
 				if !exactOnly && !f.ste.termExists {
-					panic("niy")
+					// We are on a sub-block, and caller wants us to position
+					// to the next term after the target, so we must recurse
+					// into the sub-frame(s):
+					if f.ste.currentFrame, err = f.ste.pushFrameAt(nil, f.ste.currentFrame.lastSubFP, termLen); err != nil {
+						return 0, err
+					}
+					if err = f.ste.currentFrame.loadBlock(); err != nil {
+						return 0, err
+					}
+					for f.ste.currentFrame.next() {
+						if f.ste.currentFrame, err = f.ste.pushFrameAt(nil, f.ste.currentFrame.lastSubFP, f.ste.term.Length()); err != nil {
+							return 0, err
+						}
+						if err = f.ste.currentFrame.loadBlock(); err != nil {
+							return 0, err
+						}
+					}
 				}
 
-				fmt.Println("        not found")
+				// fmt.Println("        not found")
 				return SEEK_STATUS_NOT_FOUND, nil
 			} else if stop {
 				// Exact match!
@@ -584,7 +684,7 @@ func (f *segmentTermsEnumFrame) scanToTermNonLeaf(target []byte,
 
 				assert(f.ste.termExists)
 				f.fillTerm()
-				fmt.Println("        found!")
+				// fmt.Println("        found!")
 				return SEEK_STATUS_FOUND, nil
 			}
 		}
@@ -603,7 +703,7 @@ func (f *segmentTermsEnumFrame) scanToTermNonLeaf(target []byte,
 	// E.g., target could be foozzz, and terms index pointed us to the
 	// foo* block, but the last term in this block was fooz (and, e.g.,
 	// first term in the next block will be fop).
-	fmt.Println("      block end")
+	// fmt.Println("      block end")
 	if exactOnly {
 		f.fillTerm()
 	}
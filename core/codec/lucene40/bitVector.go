@@ -12,6 +12,8 @@ import (
 const (
 	CODEC = "BitVector"
 
+	BV_VERSION_START = 0
+
 	/* Change DGaps to encode gaps between cleared bits, not set: */
 	BV_VERSION_DGAPS_CLEARED = 1
 
@@ -34,6 +36,69 @@ func NewBitVector(n int) *BitVector {
 	}
 }
 
+/*
+Constructs a bit vector from the file name in Directory d, as written
+by the Write() method.
+*/
+func ReadBitVector(d store.Directory, name string, ctx store.IOContext) (bv *BitVector, err error) {
+	var input store.ChecksumIndexInput
+	if input, err = d.OpenChecksumInput(name, ctx); err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = mergeError(err, input.Close())
+	}()
+
+	firstInt, err := input.ReadInt()
+	if err != nil {
+		return nil, err
+	}
+	if firstInt != -2 {
+		return nil, errors.New(fmt.Sprintf(
+			"format of pre-4.0 deletes is not supported (resource: %v)", input))
+	}
+	// New format, with full header & version:
+	version, err := codec.CheckHeader(input, CODEC, BV_VERSION_START, BV_VERSION_CURRENT)
+	if err != nil {
+		return nil, err
+	}
+	if version < BV_VERSION_DGAPS_CLEARED {
+		return nil, errors.New(fmt.Sprintf(
+			"deletes encoded as set d-gaps are not supported (resource: %v)", input))
+	}
+	size, err := input.ReadInt()
+	if err != nil {
+		return nil, err
+	}
+	bv = new(BitVector)
+	if size == -1 {
+		err = bv.readClearedDgaps(input)
+	} else {
+		bv.size = int(size)
+		err = bv.readBits(input)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if version >= BV_VERSION_CHECKSUM {
+		_, err = codec.CheckFooter(input)
+	} else {
+		err = codec.CheckEOF(input)
+	}
+	if err != nil {
+		return nil, err
+	}
+	bv.assertCount()
+	return bv, nil
+}
+
+/* Returns a copy of this vector, with its own bits. */
+func (bv *BitVector) Clone() *BitVector {
+	bits := make([]byte, len(bv.bits))
+	copy(bits, bv.bits)
+	return &BitVector{bits: bits, size: bv.size, count: bv.count}
+}
+
 func numBytes(size int) int {
 	bytesLength := int(uint(size) >> 3)
 	if (size & 7) != 0 {
@@ -138,6 +203,18 @@ func (bv *BitVector) InvertAll() {
 		for idx, v := range bv.bits {
 			bv.bits[idx] = byte(^v)
 		}
+		bv.clearUnusedBits()
+	}
+}
+
+/* Clears the unused bits in the last byte, so that they're not counted. */
+func (bv *BitVector) clearUnusedBits() {
+	// Take care not to invert the "unused" bits in the last byte:
+	if len(bv.bits) > 0 {
+		if lastNBits := uint(bv.size & 7); lastNBits != 0 {
+			mask := byte(1<<lastNBits) - 1
+			bv.bits[len(bv.bits)-1] &= mask
+		}
 	}
 }
 
@@ -188,7 +265,82 @@ Indicates if the bit vector is sparse and should be saved as a d-gaps
 list, or dense, and should be saved as a bit set.
 */
 func (bv *BitVector) isSparse() bool {
-	panic("not implemented yet")
+	clearedCount := bv.size - bv.Count()
+	if clearedCount == 0 {
+		return true
+	}
+
+	avgGapLength := len(bv.bits) / clearedCount
+
+	// expected number of bytes for vInt encoding of each gap
+	var expectedDGapBytes int
+	switch {
+	case avgGapLength <= (1 << 7):
+		expectedDGapBytes = 1
+	case avgGapLength <= (1 << 14):
+		expectedDGapBytes = 2
+	case avgGapLength <= (1 << 21):
+		expectedDGapBytes = 3
+	case avgGapLength <= (1 << 28):
+		expectedDGapBytes = 4
+	default:
+		expectedDGapBytes = 5
+	}
+
+	// +1 because we write the byte itself that contains the set bit
+	bytesPerSetBit := expectedDGapBytes + 1
+
+	// note: adding 32 because we start with ((int) -1) to indicate
+	// d-gaps format.
+	expectedBits := int64(32 + 8*bytesPerSetBit*clearedCount)
+
+	// note: factor is for read/write of byte-arrays being faster than
+	// vints.
+	const factor = 10
+	return factor*expectedBits < int64(bv.size)
+}
+
+/* Read as a bit set */
+func (bv *BitVector) readBits(input store.IndexInput) (err error) {
+	if bv.count, err = asInt(input.ReadInt()); err != nil { // read count
+		return err
+	}
+	bv.bits = make([]byte, numBytes(bv.size)) // allocate bits
+	return input.ReadBytes(bv.bits)
+}
+
+/* Read as a d-gaps cleared bits list */
+func (bv *BitVector) readClearedDgaps(input store.IndexInput) (err error) {
+	if bv.size, err = asInt(input.ReadInt()); err != nil { // (re)read size
+		return err
+	}
+	if bv.count, err = asInt(input.ReadInt()); err != nil { // read count
+		return err
+	}
+	bv.bits = make([]byte, numBytes(bv.size)) // allocate bits
+	for i, _ := range bv.bits {
+		bv.bits[i] = 0xff
+	}
+	bv.clearUnusedBits()
+	last, numCleared := 0, bv.size-bv.count
+	for numCleared > 0 {
+		gap, err := input.ReadVInt()
+		if err != nil {
+			return err
+		}
+		last += int(gap)
+		if bv.bits[last], err = input.ReadByte(); err != nil {
+			return err
+		}
+		numCleared -= 8 - util.BitCount(bv.bits[last])
+		assert(numCleared >= 0 ||
+			last == len(bv.bits)-1 && numCleared == -(8-(bv.size&7)))
+	}
+	return nil
+}
+
+func asInt(n int32, err error) (int, error) {
+	return int(n), err
 }
 
 func (bv *BitVector) assertCount() {
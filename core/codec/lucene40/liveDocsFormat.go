@@ -1,6 +1,8 @@
 package lucene40
 
 import (
+	"errors"
+	"fmt"
 	. "github.com/gzg1984/golucene/core/codec/spi"
 	"github.com/gzg1984/golucene/core/store"
 	"github.com/gzg1984/golucene/core/util"
@@ -61,6 +63,31 @@ func (format *Lucene40LiveDocsFormat) NewLiveDocs(size int) util.MutableBits {
 	return ans
 }
 
+func (format *Lucene40LiveDocsFormat) NewLiveDocsFrom(existing util.Bits) util.MutableBits {
+	return existing.(*BitVector).Clone()
+}
+
+func (format *Lucene40LiveDocsFormat) ReadLiveDocs(dir store.Directory,
+	info *SegmentCommitInfo, ctx store.IOContext) (util.Bits, error) {
+
+	filename := util.FileNameFromGeneration(info.Info.Name, DELETES_EXTENSION, info.DelGen())
+	liveDocs, err := ReadBitVector(dir, filename, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if liveDocs.Length() != info.Info.DocCount() {
+		return nil, errors.New(fmt.Sprintf(
+			"liveDocs.length()=%v info.docCount=%v (filename=%v)",
+			liveDocs.Length(), info.Info.DocCount(), filename))
+	}
+	if liveDocs.Count() != info.Info.DocCount()-info.DelCount() {
+		return nil, errors.New(fmt.Sprintf(
+			"liveDocs.count()=%v info.docCount=%v info.getDelCount()=%v (filename=%v)",
+			liveDocs.Count(), info.Info.DocCount(), info.DelCount(), filename))
+	}
+	return liveDocs, nil
+}
+
 func (format *Lucene40LiveDocsFormat) WriteLiveDocs(bits util.MutableBits,
 	dir store.Directory, info *SegmentCommitInfo, newDelCount int,
 	ctx store.IOContext) error {
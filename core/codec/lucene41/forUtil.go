@@ -2,6 +2,7 @@ package lucene41
 
 import (
 	"fmt"
+	"github.com/gzg1984/golucene/core/store"
 	"github.com/gzg1984/golucene/core/util"
 	"github.com/gzg1984/golucene/core/util/packed"
 	"math"
@@ -172,6 +173,55 @@ func (u *ForUtil) writeBlock(data []int, encoded []byte, out IndexOutput) error
 	return out.WriteBytes(encoded[:encodedSize])
 }
 
+/* Read the next block of data (For format). */
+func (u *ForUtil) readBlock(in store.IndexInput, encoded []byte, decoded []int) error {
+	b, err := in.ReadByte()
+	if err != nil {
+		return err
+	}
+	numBits := int(b)
+	assert2(numBits <= 32, "%v", numBits)
+
+	if numBits == ALL_VALUES_EQUAL {
+		value, err := in.ReadVInt()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < LUCENE41_BLOCK_SIZE; i++ {
+			decoded[i] = int(value)
+		}
+		return nil
+	}
+
+	encodedSize := int(u.encodedSizes[numBits])
+	if err = in.ReadBytes(encoded[:encodedSize]); err != nil {
+		return err
+	}
+
+	decoder := u.decoders[numBits]
+	iters := int(u.iterations[numBits])
+	assert(iters*decoder.ByteValueCount() >= LUCENE41_BLOCK_SIZE)
+
+	decoder.DecodeByteToInt(encoded, decoded, iters)
+	return nil
+}
+
+/* Skip the next block of data. */
+func (u *ForUtil) skipBlock(in store.IndexInput) error {
+	b, err := in.ReadByte()
+	if err != nil {
+		return err
+	}
+	numBits := int(b)
+	if numBits == ALL_VALUES_EQUAL {
+		_, err = in.ReadVInt()
+		return err
+	}
+	assert2(numBits > 0 && numBits <= 32, "%v", numBits)
+	encodedSize := int64(u.encodedSizes[numBits])
+	return in.Seek(in.FilePointer() + encodedSize)
+}
+
 func encodedSize(format packed.PackedFormat, packedIntsVersion int32, bitsPerValue uint32) int32 {
 	byteCount := format.ByteCount(packedIntsVersion, LUCENE41_BLOCK_SIZE, bitsPerValue)
 	// assert byteCount >= 0 && byteCount <= math.MaxInt32()
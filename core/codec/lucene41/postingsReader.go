@@ -299,7 +299,7 @@ type blockDocsEnum struct {
 
 	docBufferUpto int
 
-	// skipper Lucene41SkipReader
+	skipper *SkipReader
 	skipped bool
 
 	startDocIn store.IndexInput
@@ -346,7 +346,7 @@ func newBlockDocsEnum(owner *Lucene41PostingsReader,
 		docIn:                  nil,
 		indexHasFreq:           fieldInfo.IndexOptions() >= INDEX_OPT_DOCS_AND_FREQS,
 		indexHasPos:            fieldInfo.IndexOptions() >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS,
-		indexHasOffsets:        fieldInfo.IndexOptions() >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS,
+		indexHasOffsets:        fieldInfo.IndexOptions() >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS,
 		indexHasPayloads:       fieldInfo.HasPayloads(),
 		encoded:                make([]byte, MAX_ENCODED_SIZE),
 	}
@@ -391,7 +391,11 @@ func (de *blockDocsEnum) reset(liveDocs util.Bits, termState *intBlockTermState,
 	}
 	de.accum = 0
 	de.docUpto = 0
-	de.nextSkipDoc = LUCENE41_BLOCK_SIZE - 1 // we won't skip if target is found in first block
+	if de.docFreq > LUCENE41_BLOCK_SIZE {
+		de.nextSkipDoc = LUCENE41_BLOCK_SIZE - 1 // we won't skip if target is found in first block
+	} else {
+		de.nextSkipDoc = NO_MORE_DOCS // not enough docs for skipping
+	}
 	de.docBufferUpto = LUCENE41_BLOCK_SIZE
 	de.skipped = false
 	return de, nil
@@ -410,8 +414,21 @@ func (de *blockDocsEnum) refillDocs() (err error) {
 	assert(left > 0)
 
 	if left >= LUCENE41_BLOCK_SIZE {
-		fmt.Println("    fill doc block from fp=", de.docIn.FilePointer())
-		panic("not implemented yet")
+		// fmt.Println("    fill doc block from fp=", de.docIn.FilePointer())
+		if err = de.forUtil.readBlock(de.docIn, de.encoded, de.docDeltaBuffer); err != nil {
+			return
+		}
+		if de.indexHasFreq {
+			// fmt.Println("    fill freq block from fp=", de.docIn.FilePointer())
+			if de.needsFreq {
+				err = de.forUtil.readBlock(de.docIn, de.encoded, de.freqBuffer)
+			} else {
+				err = de.forUtil.skipBlock(de.docIn) // skip over freqs
+			}
+			if err != nil {
+				return
+			}
+		}
 	} else if de.docFreq == 1 {
 		de.docDeltaBuffer[0] = de.singletonDocID
 		de.freqBuffer[0] = int(de.totalTermFreq)
@@ -460,29 +477,63 @@ func (de *blockDocsEnum) NextDoc() (n int, err error) {
 
 func (de *blockDocsEnum) Advance(target int) (int, error) {
 	// TODO: make frq block load lazy/skippable
-	fmt.Printf("  FPR.advance target=%v\n", target)
+	// fmt.Printf("  FPR.advance target=%v\n", target)
 
 	// current skip docID < docIDs generated from current buffer <= next
 	// skip docID, we don't need to skip if target is buffered already
 	if de.docFreq > LUCENE41_BLOCK_SIZE && target > de.nextSkipDoc {
-		fmt.Println("load skipper")
+		if de.skipper == nil {
+			// Lazy init: first time this enum has ever been used for skipping
+			de.skipper = NewSkipReader(de.docIn.Clone(), maxSkipLevels,
+				LUCENE41_BLOCK_SIZE, de.indexHasPos, de.indexHasOffsets, de.indexHasPayloads)
+		}
+
+		if !de.skipped {
+			assert(de.skipOffset != -1)
+			// This is the first time this enum has skipped since reset()
+			// was called; load the skip data:
+			de.skipper.Init(de.docTermStartFP+de.skipOffset, de.docTermStartFP, 0, 0, de.docFreq)
+			de.skipped = true
+		}
 
-		panic("not implemented yet")
+		// always plus one to fix the result, since skip position in
+		// SkipReader is a little different from MultiLevelSkipListReader
+		newDocUpto, err := de.skipper.SkipTo(target)
+		if err != nil {
+			return 0, err
+		}
+		newDocUpto++
+
+		if newDocUpto > de.docUpto {
+			// Skipper moved
+			assert2(newDocUpto%LUCENE41_BLOCK_SIZE == 0, "got %v", newDocUpto)
+			de.docUpto = newDocUpto
+
+			// Force to read next block
+			de.docBufferUpto = LUCENE41_BLOCK_SIZE
+			de.accum = de.skipper.Doc() // actually, this is just lastSkipEntry
+			// now point to the block we want to search
+			if err = de.docIn.Seek(de.skipper.DocPointer()); err != nil {
+				return 0, err
+			}
+		}
+		// next time we call advance, this is used to foresee whether
+		// skipper is necessary.
+		de.nextSkipDoc = de.skipper.NextSkipDoc()
 	}
 	if de.docUpto == de.docFreq {
 		de.doc = NO_MORE_DOCS
 		return de.doc, nil
 	}
 	if de.docBufferUpto == LUCENE41_BLOCK_SIZE {
-		err := de.refillDocs()
-		if err != nil {
-			return 0, nil
+		if err := de.refillDocs(); err != nil {
+			return 0, err
 		}
 	}
 
 	// Now scan.. this is an inlined/pared down version of nextDoc():
 	for {
-		fmt.Printf("  scan doc=%v docBufferUpto=%v\n", de.accum, de.docBufferUpto)
+		// fmt.Printf("  scan doc=%v docBufferUpto=%v\n", de.accum, de.docBufferUpto)
 		de.accum += de.docDeltaBuffer[de.docBufferUpto]
 		de.docUpto++
 
@@ -497,14 +548,436 @@ func (de *blockDocsEnum) Advance(target int) (int, error) {
 	}
 
 	if de.liveDocs == nil || de.liveDocs.At(de.accum) {
-		fmt.Printf("  return doc=%v\n", de.accum)
+		// fmt.Printf("  return doc=%v\n", de.accum)
 		de.freq = de.freqBuffer[de.docBufferUpto]
 		de.docBufferUpto++
 		de.doc = de.accum
 		return de.doc, nil
+	}
+	// fmt.Println("  now do nextDoc()")
+	de.docBufferUpto++
+	return de.NextDoc()
+}
+
+func (r *Lucene41PostingsReader) DocsAndPositions(fieldInfo *FieldInfo,
+	termState *BlockTermState, liveDocs util.Bits,
+	reuse DocsAndPositionsEnum, flags int) (DocsAndPositionsEnum, error) {
+
+	indexHasOffsets := fieldInfo.IndexOptions() >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS
+	indexHasPayloads := fieldInfo.HasPayloads()
+
+	if (!indexHasOffsets || (flags&DOCS_POSITIONS_ENUM_FLAG_OFF_SETS) == 0) &&
+		(!indexHasPayloads || (flags&DOCS_POSITIONS_ENUM_FLAG_PAYLOADS) == 0) {
+
+		var docsAndPositionsEnum *blockDocsAndPositionsEnum
+		if v, ok := reuse.(*blockDocsAndPositionsEnum); ok {
+			docsAndPositionsEnum = v
+			if !docsAndPositionsEnum.canReuse(r.docIn, fieldInfo) {
+				docsAndPositionsEnum = newBlockDocsAndPositionsEnum(r, fieldInfo)
+			}
+		} else {
+			docsAndPositionsEnum = newBlockDocsAndPositionsEnum(r, fieldInfo)
+		}
+		return docsAndPositionsEnum.reset(liveDocs, termState.Self.(*intBlockTermState))
+	}
+	return nil, fmt.Errorf("field %v: reading offsets and payloads is not supported yet", fieldInfo.Name)
+}
+
+/*
+Also handles payloads and offsets, but only skips over them: this
+enum is used when the caller doesn't need either of them.
+*/
+type blockDocsAndPositionsEnum struct {
+	*Lucene41PostingsReader // embedded struct
+
+	encoded []byte
+
+	docDeltaBuffer []int
+	freqBuffer     []int
+	posDeltaBuffer []int
+
+	docBufferUpto int
+	posBufferUpto int
+
+	startDocIn store.IndexInput
+
+	docIn            store.IndexInput
+	posIn            store.IndexInput
+	indexHasOffsets  bool
+	indexHasPayloads bool
+
+	docFreq       int   // number of docs in this posting list
+	totalTermFreq int64 // number of positions in this posting list
+	docUpto       int   // how many docs we've read
+	doc           int   // doc we last read
+	accum         int   // accumulator for doc deltas
+	freq          int   // freq we last read
+	position      int   // current position
+
+	// how many positions "behind" we are; nextPosition must
+	// skip these to "catch up":
+	posPendingCount int
+
+	// Lazy pos seek: if != -1 then we must seek to this FP
+	// before reading positions:
+	posPendingFP int64
+
+	// Where this term's postings start in the .doc file:
+	docTermStartFP int64
+
+	// Where this term's postings start in the .pos file:
+	posTermStartFP int64
+
+	// Where this term's payloads/offsets start in the .pay
+	// file:
+	payTermStartFP int64
+
+	// File pointer where the last (vInt encoded) pos delta
+	// block is. We need this to know whether to bulk
+	// decode vs vInt decode the block:
+	lastPosBlockFP int64
+
+	// Where this term's skip data starts (after
+	// docTermStartFP) in the .doc file (or -1 if there is
+	// no skip data for this term):
+	skipOffset int64
+
+	// docID for next skip point, we won't use skipper if
+	// target docID is not larger than this
+	nextSkipDoc int
+
+	liveDocs util.Bits
+
+	skipper *SkipReader
+	skipped bool
+
+	singletonDocID int // docid when there is a single pulsed posting, otherwise -1
+}
+
+func newBlockDocsAndPositionsEnum(owner *Lucene41PostingsReader,
+	fieldInfo *FieldInfo) *blockDocsAndPositionsEnum {
+
+	return &blockDocsAndPositionsEnum{
+		Lucene41PostingsReader: owner,
+		encoded:                make([]byte, MAX_ENCODED_SIZE),
+		docDeltaBuffer:         make([]int, MAX_DATA_SIZE),
+		freqBuffer:             make([]int, MAX_DATA_SIZE),
+		posDeltaBuffer:         make([]int, MAX_DATA_SIZE),
+		startDocIn:             owner.docIn,
+		posIn:                  owner.posIn.Clone(),
+		indexHasOffsets:        fieldInfo.IndexOptions() >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS,
+		indexHasPayloads:       fieldInfo.HasPayloads(),
+	}
+}
+
+func (de *blockDocsAndPositionsEnum) canReuse(docIn store.IndexInput, fieldInfo *FieldInfo) bool {
+	return docIn == de.startDocIn &&
+		de.indexHasOffsets == (fieldInfo.IndexOptions() >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS) &&
+		de.indexHasPayloads == fieldInfo.HasPayloads()
+}
+
+func (de *blockDocsAndPositionsEnum) reset(liveDocs util.Bits,
+	termState *intBlockTermState) (DocsAndPositionsEnum, error) {
+
+	de.liveDocs = liveDocs
+	// fmt.Println("  FPR.reset: termState=", termState)
+	de.docFreq = termState.DocFreq
+	de.docTermStartFP = termState.docStartFP
+	de.posTermStartFP = termState.posStartFP
+	de.payTermStartFP = termState.payStartFP
+	de.skipOffset = termState.skipOffset
+	de.totalTermFreq = termState.TotalTermFreq
+	de.singletonDocID = termState.singletonDocID
+	if de.docFreq > 1 {
+		if de.docIn == nil {
+			// lazy init
+			de.docIn = de.startDocIn.Clone()
+		}
+		if err := de.docIn.Seek(de.docTermStartFP); err != nil {
+			return nil, err
+		}
+	}
+	de.posPendingFP = de.posTermStartFP
+	de.posPendingCount = 0
+	if termState.TotalTermFreq < LUCENE41_BLOCK_SIZE {
+		de.lastPosBlockFP = de.posTermStartFP
+	} else if termState.TotalTermFreq == LUCENE41_BLOCK_SIZE {
+		de.lastPosBlockFP = -1
+	} else {
+		de.lastPosBlockFP = de.posTermStartFP + termState.lastPosBlockOffset
+	}
+
+	de.doc = -1
+	de.accum = 0
+	de.docUpto = 0
+	if de.docFreq > LUCENE41_BLOCK_SIZE {
+		de.nextSkipDoc = LUCENE41_BLOCK_SIZE - 1 // we won't skip if target is found in first block
+	} else {
+		de.nextSkipDoc = NO_MORE_DOCS // not enough docs for skipping
+	}
+	de.docBufferUpto = LUCENE41_BLOCK_SIZE
+	de.skipped = false
+	return de, nil
+}
+
+func (de *blockDocsAndPositionsEnum) Freq() (int, error) {
+	return de.freq, nil
+}
+
+func (de *blockDocsAndPositionsEnum) DocId() int {
+	return de.doc
+}
+
+func (de *blockDocsAndPositionsEnum) refillDocs() (err error) {
+	left := de.docFreq - de.docUpto
+	assert(left > 0)
+
+	if left >= LUCENE41_BLOCK_SIZE {
+		// fmt.Println("    fill doc block from fp=", de.docIn.FilePointer())
+		if err = de.forUtil.readBlock(de.docIn, de.encoded, de.docDeltaBuffer); err != nil {
+			return
+		}
+		// fmt.Println("    fill freq block from fp=", de.docIn.FilePointer())
+		if err = de.forUtil.readBlock(de.docIn, de.encoded, de.freqBuffer); err != nil {
+			return
+		}
+	} else if de.docFreq == 1 {
+		de.docDeltaBuffer[0] = de.singletonDocID
+		de.freqBuffer[0] = int(de.totalTermFreq)
 	} else {
-		fmt.Println("  now do nextDoc()")
+		// Read vInts:
+		// fmt.Println("    fill last vInt block from fp=", de.docIn.FilePointer())
+		err = readVIntBlock(de.docIn, de.docDeltaBuffer, de.freqBuffer, left, true)
+	}
+	de.docBufferUpto = 0
+	return
+}
+
+func (de *blockDocsAndPositionsEnum) refillPositions() error {
+	// fmt.Println("      refillPositions")
+	if de.posIn.FilePointer() == de.lastPosBlockFP {
+		// fmt.Println("        vInt pos block @ fp=", de.posIn.FilePointer())
+		count := int(de.totalTermFreq % LUCENE41_BLOCK_SIZE)
+		payloadLength := 0
+		for i := 0; i < count; i++ {
+			code, err := asInt(de.posIn.ReadVInt())
+			if err != nil {
+				return err
+			}
+			if de.indexHasPayloads {
+				if (code & 1) != 0 {
+					if payloadLength, err = asInt(de.posIn.ReadVInt()); err != nil {
+						return err
+					}
+				}
+				de.posDeltaBuffer[i] = int(uint(code) >> 1)
+				if payloadLength != 0 {
+					if err = de.posIn.Seek(de.posIn.FilePointer() + int64(payloadLength)); err != nil {
+						return err
+					}
+				}
+			} else {
+				de.posDeltaBuffer[i] = code
+			}
+			if de.indexHasOffsets {
+				n, err := de.posIn.ReadVInt()
+				if err != nil {
+					return err
+				}
+				if (n & 1) != 0 {
+					// offset length changed
+					if _, err = de.posIn.ReadVInt(); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	// fmt.Println("        bulk pos block @ fp=", de.posIn.FilePointer())
+	return de.forUtil.readBlock(de.posIn, de.encoded, de.posDeltaBuffer)
+}
+
+func (de *blockDocsAndPositionsEnum) NextDoc() (int, error) {
+	// fmt.Println("FPR.nextDoc")
+	for {
+		if de.docUpto == de.docFreq {
+			de.doc = NO_MORE_DOCS
+			return de.doc, nil
+		}
+		if de.docBufferUpto == LUCENE41_BLOCK_SIZE {
+			if err := de.refillDocs(); err != nil {
+				return 0, err
+			}
+		}
+		de.accum += de.docDeltaBuffer[de.docBufferUpto]
+		de.freq = de.freqBuffer[de.docBufferUpto]
+		de.posPendingCount += de.freq
 		de.docBufferUpto++
-		return de.NextDoc()
+		de.docUpto++
+
+		if de.liveDocs == nil || de.liveDocs.At(de.accum) {
+			de.doc = de.accum
+			de.position = 0
+			// fmt.Printf("  return doc=%v freq=%v posPendingCount=%v\n", de.doc, de.freq, de.posPendingCount)
+			return de.doc, nil
+		}
 	}
 }
+
+func (de *blockDocsAndPositionsEnum) Advance(target int) (int, error) {
+	// fmt.Printf("  FPR.advance target=%v\n", target)
+
+	// TODO: make frq block load lazy/skippable
+	if de.docFreq > LUCENE41_BLOCK_SIZE && target > de.nextSkipDoc {
+		if de.skipper == nil {
+			// Lazy init: first time this enum has ever been used for skipping
+			de.skipper = NewSkipReader(de.docIn.Clone(), maxSkipLevels,
+				LUCENE41_BLOCK_SIZE, true, de.indexHasOffsets, de.indexHasPayloads)
+		}
+
+		if !de.skipped {
+			assert(de.skipOffset != -1)
+			// This is the first time this enum has skipped since reset()
+			// was called; load the skip data:
+			de.skipper.Init(de.docTermStartFP+de.skipOffset, de.docTermStartFP,
+				de.posTermStartFP, de.payTermStartFP, de.docFreq)
+			de.skipped = true
+		}
+
+		newDocUpto, err := de.skipper.SkipTo(target)
+		if err != nil {
+			return 0, err
+		}
+		newDocUpto++
+
+		if newDocUpto > de.docUpto {
+			// Skipper moved
+			assert2(newDocUpto%LUCENE41_BLOCK_SIZE == 0, "got %v", newDocUpto)
+			de.docUpto = newDocUpto
+
+			// Force to read next block
+			de.docBufferUpto = LUCENE41_BLOCK_SIZE
+			de.accum = de.skipper.Doc()
+			if err = de.docIn.Seek(de.skipper.DocPointer()); err != nil {
+				return 0, err
+			}
+			de.posPendingFP = de.skipper.PosPointer()
+			de.posPendingCount = de.skipper.PosBufferUpto()
+		}
+		de.nextSkipDoc = de.skipper.NextSkipDoc()
+	}
+	if de.docUpto == de.docFreq {
+		de.doc = NO_MORE_DOCS
+		return de.doc, nil
+	}
+	if de.docBufferUpto == LUCENE41_BLOCK_SIZE {
+		if err := de.refillDocs(); err != nil {
+			return 0, err
+		}
+	}
+
+	// Now scan... this is an inlined/pared down version of nextDoc():
+	for {
+		de.accum += de.docDeltaBuffer[de.docBufferUpto]
+		de.freq = de.freqBuffer[de.docBufferUpto]
+		de.posPendingCount += de.freq
+		de.docBufferUpto++
+		de.docUpto++
+
+		if de.accum >= target {
+			break
+		}
+		if de.docUpto == de.docFreq {
+			de.doc = NO_MORE_DOCS
+			return de.doc, nil
+		}
+	}
+
+	if de.liveDocs == nil || de.liveDocs.At(de.accum) {
+		de.position = 0
+		de.doc = de.accum
+		return de.doc, nil
+	}
+	return de.NextDoc()
+}
+
+/*
+TODO: in theory we could avoid loading frq block when not needed, ie,
+use skip data to load how far to seek the pos pointer ... instead of
+having to load frq blocks for the skipper.
+*/
+func (de *blockDocsAndPositionsEnum) skipPositions() (err error) {
+	// Skip positions now:
+	toSkip := de.posPendingCount - de.freq
+	// fmt.Printf("      FPR.skipPositions: toSkip=%v\n", toSkip)
+
+	leftInBlock := LUCENE41_BLOCK_SIZE - de.posBufferUpto
+	if toSkip < leftInBlock {
+		de.posBufferUpto += toSkip
+		// fmt.Printf("        skip w/in block to posBufferUpto=%v\n", de.posBufferUpto)
+	} else {
+		toSkip -= leftInBlock
+		for toSkip >= LUCENE41_BLOCK_SIZE {
+			// fmt.Printf("        skip whole block @ fp=%v\n", de.posIn.FilePointer())
+			assert(de.posIn.FilePointer() != de.lastPosBlockFP)
+			if err = de.forUtil.skipBlock(de.posIn); err != nil {
+				return
+			}
+			toSkip -= LUCENE41_BLOCK_SIZE
+		}
+		if err = de.refillPositions(); err != nil {
+			return
+		}
+		de.posBufferUpto = toSkip
+		// fmt.Printf("        skip w/in block to posBufferUpto=%v\n", de.posBufferUpto)
+	}
+
+	de.position = 0
+	return nil
+}
+
+func (de *blockDocsAndPositionsEnum) NextPosition() (int, error) {
+	// fmt.Printf("    FPR.nextPosition posPendingCount=%v posBufferUpto=%v\n", de.posPendingCount, de.posBufferUpto)
+	if de.posPendingFP != -1 {
+		// fmt.Printf("      seek to pendingFP=%v\n", de.posPendingFP)
+		if err := de.posIn.Seek(de.posPendingFP); err != nil {
+			return 0, err
+		}
+		de.posPendingFP = -1
+
+		// Force buffer refill:
+		de.posBufferUpto = LUCENE41_BLOCK_SIZE
+	}
+
+	if de.posPendingCount > de.freq {
+		if err := de.skipPositions(); err != nil {
+			return 0, err
+		}
+		de.posPendingCount = de.freq
+	}
+
+	if de.posBufferUpto == LUCENE41_BLOCK_SIZE {
+		if err := de.refillPositions(); err != nil {
+			return 0, err
+		}
+		de.posBufferUpto = 0
+	}
+	de.position += de.posDeltaBuffer[de.posBufferUpto]
+	de.posBufferUpto++
+	de.posPendingCount--
+	// fmt.Printf("      return pos=%v\n", de.position)
+	return de.position, nil
+}
+
+func (de *blockDocsAndPositionsEnum) StartOffset() (int, error) {
+	return -1, nil
+}
+
+func (de *blockDocsAndPositionsEnum) EndOffset() (int, error) {
+	return -1, nil
+}
+
+func (de *blockDocsAndPositionsEnum) Payload() ([]byte, error) {
+	return nil, nil
+}
@@ -8,6 +8,10 @@ import (
 
 // lucene42/Lucene42DocValuesFormat.java
 
+func init() {
+	RegisterDocValuesFormat(NewLucene42DocValuesFormat())
+}
+
 /*
 Lucene 4.2 DocValues format.
 
@@ -252,6 +252,15 @@ func (dvp *Lucene42DocValuesProducer) SortedSet(field *FieldInfo) (v SortedSetDo
 	return nil, nil
 }
 
+func (dvp *Lucene42DocValuesProducer) DocsWithField(field *FieldInfo) (v util.Bits, err error) {
+	if field.DocValuesType() == DOC_VALUES_TYPE_SORTED_SET {
+		// docs with a value can only be told apart by reading the ords
+		return nil, errors.New(fmt.Sprintf(
+			"reading sorted set doc values is not supported yet, field=%v", field.Name))
+	}
+	return util.NewMatchAllBits(dvp.maxDoc), nil
+}
+
 func (dvp *Lucene42DocValuesProducer) Close() error {
 	if dvp == nil {
 		return nil
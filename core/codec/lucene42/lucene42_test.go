@@ -1,6 +1,7 @@
 package lucene42
 
 import (
+	. "github.com/gzg1984/golucene/core/index/model"
 	"github.com/gzg1984/golucene/core/store"
 	"testing"
 )
@@ -24,3 +25,22 @@ func TestReadFieldInfos(t *testing.T) {
 		t.Errorf("hasNorms must be true and hasDocValues must be false, but found %v", fis)
 	}
 }
+
+func TestDocsWithFieldOfSortedTypes(t *testing.T) {
+	dvp := &Lucene42DocValuesProducer{maxDoc: 5}
+	sorted := NewFieldInfo("color", true, 0, false, false, false,
+		INDEX_OPT_DOCS_ONLY, DOC_VALUES_TYPE_SORTED, 0, -1, nil)
+	bits, err := dvp.DocsWithField(sorted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bits.Length() != 5 || !bits.At(3) {
+		t.Errorf("expected all 5 docs to have a sorted value, but %v", bits)
+	}
+
+	sortedSet := NewFieldInfo("tags", true, 1, false, false, false,
+		INDEX_OPT_DOCS_ONLY, DOC_VALUES_TYPE_SORTED_SET, 0, -1, nil)
+	if _, err = dvp.DocsWithField(sortedSet); err == nil {
+		t.Error("expected an error for sorted set doc values")
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/gzg1984/golucene/core/codec/lucene42"
 	"github.com/gzg1984/golucene/core/codec/perfield"
 	. "github.com/gzg1984/golucene/core/codec/spi"
-	. "github.com/gzg1984/golucene/core/index/model"
 )
 
 // codec/lucene45/Lucene45Codec.java
@@ -42,7 +41,7 @@ var Lucene45CodecImpl = func() *Lucene45Codec {
 			return LoadPostingsFormat("Lucene41")
 		}),
 		perfield.NewPerFieldDocValuesFormat(func(field string) DocValuesFormat {
-			panic("not implemented yet")
+			return LoadDocValuesFormat("Lucene45")
 		}),
 		lucene42.NewLucene42NormsFormat(),
 	)
@@ -50,24 +49,3 @@ var Lucene45CodecImpl = func() *Lucene45Codec {
 		CodecImpl: codec,
 	}
 }()
-
-// codec/lucene45/Lucene45DocValuesFormat.java
-
-const (
-	LUCENE45_DV_DATA_CODEC     = "Lucene45DocValuesData"
-	LUCENE45_DV_DATA_EXTENSION = "dvd"
-	LUCENE45_DV_META_CODEC     = "Lucene45valuesMetadata"
-	LUCENE45_DV_META_EXTENSION = "dvm"
-)
-
-// codec/lucene45/Lucene45DocValuesProducer.java
-
-type Lucene45DocvaluesProducer struct {
-}
-
-// expert: instantiate a new reader
-func newLucene45DocValuesProducer(
-	state SegmentReadState, dataCodec, dataExtension, metaCodec, metaExtension string) (
-	dvp *lucene42.Lucene42DocValuesProducer, err error) {
-	panic("not implemented yet")
-}
@@ -0,0 +1,607 @@
+package lucene45
+
+import (
+	"github.com/gzg1984/golucene/core/codec"
+	. "github.com/gzg1984/golucene/core/index/model"
+	"github.com/gzg1984/golucene/core/store"
+	"github.com/gzg1984/golucene/core/util"
+	"github.com/gzg1984/golucene/core/util/packed"
+	"math"
+	"sort"
+)
+
+// lucene45/Lucene45DocValuesConsumer.java
+
+const (
+	BLOCK_SIZE = 16384
+	// Every n-th term of a prefix-compressed terms dict is addressed.
+	ADDRESS_INTERVAL = 16
+
+	// Compressed using packed blocks of ints.
+	DELTA_COMPRESSED = 0
+	// Compressed by computing the GCD.
+	GCD_COMPRESSED = 1
+	// Compressed by giving IDs to unique values.
+	TABLE_COMPRESSED = 2
+
+	// Uncompressed binary, written directly (fixed length).
+	BINARY_FIXED_UNCOMPRESSED = 0
+	// Uncompressed binary, written directly (variable length).
+	BINARY_VARIABLE_UNCOMPRESSED = 1
+	// Compressed binary with shared prefixes
+	BINARY_PREFIX_COMPRESSED = 2
+
+	// Standard storage for sorted set values with 1 level of
+	// indirection: docId -> address -> ord.
+	SORTED_SET_WITH_ADDRESSES = 0
+	// Single-valued sorted set values, encoded as sorted values, so no
+	// level of indirection: docId -> ord.
+	SORTED_SET_SINGLE_VALUED_SORTED = 1
+)
+
+/* Writer for Lucene45DocValuesFormat */
+type Lucene45DocValuesConsumer struct {
+	data, meta store.IndexOutput
+	maxDoc     int
+}
+
+func newLucene45DocValuesConsumer(state *SegmentWriteState,
+	dataCodec, dataExtension, metaCodec, metaExtension string) (dvc *Lucene45DocValuesConsumer, err error) {
+
+	dvc = &Lucene45DocValuesConsumer{maxDoc: state.SegmentInfo.DocCount()}
+	var success = false
+	defer func() {
+		if !success {
+			util.CloseWhileSuppressingError(dvc)
+		}
+	}()
+
+	dataName := util.SegmentFileName(state.SegmentInfo.Name, state.SegmentSuffix, dataExtension)
+	if dvc.data, err = state.Directory.CreateOutput(dataName, state.Context); err != nil {
+		return nil, err
+	}
+	if err = codec.WriteHeader(dvc.data, dataCodec, LUCENE45_DV_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	metaName := util.SegmentFileName(state.SegmentInfo.Name, state.SegmentSuffix, metaExtension)
+	if dvc.meta, err = state.Directory.CreateOutput(metaName, state.Context); err != nil {
+		return nil, err
+	}
+	if err = codec.WriteHeader(dvc.meta, metaCodec, LUCENE45_DV_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	success = true
+	return dvc, nil
+}
+
+/* Converts an ord, ord count or numeric value to int64. */
+func asInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case int32:
+		return int64(n)
+	}
+	panic("assert fail")
+}
+
+func (dvc *Lucene45DocValuesConsumer) AddNumericField(field *FieldInfo,
+	values func() func() (interface{}, bool)) error {
+	return dvc.addNumericField(field, values, true)
+}
+
+func (dvc *Lucene45DocValuesConsumer) addNumericField(field *FieldInfo,
+	values func() func() (interface{}, bool), optimizeStorage bool) (err error) {
+
+	count := int64(0)
+	minValue, maxValue := int64(math.MaxInt64), int64(math.MinInt64)
+	gcd := int64(0)
+	missing := false
+	// TODO: more efficient?
+	var uniqueValues map[int64]bool
+	if optimizeStorage {
+		uniqueValues = make(map[int64]bool)
+
+		next := values()
+		for nv, ok := next(); ok; nv, ok = next() {
+			var v int64
+			if nv == nil {
+				missing = true
+			} else {
+				v = asInt64(nv)
+			}
+
+			if gcd != 1 {
+				if v < math.MinInt64/2 || v > math.MaxInt64/2 {
+					// in that case v - minValue might overflow and make the GCD
+					// computation return wrong results. Since these extreme
+					// values are unlikely, we just discard GCD computation for
+					// them
+					gcd = 1
+				} else if count != 0 { // minValue needs to be set first
+					gcd = util.Gcd(gcd, v-minValue)
+				}
+			}
+
+			if v < minValue {
+				minValue = v
+			}
+			if v > maxValue {
+				maxValue = v
+			}
+
+			if uniqueValues != nil && !uniqueValues[v] {
+				if uniqueValues[v] = true; len(uniqueValues) > 256 {
+					uniqueValues = nil
+				}
+			}
+
+			count++
+		}
+	} else {
+		next := values()
+		for _, ok := next(); ok; _, ok = next() {
+			count++
+		}
+	}
+
+	delta := maxValue - minValue
+
+	var format int
+	if uniqueValues != nil &&
+		(delta < 0 || packed.BitsRequired(int64(len(uniqueValues))-1) < packed.BitsRequired(delta)) &&
+		count <= math.MaxInt32 {
+		format = TABLE_COMPRESSED
+	} else if gcd != 0 && gcd != 1 {
+		format = GCD_COMPRESSED
+	} else {
+		format = DELTA_COMPRESSED
+	}
+	if err = dvc.meta.WriteVInt(field.Number); err != nil {
+		return
+	}
+	if err = dvc.meta.WriteByte(LUCENE45_DV_NUMERIC); err != nil {
+		return
+	}
+	if err = dvc.meta.WriteVInt(int32(format)); err != nil {
+		return
+	}
+	if missing {
+		if err = dvc.meta.WriteLong(dvc.data.FilePointer()); err != nil {
+			return
+		}
+		if err = dvc.writeMissingBitset(values); err != nil {
+			return
+		}
+	} else {
+		if err = dvc.meta.WriteLong(-1); err != nil {
+			return
+		}
+	}
+	if err = store.Stream(dvc.meta).WriteVInt(packed.VERSION_CURRENT).
+		WriteLong(dvc.data.FilePointer()).
+		WriteVLong(count).
+		WriteVInt(BLOCK_SIZE).
+		Close(); err != nil {
+		return
+	}
+
+	switch format {
+	case GCD_COMPRESSED:
+		if err = store.Stream(dvc.meta).WriteLong(minValue).
+			WriteLong(gcd).
+			Close(); err != nil {
+			return
+		}
+		quotientWriter := packed.NewBlockPackedWriter(dvc.data, BLOCK_SIZE)
+		next := values()
+		for nv, ok := next(); ok; nv, ok = next() {
+			var value int64
+			if nv != nil {
+				value = asInt64(nv)
+			}
+			if err = quotientWriter.Add((value - minValue) / gcd); err != nil {
+				return
+			}
+		}
+		return quotientWriter.Finish()
+	case DELTA_COMPRESSED:
+		writer := packed.NewBlockPackedWriter(dvc.data, BLOCK_SIZE)
+		next := values()
+		for nv, ok := next(); ok; nv, ok = next() {
+			var value int64
+			if nv != nil {
+				value = asInt64(nv)
+			}
+			if err = writer.Add(value); err != nil {
+				return
+			}
+		}
+		return writer.Finish()
+	case TABLE_COMPRESSED:
+		decode := make([]int64, 0, len(uniqueValues))
+		for v, _ := range uniqueValues {
+			decode = append(decode, v)
+		}
+		sort.Sort(int64Slice(decode))
+		encode := make(map[int64]int)
+		if err = dvc.meta.WriteVInt(int32(len(decode))); err != nil {
+			return
+		}
+		for i, v := range decode {
+			if err = dvc.meta.WriteLong(v); err != nil {
+				return
+			}
+			encode[v] = i
+		}
+		bitsRequired := packed.BitsRequired(int64(len(uniqueValues)) - 1)
+		ordsWriter := packed.WriterNoHeader(dvc.data, packed.PackedFormat(packed.PACKED),
+			int(count), bitsRequired, packed.DEFAULT_BUFFER_SIZE)
+		next := values()
+		for nv, ok := next(); ok; nv, ok = next() {
+			var value int64
+			if nv != nil {
+				value = asInt64(nv)
+			}
+			if err = ordsWriter.Add(int64(encode[value])); err != nil {
+				return
+			}
+		}
+		return ordsWriter.Finish()
+	default:
+		panic("assert fail")
+	}
+}
+
+type int64Slice []int64
+
+func (a int64Slice) Len() int           { return len(a) }
+func (a int64Slice) Less(i, j int) bool { return a[i] < a[j] }
+func (a int64Slice) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+/*
+TODO: in some cases representing missing with minValue-1 wouldn't
+take up additional space and so on, but this is very simple, and
+algorithms only check this for values of 0 anyway (doesnt slow down
+normal decode)
+*/
+func (dvc *Lucene45DocValuesConsumer) writeMissingBitset(values func() func() (interface{}, bool)) error {
+	var bits byte
+	count := 0
+	next := values()
+	for v, ok := next(); ok; v, ok = next() {
+		if count == 8 {
+			if err := dvc.data.WriteByte(bits); err != nil {
+				return err
+			}
+			count = 0
+			bits = 0
+		}
+		if v != nil {
+			bits |= 1 << uint(count&7)
+		}
+		count++
+	}
+	if count > 0 {
+		return dvc.data.WriteByte(bits)
+	}
+	return nil
+}
+
+func (dvc *Lucene45DocValuesConsumer) AddBinaryField(field *FieldInfo,
+	values func() func() (interface{}, bool)) (err error) {
+
+	// write the []byte data
+	if err = dvc.meta.WriteVInt(field.Number); err != nil {
+		return
+	}
+	if err = dvc.meta.WriteByte(LUCENE45_DV_BINARY); err != nil {
+		return
+	}
+	minLength, maxLength := math.MaxInt32, math.MinInt32
+	startFP := dvc.data.FilePointer()
+	count := int64(0)
+	missing := false
+	next := values()
+	for v, ok := next(); ok; v, ok = next() {
+		var length int
+		if v == nil {
+			missing = true
+		} else {
+			length = len(v.([]byte))
+		}
+		if length < minLength {
+			minLength = length
+		}
+		if length > maxLength {
+			maxLength = length
+		}
+		if v != nil {
+			if err = dvc.data.WriteBytes(v.([]byte)); err != nil {
+				return
+			}
+		}
+		count++
+	}
+	format := BINARY_VARIABLE_UNCOMPRESSED
+	if minLength == maxLength {
+		format = BINARY_FIXED_UNCOMPRESSED
+	}
+	if err = dvc.meta.WriteVInt(int32(format)); err != nil {
+		return
+	}
+	if missing {
+		if err = dvc.meta.WriteLong(dvc.data.FilePointer()); err != nil {
+			return
+		}
+		if err = dvc.writeMissingBitset(values); err != nil {
+			return
+		}
+	} else {
+		if err = dvc.meta.WriteLong(-1); err != nil {
+			return
+		}
+	}
+	if err = store.Stream(dvc.meta).WriteVInt(int32(minLength)).
+		WriteVInt(int32(maxLength)).
+		WriteVLong(count).
+		WriteLong(startFP).
+		Close(); err != nil {
+		return
+	}
+
+	// if minLength == maxLength, its a fixed-length []byte, we are done
+	// (the addresses are implicit) otherwise, we need to record the
+	// length fields...
+	if minLength != maxLength {
+		if err = store.Stream(dvc.meta).WriteLong(dvc.data.FilePointer()).
+			WriteVInt(packed.VERSION_CURRENT).
+			WriteVInt(BLOCK_SIZE).
+			Close(); err != nil {
+			return
+		}
+
+		writer := packed.NewMonotonicBlockPackedWriter(dvc.data, BLOCK_SIZE)
+		addr := int64(0)
+		next = values()
+		for v, ok := next(); ok; v, ok = next() {
+			if v != nil {
+				addr += int64(len(v.([]byte)))
+			}
+			if err = writer.Add(addr); err != nil {
+				return
+			}
+		}
+		return writer.Finish()
+	}
+	return nil
+}
+
+/*
+Writes the deduplicated terms of a sorted or sorted set field.
+Variable-length terms are prefix-compressed: each term only records
+the suffix it doesn't share with the previous one, and every
+ADDRESS_INTERVAL-th term is written in full and indexed by address.
+*/
+func (dvc *Lucene45DocValuesConsumer) addTermsDict(field *FieldInfo,
+	values func() func() (interface{}, bool)) (err error) {
+
+	// first check if its a "fixed-length" terms dict
+	minLength, maxLength := math.MaxInt32, math.MinInt32
+	next := values()
+	for v, ok := next(); ok; v, ok = next() {
+		length := len(v.([]byte))
+		if length < minLength {
+			minLength = length
+		}
+		if length > maxLength {
+			maxLength = length
+		}
+	}
+	if minLength == maxLength {
+		// no index needed: direct addressing by mult
+		return dvc.AddBinaryField(field, values)
+	}
+
+	// header
+	if err = dvc.meta.WriteVInt(field.Number); err != nil {
+		return
+	}
+	if err = dvc.meta.WriteByte(LUCENE45_DV_BINARY); err != nil {
+		return
+	}
+	if err = store.Stream(dvc.meta).WriteVInt(BINARY_PREFIX_COMPRESSED).
+		WriteLong(-1).
+		Close(); err != nil {
+		return
+	}
+	// now write the bytes: sharing prefixes within a block
+	startFP := dvc.data.FilePointer()
+	// currently, we have to store the delta from expected for every
+	// 1/nth term
+	addressBuffer := store.NewRAMOutputStreamBuffer()
+	termAddresses := packed.NewMonotonicBlockPackedWriter(addressBuffer, BLOCK_SIZE)
+	var lastTerm []byte
+	count := int64(0)
+	next = values()
+	for v, ok := next(); ok; v, ok = next() {
+		term := v.([]byte)
+		if count%ADDRESS_INTERVAL == 0 {
+			if err = termAddresses.Add(dvc.data.FilePointer() - startFP); err != nil {
+				return
+			}
+			// force the first term in a block to be abs-encoded
+			lastTerm = lastTerm[:0]
+		}
+
+		// prefix-code
+		sharedPrefix := 0
+		for sharedPrefix < len(lastTerm) && sharedPrefix < len(term) &&
+			lastTerm[sharedPrefix] == term[sharedPrefix] {
+			sharedPrefix++
+		}
+		if err = store.Stream(dvc.data).WriteVInt(int32(sharedPrefix)).
+			WriteVInt(int32(len(term) - sharedPrefix)).
+			WriteBytes(term[sharedPrefix:]).
+			Close(); err != nil {
+			return
+		}
+		lastTerm = append(lastTerm[:0], term...)
+		count++
+	}
+	indexStartFP := dvc.data.FilePointer()
+	// write addresses of indexed terms
+	if err = termAddresses.Finish(); err != nil {
+		return
+	}
+	if err = addressBuffer.WriteTo(dvc.data); err != nil {
+		return
+	}
+	return store.Stream(dvc.meta).WriteVInt(int32(minLength)).
+		WriteVInt(int32(maxLength)).
+		WriteVLong(count).
+		WriteLong(startFP).
+		WriteVInt(ADDRESS_INTERVAL).
+		WriteLong(indexStartFP).
+		WriteVInt(packed.VERSION_CURRENT).
+		WriteVInt(BLOCK_SIZE).
+		Close()
+}
+
+func (dvc *Lucene45DocValuesConsumer) AddSortedField(field *FieldInfo,
+	values, docToOrd func() func() (interface{}, bool)) (err error) {
+
+	if err = dvc.meta.WriteVInt(field.Number); err != nil {
+		return
+	}
+	if err = dvc.meta.WriteByte(LUCENE45_DV_SORTED); err != nil {
+		return
+	}
+	if err = dvc.addTermsDict(field, values); err != nil {
+		return
+	}
+	return dvc.addNumericField(field, docToOrd, false)
+}
+
+func isSingleValued(docToOrdCount func() func() (interface{}, bool)) bool {
+	next := docToOrdCount()
+	for ordCount, ok := next(); ok; ordCount, ok = next() {
+		if asInt64(ordCount) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Returns the ords of a single-valued sorted set field, one per document,
+-1 for documents without value.
+*/
+func singletonView(docToOrdCount, ords func() func() (interface{}, bool)) func() func() (interface{}, bool) {
+	return func() func() (interface{}, bool) {
+		nextCount, nextOrd := docToOrdCount(), ords()
+		return func() (interface{}, bool) {
+			ordCount, ok := nextCount()
+			if !ok {
+				return nil, false
+			}
+			if asInt64(ordCount) == 0 {
+				return int64(-1), true
+			}
+			ord, ok := nextOrd()
+			assert(ok)
+			return asInt64(ord), true
+		}
+	}
+}
+
+func (dvc *Lucene45DocValuesConsumer) AddSortedSetField(field *FieldInfo,
+	values, docToOrdCount, ords func() func() (interface{}, bool)) (err error) {
+
+	if err = dvc.meta.WriteVInt(field.Number); err != nil {
+		return
+	}
+	if err = dvc.meta.WriteByte(LUCENE45_DV_SORTED_SET); err != nil {
+		return
+	}
+
+	if isSingleValued(docToOrdCount) {
+		if err = dvc.meta.WriteVInt(SORTED_SET_SINGLE_VALUED_SORTED); err != nil {
+			return
+		}
+		// The field is single-valued, we can encode it as SORTED
+		return dvc.AddSortedField(field, values, singletonView(docToOrdCount, ords))
+	}
+
+	if err = dvc.meta.WriteVInt(SORTED_SET_WITH_ADDRESSES); err != nil {
+		return
+	}
+
+	// write the ord -> []byte as a binary field
+	if err = dvc.addTermsDict(field, values); err != nil {
+		return
+	}
+
+	// write the stream of ords as a numeric field
+	// NOTE: we could return an iterator that delta-encodes these within a doc
+	if err = dvc.addNumericField(field, ords, false); err != nil {
+		return
+	}
+
+	// write the doc -> ord count as a absolute index to the stream
+	if err = dvc.meta.WriteVInt(field.Number); err != nil {
+		return
+	}
+	if err = dvc.meta.WriteByte(LUCENE45_DV_NUMERIC); err != nil {
+		return
+	}
+	if err = store.Stream(dvc.meta).WriteVInt(DELTA_COMPRESSED).
+		WriteLong(-1).
+		WriteVInt(packed.VERSION_CURRENT).
+		WriteLong(dvc.data.FilePointer()).
+		WriteVLong(int64(dvc.maxDoc)).
+		WriteVInt(BLOCK_SIZE).
+		Close(); err != nil {
+		return
+	}
+
+	writer := packed.NewMonotonicBlockPackedWriter(dvc.data, BLOCK_SIZE)
+	addr := int64(0)
+	next := docToOrdCount()
+	for v, ok := next(); ok; v, ok = next() {
+		addr += asInt64(v)
+		if err = writer.Add(addr); err != nil {
+			return
+		}
+	}
+	return writer.Finish()
+}
+
+func (dvc *Lucene45DocValuesConsumer) Close() (err error) {
+	var success = false
+	defer func() {
+		if success {
+			err = util.Close(dvc.data, dvc.meta)
+		} else {
+			util.CloseWhileSuppressingError(dvc.data, dvc.meta)
+		}
+	}()
+
+	if dvc.meta != nil {
+		if err = dvc.meta.WriteVInt(-1); err != nil { // write EOF marker
+			return
+		}
+		if err = codec.WriteFooter(dvc.meta); err != nil { // write checksum
+			return
+		}
+	}
+	if dvc.data != nil {
+		if err = codec.WriteFooter(dvc.data); err != nil { // write checksum
+			return
+		}
+	}
+	success = true
+	return nil
+}
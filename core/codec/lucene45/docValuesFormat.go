@@ -0,0 +1,131 @@
+package lucene45
+
+import (
+	"fmt"
+	. "github.com/gzg1984/golucene/core/codec/spi"
+	. "github.com/gzg1984/golucene/core/index/model"
+)
+
+// lucene45/Lucene45DocValuesFormat.java
+
+func init() {
+	RegisterDocValuesFormat(NewLucene45DocValuesFormat())
+}
+
+const (
+	LUCENE45_DV_DATA_CODEC     = "Lucene45DocValuesData"
+	LUCENE45_DV_DATA_EXTENSION = "dvd"
+	LUCENE45_DV_META_CODEC     = "Lucene45ValuesMetadata"
+	LUCENE45_DV_META_EXTENSION = "dvm"
+
+	LUCENE45_DV_VERSION_START                    = 0
+	LUCENE45_DV_VERSION_SORTED_SET_SINGLE_VALUED = 1
+	LUCENE45_DV_VERSION_CHECKSUM                 = 2
+	LUCENE45_DV_VERSION_CURRENT                  = LUCENE45_DV_VERSION_CHECKSUM
+
+	LUCENE45_DV_NUMERIC    = 0
+	LUCENE45_DV_BINARY     = 1
+	LUCENE45_DV_SORTED     = 2
+	LUCENE45_DV_SORTED_SET = 3
+)
+
+/*
+Lucene 4.5 DocValues format.
+
+Encodes the four per-document value types (Numeric, Binary, Sorted,
+SortedSet) with these strategies:
+
+NUMERIC:
+
+  - Delta-compressed: per-document integers written in blocks of 16k.
+    For each block the minimum value in that block is encoded, and each
+    entry is a delta from that minimum value. Each block of deltas is
+    compressed with bitpacking.
+  - Table-compressed: when the number of unique values is very small
+    (< 256), and when there are unused "gaps" in the range of values
+    used (such as SmallFloat), a lookup table is written instead. Each
+    per-document entry is instead the ordinal to this table, and those
+    ordinals are compressed with bitpacking.
+  - GCD-compressed: when all numbers share a common divisor, such as
+    dates, the greatest common denominator (GCD) is computed, and
+    quotients are stored using Delta-compressed Numerics.
+
+BINARY:
+
+  - Fixed-width Binary: one large concatenated []byte is written, along
+    with the fixed length. Each document's value can be addressed
+    directly with multiplication (docID * length).
+  - Variable-width Binary: one large concatenated []byte is written,
+    along with end addresses for each document. The addresses are
+    written in blocks of 16k, with the current absolute start for the
+    block, and the average (expected) delta per entry. For each
+    document the deviation from the delta (actual - expected) is
+    written.
+  - Prefix-compressed Binary: values are written in chunks of 16, with
+    the first value written completely and other values sharing
+    prefixes. Chunk addresses are written in blocks of 16k, with the
+    current absolute start for the block, and the average (expected)
+    delta per entry. This format is only read, never written by this
+    implementation.
+
+SORTED:
+
+  - Sorted: a mapping of ordinals to deduplicated terms is written as
+    Binary, along with the per-document ordinals written using one of
+    the numeric strategies above.
+
+SORTED_SET:
+
+  - SortedSet: a mapping of ordinals to deduplicated terms is written
+    as Binary, an ordinal list and per-document index into this list
+    are written using the numeric strategies above.
+  - SortedSet (single-valued): when no document has more than one
+    value, the field is written as Sorted instead.
+
+Files:
+
+1. .dvd: DocValues data
+2. .dvm: DocValues metadata
+
+The DocValues metadata or .dvm file stores, for each DocValues field,
+metadata such as the offset into the DocValues data (.dvd), the
+compression strategy and the offset of the missing bitset, if any
+document has no value for the field. The DocValues data or .dvd file
+stores the actual per-document data (the heavy-lifting).
+
+Unlike Lucene Java, the producer loads the values of a field into
+memory on first access.
+*/
+type Lucene45DocValuesFormat struct{}
+
+func NewLucene45DocValuesFormat() *Lucene45DocValuesFormat {
+	return &Lucene45DocValuesFormat{}
+}
+
+func (f *Lucene45DocValuesFormat) Name() string {
+	return "Lucene45"
+}
+
+func (f *Lucene45DocValuesFormat) FieldsConsumer(state *SegmentWriteState) (w DocValuesConsumer, err error) {
+	return newLucene45DocValuesConsumer(state,
+		LUCENE45_DV_DATA_CODEC, LUCENE45_DV_DATA_EXTENSION,
+		LUCENE45_DV_META_CODEC, LUCENE45_DV_META_EXTENSION)
+}
+
+func (f *Lucene45DocValuesFormat) FieldsProducer(state SegmentReadState) (r DocValuesProducer, err error) {
+	return newLucene45DocValuesProducer(state,
+		LUCENE45_DV_DATA_CODEC, LUCENE45_DV_DATA_EXTENSION,
+		LUCENE45_DV_META_CODEC, LUCENE45_DV_META_EXTENSION)
+}
+
+func assert(ok bool) {
+	if !ok {
+		panic("assert fail")
+	}
+}
+
+func assert2(ok bool, msg string, args ...interface{}) {
+	if !ok {
+		panic(fmt.Sprintf(msg, args...))
+	}
+}
@@ -0,0 +1,835 @@
+package lucene45
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gzg1984/golucene/core/codec"
+	. "github.com/gzg1984/golucene/core/codec/spi"
+	. "github.com/gzg1984/golucene/core/index/model"
+	"github.com/gzg1984/golucene/core/store"
+	"github.com/gzg1984/golucene/core/util"
+	"github.com/gzg1984/golucene/core/util/packed"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// lucene45/Lucene45DocValuesProducer.java
+
+/* Metadata entry for a numeric docvalues field */
+type numericEntry struct {
+	format int
+	// offset to the bitset representing docsWithField, or -1 if no
+	// documents have missing values
+	missingOffset     int64
+	packedIntsVersion int32
+	// offset to the actual numeric values
+	offset    int64
+	count     int64
+	blockSize int
+	minValue  int64
+	gcd       int64
+	table     []int64
+}
+
+/* Metadata entry for a binary docvalues field */
+type binaryEntry struct {
+	format int
+	// offset to the bitset representing docsWithField, or -1 if no
+	// documents have missing values
+	missingOffset int64
+	// offset to the actual binary values
+	offset               int64
+	count                int64
+	minLength, maxLength int
+	// offset to the addressing data that maps a value to its slice of
+	// the []byte
+	addressesOffset   int64
+	addressInterval   int
+	packedIntsVersion int32
+	blockSize         int
+}
+
+/* Metadata entry for a sorted-set docvalues field */
+type sortedSetEntry struct {
+	format int
+}
+
+/* Reader for Lucene45DocValuesFormat */
+type Lucene45DocValuesProducer struct {
+	sync.Locker
+
+	numerics   map[int]*numericEntry
+	binaries   map[int]*binaryEntry
+	sortedSets map[int]*sortedSetEntry
+	ords       map[int]*numericEntry
+	ordIndexes map[int]*numericEntry
+	data       store.IndexInput
+	maxDoc     int
+	version    int32
+
+	numericInstances   map[int]func(int64) int64
+	binaryInstances    map[int]*binaryDocValues
+	sortedInstances    map[int]*sortedDocValues
+	ordIndexInstances  map[int]*packed.MonotonicBlockPackedReader
+	sortedSetInstances map[int]func(int64) int64
+
+	ramBytesUsed int64 // atomic
+}
+
+/* expert: instantiates a new reader */
+func newLucene45DocValuesProducer(state SegmentReadState,
+	dataCodec, dataExtension, metaCodec, metaExtension string) (dvp *Lucene45DocValuesProducer, err error) {
+
+	dvp = &Lucene45DocValuesProducer{
+		Locker:             new(sync.Mutex),
+		numerics:           make(map[int]*numericEntry),
+		binaries:           make(map[int]*binaryEntry),
+		sortedSets:         make(map[int]*sortedSetEntry),
+		ords:               make(map[int]*numericEntry),
+		ordIndexes:         make(map[int]*numericEntry),
+		maxDoc:             state.SegmentInfo.DocCount(),
+		numericInstances:   make(map[int]func(int64) int64),
+		binaryInstances:    make(map[int]*binaryDocValues),
+		sortedInstances:    make(map[int]*sortedDocValues),
+		ordIndexInstances:  make(map[int]*packed.MonotonicBlockPackedReader),
+		sortedSetInstances: make(map[int]func(int64) int64),
+		ramBytesUsed:       util.ShallowSizeOfInstance(reflect.TypeOf(dvp)),
+	}
+	metaName := util.SegmentFileName(state.SegmentInfo.Name, state.SegmentSuffix, metaExtension)
+	// read in the entries from the metadata file.
+	var in store.ChecksumIndexInput
+	if in, err = state.Dir.OpenChecksumInput(metaName, state.Context); err != nil {
+		return nil, err
+	}
+
+	if err = func() error {
+		var success = false
+		defer func() {
+			if success {
+				err = util.Close(in)
+			} else {
+				util.CloseWhileSuppressingError(in)
+			}
+		}()
+
+		if dvp.version, err = codec.CheckHeader(in, metaCodec,
+			LUCENE45_DV_VERSION_START, LUCENE45_DV_VERSION_CURRENT); err != nil {
+			return err
+		}
+		if err = dvp.readFields(in); err != nil {
+			return err
+		}
+		if dvp.version >= LUCENE45_DV_VERSION_CHECKSUM {
+			_, err = codec.CheckFooter(in)
+		} else {
+			err = codec.CheckEOF(in)
+		}
+		if err != nil {
+			return err
+		}
+		success = true
+		return nil
+	}(); err != nil {
+		return nil, err
+	}
+
+	dataName := util.SegmentFileName(state.SegmentInfo.Name, state.SegmentSuffix, dataExtension)
+	if dvp.data, err = state.Dir.OpenInput(dataName, state.Context); err != nil {
+		return nil, err
+	}
+	var success = false
+	defer func() {
+		if !success {
+			util.CloseWhileSuppressingError(dvp.data)
+		}
+	}()
+
+	var version2 int32
+	if version2, err = codec.CheckHeader(dvp.data, dataCodec,
+		LUCENE45_DV_VERSION_START, LUCENE45_DV_VERSION_CURRENT); err != nil {
+		return nil, err
+	}
+	if version2 != dvp.version {
+		return nil, errors.New("Format versions mismatch")
+	}
+
+	if dvp.version >= LUCENE45_DV_VERSION_CHECKSUM {
+		// NOTE: data file is too costly to verify checksum against all
+		// the bytes on open, but for now we at least verify proper
+		// structure of the checksum footer: which looks for FOOTER_MAGIC
+		// + algorithmID. This is cheap and can detect some forms of
+		// corruption such as file truncation.
+		if _, err = codec.RetrieveChecksum(dvp.data); err != nil {
+			return nil, err
+		}
+	}
+
+	success = true
+	return dvp, nil
+}
+
+func (dvp *Lucene45DocValuesProducer) readSortedField(fieldNumber int, meta store.IndexInput) error {
+	// sorted = binary + numeric
+	if err := expectEntry(meta, fieldNumber, LUCENE45_DV_BINARY); err != nil {
+		return err
+	}
+	b, err := readBinaryEntry(meta)
+	if err != nil {
+		return err
+	}
+	dvp.binaries[fieldNumber] = b
+
+	if err = expectEntry(meta, fieldNumber, LUCENE45_DV_NUMERIC); err != nil {
+		return err
+	}
+	n, err := readNumericEntry(meta)
+	if err != nil {
+		return err
+	}
+	dvp.ords[fieldNumber] = n
+	return nil
+}
+
+func (dvp *Lucene45DocValuesProducer) readSortedSetFieldWithAddresses(fieldNumber int, meta store.IndexInput) error {
+	// sortedset = binary + numeric (addresses) + ordIndex
+	if err := dvp.readSortedField(fieldNumber, meta); err != nil {
+		return err
+	}
+
+	if err := expectEntry(meta, fieldNumber, LUCENE45_DV_NUMERIC); err != nil {
+		return err
+	}
+	n, err := readNumericEntry(meta)
+	if err != nil {
+		return err
+	}
+	dvp.ordIndexes[fieldNumber] = n
+	return nil
+}
+
+/* Checks the field number and type of the next sub-entry of a composite field. */
+func expectEntry(meta store.IndexInput, fieldNumber int, typ byte) error {
+	n, err := meta.ReadVInt()
+	if err != nil {
+		return err
+	}
+	if int(n) != fieldNumber {
+		return errors.New(fmt.Sprintf(
+			"field entry mismatch (expected %v, got %v) (resource=%v)",
+			fieldNumber, n, meta))
+	}
+	t, err := meta.ReadByte()
+	if err != nil {
+		return err
+	}
+	if t != typ {
+		return errors.New(fmt.Sprintf(
+			"invalid entry type (expected %v, got %v) (resource=%v)",
+			typ, t, meta))
+	}
+	return nil
+}
+
+func (dvp *Lucene45DocValuesProducer) readFields(meta store.IndexInput) error {
+	fieldNumber, err := asInt(meta.ReadVInt())
+	for err == nil && fieldNumber != -1 {
+		// check should be: infos.fieldInfo(fieldNumber) != null, which
+		// incorporates negative check but docvalues updates are
+		// currently buggy here (loading extra stuff, etc): LUCENE-5616
+		if fieldNumber < 0 {
+			// trickier to validate more: because we re-use for norms,
+			// because we use multiple entries for "composite" types like
+			// sortedset, etc.
+			return errors.New(fmt.Sprintf("Invalid field number: %v (resource=%v)", fieldNumber, meta))
+		}
+		var typ byte
+		if typ, err = meta.ReadByte(); err != nil {
+			return err
+		}
+		switch typ {
+		case LUCENE45_DV_NUMERIC:
+			var n *numericEntry
+			if n, err = readNumericEntry(meta); err != nil {
+				return err
+			}
+			dvp.numerics[fieldNumber] = n
+		case LUCENE45_DV_BINARY:
+			var b *binaryEntry
+			if b, err = readBinaryEntry(meta); err != nil {
+				return err
+			}
+			dvp.binaries[fieldNumber] = b
+		case LUCENE45_DV_SORTED:
+			if err = dvp.readSortedField(fieldNumber, meta); err != nil {
+				return err
+			}
+		case LUCENE45_DV_SORTED_SET:
+			var ss *sortedSetEntry
+			if ss, err = dvp.readSortedSetEntry(meta); err != nil {
+				return err
+			}
+			dvp.sortedSets[fieldNumber] = ss
+			switch ss.format {
+			case SORTED_SET_WITH_ADDRESSES:
+				err = dvp.readSortedSetFieldWithAddresses(fieldNumber, meta)
+			case SORTED_SET_SINGLE_VALUED_SORTED:
+				if err = expectEntry(meta, fieldNumber, LUCENE45_DV_SORTED); err == nil {
+					err = dvp.readSortedField(fieldNumber, meta)
+				}
+			default:
+				panic("assert fail")
+			}
+			if err != nil {
+				return err
+			}
+		default:
+			return errors.New(fmt.Sprintf("invalid type: %v, resource=%v", typ, meta))
+		}
+		fieldNumber, err = asInt(meta.ReadVInt())
+	}
+	return err
+}
+
+func asInt(n int32, err error) (int, error) {
+	return int(n), err
+}
+
+func readNumericEntry(meta store.IndexInput) (entry *numericEntry, err error) {
+	entry = new(numericEntry)
+	if entry.format, err = asInt(meta.ReadVInt()); err != nil {
+		return nil, err
+	}
+	if entry.missingOffset, err = meta.ReadLong(); err != nil {
+		return nil, err
+	}
+	if entry.packedIntsVersion, err = meta.ReadVInt(); err != nil {
+		return nil, err
+	}
+	if entry.offset, err = meta.ReadLong(); err != nil {
+		return nil, err
+	}
+	if entry.count, err = meta.ReadVLong(); err != nil {
+		return nil, err
+	}
+	if entry.blockSize, err = asInt(meta.ReadVInt()); err != nil {
+		return nil, err
+	}
+	switch entry.format {
+	case GCD_COMPRESSED:
+		if entry.minValue, err = meta.ReadLong(); err != nil {
+			return nil, err
+		}
+		if entry.gcd, err = meta.ReadLong(); err != nil {
+			return nil, err
+		}
+	case TABLE_COMPRESSED:
+		if entry.count > int64(^uint32(0)>>1) {
+			return nil, errors.New(fmt.Sprintf(
+				"Cannot use TABLE_COMPRESSED with more than MAX_VALUE values, input=%v", meta))
+		}
+		var uniqueValues int
+		if uniqueValues, err = asInt(meta.ReadVInt()); err != nil {
+			return nil, err
+		}
+		if uniqueValues > 256 {
+			return nil, errors.New(fmt.Sprintf(
+				"TABLE_COMPRESSED cannot have more than 256 distinct values, input=%v", meta))
+		}
+		entry.table = make([]int64, uniqueValues)
+		for i, _ := range entry.table {
+			if entry.table[i], err = meta.ReadLong(); err != nil {
+				return nil, err
+			}
+		}
+	case DELTA_COMPRESSED:
+	default:
+		return nil, errors.New(fmt.Sprintf("Unknown format: %v, input=%v", entry.format, meta))
+	}
+	return entry, nil
+}
+
+func readBinaryEntry(meta store.IndexInput) (entry *binaryEntry, err error) {
+	entry = new(binaryEntry)
+	if entry.format, err = asInt(meta.ReadVInt()); err != nil {
+		return nil, err
+	}
+	if entry.missingOffset, err = meta.ReadLong(); err != nil {
+		return nil, err
+	}
+	if entry.minLength, err = asInt(meta.ReadVInt()); err != nil {
+		return nil, err
+	}
+	if entry.maxLength, err = asInt(meta.ReadVInt()); err != nil {
+		return nil, err
+	}
+	if entry.count, err = meta.ReadVLong(); err != nil {
+		return nil, err
+	}
+	if entry.offset, err = meta.ReadLong(); err != nil {
+		return nil, err
+	}
+	switch entry.format {
+	case BINARY_FIXED_UNCOMPRESSED:
+	case BINARY_PREFIX_COMPRESSED:
+		if entry.addressInterval, err = asInt(meta.ReadVInt()); err != nil {
+			return nil, err
+		}
+		fallthrough
+	case BINARY_VARIABLE_UNCOMPRESSED:
+		if entry.addressesOffset, err = meta.ReadLong(); err != nil {
+			return nil, err
+		}
+		if entry.packedIntsVersion, err = meta.ReadVInt(); err != nil {
+			return nil, err
+		}
+		if entry.blockSize, err = asInt(meta.ReadVInt()); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("Unknown format: %v, input=%v", entry.format, meta))
+	}
+	return entry, nil
+}
+
+func (dvp *Lucene45DocValuesProducer) readSortedSetEntry(meta store.IndexInput) (entry *sortedSetEntry, err error) {
+	entry = new(sortedSetEntry)
+	if dvp.version >= LUCENE45_DV_VERSION_SORTED_SET_SINGLE_VALUED {
+		if entry.format, err = asInt(meta.ReadVInt()); err != nil {
+			return nil, err
+		}
+	} else {
+		entry.format = SORTED_SET_WITH_ADDRESSES
+	}
+	if entry.format != SORTED_SET_SINGLE_VALUED_SORTED && entry.format != SORTED_SET_WITH_ADDRESSES {
+		return nil, errors.New(fmt.Sprintf("Unknown format: %v, input=%v", entry.format, meta))
+	}
+	return entry, nil
+}
+
+func (dvp *Lucene45DocValuesProducer) Numeric(field *FieldInfo) (v NumericDocValues, err error) {
+	dvp.Lock()
+	defer dvp.Unlock()
+
+	values, ok := dvp.numericInstances[int(field.Number)]
+	if !ok {
+		entry, ok := dvp.numerics[int(field.Number)]
+		assert2(ok, "no numeric docvalues for field: %v", field.Name)
+		if values, err = dvp.loadNumeric(entry); err != nil {
+			return nil, err
+		}
+		dvp.numericInstances[int(field.Number)] = values
+	}
+	return func(docID int) int64 {
+		return values(int64(docID))
+	}, nil
+}
+
+func (dvp *Lucene45DocValuesProducer) loadNumeric(entry *numericEntry) (func(int64) int64, error) {
+	if err := dvp.data.Seek(entry.offset); err != nil {
+		return nil, err
+	}
+
+	switch entry.format {
+	case DELTA_COMPRESSED:
+		reader, err := packed.NewBlockPackedReader(dvp.data,
+			entry.packedIntsVersion, entry.blockSize, entry.count)
+		if err != nil {
+			return nil, err
+		}
+		atomic.AddInt64(&dvp.ramBytesUsed, reader.RamBytesUsed())
+		return reader.Get, nil
+	case GCD_COMPRESSED:
+		min, mult := entry.minValue, entry.gcd
+		quotientReader, err := packed.NewBlockPackedReader(dvp.data,
+			entry.packedIntsVersion, entry.blockSize, entry.count)
+		if err != nil {
+			return nil, err
+		}
+		atomic.AddInt64(&dvp.ramBytesUsed, quotientReader.RamBytesUsed())
+		return func(id int64) int64 {
+			return min + mult*quotientReader.Get(id)
+		}, nil
+	case TABLE_COMPRESSED:
+		table := entry.table
+		bitsRequired := packed.BitsRequired(int64(len(table)) - 1)
+		ords, err := packed.ReaderNoHeader(dvp.data, packed.PackedFormat(packed.PACKED),
+			entry.packedIntsVersion, int32(entry.count), uint32(bitsRequired))
+		if err != nil {
+			return nil, err
+		}
+		atomic.AddInt64(&dvp.ramBytesUsed, util.SizeOf(table)+ords.RamBytesUsed())
+		return func(id int64) int64 {
+			return table[int(ords.Get(int(id)))]
+		}, nil
+	default:
+		panic("assert fail")
+	}
+}
+
+func (dvp *Lucene45DocValuesProducer) Binary(field *FieldInfo) (v BinaryDocValues, err error) {
+	dvp.Lock()
+	defer dvp.Unlock()
+	return dvp.binary(field)
+}
+
+func (dvp *Lucene45DocValuesProducer) binary(field *FieldInfo) (*binaryDocValues, error) {
+	if v, ok := dvp.binaryInstances[int(field.Number)]; ok {
+		return v, nil
+	}
+	entry, ok := dvp.binaries[int(field.Number)]
+	assert2(ok, "no binary docvalues for field: %v", field.Name)
+	v, err := dvp.loadBinary(entry)
+	if err != nil {
+		return nil, err
+	}
+	dvp.binaryInstances[int(field.Number)] = v
+	return v, nil
+}
+
+func (dvp *Lucene45DocValuesProducer) loadBinary(entry *binaryEntry) (v *binaryDocValues, err error) {
+	v = &binaryDocValues{fixedLength: entry.maxLength}
+	var numBytes int64
+	switch entry.format {
+	case BINARY_FIXED_UNCOMPRESSED:
+		numBytes = entry.count * int64(entry.maxLength)
+	case BINARY_VARIABLE_UNCOMPRESSED:
+		if err = dvp.data.Seek(entry.addressesOffset); err != nil {
+			return nil, err
+		}
+		if v.addresses, err = packed.NewMonotonicBlockPackedReader(dvp.data,
+			entry.packedIntsVersion, entry.blockSize, entry.count); err != nil {
+			return nil, err
+		}
+		if entry.count > 0 {
+			numBytes = v.addresses.Get(entry.count - 1)
+		}
+		atomic.AddInt64(&dvp.ramBytesUsed, v.addresses.RamBytesUsed())
+	case BINARY_PREFIX_COMPRESSED:
+		// only every addressInterval-th term is addressed
+		if err = dvp.data.Seek(entry.addressesOffset); err != nil {
+			return nil, err
+		}
+		interval := int64(entry.addressInterval)
+		if v.addresses, err = packed.NewMonotonicBlockPackedReader(dvp.data,
+			entry.packedIntsVersion, entry.blockSize, (entry.count+interval-1)/interval); err != nil {
+			return nil, err
+		}
+		v.addressInterval = interval
+		numBytes = entry.addressesOffset - entry.offset
+		atomic.AddInt64(&dvp.ramBytesUsed, v.addresses.RamBytesUsed())
+	default:
+		panic("assert fail")
+	}
+
+	if err = dvp.data.Seek(entry.offset); err != nil {
+		return nil, err
+	}
+	v.bytes = make([]byte, numBytes)
+	if err = dvp.data.ReadBytes(v.bytes); err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&dvp.ramBytesUsed, util.SizeOf(v.bytes))
+	return v, nil
+}
+
+func (dvp *Lucene45DocValuesProducer) Sorted(field *FieldInfo) (v SortedDocValues, err error) {
+	dvp.Lock()
+	defer dvp.Unlock()
+	return dvp.sorted(field)
+}
+
+func (dvp *Lucene45DocValuesProducer) sorted(field *FieldInfo) (*sortedDocValues, error) {
+	if v, ok := dvp.sortedInstances[int(field.Number)]; ok {
+		return v, nil
+	}
+	entry, ok := dvp.ords[int(field.Number)]
+	assert2(ok, "no sorted docvalues for field: %v", field.Name)
+	binary, err := dvp.binary(field)
+	if err != nil {
+		return nil, err
+	}
+	ordinals, err := dvp.loadNumeric(entry)
+	if err != nil {
+		return nil, err
+	}
+	v := &sortedDocValues{
+		ordinals:   ordinals,
+		binary:     binary,
+		valueCount: int(dvp.binaries[int(field.Number)].count),
+	}
+	dvp.sortedInstances[int(field.Number)] = v
+	return v, nil
+}
+
+/* returns an address instance for sortedset ordinal lists */
+func (dvp *Lucene45DocValuesProducer) ordIndexInstance(field *FieldInfo,
+	entry *numericEntry) (*packed.MonotonicBlockPackedReader, error) {
+
+	if v, ok := dvp.ordIndexInstances[int(field.Number)]; ok {
+		return v, nil
+	}
+	if err := dvp.data.Seek(entry.offset); err != nil {
+		return nil, err
+	}
+	v, err := packed.NewMonotonicBlockPackedReader(dvp.data,
+		entry.packedIntsVersion, entry.blockSize, entry.count)
+	if err != nil {
+		return nil, err
+	}
+	dvp.ordIndexInstances[int(field.Number)] = v
+	atomic.AddInt64(&dvp.ramBytesUsed, v.RamBytesUsed())
+	return v, nil
+}
+
+func (dvp *Lucene45DocValuesProducer) SortedSet(field *FieldInfo) (v SortedSetDocValues, err error) {
+	dvp.Lock()
+	defer dvp.Unlock()
+	return dvp.sortedSet(field)
+}
+
+func (dvp *Lucene45DocValuesProducer) sortedSet(field *FieldInfo) (SortedSetDocValues, error) {
+	ss, ok := dvp.sortedSets[int(field.Number)]
+	assert2(ok, "no sorted set docvalues for field: %v", field.Name)
+	if ss.format == SORTED_SET_SINGLE_VALUED_SORTED {
+		values, err := dvp.sorted(field)
+		if err != nil {
+			return nil, err
+		}
+		return newSingletonSortedSetDocValues(values), nil
+	}
+	assert(ss.format == SORTED_SET_WITH_ADDRESSES)
+
+	valueCount := dvp.binaries[int(field.Number)].count
+	// we keep the []byte for the terms in RAM
+	binary, err := dvp.binary(field)
+	if err != nil {
+		return nil, err
+	}
+	ordinals, ok := dvp.sortedSetInstances[int(field.Number)]
+	if !ok {
+		if ordinals, err = dvp.loadNumeric(dvp.ords[int(field.Number)]); err != nil {
+			return nil, err
+		}
+		dvp.sortedSetInstances[int(field.Number)] = ordinals
+	}
+	ordIndex, err := dvp.ordIndexInstance(field, dvp.ordIndexes[int(field.Number)])
+	if err != nil {
+		return nil, err
+	}
+	return &sortedSetDocValues{
+		ordinals:   ordinals,
+		ordIndex:   ordIndex,
+		binary:     binary,
+		valueCount: valueCount,
+	}, nil
+}
+
+func (dvp *Lucene45DocValuesProducer) missingBits(offset int64) (util.Bits, error) {
+	if offset == -1 {
+		return util.NewMatchAllBits(dvp.maxDoc), nil
+	}
+	if err := dvp.data.Seek(offset); err != nil {
+		return nil, err
+	}
+	bits := make([]byte, (dvp.maxDoc+7)/8)
+	if err := dvp.data.ReadBytes(bits); err != nil {
+		return nil, err
+	}
+	return &missingBits{bits, dvp.maxDoc}, nil
+}
+
+func (dvp *Lucene45DocValuesProducer) DocsWithField(field *FieldInfo) (v util.Bits, err error) {
+	dvp.Lock()
+	defer dvp.Unlock()
+
+	switch field.DocValuesType() {
+	case DOC_VALUES_TYPE_SORTED_SET:
+		ss, err := dvp.sortedSet(field)
+		if err != nil {
+			return nil, err
+		}
+		return &docsWithFieldBits{dvp.maxDoc, func(docID int) bool {
+			ss.SetDocument(docID)
+			return ss.NextOrd() != NO_MORE_ORDS
+		}}, nil
+	case DOC_VALUES_TYPE_SORTED:
+		sorted, err := dvp.sorted(field)
+		if err != nil {
+			return nil, err
+		}
+		return &docsWithFieldBits{dvp.maxDoc, func(docID int) bool {
+			return sorted.Ord(docID) != -1
+		}}, nil
+	case DOC_VALUES_TYPE_BINARY:
+		return dvp.missingBits(dvp.binaries[int(field.Number)].missingOffset)
+	case DOC_VALUES_TYPE_NUMERIC:
+		return dvp.missingBits(dvp.numerics[int(field.Number)].missingOffset)
+	default:
+		panic("assert fail")
+	}
+}
+
+func (dvp *Lucene45DocValuesProducer) RamBytesUsed() int64 {
+	return atomic.LoadInt64(&dvp.ramBytesUsed)
+}
+
+func (dvp *Lucene45DocValuesProducer) Close() error {
+	return dvp.data.Close()
+}
+
+/* In-memory binary values, of fixed or variable length. */
+type binaryDocValues struct {
+	bytes       []byte
+	fixedLength int
+	addresses   *packed.MonotonicBlockPackedReader // nil if fixed length
+	// > 0 if prefix compressed, addresses then point to every n-th term
+	addressInterval int64
+}
+
+func (v *binaryDocValues) Get(docID int) []byte {
+	if v.addressInterval > 0 {
+		return v.prefixCompressed(int64(docID))
+	}
+	if v.addresses == nil {
+		start := docID * v.fixedLength
+		return v.bytes[start : start+v.fixedLength]
+	}
+	var start int64
+	if docID > 0 {
+		start = v.addresses.Get(int64(docID - 1))
+	}
+	return v.bytes[start:v.addresses.Get(int64(docID))]
+}
+
+/*
+Decodes the given term, starting from the closest addressed term
+before it, which is written in full.
+*/
+func (v *binaryDocValues) prefixCompressed(ord int64) []byte {
+	in := store.NewByteArrayDataInput(v.bytes)
+	in.Pos = int(v.addresses.Get(ord / v.addressInterval))
+	var term []byte
+	for i := ord % v.addressInterval; i >= 0; i-- {
+		sharedPrefix, _ := in.ReadVInt()
+		suffix, _ := in.ReadVInt()
+		term = append(term[:sharedPrefix], v.bytes[in.Pos:in.Pos+int(suffix)]...)
+		in.Pos += int(suffix)
+	}
+	return term
+}
+
+type sortedDocValues struct {
+	ordinals   func(int64) int64
+	binary     *binaryDocValues
+	valueCount int
+}
+
+func (v *sortedDocValues) Ord(docID int) int {
+	return int(v.ordinals(int64(docID)))
+}
+
+func (v *sortedDocValues) LookupOrd(ord int) []byte {
+	return v.binary.Get(ord)
+}
+
+func (v *sortedDocValues) ValueCount() int {
+	return v.valueCount
+}
+
+func (v *sortedDocValues) Get(docID int) []byte {
+	if ord := v.Ord(docID); ord != -1 {
+		return v.LookupOrd(ord)
+	}
+	return nil
+}
+
+type sortedSetDocValues struct {
+	ordinals          func(int64) int64
+	ordIndex          *packed.MonotonicBlockPackedReader
+	binary            *binaryDocValues
+	valueCount        int64
+	offset, endOffset int64
+}
+
+func (v *sortedSetDocValues) SetDocument(docID int) {
+	v.offset = 0
+	if docID > 0 {
+		v.offset = v.ordIndex.Get(int64(docID - 1))
+	}
+	v.endOffset = v.ordIndex.Get(int64(docID))
+}
+
+func (v *sortedSetDocValues) NextOrd() int64 {
+	if v.offset == v.endOffset {
+		return NO_MORE_ORDS
+	}
+	ord := v.ordinals(v.offset)
+	v.offset++
+	return ord
+}
+
+func (v *sortedSetDocValues) LookupOrd(ord int64) []byte {
+	return v.binary.Get(int(ord))
+}
+
+func (v *sortedSetDocValues) ValueCount() int64 {
+	return v.valueCount
+}
+
+// index/SingletonSortedSetDocValues.java
+
+/* Exposes a single-valued SortedDocValues as multi-valued SortedSetDocValues. */
+type singletonSortedSetDocValues struct {
+	in         SortedDocValues
+	currentOrd int64
+}
+
+func newSingletonSortedSetDocValues(in SortedDocValues) *singletonSortedSetDocValues {
+	return &singletonSortedSetDocValues{in, NO_MORE_ORDS}
+}
+
+func (v *singletonSortedSetDocValues) SetDocument(docID int) {
+	v.currentOrd = int64(v.in.Ord(docID))
+}
+
+func (v *singletonSortedSetDocValues) NextOrd() int64 {
+	ord := v.currentOrd
+	v.currentOrd = NO_MORE_ORDS
+	return ord
+}
+
+func (v *singletonSortedSetDocValues) LookupOrd(ord int64) []byte {
+	return v.in.LookupOrd(int(ord))
+}
+
+func (v *singletonSortedSetDocValues) ValueCount() int64 {
+	return int64(v.in.ValueCount())
+}
+
+/* docsWithField bitset written by the consumer, one bit per document. */
+type missingBits struct {
+	bits   []byte
+	length int
+}
+
+func (b *missingBits) At(index int) bool {
+	return (b.bits[index>>3] & (1 << uint(index&7))) != 0
+}
+
+func (b *missingBits) Length() int {
+	return b.length
+}
+
+type docsWithFieldBits struct {
+	length int
+	has    func(docID int) bool
+}
+
+func (b *docsWithFieldBits) At(index int) bool {
+	return b.has(index)
+}
+
+func (b *docsWithFieldBits) Length() int {
+	return b.length
+}
@@ -0,0 +1,368 @@
+package lucene45
+
+import (
+	"bytes"
+	. "github.com/gzg1984/golucene/core/codec/spi"
+	. "github.com/gzg1984/golucene/core/index/model"
+	"github.com/gzg1984/golucene/core/store"
+	"github.com/gzg1984/golucene/core/util"
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func iterOf(values []interface{}) func() func() (interface{}, bool) {
+	return func() func() (interface{}, bool) {
+		i := 0
+		return func() (interface{}, bool) {
+			if i >= len(values) {
+				return nil, false
+			}
+			i++
+			return values[i-1], true
+		}
+	}
+}
+
+func TestDocValuesReadWrite(t *testing.T) {
+	const maxDoc = 300
+	dir := store.NewRAMDirectory()
+	defer dir.Close()
+
+	newField := func(name string, number int32, typ DocValuesType) *FieldInfo {
+		return NewFieldInfo(name, true, number, false, false, false,
+			INDEX_OPT_DOCS_ONLY, typ, 0, -1, nil)
+	}
+	fields := []*FieldInfo{
+		newField("gcd", 0, DOC_VALUES_TYPE_NUMERIC),
+		newField("table", 1, DOC_VALUES_TYPE_NUMERIC),
+		newField("delta", 2, DOC_VALUES_TYPE_NUMERIC),
+		newField("var", 3, DOC_VALUES_TYPE_BINARY),
+		newField("fixed", 4, DOC_VALUES_TYPE_BINARY),
+		newField("sorted", 5, DOC_VALUES_TYPE_SORTED),
+		newField("set", 6, DOC_VALUES_TYPE_SORTED_SET),
+		newField("single", 7, DOC_VALUES_TYPE_SORTED_SET),
+	}
+	fis := NewFieldInfos(fields)
+	si := NewSegmentInfo(dir, util.VERSION_LATEST, "_0", maxDoc, false, nil, nil)
+
+	gcd := make([]interface{}, maxDoc)
+	table := make([]interface{}, maxDoc)
+	delta := make([]interface{}, maxDoc)
+	variable := make([]interface{}, maxDoc)
+	fixed := make([]interface{}, maxDoc)
+	sortedOrds := make([]interface{}, maxDoc)
+	setCounts := make([]interface{}, maxDoc)
+	var setOrds []interface{}
+	singleCounts := make([]interface{}, maxDoc)
+	var singleOrds []interface{}
+	for i := 0; i < maxDoc; i++ {
+		gcd[i] = int64(i*1000 - 7000)
+		table[i] = int64(i%3) * 1000000000000
+		if i%7 != 0 {
+			delta[i] = rand.Int63n(1<<40) - 1<<39
+		}
+		if i%5 != 0 {
+			variable[i] = []byte(strconv.Itoa(i))
+		}
+		fixed[i] = []byte{byte(i), byte(i >> 8), 1, 2}
+		sortedOrds[i] = i%4 - 1
+		count := 0
+		for ord := int64(0); ord < 3; ord++ {
+			if i&(1<<uint(ord)) != 0 {
+				setOrds = append(setOrds, ord)
+				count++
+			}
+		}
+		setCounts[i] = count
+		if i%2 == 0 {
+			singleOrds = append(singleOrds, int64(i%3))
+			singleCounts[i] = 1
+		} else {
+			singleCounts[i] = 0
+		}
+	}
+	terms := []interface{}{[]byte("a"), []byte("bb"), []byte("ccc")}
+
+	format := NewLucene45DocValuesFormat()
+	state := NewSegmentWriteState(util.NO_OUTPUT, dir, si, fis, 32, nil, store.IO_CONTEXT_DEFAULT)
+	consumer, err := format.FieldsConsumer(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range []error{
+		consumer.AddNumericField(fields[0], iterOf(gcd)),
+		consumer.AddNumericField(fields[1], iterOf(table)),
+		consumer.AddNumericField(fields[2], iterOf(delta)),
+		consumer.AddBinaryField(fields[3], iterOf(variable)),
+		consumer.AddBinaryField(fields[4], iterOf(fixed)),
+		consumer.AddSortedField(fields[5], iterOf(terms), iterOf(sortedOrds)),
+		consumer.AddSortedSetField(fields[6], iterOf(terms), iterOf(setCounts), iterOf(setOrds)),
+		consumer.AddSortedSetField(fields[7], iterOf(terms), iterOf(singleCounts), iterOf(singleOrds)),
+		consumer.Close(),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	producer, err := format.FieldsProducer(NewSegmentReadState(dir, si, fis, store.IO_CONTEXT_READ, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	for i, values := range [][]interface{}{gcd, table, delta} {
+		ndv, err := producer.Numeric(fields[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		docsWithField, err := producer.DocsWithField(fields[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for doc, v := range values {
+			expected := int64(0)
+			if v != nil {
+				expected = v.(int64)
+			}
+			if got := ndv(doc); got != expected {
+				t.Fatalf("field %v, doc %v: expected %v, got %v", fields[i].Name, doc, expected, got)
+			}
+			if docsWithField.At(doc) != (v != nil) {
+				t.Fatalf("field %v, doc %v: docsWithField should be %v", fields[i].Name, doc, v != nil)
+			}
+		}
+	}
+
+	for i, values := range [][]interface{}{variable, fixed} {
+		bdv, err := producer.Binary(fields[3+i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		docsWithField, err := producer.DocsWithField(fields[3+i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for doc, v := range values {
+			var expected []byte
+			if v != nil {
+				expected = v.([]byte)
+			}
+			if got := bdv.Get(doc); !bytes.Equal(got, expected) {
+				t.Fatalf("field %v, doc %v: expected %v, got %v", fields[3+i].Name, doc, expected, got)
+			}
+			if docsWithField.At(doc) != (v != nil) {
+				t.Fatalf("field %v, doc %v: docsWithField should be %v", fields[3+i].Name, doc, v != nil)
+			}
+		}
+	}
+
+	sdv, err := producer.Sorted(fields[5])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sdv.ValueCount() != len(terms) {
+		t.Errorf("expected %v values, got %v", len(terms), sdv.ValueCount())
+	}
+	for doc, ord := range sortedOrds {
+		if got := sdv.Ord(doc); got != ord.(int) {
+			t.Fatalf("doc %v: expected ord %v, got %v", doc, ord, got)
+		}
+		if ord.(int) >= 0 && !bytes.Equal(sdv.Get(doc), terms[ord.(int)].([]byte)) {
+			t.Fatalf("doc %v: expected %s, got %s", doc, terms[ord.(int)], sdv.Get(doc))
+		}
+	}
+
+	for _, field := range fields[6:] {
+		ssdv, err := producer.SortedSet(field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ssdv.ValueCount() != int64(len(terms)) {
+			t.Errorf("expected %v values, got %v", len(terms), ssdv.ValueCount())
+		}
+		var ords []int64
+		for doc := 0; doc < maxDoc; doc++ {
+			ssdv.SetDocument(doc)
+			for ord := ssdv.NextOrd(); ord != NO_MORE_ORDS; ord = ssdv.NextOrd() {
+				ords = append(ords, ord)
+			}
+		}
+		expected := setOrds
+		if field.Name == "single" {
+			expected = singleOrds
+		}
+		if len(ords) != len(expected) {
+			t.Fatalf("field %v: expected %v ords, got %v", field.Name, len(expected), len(ords))
+		}
+		for i, ord := range ords {
+			if ord != expected[i].(int64) {
+				t.Fatalf("field %v: ord %v should be %v, got %v", field.Name, i, expected[i], ord)
+			}
+		}
+		if !bytes.Equal(ssdv.LookupOrd(2), []byte("ccc")) {
+			t.Errorf("field %v: expected ccc, got %s", field.Name, ssdv.LookupOrd(2))
+		}
+	}
+}
+
+func TestDocValuesReadWriteMultiBlock(t *testing.T) {
+	// more docs than BLOCK_SIZE, so that the packed values and addresses
+	// span multiple blocks, and enough variable-length terms to span
+	// many prefix-compressed address intervals
+	const maxDoc = 2*BLOCK_SIZE + 321
+	const numTerms = 5000
+	dir := store.NewRAMDirectory()
+	defer dir.Close()
+
+	newField := func(name string, number int32, typ DocValuesType) *FieldInfo {
+		return NewFieldInfo(name, true, number, false, false, false,
+			INDEX_OPT_DOCS_ONLY, typ, 0, -1, nil)
+	}
+	fields := []*FieldInfo{
+		newField("gcd", 0, DOC_VALUES_TYPE_NUMERIC),
+		newField("delta", 1, DOC_VALUES_TYPE_NUMERIC),
+		newField("var", 2, DOC_VALUES_TYPE_BINARY),
+		newField("sorted", 3, DOC_VALUES_TYPE_SORTED),
+		newField("set", 4, DOC_VALUES_TYPE_SORTED_SET),
+	}
+	fis := NewFieldInfos(fields)
+	si := NewSegmentInfo(dir, util.VERSION_LATEST, "_0", maxDoc, false, nil, nil)
+
+	// sorted terms with shared prefixes and different lengths
+	terms := make([]interface{}, numTerms)
+	for i := range terms {
+		terms[i] = []byte("term" + strconv.Itoa(i/100) + "/" + strconv.Itoa(i))
+	}
+	sort.Sort(byteSlices(terms))
+
+	gcd := make([]interface{}, maxDoc)
+	delta := make([]interface{}, maxDoc)
+	variable := make([]interface{}, maxDoc)
+	sortedOrds := make([]interface{}, maxDoc)
+	setCounts := make([]interface{}, maxDoc)
+	var setOrds []interface{}
+	for i := 0; i < maxDoc; i++ {
+		gcd[i] = int64(i) * 3
+		delta[i] = rand.Int63n(1<<uint(1+i%50)) - int64(i)
+		if i%11 != 0 {
+			variable[i] = bytes.Repeat([]byte{byte(i)}, i%37)
+		}
+		sortedOrds[i] = (i*7)%(numTerms+1) - 1
+		first := int64(i % numTerms)
+		second := int64((i*31 + 17) % numTerms)
+		switch {
+		case i%3 == 0:
+			setCounts[i] = 0
+		case first == second:
+			setCounts[i] = 1
+			setOrds = append(setOrds, first)
+		case first < second:
+			setCounts[i] = 2
+			setOrds = append(setOrds, first, second)
+		default:
+			setCounts[i] = 2
+			setOrds = append(setOrds, second, first)
+		}
+	}
+
+	format := NewLucene45DocValuesFormat()
+	state := NewSegmentWriteState(util.NO_OUTPUT, dir, si, fis, 32, nil, store.IO_CONTEXT_DEFAULT)
+	consumer, err := format.FieldsConsumer(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range []error{
+		consumer.AddNumericField(fields[0], iterOf(gcd)),
+		consumer.AddNumericField(fields[1], iterOf(delta)),
+		consumer.AddBinaryField(fields[2], iterOf(variable)),
+		consumer.AddSortedField(fields[3], iterOf(terms), iterOf(sortedOrds)),
+		consumer.AddSortedSetField(fields[4], iterOf(terms), iterOf(setCounts), iterOf(setOrds)),
+		consumer.Close(),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	producer, err := format.FieldsProducer(NewSegmentReadState(dir, si, fis, store.IO_CONTEXT_READ, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer producer.Close()
+
+	for i, values := range [][]interface{}{gcd, delta} {
+		ndv, err := producer.Numeric(fields[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for doc, v := range values {
+			if got := ndv(doc); got != v.(int64) {
+				t.Fatalf("field %v, doc %v: expected %v, got %v", fields[i].Name, doc, v, got)
+			}
+		}
+	}
+
+	bdv, err := producer.Binary(fields[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for doc, v := range variable {
+		var expected []byte
+		if v != nil {
+			expected = v.([]byte)
+		}
+		if got := bdv.Get(doc); !bytes.Equal(got, expected) {
+			t.Fatalf("doc %v: expected %v, got %v", doc, expected, got)
+		}
+	}
+
+	sdv, err := producer.Sorted(fields[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sdv.ValueCount() != numTerms {
+		t.Errorf("expected %v values, got %v", numTerms, sdv.ValueCount())
+	}
+	for ord, term := range terms {
+		if got := sdv.LookupOrd(ord); !bytes.Equal(got, term.([]byte)) {
+			t.Fatalf("ord %v: expected %s, got %s", ord, term, got)
+		}
+	}
+	for doc, ord := range sortedOrds {
+		if got := sdv.Ord(doc); got != ord.(int) {
+			t.Fatalf("doc %v: expected ord %v, got %v", doc, ord, got)
+		}
+	}
+
+	ssdv, err := producer.SortedSet(fields[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ords []int64
+	for doc := 0; doc < maxDoc; doc++ {
+		ssdv.SetDocument(doc)
+		for ord := ssdv.NextOrd(); ord != NO_MORE_ORDS; ord = ssdv.NextOrd() {
+			ords = append(ords, ord)
+		}
+	}
+	if len(ords) != len(setOrds) {
+		t.Fatalf("expected %v ords, got %v", len(setOrds), len(ords))
+	}
+	for i, ord := range ords {
+		if ord != setOrds[i].(int64) {
+			t.Fatalf("ord %v should be %v, got %v", i, setOrds[i], ord)
+		}
+	}
+	if got := ssdv.LookupOrd(numTerms - 1); !bytes.Equal(got, terms[numTerms-1].([]byte)) {
+		t.Errorf("expected %s, got %s", terms[numTerms-1], got)
+	}
+}
+
+type byteSlices []interface{}
+
+func (s byteSlices) Len() int           { return len(s) }
+func (s byteSlices) Less(i, j int) bool { return bytes.Compare(s[i].([]byte), s[j].([]byte)) < 0 }
+func (s byteSlices) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -14,7 +14,12 @@ func (f *Lucene49NormsFormat) NormsConsumer(state *SegmentWriteState) (w DocValu
 }
 
 func (f *Lucene49NormsFormat) NormsProducer(state SegmentReadState) (r DocValuesProducer, err error) {
-	return newLucene49NormsProducer(state, DATA_CODEC, DATA_EXTENSION, METADATA_CODEC, METADATA_EXTENSION)
+	np, err := newLucene49NormsProducer(state, DATA_CODEC, DATA_EXTENSION, METADATA_CODEC, METADATA_EXTENSION)
+	if err != nil {
+		// don't wrap the nil producer, which would be closed on failure
+		return nil, err
+	}
+	return np, nil
 }
 
 const (
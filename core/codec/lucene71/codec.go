@@ -4,6 +4,7 @@ import (
 	"github.com/gzg1984/golucene/core/codec/lucene40"
 	"github.com/gzg1984/golucene/core/codec/lucene41"
 	"github.com/gzg1984/golucene/core/codec/lucene42"
+	"github.com/gzg1984/golucene/core/codec/lucene45"
 	"github.com/gzg1984/golucene/core/codec/lucene46"
 	"github.com/gzg1984/golucene/core/codec/lucene49"
 	"github.com/gzg1984/golucene/core/codec/perfield"
@@ -26,6 +27,7 @@ type Lucene71Codec struct {
 }
 
 func newLucene71Codec() *Lucene71Codec {
+	defaultDVFormat := lucene45.NewLucene45DocValuesFormat()
 	return &Lucene71Codec{NewCodec("Lucene71",
 		lucene41.NewLucene41StoredFieldsFormat(),
 		lucene42.NewLucene42TermVectorsFormat(),
@@ -36,7 +38,7 @@ func newLucene71Codec() *Lucene71Codec {
 			return LoadPostingsFormat("Lucene41")
 		}),
 		perfield.NewPerFieldDocValuesFormat(func(field string) DocValuesFormat {
-			panic("not implemented yet")
+			return defaultDVFormat
 		}),
 		new(lucene49.Lucene49NormsFormat),
 	)}
@@ -2,6 +2,7 @@ package spi
 
 import (
 	. "github.com/gzg1984/golucene/core/index/model"
+	"github.com/gzg1984/golucene/core/util"
 	"io"
 )
 
@@ -45,17 +46,14 @@ func RegisterDocValuesFormat(formats ...DocValuesFormat) {
 	}
 }
 
+func LoadDocValuesFormat(name string) DocValuesFormat {
+	v, ok := allDocValuesFormats[name]
+	assert2(ok, "Service '%v' not found.", name)
+	return v
+}
+
 func LoadDocValuesProducer(name string, state SegmentReadState) (fp DocValuesProducer, err error) {
-	panic("not implemented yet")
-	// switch name {
-	// case "Lucene42":
-	// 	return newLucene42DocValuesProducer(state, LUCENE42_DV_DATA_CODEC, LUCENE42_DV_DATA_EXTENSION,
-	// 		LUCENE42_DV_METADATA_CODEC, LUCENE42_DV_METADATA_EXTENSION)
-	// case "Lucene45":
-	// 	return newLucene45DocValuesProducer(state, LUCENE45_DV_DATA_CODEC, LUCENE45_DV_DATA_EXTENSION,
-	// 		LUCENE45_DV_META_CODEC, LUCENE45_DV_META_EXTENSION)
-	// }
-	// panic(fmt.Sprintf("Service '%v' not found.", name))
+	return LoadDocValuesFormat(name).FieldsProducer(state)
 }
 
 // codecs/DocValuesConsumer.java
@@ -76,8 +74,23 @@ the values multiple times.
 */
 type DocValuesConsumer interface {
 	io.Closer
-	// Writes numeric docvalues for a field.
+	// Writes numeric docvalues for a field. Each value is an int64, or
+	// nil if the document has no value.
 	AddNumericField(*FieldInfo, func() func() (interface{}, bool)) error
+	// Writes binary docvalues for a field. Each value is a []byte, or
+	// nil if the document has no value.
+	AddBinaryField(*FieldInfo, func() func() (interface{}, bool)) error
+	// Writes pre-sorted binary docvalues for a field. values iterates
+	// the deduplicated, sorted terms; docToOrd the ord (int) of each
+	// document, -1 if it has no value.
+	AddSortedField(field *FieldInfo,
+		values, docToOrd func() func() (interface{}, bool)) error
+	// Writes pre-sorted set docvalues for a field. values iterates the
+	// deduplicated, sorted terms; docToOrdCount the number (int) of ords
+	// of each document; ords the ords (int64) of all documents, in
+	// order.
+	AddSortedSetField(field *FieldInfo,
+		values, docToOrdCount, ords func() func() (interface{}, bool)) error
 }
 
 // codecs/DocvaluesProducer.java
@@ -89,6 +102,9 @@ type DocValuesProducer interface {
 	Binary(field *FieldInfo) (v BinaryDocValues, err error)
 	Sorted(field *FieldInfo) (v SortedDocValues, err error)
 	SortedSet(field *FieldInfo) (v SortedSetDocValues, err error)
+	// Returns a Bits at the size of the segment's maxDoc, with turned
+	// on bits for each docid that has a value for this field.
+	DocsWithField(field *FieldInfo) (v util.Bits, err error)
 }
 
 // type NumericDocValues interface {
@@ -111,6 +127,9 @@ type SortedDocValues interface {
 	ValueCount() int
 }
 
+/* When returned by NextOrd() it means there are no more ordinals for the document. */
+const NO_MORE_ORDS = -1
+
 type SortedSetDocValues interface {
 	NextOrd() int64
 	SetDocument(docID int)
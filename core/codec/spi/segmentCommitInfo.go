@@ -87,7 +87,8 @@ func (si *SegmentCommitInfo) SetFieldInfosFiles(fieldInfosFiles map[string]bool)
 
 /* Called when we succeed in writing deletes */
 func (info *SegmentCommitInfo) AdvanceDelGen() {
-	info.delGen, info.nextWriteDelGen = info.nextWriteDelGen, info.delGen+1
+	info.delGen = info.nextWriteDelGen
+	info.nextWriteDelGen = info.delGen + 1
 	info.sizeInBytes = -1
 }
 
@@ -99,6 +100,38 @@ func (info *SegmentCommitInfo) AdvanceNextWriteDelGen() {
 	info.nextWriteDelGen++
 }
 
+/* Called when we succeed in writing a new FieldInfos generation. */
+func (info *SegmentCommitInfo) AdvanceFieldInfosGen() {
+	info.fieldInfosGen = info.nextWriteFieldInfosGen
+	info.nextWriteFieldInfosGen = info.fieldInfosGen + 1
+	info.sizeInBytes = -1
+}
+
+/*
+Called if there was an error while writing a new generation of
+FieldInfos, so that we don't try to write to the same file more than
+once.
+*/
+func (info *SegmentCommitInfo) AdvanceNextWriteFieldInfosGen() {
+	info.nextWriteFieldInfosGen++
+}
+
+/* Called when we succeed in writing a new DocValues generation. */
+func (info *SegmentCommitInfo) AdvanceDocValuesGen() {
+	info.docValuesGen = info.nextWriteDocValuesGen
+	info.nextWriteDocValuesGen = info.docValuesGen + 1
+	info.sizeInBytes = -1
+}
+
+/*
+Called if there was an error while writing a new generation of
+DocValues, so that we don't try to write to the same file more than
+once.
+*/
+func (info *SegmentCommitInfo) AdvanceNextWriteDocValuesGen() {
+	info.nextWriteDocValuesGen++
+}
+
 /*
 Returns total size in bytes of all files for this segment.
 
@@ -193,6 +226,16 @@ func (si *SegmentCommitInfo) DelGen() int64 {
 	return si.delGen
 }
 
+/* Returns the next available generation number of the FieldInfos files. */
+func (si *SegmentCommitInfo) NextFieldInfosGen() int64 {
+	return si.nextWriteFieldInfosGen
+}
+
+/* Returns the next available generation number of the DocValues files. */
+func (si *SegmentCommitInfo) NextDocValuesGen() int64 {
+	return si.nextWriteDocValuesGen
+}
+
 /* Returns the number of deleted docs in the segment. */
 func (si *SegmentCommitInfo) DelCount() int {
 	return si.delCount
@@ -220,12 +263,7 @@ func (si *SegmentCommitInfo) StringOf(dir store.Directory, pendingDelCount int)
 }
 
 func (si *SegmentCommitInfo) String() string {
-	panic("not implemented yet")
-	s := si.Info.StringOf(si.Info.Dir, si.delCount)
-	if si.delGen != -1 {
-		s = fmt.Sprintf("%v:delGen=%v", s, si.delGen)
-	}
-	return s
+	return si.StringOf(si.Info.Dir, 0)
 }
 
 func (si *SegmentCommitInfo) Clone() *SegmentCommitInfo {
@@ -0,0 +1,125 @@
+package document
+
+import (
+	"github.com/gzg1984/golucene/core/index/model"
+)
+
+// Builds the frozen FieldType shared by the doc values fields: not
+// indexed, not stored, only the per-document value is recorded.
+func newDocValuesFieldType(dvType model.DocValuesType) *FieldType {
+	ft := newFieldType()
+	ft._docValueType = dvType
+	ft.frozen = true
+	return ft
+}
+
+// Create field with a per-document value; used by the doc values
+// field types.
+func newDocValuesField(name string, value interface{}, ft *FieldType) *Field {
+	assert2(name != "", "name cannot be empty")
+	return &Field{_type: ft, _name: name, _data: value, _boost: 1}
+}
+
+// document/NumericDocValuesField.java
+
+// Type for numeric DocValues.
+var NUMERIC_DOC_VALUES_FIELD_TYPE = newDocValuesFieldType(model.DOC_VALUES_TYPE_NUMERIC)
+
+/*
+Field that stores a per-document int64 value for scoring, sorting or
+value retrieval. Here's an example usage:
+
+	document.Add(NewNumericDocValuesField(name, 22))
+
+If you also need to store the value, you should add a separate
+StoredField instance.
+*/
+type NumericDocValuesField struct {
+	*Field
+}
+
+// Creates a new DocValues field with the specified 64-bit int64 value
+func NewNumericDocValuesField(name string, value int64) *NumericDocValuesField {
+	return &NumericDocValuesField{newDocValuesField(name, value, NUMERIC_DOC_VALUES_FIELD_TYPE)}
+}
+
+// document/BinaryDocValuesField.java
+
+// Type for straight bytes DocValues.
+var BINARY_DOC_VALUES_FIELD_TYPE = newDocValuesFieldType(model.DOC_VALUES_TYPE_BINARY)
+
+/*
+Field that stores a per-document []byte value. The values are stored
+directly with no sharing, which is a good fit when the fields don't
+share (many) values, such as a title field. If values may be shared
+and sorted it's better to use SortedDocValuesField. Here's an example
+usage:
+
+	document.Add(NewBinaryDocValuesField(name, []byte("hello")))
+
+If you also need to store the value, you should add a separate
+StoredField instance.
+*/
+type BinaryDocValuesField struct {
+	*Field
+}
+
+/*
+Create a new binary DocValues field.
+
+NOTE: the provided []byte is not copied so be sure not to change it
+until you're done with this field.
+*/
+func NewBinaryDocValuesField(name string, value []byte) *BinaryDocValuesField {
+	assert2(value != nil, "value cannot be nil")
+	return &BinaryDocValuesField{newDocValuesField(name, value, BINARY_DOC_VALUES_FIELD_TYPE)}
+}
+
+// document/SortedDocValuesField.java
+
+// Type for sorted bytes DocValues
+var SORTED_DOC_VALUES_FIELD_TYPE = newDocValuesFieldType(model.DOC_VALUES_TYPE_SORTED)
+
+/*
+Field that stores a per-document []byte value, indexed for sorting.
+Here's an example usage:
+
+	document.Add(NewSortedDocValuesField(name, []byte("hello")))
+
+If you also need to store the value, you should add a separate
+StoredField instance.
+*/
+type SortedDocValuesField struct {
+	*Field
+}
+
+// Create a new sorted DocValues field.
+func NewSortedDocValuesField(name string, bytes []byte) *SortedDocValuesField {
+	assert2(bytes != nil, "value cannot be nil")
+	return &SortedDocValuesField{newDocValuesField(name, bytes, SORTED_DOC_VALUES_FIELD_TYPE)}
+}
+
+// document/SortedSetDocValuesField.java
+
+// Type for sorted bytes DocValues
+var SORTED_SET_DOC_VALUES_FIELD_TYPE = newDocValuesFieldType(model.DOC_VALUES_TYPE_SORTED_SET)
+
+/*
+Field that stores a set of per-document []byte values, indexed for
+faceting, grouping or joining. Here's an example usage:
+
+	document.Add(NewSortedSetDocValuesField(name, []byte("hello")))
+	document.Add(NewSortedSetDocValuesField(name, []byte("world")))
+
+If you also need to store the value, you should add a separate
+StoredField instance.
+*/
+type SortedSetDocValuesField struct {
+	*Field
+}
+
+// Create a new sorted DocValues field.
+func NewSortedSetDocValuesField(name string, bytes []byte) *SortedSetDocValuesField {
+	assert2(bytes != nil, "value cannot be nil")
+	return &SortedSetDocValuesField{newDocValuesField(name, bytes, SORTED_SET_DOC_VALUES_FIELD_TYPE)}
+}
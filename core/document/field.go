@@ -53,6 +53,14 @@ func (f *Field) StringValue() string {
 		return f._data.(string)
 	case int:
 		return strconv.Itoa(f._data.(int))
+	case int32:
+		return strconv.FormatInt(int64(f._data.(int32)), 10)
+	case int64:
+		return strconv.FormatInt(f._data.(int64), 10)
+	case float32:
+		return strconv.FormatFloat(float64(f._data.(float32)), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(f._data.(float64), 'g', -1, 64)
 	default:
 		log.Println("Unknown type", f._data)
 		panic("not implemented yet")
@@ -115,8 +123,26 @@ func (f *Field) TokenStream(analyzer analysis.Analyzer, reuse analysis.TokenStre
 		return nil, nil
 	}
 
-	if nt := f.FieldType().(*FieldType).NumericType(); nt != NumericType(0) {
-		panic("not implemented yet")
+	if nt := f._type.NumericType(); nt != NumericType(0) {
+		nts, ok := reuse.(*analysis.NumericTokenStream)
+		if !ok || nts.PrecisionStep() != f._type.NumericPrecisionStep() {
+			// lazy init the TokenStream as it is heavy to instantiate
+			// (attributes,...), if not needed (stored field loading)
+			nts = analysis.NewNumericTokenStreamWithStep(f._type.NumericPrecisionStep())
+		}
+		switch nt {
+		case FIELD_TYPE_NUMERIC_INT:
+			nts.SetIntValue(f._data.(int32))
+		case FIELD_TYPE_NUMERIC_LONG:
+			nts.SetLongValue(f._data.(int64))
+		case FIELD_TYPE_NUMERIC_FLOAT:
+			nts.SetFloatValue(f._data.(float32))
+		case FIELD_TYPE_NUMERIC_DOUBLE:
+			nts.SetDoubleValue(f._data.(float64))
+		default:
+			panic("Should never get here")
+		}
+		return nts, nil
 	}
 
 	if !f.FieldType().Tokenized() {
@@ -174,6 +200,33 @@ func (ts *StringTokenStream) IncrementToken() (bool, error) {
 	return true, nil
 }
 
+func (ts *StringTokenStream) End() error {
+	if err := ts.TokenStreamImpl.End(); err != nil {
+		return err
+	}
+	finalOffset := len(ts.value)
+	ts.offsetAttribute.SetOffset(finalOffset, finalOffset)
+	return nil
+}
+
+func (ts *StringTokenStream) Reset() error {
+	ts.used = false
+	return nil
+}
+
+func (ts *StringTokenStream) Close() error {
+	ts.value = ""
+	return nil
+}
+
+// Create field with a numeric value; used by the numeric field types.
+func newNumericField(name string, value interface{}, ft *FieldType) *Field {
+	assert2(name != "", "name cannot be empty")
+	assert2(ft.stored || ft.indexed,
+		"it doesn't make sense to have a field that is neither indexed nor stored")
+	return &Field{_type: ft, _name: name, _data: value, _boost: 1}
+}
+
 /* Specifies whether and how a field should be stored. */
 type Store int
 
@@ -297,3 +350,23 @@ not to change it until you're done with this field.
 // func newStoredField(name string, value []byte) *StoredField {
 // 	return &StoredField{newStringField(name, value, STORED_FIELD_TYPE)}
 // }
+
+// Create a stored-only field with the given int32 value.
+func NewStoredFieldInt(name string, value int32) *StoredField {
+	return &StoredField{newNumericField(name, value, STORED_FIELD_TYPE)}
+}
+
+// Create a stored-only field with the given int64 value.
+func NewStoredFieldLong(name string, value int64) *StoredField {
+	return &StoredField{newNumericField(name, value, STORED_FIELD_TYPE)}
+}
+
+// Create a stored-only field with the given float32 value.
+func NewStoredFieldFloat(name string, value float32) *StoredField {
+	return &StoredField{newNumericField(name, value, STORED_FIELD_TYPE)}
+}
+
+// Create a stored-only field with the given float64 value.
+func NewStoredFieldDouble(name string, value float64) *StoredField {
+	return &StoredField{newNumericField(name, value, STORED_FIELD_TYPE)}
+}
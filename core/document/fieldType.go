@@ -13,10 +13,10 @@ import (
 type NumericType int
 
 const (
-	FIELD_TYPE_NUMERIC_INT    = 1 // 32-bit integer numeric type
-	FIELD_TYPE_NUMERIC_LONG   = 2 // 64-bit long numeric type
-	FIELD_TYPE_NUMERIC_FLOAT  = 3 // 32-bit float numeric type
-	FIELD_TYPE_NUMERIC_DOUBLE = 4 // 64-bit double numeric type
+	FIELD_TYPE_NUMERIC_INT    = NumericType(1) // 32-bit integer numeric type
+	FIELD_TYPE_NUMERIC_LONG   = NumericType(2) // 64-bit long numeric type
+	FIELD_TYPE_NUMERIC_FLOAT  = NumericType(3) // 32-bit float numeric type
+	FIELD_TYPE_NUMERIC_DOUBLE = NumericType(4) // 64-bit double numeric type
 )
 
 // Describes the properties of a field.
@@ -50,6 +50,7 @@ func NewFieldTypeFrom(ref *FieldType) *FieldType {
 	ft._indexOptions = ref._indexOptions
 	ft._docValueType = ref._docValueType
 	ft.numericType = ref.numericType
+	ft.numericPrecisionStep = ref.numericPrecisionStep
 	// Do not copy frozen!
 	return ft
 }
@@ -96,6 +97,38 @@ func (ft *FieldType) IndexOptions() model.IndexOptions  { return ft._indexOption
 func (ft *FieldType) NumericType() NumericType          { return ft.numericType }
 func (ft *FieldType) DocValueType() model.DocValuesType { return ft._docValueType }
 
+/*
+Specifies the field's numeric type, or 0 if the field has no numeric
+type. Numeric fields are indexed as trie terms by a
+NumericTokenStream, see NumericPrecisionStep().
+*/
+func (ft *FieldType) SetNumericType(v NumericType) {
+	ft.checkIfFrozen()
+	ft.numericType = v
+}
+
+/*
+Sets the field's DocValuesType, or 0 if no DocValues should be stored.
+The default is 0 (no DocValues).
+*/
+func (ft *FieldType) SetDocValueType(v model.DocValuesType) {
+	ft.checkIfFrozen()
+	ft._docValueType = v
+}
+
+/*
+Precision step for numeric field. This has no effect if NumericType()
+returns 0. The default is NUMERIC_PRECISION_STEP_DEFAULT.
+*/
+func (ft *FieldType) NumericPrecisionStep() int { return ft.numericPrecisionStep }
+
+// Sets the numeric precision step, which must be >= 1.
+func (ft *FieldType) SetNumericPrecisionStep(v int) {
+	ft.checkIfFrozen()
+	assert2(v >= 1, fmt.Sprintf("precisionStep must be >= 1 (got %v)", v))
+	ft.numericPrecisionStep = v
+}
+
 // Prints a Field for human consumption.
 func (ft *FieldType) String() string {
 	var buf bytes.Buffer
@@ -0,0 +1,189 @@
+package document
+
+import (
+	"github.com/gzg1984/golucene/core/index/model"
+	"github.com/gzg1984/golucene/core/util"
+)
+
+// Builds the frozen FieldType shared by the numeric fields: indexed,
+// tokenized (into trie terms), omits norms, indexes DOCS_ONLY.
+func newNumericFieldType(nt NumericType, precisionStep int, stored bool) *FieldType {
+	ft := newFieldType()
+	ft.indexed = true
+	ft._tokenized = true
+	ft._omitNorms = true
+	ft._indexOptions = model.INDEX_OPT_DOCS_ONLY
+	ft.numericType = nt
+	ft.numericPrecisionStep = precisionStep
+	ft.stored = stored
+	ft.frozen = true
+	return ft
+}
+
+func numericFieldType(stored Store, notStored, storedType *FieldType) *FieldType {
+	if stored == STORE_YES {
+		return storedType
+	}
+	return notStored
+}
+
+// document/IntField.java
+
+/*
+Type for indexed and stored int32 fields, using
+NUMERIC_PRECISION_STEP_DEFAULT_32.
+*/
+var INT_FIELD_TYPE_NOT_STORED = newNumericFieldType(FIELD_TYPE_NUMERIC_INT,
+	util.NUMERIC_PRECISION_STEP_DEFAULT_32, false)
+
+var INT_FIELD_TYPE_STORED = newNumericFieldType(FIELD_TYPE_NUMERIC_INT,
+	util.NUMERIC_PRECISION_STEP_DEFAULT_32, true)
+
+/*
+Field that indexes int32 values for efficient range filtering and
+sorting. Example usage:
+
+	document.Add(NewIntField(name, 6, STORE_NO))
+
+To search, use NumericRangeQuery or NumericRangeFilter. The value is
+indexed by a NumericTokenStream as several trie terms, one for each
+precisionStep bits; a smaller precisionStep gives faster range
+queries for the price of more terms in the index. Use a custom
+FieldType with SetNumericPrecisionStep() to tune it, and use the same
+precisionStep for querying.
+
+If you only need to sort by numeric value, and never run range
+querying/filtering, you can index using a precisionStep of
+math.MaxInt32. This will minimize disk space consumed.
+*/
+type IntField struct {
+	*Field
+}
+
+/*
+Creates a stored or un-stored IntField with the provided value and
+default precisionStep NUMERIC_PRECISION_STEP_DEFAULT_32 (8).
+*/
+func NewIntField(name string, value int32, stored Store) *IntField {
+	return NewIntFieldWithType(name, value,
+		numericFieldType(stored, INT_FIELD_TYPE_NOT_STORED, INT_FIELD_TYPE_STORED))
+}
+
+// Expert: allows you to customize the FieldType.
+func NewIntFieldWithType(name string, value int32, ft *FieldType) *IntField {
+	assert2(ft.NumericType() == FIELD_TYPE_NUMERIC_INT,
+		"type.numericType() must be INT but got "+ft.String())
+	return &IntField{newNumericField(name, value, ft)}
+}
+
+// document/LongField.java
+
+/*
+Type for indexed and stored int64 fields, using
+NUMERIC_PRECISION_STEP_DEFAULT.
+*/
+var LONG_FIELD_TYPE_NOT_STORED = newNumericFieldType(FIELD_TYPE_NUMERIC_LONG,
+	util.NUMERIC_PRECISION_STEP_DEFAULT, false)
+
+var LONG_FIELD_TYPE_STORED = newNumericFieldType(FIELD_TYPE_NUMERIC_LONG,
+	util.NUMERIC_PRECISION_STEP_DEFAULT, true)
+
+/*
+Field that indexes int64 values for efficient range filtering and
+sorting, e.g. timestamps as milliseconds. See IntField for details on
+the precisionStep.
+*/
+type LongField struct {
+	*Field
+}
+
+/*
+Creates a stored or un-stored LongField with the provided value and
+default precisionStep NUMERIC_PRECISION_STEP_DEFAULT (16).
+*/
+func NewLongField(name string, value int64, stored Store) *LongField {
+	return NewLongFieldWithType(name, value,
+		numericFieldType(stored, LONG_FIELD_TYPE_NOT_STORED, LONG_FIELD_TYPE_STORED))
+}
+
+// Expert: allows you to customize the FieldType.
+func NewLongFieldWithType(name string, value int64, ft *FieldType) *LongField {
+	assert2(ft.NumericType() == FIELD_TYPE_NUMERIC_LONG,
+		"type.numericType() must be LONG but got "+ft.String())
+	return &LongField{newNumericField(name, value, ft)}
+}
+
+// document/FloatField.java
+
+/*
+Type for indexed and stored float32 fields, using
+NUMERIC_PRECISION_STEP_DEFAULT_32.
+*/
+var FLOAT_FIELD_TYPE_NOT_STORED = newNumericFieldType(FIELD_TYPE_NUMERIC_FLOAT,
+	util.NUMERIC_PRECISION_STEP_DEFAULT_32, false)
+
+var FLOAT_FIELD_TYPE_STORED = newNumericFieldType(FIELD_TYPE_NUMERIC_FLOAT,
+	util.NUMERIC_PRECISION_STEP_DEFAULT_32, true)
+
+/*
+Field that indexes float32 values for efficient range filtering and
+sorting. The values are indexed as sortable ints (see
+FloatToSortableInt()). See IntField for details on the precisionStep.
+*/
+type FloatField struct {
+	*Field
+}
+
+/*
+Creates a stored or un-stored FloatField with the provided value and
+default precisionStep NUMERIC_PRECISION_STEP_DEFAULT_32 (8).
+*/
+func NewFloatField(name string, value float32, stored Store) *FloatField {
+	return NewFloatFieldWithType(name, value,
+		numericFieldType(stored, FLOAT_FIELD_TYPE_NOT_STORED, FLOAT_FIELD_TYPE_STORED))
+}
+
+// Expert: allows you to customize the FieldType.
+func NewFloatFieldWithType(name string, value float32, ft *FieldType) *FloatField {
+	assert2(ft.NumericType() == FIELD_TYPE_NUMERIC_FLOAT,
+		"type.numericType() must be FLOAT but got "+ft.String())
+	return &FloatField{newNumericField(name, value, ft)}
+}
+
+// document/DoubleField.java
+
+/*
+Type for indexed and stored float64 fields, using
+NUMERIC_PRECISION_STEP_DEFAULT.
+*/
+var DOUBLE_FIELD_TYPE_NOT_STORED = newNumericFieldType(FIELD_TYPE_NUMERIC_DOUBLE,
+	util.NUMERIC_PRECISION_STEP_DEFAULT, false)
+
+var DOUBLE_FIELD_TYPE_STORED = newNumericFieldType(FIELD_TYPE_NUMERIC_DOUBLE,
+	util.NUMERIC_PRECISION_STEP_DEFAULT, true)
+
+/*
+Field that indexes float64 values for efficient range filtering and
+sorting, e.g. prices. The values are indexed as sortable longs (see
+DoubleToSortableLong()). See IntField for details on the
+precisionStep.
+*/
+type DoubleField struct {
+	*Field
+}
+
+/*
+Creates a stored or un-stored DoubleField with the provided value and
+default precisionStep NUMERIC_PRECISION_STEP_DEFAULT (16).
+*/
+func NewDoubleField(name string, value float64, stored Store) *DoubleField {
+	return NewDoubleFieldWithType(name, value,
+		numericFieldType(stored, DOUBLE_FIELD_TYPE_NOT_STORED, DOUBLE_FIELD_TYPE_STORED))
+}
+
+// Expert: allows you to customize the FieldType.
+func NewDoubleFieldWithType(name string, value float64, ft *FieldType) *DoubleField {
+	assert2(ft.NumericType() == FIELD_TYPE_NUMERIC_DOUBLE,
+		"type.numericType() must be DOUBLE but got "+ft.String())
+	return &DoubleField{newNumericField(name, value, ft)}
+}
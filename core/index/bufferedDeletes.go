@@ -1,7 +1,7 @@
 package index
 
 import (
-	// "bytes"
+	"bytes"
 	"fmt"
 	"github.com/gzg1984/golucene/core/util"
 	"math"
@@ -10,12 +10,32 @@ import (
 
 // index/BufferedUpdates.java
 
+/*
+Go map (amd64) consumes about 40 bytes for an extra entry, plus the
+term key's two string headers.
+*/
+const BYTES_PER_DEL_TERM = 40 + 4*util.NUM_BYTES_OBJECT_REF + util.NUM_BYTES_INT
+
 /* Go slice consumes two int for an extra doc ID, assuming 50% pre-allocation. */
 const BYTES_PER_DEL_DOCID = 2 * util.NUM_BYTES_INT
 
 /* Go map (amd64) consumes about 40 bytes for an extra entry. */
 const BYTES_PER_DEL_QUERY = 40 + util.NUM_BYTES_OBJECT_REF + util.NUM_BYTES_INT
 
+/*
+An entry of field -> updates costs a map entry, plus the term index
+map and the ordered slice of the field's updates.
+*/
+const BYTES_PER_NUMERIC_FIELD_ENTRY = 40 + 3*util.NUM_BYTES_OBJECT_REF +
+	util.NUM_BYTES_OBJECT_HEADER + util.NUM_BYTES_ARRAY_HEADER
+
+/* An update costs an entry in the term index map plus a slot in the ordered slice. */
+const BYTES_PER_NUMERIC_UPDATE_ENTRY = 40 + 2*util.NUM_BYTES_OBJECT_REF + util.NUM_BYTES_INT
+
+const BYTES_PER_BINARY_FIELD_ENTRY = BYTES_PER_NUMERIC_FIELD_ENTRY
+
+const BYTES_PER_BINARY_UPDATE_ENTRY = BYTES_PER_NUMERIC_UPDATE_ENTRY
+
 const MAX_INT = int(math.MaxInt32)
 
 const VERBOSE = false
@@ -33,13 +53,24 @@ DocumentsWriterDeleteQueue
 type BufferedUpdates struct {
 	numTermDeletes int32 // atomic
 
-	terms   map[*Term]int
+	terms   map[termKey]int
 	queries map[interface{}]int
 	docIDs  []int
 
-	numericUpdates map[string]map[*Term]*DocValuesUpdate
+	numNumericUpdates int32 // atomic
+	numBinaryUpdates  int32 // atomic
 
-	binaryUpdates map[string]map[*Term]*DocValuesUpdate
+	// Map<dvField,Map<updateTerm,NumericUpdate>>
+	// For each field we keep an ordered list of NumericUpdates, key'd by the
+	// update Term. The ordering lets us apply the updates in the order
+	// they were buffered, so that the last update of a document wins.
+	numericUpdates map[string]*docValuesUpdates
+
+	// Map<dvField,Map<updateTerm,BinaryUpdate>>
+	// For each field we keep an ordered list of BinaryUpdates, key'd by the
+	// update Term. The ordering lets us apply the updates in the order
+	// they were buffered, so that the last update of a document wins.
+	binaryUpdates map[string]*docValuesUpdates
 
 	bytesUsed int64 // atomic
 
@@ -48,37 +79,52 @@ type BufferedUpdates struct {
 
 func newBufferedUpdates() *BufferedUpdates {
 	return &BufferedUpdates{
-		terms:          make(map[*Term]int),
+		terms:          make(map[termKey]int),
 		queries:        make(map[interface{}]int),
-		numericUpdates: make(map[string]map[*Term]*DocValuesUpdate),
-		binaryUpdates:  make(map[string]map[*Term]*DocValuesUpdate),
+		numericUpdates: make(map[string]*docValuesUpdates),
+		binaryUpdates:  make(map[string]*docValuesUpdates),
 	}
 }
 
 func (bd *BufferedUpdates) String() string {
-	panic("not implemented yet")
-	// if VERBOSE {
-	// 	return fmt.Sprintf(
-	// 		"BufferedUpdates[gen=%v, numTerms=%v, terms=%v, queries=%v, docIDs=%v, bytesUsed=%v]",
-	// 		bd.gen, atomic.LoadInt32(&bd.numTermDeletes), bd.terms, bd.queries, bd.docIDs, bd.bytesUsed)
-	// } else {
-	// 	var buf bytes.Buffer
-	// 	fmt.Fprintf(&buf, "BufferedUpdates[gen=%v", bd.gen)
-	// 	if n := atomic.LoadInt32(&bd.numTermDeletes); n != 0 {
-	// 		fmt.Fprintf(&buf, " %v deleted terms (unique count=%v)", n, len(bd.terms))
-	// 	}
-	// 	if len(bd.queries) > 0 {
-	// 		fmt.Fprintf(&buf, " %v deleted queries", len(bd.queries))
-	// 	}
-	// 	if len(bd.docIDs) > 0 {
-	// 		fmt.Fprintf(&buf, " %v deleted docIDs", len(bd.docIDs))
-	// 	}
-	// 	if n := atomic.LoadInt64(&bd.bytesUsed); n != 0 {
-	// 		fmt.Fprintf(&buf, " bytesUsed=%v", n)
-	// 	}
-	// 	buf.WriteRune(']')
-	// 	return buf.String()
-	// }
+	if VERBOSE {
+		return fmt.Sprintf(
+			"BufferedUpdates[gen=%v, numTerms=%v, terms=%v, queries=%v, docIDs=%v, numericUpdates=%v, binaryUpdates=%v, bytesUsed=%v]",
+			bd.gen, atomic.LoadInt32(&bd.numTermDeletes), bd.terms, bd.queries, bd.docIDs,
+			bd.numericUpdates, bd.binaryUpdates, bd.bytesUsed)
+	} else {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "BufferedUpdates[gen=%v", bd.gen)
+		if n := atomic.LoadInt32(&bd.numTermDeletes); n != 0 {
+			fmt.Fprintf(&buf, " %v deleted terms (unique count=%v)", n, len(bd.terms))
+		}
+		if len(bd.queries) > 0 {
+			fmt.Fprintf(&buf, " %v deleted queries", len(bd.queries))
+		}
+		if len(bd.docIDs) > 0 {
+			fmt.Fprintf(&buf, " %v deleted docIDs", len(bd.docIDs))
+		}
+		if n := atomic.LoadInt32(&bd.numNumericUpdates); n != 0 {
+			fmt.Fprintf(&buf, " %v numeric updates (unique count=%v)", n, len(bd.numericUpdates))
+		}
+		if n := atomic.LoadInt32(&bd.numBinaryUpdates); n != 0 {
+			fmt.Fprintf(&buf, " %v binary updates (unique count=%v)", n, len(bd.binaryUpdates))
+		}
+		if n := atomic.LoadInt64(&bd.bytesUsed); n != 0 {
+			fmt.Fprintf(&buf, " bytesUsed=%v", n)
+		}
+		buf.WriteRune(']')
+		return buf.String()
+	}
+}
+
+func (bd *BufferedUpdates) addQuery(query Query, docIDUpto int) {
+	_, ok := bd.queries[query]
+	bd.queries[query] = docIDUpto
+	// increment bytes used only if the query wasn't added so far.
+	if !ok {
+		atomic.AddInt64(&bd.bytesUsed, BYTES_PER_DEL_QUERY)
+	}
 }
 
 func (bd *BufferedUpdates) addDocID(docID int) {
@@ -86,11 +132,69 @@ func (bd *BufferedUpdates) addDocID(docID int) {
 	atomic.AddInt64(&bd.bytesUsed, BYTES_PER_DEL_DOCID)
 }
 
+func (bd *BufferedUpdates) addTerm(term *Term, docIDUpto int) {
+	key := keyOf(term)
+	current, ok := bd.terms[key]
+	if ok && docIDUpto < current {
+		// Only record the new number if it's greater than the current
+		// one. This is important because if multiple threads are
+		// replacing the same doc at nearly the same time, it's possible
+		// that one thread that got a higher docID is scheduled before
+		// the other threads. If we blindly replace then we can
+		// incorrectly get both docs indexed.
+		return
+	}
+
+	bd.terms[key] = docIDUpto
+	// note that if current != nil then it means there's already a
+	// buffered delete on that term, therefore we seem to over-count.
+	// this over-counting is done to respect IndexWriterConfig.setMaxBufferedDeleteTerms.
+	atomic.AddInt32(&bd.numTermDeletes, 1)
+	if !ok {
+		atomic.AddInt64(&bd.bytesUsed, int64(BYTES_PER_DEL_TERM+len(term.Bytes)+
+			util.NUM_BYTES_CHAR*len(term.Field)))
+	}
+}
+
+func (bd *BufferedUpdates) addNumericUpdate(update *DocValuesUpdate, docIDUpto int) {
+	fieldUpdates, ok := bd.numericUpdates[update.field]
+	if !ok {
+		fieldUpdates = newDocValuesUpdates()
+		bd.numericUpdates[update.field] = fieldUpdates
+		atomic.AddInt64(&bd.bytesUsed, BYTES_PER_NUMERIC_FIELD_ENTRY)
+	}
+	if added, replaced := fieldUpdates.add(update, docIDUpto); added {
+		atomic.AddInt32(&bd.numNumericUpdates, 1)
+		if !replaced {
+			atomic.AddInt64(&bd.bytesUsed, int64(BYTES_PER_NUMERIC_UPDATE_ENTRY+update.sizeInBytes()))
+		}
+	}
+}
+
+func (bd *BufferedUpdates) addBinaryUpdate(update *DocValuesUpdate, docIDUpto int) {
+	fieldUpdates, ok := bd.binaryUpdates[update.field]
+	if !ok {
+		fieldUpdates = newDocValuesUpdates()
+		bd.binaryUpdates[update.field] = fieldUpdates
+		atomic.AddInt64(&bd.bytesUsed, BYTES_PER_BINARY_FIELD_ENTRY)
+	}
+	if added, replaced := fieldUpdates.add(update, docIDUpto); added {
+		atomic.AddInt32(&bd.numBinaryUpdates, 1)
+		if !replaced {
+			atomic.AddInt64(&bd.bytesUsed, int64(BYTES_PER_BINARY_UPDATE_ENTRY+update.sizeInBytes()))
+		}
+	}
+}
+
 func (bd *BufferedUpdates) clear() {
-	bd.terms = make(map[*Term]int)
+	bd.terms = make(map[termKey]int)
 	bd.queries = make(map[interface{}]int)
 	bd.docIDs = nil
+	bd.numericUpdates = make(map[string]*docValuesUpdates)
+	bd.binaryUpdates = make(map[string]*docValuesUpdates)
 	atomic.StoreInt32(&bd.numTermDeletes, 0)
+	atomic.StoreInt32(&bd.numNumericUpdates, 0)
+	atomic.StoreInt32(&bd.numBinaryUpdates, 0)
 	atomic.StoreInt64(&bd.bytesUsed, 0)
 }
 
@@ -99,6 +203,67 @@ func (bd *BufferedUpdates) any() bool {
 		len(bd.numericUpdates) > 0 || len(bd.binaryUpdates) > 0
 }
 
+/*
+Insertion-ordered DocValues updates of a single field, key'd by the
+update Term. Re-adding an update for a term moves it to the end.
+*/
+type docValuesUpdates struct {
+	index   map[termKey]int // term -> position in updates
+	updates []*DocValuesUpdate
+}
+
+func newDocValuesUpdates() *docValuesUpdates {
+	return &docValuesUpdates{index: make(map[termKey]int)}
+}
+
+/* Comparable form of a Term, so that equal terms share a map entry. */
+type termKey struct {
+	field, text string
+}
+
+func keyOf(term *Term) termKey {
+	return termKey{term.Field, string(term.Bytes)}
+}
+
+func (k termKey) term() *Term {
+	return NewTerm(k.field, k.text)
+}
+
+/*
+Records the update, unless a later update (one with a greater
+docIDUpto) was already recorded for the same term.
+*/
+func (u *docValuesUpdates) add(update *DocValuesUpdate, docIDUpto int) (added, replaced bool) {
+	key := keyOf(update.term)
+	pos, replaced := u.index[key]
+	if replaced {
+		if current := u.updates[pos]; docIDUpto < current.docIDUpto {
+			// Only record the new number if it's greater than or equal to
+			// the current one. This is important because if multiple
+			// threads are replacing the same doc at nearly the same time,
+			// it's possible that one thread that got a higher docID is
+			// scheduled before the other threads.
+			return false, true
+		}
+		// remove the current entry so that the update is added last
+		// (we're interested in insertion-order).
+		u.updates[pos] = nil
+	}
+	update.docIDUpto = docIDUpto
+	u.index[key] = len(u.updates)
+	u.updates = append(u.updates, update)
+	return true, replaced
+}
+
+/* Calls f on each update, in insertion order. */
+func (u *docValuesUpdates) each(f func(update *DocValuesUpdate)) {
+	for _, update := range u.updates {
+		if update != nil {
+			f(update)
+		}
+	}
+}
+
 // index/FrozenBufferedUpdates.java
 
 /*
@@ -130,19 +295,24 @@ type FrozenBufferedUpdates struct {
 	isSegmentPrivate bool
 }
 
-func freezeBufferedUpdates(deletes *BufferedUpdates, isPrivate bool) *FrozenBufferedUpdates {
+func freezeBufferedUpdates(deletes *BufferedUpdates, isPrivate bool) (*FrozenBufferedUpdates, error) {
 	assert2(!isPrivate || len(deletes.terms) == 0,
 		"segment private package should only have del queries")
 	var termsArray []*Term
 	for k, _ := range deletes.terms {
-		termsArray = append(termsArray, k)
+		termsArray = append(termsArray, k.term())
 	}
 	util.TimSort(TermSorter(termsArray))
 	builder := newPrefixCodedTermsBuilder()
 	for _, term := range termsArray {
-		builder.add(term)
+		if err := builder.add(term); err != nil {
+			return nil, err
+		}
+	}
+	terms, err := builder.finish()
+	if err != nil {
+		return nil, err
 	}
-	terms := builder.finish()
 
 	queries := make([]Query, len(deletes.queries))
 	queryLimits := make([]int, len(deletes.queries))
@@ -160,10 +330,10 @@ func freezeBufferedUpdates(deletes *BufferedUpdates, isPrivate bool) *FrozenBuff
 	var allNumericUpdates []*DocValuesUpdate
 	numericUpdatesSize := 0
 	for _, numericUpdates := range deletes.numericUpdates {
-		for _, update := range numericUpdates {
+		numericUpdates.each(func(update *DocValuesUpdate) {
 			allNumericUpdates = append(allNumericUpdates, update)
 			numericUpdatesSize += update.sizeInBytes()
-		}
+		})
 	}
 
 	// TODO if a Term affects multiple fields, we could keep the updates key'd by Term
@@ -173,10 +343,10 @@ func freezeBufferedUpdates(deletes *BufferedUpdates, isPrivate bool) *FrozenBuff
 	var allBinaryUpdates []*DocValuesUpdate
 	binaryUpdatesSize := 0
 	for _, binaryUpdates := range deletes.binaryUpdates {
-		for _, update := range binaryUpdates {
+		binaryUpdates.each(func(update *DocValuesUpdate) {
 			allBinaryUpdates = append(allBinaryUpdates, update)
 			binaryUpdatesSize += update.sizeInBytes()
-		}
+		})
 	}
 
 	bytesUsed := int(terms.RamBytesUsed() +
@@ -195,7 +365,7 @@ func freezeBufferedUpdates(deletes *BufferedUpdates, isPrivate bool) *FrozenBuff
 		binaryDVUpdates:  allBinaryUpdates,
 		bytesUsed:        bytesUsed,
 		numTermDeletes:   int(atomic.LoadInt32(&deletes.numTermDeletes)),
-	}
+	}, nil
 }
 
 func assert(ok bool) {
@@ -211,11 +381,31 @@ func assert2(ok bool, msg string, args ...interface{}) {
 }
 
 func (bd *FrozenBufferedUpdates) queries() []*QueryAndLimit {
-	panic("not implemented yet")
+	ans := make([]*QueryAndLimit, len(bd._queries))
+	for i, query := range bd._queries {
+		ans[i] = &QueryAndLimit{query, bd.queryLimits[i]}
+	}
+	return ans
 }
 
 func (bd *FrozenBufferedUpdates) String() string {
-	panic("not implemented yet")
+	var buf bytes.Buffer
+	if bd.numTermDeletes != 0 {
+		fmt.Fprintf(&buf, " %v deleted terms (unique count=%v)", bd.numTermDeletes, bd.termCount)
+	}
+	if len(bd._queries) != 0 {
+		fmt.Fprintf(&buf, " %v deleted queries", len(bd._queries))
+	}
+	if bd.bytesUsed != 0 {
+		fmt.Fprintf(&buf, " bytesUsed=%v", bd.bytesUsed)
+	}
+	if len(bd.numericDVUpdates) > 0 {
+		fmt.Fprintf(&buf, " numeric DV updates=%v", len(bd.numericDVUpdates))
+	}
+	if len(bd.binaryDVUpdates) > 0 {
+		fmt.Fprintf(&buf, " binary DV updates=%v", len(bd.binaryDVUpdates))
+	}
+	return buf.String()
 }
 
 func (d *FrozenBufferedUpdates) any() bool {
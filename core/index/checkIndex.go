@@ -1,13 +1,15 @@
 package index
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	. "github.com/gzg1984/golucene/core/codec/spi"
+	. "github.com/gzg1984/golucene/core/index/model"
+	. "github.com/gzg1984/golucene/core/search/model"
 	"github.com/gzg1984/golucene/core/store"
 	"github.com/gzg1984/golucene/core/util"
 	"io"
-	"runtime/debug"
 	"strconv"
 )
 
@@ -22,25 +24,31 @@ type CheckIndexStatus struct {
 	MissingSegments bool
 
 	// True if we were unable to open the segments_N file.
-	cantOpenSegments bool
+	CantOpenSegments bool
 
 	// True if we were unable to read the versioin number from segments_N file.
-	missingSegmentVersion bool
+	MissingSegmentVersion bool
 
 	// Name of latest segments_N file in the index.
-	segmentsFilename string
+	SegmentsFilename string
 
 	// Number of segments in the index
-	numSegments int
+	NumSegments int
+
+	// Empty unless you passed specific segments list to check as optional 3rd argument.
+	SegmentsChecked []string
 
 	// True if the index was created with a newer version of Lucene than the CheckIndex tool.
-	toolOutOfDate bool
+	ToolOutOfDate bool
+
+	// True if we checked only specific segments (CheckIndex() was called with non-nil argument).
+	Partial bool
 
 	// List of SegmentInfoStatus instances, detailing status of each segment.
-	segmentInfos []*SegmentInfoStatus
+	SegmentInfos []*SegmentInfoStatus
 
 	// Directory index is in.
-	dir store.Directory
+	Dir store.Directory
 
 	// SegmentInfos instance containing only segments that had no
 	// problems (this is used with the fixIndex() method to repare the
@@ -48,93 +56,131 @@ type CheckIndexStatus struct {
 	newSegments *SegmentInfos
 
 	// How many documents will be lost to bad segments.
-	totLoseDocCount int
+	TotLoseDocCount int
 
 	// How many bad segments were found.
-	numBadSegments int
+	NumBadSegments int
 
 	// Whether the SegmentInfos.counter is greater than any of the segments' names.
-	validCounter bool
+	ValidCounter bool
 
 	// The greatest segment name.
-	maxSegmentName int
+	MaxSegmentName int
 
 	// Holds the userData of the last commit in the index
-	userData map[string]string
+	UserData map[string]string
 }
 
 /* Holds the status of each segment in the index. */
 type SegmentInfoStatus struct {
 	// Name of the segment
-	name string
+	Name string
 
 	// Codec used to read this segment.
-	codec Codec
+	Codec Codec
 
 	// Document count (does not take deletions into account).
-	docCount int
+	DocCount int
 
 	// True if segment is compound file format.
-	compound bool
+	Compound bool
 
 	// Number of files referenced by this segment.
-	numFiles int
+	NumFiles int
 
 	// Net size (MB) of the files referenced by this segment.
-	sizeMB float64
+	SizeMB float64
 
 	// True if this segment has pending deletions.
-	hasDeletions bool
+	HasDeletions bool
 
 	// Current deletions generation.
-	deletionsGen int64
+	DeletionsGen int64
 
 	// Number of deleted documents.
-	numDeleted int
+	NumDeleted int
 
 	// True if we were able to open an AR on this segment.
-	openReaderPassed bool
+	OpenReaderPassed bool
 
 	// Number of fields in this segment.
-	numFields int
+	NumFields int
 
 	// Map that includes certain debugging details that IW records into each segment it creates
-	diagnostics map[string]string
+	Diagnostics map[string]string
 
 	// Status for testing of field norms (nil if field norms could not be tested).
-	fieldNormStatus *FieldNormStatus
+	FieldNormStatus *FieldNormStatus
 
 	// Status for testing of indexed terms (nil if indexed terms could not be tested).
-	termIndexStatus *TermIndexStatus
+	TermIndexStatus *TermIndexStatus
 
 	// Status for testing of stored fields (nil if stored fields could not be tested).
-	storedFieldStatus *StoredFieldStatus
+	StoredFieldStatus *StoredFieldStatus
 
 	// Status for testing term vectors (nil if term vectors could not be tested).
-	termVectorStatus *TermVectorStatus
+	TermVectorStatus *TermVectorStatus
 
 	// Status for testing of DocVlaues (nil if DocValues could not be tested).
-	docValuesStatus *DocValuesStatus
+	DocValuesStatus *DocValuesStatus
 }
 
+/* Status from testing field norms. */
 type FieldNormStatus struct {
-	err error
+	// Number of fields successfully tested
+	TotFields int64
+	// Error thrown during term index test (nil on success)
+	Err error
 }
 
+/* Status from testing term index. */
 type TermIndexStatus struct {
-	err error
+	// Number of terms with at least one live doc.
+	TermCount int64
+	// Number of terms with zero live docs docs.
+	DelTermCount int64
+	// Total frequency across all terms.
+	TotFreq int64
+	// Total number of positions.
+	TotPos int64
+	// Error thrown during term index test (nil on success)
+	Err error
 }
 
+/* Status from testing stored fields. */
 type StoredFieldStatus struct {
-	err error
+	// Number of documents tested.
+	DocCount int
+	// Total number of stored fields tested.
+	TotFields int64
+	// Error thrown during stored fields test (nil on success)
+	Err error
 }
 
+/* Status from testing stored fields. */
 type TermVectorStatus struct {
-	err error
+	// Number of documents tested.
+	DocCount int
+	// Total number of term vectors tested.
+	TotVectors int64
+	// Error thrown during term vector test (nil on success)
+	Err error
 }
 
+/* Status from testing DocValues */
 type DocValuesStatus struct {
-	err error
+	// Total number of docValues tested.
+	TotalValueFields int64
+	// Total number of numeric fields
+	TotalNumericFields int64
+	// Total number of binary fields
+	TotalBinaryFields int64
+	// Total number of sorted fields
+	TotalSortedFields int64
+	// Total number of sortedset fields
+	TotalSortedSetFields int64
+	// Error thrown during doc values test (nil on success)
+	Err error
 }
 
 /*
@@ -153,15 +199,25 @@ type CheckIndex struct {
 
 func NewCheckIndex(dir store.Directory, crossCheckTermVectors bool, infoStream io.Writer) *CheckIndex {
 	return &CheckIndex{
-		infoStream: infoStream,
-		dir:        dir,
+		infoStream:            infoStream,
+		dir:                   dir,
 		crossCheckTermVectors: crossCheckTermVectors,
 	}
 }
 
+/*
+If true, just return the first error instead of checking the rest of
+the index and recording the problems in the returned status.
+*/
+func (ch *CheckIndex) SetFailFast(v bool) {
+	ch.failFast = v
+}
+
 func (ch *CheckIndex) msg(msg string, args ...interface{}) {
-	fmt.Fprintf(ch.infoStream, msg, args...)
-	fmt.Fprintln(ch.infoStream)
+	if ch.infoStream != nil {
+		fmt.Fprintf(ch.infoStream, msg, args...)
+		fmt.Fprintln(ch.infoStream)
+	}
 }
 
 /*
@@ -170,23 +226,27 @@ Returns a Status instance detailing the state of the index.
 As this method checks every byte in the specified segments, on a
 large index it can take quite a long time to run.
 
+If onlySegments is not nil, only the segments of these names are
+checked. The error is only returned in fail fast mode, where it is
+the first problem found.
+
 WARNING: make sure you only call this when the index is not opened
 by any writer.
 */
-func (ch *CheckIndex) CheckIndex(onlySegments []string) *CheckIndexStatus {
+func (ch *CheckIndex) CheckIndex(onlySegments []string) (*CheckIndexStatus, error) {
 	sis := &SegmentInfos{}
 	result := &CheckIndexStatus{
-		dir: ch.dir,
+		Dir: ch.dir,
 	}
 	err := sis.ReadAll(ch.dir)
 	if err != nil {
 		if ch.failFast {
-			panic("niy")
+			return result, err
 		}
-		fmt.Fprintln(ch.infoStream, "ERROR: could not read any segments file in directory")
-		debug.PrintStack()
+		ch.msg("ERROR: could not read any segments file in directory")
+		ch.msg("%v", err)
 		result.MissingSegments = true
-		return result
+		return result, nil
 	}
 
 	// find the oldest and newest segment versions
@@ -194,11 +254,11 @@ func (ch *CheckIndex) CheckIndex(onlySegments []string) *CheckIndexStatus {
 	var newest util.Version
 	var oldSegs string
 	for _, si := range sis.Segments {
-		if version := si.Info.Version(); len(version) != 0 {
-			if len(oldest) == 0 || !version.OnOrAfter(oldest) {
+		if version := si.Info.Version(); version != (util.Version{}) {
+			if oldest == (util.Version{}) || !version.OnOrAfter(oldest) {
 				oldest = version
 			}
-			if len(newest) == 0 || version.OnOrAfter(newest) {
+			if newest == (util.Version{}) || version.OnOrAfter(newest) {
 				newest = version
 			}
 		} else {
@@ -213,32 +273,32 @@ func (ch *CheckIndex) CheckIndex(onlySegments []string) *CheckIndexStatus {
 	input, err := ch.dir.OpenInput(segmentsFilename, store.IO_CONTEXT_READONCE)
 	if err != nil {
 		if ch.failFast {
-			panic("niy")
+			return result, err
 		}
-		fmt.Fprintln(ch.infoStream, "ERROR: could not open segments file in directory")
-		debug.PrintStack()
-		result.cantOpenSegments = true
-		return result
+		ch.msg("ERROR: could not open segments file in directory")
+		ch.msg("%v", err)
+		result.CantOpenSegments = true
+		return result, nil
 	}
 	defer input.Close() // ignore error
 
 	_, err = input.ReadInt()
 	if err != nil {
 		if ch.failFast {
-			panic("niy")
+			return result, err
 		}
-		fmt.Fprintln(ch.infoStream, "ERROR: could not read segment file version in directory")
-		debug.PrintStack()
-		result.missingSegmentVersion = true
-		return result
+		ch.msg("ERROR: could not read segment file version in directory")
+		ch.msg("%v", err)
+		result.MissingSegmentVersion = true
+		return result, nil
 	}
 
 	var sFormat string
 	var skip = false
 
-	result.segmentsFilename = segmentsFilename
-	result.numSegments = numSegments
-	result.userData = sis.userData
+	result.SegmentsFilename = segmentsFilename
+	result.NumSegments = numSegments
+	result.UserData = sis.userData
 	var userDataStr string
 	if len(sis.userData) > 0 {
 		userDataStr = fmt.Sprintf(" userData=%v", sis.userData)
@@ -246,12 +306,12 @@ func (ch *CheckIndex) CheckIndex(onlySegments []string) *CheckIndexStatus {
 
 	var versionStr string
 	if oldSegs != "" {
-		if len(newest) != 0 {
+		if newest != (util.Version{}) {
 			versionStr = fmt.Sprintf("versions=[%v .. %v]", oldSegs, newest)
 		} else {
 			versionStr = fmt.Sprintf("version=%v", oldSegs)
 		}
-	} else if len(newest) != 0 { // implies oldest is set
+	} else if newest != (util.Version{}) { // implies oldest is set
 		if newest.Equals(oldest) {
 			versionStr = fmt.Sprintf("version=%v", oldest)
 		} else {
@@ -264,70 +324,72 @@ func (ch *CheckIndex) CheckIndex(onlySegments []string) *CheckIndexStatus {
 
 	names := make(map[string]bool)
 	if onlySegments != nil {
+		result.Partial = true
+		ch.msg("\nChecking only these segments: %v:", onlySegments)
 		for _, name := range onlySegments {
 			names[name] = true
 		}
-		panic("not implemented yet")
+		result.SegmentsChecked = append(result.SegmentsChecked, onlySegments...)
 	}
 
 	if skip {
 		ch.msg(
 			"\nERROR: this index appears to be created by a newer version of Lucene than this tool was compiled on; please re-compile this tool on the matching version of Lucene; exiting")
-		result.toolOutOfDate = true
-		return result
+		result.ToolOutOfDate = true
+		return result, nil
 	}
 
 	result.newSegments = sis.Clone()
 	result.newSegments.Clear()
-	result.maxSegmentName = -1
+	result.MaxSegmentName = -1
 
 	for i, info := range sis.Segments {
 		segmentName, err := strconv.ParseInt(info.Info.Name[1:], 36, 32)
 		if err != nil {
 			panic(err) // impossible
 		}
-		if int(segmentName) > result.maxSegmentName {
-			result.maxSegmentName = int(segmentName)
+		if int(segmentName) > result.MaxSegmentName {
+			result.MaxSegmentName = int(segmentName)
 		}
-		if _, ok := names[info.Info.Name]; !ok {
+		if onlySegments != nil && !names[info.Info.Name] {
 			continue
 		}
 		segInfoStat := new(SegmentInfoStatus)
-		result.segmentInfos = append(result.segmentInfos, segInfoStat)
+		result.SegmentInfos = append(result.SegmentInfos, segInfoStat)
 		infoDocCount := info.Info.DocCount()
 		ch.msg("  %v of %v: name=%v docCount=%v ",
 			1+i, numSegments, info.Info.Name, infoDocCount)
-		segInfoStat.name = info.Info.Name
-		segInfoStat.docCount = infoDocCount
+		segInfoStat.Name = info.Info.Name
+		segInfoStat.DocCount = infoDocCount
 
 		version := info.Info.Version()
-		if infoDocCount <= 0 && version.OnOrAfter(util.VERSION_45) {
-			panic(fmt.Sprintf("illegal number of documents: maxDoc=%v", infoDocCount))
-		}
-
-		toLoseDocCount := infoDocCount
+		// the live docs, as far as the segment info tells
+		toLoseDocCount := infoDocCount - info.DelCount()
 		err = func() error {
-			assert2(len(version) != 0, "pre 4.0 is not supported yet")
+			if infoDocCount <= 0 && version.OnOrAfter(util.VERSION_45) {
+				return fmt.Errorf("illegal number of documents: maxDoc=%v", infoDocCount)
+			}
+			assert2(version != (util.Version{}), "pre 4.0 is not supported yet")
 			ch.msg("    version=%v", version)
 			codec := info.Info.Codec().(Codec)
 			ch.msg("    codec = %v", codec)
-			segInfoStat.codec = codec
+			segInfoStat.Codec = codec
 			ch.msg("    compound = %v", info.Info.IsCompoundFile())
-			segInfoStat.compound = info.Info.IsCompoundFile()
+			segInfoStat.Compound = info.Info.IsCompoundFile()
 			ch.msg("    numFiles = %v", len(info.Files()))
-			segInfoStat.numFiles = len(info.Files())
+			segInfoStat.NumFiles = len(info.Files())
 			n, err := info.SizeInBytes()
 			if err != nil {
 				return err
 			}
-			segInfoStat.sizeMB = float64(n) / (1024 * 1024)
+			segInfoStat.SizeMB = float64(n) / (1024 * 1024)
 			if v := info.Info.Attribute("Lucene3xSegmentInfoFormat.dsoffset"); v == "" {
 				// don't print size in bytes if it's a 3.0 segment iwht shared docstores
-				ch.msg("    size (MB) = %v", segInfoStat.sizeMB)
+				ch.msg("    size (MB) = %v", segInfoStat.SizeMB)
 			}
 
 			diagnostics := info.Info.Diagnostics()
-			segInfoStat.diagnostics = diagnostics
+			segInfoStat.Diagnostics = diagnostics
 			if len(diagnostics) > 0 {
 				ch.msg("    diagnostics = %v", diagnostics)
 			}
@@ -337,15 +399,13 @@ func (ch *CheckIndex) CheckIndex(onlySegments []string) *CheckIndexStatus {
 				ch.msg("    attributes = %v", atts)
 			}
 
-			panic("not implemented yet")
-
 			if !info.HasDeletions() {
 				ch.msg("    no deletions")
-				segInfoStat.hasDeletions = false
+				segInfoStat.HasDeletions = false
 			} else {
 				ch.msg("     has deletions [delGen = %v]", info.DelGen())
-				segInfoStat.hasDeletions = true
-				segInfoStat.deletionsGen = info.DelGen()
+				segInfoStat.HasDeletions = true
+				segInfoStat.DeletionsGen = info.DelGen()
 			}
 
 			ch.msg("    test: open reader.........")
@@ -355,8 +415,16 @@ func (ch *CheckIndex) CheckIndex(onlySegments []string) *CheckIndexStatus {
 			}
 			defer reader.Close()
 
-			segInfoStat.openReaderPassed = true
+			segInfoStat.OpenReaderPassed = true
+			ch.msg("OK")
+
+			ch.msg("    test: check integrity.....")
+			if err = checkIntegrity(info); err != nil {
+				return err
+			}
+			ch.msg("OK")
 
+			ch.msg("    test: check live docs.....")
 			numDocs := reader.NumDocs()
 			toLoseDocCount = numDocs
 			if reader.hasDeletions() {
@@ -392,8 +460,8 @@ func (ch *CheckIndex) CheckIndex(onlySegments []string) *CheckIndexStatus {
 					}
 				}
 
-				segInfoStat.numDeleted = infoDocCount - numDocs
-				ch.msg("OK [%v deleted docs]", segInfoStat.numDeleted)
+				segInfoStat.NumDeleted = infoDocCount - numDocs
+				ch.msg("OK [%v deleted docs]", segInfoStat.NumDeleted)
 			} else {
 				if info.DelCount() != 0 {
 					return errors.New(fmt.Sprintf(
@@ -422,26 +490,26 @@ func (ch *CheckIndex) CheckIndex(onlySegments []string) *CheckIndexStatus {
 			ch.msg("    test: fields..............")
 			fieldInfos := reader.FieldInfos()
 			ch.msg("OK [%v fields]", fieldInfos.Size())
-			segInfoStat.numFields = fieldInfos.Size()
+			segInfoStat.NumFields = fieldInfos.Size()
 
-			segInfoStat.fieldNormStatus = ch.testFieldNorms(reader)
-			segInfoStat.termIndexStatus = ch.testPostings(reader)
-			segInfoStat.storedFieldStatus = ch.testStoredFields(reader)
-			segInfoStat.termVectorStatus = ch.testTermVectors(reader)
-			segInfoStat.docValuesStatus = ch.testDocValues(reader)
+			segInfoStat.FieldNormStatus = ch.testFieldNorms(reader)
+			segInfoStat.TermIndexStatus = ch.testPostings(reader)
+			segInfoStat.StoredFieldStatus = ch.testStoredFields(reader)
+			segInfoStat.TermVectorStatus = ch.testTermVectors(reader)
+			segInfoStat.DocValuesStatus = ch.testDocValues(reader)
 
 			// Rethrow the first error we encountered
 			// This will cause stats for failed segments to be incremented properly
-			if segInfoStat.fieldNormStatus.err != nil {
-				return errors.New("Field Norm test failed")
-			} else if segInfoStat.termIndexStatus.err != nil {
-				return errors.New("Term Index test failed")
-			} else if segInfoStat.storedFieldStatus.err != nil {
-				return errors.New("Stored Field test failed")
-			} else if segInfoStat.termVectorStatus.err != nil {
-				return errors.New("Term Vector test failed")
-			} else if segInfoStat.docValuesStatus.err != nil {
-				return errors.New("DocValues test failed")
+			if err := segInfoStat.FieldNormStatus.Err; err != nil {
+				return fmt.Errorf("Field Norm test failed: %v", err)
+			} else if err := segInfoStat.TermIndexStatus.Err; err != nil {
+				return fmt.Errorf("Term Index test failed: %v", err)
+			} else if err := segInfoStat.StoredFieldStatus.Err; err != nil {
+				return fmt.Errorf("Stored Field test failed: %v", err)
+			} else if err := segInfoStat.TermVectorStatus.Err; err != nil {
+				return fmt.Errorf("Term Vector test failed: %v", err)
+			} else if err := segInfoStat.DocValuesStatus.Err; err != nil {
+				return fmt.Errorf("DocValues test failed: %v", err)
 			}
 
 			ch.msg("")
@@ -449,61 +517,890 @@ func (ch *CheckIndex) CheckIndex(onlySegments []string) *CheckIndexStatus {
 		}()
 		if err != nil {
 			if ch.failFast {
-				panic("niy")
+				return result, err
 			}
 			ch.msg("FAILED")
 			comment := "fixIndex() would remove reference to this segment"
 			ch.msg("    WARNING: %v; full error:", comment)
-			ch.msg(string(debug.Stack()))
+			ch.msg("%v", err)
 			ch.msg("")
-			result.totLoseDocCount += toLoseDocCount
-			result.numBadSegments++
+			result.TotLoseDocCount += toLoseDocCount
+			result.NumBadSegments++
 		} else {
 			// Keeper
 			result.newSegments.Segments = append(result.newSegments.Segments, info.Clone())
 		}
 	}
 
-	if result.numBadSegments == 0 {
+	if result.NumBadSegments == 0 {
 		result.Clean = true
 	} else {
 		ch.msg(
 			"WARNING: %v broken segments (containing %v documents) detected",
-			result.numBadSegments, result.totLoseDocCount)
+			result.NumBadSegments, result.TotLoseDocCount)
 	}
 
-	result.validCounter = result.maxSegmentName < sis.counter
-	if !result.validCounter {
+	result.ValidCounter = result.MaxSegmentName < sis.counter
+	if !result.ValidCounter {
 		result.Clean = false
-		result.newSegments.counter = result.maxSegmentName + 1
+		result.newSegments.counter = result.MaxSegmentName + 1
 		ch.msg(
 			"ERROR: Next segment name counter %v is not greater than max segment name %v",
-			sis.counter, result.maxSegmentName)
+			sis.counter, result.MaxSegmentName)
 	}
 
 	if result.Clean {
 		ch.msg("No problems were detected with this index.\n")
 	}
 
-	return result
+	return result, nil
+}
+
+/*
+Verifies the checksums in the codec footers of all the files of the
+segment. Segments written before 4.8 have no footers.
+*/
+func checkIntegrity(info *SegmentCommitInfo) error {
+	if !info.Info.Version().OnOrAfter(util.VERSION_48) {
+		return nil
+	}
+	for _, file := range info.Files() {
+		if err := func() error {
+			input, err := info.Info.Dir.OpenInput(file, store.IO_CONTEXT_READONCE)
+			if err != nil {
+				return err
+			}
+			defer input.Close()
+			_, err = store.ChecksumEntireFile(input)
+			return err
+		}(); err != nil {
+			return fmt.Errorf("file %v: %v", file, err)
+		}
+	}
+	return nil
 }
 
-func (ch *CheckIndex) testFieldNorms(reader AtomicReader) *FieldNormStatus {
-	panic("not implemented yet")
+/* Test field norms. */
+func (ch *CheckIndex) testFieldNorms(reader *SegmentReader) *FieldNormStatus {
+	status := new(FieldNormStatus)
+	ch.msg("    test: field norms.........")
+	status.Err = func() error {
+		for _, info := range reader.FieldInfos().Values {
+			norms, err := reader.NormValues(info.Name)
+			if err != nil {
+				return err
+			}
+			if info.HasNorms() {
+				if info.NormType() != DOC_VALUES_TYPE_NUMERIC {
+					return fmt.Errorf("wrong normType: %v", info.NormType())
+				}
+				if norms == nil {
+					return fmt.Errorf("field: %v has norms but they could not be read", info.Name)
+				}
+				for j := 0; j < reader.MaxDoc(); j++ {
+					norms(j)
+				}
+				status.TotFields++
+			} else if norms != nil {
+				return fmt.Errorf("field: %v should omit norms but has them!", info.Name)
+			}
+		}
+		return nil
+	}()
+	if status.Err != nil {
+		ch.msg("ERROR [%v]", status.Err)
+	} else {
+		ch.msg("OK [%v fields]", status.TotFields)
+	}
+	return status
 }
 
-func (ch *CheckIndex) testPostings(reader AtomicReader) *TermIndexStatus {
-	panic("not implemented yet")
+/* Test the term index. */
+func (ch *CheckIndex) testPostings(reader *SegmentReader) *TermIndexStatus {
+	// TODO: we should go and verify term vectors match, if
+	// crossCheckTermVectors is on...
+	ch.msg("    test: terms, freq, prox...")
+	fields := reader.Fields()
+	fieldInfos := reader.FieldInfos()
+	liveDocs := reader.LiveDocs()
+	status, err := ch.checkFields(fields, liveDocs, reader.MaxDoc(), fieldInfos, true, false)
+	if err == nil && liveDocs != nil {
+		ch.msg("    test (ignoring deletes): terms, freq, prox...")
+		_, err = ch.checkFields(fields, nil, reader.MaxDoc(), fieldInfos, true, false)
+	}
+	if err != nil {
+		ch.msg("ERROR: %v", err)
+		status = &TermIndexStatus{Err: err}
+	}
+	return status
 }
 
-func (ch *CheckIndex) testStoredFields(reader AtomicReader) *StoredFieldStatus {
-	panic("not implemented yet")
+/*
+Checks Fields api is consistent with itself: walks every term, doc
+and position through the codec readers, and compares the doc freq,
+total term freq and the field statistics against what was seen.
+*/
+func (ch *CheckIndex) checkFields(fields Fields, liveDocs util.Bits, maxDoc int,
+	fieldInfos FieldInfos, doPrint, isVectors bool) (*TermIndexStatus, error) {
+
+	status := new(TermIndexStatus)
+	if fields == nil {
+		if doPrint {
+			ch.msg("OK [no fields/terms]")
+		}
+		return status, nil
+	}
+
+	for _, fieldInfo := range fieldInfos.Values {
+		field := fieldInfo.Name
+		terms := fields.Terms(field)
+		if terms == nil {
+			continue
+		}
+		if !fieldInfo.IsIndexed() {
+			return status, fmt.Errorf("fieldsEnum inconsistent with fieldInfos, isIndexed == false for: %v", field)
+		}
+
+		indexOptions := fieldInfo.IndexOptions()
+		hasFreqs := isVectors || indexOptions >= INDEX_OPT_DOCS_AND_FREQS
+		hasPositions := !isVectors && indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS
+		hasOffsets := !isVectors && indexOptions >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS
+		docsFlags := DOCS_ENUM_FLAG_NONE
+		if hasFreqs {
+			docsFlags = DOCS_ENUM_FLAG_FREQS
+		}
+
+		var lastTerm []byte
+		var sumTotalTermFreq, sumDocFreq int64
+		visitedDocs := util.NewFixedBitSetOf(maxDoc)
+		// terms to seek back to once the field is enumerated
+		var seekTerms [][]byte
+		var seekDocFreqs []int
+		var fieldTermCount int64
+
+		termsEnum := terms.Iterator(nil)
+		for {
+			term, err := termsEnum.Next()
+			if err != nil {
+				return status, err
+			}
+			if term == nil {
+				break
+			}
+
+			// make sure terms arrive in order according to
+			// the comp
+			if lastTerm != nil && bytes.Compare(lastTerm, term) >= 0 {
+				return status, fmt.Errorf("terms out of order: lastTerm=%v term=%v", lastTerm, term)
+			}
+			lastTerm = append(lastTerm[:0], term...)
+			if lastTerm == nil {
+				lastTerm = []byte{}
+			}
+
+			docFreq, err := termsEnum.DocFreq()
+			if err != nil {
+				return status, err
+			}
+			if docFreq <= 0 {
+				return status, fmt.Errorf("docfreq: %v is out of bounds", docFreq)
+			}
+			sumDocFreq += int64(docFreq)
+
+			if fieldTermCount&(fieldTermCount-1) == 0 {
+				seekTerms = append(seekTerms, append([]byte(nil), term...))
+				seekDocFreqs = append(seekDocFreqs, docFreq)
+			}
+			fieldTermCount++
+
+			var docs DocsEnum
+			var postings DocsAndPositionsEnum
+			if hasPositions || isVectors {
+				postings, err = termsEnum.DocsAndPositionsByFlags(liveDocs, nil, postingsFlags(fieldInfo))
+				if err != nil {
+					return status, err
+				}
+				if hasPositions && postings == nil {
+					return status, fmt.Errorf("field %v: positions were indexed but no DocsAndPositionsEnum", field)
+				}
+			}
+			if postings != nil {
+				docs = postings
+			} else if docs, err = termsEnum.DocsByFlags(liveDocs, nil, docsFlags); err != nil {
+				return status, err
+			}
+
+			status.TermCount++
+
+			lastDoc, docCount := -1, 0
+			var totalTermFreq int64
+			for {
+				doc, err := docs.NextDoc()
+				if err != nil {
+					return status, err
+				}
+				if doc == NO_MORE_DOCS {
+					break
+				}
+				status.TotFreq++
+				visitedDocs.Set(doc)
+				freq := -1
+				if hasFreqs {
+					if freq, err = docs.Freq(); err != nil {
+						return status, err
+					}
+					if freq <= 0 {
+						return status, fmt.Errorf("term %v: doc %v: freq %v is out of bounds", term, doc, freq)
+					}
+					status.TotPos += int64(freq)
+					totalTermFreq += int64(freq)
+				}
+				docCount++
+
+				if doc <= lastDoc {
+					return status, fmt.Errorf("term %v: doc %v <= lastDoc %v", term, doc, lastDoc)
+				}
+				if doc >= maxDoc {
+					return status, fmt.Errorf("term %v: doc %v >= maxDoc %v", term, doc, maxDoc)
+				}
+				lastDoc = doc
+
+				if postings == nil {
+					continue
+				}
+				lastPos, lastOffset := -1, 0
+				for j := 0; j < freq; j++ {
+					pos, err := postings.NextPosition()
+					if err != nil {
+						return status, err
+					}
+					if pos < 0 {
+						return status, fmt.Errorf("term %v: doc %v: pos %v is out of bounds", term, doc, pos)
+					}
+					if pos < lastPos {
+						return status, fmt.Errorf("term %v: doc %v: pos %v < lastPos %v", term, doc, pos, lastPos)
+					}
+					lastPos = pos
+
+					payload, err := postings.Payload()
+					if err != nil {
+						return status, err
+					}
+					if len(payload) > 0 && !isVectors && !fieldInfo.HasPayloads() {
+						return status, fmt.Errorf("term %v: doc %v: pos %v has a payload but field %v stores none", term, doc, pos, field)
+					}
+
+					startOffset, err := postings.StartOffset()
+					if err != nil {
+						return status, err
+					}
+					endOffset, err := postings.EndOffset()
+					if err != nil {
+						return status, err
+					}
+					if !hasOffsets && !(isVectors && startOffset != -1) {
+						continue
+					}
+					if startOffset < 0 {
+						return status, fmt.Errorf("term %v: doc %v: pos %v: startOffset %v is out of bounds", term, doc, pos, startOffset)
+					}
+					if startOffset < lastOffset {
+						return status, fmt.Errorf("term %v: doc %v: pos %v: startOffset %v < lastStartOffset %v", term, doc, pos, startOffset, lastOffset)
+					}
+					if endOffset < startOffset {
+						return status, fmt.Errorf("term %v: doc %v: pos %v: endOffset %v < startOffset %v", term, doc, pos, endOffset, startOffset)
+					}
+					lastOffset = startOffset
+				}
+			}
+
+			if docCount == 0 {
+				status.DelTermCount++
+			}
+
+			// Re-count if there are deleted docs:
+			if liveDocs != nil {
+				docsNoDel, err := termsEnum.DocsByFlags(nil, nil, docsFlags)
+				if err != nil {
+					return status, err
+				}
+				docCount, totalTermFreq = 0, 0
+				for {
+					doc, err := docsNoDel.NextDoc()
+					if err != nil {
+						return status, err
+					}
+					if doc == NO_MORE_DOCS {
+						break
+					}
+					visitedDocs.Set(doc)
+					docCount++
+					if hasFreqs {
+						freq, err := docsNoDel.Freq()
+						if err != nil {
+							return status, err
+						}
+						totalTermFreq += int64(freq)
+					}
+				}
+			}
+
+			if docCount != docFreq {
+				return status, fmt.Errorf("term %v docFreq=%v != tot docs w/o deletions %v", term, docFreq, docCount)
+			}
+			totalTermFreq2, err := termsEnum.TotalTermFreq()
+			if err != nil {
+				return status, err
+			}
+			if hasFreqs && totalTermFreq2 != -1 {
+				if totalTermFreq2 <= 0 {
+					return status, fmt.Errorf("totalTermFreq: %v is out of bounds", totalTermFreq2)
+				}
+				sumTotalTermFreq += totalTermFreq
+				if totalTermFreq != totalTermFreq2 {
+					return status, fmt.Errorf("term %v totalTermFreq=%v != recomputed totalTermFreq=%v", term, totalTermFreq2, totalTermFreq)
+				}
+			}
+
+			// Test skipping
+			for idx := 0; idx < 7; idx++ {
+				skipDocID := int(int64(idx+1) * int64(maxDoc) / 8)
+				it, err := termsEnum.DocsByFlags(liveDocs, nil, DOCS_ENUM_FLAG_NONE)
+				if err != nil {
+					return status, err
+				}
+				docID, err := it.Advance(skipDocID)
+				if err != nil {
+					return status, err
+				}
+				if docID == NO_MORE_DOCS {
+					break
+				}
+				if docID < skipDocID {
+					return status, fmt.Errorf("term %v: advance(docID=%v) returned docID=%v", term, skipDocID, docID)
+				}
+				nextDocID, err := it.NextDoc()
+				if err != nil {
+					return status, err
+				}
+				if nextDocID == NO_MORE_DOCS {
+					break
+				}
+				if nextDocID <= docID {
+					return status, fmt.Errorf("term %v: advance(docID=%v), then .next() returned docID=%v vs prev docID=%v", term, skipDocID, nextDocID, docID)
+				}
+			}
+		}
+
+		if v := terms.SumTotalTermFreq(); v != -1 && sumTotalTermFreq != v {
+			return status, fmt.Errorf("sumTotalTermFreq for field %v=%v != recomputed sumTotalTermFreq=%v", field, v, sumTotalTermFreq)
+		}
+		if v := terms.SumDocFreq(); v != -1 && sumDocFreq != v {
+			return status, fmt.Errorf("sumDocFreq for field %v=%v != recomputed sumDocFreq=%v", field, v, sumDocFreq)
+		}
+		if v := terms.DocCount(); v != -1 && visitedDocs.Cardinality() != v {
+			return status, fmt.Errorf("docCount for field %v=%v != recomputed docCount=%v", field, v, visitedDocs.Cardinality())
+		}
+
+		// Test seeking back to a sample of the terms, the last one
+		// included
+		if lastTerm != nil && !bytes.Equal(seekTerms[len(seekTerms)-1], lastTerm) {
+			seekTerms = append(seekTerms, lastTerm)
+			seekDocFreqs = append(seekDocFreqs, -1)
+		}
+		for i, seekTerm := range seekTerms {
+			seekStatus, err := termsEnum.SeekCeil(seekTerm)
+			if err != nil {
+				return status, err
+			}
+			if seekStatus != SEEK_STATUS_FOUND {
+				return status, fmt.Errorf("seek to term %v failed", seekTerm)
+			}
+			if !bytes.Equal(termsEnum.Term(), seekTerm) {
+				return status, fmt.Errorf("seek to term %v returned term %v", seekTerm, termsEnum.Term())
+			}
+			docFreq, err := termsEnum.DocFreq()
+			if err != nil {
+				return status, err
+			}
+			if seekDocFreqs[i] != -1 && docFreq != seekDocFreqs[i] {
+				return status, fmt.Errorf("docFreq=%v of term %v differs from docFreq=%v after seek", seekDocFreqs[i], seekTerm, docFreq)
+			}
+		}
+	}
+
+	if doPrint {
+		ch.msg("OK [%v terms; %v terms/docs pairs; %v tokens]",
+			status.TermCount, status.TotFreq, status.TotPos)
+	}
+	return status, nil
 }
 
-func (ch *CheckIndex) testDocValues(reader AtomicReader) *DocValuesStatus {
-	panic("not implemented yet")
+/* Test stored fields. */
+func (ch *CheckIndex) testStoredFields(reader *SegmentReader) *StoredFieldStatus {
+	status := new(StoredFieldStatus)
+	ch.msg("    test: stored fields.......")
+	status.Err = func() error {
+		// Scan stored fields for all documents
+		liveDocs := reader.LiveDocs()
+		for j := 0; j < reader.MaxDoc(); j++ {
+			// Intentionally pull even deleted documents to make sure they
+			// too are not corrupt:
+			doc, err := reader.Document(j)
+			if err != nil {
+				return err
+			}
+			if liveDocs == nil || liveDocs.At(j) {
+				status.DocCount++
+				status.TotFields += int64(len(doc.Fields()))
+			}
+		}
+
+		// Validate docCount
+		if status.DocCount != reader.NumDocs() {
+			return fmt.Errorf("docCount=%v but saw %v undeleted docs",
+				status.DocCount, reader.NumDocs())
+		}
+		return nil
+	}()
+	if status.Err != nil {
+		ch.msg("ERROR [%v]", status.Err)
+	} else {
+		ch.msg("OK [%v total field count; avg %.1f fields per doc]",
+			status.TotFields, float64(status.TotFields)/float64(status.DocCount))
+	}
+	return status
 }
 
-func (ch *CheckIndex) testTermVectors(reader AtomicReader) *TermVectorStatus {
-	panic("not implemented yet")
+/* Test docvalues. */
+func (ch *CheckIndex) testDocValues(reader *SegmentReader) *DocValuesStatus {
+	status := new(DocValuesStatus)
+	ch.msg("    test: docvalues...........")
+	status.Err = func() error {
+		for _, fieldInfo := range reader.FieldInfos().Values {
+			if fieldInfo.HasDocValues() {
+				status.TotalValueFields++
+				if err := checkDocValues(fieldInfo, reader, status); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}()
+	if status.Err != nil {
+		ch.msg("ERROR [%v]", status.Err)
+	} else {
+		ch.msg("OK [%v docvalues fields; %v BINARY; %v NUMERIC; %v SORTED; %v SORTED_SET]",
+			status.TotalValueFields, status.TotalBinaryFields, status.TotalNumericFields,
+			status.TotalSortedFields, status.TotalSortedSetFields)
+	}
+	return status
+}
+
+func checkDocValues(fi *FieldInfo, reader AtomicReader, status *DocValuesStatus) error {
+	docsWithField, err := reader.DocsWithField(fi.Name)
+	if err != nil {
+		return err
+	}
+	if docsWithField == nil {
+		return fmt.Errorf("%v docsWithField does not exist", fi.Name)
+	} else if docsWithField.Length() != reader.MaxDoc() {
+		return fmt.Errorf("%v docsWithField has incorrect length: %v, expected: %v",
+			fi.Name, docsWithField.Length(), reader.MaxDoc())
+	}
+
+	var found bool
+	switch fi.DocValuesType() {
+	case DOC_VALUES_TYPE_SORTED:
+		status.TotalSortedFields++
+		dv, err := reader.SortedDocValues(fi.Name)
+		if found = dv != nil; err == nil && found {
+			err = checkSortedDocValues(fi.Name, reader.MaxDoc(), dv, docsWithField)
+		}
+		if err != nil {
+			return err
+		}
+	case DOC_VALUES_TYPE_SORTED_SET:
+		status.TotalSortedSetFields++
+		dv, err := reader.SortedSetDocValues(fi.Name)
+		if found = dv != nil; err == nil && found {
+			err = checkSortedSetDocValues(fi.Name, reader.MaxDoc(), dv, docsWithField)
+		}
+		if err != nil {
+			return err
+		}
+	case DOC_VALUES_TYPE_BINARY:
+		status.TotalBinaryFields++
+		dv, err := reader.BinaryDocValues(fi.Name)
+		if found = dv != nil; err == nil && found {
+			err = checkBinaryDocValues(fi.Name, reader.MaxDoc(), dv, docsWithField)
+		}
+		if err != nil {
+			return err
+		}
+	case DOC_VALUES_TYPE_NUMERIC:
+		status.TotalNumericFields++
+		dv, err := reader.NumericDocValues(fi.Name)
+		if found = dv != nil; err == nil && found {
+			err = checkNumericDocValues(fi.Name, reader.MaxDoc(), dv, docsWithField)
+		}
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("field: %v has unknown doc values type %v", fi.Name, fi.DocValuesType())
+	}
+	if !found {
+		return fmt.Errorf("dv for field: %v of type %v could not be read",
+			fi.Name, fi.DocValuesType())
+	}
+	return nil
+}
+
+func checkBinaryDocValues(fieldName string, maxDoc int, dv BinaryDocValues, docsWithField util.Bits) error {
+	for i := 0; i < maxDoc; i++ {
+		if term := dv.Get(i); !docsWithField.At(i) && len(term) > 0 {
+			return fmt.Errorf("dv for field: %v is missing but has value=%v for doc: %v",
+				fieldName, term, i)
+		}
+	}
+	return nil
+}
+
+func checkSortedDocValues(fieldName string, maxDoc int, dv SortedDocValues, docsWithField util.Bits) error {
+	if err := checkBinaryDocValues(fieldName, maxDoc, dv, docsWithField); err != nil {
+		return err
+	}
+	maxOrd := dv.ValueCount() - 1
+	seenOrds := util.NewFixedBitSetOf(dv.ValueCount())
+	maxOrd2 := -1
+	for i := 0; i < maxDoc; i++ {
+		ord := dv.Ord(i)
+		if ord == -1 {
+			if docsWithField.At(i) {
+				return fmt.Errorf("dv for field: %v has -1 ord but is not marked missing for doc: %v",
+					fieldName, i)
+			}
+		} else if ord < -1 || ord > maxOrd {
+			return fmt.Errorf("ord out of bounds: %v", ord)
+		} else {
+			if !docsWithField.At(i) {
+				return fmt.Errorf("dv for field: %v is missing but has ord=%v for doc: %v",
+					fieldName, ord, i)
+			}
+			if ord > maxOrd2 {
+				maxOrd2 = ord
+			}
+			seenOrds.Set(ord)
+		}
+	}
+	if maxOrd != maxOrd2 {
+		return fmt.Errorf("dv for field: %v reports wrong maxOrd=%v but this is not the case: %v",
+			fieldName, maxOrd, maxOrd2)
+	}
+	if n := seenOrds.Cardinality(); n != dv.ValueCount() {
+		return fmt.Errorf("dv for field: %v has holes in its ords, valueCount=%v but only used: %v",
+			fieldName, dv.ValueCount(), n)
+	}
+	var lastValue []byte
+	for i := 0; i <= maxOrd; i++ {
+		term := dv.LookupOrd(i)
+		if i > 0 && bytes.Compare(term, lastValue) <= 0 {
+			return fmt.Errorf("dv for field: %v has ords out of order: %v >=%v",
+				fieldName, lastValue, term)
+		}
+		lastValue = append(lastValue[:0], term...)
+	}
+	return nil
+}
+
+func checkSortedSetDocValues(fieldName string, maxDoc int, dv SortedSetDocValues, docsWithField util.Bits) error {
+	maxOrd := dv.ValueCount() - 1
+	seenOrds := util.NewFixedBitSetOf(int(dv.ValueCount()))
+	maxOrd2 := int64(-1)
+	for i := 0; i < maxDoc; i++ {
+		dv.SetDocument(i)
+		if !docsWithField.At(i) {
+			if ord := dv.NextOrd(); ord != NO_MORE_ORDS {
+				return fmt.Errorf("dv for field: %v is marked missing but has ord=%v for doc: %v",
+					fieldName, ord, i)
+			}
+			continue
+		}
+		lastOrd, ordCount := int64(-1), 0
+		for ord := dv.NextOrd(); ord != NO_MORE_ORDS; ord = dv.NextOrd() {
+			if ord <= lastOrd {
+				return fmt.Errorf("ords out of order: %v <= %v for doc: %v", ord, lastOrd, i)
+			}
+			if ord < 0 || ord > maxOrd {
+				return fmt.Errorf("ord out of bounds: %v", ord)
+			}
+			lastOrd = ord
+			if ord > maxOrd2 {
+				maxOrd2 = ord
+			}
+			seenOrds.Set(int(ord))
+			ordCount++
+		}
+		if ordCount == 0 {
+			return fmt.Errorf("dv for field: %v has no ordinals but is not marked missing for doc: %v",
+				fieldName, i)
+		}
+	}
+	if maxOrd != maxOrd2 {
+		return fmt.Errorf("dv for field: %v reports wrong maxOrd=%v but this is not the case: %v",
+			fieldName, maxOrd, maxOrd2)
+	}
+	if n := seenOrds.Cardinality(); int64(n) != dv.ValueCount() {
+		return fmt.Errorf("dv for field: %v has holes in its ords, valueCount=%v but only used: %v",
+			fieldName, dv.ValueCount(), n)
+	}
+	var lastValue []byte
+	for i := int64(0); i <= maxOrd; i++ {
+		term := dv.LookupOrd(i)
+		if i > 0 && bytes.Compare(term, lastValue) <= 0 {
+			return fmt.Errorf("dv for field: %v has ords out of order: %v >=%v",
+				fieldName, lastValue, term)
+		}
+		lastValue = append(lastValue[:0], term...)
+	}
+	return nil
+}
+
+func checkNumericDocValues(fieldName string, maxDoc int, ndv NumericDocValues, docsWithField util.Bits) error {
+	for i := 0; i < maxDoc; i++ {
+		if v := ndv(i); !docsWithField.At(i) && v != 0 {
+			return fmt.Errorf("dv for field: %v is marked missing but has value=%v for doc: %v",
+				fieldName, v, i)
+		}
+	}
+	return nil
+}
+
+/* Test term vectors. */
+func (ch *CheckIndex) testTermVectors(reader *SegmentReader) *TermVectorStatus {
+	status := new(TermVectorStatus)
+	ch.msg("    test: term vectors........")
+	status.Err = func() error {
+		fieldInfos := reader.FieldInfos()
+		liveDocs := reader.LiveDocs()
+		var postingsFields Fields
+		if ch.crossCheckTermVectors {
+			postingsFields = reader.Fields()
+		}
+		// the vectors of a single document, deleted
+		onlyDocIsDeleted := util.NewMatchNoBits(1)
+
+		for j := 0; j < reader.MaxDoc(); j++ {
+			// Intentionally pull/visit (but don't count in stats) deleted
+			// documents to make sure they too are not corrupt:
+			tfv, err := reader.TermVectors(j)
+			if err != nil {
+				return err
+			}
+			if tfv == nil {
+				continue
+			}
+
+			// First run with no deletions:
+			if _, err = ch.checkFields(tfv, nil, 1, fieldInfos, false, true); err != nil {
+				return err
+			}
+			// Again, with the one doc deleted:
+			if _, err = ch.checkFields(tfv, onlyDocIsDeleted, 1, fieldInfos, false, true); err != nil {
+				return err
+			}
+
+			// Only agg stats if the doc is live:
+			doStats := liveDocs == nil || liveDocs.At(j)
+			if doStats {
+				status.DocCount++
+			}
+			for _, fieldInfo := range fieldInfos.Values {
+				terms := tfv.Terms(fieldInfo.Name)
+				if terms == nil {
+					continue
+				}
+				if doStats {
+					status.TotVectors++
+				}
+				// Make sure FieldInfo thinks this field is vector'd:
+				if !fieldInfo.HasVectors() {
+					return fmt.Errorf("docID=%v has term vectors for field=%v but FieldInfo has storeTermVector=false",
+						j, fieldInfo.Name)
+				}
+				if postingsFields != nil {
+					if err = crossCheckTermVector(j, fieldInfo, terms, postingsFields); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}()
+	if status.Err != nil {
+		ch.msg("ERROR [%v]", status.Err)
+	} else {
+		ch.msg("OK [%v total vector count; avg %.1f term/freq vector fields per doc]",
+			status.TotVectors, float64(status.TotVectors)/float64(status.DocCount))
+	}
+	return status
+}
+
+/*
+Returns the flags to enumerate positions with, asking only for the
+offsets and payloads the field actually indexes.
+*/
+func postingsFlags(fieldInfo *FieldInfo) int {
+	flags := 0
+	if fieldInfo.IndexOptions() >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS {
+		flags |= DOCS_POSITIONS_ENUM_FLAG_OFF_SETS
+	}
+	if fieldInfo.HasPayloads() {
+		flags |= DOCS_POSITIONS_ENUM_FLAG_PAYLOADS
+	}
+	return flags
+}
+
+/*
+Checks the term vector of a field of doc docID against the postings:
+each of its terms must be indexed for the doc, with the same freq and
+positions.
+*/
+func crossCheckTermVector(docID int, fieldInfo *FieldInfo, terms Terms, postingsFields Fields) error {
+	field := fieldInfo.Name
+	postingsTerms := postingsFields.Terms(field)
+	if postingsTerms == nil {
+		return fmt.Errorf("vector field=%v does not exist in postings; doc=%v", field, docID)
+	}
+	postingsTermsEnum := postingsTerms.Iterator(nil)
+	postingsHasFreqs := fieldInfo.IndexOptions() >= INDEX_OPT_DOCS_AND_FREQS
+	postingsHasPositions := fieldInfo.IndexOptions() >= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS
+
+	termsEnum := terms.Iterator(nil)
+	for {
+		term, err := termsEnum.Next()
+		if err != nil {
+			return err
+		}
+		if term == nil {
+			return nil
+		}
+
+		ok, err := postingsTermsEnum.SeekExact(term)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("vector term=%v field=%v does not exist in postings; doc=%v", term, field, docID)
+		}
+
+		vectorPostings, err := termsEnum.DocsAndPositionsByFlags(nil, nil, postingsFlags(fieldInfo))
+		if err != nil {
+			return err
+		}
+		var vectorDocs DocsEnum = vectorPostings
+		if vectorPostings == nil {
+			if vectorDocs, err = termsEnum.DocsByFlags(nil, nil, DOCS_ENUM_FLAG_FREQS); err != nil {
+				return err
+			}
+		}
+		var postings DocsAndPositionsEnum
+		var postingsDocs DocsEnum
+		if postingsHasPositions {
+			if postings, err = postingsTermsEnum.DocsAndPositionsByFlags(nil, nil, 0); err != nil {
+				return err
+			}
+			postingsDocs = postings
+		} else if postingsDocs, err = postingsTermsEnum.DocsByFlags(nil, nil, DOCS_ENUM_FLAG_FREQS); err != nil {
+			return err
+		}
+
+		doc, err := vectorDocs.NextDoc()
+		if err != nil {
+			return err
+		}
+		if doc != 0 {
+			return fmt.Errorf("vector for doc %v didn't return docID=0: got docID=%v", docID, doc)
+		}
+		advanceDoc, err := postingsDocs.Advance(docID)
+		if err != nil {
+			return err
+		}
+		if advanceDoc != docID {
+			return fmt.Errorf("vector term=%v field=%v: doc=%v was not found in postings (got: %v)",
+				term, field, docID, advanceDoc)
+		}
+		if !postingsHasFreqs {
+			continue
+		}
+
+		tf, err := vectorDocs.Freq()
+		if err != nil {
+			return err
+		}
+		postingsFreq, err := postingsDocs.Freq()
+		if err != nil {
+			return err
+		}
+		if tf != postingsFreq {
+			return fmt.Errorf("vector term=%v field=%v doc=%v: freq=%v differs from postings freq=%v",
+				term, field, docID, tf, postingsFreq)
+		}
+		if vectorPostings == nil || postings == nil {
+			continue
+		}
+		for i := 0; i < tf; i++ {
+			pos, err := vectorPostings.NextPosition()
+			if err != nil {
+				return err
+			}
+			postingsPos, err := postings.NextPosition()
+			if err != nil {
+				return err
+			}
+			if pos != -1 && pos != postingsPos {
+				return fmt.Errorf("vector term=%v field=%v doc=%v: pos=%v differs from postings pos=%v",
+					term, field, docID, pos, postingsPos)
+			}
+		}
+	}
+}
+
+/*
+Repairs the index using previously returned result from CheckIndex().
+Note that this does not remove any of the unreferenced files after
+it's done; you must separately open an IndexWriter, which deletes
+unreferenced files when it's created.
+
+WARNING: this writes a new segments file into the index, effectively
+removing all documents in broken segments from the index. BE CAREFUL.
+
+The write lock of the index is held while the new segments file is
+written, so this fails if an IndexWriter is open on the index.
+*/
+func (ch *CheckIndex) FixIndex(result *CheckIndexStatus) error {
+	if result.Partial {
+		return errors.New("can only fix an index that was fully checked (this status checked a subset of segments)")
+	}
+	if result.newSegments == nil {
+		return errors.New("can only fix an index whose segments file could be read")
+	}
+
+	writeLock := result.Dir.MakeLock(WRITE_LOCK_NAME)
+	ok, err := writeLock.ObtainWithin(WRITE_LOCK_TIMEOUT)
+	if err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("Index locked for write: %v", writeLock)
+	}
+	defer writeLock.Close()
+
+	result.newSegments.changed()
+	if err = result.newSegments.commit(result.Dir); err != nil {
+		return err
+	}
+	ch.msg("Wrote new segments file \"%v\"; %v documents were lost",
+		result.newSegments.SegmentsFileName(), result.TotLoseDocCount)
+	return nil
 }
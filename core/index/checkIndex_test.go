@@ -0,0 +1,27 @@
+package index
+
+import (
+	. "github.com/gzg1984/golucene/core/index/model"
+	"testing"
+)
+
+func TestPostingsFlags(t *testing.T) {
+	for _, c := range []struct {
+		indexOptions  IndexOptions
+		storePayloads bool
+		expected      int
+	}{
+		{INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS, false, 0},
+		{INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS, true, DOCS_POSITIONS_ENUM_FLAG_PAYLOADS},
+		{INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS, false, DOCS_POSITIONS_ENUM_FLAG_OFF_SETS},
+		{INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS, true,
+			DOCS_POSITIONS_ENUM_FLAG_OFF_SETS | DOCS_POSITIONS_ENUM_FLAG_PAYLOADS},
+	} {
+		fi := NewFieldInfo("f", true, 0, false, false, c.storePayloads,
+			c.indexOptions, 0, 0, -1, nil)
+		if flags := postingsFlags(fi); flags != c.expected {
+			t.Errorf("%v, payloads=%v: expected flags %v, got %v",
+				c.indexOptions, c.storePayloads, c.expected, flags)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"container/list"
 	"fmt"
 	. "github.com/gzg1984/golucene/core/codec/spi"
+	. "github.com/gzg1984/golucene/core/index/model"
 	"reflect"
 )
 
@@ -58,7 +59,13 @@ func (r *CompositeReaderImpl) Context() IndexReaderContext {
 	if r.readerContext == nil {
 		// log.Print("Obtaining context for: ", r)
 		// assert getSequentialSubReaders() != null;
-		r.readerContext = newCompositeReaderContext(r)
+		// the context should reference the outermost reader, e.g.
+		// StandardDirectoryReader, not this embedded one
+		if self, ok := r.IndexReaderImplSPI.(CompositeReader); ok {
+			r.readerContext = newCompositeReaderContext(self)
+		} else {
+			r.readerContext = newCompositeReaderContext(r)
+		}
 	}
 	return r.readerContext
 }
@@ -126,15 +133,15 @@ type CompositeReaderContextBuilder struct {
 	leafDocBase int
 }
 
-func newCompositeReaderContextBuilder(r CompositeReader) CompositeReaderContextBuilder {
-	return CompositeReaderContextBuilder{reader: r, leaves: list.New()}
+func newCompositeReaderContextBuilder(r CompositeReader) *CompositeReaderContextBuilder {
+	return &CompositeReaderContextBuilder{reader: r, leaves: list.New()}
 }
 
-func (b CompositeReaderContextBuilder) build() *CompositeReaderContext {
+func (b *CompositeReaderContextBuilder) build() *CompositeReaderContext {
 	return b.build4(nil, b.reader, 0, 0).(*CompositeReaderContext)
 }
 
-func (b CompositeReaderContextBuilder) build4(parent *CompositeReaderContext,
+func (b *CompositeReaderContextBuilder) build4(parent *CompositeReaderContext,
 	reader IndexReader, ord, docBase int) IndexReaderContext {
 	// log.Printf("Building context from %v(parent: %v, %v-%v)", reader, parent, ord, docBase)
 	if ar, ok := reader.(AtomicReader); ok {
@@ -158,7 +165,7 @@ func (b CompositeReaderContextBuilder) build4(parent *CompositeReaderContext,
 	newDocBase := 0
 	for i, r := range sequentialSubReaders {
 		children[i] = b.build4(newParent, r, i, newDocBase)
-		newDocBase = r.MaxDoc()
+		newDocBase += r.MaxDoc()
 	}
 	// assert newDocBase == cr.maxDoc()
 	return newParent
@@ -219,11 +226,10 @@ func newBaseCompositeReader(spi BaseCompositeReaderSPI, readers []IndexReader) *
 	return ans
 }
 
-func (r *BaseCompositeReader) TermVectors(docID int) error {
-	panic("not implemented yet")
-	// r.ensureOpen()
-	// i := readerIndex(docID)
-	// return r.subReaders[i].TermVectors(docID - starts[i])
+func (r *BaseCompositeReader) TermVectors(docID int) (Fields, error) {
+	r.ensureOpen()
+	i := r.readerIndex(docID) // find subreader num
+	return r.subReaders[i].TermVectors(docID - r.starts[i])
 }
 
 func (r *BaseCompositeReader) NumDocs() int {
@@ -1,6 +1,7 @@
 package index
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gzg1984/golucene/core/analysis"
 	. "github.com/gzg1984/golucene/core/codec/spi"
@@ -110,11 +111,13 @@ func (c *DefaultIndexingChain) writeDocValues(state *SegmentWriteState) (err err
 	docCount := state.SegmentInfo.DocCount()
 	var dvConsumer DocValuesConsumer
 	var success = false
-	if success {
-		err = util.Close(dvConsumer)
-	} else {
-		util.CloseWhileSuppressingError(dvConsumer)
-	}
+	defer func() {
+		if success {
+			err = util.Close(dvConsumer)
+		} else {
+			util.CloseWhileSuppressingError(dvConsumer)
+		}
+	}()
 
 	for _, perField := range c.fieldHash {
 		for perField != nil {
@@ -355,13 +358,13 @@ func (c *DefaultIndexingChain) processField(field IndexableField,
 			fp.fieldGen = fieldGen
 		}
 	} else {
-		panic("not implemented yet")
+		verifyFieldType(fieldName, fieldType)
 	}
 
 	// Add stored fields:
 	if fieldType.Stored() {
 		if fp == nil {
-			panic("not implemented yet")
+			fp = c.getOrAddField(fieldName, fieldType, false)
 		}
 		if fieldType.Stored() {
 			if err := func() error {
@@ -385,14 +388,96 @@ func (c *DefaultIndexingChain) processField(field IndexableField,
 
 	if dvType := fieldType.DocValueType(); int(dvType) != 0 {
 		if fp == nil {
-			panic("not implemented yet")
+			fp = c.getOrAddField(fieldName, fieldType, false)
+		}
+		if err := c.indexDocValue(fp, dvType, field); err != nil {
+			return 0, err
 		}
-		panic("not implemented yet")
 	}
 
 	return fieldCount, nil
 }
 
+func verifyFieldType(name string, ft IndexableFieldType) {
+	assert2(ft.IndexOptions() != 0, "IndexOptions must not be 0 (field: '%v')", name)
+	if !ft.Indexed() {
+		assert2(!ft.StoreTermVectors(),
+			"cannot store term vectors for a field that is not indexed (field='%v')", name)
+		assert2(!ft.StoreTermVectorPositions(),
+			"cannot store term vector positions for a field that is not indexed (field='%v')", name)
+		assert2(!ft.StoreTermVectorOffsets(),
+			"cannot store term vector offsets for a field that is not indexed (field='%v')", name)
+		assert2(!ft.StoreTermVectorPayloads(),
+			"cannot store term vector payloads for a field that is not indexed (field='%v')", name)
+	}
+}
+
+/* Called from processDocument to index one field's doc values */
+func (c *DefaultIndexingChain) indexDocValue(fp *PerField,
+	dvType DocValuesType, field IndexableField) error {
+
+	// check the value before the field info records the type
+	var numericValue int64
+	if dvType == DOC_VALUES_TYPE_NUMERIC {
+		var err error
+		if numericValue, err = numericDocValue(field); err != nil {
+			return err
+		}
+	}
+
+	hasDocValues := fp.fieldInfo.HasDocValues()
+
+	// This will panic if the caller tried to change the DV type for
+	// the field:
+	fp.fieldInfo.SetDocValueType(dvType)
+	if !hasDocValues {
+		// First time we see doc values in this segment
+		c.fieldInfos.GlobalFieldNumbers().SetDocValuesType(
+			int(fp.fieldInfo.Number), fp.fieldInfo.Name, dvType)
+	}
+
+	docId := c.docState.docID
+
+	switch dvType {
+	case DOC_VALUES_TYPE_NUMERIC:
+		if fp.docValuesWriter == nil {
+			fp.docValuesWriter = newNumericDocValuesWriter(fp.fieldInfo, c.bytesUsed, true)
+		}
+		fp.docValuesWriter.(*NumericDocValuesWriter).addValue(docId, numericValue)
+	case DOC_VALUES_TYPE_BINARY:
+		if fp.docValuesWriter == nil {
+			fp.docValuesWriter = newBinaryDocValuesWriter(fp.fieldInfo, c.bytesUsed)
+		}
+		fp.docValuesWriter.(*BinaryDocValuesWriter).addValue(docId, field.BinaryValue())
+	case DOC_VALUES_TYPE_SORTED:
+		if fp.docValuesWriter == nil {
+			fp.docValuesWriter = newSortedDocValuesWriter(fp.fieldInfo, c.bytesUsed)
+		}
+		fp.docValuesWriter.(*SortedDocValuesWriter).addValue(docId, field.BinaryValue())
+	case DOC_VALUES_TYPE_SORTED_SET:
+		if fp.docValuesWriter == nil {
+			fp.docValuesWriter = newSortedSetDocValuesWriter(fp.fieldInfo, c.bytesUsed)
+		}
+		fp.docValuesWriter.(*SortedSetDocValuesWriter).addValue(docId, field.BinaryValue())
+	default:
+		panic(fmt.Sprintf("unrecognized DocValues.Type: %v", dvType))
+	}
+	return nil
+}
+
+func numericDocValue(field IndexableField) (int64, error) {
+	switch v := field.NumericValue().(type) {
+	case int64:
+		return v, nil
+	case int32:
+		return int64(v), nil
+	default:
+		return 0, errors.New(fmt.Sprintf(
+			"field '%v': numeric doc values require an integer value, got %v",
+			field.Name(), field.NumericValue()))
+	}
+}
+
 /*
 Returns a previously created PerField, or nil if this field name
 wasn't seen yet.
@@ -2,6 +2,7 @@ package index
 
 import (
 	"fmt"
+	. "github.com/gzg1984/golucene/core/index/model"
 	"sync"
 	"sync/atomic"
 )
@@ -48,6 +49,7 @@ neither to the global deletes.
 */
 type DocumentsWriterDeleteQueue struct {
 	tail                  *Node // volatile
+	tailLock              sync.Locker
 	globalSlice           *DeleteSlice
 	globalBufferedUpdates *BufferedUpdates
 	globalBufferLock      sync.Locker
@@ -69,6 +71,7 @@ func newDocumentsWriterDeleteQueueWith(globalBufferedUpdates *BufferedUpdates, g
 		globalBufferedUpdates: globalBufferedUpdates,
 		globalBufferLock:      &sync.Mutex{},
 		generation:            generation,
+		tailLock:              &sync.Mutex{},
 		// we use a sentinel instance as our initial tail. No slice will
 		// ever try to apply this tail since the head is always omitted.
 		tail:        tail, // sentinel
@@ -78,10 +81,69 @@ func newDocumentsWriterDeleteQueueWith(globalBufferedUpdates *BufferedUpdates, g
 
 /* Invariant for document update */
 func (q *DocumentsWriterDeleteQueue) add(term *Term, slice *DeleteSlice) {
-	panic("not implemented yet")
+	termNode := newNode(term)
+	q.addNode(termNode)
+	// this is an update request where the term is the updated documents
+	// delTerm. in that case we need to guarantee that this insert is
+	// atomic with regards to the given delete slice. This means if two
+	// threads try to update the same document with in turn the same
+	// delTerm one of them must win. By taking the node we have created
+	// for our del term as the new tail it is guaranteed that if another
+	// thread adds the same right after us we will apply this delete
+	// next time we update our slice and one of the two competing
+	// updates wins!
+	slice.tail = termNode
+	assert2(slice.head != slice.tail, "slice head and tail must differ after add")
+	q.tryApplyGlobalSlice() // TODO doing this each time is not necessary maybe
+	// we can do it just every n times or so?
 }
 
-func (dq *DocumentsWriterDeleteQueue) freezeGlobalBuffer(callerSlice *DeleteSlice) *FrozenBufferedUpdates {
+func (dq *DocumentsWriterDeleteQueue) addDeleteQueries(queries ...Query) {
+	dq.addNode(newNode(queries))
+	dq.tryApplyGlobalSlice()
+}
+
+func (dq *DocumentsWriterDeleteQueue) addDeleteTerms(terms ...*Term) {
+	dq.addNode(newNode(terms))
+	dq.tryApplyGlobalSlice()
+}
+
+func (dq *DocumentsWriterDeleteQueue) addNumericUpdate(update *DocValuesUpdate) {
+	dq.addNode(newNode(update))
+	dq.tryApplyGlobalSlice()
+}
+
+func (dq *DocumentsWriterDeleteQueue) addBinaryUpdate(update *DocValuesUpdate) {
+	dq.addNode(newNode(update))
+	dq.tryApplyGlobalSlice()
+}
+
+/*
+Appends the node to the tail of the queue. Java uses a lock-free
+CAS loop here; appending is cheap enough to simply serialize it.
+*/
+func (dq *DocumentsWriterDeleteQueue) addNode(item *Node) {
+	dq.tailLock.Lock()
+	defer dq.tailLock.Unlock()
+	dq.tail.next = item
+	dq.tail = item
+}
+
+func (dq *DocumentsWriterDeleteQueue) tryApplyGlobalSlice() {
+	// Java only tries to lock here; since Go doesn't encourage the
+	// tryLock idea, we always wait for the global buffer.
+	dq.globalBufferLock.Lock()
+	defer dq.globalBufferLock.Unlock()
+	// The global buffer must be locked but we don't need to update
+	// them if there is an update going on right now. It is sufficient
+	// to apply the deletes that have been added after the current in
+	// process global buffer apply.
+	if dq.updateSlice(dq.globalSlice) {
+		dq.globalSlice.apply(dq.globalBufferedUpdates, MAX_INT)
+	}
+}
+
+func (dq *DocumentsWriterDeleteQueue) freezeGlobalBuffer(callerSlice *DeleteSlice) (*FrozenBufferedUpdates, error) {
 	dq.globalBufferLock.Lock()
 	defer dq.globalBufferLock.Unlock()
 
@@ -100,9 +162,12 @@ func (dq *DocumentsWriterDeleteQueue) freezeGlobalBuffer(callerSlice *DeleteSlic
 		dq.globalSlice.apply(dq.globalBufferedUpdates, MAX_INT)
 	}
 
-	packet := freezeBufferedUpdates(dq.globalBufferedUpdates, false)
+	packet, err := freezeBufferedUpdates(dq.globalBufferedUpdates, false)
+	if err != nil {
+		return nil, err
+	}
 	dq.globalBufferedUpdates.clear()
-	return packet
+	return packet, nil
 }
 
 func (dq *DocumentsWriterDeleteQueue) anyChanges() bool {
@@ -138,6 +203,10 @@ func (dq *DocumentsWriterDeleteQueue) clear() {
 	dq.globalBufferedUpdates.clear()
 }
 
+func (dq *DocumentsWriterDeleteQueue) numGlobalTermDeletes() int {
+	return int(atomic.LoadInt32(&dq.globalBufferedUpdates.numTermDeletes))
+}
+
 func (q *DocumentsWriterDeleteQueue) RamBytesUsed() int64 {
 	return atomic.LoadInt64(&q.globalBufferedUpdates.bytesUsed)
 }
@@ -203,6 +272,30 @@ func newNode(item interface{}) *Node {
 	return &Node{item: item}
 }
 
-func (node *Node) apply(BufferedUpdates *BufferedUpdates, docIDUpto int) {
-	panic("sentinel item must never be applied")
+func (node *Node) apply(bufferedUpdates *BufferedUpdates, docIDUpto int) {
+	switch item := node.item.(type) {
+	case *Term:
+		bufferedUpdates.addTerm(item, docIDUpto)
+	case []*Term:
+		for _, term := range item {
+			bufferedUpdates.addTerm(term, docIDUpto)
+		}
+	case []Query:
+		for _, query := range item {
+			bufferedUpdates.addQuery(query, docIDUpto)
+		}
+	case *DocValuesUpdate:
+		// the same node is applied to the global buffer as well as to
+		// each DWPT's private one, so each of them gets its own copy
+		switch item.typ {
+		case DOC_VALUES_TYPE_NUMERIC:
+			bufferedUpdates.addNumericUpdate(item.clone(), docIDUpto)
+		case DOC_VALUES_TYPE_BINARY:
+			bufferedUpdates.addBinaryUpdate(item.clone(), docIDUpto)
+		default:
+			panic(fmt.Sprintf("unsupported update type: %v", item.typ))
+		}
+	default:
+		panic("sentinel item must never be applied")
+	}
 }
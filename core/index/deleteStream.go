@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	. "github.com/gzg1984/golucene/core/codec/spi"
+	. "github.com/gzg1984/golucene/core/index/model"
+	search "github.com/gzg1984/golucene/core/search/model"
 	"github.com/gzg1984/golucene/core/store"
 	"github.com/gzg1984/golucene/core/util"
 	"log"
@@ -35,13 +37,28 @@ func (a SegInfoByDelGen) Less(i, j int) bool { return a[i].BufferedUpdatesGen <
 
 type Query interface{}
 
+/*
+Used by search package to resolve the documents of a segment matched
+by a deleted Query. The returned iterator may be nil if no document
+matches.
+*/
+var QueryDocIdSetIterator func(query Query, ctx *AtomicReaderContext,
+	acceptDocs util.Bits) (search.DocIdSetIterator, error)
+
+// Returned when deleting by query while QueryDocIdSetIterator is unset.
+var ErrNoQueryResolver = errors.New("delete by query requires the search package to be linked in (import github.com/gzg1984/golucene/core/search)")
+
 type QueryAndLimit struct {
+	query Query
+	limit int
 }
 
 // index/CoalescedUpdates.java
 
 type CoalescedUpdates struct {
 	_queries         map[Query]int
+	iterables        []*PrefixCodedTerms
+	numTerms         int
 	numericDVUpdates []*DocValuesUpdate
 	binaryDVUpdates  []*DocValuesUpdate
 }
@@ -53,19 +70,78 @@ func newCoalescedUpdates() *CoalescedUpdates {
 }
 
 func (cd *CoalescedUpdates) String() string {
-	panic("not implemented yet")
+	// note: we could add/collect more debugging information
+	return fmt.Sprintf("CoalescedUpdates(termSets=%v,queries=%v,numericDVUpdates=%v,binaryDVUpdates=%v)",
+		len(cd.iterables), len(cd._queries), len(cd.numericDVUpdates), len(cd.binaryDVUpdates))
 }
 
+/*
+Coalesces the given packet. Packets are coalesced from the newest to
+the oldest one, so the packet's DV updates are put before the ones
+already coalesced: updates must be applied in the order they were
+buffered, so that the latest update of a document wins.
+*/
 func (cd *CoalescedUpdates) update(in *FrozenBufferedUpdates) {
-	panic("not implemented yet")
+	cd.iterables = append(cd.iterables, in.terms)
+	cd.numTerms += in.termCount
+
+	for _, query := range in._queries {
+		cd._queries[query] = MAX_INT
+	}
+
+	cd.numericDVUpdates = append(coalesceDVUpdates(in.numericDVUpdates), cd.numericDVUpdates...)
+	cd.binaryDVUpdates = append(coalesceDVUpdates(in.binaryDVUpdates), cd.binaryDVUpdates...)
 }
 
-func (cd *CoalescedUpdates) terms() []*Term {
-	panic("not implemented yet")
+/* Clones the updates, as they apply to all docs of older segments. */
+func coalesceDVUpdates(updates []*DocValuesUpdate) []*DocValuesUpdate {
+	ans := make([]*DocValuesUpdate, len(updates))
+	for i, update := range updates {
+		ans[i] = update.clone()
+		ans[i].docIDUpto = MAX_INT
+	}
+	return ans
+}
+
+/* Returns the coalesced terms, sorted and without duplicates. */
+func (cd *CoalescedUpdates) terms() ([]*Term, error) {
+	if cd.numTerms == 0 {
+		return nil, nil
+	}
+	var terms []*Term
+	for _, iterable := range cd.iterables {
+		next, err := iterable.iterator()
+		if err != nil {
+			return nil, err
+		}
+		for {
+			term, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if term == nil {
+				break
+			}
+			terms = append(terms, term)
+		}
+	}
+	util.TimSort(TermSorter(terms))
+	// dedup: the same term may be deleted by more than one packet
+	ans := terms[:0]
+	for i, term := range terms {
+		if i == 0 || keyOf(term) != keyOf(terms[i-1]) {
+			ans = append(ans, term)
+		}
+	}
+	return ans, nil
 }
 
 func (cd *CoalescedUpdates) queries() []*QueryAndLimit {
-	panic("not implemented yet")
+	ans := make([]*QueryAndLimit, 0, len(cd._queries))
+	for query, limit := range cd._queries {
+		ans = append(ans, &QueryAndLimit{query, limit})
+	}
+	return ans
 }
 
 /*
@@ -109,7 +185,30 @@ func newBufferedUpdatesStream(infoStream util.InfoStream) *BufferedUpdatesStream
 
 /* Appends a new packet of buffered deletes to the stream, setting its generation: */
 func (s *BufferedUpdatesStream) push(packet *FrozenBufferedUpdates) int64 {
-	panic("not implemented yet")
+	s.Lock()
+	defer s.Unlock()
+	// The insert operation must be atomic. If we let threads increment
+	// the gen and push the packet afterwards we risk that packets are
+	// out of order. With DWPT this is possible if two or more flushes
+	// are racing for pushing updates. If the pushed packets get out of
+	// order would loose documents since deletes are applied to the
+	// wrong segments.
+	packet.gen = s.nextGen
+	s.nextGen++
+	assert(packet.any())
+	s.assertDeleteStats()
+	assert(packet.gen < s.nextGen)
+	assert2(len(s.updates) == 0 || s.updates[len(s.updates)-1].gen < packet.gen,
+		"Delete packets must be in order")
+	s.updates = append(s.updates, packet)
+	atomic.AddInt32(&s.numTerms, int32(packet.numTermDeletes))
+	atomic.AddInt64(&s.bytesUsed, int64(packet.bytesUsed))
+	if s.infoStream.IsEnabled("BD") {
+		s.infoStream.Message("BD", "push deletes %v delGen=%v packetCount=%v totBytesUsed=%v",
+			packet, packet.gen, len(s.updates), atomic.LoadInt64(&s.bytesUsed))
+	}
+	s.assertDeleteStats()
+	return packet.gen
 }
 
 func (ds *BufferedUpdatesStream) clear() {
@@ -221,7 +320,11 @@ func (ds *BufferedUpdatesStream) applyDeletesAndUpdates(readerPool *ReaderPool,
 				if coalescedUpdates != nil {
 					fmt.Println("    del coalesced")
 					var delta int64
-					delta, err = ds._applyTermDeletes(coalescedUpdates.terms(), rld, reader)
+					var terms []*Term
+					if terms, err = coalescedUpdates.terms(); err != nil {
+						return
+					}
+					delta, err = ds._applyTermDeletes(terms, rld, reader)
 					if err == nil {
 						delCount += delta
 						delta, err = applyQueryDeletes(coalescedUpdates.queries(), rld, reader)
@@ -305,7 +408,11 @@ func (ds *BufferedUpdatesStream) applyDeletesAndUpdates(readerPool *ReaderPool,
 						err = mergeError(err, readerPool.release(rld))
 					}()
 					var delta int64
-					delta, err = ds._applyTermDeletes(coalescedUpdates.terms(), rld, reader)
+					var terms []*Term
+					if terms, err = coalescedUpdates.terms(); err != nil {
+						return
+					}
+					delta, err = ds._applyTermDeletes(terms, rld, reader)
 					if err == nil {
 						delCount += delta
 						delta, err = applyQueryDeletes(coalescedUpdates.queries(), rld, reader)
@@ -427,20 +534,191 @@ func (ds *BufferedUpdatesStream) pruneUpdates(count int) {
 /* Delete by term */
 func (ds *BufferedUpdatesStream) _applyTermDeletes(terms []*Term,
 	rld *ReadersAndUpdates, reader *SegmentReader) (int64, error) {
-	panic("not implemented yet")
+	fields := reader.Fields()
+	if fields == nil {
+		// This reader has no postings
+		return 0, nil
+	}
+
+	var delCount int64
+	var termsEnum TermsEnum
+	var currentField string
+	var docs DocsEnum
+	var any = false
+
+	for i, term := range terms {
+		// Since we visit terms sorted, we gain performance by re-using
+		// the same TermsEnum and seeking only forwards
+		if i == 0 || term.Field != currentField {
+			assert(i == 0 || currentField < term.Field)
+			currentField = term.Field
+			if terms := fields.Terms(currentField); terms != nil {
+				termsEnum = terms.Iterator(termsEnum)
+			} else {
+				termsEnum = nil
+			}
+		}
+
+		if termsEnum == nil {
+			continue
+		}
+
+		ok, err := termsEnum.SeekExact(term.Bytes)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			continue
+		}
+		// we don't need term frequencies for this
+		if docs, err = termsEnum.DocsByFlags(rld.liveDocs(), docs, DOCS_ENUM_FLAG_NONE); err != nil {
+			return 0, err
+		}
+		if docs == nil {
+			continue
+		}
+		for {
+			docID, err := docs.NextDoc()
+			if err != nil {
+				return 0, err
+			}
+			if docID == search.NO_MORE_DOCS {
+				break
+			}
+			if !any {
+				rld.initWritableLiveDocs()
+				any = true
+			}
+			// NOTE: there is no limit check on the docID when deleting
+			// by Term (unlike by Query) because on flush we apply all
+			// Term deletes to each segment. So all Term deleting here is
+			// against prior segments:
+			if rld.delete(docID) {
+				delCount++
+			}
+		}
+	}
+	return delCount, nil
 }
 
 /* DocValues updates */
 func (ds *BufferedUpdatesStream) applyDocValuesUpdates(updates []*DocValuesUpdate,
 	rld *ReadersAndUpdates, reader *SegmentReader,
-	dvUpdatesCntainer *DocValuesFieldUpdatesContainer) error {
-	panic("not implemented yet")
+	dvUpdatesContainer *DocValuesFieldUpdatesContainer) error {
+
+	fields := reader.Fields()
+	if fields == nil {
+		// This reader has no postings
+		return nil
+	}
+
+	// TODO: we can process the updates per DV field, from last to first
+	// so that if multiple terms affect same document for the same field,
+	// we add an update only once (that of the last term). To do that, we
+	// can keep a bitset which marks which documents have already been
+	// updated. So e.g. if term T1 updates doc 7, and then we process
+	// term T2 and it updates doc 7 as well, we don't apply the update
+	// since we know T1 came last and therefore wins the update. We can
+	// also use that bitset as 'liveDocs' to pass to TermEnum.docs(), so
+	// that these documents aren't even returned.
+
+	var currentField string
+	var termsEnum TermsEnum
+	var docs DocsEnum
+
+	for _, update := range updates {
+		term := update.term
+		limit := update.docIDUpto
+
+		// TODO: we traverse the terms in update order (not term order)
+		// so that we apply the updates in the correct order, i.e. if two
+		// terms update the same document, the last one that came in
+		// wins, irrespective of the terms lexical order. We can apply
+		// the updates in terms order if we keep an updatesGen (and
+		// increment it with every update) and attach it to each update.
+		// Note that we cannot rely only on docIDUpto because an app may
+		// send two updates which will get same docIDUpto, yet will still
+		// need to respect the order those updates arrived.
+
+		if termsEnum == nil || term.Field != currentField {
+			currentField = term.Field
+			terms := fields.Terms(currentField)
+			if terms == nil {
+				termsEnum = nil
+				continue // no terms in that field
+			}
+			termsEnum = terms.Iterator(termsEnum)
+		}
+
+		ok, err := termsEnum.SeekExact(term.Bytes)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		// we don't need term frequencies for this
+		if docs, err = termsEnum.DocsByFlags(rld.liveDocs(), docs, DOCS_ENUM_FLAG_NONE); err != nil {
+			return err
+		}
+
+		dvUpdates := dvUpdatesContainer.updates(update.field, update.typ)
+		if dvUpdates == nil {
+			dvUpdates = dvUpdatesContainer.newUpdates(update.field, update.typ, reader.MaxDoc())
+		}
+		for {
+			doc, err := docs.NextDoc()
+			if err != nil {
+				return err
+			}
+			if doc == search.NO_MORE_DOCS || doc >= limit {
+				break // no more docs that can be updated for this term
+			}
+			dvUpdates.add(doc, update.value)
+		}
+	}
+	return nil
 }
 
 /* Delete by query */
 func applyQueryDeletes(queries []*QueryAndLimit,
 	rld *ReadersAndUpdates, reader *SegmentReader) (int64, error) {
-	panic("not implemented yet")
+	if len(queries) == 0 {
+		return 0, nil
+	}
+	if QueryDocIdSetIterator == nil {
+		return 0, ErrNoQueryResolver
+	}
+
+	var delCount int64
+	readerContext := reader.Context().(*AtomicReaderContext)
+	var any = false
+	for _, ent := range queries {
+		it, err := QueryDocIdSetIterator(ent.query, readerContext, rld.liveDocs())
+		if err != nil {
+			return 0, err
+		}
+		if it == nil {
+			continue
+		}
+		for {
+			doc, err := it.NextDoc()
+			if err != nil {
+				return 0, err
+			}
+			if doc >= ent.limit {
+				break
+			}
+			if !any {
+				rld.initWritableLiveDocs()
+				any = true
+			}
+			if rld.delete(doc) {
+				delCount++
+			}
+		}
+	}
+	return delCount, nil
 }
 
 func (ds *BufferedUpdatesStream) assertDeleteStats() {
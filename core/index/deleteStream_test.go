@@ -0,0 +1,46 @@
+package index
+
+import (
+	"testing"
+)
+
+func TestApplyQueryDeletesWithoutResolver(t *testing.T) {
+	if QueryDocIdSetIterator != nil {
+		t.Skip("the search package is linked in")
+	}
+	queries := []*QueryAndLimit{{"query", 1}}
+	if _, err := applyQueryDeletes(queries, nil, nil); err != ErrNoQueryResolver {
+		t.Errorf("expected ErrNoQueryResolver, got %v", err)
+	}
+}
+
+func TestCoalescedUpdatesTerms(t *testing.T) {
+	builder := newPrefixCodedTermsBuilder()
+	expected := []*Term{
+		NewTermFromBytes("body", []byte("apple")),
+		NewTermFromBytes("body", []byte("applet")),
+		NewTermFromBytes("id", []byte("7")),
+	}
+	for _, term := range expected {
+		if err := builder.add(term); err != nil {
+			t.Fatal(err)
+		}
+	}
+	terms, err := builder.finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cd := &CoalescedUpdates{iterables: []*PrefixCodedTerms{terms}, numTerms: len(expected)}
+	got, err := cd.terms()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v terms, got %v", len(expected), len(got))
+	}
+	for i, term := range got {
+		if term.Field != expected[i].Field || string(term.Bytes) != string(expected[i].Bytes) {
+			t.Errorf("term %v: expected %v, got %v", i, expected[i], term)
+		}
+	}
+}
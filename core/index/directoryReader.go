@@ -14,9 +14,8 @@ const DEFAULT_TERMS_INDEX_DIVISOR = 1
 
 type DirectoryReader interface {
 	IndexReader
-	// doOpenIfChanged() error
-	// doOpenIfChanged(c IndexCommit) error
-	// doOpenIfChanged(w IndexWriter, c IndexCommit) error
+	doOpenIfChanged(c IndexCommit) (DirectoryReader, error)
+	doOpenIfChangedFromWriter(w *IndexWriter, applyAllDeletes bool) (DirectoryReader, error)
 	Version() int64
 	IsCurrent() bool
 }
@@ -42,6 +41,76 @@ func OpenDirectoryReader(directory store.Directory) (r DirectoryReader, err erro
 	return openStandardDirectoryReader(directory, nil, DEFAULT_TERMS_INDEX_DIVISOR)
 }
 
+/*
+Open a near real time DirectoryReader from the IndexWriter.
+
+The reader sees all changes made by the writer so far, committed or
+not. If applyAllDeletes is true, all buffered deletes are applied
+(made visible) in the returned reader; if false, the deletes are not
+applied but remain buffered (in IndexWriter) so that they will be
+applied in the future. Applying deletes can be costly, so if your app
+can tolerate deleted documents being returned you might gain some
+performance by passing false.
+*/
+func OpenDirectoryReaderFromWriter(w *IndexWriter, applyAllDeletes bool) (DirectoryReader, error) {
+	return w.reader(applyAllDeletes)
+}
+
+/*
+If the index has changed since the provided reader was opened, open
+and return a new reader; else, return nil. The new reader, if not
+nil, will be the same type of reader as the previous one, ie a near
+real time reader will open a new near real time reader.
+
+This method is typically far less costly than opening a fully new
+DirectoryReader as it shares resources (for example sub-readers) with
+the provided DirectoryReader, when possible.
+
+The provided reader is not closed (you are responsible for doing so);
+if a new reader is returned you also must eventually close it. Be
+sure to never close a reader while other goroutines are still using
+it.
+*/
+func OpenDirectoryReaderIfChanged(oldReader DirectoryReader) (DirectoryReader, error) {
+	return oldReader.doOpenIfChanged(nil)
+}
+
+/*
+If the IndexCommit differs from what the provided reader is
+searching, open and return a new reader; else, return nil.
+*/
+func OpenDirectoryReaderIfChangedAt(oldReader DirectoryReader, commit IndexCommit) (DirectoryReader, error) {
+	return oldReader.doOpenIfChanged(commit)
+}
+
+/*
+Expert: If there changes (committed or not) in the IndexWriter
+versus what the provided reader is searching, then open and return a
+new IndexReader searching both committed and uncommitted changes from
+the writer; else, return nil (though, the current implementation
+never returns nil).
+
+This provides "near real-time" searching, in that changes made
+during an IndexWriter session can be quickly made available for
+searching without closing the writer nor calling Commit().
+
+It's near real-time because there is no hard guarantee on how
+quickly you can get a new reader after making changes with
+IndexWriter. You'll have to experiment in your situation to determine
+if it's fast enough. As this is a new and experimental feature,
+please report back on your findings so we can learn, improve and
+iterate.
+
+The very first time this method is called, this writer instance will
+make every effort to pool the readers that it opens for doing merges,
+applying deletes, etc. This means additional resources (RAM, file
+descriptors, CPU time) will be consumed.
+*/
+func OpenDirectoryReaderIfChangedFromWriter(oldReader DirectoryReader,
+	w *IndexWriter, applyAllDeletes bool) (DirectoryReader, error) {
+	return oldReader.doOpenIfChangedFromWriter(w, applyAllDeletes)
+}
+
 /*
 Returns true if an index likely exists at the specified directory. Note that
 if a corrupt index exists, or if an index in the process of committing
@@ -88,15 +157,22 @@ func IsIndexFileExists(files []string) bool {
 
 type StandardDirectoryReader struct {
 	*DirectoryReaderImpl
-	writer       *IndexWriter // NRT
-	segmentInfos *SegmentInfos
+	writer                *IndexWriter // NRT
+	segmentInfos          *SegmentInfos
+	termInfosIndexDivisor int
+	applyAllDeletes       bool
 }
 
-// TODO support IndexWriter
 func newStandardDirectoryReader(directory store.Directory, readers []AtomicReader,
-	sis *SegmentInfos, termInfosIndexDivisor int, applyAllDeletes bool) *StandardDirectoryReader {
+	writer *IndexWriter, sis *SegmentInfos, termInfosIndexDivisor int,
+	applyAllDeletes bool) *StandardDirectoryReader {
 	// log.Printf("Initializing StandardDirectoryReader with %v sub readers...", len(readers))
-	ans := &StandardDirectoryReader{segmentInfos: sis}
+	ans := &StandardDirectoryReader{
+		writer:                writer,
+		segmentInfos:          sis,
+		termInfosIndexDivisor: termInfosIndexDivisor,
+		applyAllDeletes:       applyAllDeletes,
+	}
 	ans.DirectoryReaderImpl = newDirectoryReader(ans, directory, readers)
 	return ans
 }
@@ -125,7 +201,7 @@ func openStandardDirectoryReader(directory store.Directory,
 			readers[i] = sr
 		}
 		// log.Printf("Obtained %v SegmentReaders.", len(readers))
-		return newStandardDirectoryReader(directory, readers, sis, termInfosIndexDivisor, false), nil
+		return newStandardDirectoryReader(directory, readers, nil, sis, termInfosIndexDivisor, false), nil
 	}).run(commit)
 	if err != nil {
 		return nil, err
@@ -133,6 +209,122 @@ func openStandardDirectoryReader(directory store.Directory,
 	return obj.(*StandardDirectoryReader), err
 }
 
+/* Used by near real-time search */
+func openStandardDirectoryReaderFromWriter(writer *IndexWriter,
+	infos *SegmentInfos, applyAllDeletes bool) (r DirectoryReader, err error) {
+
+	// IndexWriter synchronizes externally before calling us, which
+	// ensures infos will not change; so there's no need to process
+	// segments in reverse order
+	dir := writer.Directory()
+	segmentInfos := infos.Clone()
+	var readers []AtomicReader
+	var success = false
+	defer func() {
+		if !success {
+			for _, reader := range readers {
+				reader.decRef() // keep the original error
+			}
+		}
+	}()
+
+	infosUpto := 0
+	for _, info := range infos.Segments {
+		// NOTE: important that we use infos not segmentInfos here, so
+		// that we are passing the actual instance of SegmentCommitInfo
+		// in IndexWriter's segmentInfos:
+		assert(info.Info.Dir == dir)
+		rld := writer.readerPool.get(info, true)
+		reader, err := rld.readOnlyClone(store.IO_CONTEXT_READ)
+		if err2 := writer.readerPool.release(rld); err2 != nil && err == nil {
+			reader.decRef() // keep the original error
+			err = err2
+		}
+		if err != nil {
+			return nil, err
+		}
+		if reader.NumDocs() > 0 || writer.keepFullyDeletedSegments {
+			// Steal the ref:
+			readers = append(readers, reader)
+			infosUpto++
+		} else {
+			if err = reader.decRef(); err != nil {
+				return nil, err
+			}
+			segmentInfos.Segments = append(segmentInfos.Segments[:infosUpto],
+				segmentInfos.Segments[infosUpto+1:]...)
+		}
+	}
+
+	// protect the files of this point-in-time from IndexWriter's
+	// deleter; released when the reader is closed
+	writer.deleter.incRef(segmentInfos, false)
+	success = true
+	return newStandardDirectoryReader(dir, readers, writer, segmentInfos,
+		writer.config.ReaderTermsIndexDivisor(), applyAllDeletes), nil
+}
+
+/*
+Opens a reader on the given SegmentInfos, sharing the SegmentReaders
+of unchanged segments with oldReaders.
+*/
+func openStandardDirectoryReaderFrom(directory store.Directory, infos *SegmentInfos,
+	oldReaders []IndexReader, termInfosIndexDivisor int) (DirectoryReader, error) {
+
+	// we put the old SegmentReaders in a map, that allows us to lookup
+	// a reader using its segment name
+	segmentReaders := make(map[string]int)
+	for i, r := range oldReaders {
+		segmentReaders[r.(*SegmentReader).SegmentName()] = i
+	}
+
+	newReaders := make([]AtomicReader, len(infos.Segments))
+
+	for i := len(infos.Segments) - 1; i >= 0; i-- {
+		info := infos.Segments[i]
+		// find SegmentReader for this segment
+		var oldReader *SegmentReader
+		if oldReaderIndex, ok := segmentReaders[info.Info.Name]; ok {
+			// there is an old reader for this segment - we'll try to reopen it
+			oldReader = oldReaders[oldReaderIndex].(*SegmentReader)
+		}
+
+		newReader, err := func() (*SegmentReader, error) {
+			if oldReader == nil || info.Info.IsCompoundFile() != oldReader.si.Info.IsCompoundFile() {
+				// this is a new reader; in case we hit an error we can close it safely
+				return NewSegmentReader(info, termInfosIndexDivisor, store.IO_CONTEXT_READ)
+			}
+			if oldReader.si.DelGen() == info.DelGen() &&
+				oldReader.si.FieldInfosGen() == info.FieldInfosGen() {
+				// No change; this reader will be shared between the old
+				// and the new one, so we must incRef it:
+				oldReader.incRef()
+				return oldReader, nil
+			}
+			// there are changes to the reader, either liveDocs or DV updates
+			assert(info.Info.Dir == oldReader.si.Info.Dir)
+			assert(info.HasDeletions() || info.HasFieldUpdates())
+			if oldReader.si.DelGen() == info.DelGen() {
+				// only DV updates
+				return newSegmentReaderFrom(info, oldReader, oldReader.LiveDocs(), oldReader.NumDocs())
+			}
+			// both DV and liveDocs have changed
+			return newSegmentReaderWithNewDeletes(info, oldReader)
+		}()
+		if err != nil {
+			for i++; i < len(infos.Segments); i++ {
+				if newReaders[i] != nil {
+					// releases new readers, and our extra ref on shared ones
+					newReaders[i].decRef() // keep the original error
+				}
+			}
+			return nil, err
+		}
+		newReaders[i] = newReader
+	}
+	return newStandardDirectoryReader(directory, newReaders, nil, infos, termInfosIndexDivisor, false), nil
+}
+
 func (r *StandardDirectoryReader) String() string {
 	var buf bytes.Buffer
 	buf.WriteString("StandardDirectoryReader(")
@@ -140,9 +332,9 @@ func (r *StandardDirectoryReader) String() string {
 	if segmentsFile != "" {
 		fmt.Fprintf(&buf, "%v:%v", segmentsFile, r.segmentInfos.version)
 	}
-	// if r.writer != nil {
-	// fmt.Fprintf(w, "%v", r.writer)
-	// }
+	if r.writer != nil {
+		fmt.Fprintf(&buf, ":nrt")
+	}
 	for _, v := range r.getSequentialSubReaders() {
 		fmt.Fprintf(&buf, " %v", v)
 	}
@@ -157,20 +349,92 @@ func (r *StandardDirectoryReader) Version() int64 {
 
 func (r *StandardDirectoryReader) IsCurrent() bool {
 	r.ensureOpen()
-	// if writer == nill || writer.IsClosed() {
-	// Fully read the segments file: this ensures that it's
-	// completely written so that if
-	// IndexWriter.prepareCommit has been called (but not
-	// yet commit), then the reader will still see itself as
-	// current:
-	sis := SegmentInfos{}
-	sis.ReadAll(r.directory)
-
-	// we loaded SegmentInfos from the directory
-	return sis.version == r.segmentInfos.version
-	// } else {
-	// return writer.nrtIsCurrent(r.segmentInfos)
-	// }
+	if r.writer == nil || r.writer.isClosed() {
+		// Fully read the segments file: this ensures that it's
+		// completely written so that if
+		// IndexWriter.prepareCommit has been called (but not
+		// yet commit), then the reader will still see itself as
+		// current:
+		sis := SegmentInfos{}
+		sis.ReadAll(r.directory)
+
+		// we loaded SegmentInfos from the directory
+		return sis.version == r.segmentInfos.version
+	}
+	return r.writer.nrtIsCurrent(r.segmentInfos)
+}
+
+func (r *StandardDirectoryReader) doOpenIfChanged(commit IndexCommit) (DirectoryReader, error) {
+	r.ensureOpen()
+
+	// If we were obtained by writer.getReader(), re-ask the writer to
+	// get a new reader.
+	if r.writer != nil {
+		return r.doOpenFromWriter(commit)
+	}
+	return r.doOpenNoWriter(commit)
+}
+
+func (r *StandardDirectoryReader) doOpenIfChangedFromWriter(w *IndexWriter,
+	applyAllDeletes bool) (DirectoryReader, error) {
+
+	r.ensureOpen()
+	if w == r.writer && applyAllDeletes == r.applyAllDeletes {
+		return r.doOpenFromWriter(nil)
+	}
+	return w.reader(applyAllDeletes)
+}
+
+func (r *StandardDirectoryReader) doOpenFromWriter(commit IndexCommit) (DirectoryReader, error) {
+	if commit != nil {
+		return r.doOpenFromCommit(commit)
+	}
+
+	if r.writer.nrtIsCurrent(r.segmentInfos) {
+		return nil, nil
+	}
+
+	reader, err := r.writer.reader(r.applyAllDeletes)
+	if err != nil {
+		return nil, err
+	}
+
+	// If in fact no changes took place, return nil:
+	if reader.Version() == r.segmentInfos.version {
+		return nil, reader.decRef()
+	}
+	return reader, nil
+}
+
+func (r *StandardDirectoryReader) doOpenNoWriter(commit IndexCommit) (DirectoryReader, error) {
+	if commit == nil {
+		if r.IsCurrent() {
+			return nil, nil
+		}
+	} else {
+		if r.directory != commit.Directory() {
+			return nil, errors.New("the specified commit does not match the specified Directory")
+		}
+		if r.segmentInfos != nil && commit.SegmentsFileName() == r.segmentInfos.SegmentsFileName() {
+			return nil, nil
+		}
+	}
+	return r.doOpenFromCommit(commit)
+}
+
+func (r *StandardDirectoryReader) doOpenFromCommit(commit IndexCommit) (DirectoryReader, error) {
+	obj, err := NewFindSegmentsFile(r.directory, func(segmentFileName string) (interface{}, error) {
+		infos := &SegmentInfos{}
+		if err := infos.Read(r.directory, segmentFileName); err != nil {
+			return nil, err
+		}
+		return openStandardDirectoryReaderFrom(r.directory, infos,
+			r.getSequentialSubReaders(), r.termInfosIndexDivisor)
+	}).run(commit)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(DirectoryReader), nil
 }
 
 func (r *StandardDirectoryReader) doClose() error {
@@ -191,10 +455,12 @@ func (r *StandardDirectoryReader) doClose() error {
 		}()
 	}
 
-	if w := r.writer; w != nil {
-		panic("not implemented yet")
-		// Since we just closed, writer may now be able to delete unused files:
-		w.deletePendingFiles()
+	if w := r.writer; w != nil && !w.isClosed() {
+		// If our original writer was closed before we were, this may
+		// leave some un-referenced files in the index, which is
+		// harmless. The next time IW is opened on the index, it will
+		// delete them.
+		w.decRefDeleter(r.segmentInfos)
 	}
 
 	return firstErr
@@ -0,0 +1,94 @@
+package index
+
+import (
+	. "github.com/gzg1984/golucene/core/codec/spi"
+	"github.com/gzg1984/golucene/core/util"
+)
+
+// index/DocValues.java
+
+// An empty NumericDocValues which returns zero for every document
+func EmptyNumericDocValues() NumericDocValues {
+	return func(docID int) int64 { return 0 }
+}
+
+type emptyBinaryDocValues struct{}
+
+func (v emptyBinaryDocValues) Get(docID int) []byte { return nil }
+
+// An empty BinaryDocValues which returns an empty []byte for every
+// document
+func EmptyBinaryDocValues() BinaryDocValues {
+	return emptyBinaryDocValues{}
+}
+
+type emptySortedDocValues struct {
+	emptyBinaryDocValues
+}
+
+func (v emptySortedDocValues) Ord(docID int) int { return -1 }
+
+func (v emptySortedDocValues) LookupOrd(ord int) []byte {
+	panic("no ord in an empty SortedDocValues")
+}
+
+func (v emptySortedDocValues) ValueCount() int { return 0 }
+
+// An empty SortedDocValues which returns -1 for every document
+func EmptySortedDocValues() SortedDocValues {
+	return emptySortedDocValues{}
+}
+
+/*
+Returns NumericDocValues for the reader, or EmptyNumericDocValues()
+if it has none. It returns an error if the field was indexed with an
+incompatible doc values type.
+*/
+func GetNumericDocValues(reader AtomicReader, field string) (NumericDocValues, error) {
+	dv, err := reader.NumericDocValues(field)
+	if err != nil || dv != nil {
+		return dv, err
+	}
+	return EmptyNumericDocValues(), nil
+}
+
+/*
+Returns BinaryDocValues for the reader, or EmptyBinaryDocValues() if
+it has none. SortedDocValues are returned as is, since they are
+BinaryDocValues too.
+*/
+func GetBinaryDocValues(reader AtomicReader, field string) (BinaryDocValues, error) {
+	dv, err := reader.BinaryDocValues(field)
+	if err != nil || dv != nil {
+		return dv, err
+	}
+	sorted, err := reader.SortedDocValues(field)
+	if err != nil || sorted != nil {
+		return sorted, err
+	}
+	return EmptyBinaryDocValues(), nil
+}
+
+/*
+Returns SortedDocValues for the reader, or EmptySortedDocValues() if
+it has none.
+*/
+func GetSortedDocValues(reader AtomicReader, field string) (SortedDocValues, error) {
+	dv, err := reader.SortedDocValues(field)
+	if err != nil || dv != nil {
+		return dv, err
+	}
+	return EmptySortedDocValues(), nil
+}
+
+/*
+Returns the docs with a doc values entry for the field, or a
+util.Bits matching no documents if it has none.
+*/
+func GetDocsWithField(reader AtomicReader, field string) (util.Bits, error) {
+	bits, err := reader.DocsWithField(field)
+	if err != nil || bits != nil {
+		return bits, err
+	}
+	return util.NewMatchNoBits(reader.MaxDoc()), nil
+}
@@ -1,20 +1,179 @@
 package index
 
-/* Holds updates of a single DocValues field, for a set of documents. */
+import (
+	"fmt"
+	. "github.com/gzg1984/golucene/core/index/model"
+	search "github.com/gzg1984/golucene/core/search/model"
+	"math"
+	"sort"
+)
+
+// index/DocValuesFieldUpdates.java
+
+/*
+Holds updates of a single DocValues field, for a set of documents.
+Updates are recorded in the order they were resolved; when the same
+document is updated more than once, the last update wins.
+*/
 type DocValuesFieldUpdates struct {
+	field string
+	typ   DocValuesType
+
+	maxDoc int
+	docs   []int
+	values []interface{} // int64 or []byte
+}
+
+func newDocValuesFieldUpdates(field string, typ DocValuesType, maxDoc int) *DocValuesFieldUpdates {
+	assert2(typ == DOC_VALUES_TYPE_NUMERIC || typ == DOC_VALUES_TYPE_BINARY,
+		"updates are not supported for DocValues type %v", typ)
+	return &DocValuesFieldUpdates{field: field, typ: typ, maxDoc: maxDoc}
+}
+
+/* Add an update to a document. */
+func (u *DocValuesFieldUpdates) add(doc int, value interface{}) {
+	assert2(doc < u.maxDoc, "doc=%v maxDoc=%v", doc, u.maxDoc)
+	assert2(len(u.docs) < math.MaxInt32,
+		"cannot support more than MaxInt32 doc/value entries")
+	u.docs = append(u.docs, doc)
+	u.values = append(u.values, value)
+}
+
+/*
+Returns an iterator over the updated documents and their values, in
+doc ID order.
+*/
+func (u *DocValuesFieldUpdates) iterator() *DocValuesFieldUpdatesIterator {
+	// stable sort, so that the later updates of a document remain last
+	sort.Stable(u)
+	return &DocValuesFieldUpdatesIterator{owner: u, doc: -1}
+}
+
+func (u *DocValuesFieldUpdates) Len() int           { return len(u.docs) }
+func (u *DocValuesFieldUpdates) Less(i, j int) bool { return u.docs[i] < u.docs[j] }
+func (u *DocValuesFieldUpdates) Swap(i, j int) {
+	u.docs[i], u.docs[j] = u.docs[j], u.docs[i]
+	u.values[i], u.values[j] = u.values[j], u.values[i]
+}
+
+/*
+Merge with another DocValuesFieldUpdates. This is called for a
+segment which received updates while it was being merged. The given
+updates should override whatever updates are in that instance.
+*/
+func (u *DocValuesFieldUpdates) merge(other *DocValuesFieldUpdates) {
+	assert(u.typ == other.typ)
+	u.docs = append(u.docs, other.docs...)
+	u.values = append(u.values, other.values...)
+}
+
+/* Returns true if this instance contains any updates. */
+func (u *DocValuesFieldUpdates) any() bool {
+	return len(u.docs) > 0
+}
+
+func (u *DocValuesFieldUpdates) String() string {
+	return fmt.Sprintf("%v updates for %v", len(u.docs), u.field)
+}
+
+/*
+An iterator over documents and their updated values. Only documents
+with updates are returned by this iterator, and the documents are
+returned in increasing order.
+*/
+type DocValuesFieldUpdatesIterator struct {
+	owner  *DocValuesFieldUpdates
+	idx    int
+	doc    int
+	_value interface{}
+}
+
+/*
+Returns the next document which has an update, or NO_MORE_DOCS if
+there are no more documents to return.
+*/
+func (it *DocValuesFieldUpdatesIterator) nextDoc() int {
+	docs := it.owner.docs
+	if it.idx >= len(docs) {
+		it._value = nil
+		it.doc = search.NO_MORE_DOCS
+		return it.doc
+	}
+	it.doc = docs[it.idx]
+	it.idx++
+	for it.idx < len(docs) && docs[it.idx] == it.doc {
+		it.idx++
+	}
+	// idx points to the "next" element
+	it._value = it.owner.values[it.idx-1]
+	return it.doc
+}
+
+/* Returns the value of the document returned from nextDoc(). */
+func (it *DocValuesFieldUpdatesIterator) value() interface{} {
+	return it._value
+}
+
+/* Reset the iterator's state. Should be called before nextDoc() and value(). */
+func (it *DocValuesFieldUpdatesIterator) reset() {
+	it.idx, it.doc, it._value = 0, -1, nil
 }
 
 type DocValuesFieldUpdatesContainer struct {
+	numericDVUpdates map[string]*DocValuesFieldUpdates
+	binaryDVUpdates  map[string]*DocValuesFieldUpdates
 }
 
 func newDocValuesFieldUpdatesContainer() *DocValuesFieldUpdatesContainer {
-	panic("not implemented yte")
+	return &DocValuesFieldUpdatesContainer{
+		numericDVUpdates: make(map[string]*DocValuesFieldUpdates),
+		binaryDVUpdates:  make(map[string]*DocValuesFieldUpdates),
+	}
 }
 
 func (c *DocValuesFieldUpdatesContainer) any() bool {
-	panic("not implemented yet")
+	for _, updates := range c.numericDVUpdates {
+		if updates.any() {
+			return true
+		}
+	}
+	for _, updates := range c.binaryDVUpdates {
+		if updates.any() {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *DocValuesFieldUpdatesContainer) size() int {
+	return len(c.numericDVUpdates) + len(c.binaryDVUpdates)
+}
+
+func (c *DocValuesFieldUpdatesContainer) updatesOf(typ DocValuesType) map[string]*DocValuesFieldUpdates {
+	switch typ {
+	case DOC_VALUES_TYPE_NUMERIC:
+		return c.numericDVUpdates
+	case DOC_VALUES_TYPE_BINARY:
+		return c.binaryDVUpdates
+	default:
+		panic(fmt.Sprintf("unsupported type: %v", typ))
+	}
+}
+
+func (c *DocValuesFieldUpdatesContainer) updates(field string, typ DocValuesType) *DocValuesFieldUpdates {
+	return c.updatesOf(typ)[field]
+}
+
+func (c *DocValuesFieldUpdatesContainer) newUpdates(field string, typ DocValuesType, maxDoc int) *DocValuesFieldUpdates {
+	updates := c.updatesOf(typ)
+	_, ok := updates[field]
+	assert(!ok)
+	ans := newDocValuesFieldUpdates(field, typ, maxDoc)
+	updates[field] = ans
+	return ans
 }
 
 func (c *DocValuesFieldUpdatesContainer) String() string {
-	panic("not implemented yet")
+	return fmt.Sprintf("numericDVUpdates=%v binaryDVUpdates=%v",
+		c.numericDVUpdates, c.binaryDVUpdates)
 }
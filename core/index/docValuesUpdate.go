@@ -1,24 +1,88 @@
 package index
 
+import (
+	"fmt"
+	. "github.com/gzg1984/golucene/core/index/model"
+	"github.com/gzg1984/golucene/core/util"
+)
+
+// index/DocValuesUpdate.java
+
+/*
+Rough logic: OBJ_HEADER + 3*PTR + INT
+Term: OBJ_HEADER + 2*PTR
+
+	Term.field: 2*OBJ_HEADER + 4*INT + PTR + string.length*CHAR
+	Term.bytes: 2*OBJ_HEADER + 2*INT + PTR + bytes.length
+
+String: 2*OBJ_HEADER + 4*INT + PTR + string.length*CHAR
+T: OBJ_HEADER
+*/
+const RAW_SIZE_IN_BYTES = 8*util.NUM_BYTES_OBJECT_HEADER +
+	8*util.NUM_BYTES_OBJECT_REF + 8*util.NUM_BYTES_INT
+
 /* An in-place update to a DocValues field. */
 type DocValuesUpdate struct {
+	typ   DocValuesType // DOC_VALUES_TYPE_NUMERIC or DOC_VALUES_TYPE_BINARY
+	term  *Term
+	field string
+	value interface{} // int64 or []byte
+
+	// unassigned until applied, and confusing that it's here, when
+	// it's just used in BufferedUpdates...
+	docIDUpto int
+
 	valueSizeInBytes func() int64
 }
 
+func newDocValuesUpdate(typ DocValuesType, term *Term, field string,
+	value interface{}, valueSizeInBytes func() int64) *DocValuesUpdate {
+
+	return &DocValuesUpdate{
+		typ:              typ,
+		term:             term,
+		field:            field,
+		value:            value,
+		docIDUpto:        -1,
+		valueSizeInBytes: valueSizeInBytes,
+	}
+}
+
 func (u *DocValuesUpdate) sizeInBytes() int {
-	panic("not implemented yet")
+	sizeInBytes := RAW_SIZE_IN_BYTES
+	sizeInBytes += len(u.term.Field) * util.NUM_BYTES_CHAR
+	sizeInBytes += len(u.term.Bytes)
+	sizeInBytes += len(u.field) * util.NUM_BYTES_CHAR
+	sizeInBytes += int(u.valueSizeInBytes())
+	return sizeInBytes
+}
+
+/* Returns a copy of this update which can be buffered separately. */
+func (u *DocValuesUpdate) clone() *DocValuesUpdate {
+	return newDocValuesUpdate(u.typ, u.term, u.field, u.value, u.valueSizeInBytes)
 }
 
 func (u *DocValuesUpdate) String() string {
-	panic("not implemented yet")
+	if u.typ == DOC_VALUES_TYPE_BINARY {
+		return fmt.Sprintf("term=%v,field=%v,value=%v", u.term, u.field, u.value.([]byte))
+	}
+	return fmt.Sprintf("term=%v,field=%v,value=%v", u.term, u.field, u.value)
 }
 
+/* Raw value size of binary value: ARRAY_HEADER + 2*INT + PTR */
+const RAW_BINARY_VALUE_SIZE_IN_BYTES = util.NUM_BYTES_ARRAY_HEADER +
+	2*util.NUM_BYTES_INT + util.NUM_BYTES_OBJECT_REF
+
 /* An in-place update to a binary DocValues field */
 func newBinaryDocValuesUpdate(term *Term, field string, value []byte) *DocValuesUpdate {
-	panic("not implemented yet")
+	return newDocValuesUpdate(DOC_VALUES_TYPE_BINARY, term, field, value, func() int64 {
+		return int64(RAW_BINARY_VALUE_SIZE_IN_BYTES + len(value))
+	})
 }
 
-/* An in-plsace update to a numeric DocValues field */
+/* An in-place update to a numeric DocValues field */
 func newNumericDocValuesUpdate(term *Term, field string, value int64) *DocValuesUpdate {
-	panic("not implemented yet")
+	return newDocValuesUpdate(DOC_VALUES_TYPE_NUMERIC, term, field, value, func() int64 {
+		return util.NUM_BYTES_LONG
+	})
 }
@@ -5,6 +5,7 @@ import (
 	. "github.com/gzg1984/golucene/core/index/model"
 	"github.com/gzg1984/golucene/core/util"
 	"github.com/gzg1984/golucene/core/util/packed"
+	"sort"
 )
 
 type DocValuesWriter interface {
@@ -60,11 +61,10 @@ func (w *NumericDocValuesWriter) addValue(docId int, value int64) {
 }
 
 func (w *NumericDocValuesWriter) docsWithFieldBytesUsed() int64 {
-	// size of the []int64 + some overhead
 	if w.docsWithField == nil {
 		return 0
 	}
-	return util.SizeOf(w.docsWithField.Bits()) + 64
+	return w.docsWithField.RamBytesUsed()
 }
 
 func (w *NumericDocValuesWriter) updateBytesUsed() {
@@ -80,10 +80,9 @@ func (w *NumericDocValuesWriter) flush(state *SegmentWriteState,
 
 	maxDoc := state.SegmentInfo.DocCount()
 	values := w.pending.Build()
-	dvConsumer.AddNumericField(w.fieldInfo, func() func() (interface{}, bool) {
+	return dvConsumer.AddNumericField(w.fieldInfo, func() func() (interface{}, bool) {
 		return newNumericIterator(maxDoc, values, w.docsWithField)
 	})
-	return nil
 }
 
 /* Iterates over the values we have in ram */
@@ -116,3 +115,425 @@ func newNumericIterator(maxDoc int,
 		return value, true
 	}
 }
+
+// index/BinaryDocValuesWriter.java
+
+/* Buffers up pending []byte per doc, then flushes when segment flushes. */
+type BinaryDocValuesWriter struct {
+	bytes         []byte
+	iwBytesUsed   util.Counter
+	lengths       packed.PackedLongValuesBuilder
+	docsWithField *util.FixedBitSet
+	fieldInfo     *FieldInfo
+	addedValues   int
+	bytesUsed     int64
+}
+
+func newBinaryDocValuesWriter(fieldInfo *FieldInfo, iwBytesUsed util.Counter) *BinaryDocValuesWriter {
+	ans := &BinaryDocValuesWriter{
+		fieldInfo:     fieldInfo,
+		iwBytesUsed:   iwBytesUsed,
+		lengths:       packed.DeltaPackedBuilder(packed.PackedInts.COMPACT),
+		docsWithField: util.NewFixedBitSetOf(64),
+	}
+	ans.bytesUsed = ans.docsWithFieldBytesUsed()
+	ans.iwBytesUsed.AddAndGet(ans.bytesUsed)
+	return ans
+}
+
+func (w *BinaryDocValuesWriter) addValue(docId int, value []byte) {
+	assert2(docId >= w.addedValues,
+		"DocValuesField '%v' appears more than once in this document (only one value is allowed per field)",
+		w.fieldInfo.Name)
+	assert2(value != nil, "field=%v: null value not allowed", w.fieldInfo.Name)
+	assert2(len(value) <= util.MAX_ARRAY_LENGTH,
+		"DocValuesField '%v' is too large, must be <= %v",
+		w.fieldInfo.Name, util.MAX_ARRAY_LENGTH)
+
+	// Fill in any holes:
+	for w.addedValues < docId {
+		w.addedValues++
+		w.lengths.Add(0)
+	}
+	w.addedValues++
+	w.lengths.Add(int64(len(value)))
+	w.bytes = append(w.bytes, value...)
+	w.docsWithField = util.EnsureFixedBitSet(w.docsWithField, docId)
+	w.docsWithField.Set(docId)
+	w.updateBytesUsed()
+}
+
+func (w *BinaryDocValuesWriter) docsWithFieldBytesUsed() int64 {
+	return w.docsWithField.RamBytesUsed()
+}
+
+func (w *BinaryDocValuesWriter) updateBytesUsed() {
+	newBytesUsed := w.lengths.RamBytesUsed() + int64(cap(w.bytes)) + w.docsWithFieldBytesUsed()
+	w.iwBytesUsed.AddAndGet(newBytesUsed - w.bytesUsed)
+	w.bytesUsed = newBytesUsed
+}
+
+func (w *BinaryDocValuesWriter) finish(maxDoc int) {}
+
+func (w *BinaryDocValuesWriter) flush(state *SegmentWriteState,
+	dvConsumer DocValuesConsumer) error {
+
+	maxDoc := state.SegmentInfo.DocCount()
+	lengths := w.lengths.Build()
+	return dvConsumer.AddBinaryField(w.fieldInfo, func() func() (interface{}, bool) {
+		return newBinaryIterator(maxDoc, w.bytes, lengths, w.docsWithField)
+	})
+}
+
+/* Iterates over the values we have in ram */
+func newBinaryIterator(maxDoc int, bytes []byte,
+	lengths packed.PackedLongValues,
+	docsWithField *util.FixedBitSet) func() (interface{}, bool) {
+
+	upto, offset, size := 0, 0, int(lengths.Size())
+	iter := lengths.Iterator()
+	return func() (interface{}, bool) {
+		if upto >= maxDoc {
+			return nil, false
+		}
+		var value interface{}
+		if upto < size {
+			v, _ := iter()
+			length := int(v.(int64))
+			if docsWithField.At(upto) {
+				value = bytes[offset : offset+length]
+			}
+			offset += length
+		}
+		upto++
+		return value, true
+	}
+}
+
+// index/SortedDocValuesWriter.java
+
+const EMPTY_ORD = -1
+
+/*
+Buffers up pending []byte per doc, deref and sorting via int ord,
+then flushes when segment flushes.
+*/
+type SortedDocValuesWriter struct {
+	hash        *util.BytesRefHash
+	pending     packed.PackedLongValuesBuilder
+	iwBytesUsed util.Counter
+	bytesUsed   int64 // this currently only tracks differences in 'pending'
+	fieldInfo   *FieldInfo
+}
+
+func newSortedDocValuesWriter(fieldInfo *FieldInfo, iwBytesUsed util.Counter) *SortedDocValuesWriter {
+	ans := &SortedDocValuesWriter{
+		fieldInfo:   fieldInfo,
+		iwBytesUsed: iwBytesUsed,
+		hash: util.NewBytesRefHash(
+			util.NewByteBlockPool(util.NewDirectTrackingAllocator(iwBytesUsed)),
+			util.BYTES_REF_HASH_DEFAULT_CAPACITY,
+			util.NewDirectBytesStartArray(util.BYTES_REF_HASH_DEFAULT_CAPACITY, iwBytesUsed)),
+		pending: packed.DeltaPackedBuilder(packed.PackedInts.COMPACT),
+	}
+	ans.bytesUsed = ans.pending.RamBytesUsed()
+	ans.iwBytesUsed.AddAndGet(ans.bytesUsed)
+	return ans
+}
+
+func (w *SortedDocValuesWriter) addValue(docId int, value []byte) {
+	assert2(int64(docId) >= w.pending.Size(),
+		"DocValuesField '%v' appears more than once in this document (only one value is allowed per field)",
+		w.fieldInfo.Name)
+	assert2(value != nil, "field '%v': null value not allowed", w.fieldInfo.Name)
+	assert2(len(value) <= util.BYTE_BLOCK_SIZE-2,
+		"DocValuesField '%v' is too large, must be <= %v",
+		w.fieldInfo.Name, util.BYTE_BLOCK_SIZE-2)
+
+	// Fill in any holes:
+	for w.pending.Size() < int64(docId) {
+		w.pending.Add(EMPTY_ORD)
+	}
+
+	w.addOneValue(value)
+}
+
+func (w *SortedDocValuesWriter) finish(maxDoc int) {
+	for w.pending.Size() < int64(maxDoc) {
+		w.pending.Add(EMPTY_ORD)
+	}
+	w.updateBytesUsed()
+}
+
+func (w *SortedDocValuesWriter) addOneValue(value []byte) {
+	termId, err := w.hash.Add(value)
+	assert2(err == nil, "%v", err)
+	if termId < 0 {
+		termId = -termId - 1
+	} else {
+		// reserve additional space for each unique value:
+		// 1. when indexing, when hash is 50% full, rehash() suddenly needs 2*size ints.
+		//    TODO: can this same OOM happen in THPF?
+		// 2. when flushing, we need 1 int per value (slot in the ordMap).
+		w.iwBytesUsed.AddAndGet(2 * util.NUM_BYTES_INT)
+	}
+
+	w.pending.Add(int64(termId))
+	w.updateBytesUsed()
+}
+
+func (w *SortedDocValuesWriter) updateBytesUsed() {
+	newBytesUsed := w.pending.RamBytesUsed()
+	w.iwBytesUsed.AddAndGet(newBytesUsed - w.bytesUsed)
+	w.bytesUsed = newBytesUsed
+}
+
+func (w *SortedDocValuesWriter) flush(state *SegmentWriteState,
+	dvConsumer DocValuesConsumer) error {
+
+	maxDoc := state.SegmentInfo.DocCount()
+	assert(w.pending.Size() == int64(maxDoc))
+	valueCount := w.hash.Size()
+	ords := w.pending.Build()
+
+	sortedValues := w.hash.Sort(util.UTF8SortedAsUnicodeLess)
+	ordMap := make([]int, valueCount)
+	for ord := 0; ord < valueCount; ord++ {
+		ordMap[sortedValues[ord]] = ord
+	}
+
+	return dvConsumer.AddSortedField(w.fieldInfo,
+		// ord -> value
+		func() func() (interface{}, bool) {
+			return newValuesIterator(sortedValues, valueCount, w.hash)
+		},
+		// doc -> ord
+		func() func() (interface{}, bool) {
+			return newOrdsIterator(ordMap, maxDoc, ords)
+		})
+}
+
+/* Iterates over the unique values we have in ram */
+func newValuesIterator(sortedValues []int, valueCount int,
+	hash *util.BytesRefHash) func() (interface{}, bool) {
+
+	ordUpto := 0
+	return func() (interface{}, bool) {
+		if ordUpto >= valueCount {
+			return nil, false
+		}
+		scratch := hash.Get(sortedValues[ordUpto], util.NewEmptyBytesRef())
+		ordUpto++
+		return scratch.ToBytes(), true
+	}
+}
+
+/* Iterates over the ords for each doc we have in ram */
+func newOrdsIterator(ordMap []int, maxDoc int,
+	ords packed.PackedLongValues) func() (interface{}, bool) {
+
+	docUpto := 0
+	iter := ords.Iterator()
+	return func() (interface{}, bool) {
+		if docUpto >= maxDoc {
+			return nil, false
+		}
+		v, _ := iter()
+		ord := int(v.(int64))
+		docUpto++
+		if ord == EMPTY_ORD {
+			return ord, true
+		}
+		return ordMap[ord], true
+	}
+}
+
+// index/SortedSetDocValuesWriter.java
+
+/*
+Buffers up pending []byte per doc, deref and sorting via int ord,
+then flushes when segment flushes.
+*/
+type SortedSetDocValuesWriter struct {
+	hash          *util.BytesRefHash
+	pending       packed.PackedLongValuesBuilder // stream of all termIDs
+	pendingCounts packed.PackedLongValuesBuilder // termIDs per doc
+	iwBytesUsed   util.Counter
+	bytesUsed     int64 // this only tracks differences in 'pending' and 'pendingCounts'
+	fieldInfo     *FieldInfo
+	currentDoc    int
+	currentValues []int
+	maxCount      int
+}
+
+func newSortedSetDocValuesWriter(fieldInfo *FieldInfo, iwBytesUsed util.Counter) *SortedSetDocValuesWriter {
+	ans := &SortedSetDocValuesWriter{
+		fieldInfo:   fieldInfo,
+		iwBytesUsed: iwBytesUsed,
+		hash: util.NewBytesRefHash(
+			util.NewByteBlockPool(util.NewDirectTrackingAllocator(iwBytesUsed)),
+			util.BYTES_REF_HASH_DEFAULT_CAPACITY,
+			util.NewDirectBytesStartArray(util.BYTES_REF_HASH_DEFAULT_CAPACITY, iwBytesUsed)),
+		pending:       packed.DeltaPackedBuilder(packed.PackedInts.COMPACT),
+		pendingCounts: packed.DeltaPackedBuilder(packed.PackedInts.COMPACT),
+		currentValues: make([]int, 0, 8),
+	}
+	ans.bytesUsed = ans.pending.RamBytesUsed() + ans.pendingCounts.RamBytesUsed()
+	ans.iwBytesUsed.AddAndGet(ans.bytesUsed)
+	return ans
+}
+
+func (w *SortedSetDocValuesWriter) addValue(docId int, value []byte) {
+	assert2(value != nil, "field '%v': null value not allowed", w.fieldInfo.Name)
+	assert2(len(value) <= util.BYTE_BLOCK_SIZE-2,
+		"DocValuesField '%v' is too large, must be <= %v",
+		w.fieldInfo.Name, util.BYTE_BLOCK_SIZE-2)
+
+	if docId != w.currentDoc {
+		w.finishCurrentDoc()
+	}
+
+	// Fill in any holes:
+	for w.currentDoc < docId {
+		w.pendingCounts.Add(0) // no values
+		w.currentDoc++
+	}
+
+	w.addOneValue(value)
+	w.updateBytesUsed()
+}
+
+/* finalize currentDoc: this deduplicates the current term ids */
+func (w *SortedSetDocValuesWriter) finishCurrentDoc() {
+	sort.Ints(w.currentValues)
+	lastValue, count := -1, 0
+	for _, termId := range w.currentValues {
+		// if its not a duplicate
+		if termId != lastValue {
+			w.pending.Add(int64(termId)) // record the term id
+			count++
+		}
+		lastValue = termId
+	}
+	// record the number of unique term ids for this doc
+	w.pendingCounts.Add(int64(count))
+	if count > w.maxCount {
+		w.maxCount = count
+	}
+	w.currentValues = w.currentValues[:0]
+	w.currentDoc++
+}
+
+func (w *SortedSetDocValuesWriter) finish(maxDoc int) {
+	w.finishCurrentDoc()
+
+	// fill in any holes
+	for i := w.currentDoc; i < maxDoc; i++ {
+		w.pendingCounts.Add(0) // no values
+	}
+}
+
+func (w *SortedSetDocValuesWriter) addOneValue(value []byte) {
+	termId, err := w.hash.Add(value)
+	assert2(err == nil, "%v", err)
+	if termId < 0 {
+		termId = -termId - 1
+	} else {
+		// reserve additional space for each unique value:
+		// 1. when indexing, when hash is 50% full, rehash() suddenly needs 2*size ints.
+		//    TODO: can this same OOM happen in THPF?
+		// 2. when flushing, we need 1 int per value (slot in the ordMap).
+		w.iwBytesUsed.AddAndGet(2 * util.NUM_BYTES_INT)
+	}
+
+	if len(w.currentValues) == cap(w.currentValues) {
+		w.iwBytesUsed.AddAndGet(int64(cap(w.currentValues)) * 2 * util.NUM_BYTES_INT)
+	}
+	w.currentValues = append(w.currentValues, termId)
+}
+
+func (w *SortedSetDocValuesWriter) updateBytesUsed() {
+	newBytesUsed := w.pending.RamBytesUsed() + w.pendingCounts.RamBytesUsed()
+	w.iwBytesUsed.AddAndGet(newBytesUsed - w.bytesUsed)
+	w.bytesUsed = newBytesUsed
+}
+
+func (w *SortedSetDocValuesWriter) flush(state *SegmentWriteState,
+	dvConsumer DocValuesConsumer) error {
+
+	maxDoc := state.SegmentInfo.DocCount()
+	maxCountPerDoc := w.maxCount
+	assert(w.pendingCounts.Size() == int64(maxDoc))
+	valueCount := w.hash.Size()
+	ords := w.pending.Build()
+	ordCounts := w.pendingCounts.Build()
+
+	sortedValues := w.hash.Sort(util.UTF8SortedAsUnicodeLess)
+	ordMap := make([]int, valueCount)
+	for ord := 0; ord < valueCount; ord++ {
+		ordMap[sortedValues[ord]] = ord
+	}
+
+	return dvConsumer.AddSortedSetField(w.fieldInfo,
+		// ord -> value
+		func() func() (interface{}, bool) {
+			return newValuesIterator(sortedValues, valueCount, w.hash)
+		},
+		// doc -> ordCount
+		func() func() (interface{}, bool) {
+			return newOrdCountIterator(maxDoc, ordCounts)
+		},
+		// ords
+		func() func() (interface{}, bool) {
+			return newSortedSetOrdsIterator(ordMap, maxCountPerDoc, ords, ordCounts)
+		})
+}
+
+/* Iterates over the ords for each doc we have in ram */
+func newSortedSetOrdsIterator(ordMap []int, maxCount int,
+	ords, ordCounts packed.PackedLongValues) func() (interface{}, bool) {
+
+	iter := ords.Iterator()
+	counts := ordCounts.Iterator()
+	numOrds := ords.Size()
+	currentDoc := make([]int, 0, maxCount)
+	var ordUpto int64
+	currentUpto := 0
+	return func() (interface{}, bool) {
+		if ordUpto >= numOrds {
+			return nil, false
+		}
+		if currentUpto == len(currentDoc) {
+			// refill next doc, and sort remapped ords within the doc.
+			currentDoc, currentUpto = currentDoc[:0], 0
+			for len(currentDoc) == 0 {
+				v, _ := counts()
+				for i, n := 0, int(v.(int64)); i < n; i++ {
+					ord, _ := iter()
+					currentDoc = append(currentDoc, ordMap[int(ord.(int64))])
+				}
+			}
+			sort.Ints(currentDoc)
+		}
+		ord := currentDoc[currentUpto]
+		currentUpto++
+		ordUpto++
+		return int64(ord), true
+	}
+}
+
+/* Iterates over the ord counts for each doc we have in ram */
+func newOrdCountIterator(maxDoc int,
+	ordCounts packed.PackedLongValues) func() (interface{}, bool) {
+
+	docUpto := 0
+	iter := ordCounts.Iterator()
+	return func() (interface{}, bool) {
+		if docUpto >= maxDoc {
+			return nil, false
+		}
+		v, _ := iter()
+		docUpto++
+		return int(v.(int64)), true
+	}
+}
@@ -168,6 +168,54 @@ func (dw *DocumentsWriter) abort(writer *IndexWriter) {
 	success = true
 }
 
+/*
+Aborts all buffered documents and deletes, and leaves every
+ThreadState locked so that no document can be indexed until
+unlockAllAfterAbortAll is called with the returned states. Must be
+called while holding IW's fullFlushLock.
+*/
+func (dw *DocumentsWriter) lockAndAbortAll(writer *IndexWriter) []*ThreadState {
+	dw.Lock() // synchronized
+	defer dw.Unlock()
+
+	if dw.infoStream.IsEnabled("DW") {
+		dw.infoStream.Message("DW", "lockAndAbortAll")
+	}
+	var success = false
+	var locked []*ThreadState
+	defer func() {
+		if dw.infoStream.IsEnabled("DW") {
+			dw.infoStream.Message("DW", "finished lockAndAbortAll success=%v", success)
+		}
+		if !success {
+			dw.unlockAllAfterAbortAll(writer, locked)
+		}
+	}()
+
+	newFilesSet := make(map[string]bool)
+	dw.deleteQueue.clear()
+	for i, limit := 0, dw.perThreadPool.numActiveThreadState(); i < limit; i++ {
+		perThread := dw.perThreadPool.lock(i, true)
+		locked = append(locked, perThread)
+		dw.abortThreadState(perThread, newFilesSet)
+	}
+	dw.deleteQueue.clear()
+	dw.flushControl.abortPendingFlushes(newFilesSet)
+	dw.putEvent(newDeleteNewFilesEvent(newFilesSet))
+	dw.flushControl.waitForFlush()
+	success = true
+	return locked
+}
+
+func (dw *DocumentsWriter) unlockAllAfterAbortAll(writer *IndexWriter, locked []*ThreadState) {
+	if dw.infoStream.IsEnabled("DW") {
+		dw.infoStream.Message("DW", "unlockAll")
+	}
+	for _, perThread := range locked {
+		dw.perThreadPool.release(perThread)
+	}
+}
+
 func (dw *DocumentsWriter) abortThreadState(perThread *ThreadState, newFiles map[string]bool) {
 	if perThread.isActive { // we might be closed
 		if perThread.dwpt != nil {
@@ -338,6 +386,43 @@ func (dw *DocumentsWriter) updateDocument(doc []model.IndexableField,
 	return dw.postUpdate(flushingDWPT, hasEvents)
 }
 
+func (dw *DocumentsWriter) deleteQueries(queries ...Query) (bool, error) {
+	dw.Lock() // synchronized
+	defer dw.Unlock()
+	// TODO why is this synchronized?
+	deleteQueue := dw.deleteQueue
+	deleteQueue.addDeleteQueries(queries...)
+	dw.flushControl.doOnDelete()
+	return dw.applyAllDeletes(deleteQueue)
+}
+
+func (dw *DocumentsWriter) deleteTerms(terms ...*Term) (bool, error) {
+	dw.Lock() // synchronized
+	defer dw.Unlock()
+	deleteQueue := dw.deleteQueue
+	deleteQueue.addDeleteTerms(terms...)
+	dw.flushControl.doOnDelete()
+	return dw.applyAllDeletes(deleteQueue)
+}
+
+func (dw *DocumentsWriter) updateNumericDocValue(term *Term, field string, value int64) (bool, error) {
+	dw.Lock() // synchronized
+	defer dw.Unlock()
+	deleteQueue := dw.deleteQueue
+	deleteQueue.addNumericUpdate(newNumericDocValuesUpdate(term, field, value))
+	dw.flushControl.doOnDelete()
+	return dw.applyAllDeletes(deleteQueue)
+}
+
+func (dw *DocumentsWriter) updateBinaryDocValue(term *Term, field string, value []byte) (bool, error) {
+	dw.Lock() // synchronized
+	defer dw.Unlock()
+	deleteQueue := dw.deleteQueue
+	deleteQueue.addBinaryUpdate(newBinaryDocValuesUpdate(term, field, value))
+	dw.flushControl.doOnDelete()
+	return dw.applyAllDeletes(deleteQueue)
+}
+
 func (dw *DocumentsWriter) doFlush(flushingDWPT *DocumentsWriterPerThread) (bool, error) {
 
 	fmt.Printf("=====Enter DocumentsWriter doFlush\n")
@@ -390,10 +475,13 @@ func (dw *DocumentsWriter) doFlush(flushingDWPT *DocumentsWriterPerThread) (bool
 				fmt.Printf("=====Before dw.ticketQueue.addFlushTicket\n")
 
 				// Each flush is assigned a ticket in the order they acquire the ticketQueue lock
-				ticket = dw.ticketQueue.addFlushTicket(flushingDWPT)
+				var err error
+				if ticket, err = dw.ticketQueue.addFlushTicket(flushingDWPT); err != nil {
+					return err
+				}
 
 				flushingDocsInRAM := flushingDWPT.numDocsInRAM
-				err := func() error {
+				err = func() error {
 					var dwptSuccess = false
 					defer func() {
 						dw.subtractFlushedNumDocs(flushingDocsInRAM)
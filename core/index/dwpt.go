@@ -60,13 +60,16 @@ type FlushedSegment struct {
 
 func newFlushedSegment(segmentInfo *SegmentCommitInfo,
 	fieldInfos FieldInfos, segmentUpdates *BufferedUpdates,
-	liveDocs util.MutableBits, delCount int) *FlushedSegment {
+	liveDocs util.MutableBits, delCount int) (*FlushedSegment, error) {
 
 	var sd *FrozenBufferedUpdates
 	if segmentUpdates != nil && segmentUpdates.any() {
-		sd = freezeBufferedUpdates(segmentUpdates, true)
+		var err error
+		if sd, err = freezeBufferedUpdates(segmentUpdates, true); err != nil {
+			return nil, err
+		}
 	}
-	return &FlushedSegment{segmentInfo, fieldInfos, sd, liveDocs, delCount}
+	return &FlushedSegment{segmentInfo, fieldInfos, sd, liveDocs, delCount}, nil
 }
 
 type DocumentsWriterPerThread struct {
@@ -282,9 +285,12 @@ func (dwpt *DocumentsWriterPerThread) deleteDocID(docIDUpto int) {
 Prepares this DWPT fo flushing. This method will freeze and return
 the DWDQs global buffer and apply all pending deletes to this DWPT.
 */
-func (dwpt *DocumentsWriterPerThread) prepareFlush() *FrozenBufferedUpdates {
+func (dwpt *DocumentsWriterPerThread) prepareFlush() (*FrozenBufferedUpdates, error) {
 	assert(dwpt.numDocsInRAM > 0)
-	globalDeletes := dwpt.deleteQueue.freezeGlobalBuffer(dwpt.deleteSlice)
+	globalDeletes, err := dwpt.deleteQueue.freezeGlobalBuffer(dwpt.deleteSlice)
+	if err != nil {
+		return nil, err
+	}
 	// deleteSlice can possibly be nil if we have hit non-aborting
 	// errors during adding a document.
 	if dwpt.deleteSlice != nil {
@@ -293,7 +299,7 @@ func (dwpt *DocumentsWriterPerThread) prepareFlush() *FrozenBufferedUpdates {
 		assert(dwpt.deleteSlice.isEmpty())
 		dwpt.deleteSlice.reset()
 	}
-	return globalDeletes
+	return globalDeletes, nil
 }
 
 /* Flush all pending docs to a new segment */
@@ -348,7 +354,7 @@ func (dwpt *DocumentsWriterPerThread) flush() (fs *FlushedSegment, err error) {
 	if err != nil {
 		return nil, err
 	}
-	dwpt.pendingUpdates.terms = make(map[*Term]int)
+	dwpt.pendingUpdates.terms = make(map[termKey]int)
 	files := make(map[string]bool)
 	dwpt.directory.EachCreatedFiles(func(name string) {
 		files[name] = true
@@ -372,7 +378,11 @@ func (dwpt *DocumentsWriterPerThread) flush() (fs *FlushedSegment, err error) {
 	}
 
 	var segmentUpdates *BufferedUpdates
-	if len(dwpt.pendingUpdates.queries) > 0 {
+	if len(dwpt.pendingUpdates.queries) == 0 &&
+		len(dwpt.pendingUpdates.numericUpdates) == 0 &&
+		len(dwpt.pendingUpdates.binaryUpdates) == 0 {
+		dwpt.pendingUpdates.clear()
+	} else {
 		segmentUpdates = dwpt.pendingUpdates
 	}
 
@@ -393,8 +403,10 @@ func (dwpt *DocumentsWriterPerThread) flush() (fs *FlushedSegment, err error) {
 
 	fmt.Printf("=====Before  newFlushedSegment\n")
 
-	fs = newFlushedSegment(info, flushState.FieldInfos, segmentUpdates,
-		flushState.LiveDocs, flushState.DelCountOnFlush)
+	if fs, err = newFlushedSegment(info, flushState.FieldInfos, segmentUpdates,
+		flushState.LiveDocs, flushState.DelCountOnFlush); err != nil {
+		return nil, err
+	}
 
 	fmt.Printf("=====Before  dwpt.sealFlushedSegment\n")
 
@@ -530,6 +530,26 @@ func (fc *DocumentsWriterFlushControl) addFlushableState(perThread *ThreadState)
 	}
 }
 
+func (fc *DocumentsWriterFlushControl) doOnDelete() {
+	fc.Lock() // synchronized
+	defer fc.Unlock()
+	// pass nil this is a global delete no update
+	fc.flushPolicy.onDelete(fc, nil)
+}
+
+/*
+Returns the number of delete terms in the global pool plus the ones
+already pushed to the BufferedUpdatesStream.
+*/
+func (fc *DocumentsWriterFlushControl) numGlobalTermDeletes() int {
+	return fc.documentsWriter.deleteQueue.numGlobalTermDeletes() +
+		int(atomic.LoadInt32(&fc.bufferedUpdatesStream.numTerms))
+}
+
+func (fc *DocumentsWriterFlushControl) setApplyAllDeletes() {
+	atomic.StoreInt32(&fc.flushDeletes, 1)
+}
+
 func (fc *DocumentsWriterFlushControl) getAndResetApplyAllDeletes() bool {
 	return atomic.SwapInt32(&fc.flushDeletes, 0) == 1
 }
@@ -28,7 +28,24 @@ func newDocumentsWriterFlushQueue() *DocumentsWriterFlushQueue {
 }
 
 func (fq *DocumentsWriterFlushQueue) addDeletes(deleteQueue *DocumentsWriterDeleteQueue) error {
-	panic("not implemented yet")
+	fq.Lock()
+	defer fq.Unlock()
+
+	fq.incTickets() // first inc the ticket count - freeze opens a window for #anyChanges to fail
+	var success = false
+	defer func() {
+		if !success {
+			fq.decTickets()
+		}
+	}()
+
+	globalDeletes, err := deleteQueue.freezeGlobalBuffer(nil)
+	if err != nil {
+		return err
+	}
+	fq.queue.PushBack(newGlobalDeletesTicket(globalDeletes))
+	success = true
+	return nil
 }
 
 func (fq *DocumentsWriterFlushQueue) incTickets() {
@@ -39,7 +56,7 @@ func (fq *DocumentsWriterFlushQueue) decTickets() {
 	assert(atomic.AddInt32(&fq._ticketCount, -1) >= 0)
 }
 
-func (fq *DocumentsWriterFlushQueue) addFlushTicket(dwpt *DocumentsWriterPerThread) *SegmentFlushTicket {
+func (fq *DocumentsWriterFlushQueue) addFlushTicket(dwpt *DocumentsWriterPerThread) (*SegmentFlushTicket, error) {
 	fq.Lock()
 	defer fq.Unlock()
 
@@ -53,10 +70,14 @@ func (fq *DocumentsWriterFlushQueue) addFlushTicket(dwpt *DocumentsWriterPerThre
 	}()
 
 	// prepare flush freezes the global deletes - do in synced block!
-	ticket := newSegmentFlushTicket(dwpt.prepareFlush())
+	globalDeletes, err := dwpt.prepareFlush()
+	if err != nil {
+		return nil, err
+	}
+	ticket := newSegmentFlushTicket(globalDeletes)
 	fq.queue.PushBack(ticket)
 	success = true
-	return ticket
+	return ticket, nil
 }
 
 func (q *DocumentsWriterFlushQueue) addSegment(ticket *SegmentFlushTicket, segment *FlushedSegment) {
@@ -187,6 +208,25 @@ func (t *FlushTicketImpl) finishFlush(indexWriter *IndexWriter,
 	return t.publishFlushedSegment(indexWriter, newSegment, bufferedUpdates)
 }
 
+type GlobalDeletesTicket struct {
+	*FlushTicketImpl
+}
+
+func newGlobalDeletesTicket(frozenUpdates *FrozenBufferedUpdates) *GlobalDeletesTicket {
+	return &GlobalDeletesTicket{newFlushTicket(frozenUpdates)}
+}
+
+func (ticket *GlobalDeletesTicket) publish(writer *IndexWriter) error {
+	assertn(!ticket.published, "ticket was already publised - can not publish twice")
+	ticket.published = true
+	// it's a global ticket - no segment to publish
+	return ticket.finishFlush(writer, nil, ticket.frozenUpdates)
+}
+
+func (ticket *GlobalDeletesTicket) canPublish() bool {
+	return true
+}
+
 type SegmentFlushTicket struct {
 	*FlushTicketImpl
 	segment *FlushedSegment
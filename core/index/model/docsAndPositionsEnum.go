@@ -5,5 +5,35 @@ const (
 	DOCS_POSITIONS_ENUM_FLAG_PAYLOADS = 2
 )
 
-type DocsAndPositionsEnum struct {
+// index/DocsAndPositionsEnum.java
+
+/*
+Also iterates through positions.
+*/
+type DocsAndPositionsEnum interface {
+	DocsEnum
+	/*
+		Returns the next position. You should only call this up to
+		DocsEnum.Freq() times else the behavior is not defined. If
+		positions were not indexed this will return -1; this only
+		happens if offsets were indexed and you passed needsOffset=true
+		when pulling the enum.
+	*/
+	NextPosition() (int, error)
+	/*
+		Returns start offset for the current position, or -1 if offsets
+		were not indexed.
+	*/
+	StartOffset() (int, error)
+	/*
+		Returns end offset for the current position, or -1 if offsets
+		were not indexed.
+	*/
+	EndOffset() (int, error)
+	/*
+		Returns the payload at this position, or nil if no payload was
+		indexed. You should not modify anything (neither members of the
+		returned slice nor its bytes).
+	*/
+	Payload() ([]byte, error)
 }
@@ -31,7 +31,7 @@ func NewFieldInfo(name string, indexed bool, number int32,
 	indexOptions IndexOptions, docValues, normsType DocValuesType,
 	dvGen int64, attributes map[string]string) *FieldInfo {
 
-	assert(indexOptions > 0)
+	assert(!indexed || indexOptions > 0)
 	assert(indexOptions <= INDEX_OPT_DOCS_AND_FREQS_AND_POSITIONS_AND_OFFSETS)
 
 	fi := &FieldInfo{Name: name, indexed: indexed, Number: number, docValueType: docValues}
@@ -101,7 +101,7 @@ func (info *FieldInfo) update(indexed, storeTermVector, omitNorms, storePayloads
 }
 
 func (info *FieldInfo) SetDocValueType(v DocValuesType) {
-	assert2(int(info.docValueType) != 0 && info.docValueType != v,
+	assert2(int(info.docValueType) == 0 || info.docValueType == v,
 		"cannot change DocValues type from %v to %v for field '%v'",
 		info.docValueType, v, info.Name)
 	info.docValueType = v
@@ -121,6 +121,16 @@ func (info *FieldInfo) DocValuesType() DocValuesType {
 	return info.docValueType
 }
 
+/* Sets the docValues generation of this field. */
+func (info *FieldInfo) SetDocValuesGen(dvGen int64) {
+	info.dvGen = dvGen
+	info.checkConsistency()
+}
+
+/*
+Returns the docValues generation of this field, or -1 if no docValues
+updates exist for it.
+*/
 func (info *FieldInfo) DocValuesGen() int64 {
 	return info.dvGen
 }
@@ -155,6 +155,55 @@ func (fn *FieldNumbers) addOrGet(name string, preferredNumber int, dv DocValuesT
 	return number
 }
 
+func (fn *FieldNumbers) verifyConsistent(number int, name string, dv DocValuesType) {
+	assert2(fn.numberToName[number] == name,
+		"field number %v is already mapped to field name \"%v\", not \"%v\"",
+		number, fn.numberToName[number], name)
+	n, ok := fn.nameToNumber[name]
+	assert2(ok && n == number,
+		"field name \"%v\" is already mapped to field number \"%v\", not \"%v\"",
+		name, n, number)
+	if currentDv := fn.docValuesType[name]; dv != 0 && currentDv != 0 {
+		assert2(currentDv == dv,
+			"cannot change DocValues type from %v to %v for field '%v'",
+			currentDv, dv, name)
+	}
+}
+
+/*
+Returns true if the field exists globally and was indexed with the
+given DocValuesType. Used by IndexWriter to validate doc values
+updates.
+*/
+func (fn *FieldNumbers) Contains(fieldName string, dvType DocValuesType) bool {
+	fn.Lock()
+	defer fn.Unlock()
+	if _, ok := fn.nameToNumber[fieldName]; !ok {
+		return false
+	}
+	// only return true if the field has the same dvType as the requested one
+	return fn.docValuesType[fieldName] == dvType
+}
+
+/*
+Records the DocValuesType of a field that was already added without
+doc values, e.g. because it was first seen as a plain indexed field.
+*/
+func (fn *FieldNumbers) SetDocValuesType(number int, name string, dv DocValuesType) {
+	fn.Lock()
+	defer fn.Unlock()
+	fn.verifyConsistent(number, name, dv)
+	fn.docValuesType[name] = dv
+}
+
+func (fn *FieldNumbers) Clear() {
+	fn.Lock()
+	defer fn.Unlock()
+	fn.numberToName = make(map[int]string)
+	fn.nameToNumber = make(map[string]int)
+	fn.docValuesType = make(map[string]DocValuesType)
+}
+
 type FieldInfosBuilder struct {
 	byName             map[string]*FieldInfo
 	globalFieldNumbers *FieldNumbers
@@ -168,6 +217,10 @@ func NewFieldInfosBuilder(globalFieldNumbers *FieldNumbers) *FieldInfosBuilder {
 	}
 }
 
+func (b *FieldInfosBuilder) GlobalFieldNumbers() *FieldNumbers {
+	return b.globalFieldNumbers
+}
+
 func assert(ok bool) {
 	assert2(ok, "assert fail")
 }
@@ -194,13 +247,39 @@ func (b *FieldInfosBuilder) AddOrUpdate(name string, fieldType IndexableFieldTyp
 		fieldType.IndexOptions(), fieldType.DocValueType(), DocValuesType(0))
 }
 
+/*
+Adds a copy of the given FieldInfo. NOTE: as in Lucene, this does not
+carry over attributes nor the docValues generation.
+*/
+func (b *FieldInfosBuilder) Add(fi *FieldInfo) *FieldInfo {
+	// IMPORTANT - reuse the field number if possible for consistent field numbers across segments
+	return b.addOrUpdateInternal(fi.Name, int(fi.Number), fi.indexed,
+		fi.storeTermVector, fi.omitNorms, fi.storePayloads,
+		fi.indexOptions, fi.docValueType, fi.normType)
+}
+
 func (b *FieldInfosBuilder) addOrUpdateInternal(name string,
 	preferredFieldNumber int, isIndexed bool, storeTermVector bool,
 	omitNorms bool, storePayloads bool, indexOptions IndexOptions,
 	docValues DocValuesType, normType DocValuesType) *FieldInfo {
 
 	if fi, ok := b.byName[name]; ok {
-		panic("not implemented yet")
+		fi.update(isIndexed, storeTermVector, omitNorms, storePayloads, indexOptions)
+
+		if docValues != 0 {
+			// only pay the synchronization cost if fi does not already have a DVType
+			if !fi.HasDocValues() {
+				// Must also update docValuesType map so it's aware of this
+				// field's DocValueType. This will panic if an illegal type
+				// change was attempted.
+				b.globalFieldNumbers.SetDocValuesType(int(fi.Number), name, docValues)
+			}
+			fi.SetDocValueType(docValues) // this will also perform the consistency check.
+		}
+
+		if !fi.omitNorms && normType != 0 {
+			fi.SetNormValueType(normType)
+		}
 		return fi
 	} else {
 		// This field wasn't yet added to this in-RAM segment's
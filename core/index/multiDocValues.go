@@ -0,0 +1,143 @@
+package index
+
+import (
+	"bytes"
+	"container/heap"
+	. "github.com/gzg1984/golucene/core/codec/spi"
+)
+
+// index/MultiDocValues.java
+
+/*
+Maps per-segment ordinals to/from global ordinal space.
+
+Global ordinals are the ordinals of the values of all segments,
+merged and sorted: the global ordinal of a value is the same in all
+segments, so ordinals of different segments can be compared or
+counted together.
+*/
+type OrdinalMap struct {
+	// cache key of whoever asked for this awful thing
+	owner interface{}
+	// segment ord -> global ord, for each segment
+	globalOrds [][]int64
+	// global ord -> first segment containing the value
+	firstSegments []int
+	// global ord -> ord of the value in its first segment
+	firstSegmentOrds []int64
+}
+
+/*
+Creates an ordinal map that allows mapping ords to/from a merged
+space from subs.
+
+owner is a cache key; subs are the SortedSetDocValues of each
+segment, in order. A nil sub is treated like a segment without any
+value.
+*/
+func NewOrdinalMap(owner interface{}, subs []SortedSetDocValues) *OrdinalMap {
+	m := &OrdinalMap{
+		owner:      owner,
+		globalOrds: make([][]int64, len(subs)),
+	}
+	queue := make(ordinalMapQueue, 0, len(subs))
+	for i, sub := range subs {
+		if sub == nil {
+			continue
+		}
+		m.globalOrds[i] = make([]int64, sub.ValueCount())
+		if sub.ValueCount() > 0 {
+			queue = append(queue, &ordinalMapSegment{i, sub, 0, sub.LookupOrd(0)})
+		}
+	}
+	heap.Init(&queue)
+
+	var current []byte
+	globalOrd := int64(-1)
+	for len(queue) > 0 {
+		top := queue[0]
+		if globalOrd < 0 || !bytes.Equal(top.value, current) {
+			// a new value, first seen in this segment
+			globalOrd++
+			current = append(current[:0], top.value...)
+			m.firstSegments = append(m.firstSegments, top.index)
+			m.firstSegmentOrds = append(m.firstSegmentOrds, top.ord)
+		}
+		m.globalOrds[top.index][top.ord] = globalOrd
+
+		if top.ord++; top.ord < top.values.ValueCount() {
+			top.value = top.values.LookupOrd(top.ord)
+			heap.Fix(&queue, 0)
+		} else {
+			heap.Pop(&queue)
+		}
+	}
+	return m
+}
+
+/* Given a segment number and segment ordinal, returns the corresponding global ordinal. */
+func (m *OrdinalMap) GlobalOrd(segmentIndex int, segmentOrd int64) int64 {
+	return m.globalOrds[segmentIndex][segmentOrd]
+}
+
+/*
+Returns the global ordinals of the values of a segment, indexed by
+their segment ordinals. The returned slice must not be modified.
+*/
+func (m *OrdinalMap) GlobalOrds(segmentIndex int) []int64 {
+	return m.globalOrds[segmentIndex]
+}
+
+/*
+Given global ordinal, returns the index of the first segment that
+contains this term.
+*/
+func (m *OrdinalMap) FirstSegmentNumber(globalOrd int64) int {
+	return m.firstSegments[globalOrd]
+}
+
+/*
+Given global ordinal, returns the ordinal of the first segment which
+contains this ordinal (the corresponding to the segment return
+FirstSegmentNumber()).
+*/
+func (m *OrdinalMap) FirstSegmentOrd(globalOrd int64) int64 {
+	return m.firstSegmentOrds[globalOrd]
+}
+
+/* Returns the total number of unique terms in global ord space. */
+func (m *OrdinalMap) ValueCount() int64 {
+	return int64(len(m.firstSegments))
+}
+
+func (m *OrdinalMap) Owner() interface{} {
+	return m.owner
+}
+
+/* The current value of a segment, during the merge of all values. */
+type ordinalMapSegment struct {
+	index  int
+	values SortedSetDocValues
+	ord    int64
+	value  []byte
+}
+
+/* Orders segments by their current value, then by segment index. */
+type ordinalMapQueue []*ordinalMapSegment
+
+func (q ordinalMapQueue) Len() int { return len(q) }
+func (q ordinalMapQueue) Less(i, j int) bool {
+	if cmp := bytes.Compare(q[i].value, q[j].value); cmp != 0 {
+		return cmp < 0
+	}
+	return q[i].index < q[j].index
+}
+func (q ordinalMapQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *ordinalMapQueue) Push(x interface{}) { *q = append(*q, x.(*ordinalMapSegment)) }
+func (q *ordinalMapQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
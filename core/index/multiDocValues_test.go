@@ -0,0 +1,44 @@
+package index
+
+import (
+	. "github.com/gzg1984/golucene/core/codec/spi"
+	"testing"
+)
+
+/* In-memory SortedSetDocValues with sorted values and no document. */
+type valuesOnlySortedSet []string
+
+func (v valuesOnlySortedSet) NextOrd() int64             { return -1 }
+func (v valuesOnlySortedSet) SetDocument(docID int)      {}
+func (v valuesOnlySortedSet) LookupOrd(ord int64) []byte { return []byte(v[ord]) }
+func (v valuesOnlySortedSet) ValueCount() int64          { return int64(len(v)) }
+
+func TestOrdinalMap(t *testing.T) {
+	seg0 := valuesOnlySortedSet{"b", "d"}
+	seg2 := valuesOnlySortedSet{"a", "b", "c", "e"}
+	m := NewOrdinalMap("owner", []SortedSetDocValues{seg0, nil, valuesOnlySortedSet{}, seg2})
+
+	if m.ValueCount() != 5 {
+		t.Errorf("expected 5 global values, got %v", m.ValueCount())
+	}
+	expected := map[int][]int64{0: {1, 3}, 1: {}, 2: {}, 3: {0, 1, 2, 4}}
+	for seg, globalOrds := range expected {
+		if len(m.GlobalOrds(seg)) != len(globalOrds) {
+			t.Errorf("segment %v: expected %v, got %v", seg, globalOrds, m.GlobalOrds(seg))
+			continue
+		}
+		for ord, globalOrd := range globalOrds {
+			if m.GlobalOrd(seg, int64(ord)) != globalOrd {
+				t.Errorf("segment %v ord %v: expected global ord %v, got %v",
+					seg, ord, globalOrd, m.GlobalOrd(seg, int64(ord)))
+			}
+		}
+	}
+	for globalOrd, value := range []string{"a", "b", "c", "d", "e"} {
+		seg, ord := m.FirstSegmentNumber(int64(globalOrd)), m.FirstSegmentOrd(int64(globalOrd))
+		sub := map[int]valuesOnlySortedSet{0: seg0, 3: seg2}[seg]
+		if sub == nil || string(sub.LookupOrd(ord)) != value {
+			t.Errorf("global ord %v: expected %q, found segment %v ord %v", globalOrd, value, seg, ord)
+		}
+	}
+}
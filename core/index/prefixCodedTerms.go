@@ -17,28 +17,108 @@ func (terms *PrefixCodedTerms) RamBytesUsed() int64 {
 	return terms.buffer.RamBytesUsed()
 }
 
+/*
+Returns an iterator over the terms, in the order they were added.
+The iterator returns nil once all terms are consumed. Each returned
+Term is a new instance and can be kept by the caller.
+*/
+func (terms *PrefixCodedTerms) iterator() (func() (*Term, error), error) {
+	input, err := store.NewRAMInputStream("PrefixCodedTermsIterator", terms.buffer)
+	if err != nil {
+		return nil, err
+	}
+	var field string
+	var bytes []byte
+	return func() (*Term, error) {
+		if input.FilePointer() >= input.Length() {
+			return nil, nil
+		}
+		code, err := input.ReadVInt()
+		if err != nil {
+			return nil, err
+		}
+		if (code & 1) != 0 {
+			// new field
+			if field, err = input.ReadString(); err != nil {
+				return nil, err
+			}
+		}
+		prefix := int(uint32(code) >> 1)
+		suffix, err := input.ReadVInt()
+		if err != nil {
+			return nil, err
+		}
+		next := make([]byte, prefix+int(suffix))
+		copy(next, bytes[:prefix])
+		if err = input.ReadBytes(next[prefix:]); err != nil {
+			return nil, err
+		}
+		bytes = next
+		return NewTermFromBytes(field, bytes), nil
+	}, nil
+}
+
 /* Builds a PrefixCodedTerms: call add repeatedly, then finish. */
 type PrefixCodedTermsBuilder struct {
-	buffer *store.RAMFile
-	output *store.RAMOutputStream
+	buffer   *store.RAMFile
+	output   *store.RAMOutputStream
+	lastTerm *Term
 }
 
 func newPrefixCodedTermsBuilder() *PrefixCodedTermsBuilder {
 	f := store.NewRAMFileBuffer()
 	return &PrefixCodedTermsBuilder{
-		buffer: f,
-		output: store.NewRAMOutputStream(f, false),
+		buffer:   f,
+		output:   store.NewRAMOutputStream(f, false),
+		lastTerm: NewEmptyTerm(""),
 	}
 }
 
-func (b *PrefixCodedTermsBuilder) add(term *Term) {
-	panic("not implemented yet")
+/* add a term */
+func (b *PrefixCodedTermsBuilder) add(term *Term) error {
+	assert(b.lastTerm.Field == "" && len(b.lastTerm.Bytes) == 0 ||
+		TermSorter([]*Term{b.lastTerm, term}).Less(0, 1))
+	prefix := sharedPrefix(b.lastTerm.Bytes, term.Bytes)
+	suffix := len(term.Bytes) - prefix
+	if term.Field == b.lastTerm.Field {
+		if err := b.output.WriteVInt(int32(prefix << 1)); err != nil {
+			return err
+		}
+	} else {
+		if err := b.output.WriteVInt(int32(prefix<<1 | 1)); err != nil {
+			return err
+		}
+		if err := b.output.WriteString(term.Field); err != nil {
+			return err
+		}
+	}
+	if err := b.output.WriteVInt(int32(suffix)); err != nil {
+		return err
+	}
+	if err := b.output.WriteBytes(term.Bytes[prefix:]); err != nil {
+		return err
+	}
+	b.lastTerm = NewTermFromBytes(term.Field, append([]byte(nil), term.Bytes...))
+	return nil
 }
 
-func (b *PrefixCodedTermsBuilder) finish() *PrefixCodedTerms {
-	err := b.output.Close()
-	if err != nil {
-		panic(err)
+/* return finalized form */
+func (b *PrefixCodedTermsBuilder) finish() (*PrefixCodedTerms, error) {
+	if err := b.output.Close(); err != nil {
+		return nil, err
+	}
+	return newPrefixCodedTerms(b.buffer), nil
+}
+
+func sharedPrefix(term1, term2 []byte) int {
+	end := len(term1)
+	if len(term2) < end {
+		end = len(term2)
+	}
+	for i := 0; i < end; i++ {
+		if term1[i] != term2[i] {
+			return i
+		}
 	}
-	return newPrefixCodedTerms(b.buffer)
+	return end
 }
@@ -17,8 +17,18 @@ import (
 type IndexReader interface {
 	io.Closer
 	decRef() error
+	IncRef()
+	TryIncRef() bool
+	DecRef() error
+	RefCount() int
+	AddReaderClosedListener(ReaderClosedListener)
+	RemoveReaderClosedListener(ReaderClosedListener)
 	ensureOpen()
 	registerParentReader(r IndexReader)
+	// Retrieve term vectors for this document, or nil if term vectors
+	// were not indexed. The returned Fields instance acts like a
+	// single-document inverted index (the docID will be 0).
+	TermVectors(docID int) (Fields, error)
 	NumDocs() int
 	MaxDoc() int
 	/** Expert: visits the fields of a stored document, for
@@ -61,10 +71,11 @@ type IndexReader interface {
 
 /* A custom listener that's invoked when the IndexReader is closed. */
 type ReaderClosedListener interface {
-	onClose(IndexReader)
+	OnClose(IndexReader)
 }
 
 type IndexReaderImplSPI interface {
+	TermVectors(int) (Fields, error)
 	NumDocs() int
 	MaxDoc() int
 	VisitDocument(int, StoredFieldVisitor) error
@@ -94,10 +105,86 @@ func newIndexReader(spi IndexReaderImplSPI) *IndexReaderImpl {
 	}
 }
 
+/*
+Expert: increments the refCount of this IndexReader instance.
+RefCounts are used to determine when a reader can be closed safely,
+i.e. as soon as there are no more references. Be sure to always call
+a corresponding decRef(), in a defer statement; otherwise the reader
+may never be closed.
+*/
+func (r *IndexReaderImpl) incRef() {
+	r.ensureOpen()
+	atomic.AddInt32(&r.refCount, 1)
+}
+
+/*
+Expert: increments the refCount of this IndexReader instance only if
+the IndexReader has not been closed yet and returns true iff the
+refCount was successfully incremented, otherwise false. If this method
+returns false the reader is either already closed or is currently
+being closed. Either way this reader instance shouldn't be used by an
+application unless true is returned.
+
+RefCounts are used to determine when a reader can be closed safely,
+i.e. as soon as there are no more references. Be sure to always call
+a corresponding DecRef(), in a defer statement; otherwise the reader
+may never be closed.
+*/
+func (r *IndexReaderImpl) TryIncRef() bool {
+	for count := atomic.LoadInt32(&r.refCount); count > 0; count = atomic.LoadInt32(&r.refCount) {
+		if atomic.CompareAndSwapInt32(&r.refCount, count, count+1) {
+			return true
+		}
+	}
+	return false
+}
+
+/* Expert: increments the refCount of this IndexReader instance. */
+func (r *IndexReaderImpl) IncRef() {
+	r.incRef()
+}
+
+/*
+Expert: decreases the refCount of this IndexReader instance. If the
+refCount drops to 0, then this reader is closed. If an error is hit,
+the refCount is unchanged.
+*/
+func (r *IndexReaderImpl) DecRef() error {
+	return r.decRef()
+}
+
+/* Expert: returns the current refCount for this reader */
+func (r *IndexReaderImpl) RefCount() int {
+	// NOTE: don't ensureOpen, so that callers can see refCount is 0
+	// (reader is closed)
+	return int(atomic.LoadInt32(&r.refCount))
+}
+
+/*
+Expert: adds a ReaderClosedListener. The provided listener will be
+invoked when this reader is closed.
+*/
+func (r *IndexReaderImpl) AddReaderClosedListener(listener ReaderClosedListener) {
+	r.ensureOpen()
+	r.readerClosedListenersLock.Lock()
+	defer r.readerClosedListenersLock.Unlock()
+	if r.readerClosedListeners == nil {
+		r.readerClosedListeners = make(map[ReaderClosedListener]bool)
+	}
+	r.readerClosedListeners[listener] = true
+}
+
+/* Expert: remove a previously added ReaderClosedListener. */
+func (r *IndexReaderImpl) RemoveReaderClosedListener(listener ReaderClosedListener) {
+	r.readerClosedListenersLock.Lock()
+	defer r.readerClosedListenersLock.Unlock()
+	delete(r.readerClosedListeners, listener)
+}
+
 func (r *IndexReaderImpl) decRef() error {
 	// only check refcount here (don't call ensureOpen()), so we can
 	// still close the reader if it was made invalid by a child:
-	assert2(r.refCount > 0, "this IndexReader is closed")
+	assert2(atomic.LoadInt32(&r.refCount) > 0, "this IndexReader is closed")
 
 	rc := atomic.AddInt32(&r.refCount, -1)
 	assert2(rc >= 0, "too many decRef calls: refCount is %v after decrement", rc)
@@ -141,7 +228,11 @@ func (r *IndexReaderImpl) notifyReaderClosedListeners(err error) {
 					err = mergeError(err, errors.New(fmt.Sprintf("%v", e)))
 				}
 			}()
-			listener.onClose(r)
+			if reader, ok := r.IndexReaderImplSPI.(IndexReader); ok {
+				listener.OnClose(reader)
+			} else {
+				listener.OnClose(r)
+			}
 		}()
 	}
 	return
@@ -244,6 +335,22 @@ type ARFieldsReader interface {
 	 *  were indexed. The returned instance should only be
 	 *  used by a single thread. */
 	NormValues(field string) (ndv NumericDocValues, err error)
+	// Returns NumericDocValues for this field, or nil if no
+	// NumericDocValues were indexed for this field.
+	NumericDocValues(field string) (v NumericDocValues, err error)
+	// Returns BinaryDocValues for this field, or nil if no
+	// BinaryDocValues were indexed for this field.
+	BinaryDocValues(field string) (v BinaryDocValues, err error)
+	// Returns SortedDocValues for this field, or nil if no
+	// SortedDocValues were indexed for this field.
+	SortedDocValues(field string) (v SortedDocValues, err error)
+	// Returns SortedSetDocValues for this field, or nil if no
+	// SortedSetDocValues were indexed for this field.
+	SortedSetDocValues(field string) (v SortedSetDocValues, err error)
+	// Returns a util.Bits at the size of reader.MaxDoc(), with turned
+	// on bits for each docid that does have a value for this field, or
+	// nil if no DocValues were indexed for this field.
+	DocsWithField(field string) (bits util.Bits, err error)
 }
 
 type AtomicReader interface {
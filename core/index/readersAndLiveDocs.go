@@ -1,10 +1,14 @@
 package index
 
 import (
+	"fmt"
 	. "github.com/gzg1984/golucene/core/codec/spi"
+	docu "github.com/gzg1984/golucene/core/document"
+	. "github.com/gzg1984/golucene/core/index/model"
 	"github.com/gzg1984/golucene/core/store"
 	"github.com/gzg1984/golucene/core/util"
 	"log"
+	"strconv"
 	"sync"
 	"sync/atomic"
 )
@@ -72,7 +76,13 @@ type ReadersAndUpdates struct {
 }
 
 func newReadersAndUpdates(writer *IndexWriter, info *SegmentCommitInfo) *ReadersAndUpdates {
-	return &ReadersAndUpdates{info: info, writer: writer, liveDocsShared: true}
+	return &ReadersAndUpdates{
+		Locker:         &sync.Mutex{},
+		refCountMixin:  newRefCountMixin(),
+		info:           info,
+		writer:         writer,
+		liveDocsShared: true,
+	}
 }
 
 func (rld *ReadersAndUpdates) pendingDeleteCount() int {
@@ -85,11 +95,36 @@ func (rld *ReadersAndUpdates) pendingDeleteCount() int {
 Get reader for searching/deleting
 */
 func (rld *ReadersAndUpdates) reader(ctx store.IOContext) (*SegmentReader, error) {
-	panic("not implemented yet")
+	rld.Lock()
+	defer rld.Unlock()
+
+	if err := rld.initReader(ctx); err != nil {
+		return nil, err
+	}
+	// Ref for caller
+	rld._reader.incRef()
+	return rld._reader, nil
+}
+
+/* Opens the pooled reader, if not already open. Requires rld's lock. */
+func (rld *ReadersAndUpdates) initReader(ctx store.IOContext) error {
+	if rld._reader == nil {
+		// We steal returned ref:
+		r, err := NewSegmentReader(rld.info, rld.writer.config.ReaderTermsIndexDivisor(), ctx)
+		if err != nil {
+			return err
+		}
+		rld._reader = r
+		if rld._liveDocs == nil {
+			rld._liveDocs = r.LiveDocs()
+		}
+	}
+	return nil
 }
 
 func (rld *ReadersAndUpdates) release(sr *SegmentReader) error {
-	panic("not implemented yet")
+	assert(rld.info == sr.si)
+	return sr.decRef()
 }
 
 // NOTE: removes callers ref
@@ -133,18 +168,78 @@ func (rld *ReadersAndUpdates) liveDocs() util.Bits {
 	return rld._liveDocs
 }
 
+/* Returns true if the doc was deleted by this call. */
+func (rld *ReadersAndUpdates) delete(docID int) bool {
+	rld.Lock()
+	defer rld.Unlock()
+
+	assert(rld._liveDocs != nil)
+	assert2(docID >= 0 && docID < rld._liveDocs.Length(),
+		"out of bounds: docid=%v liveDocsLength=%v seg=%v docCount=%v",
+		docID, rld._liveDocs.Length(), rld.info.Info.Name, rld.info.Info.DocCount())
+	assert(!rld.liveDocsShared)
+	didDelete := rld._liveDocs.At(docID)
+	if didDelete {
+		rld._liveDocs.(util.MutableBits).Clear(docID)
+		rld._pendingDeleteCount++
+	}
+	return didDelete
+}
+
+func (rld *ReadersAndUpdates) initWritableLiveDocs() {
+	rld.Lock()
+	defer rld.Unlock()
+
+	assert(rld.info.Info.DocCount() > 0)
+	if rld.liveDocsShared {
+		// Copy on write: this means we've cloned a SegmentReader
+		// sharing the current liveDocs instance; must now make a
+		// private clone so we can change it:
+		liveDocsFormat := rld.info.Info.Codec().(Codec).LiveDocsFormat()
+		if rld._liveDocs == nil {
+			rld._liveDocs = liveDocsFormat.NewLiveDocs(rld.info.Info.DocCount())
+		} else {
+			rld._liveDocs = liveDocsFormat.NewLiveDocsFrom(rld._liveDocs)
+		}
+		rld.liveDocsShared = false
+	}
+}
+
+/*
+Returns a ref to a clone. NOTE: you should decRef() the reader when
+you're done (ie do not call Close()).
+*/
+func (rld *ReadersAndUpdates) readOnlyClone(ctx store.IOContext) (*SegmentReader, error) {
+	rld.Lock()
+	defer rld.Unlock()
+
+	if err := rld.initReader(ctx); err != nil {
+		return nil, err
+	}
+	// force new liveDocs in initWritableLiveDocs even if it's nil
+	rld.liveDocsShared = true
+	if rld._liveDocs != nil {
+		return newSegmentReaderFrom(rld._reader.si, rld._reader, rld._liveDocs,
+			rld.info.Info.DocCount()-rld.info.DelCount()-rld._pendingDeleteCount)
+	}
+	// liveDocs == nil and reader != nil. That can only be if there
+	// are no deletes
+	assert(rld._reader.LiveDocs() == nil)
+	rld._reader.incRef()
+	return rld._reader, nil
+}
+
 /*
 Commit live docs (writes new _X_N.del files) and field update (writes
 new _X_N.del files) to the directory; returns true if it wrote any
 file and false if there were no new deletes or updates to write:
 */
 func (rld *ReadersAndUpdates) writeLiveDocs(dir store.Directory) (bool, error) {
-	panic("not implemented yet")
 	rld.Lock()
 	defer rld.Unlock()
 
-	log.Printf("rld.writeLiveDocs seg=%v pendingDelCount=%v", rld.info, rld._pendingDeleteCount)
 	if rld._pendingDeleteCount != 0 {
+		log.Printf("rld.writeLiveDocs seg=%v pendingDelCount=%v", rld.info, rld._pendingDeleteCount)
 		// We have new deletes
 		assert(rld._liveDocs.Length() == rld.info.Info.DocCount())
 
@@ -189,12 +284,246 @@ func (rld *ReadersAndUpdates) writeLiveDocs(dir store.Directory) (bool, error) {
 	return false, nil
 }
 
-/* Writes field updates (new _X_N updates files) to the directory */
-func (r *ReadersAndUpdates) writeFieldUpdates(dir store.Directory,
-	dvUpdates *DocValuesFieldUpdatesContainer) error {
-	panic("not implemented yet")
+/*
+Writes field updates (new _X_N updates files) to the directory. Each
+updated field is written to its own doc values generation, and a new
+generation of the FieldInfos records the fields' doc values gens.
+*/
+func (rld *ReadersAndUpdates) writeFieldUpdates(dir store.Directory,
+	dvUpdates *DocValuesFieldUpdatesContainer) (err error) {
+
+	rld.Lock()
+	defer rld.Unlock()
+
+	assert(dvUpdates.any())
+
+	// Do this so we can delete any created files on error; this saves
+	// all codecs from having to do it:
+	trackingDir := store.NewTrackingDirectoryWrapper(dir)
+
+	newDVFiles := make(map[int]map[string]bool)
+	var fieldInfosFiles map[string]bool
+	var success = false
+	defer func() {
+		if !success {
+			// Advance only the nextWriteFieldInfosGen and
+			// nextWriteDocValuesGen, so that a 2nd attempt to write will
+			// write to a new file
+			rld.info.AdvanceNextWriteFieldInfosGen()
+			rld.info.AdvanceNextWriteDocValuesGen()
+
+			// Delete any partially created file(s):
+			trackingDir.EachCreatedFiles(func(filename string) {
+				dir.DeleteFile(filename) // ignore error
+			})
+		}
+	}()
+
+	codec := rld.info.Info.Codec().(Codec)
+	// reader could be nil e.g. for a just merged segment
+	reader := rld._reader
+	if reader == nil {
+		if reader, err = NewSegmentReader(rld.info,
+			rld.writer.config.ReaderTermsIndexDivisor(), store.IO_CONTEXT_READONCE); err != nil {
+			return err
+		}
+		defer func() {
+			err = mergeError(err, reader.Close())
+		}()
+	}
+
+	// clone FieldInfos so that we can update their dvGen separately
+	// from the reader's infos and write them to a new fieldInfos_gen
+	// file
+	builder := NewFieldInfosBuilder(rld.writer.globalFieldNumberMap)
+	// cannot use builder.Add() alone because it does not clone
+	// FI.attributes as well FI.dvGen
+	for _, fi := range reader.FieldInfos().Values {
+		clone := builder.Add(fi)
+		// copy the stuff FieldInfosBuilder doesn't copy
+		for k, v := range fi.Attributes() {
+			clone.PutAttribute(k, v)
+		}
+		clone.SetDocValuesGen(fi.DocValuesGen())
+	}
+	// create new fields or update existing ones to have NumericDV type
+	for f, _ := range dvUpdates.numericDVUpdates {
+		builder.AddOrUpdate(f, docu.NUMERIC_DOC_VALUES_FIELD_TYPE)
+	}
+	// create new fields or update existing ones to have BinaryDV type
+	for f, _ := range dvUpdates.binaryDVUpdates {
+		builder.AddOrUpdate(f, docu.BINARY_DOC_VALUES_FIELD_TYPE)
+	}
+	fieldInfos := builder.Finish()
+
+	dvFormat := codec.DocValuesFormat()
+	for _, typ := range []DocValuesType{DOC_VALUES_TYPE_NUMERIC, DOC_VALUES_TYPE_BINARY} {
+		for field, fieldUpdates := range dvUpdates.updatesOf(typ) {
+			fieldInfo := fieldInfos.FieldInfoByName(field)
+			assert(fieldInfo != nil)
+
+			nextDocValuesGen := rld.info.NextDocValuesGen()
+			segmentSuffix := strconv.FormatInt(nextDocValuesGen, 36)
+			fieldInfo.SetDocValuesGen(nextDocValuesGen)
+			// separately also track which files were created for this gen
+			fieldTrackingDir := store.NewTrackingDirectoryWrapper(trackingDir)
+			state := NewSegmentWriteState2(nil, fieldTrackingDir, rld.info.Info,
+				NewFieldInfos([]*FieldInfo{fieldInfo}),
+				rld.writer.config.TermIndexInterval(), nil,
+				store.IO_CONTEXT_DEFAULT, segmentSuffix)
+			if err = writeDocValuesUpdates(dvFormat, state, reader,
+				fieldInfo, fieldUpdates); err != nil {
+				return err
+			}
+			rld.info.AdvanceDocValuesGen()
+
+			files := make(map[string]bool)
+			fieldTrackingDir.EachCreatedFiles(func(filename string) {
+				files[filename] = true
+			})
+			_, ok := newDVFiles[int(fieldInfo.Number)]
+			assert(!ok)
+			newDVFiles[int(fieldInfo.Number)] = files
+		}
+	}
+
+	// write the new FieldInfos generation
+	fieldInfosTrackingDir := store.NewTrackingDirectoryWrapper(trackingDir)
+	segmentSuffix := strconv.FormatInt(rld.info.NextFieldInfosGen(), 36)
+	if err = codec.FieldInfosFormat().FieldInfosWriter()(fieldInfosTrackingDir,
+		rld.info.Info.Name, segmentSuffix, fieldInfos, store.IO_CONTEXT_DEFAULT); err != nil {
+		return err
+	}
+	rld.info.AdvanceFieldInfosGen()
+	fieldInfosFiles = make(map[string]bool)
+	fieldInfosTrackingDir.EachCreatedFiles(func(filename string) {
+		fieldInfosFiles[filename] = true
+	})
+	success = true
+
+	// writing field updates succeeded
+	rld.info.SetFieldInfosFiles(fieldInfosFiles)
+
+	// update the doc-values updates files. The files map each field to
+	// its set of files, hence we copy from the existing map all fields
+	// w/ updates that were not updated in this session, and add new
+	// mappings for fields that were updated now.
+	assert(len(newDVFiles) > 0)
+	for number, files := range rld.info.DocValuesUpdatesFiles() {
+		if _, ok := newDVFiles[number]; !ok {
+			newDVFiles[number] = files
+		}
+	}
+	rld.info.SetDocValuesUpdatesFiles(newDVFiles)
+
+	// checkpoint the writer, so that it can delete unreferenced files.
+	// The writer's lock is already held by the caller; ours is released
+	// meanwhile since the deleter asks for our pending delete count.
+	rld.Unlock()
+	err = rld.writer._checkpoint()
+	rld.Lock()
+	if err != nil {
+		return err
+	}
+
+	// if there is a reader open, reopen it to reflect the updates
+	if rld._reader != nil {
+		newReader, err := newSegmentReaderFrom(rld.info, rld._reader, rld._liveDocs,
+			rld.info.Info.DocCount()-rld.info.DelCount()-rld._pendingDeleteCount)
+		if err != nil {
+			return err
+		}
+		old := rld._reader
+		rld._reader = newReader
+		if err = old.decRef(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+Writes a single field's doc values, merging the segment's current
+values with the given updates, using the given write state.
+*/
+func writeDocValuesUpdates(dvFormat DocValuesFormat, state *SegmentWriteState,
+	reader *SegmentReader, fieldInfo *FieldInfo,
+	fieldUpdates *DocValuesFieldUpdates) (err error) {
+
+	field := fieldInfo.Name
+	maxDoc := reader.MaxDoc()
+	docsWithField, err := reader.DocsWithField(field)
+	if err != nil {
+		return err
+	}
+
+	// current returns the current value of the document, or nil if it
+	// did not have a value before
+	var current func(doc int) interface{}
+	switch fieldUpdates.typ {
+	case DOC_VALUES_TYPE_NUMERIC:
+		currentValues, err := reader.NumericDocValues(field)
+		if err != nil {
+			return err
+		}
+		current = func(doc int) interface{} {
+			if currentValues != nil && docsWithField.At(doc) {
+				return currentValues(doc)
+			}
+			return nil
+		}
+	case DOC_VALUES_TYPE_BINARY:
+		currentValues, err := reader.BinaryDocValues(field)
+		if err != nil {
+			return err
+		}
+		current = func(doc int) interface{} {
+			if currentValues != nil && docsWithField.At(doc) {
+				return currentValues.Get(doc)
+			}
+			return nil
+		}
+	default:
+		panic(fmt.Sprintf("unsupported type: %v", fieldUpdates.typ))
+	}
+
+	values := func() func() (interface{}, bool) {
+		updatesIter := fieldUpdates.iterator()
+		curDoc, updateDoc := -1, updatesIter.nextDoc()
+		return func() (interface{}, bool) {
+			if curDoc+1 >= maxDoc {
+				return nil, false
+			}
+			curDoc++
+			if curDoc == updateDoc {
+				// this document has an updated value; either nil (unset
+				// value) or the updated value
+				value := updatesIter.value()
+				updateDoc = updatesIter.nextDoc() // prepare for next round
+				return value, true
+			}
+			// no update for this document
+			assert(curDoc < updateDoc)
+			return current(curDoc), true
+		}
+	}
+
+	fieldsConsumer, err := dvFormat.FieldsConsumer(state)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = mergeError(err, fieldsConsumer.Close())
+	}()
+	if fieldUpdates.typ == DOC_VALUES_TYPE_NUMERIC {
+		return fieldsConsumer.AddNumericField(fieldInfo, values)
+	}
+	return fieldsConsumer.AddBinaryField(fieldInfo, values)
 }
 
 func (rld *ReadersAndUpdates) String() string {
-	panic("not implemented yet")
+	rld.Lock()
+	defer rld.Unlock()
+	return fmt.Sprintf("ReadersAndLiveDocs(seg=%v pendingDeleteCount=%v liveDocsShared=%v)",
+		rld.info, rld._pendingDeleteCount, rld.liveDocsShared)
 }
@@ -506,10 +506,15 @@ func (sis *SegmentInfos) Read(directory store.Directory, segmentFileName string)
 					if numDVFields, err = asInt(input.ReadInt()); err != nil {
 						return err
 					}
-					if numDVFields == 0 {
-						dvUpdatesFiles = make(map[int]map[string]bool)
-					} else {
-						panic("not implemented yet")
+					dvUpdatesFiles = make(map[int]map[string]bool)
+					for i := 0; i < numDVFields; i++ {
+						var number int
+						if number, err = asInt(input.ReadInt()); err != nil {
+							return err
+						}
+						if dvUpdatesFiles[number], err = input.ReadStringSet(); err != nil {
+							return err
+						}
 					}
 					siPerCommit.SetDocValuesUpdatesFiles(dvUpdatesFiles)
 				}
@@ -851,6 +856,21 @@ func (sis *SegmentInfos) finishCommit(dir store.Directory) (fileName string, err
 	return
 }
 
+/*
+Writes & syncs to the Directory dir, taking care to remove the
+segments file on error.
+
+Note: changed() should be called prior to this method if changes have
+been made to this SegmentInfos instance.
+*/
+func (sis *SegmentInfos) commit(dir store.Directory) error {
+	if err := sis.prepareCommit(dir); err != nil {
+		return err
+	}
+	_, err := sis.finishCommit(dir)
+	return err
+}
+
 // L1041
 /*
 Replaces all segments in this instance in this instance, but keeps
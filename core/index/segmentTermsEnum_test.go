@@ -2,6 +2,7 @@ package index
 
 import (
 	_ "github.com/gzg1984/golucene/core/codec/lucene42"
+	. "github.com/gzg1984/golucene/core/index/model"
 	"github.com/gzg1984/golucene/core/store"
 	"testing"
 )
@@ -37,3 +38,42 @@ func TestInitTerm(t *testing.T) {
 		t.Error("SeekExact should return true.")
 	}
 }
+
+func TestSeekCeil(t *testing.T) {
+	d, err := store.OpenFSDirectory("../search/testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	termsEnum := r.Context().Leaves()[0].reader.Fields().Terms("content").Iterator(nil)
+
+	for _, v := range []struct {
+		target string
+		status SeekStatus
+		term   string
+	}{
+		{"fruit", SEEK_STATUS_FOUND, "fruit"},
+		{"fru", SEEK_STATUS_NOT_FOUND, "fruit"},
+		{"bat", SEEK_STATUS_FOUND, "bat"},
+		{"guan", SEEK_STATUS_NOT_FOUND, "guano"},
+		{"sonar", SEEK_STATUS_FOUND, "sonar"},
+		{"zzzzz", SEEK_STATUS_END, ""},
+		{"guano", SEEK_STATUS_FOUND, "guano"},
+	} {
+		status, err := termsEnum.SeekCeil([]byte(v.target))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != v.status {
+			t.Errorf("SeekCeil(%v) should return %v, but was %v", v.target, v.status, status)
+			continue
+		}
+		if status != SEEK_STATUS_END && string(termsEnum.Term()) != v.term {
+			t.Errorf("SeekCeil(%v) should be positioned on %v, but was %v",
+				v.target, v.term, string(termsEnum.Term()))
+		}
+	}
+}
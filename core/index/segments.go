@@ -11,6 +11,7 @@ import (
 	. "github.com/gzg1984/golucene/core/index/model"
 	"github.com/gzg1984/golucene/core/store"
 	"github.com/gzg1984/golucene/core/util"
+	"io"
 	"sync/atomic"
 )
 
@@ -34,6 +35,11 @@ type SegmentReader struct {
 	core    *SegmentCoreReaders
 
 	fieldInfos FieldInfos
+
+	// per-field DocValuesProducer, key'd by field name; fields updated
+	// in the same generation share the same producer
+	dvProducersByField map[string]DocValuesProducer
+	dvProducers        []DocValuesProducer
 }
 
 /**
@@ -45,7 +51,7 @@ type SegmentReader struct {
 func NewSegmentReader(si *SegmentCommitInfo,
 	termInfosIndexDivisor int, context store.IOContext) (r *SegmentReader, err error) {
 
-	r = &SegmentReader{}
+	r = &SegmentReader{dvProducersByField: make(map[string]DocValuesProducer)}
 	r.AtomicReaderImpl = newAtomicReader(r)
 	r.ARFieldsReader = r
 
@@ -69,40 +75,143 @@ func NewSegmentReader(si *SegmentCommitInfo,
 		if !success {
 			// log.Printf("Failed to initialize SegmentReader.")
 			r.core.decRef()
+			for _, dvp := range r.dvProducers {
+				dvp.Close() // keep the original error
+			}
 		}
 	}()
 
 	codec := si.Info.Codec().(Codec)
 	if si.HasDeletions() {
-		panic("not supported yet")
+		// NOTE: the bitvector is stored using the regular directory, not cfs
+		if r.liveDocs, err = codec.LiveDocsFormat().ReadLiveDocs(r.Directory(), si, store.IO_CONTEXT_READONCE); err != nil {
+			return nil, err
+		}
 	} else {
 		assert(si.DelCount() == 0)
 	}
 	r.numDocs = si.Info.DocCount() - si.DelCount()
 
 	if r.fieldInfos.HasDocValues {
-		r.initDocValuesProducers(codec)
+		if err = r.initDocValuesProducers(codec); err != nil {
+			return nil, err
+		}
 	}
 	success = true
 	return r, nil
 }
 
-/* initialize the per-field DocValuesProducer */
-func (r *SegmentReader) initDocValuesProducers(codec Codec) error {
-	// var dir store.Directory
-	// if r.core.cfsReader != nil {
-	// 	dir = r.core.cfsReader
-	// } else {
-	// 	dir = r.si.Info.Dir
-	// }
-	// dvFormat := codec.DocValuesFormat()
+/*
+Create new SegmentReader sharing core from a previous SegmentReader
+and using the provided in-memory liveDocs. Used by IndexWriter to
+provide a new NRT reader, and by StandardDirectoryReader on reopen.
+*/
+func newSegmentReaderFrom(si *SegmentCommitInfo, sr *SegmentReader,
+	liveDocs util.Bits, numDocs int) (r *SegmentReader, err error) {
+
+	if numDocs > si.Info.DocCount() {
+		return nil, fmt.Errorf("numDocs=%v but maxDoc=%v", numDocs, si.Info.DocCount())
+	}
+	if liveDocs != nil && liveDocs.Length() != si.Info.DocCount() {
+		return nil, fmt.Errorf("maxDoc=%v but liveDocs.size()=%v",
+			si.Info.DocCount(), liveDocs.Length())
+	}
+
+	r = &SegmentReader{dvProducersByField: make(map[string]DocValuesProducer)}
+	r.AtomicReaderImpl = newAtomicReader(r)
+	r.ARFieldsReader = r
+
+	r.si = si
+	r.liveDocs = liveDocs
+	r.numDocs = numDocs
+	r.core = sr.core
+	r.core.incRef()
+
+	var success = false
+	defer func() {
+		if !success {
+			r.doClose() // keep the original error
+		}
+	}()
+
+	if r.fieldInfos, err = ReadFieldInfos(si); err != nil {
+		return nil, err
+	}
+	if r.fieldInfos.HasDocValues {
+		if err = r.initDocValuesProducers(si.Info.Codec().(Codec)); err != nil {
+			return nil, err
+		}
+	}
+	success = true
+	return r, nil
+}
 
-	// termsIndexDivisor := r.core.termsIndexDivisor
-	if !r.si.HasFieldUpdates() {
-		panic("not implemented yet")
+/*
+Create new SegmentReader sharing core from a previous SegmentReader
+and loading new live docs from a new deletes file. Used by
+StandardDirectoryReader on reopen.
+*/
+func newSegmentReaderWithNewDeletes(si *SegmentCommitInfo, sr *SegmentReader) (*SegmentReader, error) {
+	liveDocs, err := si.Info.Codec().(Codec).LiveDocsFormat().ReadLiveDocs(si.Info.Dir, si, store.IO_CONTEXT_READONCE)
+	if err != nil {
+		return nil, err
 	}
+	return newSegmentReaderFrom(si, sr, liveDocs, si.Info.DocCount()-si.DelCount())
+}
 
-	panic("not implemented yet")
+/* initialize the per-field DocValuesProducer */
+func (r *SegmentReader) initDocValuesProducers(codec Codec) (err error) {
+	var dir store.Directory
+	if r.core.cfsReader != nil {
+		dir = r.core.cfsReader
+	} else {
+		dir = r.si.Info.Dir
+	}
+	dvFormat := codec.DocValuesFormat()
+
+	gens, genInfos := r.genInfos()
+	for _, gen := range gens {
+		infos := genInfos[gen]
+		dvDir, segmentSuffix := dir, ""
+		if gen != -1 {
+			// gen'd files are written outside CFS, so use SegInfo directory
+			dvDir = r.si.Info.Dir
+			segmentSuffix = strconv.FormatInt(gen, 36)
+		}
+		// set SegmentReadState to list only the fields that are relevant to that gen
+		state := NewSegmentReadState(dvDir, r.si.Info, NewFieldInfos(infos),
+			store.IO_CONTEXT_READ, r.core.termsIndexDivisor)
+		state.SegmentSuffix = segmentSuffix
+		dvp, err := dvFormat.FieldsProducer(state)
+		if err != nil {
+			return err
+		}
+		r.dvProducers = append(r.dvProducers, dvp)
+		for _, fi := range infos {
+			r.dvProducersByField[fi.Name] = dvp
+		}
+	}
+	return nil
+}
+
+/*
+Returns the doc values fields grouped by their doc values generation,
+along with the generations in the order they were first seen.
+*/
+func (r *SegmentReader) genInfos() ([]int64, map[int64][]*FieldInfo) {
+	var gens []int64
+	genInfos := make(map[int64][]*FieldInfo)
+	for _, fi := range r.fieldInfos.Values {
+		if fi.DocValuesType() == DocValuesType(0) {
+			continue
+		}
+		gen := fi.DocValuesGen()
+		if _, ok := genInfos[gen]; !ok {
+			gens = append(gens, gen)
+		}
+		genInfos[gen] = append(genInfos[gen], fi)
+	}
+	return gens, genInfos
 }
 
 /* Reads the most recent FieldInfos of the given segment info. */
@@ -144,9 +253,14 @@ func (r *SegmentReader) LiveDocs() util.Bits {
 }
 
 func (r *SegmentReader) doClose() error {
-	panic("not implemented yet")
 	r.core.decRef()
-	return nil
+	var err error
+	for _, dvp := range r.dvProducers {
+		err = mergeError(err, dvp.Close())
+	}
+	r.dvProducers = nil
+	r.dvProducersByField = make(map[string]DocValuesProducer)
+	return err
 }
 
 func (r *SegmentReader) FieldInfos() FieldInfos {
@@ -180,12 +294,20 @@ func (r *SegmentReader) MaxDoc() int {
 	return r.si.Info.DocCount()
 }
 
+// Expert: retrieve thread-private TermVectorsReader, or nil if this
+// segment has no term vectors.
 func (r *SegmentReader) TermVectorsReader() TermVectorsReader {
-	panic("not implemented yet")
+	r.ensureOpen()
+	return r.core.termVectorsLocal()
 }
 
 func (r *SegmentReader) TermVectors(docID int) (fs Fields, err error) {
-	panic("not implemented yet")
+	termVectorsReader := r.TermVectorsReader()
+	if termVectorsReader == nil {
+		return nil, nil
+	}
+	r.checkBounds(docID)
+	return termVectorsReader.Get(docID), nil
 }
 
 func (r *SegmentReader) checkBounds(docID int) {
@@ -228,24 +350,73 @@ func (r *SegmentReader) TermInfosIndexDivisor() int {
 	return r.core.termsIndexDivisor
 }
 
+/*
+Returns the FieldInfo that corresponds to the given field and type,
+or nil if the field does not exist, or not indexed with the requested
+DocValuesType.
+*/
+func (r *SegmentReader) dvField(field string, typ DocValuesType) (*FieldInfo, error) {
+	fi := r.fieldInfos.FieldInfoByName(field)
+	if fi == nil {
+		// Field does not exist
+		return nil, nil
+	}
+	if fi.DocValuesType() == DocValuesType(0) {
+		// Field was not indexed with doc values
+		return nil, nil
+	}
+	if fi.DocValuesType() != typ {
+		// Field DocValues are different than requested type
+		return nil, fmt.Errorf("DocValues type for field '%v' was indexed as %v",
+			field, fi.DocValuesType())
+	}
+	return fi, nil
+}
+
 func (r *SegmentReader) NumericDocValues(field string) (v NumericDocValues, err error) {
 	r.ensureOpen()
-	panic("not implemented yet")
+	fi, err := r.dvField(field, DOC_VALUES_TYPE_NUMERIC)
+	if fi == nil || err != nil {
+		return nil, err
+	}
+	return r.dvProducersByField[field].Numeric(fi)
 }
 
 func (r *SegmentReader) BinaryDocValues(field string) (v BinaryDocValues, err error) {
 	r.ensureOpen()
-	panic("not implemented yet")
+	fi, err := r.dvField(field, DOC_VALUES_TYPE_BINARY)
+	if fi == nil || err != nil {
+		return nil, err
+	}
+	return r.dvProducersByField[field].Binary(fi)
 }
 
 func (r *SegmentReader) SortedDocValues(field string) (v SortedDocValues, err error) {
 	r.ensureOpen()
-	panic("not implemented yet")
+	fi, err := r.dvField(field, DOC_VALUES_TYPE_SORTED)
+	if fi == nil || err != nil {
+		return nil, err
+	}
+	return r.dvProducersByField[field].Sorted(fi)
 }
 
 func (r *SegmentReader) SortedSetDocValues(field string) (v SortedSetDocValues, err error) {
 	r.ensureOpen()
-	panic("not implemented yet")
+	fi, err := r.dvField(field, DOC_VALUES_TYPE_SORTED_SET)
+	if fi == nil || err != nil {
+		return nil, err
+	}
+	return r.dvProducersByField[field].SortedSet(fi)
+}
+
+func (r *SegmentReader) DocsWithField(field string) (v util.Bits, err error) {
+	r.ensureOpen()
+	fi := r.fieldInfos.FieldInfoByName(field)
+	if fi == nil || fi.DocValuesType() == DocValuesType(0) {
+		// Field does not exist or does not have docvalues
+		return nil, nil
+	}
+	return r.dvProducersByField[field].DocsWithField(fi)
 }
 
 func (r *SegmentReader) NormValues(field string) (v NumericDocValues, err error) {
@@ -253,8 +424,31 @@ func (r *SegmentReader) NormValues(field string) (v NumericDocValues, err error)
 	return r.core.normValues(r.fieldInfos, field)
 }
 
+/*
+Called when the shared core for a SegmentReader is closed.
+
+This listener is called only once all SegmentReaders sharing the same
+core are closed.
+*/
 type CoreClosedListener interface {
-	onClose(r interface{})
+	/*
+		Invoked when the shared core of the original SegmentReader has
+		closed. The provided ownerCoreCacheKey will be the same key as the
+		one returned by CoreCacheKey().
+	*/
+	OnClose(ownerCoreCacheKey interface{})
+}
+
+// Expert: adds a CoreClosedListener to this reader's shared core
+func (r *SegmentReader) AddCoreClosedListener(listener CoreClosedListener) {
+	r.ensureOpen()
+	r.core.addListener <- listener
+}
+
+// Expert: removes a CoreClosedListener from this reader's shared core
+func (r *SegmentReader) RemoveCoreClosedListener(listener CoreClosedListener) {
+	r.ensureOpen()
+	r.core.removeListener <- listener
 }
 
 // index/SegmentCoreReaders.java
@@ -281,6 +475,7 @@ type SegmentCoreReaders struct {
 	 TODO redesign when ported to goroutines
 	*/
 	fieldsReaderLocal func() StoredFieldsReader
+	termVectorsLocal  func() TermVectorsReader
 	normsLocal        func() map[string]interface{}
 
 	addListener    chan CoreClosedListener
@@ -304,12 +499,21 @@ func newSegmentCoreReaders(owner *SegmentReader, dir store.Directory, si *Segmen
 	self.fieldsReaderLocal = func() StoredFieldsReader {
 		return self.fieldsReaderOrig.Clone()
 	}
+	self.termVectorsLocal = func() TermVectorsReader {
+		if self.termVectorsReaderOrig == nil {
+			return nil
+		}
+		return self.termVectorsReaderOrig.Clone()
+	}
 
 	// fmt.Println("Initializing listeners...")
 	self.addListener = make(chan CoreClosedListener)
 	self.removeListener = make(chan CoreClosedListener)
 	self.notifyListener = make(chan bool)
 	// TODO re-enable later
+	// self is the named result, which is nil once an error is returned,
+	// so the listeners must keep their own reference.
+	core := self
 	go func() { // ensure listners are synchronized
 		coreClosedListeners := make([]CoreClosedListener, 0)
 		isRunning := true
@@ -317,9 +521,9 @@ func newSegmentCoreReaders(owner *SegmentReader, dir store.Directory, si *Segmen
 		for isRunning {
 			// fmt.Println("Listening for events...")
 			select {
-			case listener = <-self.addListener:
+			case listener = <-core.addListener:
 				coreClosedListeners = append(coreClosedListeners, listener)
-			case listener = <-self.removeListener:
+			case listener = <-core.removeListener:
 				n := len(coreClosedListeners)
 				for i, v := range coreClosedListeners {
 					if v == listener {
@@ -330,15 +534,16 @@ func newSegmentCoreReaders(owner *SegmentReader, dir store.Directory, si *Segmen
 						break
 					}
 				}
-			case <-self.notifyListener:
+			case <-core.notifyListener:
 				fmt.Println("Shutting down SegmentCoreReaders...")
 				isRunning = false
 				for _, v := range coreClosedListeners {
-					v.onClose(self)
+					v.OnClose(core)
 				}
 			}
 		}
 		fmt.Println("Listeners are done.")
+		core.notifyListener <- true
 	}()
 
 	var success = false
@@ -424,12 +629,27 @@ func (r *SegmentCoreReaders) normValues(infos FieldInfos,
 	return
 }
 
+func (r *SegmentCoreReaders) incRef() {
+	for {
+		count := atomic.LoadInt32(&r.refCount)
+		assert2(count > 0, "SegmentCoreReaders is already closed")
+		if atomic.CompareAndSwapInt32(&r.refCount, count, count+1) {
+			return
+		}
+	}
+}
+
 func (r *SegmentCoreReaders) decRef() {
 	if atomic.AddInt32(&r.refCount, -1) == 0 {
 		fmt.Println("--- closing core readers")
+		var cfsReader io.Closer // a nil *CompoundFileDirectory is not a nil Closer
+		if r.cfsReader != nil {
+			cfsReader = r.cfsReader
+		}
 		util.Close( /*self.termVectorsLocal, self.fieldsReaderLocal,  r.normsLocal,*/
 			r.fields, r.termVectorsReaderOrig, r.fieldsReaderOrig,
-			r.cfsReader, r.normsProducer)
+			cfsReader, r.normsProducer)
 		r.notifyListener <- true
+		<-r.notifyListener // wait until all listeners are notified
 	}
 }
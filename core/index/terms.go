@@ -1,10 +1,12 @@
 package index
 
 import (
+	"errors"
 	"fmt"
 	// "github.com/gzg1984/golucene/core/analysis/tokenattributes"
 	. "github.com/gzg1984/golucene/core/index/model"
 	"github.com/gzg1984/golucene/core/util"
+	"github.com/gzg1984/golucene/core/util/automaton"
 	// "sort"
 )
 
@@ -153,6 +155,14 @@ func (mt *MultiTerms) Iterator(reuse TermsEnum) TermsEnum {
 	panic("not implemented yet")
 }
 
+/*
+Intersection across sub-readers needs a merged terms enum, which is
+not ported yet. Intersect the terms of each leaf reader instead.
+*/
+func (mt *MultiTerms) Intersect(compiled *automaton.CompiledAutomaton, startTerm []byte) (TermsEnum, error) {
+	return nil, errors.New("intersecting terms of multiple segments is not supported yet; intersect each leaf's terms instead")
+}
+
 func (mt *MultiTerms) DocCount() int {
 	sum := 0
 	for _, terms := range mt.subs {
@@ -77,7 +77,18 @@ func (c *TermVectorsConsumerPerField) start(field IndexableField, first bool) bo
 				field.Name())
 		}
 	} else {
-		panic("not implemented yet")
+		assert2(c.doVectors == t.StoreTermVectors(),
+			"all instances of a given field name must have the same term vectors settings (storeTermVectors changed for field='%v')",
+			field.Name())
+		assert2(!t.StoreTermVectorOffsets(),
+			"all instances of a given field name must have the same term vectors settings (storeTermVectorOffsets changed for field='%v')",
+			field.Name())
+		assert2(!t.StoreTermVectorPositions(),
+			"all instances of a given field name must have the same term vectors settings (storeTermVectorPositions changed for field='%v')",
+			field.Name())
+		assert2(!t.StoreTermVectorPayloads(),
+			"all instances of a given field name must have the same term vectors settings (storeTermVectorPayloads changed for field='%v')",
+			field.Name())
 	}
 
 	if c.doVectors {
@@ -322,7 +333,15 @@ func (w *FreqProxTermsWriterPerField) addTerm(termId int) {
 	assert(!w.hasFreq || postings.termFreqs[termId] > 0)
 
 	if !w.hasFreq {
-		panic("not implemented yet")
+		assert(postings.termFreqs == nil)
+		if w.docState.docID != postings.lastDocIDs[termId] {
+			// New document; now encode docCode for previous doc:
+			assert(w.docState.docID > postings.lastDocIDs[termId])
+			w.writeVInt(0, postings.lastDocCodes[termId])
+			postings.lastDocCodes[termId] = w.docState.docID - postings.lastDocIDs[termId]
+			postings.lastDocIDs[termId] = w.docState.docID
+			w.fieldState.uniqueTermCount++
+		}
 	} else if w.docState.docID != postings.lastDocIDs[termId] {
 		assert2(w.docState.docID > postings.lastDocIDs[termId],
 			"id: %v postings ID: %v termID: %v",
@@ -498,7 +517,7 @@ func (w *FreqProxTermsWriterPerField) flush(fieldName string,
 
 	assert(!writeOffsets || writePositions)
 
-	var segUpdates map[*Term]int
+	var segUpdates map[termKey]int
 	if state.SegUpdates != nil && len(state.SegUpdates.(*BufferedUpdates).terms) > 0 {
 		segUpdates = state.SegUpdates.(*BufferedUpdates).terms
 	}
@@ -514,7 +533,6 @@ func (w *FreqProxTermsWriterPerField) flush(fieldName string,
 	sumTotalTermFreq := int64(0)
 	sumDocFreq := int64(0)
 
-	protoTerm := NewEmptyTerm(fieldName)
 	for i := 0; i < numTerms; i++ {
 		termId := termIDs[i]
 		// fmt.Printf("term=%v\n", termId)
@@ -539,8 +557,7 @@ func (w *FreqProxTermsWriterPerField) flush(fieldName string,
 
 		delDocLimit := 0
 		if segUpdates != nil {
-			protoTerm.Bytes = text.ToBytes()
-			if docIDUpto, ok := segUpdates[protoTerm]; ok {
+			if docIDUpto, ok := segUpdates[termKey{fieldName, string(text.ToBytes())}]; ok {
 				delDocLimit = docIDUpto
 			}
 		}
@@ -612,7 +629,18 @@ func (w *FreqProxTermsWriterPerField) flush(fieldName string,
 				return err
 			}
 			if docId < delDocLimit {
-				panic("not implemented yet")
+				// Mark it deleted. TODO: we could also skip writing its
+				// postings; this would be deterministic (just for this
+				// Term's docs).
+
+				// TODO: can we do this reach-around in a cleaner way????
+				if state.LiveDocs == nil {
+					state.LiveDocs = state.SegmentInfo.Codec().(Codec).LiveDocsFormat().NewLiveDocs(state.SegmentInfo.DocCount())
+				}
+				if state.LiveDocs.At(docId) {
+					state.DelCountOnFlush++
+					state.LiveDocs.Clear(docId)
+				}
 			}
 
 			totalTermFreq += int64(termFreq)
@@ -88,12 +88,14 @@ type ClosingControl struct {
 	_closing bool // volatile
 	closer   chan func() (bool, error)
 	done     chan error
+	lock     sync.Locker // the writer's lock, guards publishing _closed
 }
 
-func newClosingControl() *ClosingControl {
+func newClosingControl(lock sync.Locker) *ClosingControl {
 	ans := &ClosingControl{
 		closer: make(chan func() (bool, error)),
 		done:   make(chan error),
+		lock:   lock,
 	}
 	go ans.daemon()
 	return ans
@@ -108,7 +110,11 @@ func (cc *ClosingControl) daemon() {
 			log.Println("...closing...")
 			if !cc._closed {
 				cc._closing = true
-				cc._closed, err = f()
+				var closed bool
+				closed, err = f()
+				cc.lock.Lock()
+				cc._closed = closed
+				cc.lock.Unlock()
 				cc._closing = false
 			}
 			cc.done <- err
@@ -290,9 +296,10 @@ passed to another writer. If you intend to do so, you should clone it
 beforehand.
 */
 func NewIndexWriter(d store.Directory, conf *IndexWriterConfig) (w *IndexWriter, err error) {
+	lock := &sync.Mutex{}
 	ans := &IndexWriter{
-		Locker:         &sync.Mutex{},
-		ClosingControl: newClosingControl(),
+		Locker:         lock,
+		ClosingControl: newClosingControl(lock),
 
 		segmentsToMerge: make(map[*SegmentCommitInfo]bool),
 		mergeExceptions: make([]*OneMerge, 0),
@@ -454,6 +461,107 @@ func NewIndexWriter(d store.Directory, conf *IndexWriterConfig) (w *IndexWriter,
 // 		cfsDir, info.Name, store.IO_CONTEXT_READONCE)
 // }
 
+/*
+Expert: returns a readonly reader, covering all committed as well as
+un-committed changes to the index. This provides "near real-time"
+searching, in that changes made during an IndexWriter session can be
+quickly made available for searching without closing the writer nor
+calling Commit().
+
+Note that this is functionally equivalent to calling Commit() and
+then using OpenDirectoryReader() to open a new reader. But the
+turnaround time of this method should be faster since it avoids the
+potentially costly Commit().
+
+You must close the reader returned by this method once you are done
+using it.
+
+The reader stays valid and usable even after the writer was closed;
+this makes it possible to keep searching while a new writer is being
+opened.
+
+The first time this is called, the writer starts pooling the readers
+it opens for applying deletes, so that they can be shared with the
+near real-time readers.
+*/
+func (w *IndexWriter) reader(applyAllDeletes bool) (r DirectoryReader, err error) {
+	w.ensureOpen()
+
+	if w.infoStream.IsEnabled("IW") {
+		w.infoStream.Message("IW", "flush at getReader")
+	}
+	// Do this up front before flushing so that the readers obtained
+	// during this flush are pooled, the first time this method is
+	// called:
+	w.poolReaders = true
+	if err = w.doBeforeFlush(); err != nil {
+		return nil, err
+	}
+	var anySegmentFlushed bool
+	// Prevent segmentInfos from changing while opening the reader;
+	// in theory we could instead do similar retry logic, just like we
+	// do when loading segments_N
+	err = func() error {
+		w.fullFlushLock.Lock()
+		defer w.fullFlushLock.Unlock()
+
+		var success = false
+		defer func() {
+			// Done: finish the full flush!
+			w.docWriter.finishFullFlush(success)
+			if success {
+				w.docWriter.processEvents(w, false, true)
+			}
+			if err := w.doAfterFlush(); err != nil {
+				log.Printf("Error in doAfterFlush: %v", err)
+			}
+			if !success && w.infoStream.IsEnabled("IW") {
+				w.infoStream.Message("IW", "hit error during NRT reader")
+			}
+		}()
+
+		var err error
+		if anySegmentFlushed, err = w.docWriter.flushAllThreads(w); err != nil {
+			return err
+		}
+		if !anySegmentFlushed {
+			// prevent double increment since docWriter.doFlush
+			// increments the flushCount if we flushed anything.
+			atomic.AddInt32(&w.flushCount, 1)
+		}
+
+		if r, err = func() (DirectoryReader, error) {
+			w.Lock()
+			defer w.Unlock()
+
+			if err := w._maybeApplyDeletes(applyAllDeletes); err != nil {
+				return nil, err
+			}
+			return openStandardDirectoryReaderFromWriter(w, w.segmentInfos, applyAllDeletes)
+		}(); err != nil {
+			return err
+		}
+		if w.infoStream.IsEnabled("IW") {
+			w.infoStream.Message("IW", "return reader version=%v reader=%v",
+				r.Version(), r)
+		}
+		success = true
+		return nil
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	if anySegmentFlushed {
+		if err = w.maybeMerge(w.config.MergePolicy(), MERGE_TRIGGER_FULL_FLUSH,
+			UNBOUNDED_MAX_MERGE_SEGMENTS); err != nil {
+			r.decRef() // keep the original error
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
 /*
 Loads or returns the alread loaded the global field number map for
 this SegmentInfos. If this SegmentInfos has no global field number
@@ -507,8 +615,14 @@ func (w *IndexWriter) Close() error {
 	return w.close(func() (ok bool, err error) {
 		defer func() {
 			if !ok { // be certain to close the index on any error
-				defer recover() // suppress so we keep returning original error
-				w.rollbackInternal()
+				if e := recover(); e != nil && err == nil {
+					// surface the failure instead of silently dropping the segment
+					err = errors.New(fmt.Sprintf("%v", e))
+				}
+				func() {
+					defer func() { recover() }() // suppress so we keep returning original error
+					w.rollbackInternal()
+				}()
 			}
 		}()
 		if w.infoStream.IsEnabled("IW") {
@@ -577,6 +691,44 @@ func (w *IndexWriter) AddDocumentWithAnalyzer(doc []IndexableField, analyzer ana
 	return w.UpdateDocument(nil, doc, analyzer)
 }
 
+/*
+Deletes the document(s) containing any of the terms. All given
+deletes are applied and flushed atomically at the same time.
+*/
+func (w *IndexWriter) DeleteDocuments(terms ...*Term) error {
+	w.ensureOpen()
+	ok, err := w.docWriter.deleteTerms(terms...)
+	if err != nil {
+		return err
+	}
+	if ok {
+		_, err = w.docWriter.processEvents(w, true, false)
+	}
+	return err
+}
+
+/*
+Deletes the document(s) matching any of the provided queries. All
+given deletes are applied and flushed atomically at the same time.
+The queries are resolved against each segment, through the search
+package, when deletes are applied. ErrNoQueryResolver is returned if
+the search package is not linked in.
+*/
+func (w *IndexWriter) DeleteDocumentsByQuery(queries ...Query) error {
+	w.ensureOpen()
+	if QueryDocIdSetIterator == nil {
+		return ErrNoQueryResolver
+	}
+	ok, err := w.docWriter.deleteQueries(queries...)
+	if err != nil {
+		return err
+	}
+	if ok {
+		_, err = w.docWriter.processEvents(w, true, false)
+	}
+	return err
+}
+
 // L1545
 /*
 Updates a document by first deleting the document(s) containing term
@@ -609,6 +761,138 @@ func (w *IndexWriter) UpdateDocument(term *Term, doc []IndexableField, analyzer
 	return nil
 }
 
+/*
+Updates a document's NumericDocValues for field to the given value.
+This method can be used to update only numeric doc values fields,
+which were indexed with NumericDocValuesField, for all documents
+which contain the given term. The update is buffered and applied,
+as a new generation of doc values files, when deletes and updates
+are next applied (e.g. on commit).
+
+NOTE: this method currently replaces the existing value of all
+affected documents with the new value.
+*/
+func (w *IndexWriter) UpdateNumericDocValue(term *Term, field string, value int64) error {
+	w.ensureOpen()
+	if !w.globalFieldNumberMap.Contains(field, DOC_VALUES_TYPE_NUMERIC) {
+		return errors.New("can only update existing numeric-docvalues fields!")
+	}
+	ok, err := w.docWriter.updateNumericDocValue(term, field, value)
+	if err != nil {
+		return err
+	}
+	if ok {
+		_, err = w.docWriter.processEvents(w, true, false)
+	}
+	return err
+}
+
+/*
+Updates a document's BinaryDocValues for field to the given value.
+This method can be used to update only binary doc values fields,
+which were indexed with BinaryDocValuesField, for all documents
+which contain the given term.
+
+NOTE: this method currently replaces the existing value of all
+affected documents with the new value.
+*/
+func (w *IndexWriter) UpdateBinaryDocValue(term *Term, field string, value []byte) error {
+	w.ensureOpen()
+	if value == nil {
+		return fmt.Errorf("cannot update a field to a nil value: %v", field)
+	}
+	if !w.globalFieldNumberMap.Contains(field, DOC_VALUES_TYPE_BINARY) {
+		return errors.New("can only update existing binary-docvalues fields!")
+	}
+	ok, err := w.docWriter.updateBinaryDocValue(term, field, value)
+	if err != nil {
+		return err
+	}
+	if ok {
+		_, err = w.docWriter.processEvents(w, true, false)
+	}
+	return err
+}
+
+/*
+Delete all documents in the index.
+
+This method will drop all buffered documents and will remove all
+segments from the index. This change will not be visible until a
+Commit() has been called. This method can be rolled back using
+Rollback().
+
+NOTE: this method is much faster than using DeleteDocuments(
+NewTerm("*", "*")).
+
+NOTE: this method will forcefully abort all merges in progress. If
+other goroutines are running ForceMerge(), AddIndexes() or
+ForceMergeDeletes() methods, they may receive MergeAbortedErrors.
+*/
+func (w *IndexWriter) DeleteAll() error {
+	w.ensureOpen()
+	// Remove any buffered docs
+	var success = false
+	defer func() {
+		if !success && w.infoStream.IsEnabled("IW") {
+			w.infoStream.Message("IW", "hit error during deleteAll")
+		}
+	}()
+
+	// hold the full flush lock to prevent concurrency commits / NRT
+	// reopens to get in our way and do unnecessary work. -- if we
+	// don't lock this here we might get in trouble if
+	w.fullFlushLock.Lock()
+	defer w.fullFlushLock.Unlock()
+
+	// We first abort and trash everything we have in-memory and keep
+	// the thread-states locked, the lockAndAbortAll operation also
+	// guarantees "point in time semantics" ie. the checkpoint that we
+	// need in terms of logical happens-before relationship in the DW.
+	// So we do abort all in memory structures. We also drop global
+	// field numbering before during abort to make sure it's just like
+	// a fresh index.
+	locked := w.docWriter.lockAndAbortAll(w)
+	defer w.docWriter.unlockAllAfterAbortAll(w, locked)
+
+	if _, err := w.docWriter.processEvents(w, false, true); err != nil {
+		return err
+	}
+
+	w.Lock() // synchronized
+	defer w.Unlock()
+
+	// Abort any running merges
+	w.abortAllMerges()
+	// Let merges run again
+	w.stopMerges = false
+	// Remove all segments
+	for _, info := range w.segmentInfos.Segments {
+		atomic.AddInt64(&w.pendingNumDocs, -int64(info.Info.DocCount()))
+	}
+	w.segmentInfos.Clear()
+	// Ask deleter to locate unreferenced files & remove them:
+	if err := w.deleter.checkpoint(w.segmentInfos, false); err != nil {
+		return err
+	}
+	// don't refresh the deleter here since there might be concurrent
+	// indexing requests coming in opening files on the directory after
+	// we called DW.abort() if we do so these indexing requests might
+	// hit FNF errors. We will remove the files incrementally as we
+	// go...
+
+	// Don't bother saving any changes in our segmentInfos
+	if err := w.readerPool.dropAll(false); err != nil {
+		return err
+	}
+	// Mark that the index has changed
+	w.changeCount++
+	w.segmentInfos.changed()
+	w.globalFieldNumberMap.Clear()
+	success = true
+	return nil
+}
+
 func (w *IndexWriter) newSegmentName() string {
 	// Cannot synchronize on IndexWriter because that causes deadlook
 	// Ian: but why?
@@ -946,6 +1230,10 @@ segments file, but does NOT bump segmentInfos.version.
 func (w *IndexWriter) checkpointNoSIS() (err error) {
 	w.Lock() // synchronized
 	defer w.Unlock()
+	return w._checkpointNoSIS()
+}
+
+func (w *IndexWriter) _checkpointNoSIS() error {
 	w.changeCount++
 	return w.deleter.checkpoint(w.segmentInfos, false)
 }
@@ -1398,7 +1686,7 @@ func (w *IndexWriter) _applyAllDeletesAndUpdates() error {
 		return err
 	}
 	if result.anyDeletes {
-		err = w.checkpoint()
+		err = w._checkpoint()
 		if err != nil {
 			return err
 		}
@@ -1421,7 +1709,7 @@ func (w *IndexWriter) _applyAllDeletesAndUpdates() error {
 				}
 			}
 		}
-		err = w.checkpoint()
+		err = w._checkpoint()
 		if err != nil {
 			return err
 		}
@@ -1435,6 +1723,40 @@ func (w *IndexWriter) _applyAllDeletesAndUpdates() error {
 Merges the indicated segments, replacing them in the stack with a
 single segment.
 */
+/* Returns true if this writer has been closed. */
+func (w *IndexWriter) isClosed() bool {
+	w.Lock() // synchronized
+	defer w.Unlock()
+	return w._closed
+}
+
+/*
+Returns true if the given SegmentInfos, as seen by a near real-time
+reader, still reflects all changes made by this writer.
+*/
+func (w *IndexWriter) nrtIsCurrent(infos *SegmentInfos) bool {
+	w.Lock() // synchronized
+	defer w.Unlock()
+	w.ensureOpen()
+	if w.infoStream.IsEnabled("IW") {
+		w.infoStream.Message("IW", "nrtIsCurrent: infoVersion matches: %v; DW changes: %v; BD changes: %v",
+			infos.version == w.segmentInfos.version, w.docWriter.anyChanges(),
+			w.bufferedUpdatesStream.any())
+	}
+	return infos.version == w.segmentInfos.version &&
+		!w.docWriter.anyChanges() && !w.bufferedUpdatesStream.any()
+}
+
+/*
+Releases the files referenced by a near real-time reader's
+SegmentInfos, once that reader is closed.
+*/
+func (w *IndexWriter) decRefDeleter(infos *SegmentInfos) {
+	w.Lock() // synchronized
+	defer w.Unlock()
+	w.deleter.decRefInfos(infos)
+}
+
 func (w *IndexWriter) merge(merge *OneMerge) error {
 	panic("not implemented yet")
 }
@@ -0,0 +1,96 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/index/model"
+	"github.com/gzg1984/golucene/core/util/automaton"
+)
+
+// search/AutomatonQuery.java
+
+/*
+A Query that will match terms against a finite-state machine.
+
+This query will match documents that contain terms accepted by a
+given finite-state machine. The automaton can be constructed with
+the automaton API directly, or more conveniently with
+WildcardQuery, PrefixQuery or RegexpQuery.
+
+When the query is executed, it will create an equivalent DFA of the
+finite-state machine, and will enumerate the term dictionary in an
+intelligent way to reduce the number of comparisons. For example:
+regular expression of [dl]og? will make approximately four
+comparisons: do, dog, lo, and log.
+*/
+type AutomatonQuery struct {
+	*MultiTermQuery
+	// the automaton to match index terms against
+	automaton *automaton.Automaton
+	compiled  *automaton.CompiledAutomaton
+	// term containing the field, and possibly some pattern structure
+	term *index.Term
+}
+
+/*
+Create a new AutomatonQuery from an Automaton.
+
+term contains the field, and possibly some pattern structure. The
+term text is ignored.
+*/
+func NewAutomatonQuery(term *index.Term, a *automaton.Automaton) *AutomatonQuery {
+	ans := &AutomatonQuery{}
+	ans.MultiTermQuery = newMultiTermQuery(ans, term.Field)
+	ans.init(term, a, false)
+	return ans
+}
+
+func newAutomatonQuery(self interface{}, term *index.Term,
+	a *automaton.Automaton, isBinary bool) *AutomatonQuery {
+
+	ans := &AutomatonQuery{}
+	ans.MultiTermQuery = newMultiTermQuery(self, term.Field)
+	ans.init(term, a, isBinary)
+	return ans
+}
+
+func (q *AutomatonQuery) init(term *index.Term, a *automaton.Automaton, isBinary bool) {
+	q.term = term
+	q.automaton = a
+	q.compiled = automaton.NewCompiledAutomatonWith(a, nil, true, isBinary)
+}
+
+/*
+Returns a TermsEnum that iterates over all terms of the field that
+are accepted by the compiled automaton, choosing the cheapest
+strategy for its type.
+*/
+func (q *AutomatonQuery) TermsEnum(terms Terms) (TermsEnum, error) {
+	switch q.compiled.Type {
+	case automaton.AUTOMATON_TYPE_NONE:
+		return EMPTY_TERMS_ENUM, nil
+	case automaton.AUTOMATON_TYPE_ALL:
+		return terms.Iterator(nil), nil
+	case automaton.AUTOMATON_TYPE_SINGLE:
+		return newSingleTermsEnum(terms.Iterator(nil), q.compiled.Term), nil
+	case automaton.AUTOMATON_TYPE_NORMAL:
+		return terms.Intersect(q.compiled, nil)
+	}
+	panic(fmt.Sprintf("unhandled case: %v", q.compiled.Type))
+}
+
+func (q *AutomatonQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	if q.term.Field != field {
+		buf.WriteString(q.term.Field)
+		buf.WriteRune(':')
+	}
+	buf.WriteString("AutomatonQuery {\n")
+	buf.WriteString(fmt.Sprintf("%v", q.automaton))
+	buf.WriteRune('}')
+	if q.boost != 1.0 {
+		buf.WriteString(fmt.Sprintf("^%v", q.boost))
+	}
+	return buf.String()
+}
@@ -2,11 +2,40 @@ package search
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/gzg1984/golucene/core/index"
 	"github.com/gzg1984/golucene/core/util"
 )
 
-const maxClauseCount = 1024
+var maxClauseCount = 1024
+
+/*
+Returned when an attempt is made to add more than MaxClauseCount()
+clauses. This typically happens if a PrefixQuery, FuzzyQuery,
+WildcardQuery, or TermRangeQuery is expanded to many terms during
+search.
+*/
+type TooManyClauses struct{}
+
+func (err *TooManyClauses) Error() string {
+	return fmt.Sprintf("maxClauseCount is set to %v", maxClauseCount)
+}
+
+/*
+Return the maximum number of clauses permitted, 1024 by default.
+Attempts to add more than the permitted number of clauses cause
+TooManyClauses to be returned.
+*/
+func MaxClauseCount() int {
+	return maxClauseCount
+}
+
+// Set the maximum number of clauses permitted per BooleanQuery.
+// Default value is 1024.
+func SetMaxClauseCount(n int) {
+	assert2(n >= 1, "maxClauseCount must be >= 1")
+	maxClauseCount = n
+}
 
 type BooleanQuery struct {
 	*AbstractQuery
@@ -27,16 +56,55 @@ func NewBooleanQueryDisableCoord(disableCoord bool) *BooleanQuery {
 	return ans
 }
 
-func (q *BooleanQuery) Add(query Query, occur Occur) {
-	q.AddClause(NewBooleanClause(query, occur))
+/*
+Specifies a minimum number of the optional BooleanClauses which must
+be satisfied.
+
+By default no optional clauses are necessary for a match (unless
+there are no required clauses). If this method is used, then the
+specified number of clauses is required.
+
+Use of this method is totally independent of specifying that any
+specific clauses are required (or prohibited). This number will only
+be compared against the number of matching optional clauses.
+*/
+func (q *BooleanQuery) SetMinimumNumberShouldMatch(min int) {
+	q.minNrShouldMatch = min
+}
+
+// Gets the minimum number of the optional BooleanClauses which must be
+// satisfied.
+func (q *BooleanQuery) MinimumNumberShouldMatch() int {
+	return q.minNrShouldMatch
+}
+
+// Returns the set of clauses in this query.
+func (q *BooleanQuery) Clauses() []*BooleanClause {
+	return q.clauses
+}
+
+/*
+Adds a clause to a boolean query. Returns TooManyClauses if the new
+number of clauses exceeds the maximum clause number.
+*/
+func (q *BooleanQuery) Add(query Query, occur Occur) error {
+	return q.AddClause(NewBooleanClause(query, occur))
 }
 
-func (q *BooleanQuery) AddClause(clause *BooleanClause) {
-	assert(len(q.clauses) < maxClauseCount)
+/*
+Adds a clause to a boolean query. Returns TooManyClauses if the new
+number of clauses exceeds the maximum clause number.
+*/
+func (q *BooleanQuery) AddClause(clause *BooleanClause) error {
+	if len(q.clauses) >= maxClauseCount {
+		return new(TooManyClauses)
+	}
 	q.clauses = append(q.clauses, clause)
+	return nil
 }
 
 type BooleanWeight struct {
+	*WeightImpl
 	owner        *BooleanQuery
 	similarity   Similarity
 	weights      []Weight
@@ -52,6 +120,7 @@ func newBooleanWeight(owner *BooleanQuery,
 		similarity:   searcher.similarity,
 		disableCoord: disableCoord,
 	}
+	w.WeightImpl = newWeightImpl(w)
 	var subWeight Weight
 	for _, c := range owner.clauses {
 		if subWeight, err = c.query.CreateWeight(searcher); err != nil {
@@ -95,14 +164,93 @@ func (w *BooleanWeight) Normalize(norm, topLevelBoost float32) {
 }
 
 func (w *BooleanWeight) Explain(context *index.AtomicReaderContext, doc int) (Explanation, error) {
-	panic("not implemented yet")
+	minShouldMatch := w.owner.minNrShouldMatch
+	sumExpl := newEmptyComplexExplanation()
+	sumExpl.description = "sum of:"
+	coord := 0
+	var sum float32
+	fail := false
+	shouldMatchCount := 0
+	liveDocs := context.Reader().(index.AtomicReader).LiveDocs()
+	for i, subWeight := range w.weights {
+		c := w.owner.clauses[i]
+		scorer, err := subWeight.Scorer(context, liveDocs)
+		if err != nil {
+			return nil, err
+		}
+		if scorer == nil {
+			if c.IsRequired() {
+				fail = true
+				sumExpl.addDetail(newExplanation(0, fmt.Sprintf(
+					"no match on required clause (%v)", c.query.ToString(""))))
+			}
+			continue
+		}
+		e, err := subWeight.Explain(context, doc)
+		if err != nil {
+			return nil, err
+		}
+		if e.IsMatch() {
+			if !c.IsProhibited() {
+				sumExpl.addDetail(e)
+				sum += e.Value()
+				coord++
+			} else {
+				r := newExplanation(0, fmt.Sprintf(
+					"match on prohibited clause (%v)", c.query.ToString("")))
+				r.addDetail(e)
+				sumExpl.addDetail(r)
+				fail = true
+			}
+			if c.occur == SHOULD {
+				shouldMatchCount++
+			}
+		} else if c.IsRequired() {
+			r := newExplanation(0, fmt.Sprintf(
+				"no match on required clause (%v)", c.query.ToString("")))
+			r.addDetail(e)
+			sumExpl.addDetail(r)
+			fail = true
+		}
+	}
+	if fail {
+		sumExpl.match = false
+		sumExpl.value = 0
+		sumExpl.description = "Failure to meet condition(s) of required/prohibited clause(s)"
+		return sumExpl, nil
+	} else if shouldMatchCount < minShouldMatch {
+		sumExpl.match = false
+		sumExpl.value = 0
+		sumExpl.description = fmt.Sprintf(
+			"Failure to match minimum number of optional clauses: %v", minShouldMatch)
+		return sumExpl, nil
+	}
+
+	sumExpl.match = coord > 0
+	sumExpl.value = sum
+
+	coordFactor := float32(1)
+	if !w.disableCoord {
+		coordFactor = w.coord(coord, w.maxCoord)
+	}
+	if coordFactor == 1 {
+		return sumExpl, nil // eliminate wrapper
+	}
+	result := newComplexExplanation(sumExpl.IsMatch(), sum*coordFactor, "product of:")
+	result.addDetail(sumExpl)
+	result.addDetail(newExplanation(coordFactor,
+		fmt.Sprintf("coord(%v/%v)", coord, w.maxCoord)))
+	return result, nil
 }
 
 func (w *BooleanWeight) BulkScorer(context *index.AtomicReaderContext,
 	scoreDocsInOrder bool, acceptDocs util.Bits) (BulkScorer, error) {
 
 	if scoreDocsInOrder || w.owner.minNrShouldMatch > 1 {
-		panic("not implemented yet")
+		// TODO: (LUCENE-4872) in some cases BooleanScorer may be faster
+		// for minNrShouldMatch but the same is even true of pure
+		// conjunctions...
+		return w.WeightImpl.BulkScorer(context, scoreDocsInOrder, acceptDocs)
 	}
 
 	var prohibited, optional []BulkScorer
@@ -117,7 +265,9 @@ func (w *BooleanWeight) BulkScorer(context *index.AtomicReaderContext,
 				return nil, nil
 			}
 		} else if c.IsRequired() {
-			panic("not implemented yet")
+			// TODO: there are some cases where BooleanScorer would handle
+			// conjunctions faster than BooleanScorer2...
+			return w.WeightImpl.BulkScorer(context, scoreDocsInOrder, acceptDocs)
 		} else if c.IsProhibited() {
 			prohibited = append(prohibited, subScorer)
 		} else {
@@ -128,6 +278,69 @@ func (w *BooleanWeight) BulkScorer(context *index.AtomicReaderContext,
 	return newBooleanScorer(w, w.disableCoord, w.owner.minNrShouldMatch, optional, prohibited, w.maxCoord), nil
 }
 
+func (w *BooleanWeight) Scorer(context *index.AtomicReaderContext,
+	acceptDocs util.Bits) (Scorer, error) {
+
+	var required, prohibited, optional []Scorer
+	for i, subWeight := range w.weights {
+		c := w.owner.clauses[i]
+		subScorer, err := subWeight.Scorer(context, acceptDocs)
+		if err != nil {
+			return nil, err
+		}
+		if subScorer == nil {
+			if c.IsRequired() {
+				return nil, nil
+			}
+		} else if c.IsRequired() {
+			required = append(required, subScorer)
+		} else if c.IsProhibited() {
+			prohibited = append(prohibited, subScorer)
+		} else {
+			optional = append(optional, subScorer)
+		}
+	}
+
+	minNrShouldMatch := w.owner.minNrShouldMatch
+	if len(required) == 0 && len(optional) == 0 {
+		// no required and optional clauses.
+		return nil, nil
+	} else if len(optional) < minNrShouldMatch {
+		// either >1 req scorer, or there are 0 req scorers and at least 1
+		// optional scorer. Therefore if there are not enough optional
+		// scorers no documents will be matched by the query
+		return nil, nil
+	}
+
+	// simple conjunction
+	if len(optional) == 0 && len(prohibited) == 0 {
+		coord := float32(1)
+		if !w.disableCoord {
+			coord = w.coord(len(required), w.maxCoord)
+		}
+		return newConjunctionScorer(w, required, coord), nil
+	}
+
+	// simple disjunction
+	if len(required) == 0 && len(prohibited) == 0 &&
+		minNrShouldMatch <= 1 && len(optional) > 1 {
+
+		coord := make([]float32, len(optional)+1)
+		for i, _ := range coord {
+			if w.disableCoord {
+				coord[i] = 1
+			} else {
+				coord[i] = w.coord(i, w.maxCoord)
+			}
+		}
+		return newDisjunctionSumScorer(w, optional, coord), nil
+	}
+
+	// Return a BooleanScorer2
+	return newBooleanScorer2(w, w.disableCoord, minNrShouldMatch,
+		required, prohibited, optional, w.maxCoord), nil
+}
+
 func (w *BooleanWeight) IsScoresDocsOutOfOrder() bool {
 	if w.owner.minNrShouldMatch > 1 {
 		// BS2 (in-order) will be used by scorer()
@@ -155,29 +368,61 @@ func (q *BooleanQuery) CreateWeight(searcher *IndexSearcher) (Weight, error) {
 	return newBooleanWeight(q, searcher, q.disableCoord)
 }
 
-func (q *BooleanQuery) Rewrite(reader index.IndexReader) Query {
-	if q.minNrShouldMatch == 0 && len(q.clauses) == 1 {
-		panic("not implemented yet")
+func (q *BooleanQuery) Rewrite(reader index.IndexReader) (Query, error) {
+	if q.minNrShouldMatch == 0 && len(q.clauses) == 1 { // optimize 1-clause queries
+		if c := q.clauses[0]; !c.IsProhibited() { // just return clause
+			query, err := c.query.Rewrite(reader) // rewrite first
+			if err != nil {
+				return nil, err
+			}
+			if q.boost == 1 {
+				return query, nil
+			}
+			// Since the BooleanQuery only has 1 clause, the BooleanQuery
+			// will be written out. Therefore the rewritten Query's boost
+			// must incorporate both the clause's boost, and the boost of
+			// the BooleanQuery itself. The rewritten query may still be
+			// owned by the caller (a no-op rewrite returns the clause
+			// itself), so the boost goes on a clone; a query that can't be
+			// cloned keeps its BooleanQuery.
+			if clone := cloneQuery(query); clone != nil {
+				clone.SetBoost(q.boost * clone.Boost())
+				return clone, nil
+			}
+		}
 	}
 
 	var clone *BooleanQuery // recursively rewrite
-	for _, c := range q.clauses {
-		if query := c.query.Rewrite(reader); query != c.query {
+	for i, c := range q.clauses {
+		query, err := c.query.Rewrite(reader)
+		if err != nil {
+			return nil, err
+		}
+		if query != c.query {
 			// clause rewrote: must clone
 			if clone == nil {
 				// The BooleanQuery clone is lazily initialized so only
 				// initialize it if a rewritten clause differs from the
 				// original clause (and hasn't been initialized already). If
 				// nothing difers, the clone isn't needlessly created
-				panic("not implemented yet")
+				clone = q.clone()
 			}
-			panic("not implemented yet")
+			clone.clauses[i] = NewBooleanClause(query, c.occur)
 		}
 	}
 	if clone != nil {
-		return clone // some clauses rewrote
+		return clone, nil // some clauses rewrote
 	}
-	return q
+	return q, nil
+}
+
+func (q *BooleanQuery) clone() *BooleanQuery {
+	ans := NewBooleanQueryDisableCoord(q.disableCoord)
+	ans.boost = q.boost
+	ans.minNrShouldMatch = q.minNrShouldMatch
+	ans.clauses = make([]*BooleanClause, len(q.clauses))
+	copy(ans.clauses, q.clauses)
+	return ans
 }
 
 func (q *BooleanQuery) ToString(field string) string {
@@ -216,11 +461,11 @@ func (q *BooleanQuery) ToString(field string) string {
 	}
 
 	if q.minNrShouldMatch > 0 {
-		panic("not implemented yet")
+		buf.WriteString(fmt.Sprintf("~%v", q.minNrShouldMatch))
 	}
 
 	if q.Boost() != 1 {
-		panic("not implemented yet")
+		buf.WriteString(fmt.Sprintf("^%v", q.Boost()))
 	}
 
 	return buf.String()
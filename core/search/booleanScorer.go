@@ -1,6 +1,8 @@
 package search
 
 import (
+	"bytes"
+	"fmt"
 	"github.com/gzg1984/golucene/core/index"
 )
 
@@ -46,9 +48,9 @@ func (c *BooleanScorerCollector) Collect(doc int) (err error) {
 	return nil
 }
 
-func (c *BooleanScorerCollector) SetNextReader(*index.AtomicReaderContext) {}
-func (c *BooleanScorerCollector) SetScorer(scorer Scorer)                  { c.scorer = scorer }
-func (c *BooleanScorerCollector) AcceptsDocsOutOfOrder() bool              { return true }
+func (c *BooleanScorerCollector) SetNextReader(*index.AtomicReaderContext) error { return nil }
+func (c *BooleanScorerCollector) SetScorer(scorer Scorer)                        { c.scorer = scorer }
+func (c *BooleanScorerCollector) AcceptsDocsOutOfOrder() bool                    { return true }
 
 type Bucket struct {
 	doc   int // tells if bucket is valid
@@ -169,7 +171,11 @@ func (s *BooleanScorer) ScoreAndCollectUpto(collector Collector, max int) (more
 			if (s.current.bits & PROHIBITED_MASK) == 0 {
 
 				if s.current.doc >= max {
-					panic("not implemented yet")
+					tmp := s.current
+					s.current = s.current.next
+					tmp.next = s.bucketTable.first
+					s.bucketTable.first = tmp
+					continue
 				}
 
 				if s.current.coord >= s.minNrShouldMatch {
@@ -186,7 +192,9 @@ func (s *BooleanScorer) ScoreAndCollectUpto(collector Collector, max int) (more
 		}
 
 		if s.bucketTable.first != nil {
-			panic("not implemented yet")
+			s.current = s.bucketTable.first
+			s.bucketTable.first = s.current.next
+			return true, nil
 		}
 
 		// refill the queue
@@ -210,7 +218,14 @@ func (s *BooleanScorer) ScoreAndCollectUpto(collector Collector, max int) (more
 }
 
 func (s *BooleanScorer) String() string {
-	panic("not implemented yet")
+	var buf bytes.Buffer
+	buf.WriteString("boolean(")
+	for sub := s.scorers; sub != nil; sub = sub.next {
+		buf.WriteString(fmt.Sprintf("%v", sub.scorer))
+		buf.WriteRune(' ')
+	}
+	buf.WriteRune(')')
+	return buf.String()
 }
 
 type FakeScorer struct {
@@ -0,0 +1,295 @@
+package search
+
+import (
+	"fmt"
+	"math"
+)
+
+// search/BooleanScorer2.java
+
+type coordinator struct {
+	coordFactors []float32
+	nrMatchers   int // to be increased by score() of match counting scorers.
+}
+
+func newCoordinator(maxCoord int, disableCoord bool,
+	w *BooleanWeight, nrScorers int) *coordinator {
+
+	ans := &coordinator{coordFactors: make([]float32, nrScorers+1)}
+	for i, _ := range ans.coordFactors {
+		if disableCoord {
+			ans.coordFactors[i] = 1
+		} else {
+			ans.coordFactors[i] = w.coord(i, maxCoord)
+		}
+	}
+	return ans
+}
+
+/*
+See the description in BooleanScorer comparing BooleanScorer and
+BooleanScorer2.
+
+An alternative to BooleanScorer that also allows a minimum number of
+optional scorers that should match.
+
+Implements Scorer.Advance(), and it uses Advance() on the given
+scorers.
+*/
+type BooleanScorer2 struct {
+	*abstractScorer
+	requiredScorers   []Scorer
+	optionalScorers   []Scorer
+	prohibitedScorers []Scorer
+
+	coordinator *coordinator
+
+	// The scorer to which all scoring will be delegated, except for
+	// computing and using the coordination factor.
+	countingSumScorer Scorer
+
+	// The number of optionalScorers that need to match (if there are
+	// any)
+	minNrShouldMatch int
+
+	doc int
+}
+
+/*
+Creates a Scorer with the given similarity and lists of required,
+prohibited and optional scorers. In no required scorers are added,
+at least one of the optional scorers will have to match during the
+search.
+
+weight: The BooleanWeight to be used.
+disableCoord: If this parameter is true, coordination level matching
+(Similarity.Coord()) is not used.
+minNrShouldMatch: The minimum number of optional added scorers that
+should match during the search. In case no required scorers are
+added, at least one of the optional scorers will have to match during
+the search.
+required: the list of required scorers.
+prohibited: the list of prohibited scorers.
+optional: the list of optional scorers.
+*/
+func newBooleanScorer2(weight *BooleanWeight, disableCoord bool,
+	minNrShouldMatch int, required, prohibited, optional []Scorer,
+	maxCoord int) *BooleanScorer2 {
+
+	assert2(minNrShouldMatch >= 0, "Minimum number of optional scorers should not be negative")
+	ans := &BooleanScorer2{
+		requiredScorers:   required,
+		optionalScorers:   optional,
+		prohibitedScorers: prohibited,
+		minNrShouldMatch:  minNrShouldMatch,
+		doc:               -1,
+	}
+	ans.abstractScorer = newScorer(ans, weight)
+	ans.coordinator = newCoordinator(maxCoord, disableCoord, weight,
+		len(optional)+len(required))
+	ans.countingSumScorer = ans.makeCountingSumScorer(disableCoord)
+	return ans
+}
+
+/*
+Wraps a scorer so that each call of Score() on the current document
+also counts its matchers in the coordinator.
+*/
+type countingScorer struct {
+	Scorer
+	count func() error
+}
+
+func (s *countingScorer) Score() (float32, error) {
+	score, err := s.Scorer.Score()
+	if err != nil {
+		return 0, err
+	}
+	return score, s.count()
+}
+
+/* Count a scorer as a single match. */
+type singleMatchScorer struct {
+	Scorer
+	coordinator *coordinator
+	// Save the score of lastScoredDoc, so that we don't compute it more
+	// than once in Score().
+	lastScoredDoc int
+	lastDocScore  float32
+}
+
+func newSingleMatchScorer(scorer Scorer, c *coordinator) *singleMatchScorer {
+	return &singleMatchScorer{
+		Scorer:        scorer,
+		coordinator:   c,
+		lastScoredDoc: -1,
+		lastDocScore:  float32(math.NaN()),
+	}
+}
+
+func (s *singleMatchScorer) Score() (float32, error) {
+	if doc := s.DocId(); doc >= s.lastScoredDoc {
+		if doc > s.lastScoredDoc {
+			score, err := s.Scorer.Score()
+			if err != nil {
+				return 0, err
+			}
+			s.lastDocScore, s.lastScoredDoc = score, doc
+		}
+		s.coordinator.nrMatchers++
+	}
+	return s.lastDocScore, nil
+}
+
+func (s *BooleanScorer2) countingDisjunctionSumScorer(scorers []Scorer,
+	minNrShouldMatch int) Scorer {
+
+	// each scorer from the list counted as a single matcher; we pass nil
+	// for coord since we coordinate ourselves.
+	var scorer Scorer
+	if minNrShouldMatch > 1 {
+		scorer = newMinShouldMatchSumScorer(s.weight, scorers, minNrShouldMatch, nil)
+	} else {
+		scorer = newDisjunctionSumScorer(s.weight, scorers, nil)
+	}
+	return &countingScorer{scorer, func() error {
+		freq, err := scorer.Freq()
+		if err != nil {
+			return err
+		}
+		s.coordinator.nrMatchers += freq
+		return nil
+	}}
+}
+
+func (s *BooleanScorer2) countingConjunctionSumScorer(disableCoord bool,
+	requiredScorers []Scorer) Scorer {
+
+	// each scorer from the list counted as a single matcher
+	requiredNrMatchers := len(requiredScorers)
+	cs := newConjunctionScorer(s.weight, requiredScorers, 1)
+	return &countingScorer{cs, func() error {
+		s.coordinator.nrMatchers += requiredNrMatchers
+		return nil
+	}}
+}
+
+func (s *BooleanScorer2) dualConjunctionSumScorer(disableCoord bool,
+	req1, req2 Scorer) Scorer {
+
+	// non counting.
+	return newConjunctionScorer(s.weight, []Scorer{req1, req2}, 1)
+	// All scorers match, so defaultSimilarity always has 1 as
+	// the coordination factor.
+	// Therefore the sum of the scores of two scorers
+	// is used as score.
+}
+
+/*
+Returns the scorer to be used for match counting and score summing.
+Uses requiredScorers, optionalScorers and prohibitedScorers.
+*/
+func (s *BooleanScorer2) makeCountingSumScorer(disableCoord bool) Scorer {
+	if len(s.requiredScorers) == 0 {
+		return s.makeCountingSumScorerNoReq(disableCoord)
+	}
+	return s.makeCountingSumScorerSomeReq(disableCoord)
+}
+
+func (s *BooleanScorer2) makeCountingSumScorerNoReq(disableCoord bool) Scorer {
+	// minNrShouldMatch optional scorers are required, but at least 1
+	nrOptRequired := s.minNrShouldMatch
+	if nrOptRequired < 1 {
+		nrOptRequired = 1
+	}
+	var requiredCountingSumScorer Scorer
+	if len(s.optionalScorers) > nrOptRequired {
+		requiredCountingSumScorer = s.countingDisjunctionSumScorer(s.optionalScorers, nrOptRequired)
+	} else if len(s.optionalScorers) == 1 {
+		requiredCountingSumScorer = newSingleMatchScorer(s.optionalScorers[0], s.coordinator)
+	} else {
+		requiredCountingSumScorer = s.countingConjunctionSumScorer(disableCoord, s.optionalScorers)
+	}
+	return s.addProhibitedScorers(requiredCountingSumScorer)
+}
+
+func (s *BooleanScorer2) makeCountingSumScorerSomeReq(disableCoord bool) Scorer {
+	if len(s.optionalScorers) == s.minNrShouldMatch { // all optional scorers also required.
+		allReq := make([]Scorer, 0, len(s.requiredScorers)+len(s.optionalScorers))
+		allReq = append(allReq, s.requiredScorers...)
+		allReq = append(allReq, s.optionalScorers...)
+		return s.addProhibitedScorers(s.countingConjunctionSumScorer(disableCoord, allReq))
+	}
+
+	// optionalScorers.size() > minNrShouldMatch, and at least one
+	// required scorer
+	var requiredCountingSumScorer Scorer
+	if len(s.requiredScorers) == 1 {
+		requiredCountingSumScorer = newSingleMatchScorer(s.requiredScorers[0], s.coordinator)
+	} else {
+		requiredCountingSumScorer = s.countingConjunctionSumScorer(disableCoord, s.requiredScorers)
+	}
+	if s.minNrShouldMatch > 0 { // use a required disjunction scorer over the optional scorers
+		return s.addProhibitedScorers(
+			s.dualConjunctionSumScorer( // non counting
+				disableCoord,
+				requiredCountingSumScorer,
+				s.countingDisjunctionSumScorer(s.optionalScorers, s.minNrShouldMatch)))
+	}
+	// minNrShouldMatch == 0
+	var optionalScorer Scorer
+	if len(s.optionalScorers) == 1 {
+		optionalScorer = newSingleMatchScorer(s.optionalScorers[0], s.coordinator)
+	} else {
+		// require 1 in combined, optional scorer.
+		optionalScorer = s.countingDisjunctionSumScorer(s.optionalScorers, 1)
+	}
+	return newReqOptSumScorer(s.addProhibitedScorers(requiredCountingSumScorer), optionalScorer)
+}
+
+/*
+Returns the scorer to be used for match counting and score summing.
+Uses the given required scorer and the prohibitedScorers.
+*/
+func (s *BooleanScorer2) addProhibitedScorers(requiredCountingSumScorer Scorer) Scorer {
+	switch len(s.prohibitedScorers) {
+	case 0:
+		return requiredCountingSumScorer // no prohibited
+	case 1:
+		return newReqExclScorer(requiredCountingSumScorer, s.prohibitedScorers[0])
+	default:
+		return newReqExclScorer(requiredCountingSumScorer,
+			newDisjunctionSumScorer(s.weight, s.prohibitedScorers, nil))
+	}
+}
+
+func (s *BooleanScorer2) DocId() int {
+	return s.doc
+}
+
+func (s *BooleanScorer2) NextDoc() (doc int, err error) {
+	s.doc, err = s.countingSumScorer.NextDoc()
+	return s.doc, err
+}
+
+func (s *BooleanScorer2) Score() (float32, error) {
+	s.coordinator.nrMatchers = 0
+	sum, err := s.countingSumScorer.Score()
+	if err != nil {
+		return 0, err
+	}
+	return sum * s.coordinator.coordFactors[s.coordinator.nrMatchers], nil
+}
+
+func (s *BooleanScorer2) Freq() (int, error) {
+	return s.countingSumScorer.Freq()
+}
+
+func (s *BooleanScorer2) Advance(target int) (doc int, err error) {
+	s.doc, err = s.countingSumScorer.Advance(target)
+	return s.doc, err
+}
+
+func (s *BooleanScorer2) String() string {
+	return fmt.Sprintf("BooleanScorer2(%v)", s.weight)
+}
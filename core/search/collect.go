@@ -14,8 +14,9 @@ type ScoreDoc struct {
 	/** A hit document's number.
 	 * @see IndexSearcher#doc(int) */
 	Doc int
-	/** Only set by {@link TopDocs#merge} */
-	shardIndex int
+	/** Only set by {@link TopDocs#merge}: the position of the hit's
+	 * TopDocs in the merged shards, or -1. */
+	ShardIndex int
 }
 
 func newScoreDoc(doc int, score float32) *ScoreDoc {
@@ -27,7 +28,7 @@ func newShardedScoreDoc(doc int, score float32, shardIndex int) *ScoreDoc {
 }
 
 func (d *ScoreDoc) String() string {
-	return fmt.Sprintf("doc=%v score=%v shardIndex=%v", d.Doc, d.Score, d.shardIndex)
+	return fmt.Sprintf("doc=%v score=%v shardIndex=%v", d.Doc, d.Score, d.ShardIndex)
 }
 
 type PriorityQueue struct {
@@ -56,10 +57,16 @@ type TopDocs struct {
 	maxScore  float64
 }
 
+// Returns the maximum score value encountered. Note that in case
+// scores are not tracked, this returns NaN.
+func (d TopDocs) MaxScore() float64 {
+	return d.maxScore
+}
+
 type Collector interface {
 	SetScorer(s Scorer)
 	Collect(doc int) error
-	SetNextReader(ctx *index.AtomicReaderContext)
+	SetNextReader(ctx *index.AtomicReaderContext) error
 	AcceptsDocsOutOfOrder() bool
 }
 
@@ -152,14 +159,14 @@ func (c *abstractTopDocsCollector) TopDocs() TopDocs {
 	// In case pq was populated with sentinel values, there might be less
 	// results than pq.size(). Therefore return all results until either
 	// pq.size() or totalHits.
-	return c.TopDocsRange(0, c.topDocsSize())
+	return c.TopDocsRange(0, c.TopDocsCreator.topDocsSize())
 }
 
 func (c *abstractTopDocsCollector) TopDocsRange(start, howMany int) TopDocs {
 	// In case pq was populated with sentinel values, there might be less
 	// results than pq.size(). Therefore return all results until either
 	// pq.size() or totalHits.
-	size := c.topDocsSize()
+	size := c.TopDocsCreator.topDocsSize()
 
 	// Don't bother to throw an exception, just return an empty TopDocs in case
 	// the parameters are invalid or out of range.
@@ -185,7 +192,7 @@ func (c *abstractTopDocsCollector) TopDocsRange(start, howMany int) TopDocs {
 	}
 
 	// Get the requested results from pq.
-	c.populateResults(results, howMany)
+	c.TopDocsCreator.populateResults(results, howMany)
 
 	return c.newTopDocs(results, start)
 }
@@ -243,8 +250,9 @@ func (c *TopScoreDocCollector) newTopDocs(results []*ScoreDoc, start int) TopDoc
 	return TopDocs{c.TotalHits, results, maxScore}
 }
 
-func (c *TopScoreDocCollector) SetNextReader(ctx *index.AtomicReaderContext) {
+func (c *TopScoreDocCollector) SetNextReader(ctx *index.AtomicReaderContext) error {
 	c.docBase = ctx.DocBase
+	return nil
 }
 
 func (c *TopScoreDocCollector) SetScorer(scorer Scorer) {
@@ -260,13 +268,12 @@ func NewTopScoreDocCollector(numHits int, after *ScoreDoc, docsScoredInOrder boo
 		if after == nil {
 			return newInOrderTopScoreDocCollector(numHits)
 		}
-		panic("not implemented yet")
-		// TODO support paging
+		return newInOrderPagingScoreDocCollector(numHits, after)
 	} else {
 		if after == nil {
 			return newOutOfOrderTopScoreDocCollector(numHits)
 		}
-		panic("not implemented yet")
+		return newOutOfOrderPagingScoreDocCollector(numHits, after)
 	}
 }
 
@@ -344,3 +351,133 @@ func (c *OutOfOrderTopScoreDocCollector) Collect(doc int) (err error) {
 func (c *OutOfOrderTopScoreDocCollector) AcceptsDocsOutOfOrder() bool {
 	return true
 }
+
+/*
+Base of the collectors that only collect the hits sorting after a
+given ScoreDoc, ie the next page of results after a previous search.
+*/
+type pagingTopScoreDocCollector struct {
+	*TopScoreDocCollector
+	after *ScoreDoc
+	// after.Doc relative to the current reader
+	afterDoc      int
+	collectedHits int
+}
+
+func newPagingTopScoreDocCollector(numHits int, after *ScoreDoc) *pagingTopScoreDocCollector {
+	c := &pagingTopScoreDocCollector{
+		TopScoreDocCollector: newTocScoreDocCollector(numHits),
+		after:                after,
+	}
+	c.TopDocsCreator = c
+	return c
+}
+
+func (c *pagingTopScoreDocCollector) SetNextReader(ctx *index.AtomicReaderContext) error {
+	c.docBase = ctx.DocBase
+	c.afterDoc = c.after.Doc - ctx.DocBase
+	return nil
+}
+
+// Returns true if the hit was collected on a previous page.
+func (c *pagingTopScoreDocCollector) collectedBefore(doc int, score float32) bool {
+	return score > c.after.Score || score == c.after.Score && doc <= c.afterDoc
+}
+
+func (c *pagingTopScoreDocCollector) topDocsSize() int {
+	// Hits of previous pages are counted in TotalHits, but never enter
+	// the queue
+	if n := c.pq.Len(); c.collectedHits >= n {
+		return n
+	}
+	return c.collectedHits
+}
+
+func (c *pagingTopScoreDocCollector) newTopDocs(results []*ScoreDoc, start int) TopDocs {
+	if results == nil {
+		return TopDocs{c.TotalHits, []*ScoreDoc{}, math.NaN()}
+	}
+	// The max score was on a previous page
+	return TopDocs{c.TotalHits, results, math.NaN()}
+}
+
+// Assumes docs are scored in order.
+type InOrderPagingScoreDocCollector struct {
+	*pagingTopScoreDocCollector
+}
+
+func newInOrderPagingScoreDocCollector(numHits int, after *ScoreDoc) *InOrderPagingScoreDocCollector {
+	return &InOrderPagingScoreDocCollector{newPagingTopScoreDocCollector(numHits, after)}
+}
+
+func (c *InOrderPagingScoreDocCollector) Collect(doc int) error {
+	score, err := c.scorer.Score()
+	if err != nil {
+		return err
+	}
+
+	// This collector cannot handle these scores:
+	assert(score != -math.MaxFloat32)
+	assert(!math.IsNaN(float64(score)))
+
+	c.TotalHits++
+	if c.collectedBefore(doc, score) {
+		return nil
+	}
+	if score <= c.pqTop.Score {
+		// Since docs are returned in-order (i.e., increasing doc Id), a document
+		// with equal score to pqTop.score cannot compete since HitQueue favors
+		// documents with lower doc Ids. Therefore reject those docs too.
+		return nil
+	}
+	c.collectedHits++
+	c.pqTop.Doc = doc + c.docBase
+	c.pqTop.Score = score
+	c.pqTop = c.pq.updateTop().(*ScoreDoc)
+	return nil
+}
+
+func (c *InOrderPagingScoreDocCollector) AcceptsDocsOutOfOrder() bool {
+	return false
+}
+
+type OutOfOrderPagingScoreDocCollector struct {
+	*pagingTopScoreDocCollector
+}
+
+func newOutOfOrderPagingScoreDocCollector(numHits int, after *ScoreDoc) *OutOfOrderPagingScoreDocCollector {
+	return &OutOfOrderPagingScoreDocCollector{newPagingTopScoreDocCollector(numHits, after)}
+}
+
+func (c *OutOfOrderPagingScoreDocCollector) Collect(doc int) error {
+	score, err := c.scorer.Score()
+	if err != nil {
+		return err
+	}
+
+	// This collector cannot handle NaN
+	assert(!math.IsNaN(float64(score)))
+
+	c.TotalHits++
+	if c.collectedBefore(doc, score) {
+		return nil
+	}
+	if score < c.pqTop.Score {
+		// Doesn't compete w/ bottom entry in queue
+		return nil
+	}
+	doc += c.docBase
+	if score == c.pqTop.Score && doc > c.pqTop.Doc {
+		// Break tie in score by doc ID:
+		return nil
+	}
+	c.collectedHits++
+	c.pqTop.Doc = doc
+	c.pqTop.Score = score
+	c.pqTop = c.pq.updateTop().(*ScoreDoc)
+	return nil
+}
+
+func (c *OutOfOrderPagingScoreDocCollector) AcceptsDocsOutOfOrder() bool {
+	return true
+}
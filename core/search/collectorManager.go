@@ -0,0 +1,89 @@
+package search
+
+// search/CollectorManager.java
+
+/*
+A manager of collectors. This type is useful to parallelize execution
+of search requests and has two methods:
+
+NewCollector() creates a new Collector which will be used to collect
+a certain set of leaves.
+
+Reduce() reduces the results of individual collections into a
+meaningful result. For instance a TopDocsCollector's manager would
+merge the TopDocs of each collector into a single one.
+
+Each Collector is only ever used by a single goroutine, but Reduce()
+sees all of them, once they are all done.
+*/
+type CollectorManager interface {
+	// Return a new Collector. This must return a different instance
+	// on each call.
+	NewCollector() (Collector, error)
+	// Reduce the results of individual collectors into a meaningful
+	// result. For instance a TopDocsCollector's manager would compute
+	// the top docs of each collector and then merge them. This method
+	// is called after all collectors have finished collecting.
+	Reduce(collectors []Collector) (interface{}, error)
+}
+
+/* Manages TopScoreDocCollectors; reduces to a merged TopDocs. */
+type topScoreDocCollectorManager struct {
+	numHits           int
+	after             *ScoreDoc
+	docsScoredInOrder bool
+}
+
+func (m *topScoreDocCollectorManager) NewCollector() (Collector, error) {
+	return NewTopScoreDocCollector(m.numHits, m.after, m.docsScoredInOrder), nil
+}
+
+func (m *topScoreDocCollectorManager) Reduce(collectors []Collector) (interface{}, error) {
+	if len(collectors) == 1 {
+		return collectors[0].(TopDocsCollector).TopDocs(), nil
+	}
+	shardHits := make([]TopDocs, len(collectors))
+	for i, c := range collectors {
+		shardHits[i] = c.(TopDocsCollector).TopDocs()
+	}
+	merged := MergeTopDocs(m.numHits, shardHits)
+	clearShardIndex(merged.ScoreDocs)
+	return merged, nil
+}
+
+/* Manages TopFieldCollectors; reduces to a merged TopFieldDocs. */
+type topFieldCollectorManager struct {
+	sort                                      *Sort
+	numHits                                   int
+	after                                     *FieldDoc
+	fillFields, trackDocScores, trackMaxScore bool
+	docsScoredInOrder                         bool
+}
+
+func (m *topFieldCollectorManager) NewCollector() (Collector, error) {
+	return NewTopFieldCollector(m.sort, m.numHits, m.after, m.fillFields,
+		m.trackDocScores, m.trackMaxScore, m.docsScoredInOrder), nil
+}
+
+func (m *topFieldCollectorManager) Reduce(collectors []Collector) (interface{}, error) {
+	if len(collectors) == 1 {
+		return collectors[0].(*TopFieldCollector).TopFieldDocs(), nil
+	}
+	shardHits := make([]TopFieldDocs, len(collectors))
+	for i, c := range collectors {
+		shardHits[i] = c.(*TopFieldCollector).TopFieldDocs()
+	}
+	merged := MergeTopFieldDocs(m.sort, m.numHits, shardHits)
+	clearShardIndex(merged.ScoreDocs)
+	return merged, nil
+}
+
+/*
+The slices of a single searcher are not shards: reset the ShardIndex
+set by merging their hits.
+*/
+func clearShardIndex(hits []*ScoreDoc) {
+	for _, hit := range hits {
+		hit.ShardIndex = -1
+	}
+}
@@ -0,0 +1,122 @@
+package search
+
+import (
+	"fmt"
+	. "github.com/gzg1984/golucene/core/search/model"
+)
+
+// search/ConjunctionScorer.java
+
+type docsAndFreqs struct {
+	scorer Scorer
+	doc    int
+}
+
+/* Scorer for conjunctions, sets of queries, all of which are required. */
+type ConjunctionScorer struct {
+	*abstractScorer
+	lastDoc      int
+	docsAndFreqs []*docsAndFreqs
+	lead         *docsAndFreqs
+	coord        float32
+}
+
+func newConjunctionScorer(w Weight, scorers []Scorer, coord float32) *ConjunctionScorer {
+	ans := &ConjunctionScorer{
+		lastDoc:      -1,
+		docsAndFreqs: make([]*docsAndFreqs, len(scorers)),
+		coord:        coord,
+	}
+	for i, scorer := range scorers {
+		ans.docsAndFreqs[i] = &docsAndFreqs{scorer: scorer, doc: -1}
+	}
+	// TODO: sort by cost once DocIdSetIterator exposes it, so that the
+	// least frequent scorer leads the matching.
+	ans.lead = ans.docsAndFreqs[0]
+	ans.abstractScorer = newScorer(ans, w)
+	return ans
+}
+
+func (s *ConjunctionScorer) doNext(doc int) (int, error) {
+	var err error
+	for {
+		// doc may already be NO_MORE_DOCS here, but we don't check
+		// explicitly since all scorers should advance to NO_MORE_DOCS,
+		// match, then return that value.
+		matched := true
+		for _, df := range s.docsAndFreqs[1:] {
+			// invariant: df.doc <= doc at this point.
+			// df.doc may already be equal to doc if we "broke advanceHead"
+			// on the previous iteration and the advance on the lead
+			// scorer exactly matched.
+			if df.doc < doc {
+				if df.doc, err = df.scorer.Advance(doc); err != nil {
+					return 0, err
+				}
+				if df.doc > doc {
+					// DocsEnum beyond the current doc - break and advance
+					// lead to the new highest doc.
+					doc = df.doc
+					matched = false
+					break
+				}
+			}
+		}
+		if matched {
+			// success - all DocsEnums are on the same doc
+			return doc, nil
+		}
+		// advance head for next iteration
+		if s.lead.doc, err = s.lead.scorer.Advance(doc); err != nil {
+			return 0, err
+		}
+		doc = s.lead.doc
+	}
+}
+
+func (s *ConjunctionScorer) Advance(target int) (doc int, err error) {
+	if s.lead.doc, err = s.lead.scorer.Advance(target); err != nil {
+		return 0, err
+	}
+	if s.lastDoc, err = s.doNext(s.lead.doc); err != nil {
+		return 0, err
+	}
+	return s.lastDoc, nil
+}
+
+func (s *ConjunctionScorer) DocId() int {
+	return s.lastDoc
+}
+
+func (s *ConjunctionScorer) NextDoc() (doc int, err error) {
+	if s.lead.doc, err = s.lead.scorer.NextDoc(); err != nil {
+		return 0, err
+	}
+	if s.lastDoc, err = s.doNext(s.lead.doc); err != nil {
+		return 0, err
+	}
+	return s.lastDoc, nil
+}
+
+func (s *ConjunctionScorer) Score() (float32, error) {
+	assert(s.lastDoc != NO_MORE_DOCS)
+	// TODO: sum into a double and cast to float if we ever send
+	// required clauses to BS1
+	var sum float32
+	for _, df := range s.docsAndFreqs {
+		score, err := df.scorer.Score()
+		if err != nil {
+			return 0, err
+		}
+		sum += score
+	}
+	return sum * s.coord, nil
+}
+
+func (s *ConjunctionScorer) Freq() (int, error) {
+	return len(s.docsAndFreqs), nil
+}
+
+func (s *ConjunctionScorer) String() string {
+	return fmt.Sprintf("ConjunctionScorer(%v)", s.weight)
+}
@@ -0,0 +1,227 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/search/model"
+	"github.com/gzg1984/golucene/core/util"
+)
+
+// search/ConstantScoreQuery.java
+
+/*
+A query that wraps another query or a filter and simply returns a
+constant score equal to the query boost for every document that
+matches the filter or query. For queries it therefore simply strips
+of all scores and returns a constant one.
+*/
+type ConstantScoreQuery struct {
+	*AbstractQuery
+	filter Filter
+	query  Query
+}
+
+/*
+Strips off scores from the passed in Query. The hits will get a
+constant score dependent on the boost factor of this query.
+*/
+func NewConstantScoreQuery(query Query) *ConstantScoreQuery {
+	assert2(query != nil, "Query may not be null")
+	ans := &ConstantScoreQuery{query: query}
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+/*
+Wraps a Filter as a Query. The hits will get a constant score
+dependent on the boost factor of this query. If you simply want to
+strip off scores from a Query, no longer use
+NewConstantScoreQueryWithFilter(NewQueryWrapperFilter(query)),
+instead use NewConstantScoreQuery(query)!
+*/
+func NewConstantScoreQueryWithFilter(filter Filter) *ConstantScoreQuery {
+	assert2(filter != nil, "Filter may not be null")
+	ans := &ConstantScoreQuery{filter: filter}
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+// Returns the encapsulated filter, returns nil if a query is wrapped.
+func (q *ConstantScoreQuery) Filter() Filter {
+	return q.filter
+}
+
+// Returns the encapsulated query, returns nil if a filter is wrapped.
+func (q *ConstantScoreQuery) Query() Query {
+	return q.query
+}
+
+func (q *ConstantScoreQuery) Rewrite(reader index.IndexReader) (Query, error) {
+	if q.query != nil {
+		rewritten, err := q.query.Rewrite(reader)
+		if err != nil {
+			return nil, err
+		}
+		if rewritten != q.query {
+			ans := NewConstantScoreQuery(rewritten)
+			ans.SetBoost(q.boost)
+			return ans, nil
+		}
+	}
+	return q, nil
+}
+
+func (q *ConstantScoreQuery) CreateWeight(searcher *IndexSearcher) (Weight, error) {
+	return newConstantWeight(q, searcher)
+}
+
+func (q *ConstantScoreQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	buf.WriteString("ConstantScore(")
+	if q.query == nil {
+		buf.WriteString(fmt.Sprintf("%v", q.filter))
+	} else {
+		buf.WriteString(q.query.ToString(field))
+	}
+	buf.WriteRune(')')
+	if q.boost != 1 {
+		buf.WriteString(fmt.Sprintf("^%v", q.boost))
+	}
+	return buf.String()
+}
+
+type ConstantWeight struct {
+	*WeightImpl
+	owner       *ConstantScoreQuery
+	innerWeight Weight
+	queryNorm   float32
+	queryWeight float32
+}
+
+func newConstantWeight(owner *ConstantScoreQuery, searcher *IndexSearcher) (*ConstantWeight, error) {
+	ans := &ConstantWeight{owner: owner}
+	if owner.query != nil {
+		var err error
+		if ans.innerWeight, err = owner.query.CreateWeight(searcher); err != nil {
+			return nil, err
+		}
+	}
+	ans.WeightImpl = newWeightImpl(ans)
+	return ans, nil
+}
+
+func (w *ConstantWeight) ValueForNormalization() float32 {
+	// we calculate sumOfSquaredWeights of the inner weight, but ignore
+	// it (just to initialize everything)
+	if w.innerWeight != nil {
+		w.innerWeight.ValueForNormalization()
+	}
+	w.queryWeight = w.owner.boost
+	return w.queryWeight * w.queryWeight
+}
+
+func (w *ConstantWeight) Normalize(norm float32, topLevelBoost float32) {
+	w.queryNorm = norm * topLevelBoost
+	w.queryWeight *= w.queryNorm
+	// we normalize the inner weight, but ignore it (just to initialize
+	// everything)
+	if w.innerWeight != nil {
+		w.innerWeight.Normalize(norm, topLevelBoost)
+	}
+}
+
+func (w *ConstantWeight) Scorer(ctx *index.AtomicReaderContext,
+	acceptDocs util.Bits) (Scorer, error) {
+
+	var disi DocIdSetIterator
+	if w.owner.filter != nil {
+		assert(w.owner.query == nil)
+		dis, err := w.owner.filter.DocIdSet(ctx, acceptDocs)
+		if dis == nil || err != nil {
+			return nil, err
+		}
+		if disi, err = dis.Iterator(); err != nil {
+			return nil, err
+		}
+	} else {
+		assert(w.owner.query != nil && w.innerWeight != nil)
+		scorer, err := w.innerWeight.Scorer(ctx, acceptDocs)
+		if err != nil {
+			return nil, err
+		}
+		if scorer != nil {
+			disi = scorer
+		}
+	}
+
+	if disi == nil {
+		return nil, nil
+	}
+	return newConstantScorer(disi, w, w.queryWeight), nil
+}
+
+func (w *ConstantWeight) IsScoresDocsOutOfOrder() bool {
+	return false
+}
+
+func (w *ConstantWeight) Explain(ctx *index.AtomicReaderContext, doc int) (Explanation, error) {
+	cs, err := w.Scorer(ctx, ctx.Reader().(index.AtomicReader).LiveDocs())
+	if err != nil {
+		return nil, err
+	}
+	exists := false
+	if cs != nil {
+		target, err := cs.Advance(doc)
+		if err != nil {
+			return nil, err
+		}
+		exists = target == doc
+	}
+
+	if exists {
+		result := newComplexExplanation(true, w.queryWeight,
+			fmt.Sprintf("%v, product of:", w.owner))
+		result.addDetail(newExplanation(w.owner.boost, "boost"))
+		result.addDetail(newExplanation(w.queryNorm, "queryNorm"))
+		return result, nil
+	}
+	return newComplexExplanation(false, 0,
+		fmt.Sprintf("%v doesn't match id %v", w.owner, doc)), nil
+}
+
+type ConstantScorer struct {
+	*abstractScorer
+	docIdSetIterator DocIdSetIterator
+	theScore         float32
+}
+
+func newConstantScorer(docIdSetIterator DocIdSetIterator, w Weight, theScore float32) *ConstantScorer {
+	ans := &ConstantScorer{
+		docIdSetIterator: docIdSetIterator,
+		theScore:         theScore,
+	}
+	ans.abstractScorer = newScorer(ans, w)
+	return ans
+}
+
+func (s *ConstantScorer) NextDoc() (int, error) {
+	return s.docIdSetIterator.NextDoc()
+}
+
+func (s *ConstantScorer) DocId() int {
+	return s.docIdSetIterator.DocId()
+}
+
+func (s *ConstantScorer) Score() (float32, error) {
+	assert(s.docIdSetIterator.DocId() != NO_MORE_DOCS)
+	return s.theScore, nil
+}
+
+func (s *ConstantScorer) Freq() (int, error) {
+	return 1, nil
+}
+
+func (s *ConstantScorer) Advance(target int) (int, error) {
+	return s.docIdSetIterator.Advance(target)
+}
@@ -0,0 +1,242 @@
+package search
+
+import (
+	"github.com/gzg1984/golucene/core/index"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// search/ControlledRealTimeReopenThread.java
+
+/*
+Utility class that runs a goroutine to manage periodic reopens of a
+ReferenceManager, with methods to wait for a specific index changes
+to become visible. To use this class you must first wrap your
+IndexWriter with a TrackingIndexWriter and always use it to make
+changes to the index, saving the returned generation. Then, when a
+given search request needs to see a specific index change, call the
+WaitForGeneration() to wait for that change to be visible. Note that
+this will only scale well if most searches do not need to wait for a
+specific index generation.
+
+The reopen goroutine is started by the constructor and stopped by
+Close().
+*/
+type ControlledRealTimeReopenThread struct {
+	manager        *ReferenceManager
+	targetMaxStale time.Duration
+	targetMinStale time.Duration
+	writer         *index.TrackingIndexWriter
+	listener       *handleRefresh
+
+	sync.Mutex      // synchronized
+	waitingGen      int64
+	searchingGen    int64
+	refreshStartGen int64
+	// closed, and replaced, each time searchingGen is updated
+	searchingGenChanged chan struct{}
+
+	reopen chan struct{} // wakes up the reopen goroutine
+	finish chan struct{} // closed to stop the reopen goroutine
+	done   chan struct{} // closed once the reopen goroutine exited
+}
+
+/*
+Create ControlledRealTimeReopenThread, to periodically reopen the
+ReferenceManager (for example, a SearcherManager's).
+
+targetMaxStale is the maximum time until a new reader must be
+opened; this sets the upper bound on how slowly reopens may occur,
+when no caller is waiting for a specific generation to become
+visible.
+
+targetMinStale is the mininum time until a new reader can be opened;
+this sets the lower bound on how quickly reopens may occur, when a
+caller is waiting for a specific generation to become visible.
+*/
+func NewControlledRealTimeReopenThread(writer *index.TrackingIndexWriter,
+	manager *ReferenceManager, targetMaxStale, targetMinStale time.Duration) *ControlledRealTimeReopenThread {
+
+	assert2(targetMaxStale >= targetMinStale,
+		"targetMaxStale (= %v) must be >= targetMinStale (=%v)", targetMaxStale, targetMinStale)
+	t := &ControlledRealTimeReopenThread{
+		manager:             manager,
+		targetMaxStale:      targetMaxStale,
+		targetMinStale:      targetMinStale,
+		writer:              writer,
+		searchingGenChanged: make(chan struct{}),
+		reopen:              make(chan struct{}, 1),
+		finish:              make(chan struct{}),
+		done:                make(chan struct{}),
+	}
+	t.listener = &handleRefresh{t}
+	manager.AddListener(t.listener)
+	go t.run()
+	return t
+}
+
+type handleRefresh struct {
+	owner *ControlledRealTimeReopenThread
+}
+
+func (h *handleRefresh) BeforeRefresh() error {
+	return nil
+}
+
+func (h *handleRefresh) AfterRefresh(didRefresh bool) error {
+	h.owner.refreshDone()
+	return nil
+}
+
+func (t *ControlledRealTimeReopenThread) refreshDone() {
+	t.Lock() // synchronized
+	defer t.Unlock()
+	t.setSearchingGen(t.refreshStartGen)
+}
+
+/* Requires t's lock. */
+func (t *ControlledRealTimeReopenThread) setSearchingGen(gen int64) {
+	t.searchingGen = gen
+	close(t.searchingGenChanged)
+	t.searchingGenChanged = make(chan struct{})
+}
+
+/*
+Stops the reopen goroutine, and releases all goroutines waiting in
+WaitForGeneration().
+*/
+func (t *ControlledRealTimeReopenThread) Close() error {
+	t.manager.RemoveListener(t.listener)
+	close(t.finish)
+	<-t.done
+
+	t.Lock()
+	defer t.Unlock()
+	// Max it out so that all waiting search goroutines will return:
+	t.setSearchingGen(math.MaxInt64)
+	return nil
+}
+
+/*
+Waits for the target generation to become visible in the searcher.
+If the current searcher is older than the target generation, this
+method will block until the searcher is reopened, by another goroutine via
+MaybeRefresh() or until the ReferenceManager is closed.
+*/
+func (t *ControlledRealTimeReopenThread) WaitForGeneration(targetGen int64) {
+	t.WaitForGenerationTimeout(targetGen, -1)
+}
+
+/*
+Waits for the target generation to become visible in the searcher,
+up to a maximum specified wait time. If the current searcher is
+older than the target generation, this method will block until the
+searcher has been reopened by another goroutine via MaybeRefresh(),
+the given waiting time has elapsed, or until the ReferenceManager is
+closed.
+
+A negative maxWait waits indefinitely. Returns true if the
+targetGen is now available, or false if maxWait was exceeded.
+*/
+func (t *ControlledRealTimeReopenThread) WaitForGenerationTimeout(targetGen int64, maxWait time.Duration) bool {
+	t.Lock() // synchronized
+	defer t.Unlock()
+
+	curGen := t.writer.Generation()
+	assert2(targetGen <= curGen,
+		"targetGen=%v was never returned by the ReferenceManager instance (current gen=%v)",
+		targetGen, curGen)
+	if targetGen > t.searchingGen {
+		// Notify the reopen goroutine that the waitingGen has changed,
+		// so it may wake up and realize it should not sleep for much or
+		// any longer before reopening:
+		if targetGen > t.waitingGen {
+			t.waitingGen = targetGen
+		}
+		select {
+		case t.reopen <- struct{}{}:
+		default:
+		}
+
+		var timeout <-chan time.Time
+		if maxWait >= 0 {
+			timer := time.NewTimer(maxWait)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		for targetGen > t.searchingGen {
+			changed := t.searchingGenChanged
+			t.Unlock()
+			select {
+			case <-changed:
+				t.Lock()
+			case <-timeout:
+				t.Lock()
+				return targetGen <= t.searchingGen
+			}
+		}
+	}
+	return true
+}
+
+func (t *ControlledRealTimeReopenThread) run() {
+	defer close(t.done)
+
+	// TODO: maybe use private goroutine ticktock timer, in case clock
+	// shift messes up wait time?
+	lastReopenStart := time.Now()
+
+	for {
+		for {
+			// True if we have someone waiting for reopened searcher:
+			t.Lock()
+			hasWaiting := t.waitingGen > t.searchingGen
+			t.Unlock()
+
+			nextReopenStart := lastReopenStart.Add(t.targetMaxStale)
+			if hasWaiting {
+				nextReopenStart = lastReopenStart.Add(t.targetMinStale)
+			}
+			sleep := time.Until(nextReopenStart)
+			if sleep <= 0 {
+				break
+			}
+			timer := time.NewTimer(sleep)
+			select {
+			case <-t.finish:
+				timer.Stop()
+				return
+			case <-t.reopen:
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+
+		select {
+		case <-t.finish:
+			return
+		default:
+		}
+
+		lastReopenStart = time.Now()
+		// Save the gen as of when we started the reopen; the listener
+		// (handleRefresh above) copies this to searchingGen once the
+		// reopen completes:
+		gen := t.writer.GetAndIncrementGeneration()
+		t.Lock()
+		t.refreshStartGen = gen
+		t.Unlock()
+		if err := t.manager.MaybeRefreshBlocking(); err != nil {
+			log.Printf("ControlledRealTimeReopenThread: refresh failed: %v", err)
+		}
+	}
+}
+
+/* Returns which generation the current searcher is guaranteed to include. */
+func (t *ControlledRealTimeReopenThread) SearchingGen() int64 {
+	t.Lock() // synchronized
+	defer t.Unlock()
+	return t.searchingGen
+}
@@ -0,0 +1,242 @@
+package search
+
+import (
+	"fmt"
+	. "github.com/gzg1984/golucene/core/search/model"
+)
+
+// search/DisjunctionScorer.java
+
+/*
+Base class for Scorers that score disjunctions. Currently this just
+provides helper methods to manage the heap.
+*/
+type DisjunctionScorer struct {
+	*abstractScorer
+	subScorers []Scorer
+	// The number of subscorers.
+	numScorers int
+	// The document number of the current match.
+	doc int
+	// Number of matching scorers for the current match, -1 if not
+	// computed yet.
+	freq int
+	// The score of the current match, summed by countMatches().
+	score float64
+}
+
+func newDisjunctionScorer(spi ScorerSPI, w Weight, subScorers []Scorer) *DisjunctionScorer {
+	ans := &DisjunctionScorer{
+		abstractScorer: newScorer(spi, w),
+		subScorers:     subScorers,
+		numScorers:     len(subScorers),
+		doc:            -1,
+		freq:           -1,
+	}
+	ans.heapify()
+	return ans
+}
+
+/*
+Organize subScorers into a min heap with scorers generating the
+earliest document on top.
+*/
+func (s *DisjunctionScorer) heapify() {
+	for i := (s.numScorers >> 1) - 1; i >= 0; i-- {
+		s.heapAdjust(i)
+	}
+}
+
+/*
+The subtree of subScorers at root is a min heap except possibly for
+its root element. Bubble the root down as required to make the
+subtree a heap.
+*/
+func (s *DisjunctionScorer) heapAdjust(root int) {
+	scorer := s.subScorers[root]
+	doc := scorer.DocId()
+	i := root
+	for i <= (s.numScorers>>1)-1 {
+		lchild := (i << 1) + 1
+		lscorer := s.subScorers[lchild]
+		ldoc := lscorer.DocId()
+		rdoc, rchild := NO_MORE_DOCS, (i<<1)+2
+		var rscorer Scorer
+		if rchild < s.numScorers {
+			rscorer = s.subScorers[rchild]
+			rdoc = rscorer.DocId()
+		}
+		if ldoc < doc {
+			if rdoc < ldoc {
+				s.subScorers[i] = rscorer
+				s.subScorers[rchild] = scorer
+				i = rchild
+			} else {
+				s.subScorers[i] = lscorer
+				s.subScorers[lchild] = scorer
+				i = lchild
+			}
+		} else if rdoc < doc {
+			s.subScorers[i] = rscorer
+			s.subScorers[rchild] = scorer
+			i = rchild
+		} else {
+			return
+		}
+	}
+}
+
+/*
+Remove the root Scorer from subScorers and re-establish it as a heap.
+*/
+func (s *DisjunctionScorer) heapRemoveRoot() {
+	if s.numScorers == 1 {
+		s.subScorers[0] = nil
+		s.numScorers = 0
+	} else {
+		s.subScorers[0] = s.subScorers[s.numScorers-1]
+		s.subScorers[s.numScorers-1] = nil
+		s.numScorers--
+		s.heapAdjust(0)
+	}
+}
+
+func (s *DisjunctionScorer) DocId() int {
+	return s.doc
+}
+
+func (s *DisjunctionScorer) NextDoc() (int, error) {
+	assert(s.doc != NO_MORE_DOCS)
+	for {
+		doc, err := s.subScorers[0].NextDoc()
+		if err != nil {
+			return 0, err
+		}
+		if doc != NO_MORE_DOCS {
+			s.heapAdjust(0)
+		} else {
+			s.heapRemoveRoot()
+			if s.numScorers == 0 {
+				s.doc = NO_MORE_DOCS
+				return s.doc, nil
+			}
+		}
+		if docId := s.subScorers[0].DocId(); docId != s.doc {
+			s.doc, s.freq = docId, -1
+			return s.doc, nil
+		}
+	}
+}
+
+func (s *DisjunctionScorer) Advance(target int) (int, error) {
+	assert(s.doc != NO_MORE_DOCS)
+	for {
+		doc, err := s.subScorers[0].Advance(target)
+		if err != nil {
+			return 0, err
+		}
+		if doc != NO_MORE_DOCS {
+			s.heapAdjust(0)
+		} else {
+			s.heapRemoveRoot()
+			if s.numScorers == 0 {
+				s.doc = NO_MORE_DOCS
+				return s.doc, nil
+			}
+		}
+		if docId := s.subScorers[0].DocId(); docId >= target {
+			s.doc, s.freq = docId, -1
+			return s.doc, nil
+		}
+	}
+}
+
+/*
+Counts the sub scorers positioned on the current document and sums
+their scores. This is done lazily, only when Score() or Freq() is
+called for the current document.
+*/
+func (s *DisjunctionScorer) countMatches() error {
+	if s.freq >= 0 {
+		return nil
+	}
+	s.freq, s.score = 0, 0
+	return s.visit(0)
+}
+
+func (s *DisjunctionScorer) visit(root int) error {
+	if root < s.numScorers && s.subScorers[root].DocId() == s.doc {
+		score, err := s.subScorers[root].Score()
+		if err != nil {
+			return err
+		}
+		s.freq++
+		s.score += float64(score)
+		if err = s.visit((root << 1) + 1); err != nil {
+			return err
+		}
+		return s.visit((root << 1) + 2)
+	}
+	return nil
+}
+
+func (s *DisjunctionScorer) Freq() (int, error) {
+	if err := s.countMatches(); err != nil {
+		return 0, err
+	}
+	return s.freq, nil
+}
+
+// search/DisjunctionSumScorer.java
+
+/*
+A Scorer for OR like queries, counterpart of ConjunctionScorer.
+This Scorer implements Scorer.Advance() and uses Advance() on the
+given Scorers.
+*/
+type DisjunctionSumScorer struct {
+	*DisjunctionScorer
+	coord []float32
+}
+
+/*
+Construct a DisjunctionScorer.
+
+weight: The weight to be used.
+subScorers: Array of at least two subscorers.
+coord: Table of coordination factors; nil when the caller applies
+the coordination factor itself, in which case Score() returns the
+raw sum.
+*/
+func newDisjunctionSumScorer(w Weight, subScorers []Scorer, coord []float32) *DisjunctionSumScorer {
+	assert2(len(subScorers) > 1, "There must be at least 2 subScorers")
+	ans := &DisjunctionSumScorer{coord: coord}
+	ans.DisjunctionScorer = newDisjunctionScorer(ans, w, subScorers)
+	return ans
+}
+
+func (s *DisjunctionSumScorer) NextDoc() (int, error) {
+	return s.DisjunctionScorer.NextDoc()
+}
+
+func (s *DisjunctionSumScorer) DocId() int {
+	return s.doc
+}
+
+/*
+Returns the score of the current document matching the query.
+Initially invalid, until NextDoc() is called the first time.
+*/
+func (s *DisjunctionSumScorer) Score() (float32, error) {
+	if err := s.countMatches(); err != nil {
+		return 0, err
+	}
+	if s.coord == nil {
+		return float32(s.score), nil
+	}
+	return float32(s.score * float64(s.coord[s.freq])), nil
+}
+
+func (s *DisjunctionSumScorer) String() string {
+	return fmt.Sprintf("DisjunctionSumScorer(%v)", s.weight)
+}
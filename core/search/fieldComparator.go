@@ -0,0 +1,821 @@
+package search
+
+import (
+	"bytes"
+	. "github.com/gzg1984/golucene/core/codec/spi"
+	"github.com/gzg1984/golucene/core/index"
+	"github.com/gzg1984/golucene/core/util"
+	"math"
+)
+
+// search/FieldComparator.java
+
+/*
+Expert: a FieldComparator compares hits so as to determine their sort
+order when collecting the top results with TopFieldCollector. The
+concrete public FieldComparator types here correspond to the
+SortField types.
+
+This API is designed to achieve high performance sorting, by exposing
+a tight interaction with FieldValueHitQueue as it visits hits.
+Whenever a hit is competitive, it's enrolled into a virtual slot,
+which is an int ranging from 0 to numHits-1. The FieldComparator is
+made aware of segment transitions during searching in case any
+internal state it's tracking needs to be recomputed during these
+transitions.
+
+A comparator must define these functions:
+
+Compare() compares a hit at 'slot a' with hit 'slot b'.
+
+SetBottom() is called by FieldValueHitQueue to notify the
+FieldComparator of the current weakest ("bottom") slot. Note that
+this slot may not hold the weakest value according to your
+comparator, in cases where your comparator is not the primary one
+(ie, is only used to break ties from the comparators before it).
+
+CompareBottom() compares a new hit (docID) against the "weakest"
+(bottom) entry in the queue.
+
+SetTopValue() is called by TopFieldCollector to notify the
+FieldComparator of the top most value, which is used by future calls
+to CompareTop(). This is only used when paging with SearchAfter.
+
+CompareTop() compares a new hit (docID) against the top value
+previously set by a call to SetTopValue().
+
+Copy() installs a new hit into the priority queue. The
+FieldValueHitQueue calls this method when a new hit is competitive.
+
+SetNextReader() is invoked when the search is switching to the next
+segment. You may need to update internal state of the comparator, for
+example retrieving new values from doc values.
+
+Value() returns the sort value stored in the specified slot. This is
+only called at the end of the search, in order to populate the
+FieldDoc's Fields when returning the top results.
+*/
+type FieldComparator interface {
+	// Compare hit at slot1 with hit at slot2. Returns any N < 0 if
+	// slot2's value is sorted after slot1, any N > 0 if the slot2's
+	// value is sorted before slot1 and 0 if they are equal.
+	Compare(slot1, slot2 int) int
+	// Set the bottom slot, ie the "weakest" (sorted last) entry in the
+	// queue. When CompareBottom() is called, you should compare against
+	// this slot. This will always be called before CompareBottom().
+	SetBottom(slot int)
+	// Compare the bottom of the queue with this doc. This will only
+	// invoked after SetBottom() has been called. This should return the
+	// same result as Compare(bottomSlot, otherSlot) as if doc were
+	// copied into otherSlot.
+	CompareBottom(doc int) (int, error)
+	// Record the top value, for future calls to CompareTop(). This is
+	// only called for searches that use SearchAfter (deep paging), and
+	// is called before any calls to SetNextReader().
+	SetTopValue(value interface{})
+	// Compare the top value with this doc. This will only invoked after
+	// SetTopValue() has been called. This should return the same
+	// result as Compare(topSlot, otherSlot) as if the top value were in
+	// topSlot and doc were copied into otherSlot. This is only called
+	// for searches that use SearchAfter (deep paging).
+	CompareTop(doc int) (int, error)
+	// This method is called when a new hit is competitive. You should
+	// copy any state associated with this document that will be
+	// required for future comparisons, into the specified slot.
+	Copy(slot, doc int) error
+	// Set a new AtomicReaderContext. All subsequent docIDs are relative
+	// to the current reader (you must add docBase if you need to map it
+	// to a top-level docID).
+	SetNextReader(ctx *index.AtomicReaderContext) error
+	// Sets the Scorer to use in case a document's score is needed.
+	SetScorer(scorer Scorer)
+	// Return the actual value in the slot.
+	Value(slot int) interface{}
+	// Returns -1 if first is less than second. Default impl assumes
+	// the type implements the natural ordering of the sort values.
+	CompareValues(first, second interface{}) int
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareInt32(a, b int32) int {
+	return compareInt(int(a), int(b))
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Compares like Java's Float.compare(): -0 is less than 0, and NaN is
+// greater than any other value.
+func compareFloat32(a, b float32) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return compareInt32(util.FloatToSortableInt(a), util.FloatToSortableInt(b))
+}
+
+// Compares like Java's Double.compare(): -0 is less than 0, and NaN is
+// greater than any other value.
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return compareInt64(util.DoubleToSortableLong(a), util.DoubleToSortableLong(b))
+}
+
+/*
+Base type for comparators sorting by the NumericDocValues of a field.
+Documents without a value get the missing value of the SortField.
+*/
+type numericComparator struct {
+	field               string
+	hasMissingValue     bool
+	currentReaderValues NumericDocValues
+	docsWithField       util.Bits
+}
+
+func newNumericComparator(field string, hasMissingValue bool) *numericComparator {
+	return &numericComparator{
+		field:           field,
+		hasMissingValue: hasMissingValue,
+	}
+}
+
+func (c *numericComparator) SetNextReader(ctx *index.AtomicReaderContext) (err error) {
+	reader := ctx.Reader().(index.AtomicReader)
+	if c.currentReaderValues, err = index.GetNumericDocValues(reader, c.field); err != nil {
+		return err
+	}
+	// a missing value of zero is what NumericDocValues returns for
+	// documents without value anyway
+	if c.hasMissingValue {
+		if c.docsWithField, err = index.GetDocsWithField(reader, c.field); err != nil {
+			return err
+		}
+		if _, ok := c.docsWithField.(util.MatchAllBits); ok {
+			c.docsWithField = nil
+		}
+	}
+	return nil
+}
+
+func (c *numericComparator) SetScorer(scorer Scorer) {}
+
+// Returns the raw value of doc, and whether doc has no value.
+func (c *numericComparator) value(doc int) (v int64, missing bool) {
+	v = c.currentReaderValues(doc)
+	return v, v == 0 && c.docsWithField != nil && !c.docsWithField.At(doc)
+}
+
+// Parses field's values as int32 (using NumericDocValues) and sorts
+// by ascending value
+type intComparator struct {
+	*numericComparator
+	values       []int32
+	bottom       int32
+	topValue     int32
+	missingValue int32
+}
+
+func newIntComparator(numHits int, field string, missingValue int32) *intComparator {
+	return &intComparator{
+		numericComparator: newNumericComparator(field, missingValue != 0),
+		values:            make([]int32, numHits),
+		missingValue:      missingValue,
+	}
+}
+
+func (c *intComparator) value(doc int) int32 {
+	v, missing := c.numericComparator.value(doc)
+	if missing {
+		return c.missingValue
+	}
+	return int32(v)
+}
+
+func (c *intComparator) Compare(slot1, slot2 int) int {
+	return compareInt32(c.values[slot1], c.values[slot2])
+}
+
+func (c *intComparator) CompareBottom(doc int) (int, error) {
+	return compareInt32(c.bottom, c.value(doc)), nil
+}
+
+func (c *intComparator) Copy(slot, doc int) error {
+	c.values[slot] = c.value(doc)
+	return nil
+}
+
+func (c *intComparator) SetBottom(slot int)         { c.bottom = c.values[slot] }
+func (c *intComparator) SetTopValue(v interface{})  { c.topValue = v.(int32) }
+func (c *intComparator) Value(slot int) interface{} { return c.values[slot] }
+
+func (c *intComparator) CompareTop(doc int) (int, error) {
+	return compareInt32(c.topValue, c.value(doc)), nil
+}
+
+func (c *intComparator) CompareValues(first, second interface{}) int {
+	return compareInt32(first.(int32), second.(int32))
+}
+
+// Parses field's values as int64 (using NumericDocValues) and sorts
+// by ascending value
+type longComparator struct {
+	*numericComparator
+	values       []int64
+	bottom       int64
+	topValue     int64
+	missingValue int64
+}
+
+func newLongComparator(numHits int, field string, missingValue int64) *longComparator {
+	return &longComparator{
+		numericComparator: newNumericComparator(field, missingValue != 0),
+		values:            make([]int64, numHits),
+		missingValue:      missingValue,
+	}
+}
+
+func (c *longComparator) value(doc int) int64 {
+	v, missing := c.numericComparator.value(doc)
+	if missing {
+		return c.missingValue
+	}
+	return v
+}
+
+func (c *longComparator) Compare(slot1, slot2 int) int {
+	return compareInt64(c.values[slot1], c.values[slot2])
+}
+
+func (c *longComparator) CompareBottom(doc int) (int, error) {
+	return compareInt64(c.bottom, c.value(doc)), nil
+}
+
+func (c *longComparator) Copy(slot, doc int) error {
+	c.values[slot] = c.value(doc)
+	return nil
+}
+
+func (c *longComparator) SetBottom(slot int)         { c.bottom = c.values[slot] }
+func (c *longComparator) SetTopValue(v interface{})  { c.topValue = v.(int64) }
+func (c *longComparator) Value(slot int) interface{} { return c.values[slot] }
+
+func (c *longComparator) CompareTop(doc int) (int, error) {
+	return compareInt64(c.topValue, c.value(doc)), nil
+}
+
+func (c *longComparator) CompareValues(first, second interface{}) int {
+	return compareInt64(first.(int64), second.(int64))
+}
+
+// Parses field's values as float32 (using NumericDocValues holding the
+// float bits) and sorts by ascending value
+type floatComparator struct {
+	*numericComparator
+	values       []float32
+	bottom       float32
+	topValue     float32
+	missingValue float32
+}
+
+func newFloatComparator(numHits int, field string, missingValue float32) *floatComparator {
+	return &floatComparator{
+		numericComparator: newNumericComparator(field, missingValue != 0),
+		values:            make([]float32, numHits),
+		missingValue:      missingValue,
+	}
+}
+
+func (c *floatComparator) value(doc int) float32 {
+	v, missing := c.numericComparator.value(doc)
+	if missing {
+		return c.missingValue
+	}
+	return math.Float32frombits(uint32(v))
+}
+
+func (c *floatComparator) Compare(slot1, slot2 int) int {
+	return compareFloat32(c.values[slot1], c.values[slot2])
+}
+
+func (c *floatComparator) CompareBottom(doc int) (int, error) {
+	return compareFloat32(c.bottom, c.value(doc)), nil
+}
+
+func (c *floatComparator) Copy(slot, doc int) error {
+	c.values[slot] = c.value(doc)
+	return nil
+}
+
+func (c *floatComparator) SetBottom(slot int)         { c.bottom = c.values[slot] }
+func (c *floatComparator) SetTopValue(v interface{})  { c.topValue = v.(float32) }
+func (c *floatComparator) Value(slot int) interface{} { return c.values[slot] }
+
+func (c *floatComparator) CompareTop(doc int) (int, error) {
+	return compareFloat32(c.topValue, c.value(doc)), nil
+}
+
+func (c *floatComparator) CompareValues(first, second interface{}) int {
+	return compareFloat32(first.(float32), second.(float32))
+}
+
+// Parses field's values as float64 (using NumericDocValues holding the
+// double bits) and sorts by ascending value
+type doubleComparator struct {
+	*numericComparator
+	values       []float64
+	bottom       float64
+	topValue     float64
+	missingValue float64
+}
+
+func newDoubleComparator(numHits int, field string, missingValue float64) *doubleComparator {
+	return &doubleComparator{
+		numericComparator: newNumericComparator(field, missingValue != 0),
+		values:            make([]float64, numHits),
+		missingValue:      missingValue,
+	}
+}
+
+func (c *doubleComparator) value(doc int) float64 {
+	v, missing := c.numericComparator.value(doc)
+	if missing {
+		return c.missingValue
+	}
+	return math.Float64frombits(uint64(v))
+}
+
+func (c *doubleComparator) Compare(slot1, slot2 int) int {
+	return compareFloat64(c.values[slot1], c.values[slot2])
+}
+
+func (c *doubleComparator) CompareBottom(doc int) (int, error) {
+	return compareFloat64(c.bottom, c.value(doc)), nil
+}
+
+func (c *doubleComparator) Copy(slot, doc int) error {
+	c.values[slot] = c.value(doc)
+	return nil
+}
+
+func (c *doubleComparator) SetBottom(slot int)         { c.bottom = c.values[slot] }
+func (c *doubleComparator) SetTopValue(v interface{})  { c.topValue = v.(float64) }
+func (c *doubleComparator) Value(slot int) interface{} { return c.values[slot] }
+
+func (c *doubleComparator) CompareTop(doc int) (int, error) {
+	return compareFloat64(c.topValue, c.value(doc)), nil
+}
+
+func (c *doubleComparator) CompareValues(first, second interface{}) int {
+	return compareFloat64(first.(float64), second.(float64))
+}
+
+/*
+Sorts by descending relevance. NOTE: if you are sorting only by
+descending relevance and then secondarily by ascending docID,
+performance is faster using TopScoreDocCollector directly (which
+IndexSearcher.Search() uses when no Sort is specified).
+*/
+type relevanceComparator struct {
+	scores   []float32
+	bottom   float32
+	topValue float32
+	scorer   Scorer
+}
+
+func newRelevanceComparator(numHits int) *relevanceComparator {
+	return &relevanceComparator{scores: make([]float32, numHits)}
+}
+
+func (c *relevanceComparator) Compare(slot1, slot2 int) int {
+	return compareFloat32(c.scores[slot2], c.scores[slot1])
+}
+
+func (c *relevanceComparator) CompareBottom(doc int) (int, error) {
+	score, err := c.scorer.Score()
+	if err != nil {
+		return 0, err
+	}
+	assert(!math.IsNaN(float64(score)))
+	return compareFloat32(score, c.bottom), nil
+}
+
+func (c *relevanceComparator) Copy(slot, doc int) (err error) {
+	c.scores[slot], err = c.scorer.Score()
+	assert(err != nil || !math.IsNaN(float64(c.scores[slot])))
+	return err
+}
+
+func (c *relevanceComparator) SetNextReader(ctx *index.AtomicReaderContext) error {
+	return nil
+}
+
+func (c *relevanceComparator) SetBottom(slot int) {
+	c.bottom = c.scores[slot]
+}
+
+func (c *relevanceComparator) SetTopValue(value interface{}) {
+	c.topValue = value.(float32)
+}
+
+func (c *relevanceComparator) CompareTop(doc int) (int, error) {
+	score, err := c.scorer.Score()
+	if err != nil {
+		return 0, err
+	}
+	assert(!math.IsNaN(float64(score)))
+	// Reversed intentionally because relevance by default sorts
+	// descending:
+	return compareFloat32(score, c.topValue), nil
+}
+
+func (c *relevanceComparator) SetScorer(scorer Scorer) {
+	// wrap with a ScoreCachingWrappingScorer so that successive calls
+	// to Score() will not incur score computation over and over again.
+	if _, ok := scorer.(*ScoreCachingWrappingScorer); !ok {
+		scorer = NewScoreCachingWrappingScorer(scorer)
+	}
+	c.scorer = scorer
+}
+
+func (c *relevanceComparator) Value(slot int) interface{} {
+	return c.scores[slot]
+}
+
+// Override because we sort reverse of natural float32 order:
+func (c *relevanceComparator) CompareValues(first, second interface{}) int {
+	// Reversed intentionally because relevance by default sorts
+	// descending:
+	return compareFloat32(second.(float32), first.(float32))
+}
+
+// Sorts by ascending docID
+type docComparator struct {
+	docIDs   []int
+	docBase  int
+	bottom   int
+	topValue int
+}
+
+func newDocComparator(numHits int) *docComparator {
+	return &docComparator{docIDs: make([]int, numHits)}
+}
+
+func (c *docComparator) Compare(slot1, slot2 int) int {
+	// No overflow risk because docIDs are non-negative
+	return c.docIDs[slot1] - c.docIDs[slot2]
+}
+
+func (c *docComparator) CompareBottom(doc int) (int, error) {
+	// No overflow risk because docIDs are non-negative
+	return c.bottom - (c.docBase + doc), nil
+}
+
+func (c *docComparator) Copy(slot, doc int) error {
+	c.docIDs[slot] = c.docBase + doc
+	return nil
+}
+
+func (c *docComparator) SetNextReader(ctx *index.AtomicReaderContext) error {
+	// TODO: can we "map" our docIDs to the current reader? saves
+	// having to then subtract on every compare call
+	c.docBase = ctx.DocBase
+	return nil
+}
+
+func (c *docComparator) SetBottom(slot int)         { c.bottom = c.docIDs[slot] }
+func (c *docComparator) SetTopValue(v interface{})  { c.topValue = v.(int) }
+func (c *docComparator) SetScorer(scorer Scorer)    {}
+func (c *docComparator) Value(slot int) interface{} { return c.docIDs[slot] }
+
+func (c *docComparator) CompareTop(doc int) (int, error) {
+	return compareInt(c.topValue, c.docBase+doc), nil
+}
+
+func (c *docComparator) CompareValues(first, second interface{}) int {
+	return compareInt(first.(int), second.(int))
+}
+
+// Compares two []byte sort values, nil standing for a missing value.
+func compareMissingBytes(val1, val2 []byte, missingSortCmp int) int {
+	if val1 == nil {
+		if val2 == nil {
+			return 0
+		}
+		return missingSortCmp
+	} else if val2 == nil {
+		return -missingSortCmp
+	}
+	return bytes.Compare(val1, val2)
+}
+
+// Copies term into buf, keeping an empty term distinct from nil.
+func copyTerm(buf, term []byte) []byte {
+	if buf == nil {
+		buf = make([]byte, 0, len(term))
+	}
+	return append(buf[:0], term...)
+}
+
+/*
+Returns the ord of key in values; if key is not present, returns
+-insertionPoint-1.
+*/
+func lookupTerm(values SortedDocValues, key []byte) int {
+	low, high := 0, values.ValueCount()-1
+	for low <= high {
+		mid := int(uint(low+high) >> 1)
+		if cmp := bytes.Compare(values.LookupOrd(mid), key); cmp < 0 {
+			low = mid + 1
+		} else if cmp > 0 {
+			high = mid - 1
+		} else {
+			return mid // key found
+		}
+	}
+	return -(low + 1) // key not found
+}
+
+/*
+Sorts by field's natural term sort order, using ordinals. This is
+functionally equivalent to termValComparator, but it first resolves
+the string to their relative ordinal positions (using the index
+returned by SortedDocValues), and does most comparisons using the
+ordinals. For medium to large results, this comparator will be much
+faster than termValComparator. For very small result sets it may be
+slower.
+*/
+type termOrdValComparator struct {
+	// Ords for each slot.
+	ords []int
+	// Values for each slot.
+	values [][]byte
+	// Which reader last copied a value into the slot. When we compare
+	// two slots, we just compare-by-ord if the readerGen is the same;
+	// else we must compare the values (slower).
+	readerGen []int
+
+	// Gen of current reader we are on.
+	currentReaderGen int
+
+	// Current reader's doc ord/values.
+	termsIndex SortedDocValues
+
+	field string
+
+	// Bottom slot, or -1 if queue isn't full yet
+	bottomSlot int
+
+	// Bottom ord (same as ords[bottomSlot] once bottomSlot is set).
+	// Cached for faster compares.
+	bottomOrd int
+
+	// True if current bottom slot matches the current reader.
+	bottomSameReader bool
+
+	// Bottom value (same as values[bottomSlot] once bottomSlot is set).
+	// Cached for faster compares.
+	bottomValue []byte
+
+	// Top value, set by SetTopValue(); nil if missing.
+	topValue []byte
+
+	// -1 if missing values are sorted first, 1 if they are sorted last
+	missingSortCmp int
+
+	// Which ordinal to use for a missing value.
+	missingOrd int
+}
+
+/*
+Creates this, with control over how missing values are sorted. Pass
+sortMissingLast=true to put missing values at the end.
+*/
+func newTermOrdValComparator(numHits int, field string, sortMissingLast bool) *termOrdValComparator {
+	ans := &termOrdValComparator{
+		ords:             make([]int, numHits),
+		values:           make([][]byte, numHits),
+		readerGen:        make([]int, numHits),
+		field:            field,
+		bottomSlot:       -1,
+		currentReaderGen: -1,
+	}
+	if sortMissingLast {
+		ans.missingSortCmp = 1
+		ans.missingOrd = math.MaxInt32
+	} else {
+		ans.missingSortCmp = -1
+		ans.missingOrd = -1
+	}
+	return ans
+}
+
+func (c *termOrdValComparator) Compare(slot1, slot2 int) int {
+	if c.readerGen[slot1] == c.readerGen[slot2] {
+		return c.ords[slot1] - c.ords[slot2]
+	}
+	return compareMissingBytes(c.values[slot1], c.values[slot2], c.missingSortCmp)
+}
+
+func (c *termOrdValComparator) CompareBottom(doc int) (int, error) {
+	assert(c.bottomSlot != -1)
+	docOrd := c.termsIndex.Ord(doc)
+	if docOrd == -1 {
+		docOrd = c.missingOrd
+	}
+	if c.bottomSameReader {
+		// ord is precisely comparable, even in the equal case
+		return c.bottomOrd - docOrd, nil
+	} else if c.bottomOrd >= docOrd {
+		// the equals case always means bottom is > doc (because we set
+		// bottomOrd to the lower bound in SetBottom()):
+		return 1, nil
+	}
+	return -1, nil
+}
+
+func (c *termOrdValComparator) Copy(slot, doc int) error {
+	ord := c.termsIndex.Ord(doc)
+	if ord == -1 {
+		ord = c.missingOrd
+		c.values[slot] = nil
+	} else {
+		assert(ord >= 0)
+		c.values[slot] = copyTerm(c.values[slot], c.termsIndex.LookupOrd(ord))
+	}
+	c.ords[slot] = ord
+	c.readerGen[slot] = c.currentReaderGen
+	return nil
+}
+
+func (c *termOrdValComparator) SetNextReader(ctx *index.AtomicReaderContext) (err error) {
+	reader := ctx.Reader().(index.AtomicReader)
+	if c.termsIndex, err = index.GetSortedDocValues(reader, c.field); err != nil {
+		return err
+	}
+	c.currentReaderGen++
+	if c.bottomSlot != -1 {
+		// Recompute bottomOrd/SameReader
+		c.SetBottom(c.bottomSlot)
+	}
+	return nil
+}
+
+func (c *termOrdValComparator) SetBottom(bottom int) {
+	c.bottomSlot = bottom
+
+	c.bottomValue = c.values[c.bottomSlot]
+	if c.currentReaderGen == c.readerGen[c.bottomSlot] {
+		c.bottomOrd = c.ords[c.bottomSlot]
+		c.bottomSameReader = true
+	} else if c.bottomValue == nil {
+		// missingOrd is nil for all segments
+		assert(c.ords[c.bottomSlot] == c.missingOrd)
+		c.bottomOrd = c.missingOrd
+		c.bottomSameReader = true
+		c.readerGen[c.bottomSlot] = c.currentReaderGen
+	} else if ord := lookupTerm(c.termsIndex, c.bottomValue); ord < 0 {
+		c.bottomOrd = -ord - 2
+		c.bottomSameReader = false
+	} else {
+		c.bottomOrd = ord
+		// exact value match
+		c.bottomSameReader = true
+		c.readerGen[c.bottomSlot] = c.currentReaderGen
+		c.ords[c.bottomSlot] = c.bottomOrd
+	}
+}
+
+func (c *termOrdValComparator) SetTopValue(value interface{}) {
+	// nil means the top value is missing
+	c.topValue, _ = value.([]byte)
+}
+
+func (c *termOrdValComparator) CompareTop(doc int) (int, error) {
+	// TODO: resolve topValue to an ord in SetNextReader(), like the
+	// bottom, to compare by ord here
+	var docValue []byte
+	if ord := c.termsIndex.Ord(doc); ord != -1 {
+		docValue = c.termsIndex.LookupOrd(ord)
+	}
+	return compareMissingBytes(c.topValue, docValue, c.missingSortCmp), nil
+}
+
+func (c *termOrdValComparator) SetScorer(scorer Scorer) {}
+
+func (c *termOrdValComparator) Value(slot int) interface{} {
+	return c.values[slot]
+}
+
+func (c *termOrdValComparator) CompareValues(first, second interface{}) int {
+	return compareMissingBytes(first.([]byte), second.([]byte), c.missingSortCmp)
+}
+
+/*
+Sorts by field's natural term sort order. All comparisons are done
+using bytes.Compare(), which is slow for medium to large result sets
+but possibly very fast for very small results sets.
+*/
+type termValComparator struct {
+	values         [][]byte
+	docTerms       BinaryDocValues
+	docsWithField  util.Bits
+	field          string
+	bottom         []byte
+	topValue       []byte
+	missingSortCmp int
+}
+
+func newTermValComparator(numHits int, field string, sortMissingLast bool) *termValComparator {
+	ans := &termValComparator{
+		values:         make([][]byte, numHits),
+		field:          field,
+		missingSortCmp: -1,
+	}
+	if sortMissingLast {
+		ans.missingSortCmp = 1
+	}
+	return ans
+}
+
+// Returns the value of doc, or nil if it has none.
+func (c *termValComparator) comparableBytes(doc int) []byte {
+	term := c.docTerms.Get(doc)
+	if len(term) == 0 && c.docsWithField != nil && !c.docsWithField.At(doc) {
+		return nil
+	}
+	if term == nil {
+		return []byte{}
+	}
+	return term
+}
+
+func (c *termValComparator) Compare(slot1, slot2 int) int {
+	return compareMissingBytes(c.values[slot1], c.values[slot2], c.missingSortCmp)
+}
+
+func (c *termValComparator) CompareBottom(doc int) (int, error) {
+	return compareMissingBytes(c.bottom, c.comparableBytes(doc), c.missingSortCmp), nil
+}
+
+func (c *termValComparator) Copy(slot, doc int) error {
+	if term := c.comparableBytes(doc); term == nil {
+		c.values[slot] = nil
+	} else {
+		c.values[slot] = copyTerm(c.values[slot], term)
+	}
+	return nil
+}
+
+func (c *termValComparator) SetNextReader(ctx *index.AtomicReaderContext) (err error) {
+	reader := ctx.Reader().(index.AtomicReader)
+	if c.docTerms, err = index.GetBinaryDocValues(reader, c.field); err != nil {
+		return err
+	}
+	if c.docsWithField, err = index.GetDocsWithField(reader, c.field); err != nil {
+		return err
+	}
+	if _, ok := c.docsWithField.(util.MatchAllBits); ok {
+		c.docsWithField = nil
+	}
+	return nil
+}
+
+func (c *termValComparator) SetBottom(slot int)         { c.bottom = c.values[slot] }
+func (c *termValComparator) SetScorer(scorer Scorer)    {}
+func (c *termValComparator) Value(slot int) interface{} { return c.values[slot] }
+
+func (c *termValComparator) SetTopValue(value interface{}) {
+	// nil means the top value is missing
+	c.topValue, _ = value.([]byte)
+}
+
+func (c *termValComparator) CompareTop(doc int) (int, error) {
+	return compareMissingBytes(c.topValue, c.comparableBytes(doc), c.missingSortCmp), nil
+}
+
+func (c *termValComparator) CompareValues(first, second interface{}) int {
+	return compareMissingBytes(first.([]byte), second.([]byte), c.missingSortCmp)
+}
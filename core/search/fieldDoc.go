@@ -0,0 +1,49 @@
+package search
+
+import (
+	"fmt"
+)
+
+// search/FieldDoc.java
+
+/*
+Expert: A ScoreDoc which also contains information about how to sort
+the referenced document. In addition to the document number and
+score, this object contains a slice of values for the document from
+the field(s) used to sort. For example, if the sort criteria was to
+sort by fields "a", "b" then "c", the Fields slice would have three
+elements, corresponding respectively to the term values for the
+document in fields "a", "b" and "c". The type of each value depends
+on the SortFieldType of its field.
+*/
+type FieldDoc struct {
+	*ScoreDoc
+	// Expert: The values which are used to sort the referenced
+	// document. The order of these will match the original sort
+	// criteria given by a Sort object. Each value will have been
+	// returned from the Value() method of the FieldComparator used to
+	// sort this field.
+	Fields []interface{}
+}
+
+// Expert: Creates one of these objects with the given sort
+// information.
+func NewFieldDoc(doc int, score float32, fields []interface{}) *FieldDoc {
+	return &FieldDoc{newScoreDoc(doc, score), fields}
+}
+
+func (d *FieldDoc) String() string {
+	return fmt.Sprintf("%v fields=%v", d.ScoreDoc, d.Fields)
+}
+
+// search/TopFieldDocs.java
+
+// Represents hits returned by IndexSearcher.SearchSorted().
+type TopFieldDocs struct {
+	TopDocs
+	// The fields which were used to sort results by.
+	Fields []*SortField
+	// The top hits with their sort values, in the same order as
+	// ScoreDocs.
+	FieldDocs []*FieldDoc
+}
@@ -0,0 +1,90 @@
+package search
+
+import (
+	"fmt"
+)
+
+// search/FieldValueHitQueue.java
+
+// An entry of the hit queue: a hit and the comparator slot holding its
+// sort values.
+type fieldValueHitQueueEntry struct {
+	*ScoreDoc
+	slot int
+}
+
+func newFieldValueHitQueueEntry(slot, doc int, score float32) *fieldValueHitQueueEntry {
+	return &fieldValueHitQueueEntry{newScoreDoc(doc, score), slot}
+}
+
+func (e *fieldValueHitQueueEntry) String() string {
+	return fmt.Sprintf("slot:%v %v", e.slot, e.ScoreDoc)
+}
+
+/*
+Expert: A hit queue for sorting by hits by terms in more than one
+field. The top of the queue is the least competitive hit, which is
+replaced first when a more competitive one is collected.
+*/
+type fieldValueHitQueue struct {
+	*PriorityQueue
+	// Stores the sort criteria being used.
+	fields      []*SortField
+	comparators []FieldComparator
+	reverseMul  []int
+}
+
+func newFieldValueHitQueue(fields []*SortField, size int) *fieldValueHitQueue {
+	assert2(len(fields) > 0, "Sort must contain at least one field")
+	q := &fieldValueHitQueue{
+		fields:      fields,
+		comparators: make([]FieldComparator, len(fields)),
+		reverseMul:  make([]int, len(fields)),
+	}
+	for i, field := range fields {
+		q.comparators[i] = field.Comparator(size, i)
+		q.reverseMul[i] = 1
+		if field.reverse {
+			q.reverseMul[i] = -1
+		}
+	}
+	q.PriorityQueue = &PriorityQueue{items: make([]interface{}, 0, size)}
+	q.PriorityQueue.less = q.lessThan
+	return q
+}
+
+/*
+Returns whether hit i should be sorted after hit j, i.e. is less
+competitive.
+*/
+func (q *fieldValueHitQueue) lessThan(i, j int) bool {
+	hitA := q.items[i].(*fieldValueHitQueueEntry)
+	hitB := q.items[j].(*fieldValueHitQueueEntry)
+	assert(hitA != hitB)
+	assert(hitA.slot != hitB.slot)
+
+	for k, comparator := range q.comparators {
+		if c := q.reverseMul[k] * comparator.Compare(hitA.slot, hitB.slot); c != 0 {
+			// Short circuit
+			return c > 0
+		}
+	}
+
+	// avoid random sort order that could lead to duplicates
+	return hitA.Doc > hitB.Doc
+}
+
+/*
+Given a queue entry, creates a corresponding FieldDoc that contains
+the values used to sort the given document. These values are not the
+raw values out of the index, but the internal representation of them.
+This is so the given search hit can be collated by a MultiSearcher
+with other search hits.
+*/
+func (q *fieldValueHitQueue) fillFields(entry *fieldValueHitQueueEntry) *FieldDoc {
+	fields := make([]interface{}, len(q.comparators))
+	for i, comparator := range q.comparators {
+		fields[i] = comparator.Value(entry.slot)
+	}
+	return NewFieldDoc(entry.Doc, entry.Score, fields)
+}
@@ -0,0 +1,638 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/search/model"
+	"github.com/gzg1984/golucene/core/util"
+)
+
+// search/FilteredQuery.java
+
+/*
+A query that applies a filter to the results of another query.
+
+Note: the bits are retrieved from the filter each time this query is
+used in a search - use a CachingWrapperFilter to avoid regenerating
+the bits every time.
+*/
+type FilteredQuery struct {
+	*AbstractQuery
+	query    Query
+	filter   Filter
+	strategy FilterStrategy
+}
+
+/*
+Constructs a new query which applies a filter to the results of the
+original query. Filter.DocIdSet() will be called every time this
+query is used in a search.
+*/
+func NewFilteredQuery(query Query, filter Filter) *FilteredQuery {
+	return NewFilteredQueryWithStrategy(query, filter, RANDOM_ACCESS_FILTER_STRATEGY)
+}
+
+/*
+Expert: Constructs a new query which applies a filter to the results
+of the original query. Filter.DocIdSet() will be called every time
+this query is used in a search.
+*/
+func NewFilteredQueryWithStrategy(query Query, filter Filter,
+	strategy FilterStrategy) *FilteredQuery {
+
+	assert2(query != nil && filter != nil, "Query and filter cannot be null.")
+	assert2(strategy != nil, "FilterStrategy can not be null")
+	ans := &FilteredQuery{
+		query:    query,
+		filter:   filter,
+		strategy: strategy,
+	}
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+// Returns this FilteredQuery's (unfiltered) Query
+func (q *FilteredQuery) Query() Query {
+	return q.query
+}
+
+// Returns this FilteredQuery's filter
+func (q *FilteredQuery) Filter() Filter {
+	return q.filter
+}
+
+// Returns this FilteredQuery's FilterStrategy
+func (q *FilteredQuery) FilterStrategy() FilterStrategy {
+	return q.strategy
+}
+
+/*
+Returns a Weight that applies the filter to the enclosed query's
+Weight. This is accomplished by overriding the Scorer returned by the
+Weight.
+*/
+func (q *FilteredQuery) CreateWeight(searcher *IndexSearcher) (Weight, error) {
+	weight, err := q.query.CreateWeight(searcher)
+	if err != nil {
+		return nil, err
+	}
+	return newFilteredWeight(q, weight), nil
+}
+
+// Rewrites the query. Returns a new FilteredQuery wrapping the
+// rewritten query, or this query if nothing was rewritten.
+func (q *FilteredQuery) Rewrite(reader index.IndexReader) (Query, error) {
+	queryRewritten, err := q.query.Rewrite(reader)
+	if err != nil {
+		return nil, err
+	}
+	if queryRewritten != q.query {
+		// rewrite to a new FilteredQuery wrapping the rewritten query
+		rewritten := NewFilteredQueryWithStrategy(queryRewritten, q.filter, q.strategy)
+		rewritten.SetBoost(q.boost)
+		return rewritten, nil
+	}
+	// nothing to rewrite, we are done!
+	return q, nil
+}
+
+// Prints a user-readable version of this query.
+func (q *FilteredQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	buf.WriteString("filtered(")
+	buf.WriteString(q.query.ToString(field))
+	buf.WriteString(")->")
+	buf.WriteString(fmt.Sprintf("%v", q.filter))
+	if q.boost != 1 {
+		buf.WriteString(fmt.Sprintf("^%v", q.boost))
+	}
+	return buf.String()
+}
+
+type FilteredWeight struct {
+	*WeightImpl
+	owner  *FilteredQuery
+	weight Weight
+}
+
+func newFilteredWeight(owner *FilteredQuery, weight Weight) *FilteredWeight {
+	ans := &FilteredWeight{owner: owner, weight: weight}
+	ans.WeightImpl = newWeightImpl(ans)
+	return ans
+}
+
+func (w *FilteredWeight) IsScoresDocsOutOfOrder() bool {
+	return true
+}
+
+func (w *FilteredWeight) ValueForNormalization() float32 {
+	// boost sub-weight
+	return w.weight.ValueForNormalization() * w.owner.boost * w.owner.boost
+}
+
+func (w *FilteredWeight) Normalize(norm float32, topLevelBoost float32) {
+	// incorporate boost
+	w.weight.Normalize(norm, topLevelBoost*w.owner.boost)
+}
+
+func (w *FilteredWeight) Explain(ctx *index.AtomicReaderContext, doc int) (Explanation, error) {
+	inner, err := w.weight.Explain(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	f := w.owner.filter
+	docIdSet, err := f.DocIdSet(ctx, ctx.Reader().(index.AtomicReader).LiveDocs())
+	if err != nil {
+		return nil, err
+	}
+	var docIdSetIterator DocIdSetIterator
+	if docIdSet != nil {
+		if docIdSetIterator, err = docIdSet.Iterator(); err != nil {
+			return nil, err
+		}
+	}
+	if docIdSetIterator != nil {
+		target, err := docIdSetIterator.Advance(doc)
+		if err != nil {
+			return nil, err
+		}
+		if target == doc {
+			return inner, nil
+		}
+	}
+	result := newExplanation(0, fmt.Sprintf("failure to match filter: %v", f))
+	result.addDetail(inner)
+	return result, nil
+}
+
+// return a filtering scorer
+func (w *FilteredWeight) Scorer(ctx *index.AtomicReaderContext,
+	acceptDocs util.Bits) (Scorer, error) {
+
+	filterDocIdSet, err := w.owner.filter.DocIdSet(ctx, acceptDocs)
+	if filterDocIdSet == nil || err != nil {
+		// this means the filter does not accept any documents.
+		return nil, err
+	}
+	return w.owner.strategy.FilteredScorer(ctx, w.weight, filterDocIdSet)
+}
+
+// return a filtering top scorer
+func (w *FilteredWeight) BulkScorer(ctx *index.AtomicReaderContext,
+	scoreDocsInOrder bool, acceptDocs util.Bits) (BulkScorer, error) {
+
+	filterDocIdSet, err := w.owner.filter.DocIdSet(ctx, acceptDocs)
+	if filterDocIdSet == nil || err != nil {
+		// this means the filter does not accept any documents.
+		return nil, err
+	}
+	return w.owner.strategy.FilteredBulkScorer(ctx, w.weight, scoreDocsInOrder, filterDocIdSet)
+}
+
+/*
+A scorer that consults the filter iff a document was matched by the
+delegate scorer. This is useful if the filter computation is more
+expensive than document scoring or if the filter has a linear
+running time to compute the next matching doc like exact geo
+distances.
+*/
+type queryFirstScorer struct {
+	*abstractScorer
+	scorer     Scorer
+	scorerDoc  int
+	filterBits util.Bits
+}
+
+func newQueryFirstScorer(w Weight, filterBits util.Bits, other Scorer) *queryFirstScorer {
+	ans := &queryFirstScorer{
+		scorer:     other,
+		scorerDoc:  -1,
+		filterBits: filterBits,
+	}
+	ans.abstractScorer = newScorer(ans, w)
+	return ans
+}
+
+func (s *queryFirstScorer) NextDoc() (doc int, err error) {
+	for {
+		if doc, err = s.scorer.NextDoc(); err != nil {
+			return 0, err
+		}
+		if doc == NO_MORE_DOCS || s.filterBits.At(doc) {
+			s.scorerDoc = doc
+			return doc, nil
+		}
+	}
+}
+
+func (s *queryFirstScorer) Advance(target int) (doc int, err error) {
+	if doc, err = s.scorer.Advance(target); err != nil {
+		return 0, err
+	}
+	if doc != NO_MORE_DOCS && !s.filterBits.At(doc) {
+		return s.NextDoc()
+	}
+	s.scorerDoc = doc
+	return doc, nil
+}
+
+func (s *queryFirstScorer) DocId() int              { return s.scorerDoc }
+func (s *queryFirstScorer) Score() (float32, error) { return s.scorer.Score() }
+func (s *queryFirstScorer) Freq() (int, error)      { return s.scorer.Freq() }
+
+type queryFirstBulkScorer struct {
+	*BulkScorerImpl
+	scorer     Scorer
+	filterBits util.Bits
+}
+
+func newQueryFirstBulkScorer(scorer Scorer, filterBits util.Bits) *queryFirstBulkScorer {
+	ans := &queryFirstBulkScorer{scorer: scorer, filterBits: filterBits}
+	ans.BulkScorerImpl = newBulkScorer(ans)
+	return ans
+}
+
+func (s *queryFirstBulkScorer) ScoreAndCollectUpto(collector Collector, maxDoc int) (bool, error) {
+	// the normalization trick already applies the boost of this query,
+	// so we can use the wrapped scorer directly:
+	collector.SetScorer(s.scorer)
+	if s.scorer.DocId() == -1 {
+		if _, err := s.scorer.NextDoc(); err != nil {
+			return false, err
+		}
+	}
+	for scorerDoc := s.scorer.DocId(); scorerDoc < maxDoc; scorerDoc = s.scorer.DocId() {
+		if s.filterBits.At(scorerDoc) {
+			if err := collector.Collect(scorerDoc); err != nil {
+				return false, err
+			}
+		}
+		if _, err := s.scorer.NextDoc(); err != nil {
+			return false, err
+		}
+	}
+	return s.scorer.DocId() != NO_MORE_DOCS, nil
+}
+
+/*
+A Scorer that uses a "leap-frog" approach (also called "zig-zag
+join"). The scorer and the filter take turns trying to advance to
+each other's next matching document, often jumping past the target
+document. When both land on the same document, it's collected.
+*/
+type leapFrogScorer struct {
+	*abstractScorer
+	secondary    DocIdSetIterator
+	primary      DocIdSetIterator
+	scorer       Scorer
+	primaryDoc   int
+	secondaryDoc int
+	// returns the next doc of the primary iterator; overridden when the
+	// filter was already advanced to its first doc.
+	primaryNext func() (int, error)
+}
+
+func newLeapFrogScorer(w Weight, primary, secondary DocIdSetIterator,
+	scorer Scorer) *leapFrogScorer {
+
+	ans := &leapFrogScorer{
+		primary:      primary,
+		secondary:    secondary,
+		scorer:       scorer,
+		primaryDoc:   -1,
+		secondaryDoc: -1,
+	}
+	ans.primaryNext = primary.NextDoc
+	ans.abstractScorer = newScorer(ans, w)
+	return ans
+}
+
+/*
+The primary iterator is the filter, which was already advanced to
+firstFilteredDoc to decide between random access and leap-frog.
+*/
+func newPrimaryAdvancedLeapFrogScorer(w Weight, firstFilteredDoc int,
+	filterIter DocIdSetIterator, other Scorer) *leapFrogScorer {
+
+	ans := newLeapFrogScorer(w, filterIter, other, other)
+	// initialize to prevent and advance call to move it further
+	ans.primaryDoc = firstFilteredDoc
+	ans.primaryNext = func() (int, error) {
+		if ans.secondaryDoc != -1 {
+			return ans.primary.NextDoc()
+		}
+		return firstFilteredDoc, nil
+	}
+	return ans
+}
+
+func (s *leapFrogScorer) advanceToNextCommonDoc() (err error) {
+	for {
+		if s.secondaryDoc < s.primaryDoc {
+			if s.secondaryDoc, err = s.secondary.Advance(s.primaryDoc); err != nil {
+				return err
+			}
+		} else if s.secondaryDoc == s.primaryDoc {
+			return nil
+		} else {
+			if s.primaryDoc, err = s.primary.Advance(s.secondaryDoc); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *leapFrogScorer) NextDoc() (doc int, err error) {
+	if s.primaryDoc, err = s.primaryNext(); err != nil {
+		return 0, err
+	}
+	if err = s.advanceToNextCommonDoc(); err != nil {
+		return 0, err
+	}
+	return s.primaryDoc, nil
+}
+
+func (s *leapFrogScorer) Advance(target int) (doc int, err error) {
+	if target > s.primaryDoc {
+		if s.primaryDoc, err = s.primary.Advance(target); err != nil {
+			return 0, err
+		}
+	}
+	if err = s.advanceToNextCommonDoc(); err != nil {
+		return 0, err
+	}
+	return s.primaryDoc, nil
+}
+
+func (s *leapFrogScorer) DocId() int              { return s.secondaryDoc }
+func (s *leapFrogScorer) Score() (float32, error) { return s.scorer.Score() }
+func (s *leapFrogScorer) Freq() (int, error)      { return s.scorer.Freq() }
+
+/*
+Abstract class that defines how the filter (DocIdSet) applied during
+document collection.
+*/
+type FilterStrategy interface {
+	/*
+		Returns a filtered Scorer based on this strategy.
+
+		context: the AtomicReaderContext for which to return the Scorer.
+		weight: the FilteredQuery Weight to create the filtered scorer.
+		docIdSet: the filter DocIdSet to apply
+	*/
+	FilteredScorer(context *index.AtomicReaderContext,
+		weight Weight, docIdSet DocIdSet) (Scorer, error)
+	/*
+		Returns a filtered BulkScorer based on this strategy. This is an
+		optional method: the default implementation just calls
+		FilteredScorer() and wraps that into a BulkScorer.
+	*/
+	FilteredBulkScorer(context *index.AtomicReaderContext,
+		weight Weight, scoreDocsInOrder bool, docIdSet DocIdSet) (BulkScorer, error)
+}
+
+type FilterStrategySPI interface {
+	FilteredScorer(*index.AtomicReaderContext, Weight, DocIdSet) (Scorer, error)
+}
+
+type FilterStrategyImpl struct {
+	spi FilterStrategySPI
+}
+
+func newFilterStrategy(spi FilterStrategySPI) *FilterStrategyImpl {
+	return &FilterStrategyImpl{spi}
+}
+
+func (fs *FilterStrategyImpl) FilteredBulkScorer(context *index.AtomicReaderContext,
+	weight Weight, scoreDocsInOrder bool, docIdSet DocIdSet) (BulkScorer, error) {
+
+	scorer, err := fs.spi.FilteredScorer(context, weight, docIdSet)
+	if scorer == nil || err != nil {
+		return nil, err
+	}
+	// This impl always scores docs in order, so we can ignore
+	// scoreDocsInOrder:
+	return newDefaultScorer(scorer), nil
+}
+
+/*
+A FilterStrategy that conditionally uses a random access filter if
+the given DocIdSet supports random access (returns a non-nil value
+from DocIdSet.Bits()) and UseRandomAccess() returns true. Otherwise
+this strategy falls back to a "zig-zag join" (LEAP_FROG_FILTER_FIRST_STRATEGY)
+strategy.
+
+Note: this strategy is the default strategy in FilteredQuery
+*/
+var RANDOM_ACCESS_FILTER_STRATEGY FilterStrategy = NewRandomAccessFilterStrategy()
+
+/*
+A filter strategy that uses a "leap-frog" approach (also called
+"zig-zag join"). The scorer and the filter take turns trying to
+advance to each other's next matching document, often jumping past
+the target document. When both land on the same document, it's
+collected.
+
+Note: This strategy uses the filter to lead the iteration.
+*/
+var LEAP_FROG_FILTER_FIRST_STRATEGY FilterStrategy = newLeapFrogFilterStrategy(false)
+
+/*
+A filter strategy that uses a "leap-frog" approach (also called
+"zig-zag join"). The scorer and the filter take turns trying to
+advance to each other's next matching document, often jumping past
+the target document. When both land on the same document, it's
+collected.
+
+Note: This strategy uses the query to lead the iteration.
+*/
+var LEAP_FROG_QUERY_FIRST_STRATEGY FilterStrategy = newLeapFrogFilterStrategy(true)
+
+/*
+A filter strategy that advances the Query or rather its Scorer first
+and consults the filter DocIdSet for each matched document.
+
+Note: this strategy requires a DocIdSet.Bits() to return a non-nil
+value. Otherwise this strategy falls back to
+LEAP_FROG_QUERY_FIRST_STRATEGY.
+
+Use this strategy if the filter computation is more expensive than
+document scoring or if the filter has a linear running time to
+compute the next matching doc like exact geo distances.
+*/
+var QUERY_FIRST_FILTER_STRATEGY FilterStrategy = newQueryFirstFilterStrategy()
+
+/*
+A FilterStrategy that conditionally uses a random access filter if
+the given DocIdSet supports random access (returns a non-nil value
+from DocIdSet.Bits()) and UseRandomAccess() returns true. Otherwise
+this strategy falls back to a "zig-zag join" (LEAP_FROG_FILTER_FIRST_STRATEGY)
+strategy.
+*/
+type RandomAccessFilterStrategy struct {
+	/*
+		Expert: decides if a filter should be executed as "random-access"
+		or not. Random-access means the filter "filters" in a similar way
+		as deleted docs are filtered in Lucene. This is faster when the
+		filter accepts many documents. However, when the filter is very
+		sparse, it can be faster to execute the query+filter as a
+		conjunction in some cases.
+
+		The default implementation returns true if the first document
+		accepted by the filter is < 100.
+	*/
+	UseRandomAccess func(bits util.Bits, firstFilterDoc int) bool
+}
+
+func NewRandomAccessFilterStrategy() *RandomAccessFilterStrategy {
+	return &RandomAccessFilterStrategy{
+		UseRandomAccess: func(bits util.Bits, firstFilterDoc int) bool {
+			// TODO once we have a cost API on filters and scorers we
+			// should rethink this heuristic
+			return firstFilterDoc < 100
+		},
+	}
+}
+
+func (fs *RandomAccessFilterStrategy) FilteredScorer(context *index.AtomicReaderContext,
+	weight Weight, docIdSet DocIdSet) (Scorer, error) {
+
+	filterIter, err := docIdSet.Iterator()
+	if filterIter == nil || err != nil {
+		// this means the filter does not accept any documents.
+		return nil, err
+	}
+
+	firstFilterDoc, err := filterIter.NextDoc()
+	if firstFilterDoc == NO_MORE_DOCS || err != nil {
+		return nil, err
+	}
+
+	filterAcceptDocs := docIdSet.Bits()
+	// force if RA is requested
+	if filterAcceptDocs != nil && fs.UseRandomAccess(filterAcceptDocs, firstFilterDoc) {
+		// if we are using random access, we return the inner scorer,
+		// just with other acceptDocs
+		return weight.Scorer(context, filterAcceptDocs)
+	}
+	assert(firstFilterDoc > -1)
+	// we are gonna advance() this scorer, so we set inorder=true/toplevel=false
+	// we pass nil as acceptDocs, as our filter has already respected
+	// acceptDocs, no need to do twice
+	scorer, err := weight.Scorer(context, nil)
+	if scorer == nil || err != nil {
+		return nil, err
+	}
+	// TODO once we have way to figure out if we use RA or LeapFrog we
+	// can remove this scorer
+	return newPrimaryAdvancedLeapFrogScorer(weight, firstFilterDoc, filterIter, scorer), nil
+}
+
+func (fs *RandomAccessFilterStrategy) FilteredBulkScorer(context *index.AtomicReaderContext,
+	weight Weight, scoreDocsInOrder bool, docIdSet DocIdSet) (BulkScorer, error) {
+
+	filterIter, err := docIdSet.Iterator()
+	if filterIter == nil || err != nil {
+		// this means the filter does not accept any documents.
+		return nil, err
+	}
+
+	firstFilterDoc, err := filterIter.NextDoc()
+	if firstFilterDoc == NO_MORE_DOCS || err != nil {
+		return nil, err
+	}
+
+	filterAcceptDocs := docIdSet.Bits()
+	if filterAcceptDocs != nil && fs.UseRandomAccess(filterAcceptDocs, firstFilterDoc) {
+		// if we are using random access, we return the inner scorer,
+		// just with other acceptDocs
+		return weight.BulkScorer(context, scoreDocsInOrder, filterAcceptDocs)
+	}
+	// we pass nil as acceptDocs, as our filter has already respected
+	// acceptDocs, no need to do twice
+	scorer, err := weight.Scorer(context, nil)
+	if scorer == nil || err != nil {
+		return nil, err
+	}
+	return newDefaultScorer(newPrimaryAdvancedLeapFrogScorer(
+		weight, firstFilterDoc, filterIter, scorer)), nil
+}
+
+type leapFrogFilterStrategy struct {
+	*FilterStrategyImpl
+	scorerFirst bool
+}
+
+func newLeapFrogFilterStrategy(scorerFirst bool) *leapFrogFilterStrategy {
+	ans := &leapFrogFilterStrategy{scorerFirst: scorerFirst}
+	ans.FilterStrategyImpl = newFilterStrategy(ans)
+	return ans
+}
+
+func (fs *leapFrogFilterStrategy) FilteredScorer(context *index.AtomicReaderContext,
+	weight Weight, docIdSet DocIdSet) (Scorer, error) {
+
+	filterIter, err := docIdSet.Iterator()
+	if filterIter == nil || err != nil {
+		// this means the filter does not accept any documents.
+		return nil, err
+	}
+	// we pass nil as acceptDocs, as our filter has already respected
+	// acceptDocs, no need to do twice
+	scorer, err := weight.Scorer(context, nil)
+	if scorer == nil || err != nil {
+		return nil, err
+	}
+	if fs.scorerFirst {
+		return newLeapFrogScorer(weight, scorer, filterIter, scorer), nil
+	}
+	return newLeapFrogScorer(weight, filterIter, scorer, scorer), nil
+}
+
+/*
+A filter strategy that advances the scorer first and consults the
+filter's Bits for each document matched by the scorer.
+*/
+type queryFirstFilterStrategy struct {
+	*FilterStrategyImpl
+}
+
+func newQueryFirstFilterStrategy() *queryFirstFilterStrategy {
+	ans := &queryFirstFilterStrategy{}
+	ans.FilterStrategyImpl = newFilterStrategy(ans)
+	return ans
+}
+
+func (fs *queryFirstFilterStrategy) FilteredScorer(context *index.AtomicReaderContext,
+	weight Weight, docIdSet DocIdSet) (Scorer, error) {
+
+	filterAcceptDocs := docIdSet.Bits()
+	if filterAcceptDocs == nil {
+		// Filter does not provide random-access Bits; we must fallback
+		// to leapfrog:
+		return LEAP_FROG_QUERY_FIRST_STRATEGY.FilteredScorer(context, weight, docIdSet)
+	}
+	scorer, err := weight.Scorer(context, nil)
+	if scorer == nil || err != nil {
+		return nil, err
+	}
+	return newQueryFirstScorer(weight, filterAcceptDocs, scorer), nil
+}
+
+func (fs *queryFirstFilterStrategy) FilteredBulkScorer(context *index.AtomicReaderContext,
+	weight Weight, scoreDocsInOrder bool, docIdSet DocIdSet) (BulkScorer, error) {
+
+	filterAcceptDocs := docIdSet.Bits()
+	if filterAcceptDocs == nil {
+		// Filter does not provide random-access Bits; we must fallback
+		// to leapfrog:
+		return LEAP_FROG_QUERY_FIRST_STRATEGY.FilteredBulkScorer(
+			context, weight, scoreDocsInOrder, docIdSet)
+	}
+	scorer, err := weight.Scorer(context, nil)
+	if scorer == nil || err != nil {
+		return nil, err
+	}
+	return newQueryFirstBulkScorer(scorer, filterAcceptDocs), nil
+}
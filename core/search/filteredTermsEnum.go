@@ -0,0 +1,152 @@
+package search
+
+import (
+	. "github.com/gzg1984/golucene/core/index/model"
+)
+
+// index/FilteredTermsEnum.java
+
+// Return value, if term should be accepted or the iteration should
+// END. The *_SEEK values denote, that after handling the current term
+// the enum should call nextSeekTerm() and step forward.
+type acceptStatus int
+
+const (
+	// Accept the term and position the enum at the next term.
+	ACCEPT_STATUS_YES = acceptStatus(iota)
+	// Accept the term and advance (nextSeekTerm()) to the next term.
+	ACCEPT_STATUS_YES_AND_SEEK
+	// Reject the term and position the enum at the next term.
+	ACCEPT_STATUS_NO
+	// Reject the term and advance (nextSeekTerm()) to the next term.
+	ACCEPT_STATUS_NO_AND_SEEK
+	// Reject the term and stop enumerating.
+	ACCEPT_STATUS_END
+)
+
+type filteredTermsEnumSPI interface {
+	// Return if term is accepted, not accepted or the iteration should
+	// end (and possibly seek).
+	accept(term []byte) acceptStatus
+	/*
+		On the first call to Next() or if accept() returns
+		ACCEPT_STATUS_YES_AND_SEEK or ACCEPT_STATUS_NO_AND_SEEK, this
+		method will be called to eventually seek the underlying TermsEnum
+		to a new position. On the first call, currentTerm will be nil,
+		later calls will provide the term the underlying enum is
+		positioned at. This method returns per default only one time the
+		initial seek term and then nil, so no repositioning is ever done.
+
+		Override this method, if you want a more sophisticated TermsEnum,
+		that repositions the iterator during enumeration. If this method
+		always returns nil the enum is empty.
+
+		Please note: This method should always provide a greater term
+		than the last enumerated term, else the behaviour of this enum
+		violates the contract for TermsEnums.
+	*/
+	nextSeekTerm(currentTerm []byte) []byte
+}
+
+/*
+Abstract type for enumerating a subset of all terms.
+
+Term enumerations are always ordered by Comparator(). Each term in
+the enumeration is greater than all that precede it.
+
+Please note: Consumers of this enum cannot call Seek(), it is forward
+only; it panics when a seeking method is called.
+*/
+type filteredTermsEnum struct {
+	TermsEnum // the delegate enum
+	spi       filteredTermsEnumSPI
+
+	initialSeekTerm []byte
+	doSeek          bool
+	actualTerm      []byte
+}
+
+/*
+Creates a filtered TermsEnum on a terms enum. If startWithSeek is
+true, the enum seeks to the term returned by nextSeekTerm() before
+the first term is enumerated.
+*/
+func newFilteredTermsEnum(spi filteredTermsEnumSPI, tenum TermsEnum, startWithSeek bool) *filteredTermsEnum {
+	assert(tenum != nil)
+	return &filteredTermsEnum{
+		TermsEnum: tenum,
+		spi:       spi,
+		doSeek:    startWithSeek,
+	}
+}
+
+/*
+Use this method to set the initial []byte to seek before iterating.
+This is a convenience method for subclasses that do not override
+nextSeekTerm(). If the initial seek term is nil (default), the enum is
+empty.
+
+You can only use this method, if you keep the default implementation
+of nextSeekTerm().
+*/
+func (e *filteredTermsEnum) setInitialSeekTerm(term []byte) {
+	e.initialSeekTerm = term
+}
+
+func (e *filteredTermsEnum) nextSeekTerm(currentTerm []byte) []byte {
+	t := e.initialSeekTerm
+	e.initialSeekTerm = nil
+	return t
+}
+
+func (e *filteredTermsEnum) SeekExact(term []byte) (bool, error) {
+	panic("filteredTermsEnum does not support seeking")
+}
+
+func (e *filteredTermsEnum) SeekCeil(term []byte) (SeekStatus, error) {
+	panic("filteredTermsEnum does not support seeking")
+}
+
+func (e *filteredTermsEnum) SeekExactByPosition(ord int64) error {
+	panic("filteredTermsEnum does not support seeking")
+}
+
+func (e *filteredTermsEnum) Next() (term []byte, err error) {
+	for {
+		// Seek or forward the iterator
+		if e.doSeek {
+			e.doSeek = false
+			t := e.spi.nextSeekTerm(e.actualTerm)
+			if t == nil {
+				// no more terms to seek to
+				return nil, nil
+			}
+			status, err := e.TermsEnum.SeekCeil(t)
+			if err != nil || status == SEEK_STATUS_END {
+				// enum exhausted
+				return nil, err
+			}
+			e.actualTerm = e.TermsEnum.Term()
+		} else {
+			if e.actualTerm, err = e.TermsEnum.Next(); err != nil || e.actualTerm == nil {
+				// enum exhausted
+				return nil, err
+			}
+		}
+
+		// check if term is accepted
+		switch e.spi.accept(e.actualTerm) {
+		case ACCEPT_STATUS_YES_AND_SEEK:
+			e.doSeek = true
+			fallthrough
+		case ACCEPT_STATUS_YES:
+			return e.actualTerm, nil
+		case ACCEPT_STATUS_NO_AND_SEEK:
+			// invalid term, seek next time
+			e.doSeek = true
+		case ACCEPT_STATUS_END:
+			// we are supposed to end the enum
+			return nil, nil
+		}
+	}
+}
@@ -1,4 +1,394 @@
 package search
 
+import (
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/search/model"
+	"github.com/gzg1984/golucene/core/util"
+	"reflect"
+	"sync"
+)
+
+// search/Filter.java
+
+/*
+Abstract base class for restricting which documents may be returned
+during searching.
+*/
 type Filter interface {
+	/*
+		Creates a DocIdSet enumerating the documents that should be
+		permitted in search results.
+
+		NOTE: nil can be returned if no documents are accepted by this
+		Filter.
+
+		Note: this method will be called once per segment in the index
+		during searching. The returned DocIdSet must refer to document
+		IDs for that segment, not for the top-level reader.
+
+		acceptDocs: Bits that represent the allowable docs to match
+		(typically deleted docs but possibly filtering other documents).
+	*/
+	DocIdSet(ctx *index.AtomicReaderContext, acceptDocs util.Bits) (DocIdSet, error)
+}
+
+// search/DocIdSet.java
+
+/*
+A DocIdSet contains a set of doc ids. Implementing classes must only
+implement Iterator() to provide access to the set.
+*/
+type DocIdSet interface {
+	/*
+		Provides a DocIdSetIterator to access the set. This
+		implementation can return nil if there are no docs that match.
+	*/
+	Iterator() (DocIdSetIterator, error)
+	/*
+		Optionally provides a Bits interface for random access to
+		matching documents.
+
+		Returns nil, if this DocIdSet does not support random access.
+		In contrast to Iterator(), a return value of nil does not imply
+		that no documents match the filter! The default implementation
+		does not provide random access, so you only need to implement
+		this method if your DocIdSet can guarantee random access to
+		every docid in O(1) time without external disk access (as Bits
+		interface cannot return IOException).
+	*/
+	Bits() util.Bits
+	/*
+		This method is a hint for CachingWrapperFilter, if this DocIdSet
+		should be cached without copying it. The default is to return
+		false. If you have an own DocIdSet implementation that does its
+		iteration very effective and fast without doing disk I/O,
+		override this method and return true.
+	*/
+	IsCacheable() bool
+}
+
+/* An empty DocIdSet instance */
+var EMPTY_DOCIDSET DocIdSet = emptyDocIdSet{}
+
+type emptyDocIdSet struct{}
+
+func (s emptyDocIdSet) Iterator() (DocIdSetIterator, error) { return nil, nil }
+func (s emptyDocIdSet) Bits() util.Bits                     { return nil }
+func (s emptyDocIdSet) IsCacheable() bool                   { return true }
+
+// search/FilteredDocIdSet.java
+
+/*
+Abstract decorator class for a DocIdSet implementation that provides
+on-demand filtering/validation mechanism on a given DocIdSet.
+
+Technically, this same functionality could be achieved with
+ChainedFilter (under queries), however the benefit of this class is
+it never materializes the full bitset for the filter. Instead, the
+match() method is invoked on-demand, per docID visited during
+searching. If you know few docIDs will be visited, and the logic
+behind match() is relatively costly, this may be a better way to
+filter than ChainedFilter.
+*/
+type FilteredDocIdSet struct {
+	innerSet DocIdSet
+	// Validation method to determine whether a docid should be in the
+	// result set.
+	match func(docid int) bool
+}
+
+func NewFilteredDocIdSet(innerSet DocIdSet, match func(int) bool) *FilteredDocIdSet {
+	return &FilteredDocIdSet{innerSet, match}
+}
+
+// This DocIdSet implementation is cacheable if the inner set is cacheable.
+func (s *FilteredDocIdSet) IsCacheable() bool {
+	return s.innerSet.IsCacheable()
+}
+
+func (s *FilteredDocIdSet) Bits() util.Bits {
+	bits := s.innerSet.Bits()
+	if bits == nil {
+		return nil
+	}
+	return &filteredBits{bits, s.match}
+}
+
+type filteredBits struct {
+	util.Bits
+	match func(int) bool
+}
+
+func (b *filteredBits) At(docid int) bool {
+	return b.Bits.At(docid) && b.match(docid)
+}
+
+/* Implementation of the contract to build a DocIdSetIterator. */
+func (s *FilteredDocIdSet) Iterator() (DocIdSetIterator, error) {
+	iterator, err := s.innerSet.Iterator()
+	if iterator == nil || err != nil {
+		return nil, err
+	}
+	return newFilteredDocIdSetIterator(iterator, s.match), nil
+}
+
+// search/FilteredDocIdSetIterator.java
+
+/*
+Abstract decorator class of a DocIdSetIterator implementation that
+provides on-demand filter/validation mechanism on an underlying
+DocIdSetIterator.
+*/
+type FilteredDocIdSetIterator struct {
+	innerIter DocIdSetIterator
+	doc       int
+	match     func(int) bool
+}
+
+func newFilteredDocIdSetIterator(innerIter DocIdSetIterator,
+	match func(int) bool) *FilteredDocIdSetIterator {
+
+	assert2(innerIter != nil, "null iterator")
+	return &FilteredDocIdSetIterator{innerIter, -1, match}
+}
+
+func (it *FilteredDocIdSetIterator) DocId() int {
+	return it.doc
+}
+
+func (it *FilteredDocIdSetIterator) NextDoc() (doc int, err error) {
+	for {
+		if it.doc, err = it.innerIter.NextDoc(); err != nil {
+			return 0, err
+		}
+		if it.doc == NO_MORE_DOCS || it.match(it.doc) {
+			return it.doc, nil
+		}
+	}
+}
+
+func (it *FilteredDocIdSetIterator) Advance(target int) (doc int, err error) {
+	if it.doc, err = it.innerIter.Advance(target); err != nil {
+		return 0, err
+	}
+	if it.doc == NO_MORE_DOCS || it.match(it.doc) {
+		return it.doc, nil
+	}
+	return it.NextDoc()
+}
+
+// search/BitsFilteredDocIdSet.java
+
+/*
+Convenience wrapper which filters a DocIdSet by the acceptDocs, if
+any. Returns the set itself, if acceptDocs is nil.
+*/
+func BitsFilteredDocIdSet(set DocIdSet, acceptDocs util.Bits) DocIdSet {
+	if set == nil || acceptDocs == nil {
+		return set
+	}
+	return NewFilteredDocIdSet(set, acceptDocs.At)
+}
+
+// search/QueryWrapperFilter.java
+
+/*
+Constrains search results to only match those which also match a
+provided query.
+
+This could be used, for example, with a NumericRangeQuery on a
+suitably formatted date field to implement date filtering. One could
+re-use a single CachingWrapperFilter(QueryWrapperFilter) that matches,
+e.g., only documents modified within the last week. This would only
+need to be reconstructed once per day.
+*/
+type QueryWrapperFilter struct {
+	query Query
+}
+
+// Constructs a filter which only matches documents matching query.
+func NewQueryWrapperFilter(query Query) *QueryWrapperFilter {
+	assert2(query != nil, "Query may not be null")
+	return &QueryWrapperFilter{query}
+}
+
+// returns the inner Query
+func (f *QueryWrapperFilter) Query() Query {
+	return f.query
+}
+
+func (f *QueryWrapperFilter) DocIdSet(ctx *index.AtomicReaderContext,
+	acceptDocs util.Bits) (DocIdSet, error) {
+
+	// get a private context that is used to rewrite, createWeight and
+	// score eventually
+	privateContext := ctx.Reader().Context().(*index.AtomicReaderContext)
+	weight, err := NewIndexSearcherFromContext(privateContext).CreateNormalizedWeight(f.query)
+	if err != nil {
+		return nil, err
+	}
+	return &queryWrapperDocIdSet{weight, privateContext, acceptDocs}, nil
+}
+
+func init() {
+	// resolves IndexWriter's delete-by-query, per segment
+	index.QueryDocIdSetIterator = func(query index.Query, ctx *index.AtomicReaderContext,
+		acceptDocs util.Bits) (DocIdSetIterator, error) {
+
+		docs, err := NewQueryWrapperFilter(query.(Query)).DocIdSet(ctx, acceptDocs)
+		if docs == nil || err != nil {
+			return nil, err
+		}
+		return docs.Iterator()
+	}
+}
+
+func (f *QueryWrapperFilter) String() string {
+	return fmt.Sprintf("QueryWrapperFilter(%v)", f.query)
+}
+
+type queryWrapperDocIdSet struct {
+	weight     Weight
+	context    *index.AtomicReaderContext
+	acceptDocs util.Bits
+}
+
+func (s *queryWrapperDocIdSet) Iterator() (DocIdSetIterator, error) {
+	scorer, err := s.weight.Scorer(s.context, s.acceptDocs)
+	if scorer == nil || err != nil {
+		return nil, err
+	}
+	return scorer, nil
+}
+
+func (s *queryWrapperDocIdSet) Bits() util.Bits   { return nil }
+func (s *queryWrapperDocIdSet) IsCacheable() bool { return false }
+
+// search/CachingWrapperFilter.java
+
+/*
+Wraps another Filter's result and caches it. The purpose is to allow
+filters to simply filter, and then wrap with this class to add
+caching.
+*/
+type CachingWrapperFilter struct {
+	filter Filter
+
+	sync.Locker
+	// entries are dropped when the segment core is closed
+	cache map[interface{}]DocIdSet
+
+	// for testing
+	hitCount, missCount int
+}
+
+// Wraps another filter's result and caches it.
+func NewCachingWrapperFilter(filter Filter) *CachingWrapperFilter {
+	return &CachingWrapperFilter{
+		filter: filter,
+		Locker: &sync.Mutex{},
+		cache:  make(map[interface{}]DocIdSet),
+	}
+}
+
+// Returns the inner filter
+func (f *CachingWrapperFilter) Filter() Filter {
+	return f.filter
+}
+
+/*
+Provide the DocIdSet to be cached, using the DocIdSet provided by the
+wrapped Filter.
+
+This implementation returns the given DocIdSet, if
+DocIdSet.IsCacheable() returns true, else it copies the
+DocIdSetIterator into a cacheable FixedBitSet.
+*/
+func (f *CachingWrapperFilter) docIdSetToCache(docIdSet DocIdSet,
+	reader index.AtomicReader) (DocIdSet, error) {
+
+	if docIdSet == nil {
+		// this is better than returning nil, as the nonnull result can
+		// be cached
+		return EMPTY_DOCIDSET, nil
+	} else if docIdSet.IsCacheable() {
+		return docIdSet, nil
+	}
+	it, err := docIdSet.Iterator()
+	if err != nil {
+		return nil, err
+	}
+	// nil is allowed to be returned by Iterator(), in this case we wrap
+	// with the empty set, which is cacheable.
+	if it == nil {
+		return EMPTY_DOCIDSET, nil
+	}
+	return f.cacheImpl(it, reader)
+}
+
+// Default cache implementation: uses FixedBitSet.
+func (f *CachingWrapperFilter) cacheImpl(iterator DocIdSetIterator,
+	reader index.AtomicReader) (DocIdSet, error) {
+
+	bits := util.NewFixedBitSetOf(reader.MaxDoc())
+	if err := bits.Or(iterator); err != nil {
+		return nil, err
+	}
+	return bits, nil
+}
+
+func (f *CachingWrapperFilter) DocIdSet(ctx *index.AtomicReaderContext,
+	acceptDocs util.Bits) (DocIdSet, error) {
+
+	reader := ctx.Reader().(index.AtomicReader)
+	var key interface{} = reader
+	core, isCore := reader.(interface {
+		CoreCacheKey() interface{}
+		AddCoreClosedListener(index.CoreClosedListener)
+	})
+	if isCore {
+		key = core.CoreCacheKey()
+	}
+
+	f.Lock()
+	defer f.Unlock()
+	docIdSet, ok := f.cache[key]
+	if ok {
+		f.hitCount++
+	} else {
+		f.missCount++
+		set, err := f.filter.DocIdSet(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		if docIdSet, err = f.docIdSetToCache(set, reader); err != nil {
+			return nil, err
+		}
+		assert(docIdSet.IsCacheable())
+		f.cache[key] = docIdSet
+		if isCore {
+			core.AddCoreClosedListener(&cacheEvictor{f})
+		}
+	}
+
+	if docIdSet == EMPTY_DOCIDSET {
+		return nil, nil
+	}
+	return BitsFilteredDocIdSet(docIdSet, acceptDocs), nil
+}
+
+// Drops the cached DocIdSet of a segment core once the core is closed.
+type cacheEvictor struct {
+	owner *CachingWrapperFilter
+}
+
+func (e *cacheEvictor) OnClose(ownerCoreCacheKey interface{}) {
+	e.owner.Lock()
+	defer e.owner.Unlock()
+	delete(e.owner.cache, ownerCoreCacheKey)
+}
+
+func (f *CachingWrapperFilter) String() string {
+	return fmt.Sprintf("%v(%v)", reflect.TypeOf(f).Elem().Name(), f.filter)
 }
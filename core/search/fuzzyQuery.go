@@ -0,0 +1,155 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/index/model"
+	"github.com/gzg1984/golucene/core/util/automaton"
+	"unicode/utf8"
+)
+
+// search/FuzzyQuery.java
+
+const (
+	FUZZY_DEFAULT_MAX_EDITS      = automaton.MAXIMUM_SUPPORTED_DISTANCE
+	FUZZY_DEFAULT_PREFIX_LENGTH  = 0
+	FUZZY_DEFAULT_MAX_EXPANSIONS = 50
+	FUZZY_DEFAULT_TRANSPOSITIONS = true
+)
+
+/*
+Implements the fuzzy search query. The similarity measurement is
+based on the Damerau-Levenshtein (optimal string alignment)
+algorithm, though you can explicitly choose classic Levenshtein by
+passing false to the transpositions parameter.
+
+This query uses TopTermsScoringBooleanQueryRewrite as default: it
+rewrites into a BooleanQuery of the maxExpansions most similar terms,
+each boosted by its similarity. So terms will be collected and scored
+according to their edit distance. Only the top terms are used for
+building the BooleanQuery.
+
+At most, this query will match terms up to MAXIMUM_SUPPORTED_DISTANCE
+edits. Higher distances (especially with transpositions enabled), are
+generally not useful and will match a significant amount of the term
+dictionary.
+
+NOTE: terms of length 1 or 2 will sometimes not match because of how
+the scaled distance between two terms is computed. For a term to
+match, the edit distance between the terms must be less than the
+minimum length term (either the input term, or the candidate term).
+For example, FuzzyQuery on term "abcd" with maxEdits=2 will not match
+an indexed term "ab", and FuzzyQuery on term "a" with maxEdits=2 will
+not match an indexed term "abc".
+*/
+type FuzzyQuery struct {
+	*MultiTermQuery
+	maxEdits       int
+	maxExpansions  int
+	transpositions bool
+	prefixLength   int
+	term           *index.Term
+}
+
+// Calls NewFuzzyQueryWith(term, FUZZY_DEFAULT_MAX_EDITS, ...) with
+// all default parameters.
+func NewFuzzyQuery(term *index.Term) *FuzzyQuery {
+	return NewFuzzyQueryWith(term, FUZZY_DEFAULT_MAX_EDITS,
+		FUZZY_DEFAULT_PREFIX_LENGTH, FUZZY_DEFAULT_MAX_EXPANSIONS,
+		FUZZY_DEFAULT_TRANSPOSITIONS)
+}
+
+/*
+Create a new FuzzyQuery that will match terms with an edit distance
+of at most maxEdits to term. If a prefixLength > 0 is specified, a
+common prefix of that length is also required.
+
+maxEdits must be between 0 and MAXIMUM_SUPPORTED_DISTANCE;
+maxExpansions is the maximum number of terms to match, and
+transpositions tells whether a transposition of adjacent characters
+counts as one edit.
+*/
+func NewFuzzyQueryWith(term *index.Term, maxEdits, prefixLength,
+	maxExpansions int, transpositions bool) *FuzzyQuery {
+
+	assert2(maxEdits >= 0 && maxEdits <= automaton.MAXIMUM_SUPPORTED_DISTANCE,
+		"maxEdits must be between 0 and %v", automaton.MAXIMUM_SUPPORTED_DISTANCE)
+	assert2(prefixLength >= 0, "prefixLength cannot be negative.")
+	assert2(maxExpansions > 0, "maxExpansions must be positive.")
+
+	ans := &FuzzyQuery{
+		term:           term,
+		maxEdits:       maxEdits,
+		prefixLength:   prefixLength,
+		transpositions: transpositions,
+		maxExpansions:  maxExpansions,
+	}
+	ans.MultiTermQuery = newMultiTermQuery(ans, term.Field)
+	ans.SetRewriteMethod(NewTopTermsScoringBooleanQueryRewrite(maxExpansions))
+	return ans
+}
+
+// Returns the maximum number of edit distances allowed for this query
+// to match.
+func (q *FuzzyQuery) MaxEdits() int {
+	return q.maxEdits
+}
+
+// Returns the non-fuzzy prefix length. This is the number of
+// characters at the start of a term that must be identical (not
+// fuzzy) to the query term if the query is to match that term.
+func (q *FuzzyQuery) PrefixLength() int {
+	return q.prefixLength
+}
+
+// Returns true if transpositions should be treated as a primitive
+// edit operation.
+func (q *FuzzyQuery) Transpositions() bool {
+	return q.transpositions
+}
+
+// Returns the pattern term.
+func (q *FuzzyQuery) Term() *index.Term {
+	return q.term
+}
+
+func (q *FuzzyQuery) TermsEnum(terms Terms) (TermsEnum, error) {
+	if q.maxEdits == 0 || q.prefixLength >= utf8.RuneCount(q.term.Bytes) { // can only match if it's exact
+		return newSingleTermsEnum(terms.Iterator(nil), q.term.Bytes), nil
+	}
+	return NewFuzzyTermsEnum(terms, q.term, q.maxEdits, q.prefixLength, q.transpositions)
+}
+
+func (q *FuzzyQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	if q.term.Field != field {
+		buf.WriteString(q.term.Field)
+		buf.WriteRune(':')
+	}
+	buf.WriteString(string(q.term.Bytes))
+	fmt.Fprintf(&buf, "~%v", q.maxEdits)
+	if q.boost != 1.0 {
+		buf.WriteString(fmt.Sprintf("^%v", q.boost))
+	}
+	return buf.String()
+}
+
+/*
+Helper function to convert from deprecated "minimumSimilarity"
+fractions to raw edit distances.
+*/
+func FloatToEdits(minimumSimilarity float32, termLen int) int {
+	if minimumSimilarity >= 1 {
+		if minimumSimilarity > automaton.MAXIMUM_SUPPORTED_DISTANCE {
+			return automaton.MAXIMUM_SUPPORTED_DISTANCE
+		}
+		return int(minimumSimilarity)
+	} else if minimumSimilarity == 0 {
+		return 0 // 0 means exact, not infinite # of edits!
+	}
+	if edits := int((1 - float64(minimumSimilarity)) * float64(termLen)); edits < automaton.MAXIMUM_SUPPORTED_DISTANCE {
+		return edits
+	}
+	return automaton.MAXIMUM_SUPPORTED_DISTANCE
+}
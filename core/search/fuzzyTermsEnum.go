@@ -0,0 +1,134 @@
+package search
+
+import (
+	"bytes"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/index/model"
+	"github.com/gzg1984/golucene/core/util/automaton"
+	"unicode/utf8"
+)
+
+// search/FuzzyTermsEnum.java
+
+/*
+Subclass of TermsEnum for enumerating all terms that are similar to
+the specified filter term.
+
+Term enumerations are always ordered by Comparator(). Each term in
+the enumeration is greater than all that precede it.
+
+The enumeration intersects the terms dictionary with a Levenshtein
+automaton accepting all terms within maxEdits of the filter term, then
+computes the exact edit distance of each accepted term to give it a
+boost: 1 for an exact match, else 1 - ed/min(len(term), len(text)).
+*/
+type FuzzyTermsEnum struct {
+	TermsEnum
+	boost float32
+
+	// the automata for each edit distance, for computing the exact
+	// edit distance of an accepted term
+	matchers []*automaton.ByteRunAutomaton
+
+	termText []byte
+	// length of the filter term, in code points
+	termLength int
+
+	maxEdits     int
+	prefixLength int
+}
+
+/*
+Constructor for enumeration of all terms from specified reader which
+share a prefix of length prefixLength with term and which have a
+fuzzy similarity within maxEdits edits.
+*/
+func NewFuzzyTermsEnum(terms Terms, term *index.Term,
+	maxEdits, prefixLength int, transpositions bool) (*FuzzyTermsEnum, error) {
+
+	assert2(maxEdits >= 0 && maxEdits <= automaton.MAXIMUM_SUPPORTED_DISTANCE,
+		"max edits must be 0..%v, inclusive; got: %v",
+		automaton.MAXIMUM_SUPPORTED_DISTANCE, maxEdits)
+	text := []rune(string(term.Bytes))
+	e := &FuzzyTermsEnum{
+		termText:   term.Bytes,
+		termLength: len(text),
+		maxEdits:   maxEdits,
+	}
+	// if the prefix is longer than the term, the whole term is the
+	// prefix
+	e.prefixLength = prefixLength
+	if e.prefixLength > e.termLength {
+		e.prefixLength = e.termLength
+	}
+
+	prefix := string(text[:e.prefixLength])
+	builder := automaton.NewLevenshteinAutomata(string(text[e.prefixLength:]), transpositions)
+	e.matchers = make([]*automaton.ByteRunAutomaton, maxEdits+1)
+	var a *automaton.Automaton
+	for i, _ := range e.matchers {
+		a = builder.ToAutomatonWithPrefix(i, prefix)
+		e.matchers[i] = automaton.NewByteRunAutomaton(a, false)
+	}
+
+	finite := true
+	compiled := automaton.NewCompiledAutomatonWith(a, &finite, false, false)
+	var err error
+	if e.TermsEnum, err = terms.Intersect(compiled, nil); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Returns the boost of the current term, by its similarity to the
+// filter term.
+func (e *FuzzyTermsEnum) Boost() float32 {
+	return e.boost
+}
+
+func (e *FuzzyTermsEnum) Next() ([]byte, error) {
+	for {
+		term, err := e.TermsEnum.Next()
+		if term == nil || err != nil {
+			return nil, err
+		}
+		if e.accept(term) {
+			return term, nil
+		}
+	}
+}
+
+// Finds the smallest edit distance that matches and computes the
+// boost from it; rejects terms with no similarity left.
+func (e *FuzzyTermsEnum) accept(term []byte) bool {
+	// the intersected automaton always matches at maxEdits; now
+	// compute exact edit distance
+	ed := e.maxEdits
+	for ed > 0 && e.matches(term, ed-1) {
+		ed--
+	}
+
+	if ed == 0 { // exact match
+		e.boost = 1
+		return true
+	}
+
+	codePointCount := utf8.RuneCount(term)
+	if codePointCount > e.termLength {
+		codePointCount = e.termLength
+	}
+	similarity := 1 - float32(ed)/float32(codePointCount)
+	if similarity > 0 {
+		e.boost = similarity
+		return true
+	}
+	return false
+}
+
+// Returns true if term is within k edits of the filter term.
+func (e *FuzzyTermsEnum) matches(term []byte, k int) bool {
+	if k == 0 {
+		return bytes.Equal(term, e.termText)
+	}
+	return e.matchers[k].Run(term)
+}
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/search/model"
+	"github.com/gzg1984/golucene/core/util"
+)
+
+// search/MatchAllDocsQuery.java
+
+// A query that matches all documents.
+type MatchAllDocsQuery struct {
+	*AbstractQuery
+}
+
+func NewMatchAllDocsQuery() *MatchAllDocsQuery {
+	ans := new(MatchAllDocsQuery)
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+func (q *MatchAllDocsQuery) CreateWeight(searcher *IndexSearcher) (Weight, error) {
+	ans := &matchAllDocsWeight{owner: q}
+	ans.WeightImpl = newWeightImpl(ans)
+	return ans, nil
+}
+
+func (q *MatchAllDocsQuery) ToString(field string) string {
+	if q.boost != 1 {
+		return fmt.Sprintf("*:*^%v", q.boost)
+	}
+	return "*:*"
+}
+
+type matchAllDocsWeight struct {
+	*WeightImpl
+	owner       *MatchAllDocsQuery
+	queryWeight float32
+	queryNorm   float32
+}
+
+func (w *matchAllDocsWeight) ValueForNormalization() float32 {
+	w.queryWeight = w.owner.boost
+	return w.queryWeight * w.queryWeight
+}
+
+func (w *matchAllDocsWeight) Normalize(norm float32, topLevelBoost float32) {
+	w.queryNorm = norm * topLevelBoost
+	w.queryWeight *= w.queryNorm
+}
+
+func (w *matchAllDocsWeight) Scorer(ctx *index.AtomicReaderContext,
+	acceptDocs util.Bits) (Scorer, error) {
+
+	return newMatchAllScorer(ctx.Reader().MaxDoc(), acceptDocs, w, w.queryWeight), nil
+}
+
+func (w *matchAllDocsWeight) IsScoresDocsOutOfOrder() bool {
+	return false
+}
+
+func (w *matchAllDocsWeight) Explain(ctx *index.AtomicReaderContext, doc int) (Explanation, error) {
+	// explain query weight
+	queryExpl := newComplexExplanation(true, w.queryWeight, "MatchAllDocsQuery, product of:")
+	if w.owner.boost != 1 {
+		queryExpl.addDetail(newExplanation(w.owner.boost, "boost"))
+	}
+	queryExpl.addDetail(newExplanation(w.queryNorm, "queryNorm"))
+	return queryExpl, nil
+}
+
+type matchAllScorer struct {
+	*abstractScorer
+	score      float32
+	doc        int
+	maxDoc     int
+	acceptDocs util.Bits
+}
+
+func newMatchAllScorer(maxDoc int, acceptDocs util.Bits, w Weight, score float32) *matchAllScorer {
+	ans := &matchAllScorer{
+		score:      score,
+		doc:        -1,
+		maxDoc:     maxDoc,
+		acceptDocs: acceptDocs,
+	}
+	ans.abstractScorer = newScorer(ans, w)
+	return ans
+}
+
+func (s *matchAllScorer) DocId() int {
+	return s.doc
+}
+
+func (s *matchAllScorer) NextDoc() (int, error) {
+	s.doc++
+	for s.acceptDocs != nil && s.doc < s.maxDoc && !s.acceptDocs.At(s.doc) {
+		s.doc++
+	}
+	if s.doc >= s.maxDoc {
+		s.doc = NO_MORE_DOCS
+	}
+	return s.doc, nil
+}
+
+func (s *matchAllScorer) Score() (float32, error) {
+	return s.score, nil
+}
+
+func (s *matchAllScorer) Freq() (int, error) {
+	return 1, nil
+}
+
+func (s *matchAllScorer) Advance(target int) (int, error) {
+	s.doc = target - 1
+	return s.NextDoc()
+}
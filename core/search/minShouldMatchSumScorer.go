@@ -0,0 +1,111 @@
+package search
+
+import (
+	"fmt"
+	. "github.com/gzg1984/golucene/core/search/model"
+)
+
+// search/MinShouldMatchSumScorer.java
+
+/*
+A Scorer for OR like queries, counterpart of ConjunctionScorer.
+This Scorer implements Scorer.Advance() and uses Advance() on the
+given Scorers.
+
+Only documents matched by at least minimumNrMatchers of the
+sub-scorers are returned.
+
+TODO: port the "mm-1 least costly scorers" optimization which
+advances the costliest sub-scorers only when a candidate is found.
+*/
+type MinShouldMatchSumScorer struct {
+	*DisjunctionScorer
+	// The minimum number of scorers that should match.
+	mm    int
+	coord []float32
+}
+
+/*
+Construct a MinShouldMatchSumScorer.
+
+subScorers: A collection of at least two subscorers.
+minimumNrMatchers: The positive minimum number of subscorers that
+should match to match this query. When minimumNrMatchers is bigger
+than the number of subScorers, no matches will be produced.
+coord: Table of coordination factors, or nil for the raw sum.
+*/
+func newMinShouldMatchSumScorer(w Weight, subScorers []Scorer,
+	minimumNrMatchers int, coord []float32) *MinShouldMatchSumScorer {
+
+	assert2(minimumNrMatchers > 0, "Minimum nr of matchers must be positive")
+	assert2(len(subScorers) > 1, "There must be at least 2 subScorers")
+	ans := &MinShouldMatchSumScorer{
+		mm:    minimumNrMatchers,
+		coord: coord,
+	}
+	ans.DisjunctionScorer = newDisjunctionScorer(ans, w, subScorers)
+	return ans
+}
+
+func (s *MinShouldMatchSumScorer) DocId() int {
+	return s.doc
+}
+
+func (s *MinShouldMatchSumScorer) NextDoc() (int, error) {
+	doc, err := s.DisjunctionScorer.NextDoc()
+	if err != nil {
+		return 0, err
+	}
+	return s.toMinShouldMatch(doc)
+}
+
+func (s *MinShouldMatchSumScorer) Advance(target int) (int, error) {
+	if s.mm > s.numScorers {
+		s.doc = NO_MORE_DOCS
+		return s.doc, nil
+	}
+	doc, err := s.DisjunctionScorer.Advance(target)
+	if err != nil {
+		return 0, err
+	}
+	return s.toMinShouldMatch(doc)
+}
+
+// Skips candidates matched by fewer than mm sub-scorers.
+func (s *MinShouldMatchSumScorer) toMinShouldMatch(doc int) (int, error) {
+	for doc != NO_MORE_DOCS {
+		if s.mm > s.numScorers {
+			s.doc = NO_MORE_DOCS
+			return s.doc, nil
+		}
+		freq, err := s.Freq()
+		if err != nil {
+			return 0, err
+		}
+		if freq >= s.mm {
+			return doc, nil
+		}
+		if doc, err = s.DisjunctionScorer.NextDoc(); err != nil {
+			return 0, err
+		}
+	}
+	return doc, nil
+}
+
+/*
+Returns the score of the current document matching the query.
+Initially invalid, until NextDoc() is called the first time.
+*/
+func (s *MinShouldMatchSumScorer) Score() (float32, error) {
+	if err := s.countMatches(); err != nil {
+		return 0, err
+	}
+	if s.coord == nil {
+		return float32(s.score), nil
+	}
+	return float32(s.score * float64(s.coord[s.freq])), nil
+}
+
+func (s *MinShouldMatchSumScorer) String() string {
+	return fmt.Sprintf("MinShouldMatchSumScorer(%v, mm=%v)", s.weight, s.mm)
+}
@@ -0,0 +1,490 @@
+package search
+
+import (
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/index/model"
+	. "github.com/gzg1984/golucene/core/search/model"
+	"github.com/gzg1984/golucene/core/util"
+	"reflect"
+	"sort"
+)
+
+// search/MultiPhraseQuery.java
+
+/*
+MultiPhraseQuery is a generalized version of PhraseQuery, with an
+added method AddTerms().
+
+To use this class, to search for the phrase "Microsoft app*" first
+use Add() on the term "Microsoft", then find all terms that have
+"app" as prefix using the field's TermsEnum, and use AddTerms() to
+add them to the query.
+*/
+type MultiPhraseQuery struct {
+	*AbstractQuery
+	field      string
+	termArrays [][]*index.Term
+	positions  []int
+	slop       int
+}
+
+func NewMultiPhraseQuery() *MultiPhraseQuery {
+	ans := &MultiPhraseQuery{}
+	ans.AbstractQuery = NewAbstractQuery(ans)
+	return ans
+}
+
+/*
+Sets the phrase slop for this query.
+See PhraseQuery.SetSlop().
+*/
+func (q *MultiPhraseQuery) SetSlop(s int) {
+	assert2(s >= 0, "slop value cannot be negative")
+	q.slop = s
+}
+
+/*
+Gets the phrase slop for this query.
+See PhraseQuery.Slop().
+*/
+func (q *MultiPhraseQuery) Slop() int {
+	return q.slop
+}
+
+// Add a single term at the next position in the phrase.
+func (q *MultiPhraseQuery) Add(term *index.Term) {
+	q.AddTerms(term)
+}
+
+/*
+Add multiple terms at the next position in the phrase. Any of the
+terms may match.
+*/
+func (q *MultiPhraseQuery) AddTerms(terms ...*index.Term) {
+	position := 0
+	if n := len(q.positions); n > 0 {
+		position = q.positions[n-1] + 1
+	}
+	q.AddTermsAt(terms, position)
+}
+
+/*
+Allows to specify the relative position of terms within the phrase.
+*/
+func (q *MultiPhraseQuery) AddTermsAt(terms []*index.Term, position int) {
+	assert2(len(terms) > 0, "terms must not be empty")
+	if len(q.termArrays) == 0 {
+		q.field = terms[0].Field
+	}
+	for _, term := range terms {
+		assert2(term.Field == q.field,
+			"All phrase terms must be in the same field (%v): %v", q.field, term)
+	}
+
+	q.termArrays = append(q.termArrays, terms)
+	q.positions = append(q.positions, position)
+}
+
+// Returns the list of term arrays, one per position.
+func (q *MultiPhraseQuery) TermArrays() [][]*index.Term {
+	return q.termArrays
+}
+
+// Returns the relative positions of terms in this phrase.
+func (q *MultiPhraseQuery) Positions() []int {
+	return q.positions
+}
+
+func (q *MultiPhraseQuery) Rewrite(reader index.IndexReader) (Query, error) {
+	switch len(q.termArrays) {
+	case 0:
+		bq := NewBooleanQuery()
+		bq.SetBoost(q.boost)
+		return bq, nil
+	case 1: // optimize one-term case
+		bq := NewBooleanQueryDisableCoord(true)
+		for _, term := range q.termArrays[0] {
+			if err := bq.Add(NewTermQuery(term), SHOULD); err != nil {
+				return nil, err
+			}
+		}
+		bq.SetBoost(q.boost)
+		return bq, nil
+	default:
+		return q, nil
+	}
+}
+
+func (q *MultiPhraseQuery) CreateWeight(ss *IndexSearcher) (Weight, error) {
+	return newMultiPhraseWeight(q, ss)
+}
+
+// Prints a user-readable version of this query.
+func (q *MultiPhraseQuery) ToString(f string) string {
+	var buf bytes.Buffer
+	if q.field != f {
+		buf.WriteString(q.field)
+		buf.WriteRune(':')
+	}
+
+	buf.WriteRune('"')
+	lastPos := -1
+	for k, terms := range q.termArrays {
+		position := q.positions[k]
+		if k > 0 {
+			buf.WriteRune(' ')
+			for j := 1; j < position-lastPos; j++ {
+				buf.WriteString("? ")
+			}
+		}
+		if len(terms) > 1 {
+			buf.WriteRune('(')
+			for j, term := range terms {
+				if j > 0 {
+					buf.WriteRune(' ')
+				}
+				buf.WriteString(string(term.Bytes))
+			}
+			buf.WriteRune(')')
+		} else {
+			buf.WriteString(string(terms[0].Bytes))
+		}
+		lastPos = position
+	}
+	buf.WriteRune('"')
+
+	if q.slop != 0 {
+		buf.WriteString(fmt.Sprintf("~%v", q.slop))
+	}
+	if q.boost != 1.0 {
+		buf.WriteString(fmt.Sprintf("^%v", q.boost))
+	}
+	return buf.String()
+}
+
+type MultiPhraseWeight struct {
+	*WeightImpl
+	owner        *MultiPhraseQuery
+	similarity   Similarity
+	stats        SimWeight
+	termContexts map[string]*index.TermContext
+}
+
+func newMultiPhraseWeight(owner *MultiPhraseQuery, ss *IndexSearcher) (*MultiPhraseWeight, error) {
+	sim := ss.similarity
+	ctx := ss.TopReaderContext()
+	termContexts := make(map[string]*index.TermContext)
+
+	// compute idf
+	var allTermStats []TermStatistics
+	for _, terms := range owner.termArrays {
+		for _, term := range terms {
+			termContext, ok := termContexts[term.String()]
+			if !ok {
+				var err error
+				if termContext, err = index.NewTermContextFromTerm(ctx, term); err != nil {
+					return nil, err
+				}
+				termContexts[term.String()] = termContext
+			}
+			allTermStats = append(allTermStats, ss.TermStatistics(term, termContext))
+		}
+	}
+	ans := &MultiPhraseWeight{
+		owner:      owner,
+		similarity: sim,
+		stats: sim.computeWeight(owner.boost,
+			ss.CollectionStatistics(owner.field), allTermStats...),
+		termContexts: termContexts,
+	}
+	ans.WeightImpl = newWeightImpl(ans)
+	return ans, nil
+}
+
+func (w *MultiPhraseWeight) String() string {
+	return fmt.Sprintf("weight(%v)", w.owner)
+}
+
+func (w *MultiPhraseWeight) ValueForNormalization() float32 {
+	return w.stats.ValueForNormalization()
+}
+
+func (w *MultiPhraseWeight) Normalize(norm float32, topLevelBoost float32) {
+	w.stats.Normalize(norm, topLevelBoost)
+}
+
+func (w *MultiPhraseWeight) IsScoresDocsOutOfOrder() bool {
+	return false
+}
+
+func (w *MultiPhraseWeight) Scorer(context *index.AtomicReaderContext,
+	acceptDocs util.Bits) (Scorer, error) {
+
+	assert(len(w.owner.termArrays) > 0)
+	reader := context.Reader().(index.AtomicReader)
+	postingsFreqs := make([]*postingsAndFreq, len(w.owner.termArrays))
+
+	fieldTerms := reader.Terms(w.owner.field)
+	if fieldTerms == nil {
+		return nil, nil
+	}
+
+	// Reuse single TermsEnum below:
+	termsEnum := fieldTerms.Iterator(nil)
+
+	for pos, terms := range w.owner.termArrays {
+		var postingsEnum DocsAndPositionsEnum
+		var docFreq int
+		var err error
+
+		if len(terms) > 1 {
+			if postingsEnum, err = newUnionDocsAndPositionsEnum(acceptDocs,
+				context, terms, w.termContexts, termsEnum); err != nil {
+				return nil, err
+			}
+
+			// coarse -- this overcounts since a given doc can have more
+			// than one term:
+			for _, term := range terms {
+				termState := w.termContexts[term.String()].State(context.Ord)
+				if termState == nil { // term doesnt exist in reader
+					continue
+				}
+				if err = termsEnum.SeekExactFromLast(term.Bytes, termState); err != nil {
+					return nil, err
+				}
+				n, err := termsEnum.DocFreq()
+				if err != nil {
+					return nil, err
+				}
+				docFreq += n
+			}
+
+			if docFreq == 0 { // none of the terms are in this reader
+				return nil, nil
+			}
+		} else {
+			term := terms[0]
+			termState := w.termContexts[term.String()].State(context.Ord)
+			if termState == nil { // term doesnt exist in reader
+				return nil, nil
+			}
+			if err = termsEnum.SeekExactFromLast(term.Bytes, termState); err != nil {
+				return nil, err
+			}
+			if postingsEnum, err = termsEnum.DocsAndPositionsByFlags(acceptDocs, nil, DOCS_ENUM_FLAG_NONE); err != nil {
+				return nil, err
+			}
+
+			if postingsEnum == nil {
+				// term does exist, but has no positions
+				return nil, errors.New(fmt.Sprintf(
+					"field '%v' was indexed without position data; cannot run PhraseQuery (term=%v)",
+					term.Field, string(term.Bytes)))
+			}
+
+			if docFreq, err = termsEnum.DocFreq(); err != nil {
+				return nil, err
+			}
+		}
+
+		postingsFreqs[pos] = newPostingsAndFreq(postingsEnum, docFreq, w.owner.positions[pos], terms...)
+	}
+
+	simScorer, err := w.similarity.simScorer(w.stats, context)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.owner.slop == 0 {
+		// sort by increasing docFreq order
+		sort.Sort(postingsAndFreqSlice(postingsFreqs))
+		return newExactPhraseScorer(w, postingsFreqs, simScorer), nil
+	}
+	return newSloppyPhraseScorer(w, postingsFreqs, w.owner.slop, simScorer), nil
+}
+
+func (w *MultiPhraseWeight) Explain(ctx *index.AtomicReaderContext, doc int) (Explanation, error) {
+	scorer, err := w.Scorer(ctx, ctx.Reader().(index.AtomicReader).LiveDocs())
+	if err != nil {
+		return nil, err
+	}
+	if scorer != nil {
+		newDoc, err := scorer.Advance(doc)
+		if err != nil {
+			return nil, err
+		}
+		if newDoc == doc {
+			var freq float32
+			if s, ok := scorer.(*SloppyPhraseScorer); ok {
+				freq = s.sloppyFreq
+			} else {
+				n, err := scorer.Freq()
+				if err != nil {
+					return nil, err
+				}
+				freq = float32(n)
+			}
+			docScorer, err := w.similarity.simScorer(w.stats, ctx)
+			if err != nil {
+				return nil, err
+			}
+			scoreExplanation := docScorer.explain(doc,
+				newExplanation(freq, fmt.Sprintf("phraseFreq=%v", freq)))
+			ans := newComplexExplanation(true,
+				scoreExplanation.Value(),
+				fmt.Sprintf("weight(%v in %v) [%v], result of:",
+					w.owner, doc, reflect.TypeOf(w.similarity)))
+			ans.details = []Explanation{scoreExplanation}
+			return ans, nil
+		}
+	}
+	return newComplexExplanation(false, 0, "no matching term"), nil
+}
+
+/*
+Takes the logical union of multiple DocsEnum iterators.
+
+Note: positions are merged during freq()
+*/
+type unionDocsAndPositionsEnum struct {
+	doc     int
+	freq    int
+	queue   *PriorityQueue // DocsAndPositionsEnum ordered by DocId()
+	posList []int
+	posUpto int
+}
+
+func newUnionDocsAndPositionsEnum(liveDocs util.Bits,
+	context *index.AtomicReaderContext, terms []*index.Term,
+	termContexts map[string]*index.TermContext,
+	termsEnum TermsEnum) (*unionDocsAndPositionsEnum, error) {
+
+	queue := &PriorityQueue{items: make([]interface{}, 0, len(terms))}
+	queue.less = func(i, j int) bool {
+		return queue.items[i].(DocsAndPositionsEnum).DocId() <
+			queue.items[j].(DocsAndPositionsEnum).DocId()
+	}
+
+	for _, term := range terms {
+		termState := termContexts[term.String()].State(context.Ord)
+		if termState == nil { // term doesnt exist in reader
+			continue
+		}
+		if err := termsEnum.SeekExactFromLast(term.Bytes, termState); err != nil {
+			return nil, err
+		}
+		postings, err := termsEnum.DocsAndPositionsByFlags(liveDocs, nil, DOCS_ENUM_FLAG_NONE)
+		if err != nil {
+			return nil, err
+		}
+		if postings == nil {
+			// term does exist, but has no positions
+			return nil, errors.New(fmt.Sprintf(
+				"field '%v' was indexed without position data; cannot run PhraseQuery (term=%v)",
+				term.Field, string(term.Bytes)))
+		}
+		doc, err := postings.NextDoc()
+		if err != nil {
+			return nil, err
+		}
+		if doc != NO_MORE_DOCS {
+			queue.items = append(queue.items, postings)
+		}
+	}
+	heap.Init(queue)
+
+	return &unionDocsAndPositionsEnum{doc: -1, queue: queue}, nil
+}
+
+func (e *unionDocsAndPositionsEnum) top() DocsAndPositionsEnum {
+	return e.queue.items[0].(DocsAndPositionsEnum)
+}
+
+func (e *unionDocsAndPositionsEnum) NextDoc() (int, error) {
+	if e.queue.Len() == 0 {
+		e.doc = NO_MORE_DOCS
+		return e.doc, nil
+	}
+
+	// TODO: move this init into positions(): if the search doesn't
+	// need the positions for this doc then don't waste CPU merging
+	// them:
+	e.posList = e.posList[:0]
+	e.posUpto = 0
+	e.doc = e.top().DocId()
+
+	// merge sort all positions together
+	for e.queue.Len() > 0 && e.top().DocId() == e.doc {
+		postings := e.top()
+		freq, err := postings.Freq()
+		if err != nil {
+			return 0, err
+		}
+		for i := 0; i < freq; i++ {
+			pos, err := postings.NextPosition()
+			if err != nil {
+				return 0, err
+			}
+			e.posList = append(e.posList, pos)
+		}
+
+		next, err := postings.NextDoc()
+		if err != nil {
+			return 0, err
+		}
+		if next != NO_MORE_DOCS {
+			e.queue.updateTop()
+		} else {
+			heap.Pop(e.queue)
+		}
+	}
+
+	sort.Ints(e.posList)
+	e.freq = len(e.posList)
+	return e.doc, nil
+}
+
+func (e *unionDocsAndPositionsEnum) NextPosition() (int, error) {
+	pos := e.posList[e.posUpto]
+	e.posUpto++
+	return pos, nil
+}
+
+func (e *unionDocsAndPositionsEnum) StartOffset() (int, error) {
+	return -1, nil
+}
+
+func (e *unionDocsAndPositionsEnum) EndOffset() (int, error) {
+	return -1, nil
+}
+
+func (e *unionDocsAndPositionsEnum) Payload() ([]byte, error) {
+	return nil, nil
+}
+
+func (e *unionDocsAndPositionsEnum) Advance(target int) (int, error) {
+	for e.queue.Len() > 0 && target > e.top().DocId() {
+		postings := heap.Pop(e.queue).(DocsAndPositionsEnum)
+		doc, err := postings.Advance(target)
+		if err != nil {
+			return 0, err
+		}
+		if doc != NO_MORE_DOCS {
+			heap.Push(e.queue, postings)
+		}
+	}
+	return e.NextDoc()
+}
+
+func (e *unionDocsAndPositionsEnum) Freq() (int, error) {
+	return e.freq, nil
+}
+
+func (e *unionDocsAndPositionsEnum) DocId() int {
+	return e.doc
+}
@@ -0,0 +1,367 @@
+package search
+
+import (
+	"container/heap"
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/index/model"
+	"sort"
+)
+
+// search/MultiTermQuery.java
+
+/*
+An abstract Query that matches documents containing a subset of terms
+provided by a TermsEnum enumeration.
+
+This query cannot be used directly; you must subclass it and define
+TermsEnum() to provide a TermsEnum that iterates through the terms
+to be matched.
+
+NOTE: if RewriteMethod is either CONSTANT_SCORE_BOOLEAN_QUERY_REWRITE
+or SCORING_BOOLEAN_QUERY_REWRITE, you may encounter a TooManyClauses
+error during searching, which happens when the number of terms to be
+searched exceeds MaxClauseCount(). Setting RewriteMethod to
+CONSTANT_SCORE_FILTER_REWRITE prevents this.
+
+The recommended rewrite method is CONSTANT_SCORE_FILTER_REWRITE, which
+is the default: it doesn't spend CPU computing unhelpful scores, and
+it tries to pick the most performant rewrite method given the query.
+If you need scoring (like FuzzyQuery), use
+TopTermsScoringBooleanQueryRewrite which uses a priority queue to only
+collect competitive terms and not hit this limitation.
+*/
+type MultiTermQuery struct {
+	*AbstractQuery
+	spi           MultiTermQuerySPI
+	field         string
+	rewriteMethod RewriteMethod
+}
+
+type MultiTermQuerySPI interface {
+	QuerySPI
+	/*
+		Construct the enumeration to be used, expanding the pattern
+		term. This method should only be called if the field exists
+		(ie, implementations can assume the field does exist). This
+		method should not return nil (should instead return
+		EMPTY_TERMS_ENUM if no terms match). The TermsEnum must
+		already be positioned to the first matching term.
+	*/
+	TermsEnum(terms Terms) (TermsEnum, error)
+}
+
+/*
+Implemented by a TermsEnum that rates each of its terms, e.g. by
+similarity to a fuzzy term. The rating is used as the boost of the
+term's clause on rewrite; terms of other enums get a boost of 1.
+*/
+type BoostAttribute interface {
+	// Retrieves the boost of the current term.
+	Boost() float32
+}
+
+// Abstract type that defines how the query is rewritten.
+type RewriteMethod interface {
+	Rewrite(reader index.IndexReader, query *MultiTermQuery) (Query, error)
+}
+
+// Constructs a query matching terms that cannot be represented with
+// a single Term.
+func newMultiTermQuery(self interface{}, field string) *MultiTermQuery {
+	assert2(field != "", "field must not be empty")
+	return &MultiTermQuery{
+		AbstractQuery: NewAbstractQuery(self),
+		spi:           self.(MultiTermQuerySPI),
+		field:         field,
+		rewriteMethod: CONSTANT_SCORE_FILTER_REWRITE,
+	}
+}
+
+// Returns the field name for this query
+func (q *MultiTermQuery) Field() string {
+	return q.field
+}
+
+/*
+To rewrite to a simpler form, instead return a simpler enum from
+TermsEnum(). For example, to rewrite to a single term, return a
+singleTermsEnum.
+*/
+func (q *MultiTermQuery) Rewrite(reader index.IndexReader) (Query, error) {
+	return q.rewriteMethod.Rewrite(reader, q)
+}
+
+// Returns the rewrite method used to build the final query
+func (q *MultiTermQuery) RewriteMethod() RewriteMethod {
+	return q.rewriteMethod
+}
+
+/*
+Sets the rewrite method to be used when executing the query. You can
+use one of the predefined rewrite methods, or create your own.
+*/
+func (q *MultiTermQuery) SetRewriteMethod(method RewriteMethod) {
+	q.rewriteMethod = method
+}
+
+/*
+Calls collect for each term of each segment of reader matched by
+query, with the boost of the term. Stops early if collect returns
+false.
+*/
+func collectTerms(reader index.IndexReader, query *MultiTermQuery,
+	collect func(term []byte, boost float32) (bool, error)) error {
+
+	for _, ctx := range reader.Context().Leaves() {
+		terms := ctx.Reader().(index.AtomicReader).Terms(query.field)
+		if terms == nil {
+			// field does not exist
+			continue
+		}
+		termsEnum, err := query.spi.TermsEnum(terms)
+		if err != nil {
+			return err
+		}
+		assert(termsEnum != nil)
+		if termsEnum == EMPTY_TERMS_ENUM {
+			continue
+		}
+		boostAtt, hasBoost := termsEnum.(BoostAttribute)
+		for {
+			term, err := termsEnum.Next()
+			if err != nil {
+				return err
+			}
+			if term == nil {
+				break
+			}
+			boost := float32(1)
+			if hasBoost {
+				boost = boostAtt.Boost()
+			}
+			ok, err := collect(term, boost)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// search/ScoringRewrite.java
+
+/*
+A rewrite method that first translates each term into SHOULD clause
+in a BooleanQuery, and keeps the scores as computed by the query.
+Note that typically such scores are meaningless to the user, and
+require non-trivial CPU to compute, so it's almost always better to
+use CONSTANT_SCORE_FILTER_REWRITE instead.
+
+NOTE: This rewrite method will return a TooManyClauses error if the
+number of terms exceeds MaxClauseCount().
+*/
+var SCORING_BOOLEAN_QUERY_REWRITE RewriteMethod = scoringBooleanQueryRewrite{}
+
+type scoringBooleanQueryRewrite struct{}
+
+func (rw scoringBooleanQueryRewrite) Rewrite(reader index.IndexReader,
+	query *MultiTermQuery) (Query, error) {
+
+	boosts := make(map[string]float32)
+	if err := collectTerms(reader, query, func(term []byte, boost float32) (bool, error) {
+		if _, ok := boosts[string(term)]; !ok {
+			if len(boosts) >= maxClauseCount {
+				return false, new(TooManyClauses)
+			}
+			boosts[string(term)] = boost
+		}
+		return true, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, 0, len(boosts))
+	for text, _ := range boosts {
+		texts = append(texts, text)
+	}
+	sort.Strings(texts)
+
+	result := NewBooleanQueryDisableCoord(true)
+	for _, text := range texts {
+		tq := NewTermQuery(index.NewTerm(query.field, text))
+		tq.SetBoost(query.boost * boosts[text])
+		result.Add(tq, SHOULD)
+	}
+	return result, nil
+}
+
+func (rw scoringBooleanQueryRewrite) String() string {
+	return "SCORING_BOOLEAN_QUERY_REWRITE"
+}
+
+/*
+Like SCORING_BOOLEAN_QUERY_REWRITE except scores are not computed.
+Instead, each matching document receives a constant score equal to
+the query's boost.
+
+NOTE: This rewrite method will return a TooManyClauses error if the
+number of terms exceeds MaxClauseCount().
+*/
+var CONSTANT_SCORE_BOOLEAN_QUERY_REWRITE RewriteMethod = constantScoreBooleanQueryRewrite{}
+
+type constantScoreBooleanQueryRewrite struct{}
+
+func (rw constantScoreBooleanQueryRewrite) Rewrite(reader index.IndexReader,
+	query *MultiTermQuery) (Query, error) {
+
+	bq, err := SCORING_BOOLEAN_QUERY_REWRITE.Rewrite(reader, query)
+	if err != nil {
+		return nil, err
+	}
+	// strip the scores off
+	result := NewConstantScoreQuery(bq)
+	result.SetBoost(query.boost)
+	return result, nil
+}
+
+func (rw constantScoreBooleanQueryRewrite) String() string {
+	return "CONSTANT_SCORE_BOOLEAN_QUERY_REWRITE"
+}
+
+/*
+A rewrite method that first creates a private Filter, by visiting
+each term in sequence and marking all docs for that term. Matching
+documents are assigned a constant score equal to the query's boost.
+
+This method is faster than the BooleanQuery rewrite methods when the
+number of matched terms or matched documents is non-trivial. Also, it
+will never hit an errant TooManyClauses error.
+*/
+var CONSTANT_SCORE_FILTER_REWRITE RewriteMethod = constantScoreFilterRewrite{}
+
+type constantScoreFilterRewrite struct{}
+
+func (rw constantScoreFilterRewrite) Rewrite(reader index.IndexReader,
+	query *MultiTermQuery) (Query, error) {
+
+	result := NewConstantScoreQueryWithFilter(NewMultiTermQueryWrapperFilter(query))
+	result.SetBoost(query.boost)
+	return result, nil
+}
+
+func (rw constantScoreFilterRewrite) String() string {
+	return "CONSTANT_SCORE_FILTER_REWRITE"
+}
+
+// search/TopTermsRewrite.java
+
+type scoreTerm struct {
+	text  string
+	boost float32
+}
+
+/* Returns true if a is less competitive than b, i.e. b is preferred. */
+func lessCompetitive(a, b *scoreTerm) bool {
+	if a.boost == b.boost {
+		return a.text > b.text
+	}
+	return a.boost < b.boost
+}
+
+/* A heap of the collected terms, with the least competitive on top. */
+type scoreTermQueue []*scoreTerm
+
+func (q scoreTermQueue) Len() int            { return len(q) }
+func (q scoreTermQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q scoreTermQueue) Less(i, j int) bool  { return lessCompetitive(q[i], q[j]) }
+func (q *scoreTermQueue) Push(x interface{}) { *q = append(*q, x.(*scoreTerm)) }
+func (q *scoreTermQueue) Pop() interface{} {
+	n := len(*q) - 1
+	ans := (*q)[n]
+	*q = (*q)[:n]
+	return ans
+}
+
+type scoreTermsByText []*scoreTerm
+
+func (a scoreTermsByText) Len() int           { return len(a) }
+func (a scoreTermsByText) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a scoreTermsByText) Less(i, j int) bool { return a[i].text < a[j].text }
+
+/*
+A rewrite method that first translates each term into SHOULD clause
+in a BooleanQuery, and keeps the scores as computed by the query.
+
+This rewrite method only uses the top scoring terms, ranked by the
+boost of the query's TermsEnum (see BoostAttribute), so it will not
+overflow the boolean max clause count. It is the default rewrite
+method for FuzzyQuery.
+*/
+type TopTermsScoringBooleanQueryRewrite struct {
+	size int
+}
+
+/*
+Create a TopTermsScoringBooleanQueryRewrite for at most size terms.
+
+NOTE: if MaxClauseCount() is smaller than size, then it will be used
+instead.
+*/
+func NewTopTermsScoringBooleanQueryRewrite(size int) *TopTermsScoringBooleanQueryRewrite {
+	return &TopTermsScoringBooleanQueryRewrite{size}
+}
+
+// Return the maximum size of the priority queue (for boolean rewrites
+// this is MaxClauseCount()).
+func (rw *TopTermsScoringBooleanQueryRewrite) Size() int {
+	return rw.size
+}
+
+func (rw *TopTermsScoringBooleanQueryRewrite) Rewrite(reader index.IndexReader,
+	query *MultiTermQuery) (Query, error) {
+
+	size := rw.size
+	if size > maxClauseCount {
+		size = maxClauseCount
+	}
+	// only the terms currently in the queue are remembered, so that
+	// a term seen in multiple segments is not added twice
+	queue := make(scoreTermQueue, 0, size+1)
+	visited := make(map[string]*scoreTerm)
+	if err := collectTerms(reader, query, func(term []byte, boost float32) (bool, error) {
+		st := &scoreTerm{string(term), boost}
+		if _, ok := visited[st.text]; ok {
+			return true, nil
+		}
+		if len(queue) >= size && (size == 0 || !lessCompetitive(queue[0], st)) {
+			return true, nil // not competitive
+		}
+		heap.Push(&queue, st)
+		visited[st.text] = st
+		if len(queue) > size {
+			delete(visited, heap.Pop(&queue).(*scoreTerm).text)
+		}
+		return true, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	scoreTerms := []*scoreTerm(queue)
+	sort.Sort(scoreTermsByText(scoreTerms))
+
+	result := NewBooleanQueryDisableCoord(true)
+	for _, st := range scoreTerms {
+		tq := NewTermQuery(index.NewTerm(query.field, st.text))
+		tq.SetBoost(query.boost * st.boost)
+		result.Add(tq, SHOULD)
+	}
+	return result, nil
+}
+
+func (rw *TopTermsScoringBooleanQueryRewrite) String() string {
+	return fmt.Sprintf("TopTermsScoringBooleanQueryRewrite(%v)", rw.size)
+}
@@ -0,0 +1,88 @@
+package search
+
+import (
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/index/model"
+	. "github.com/gzg1984/golucene/core/search/model"
+	"github.com/gzg1984/golucene/core/util"
+)
+
+// search/MultiTermQueryWrapperFilter.java
+
+/*
+A wrapper for MultiTermQuery, that exposes its functionality as a
+Filter.
+
+MultiTermQueryWrapperFilter is not designed to be used by itself.
+Normally you subclass it to provide a Filter counterpart for a
+MultiTermQuery subclass.
+
+This type also provides the functionality behind
+CONSTANT_SCORE_FILTER_REWRITE; this is why it is not abstract.
+*/
+type MultiTermQueryWrapperFilter struct {
+	query *MultiTermQuery
+}
+
+// Wrap a MultiTermQuery as a Filter.
+func NewMultiTermQueryWrapperFilter(query *MultiTermQuery) *MultiTermQueryWrapperFilter {
+	return &MultiTermQueryWrapperFilter{query}
+}
+
+// Returns the field name for this query
+func (f *MultiTermQueryWrapperFilter) Field() string {
+	return f.query.field
+}
+
+/*
+Returns a DocIdSet with documents that should be permitted in search
+results.
+*/
+func (f *MultiTermQueryWrapperFilter) DocIdSet(ctx *index.AtomicReaderContext,
+	acceptDocs util.Bits) (DocIdSet, error) {
+
+	reader := ctx.Reader().(index.AtomicReader)
+	terms := reader.Terms(f.query.field)
+	if terms == nil {
+		// field does not exist
+		return nil, nil
+	}
+
+	termsEnum, err := f.query.spi.TermsEnum(terms)
+	if err != nil {
+		return nil, err
+	}
+	assert(termsEnum != nil)
+	term, err := termsEnum.Next()
+	if term == nil || err != nil {
+		return nil, err
+	}
+
+	// fill into a FixedBitSet
+	bitSet := util.NewFixedBitSetOf(reader.MaxDoc())
+	var docsEnum DocsEnum
+	for term != nil {
+		// we don't need freqs
+		if docsEnum, err = termsEnum.DocsByFlags(acceptDocs, docsEnum, DOCS_ENUM_FLAG_NONE); err != nil {
+			return nil, err
+		}
+		doc, err := docsEnum.NextDoc()
+		for ; doc != NO_MORE_DOCS && err == nil; doc, err = docsEnum.NextDoc() {
+			bitSet.Set(doc)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if term, err = termsEnum.Next(); err != nil {
+			return nil, err
+		}
+	}
+	return bitSet, nil
+}
+
+func (f *MultiTermQueryWrapperFilter) String() string {
+	// query.ToString should be ok for the filter, too, if the query
+	// boost is 1.0
+	return fmt.Sprintf("%v", f.query)
+}
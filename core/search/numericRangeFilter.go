@@ -0,0 +1,144 @@
+package search
+
+import (
+	"github.com/gzg1984/golucene/core/util"
+)
+
+// search/NumericRangeFilter.java
+
+/*
+A Filter that only accepts numeric values within a specified range.
+To use this, you must first index the numeric values using IntField,
+FloatField, LongField or DoubleField (expert: NumericTokenStream).
+
+You create a new NumericRangeFilter with one of the typed
+constructors, for example to accept all documents whose float64
+valued "price" field ranges from 10 to 99.99, inclusive:
+
+	min, max := 10.0, 99.99
+	f := NewNumericRangeFilterDouble("price", &min, &max, true, true)
+
+See NumericRangeQuery for details on how Lucene indexes and searches
+numeric valued fields.
+*/
+type NumericRangeFilter struct {
+	*MultiTermQueryWrapperFilter
+	query *NumericRangeQuery
+}
+
+func newNumericRangeFilter(query *NumericRangeQuery) *NumericRangeFilter {
+	return &NumericRangeFilter{NewMultiTermQueryWrapperFilter(query.MultiTermQuery), query}
+}
+
+/*
+Factory that creates a NumericRangeFilter, that filters an int64
+range using the given precisionStep. You can have half-open ranges
+(which are in fact </<= or >/>= queries) by setting the min or max
+value to nil. By setting inclusive to false, it will match all
+documents excluding the bounds, with inclusive on, the boundaries are
+hits, too.
+*/
+func NewNumericRangeFilterLongWithStep(field string, precisionStep int,
+	min, max *int64, minInclusive, maxInclusive bool) *NumericRangeFilter {
+
+	return newNumericRangeFilter(NewNumericRangeQueryLongWithStep(field,
+		precisionStep, min, max, minInclusive, maxInclusive))
+}
+
+/*
+Factory that creates a NumericRangeFilter, that filters an int64
+range using the default precisionStep NUMERIC_PRECISION_STEP_DEFAULT
+(16).
+*/
+func NewNumericRangeFilterLong(field string, min, max *int64,
+	minInclusive, maxInclusive bool) *NumericRangeFilter {
+
+	return NewNumericRangeFilterLongWithStep(field, util.NUMERIC_PRECISION_STEP_DEFAULT,
+		min, max, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeFilter, that filters an int32
+range using the given precisionStep.
+*/
+func NewNumericRangeFilterIntWithStep(field string, precisionStep int,
+	min, max *int32, minInclusive, maxInclusive bool) *NumericRangeFilter {
+
+	return newNumericRangeFilter(NewNumericRangeQueryIntWithStep(field,
+		precisionStep, min, max, minInclusive, maxInclusive))
+}
+
+/*
+Factory that creates a NumericRangeFilter, that filters an int32
+range using the default precisionStep NUMERIC_PRECISION_STEP_DEFAULT_32
+(8).
+*/
+func NewNumericRangeFilterInt(field string, min, max *int32,
+	minInclusive, maxInclusive bool) *NumericRangeFilter {
+
+	return NewNumericRangeFilterIntWithStep(field, util.NUMERIC_PRECISION_STEP_DEFAULT_32,
+		min, max, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeFilter, that filters a float64
+range using the given precisionStep. NaN will never match a half-open
+range, to hit NaN use a query with min == max == NaN.
+*/
+func NewNumericRangeFilterDoubleWithStep(field string, precisionStep int,
+	min, max *float64, minInclusive, maxInclusive bool) *NumericRangeFilter {
+
+	return newNumericRangeFilter(NewNumericRangeQueryDoubleWithStep(field,
+		precisionStep, min, max, minInclusive, maxInclusive))
+}
+
+/*
+Factory that creates a NumericRangeFilter, that filters a float64
+range using the default precisionStep NUMERIC_PRECISION_STEP_DEFAULT
+(16).
+*/
+func NewNumericRangeFilterDouble(field string, min, max *float64,
+	minInclusive, maxInclusive bool) *NumericRangeFilter {
+
+	return NewNumericRangeFilterDoubleWithStep(field, util.NUMERIC_PRECISION_STEP_DEFAULT,
+		min, max, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeFilter, that filters a float32
+range using the given precisionStep. NaN will never match a half-open
+range, to hit NaN use a query with min == max == NaN.
+*/
+func NewNumericRangeFilterFloatWithStep(field string, precisionStep int,
+	min, max *float32, minInclusive, maxInclusive bool) *NumericRangeFilter {
+
+	return newNumericRangeFilter(NewNumericRangeQueryFloatWithStep(field,
+		precisionStep, min, max, minInclusive, maxInclusive))
+}
+
+/*
+Factory that creates a NumericRangeFilter, that filters a float32
+range using the default precisionStep NUMERIC_PRECISION_STEP_DEFAULT_32
+(8).
+*/
+func NewNumericRangeFilterFloat(field string, min, max *float32,
+	minInclusive, maxInclusive bool) *NumericRangeFilter {
+
+	return NewNumericRangeFilterFloatWithStep(field, util.NUMERIC_PRECISION_STEP_DEFAULT_32,
+		min, max, minInclusive, maxInclusive)
+}
+
+// Returns true if the lower endpoint is inclusive
+func (f *NumericRangeFilter) IncludesMin() bool { return f.query.IncludesMin() }
+
+// Returns true if the upper endpoint is inclusive
+func (f *NumericRangeFilter) IncludesMax() bool { return f.query.IncludesMax() }
+
+// Returns the lower value of this range filter, or nil if open.
+func (f *NumericRangeFilter) Min() interface{} { return f.query.Min() }
+
+// Returns the upper value of this range filter, or nil if open.
+func (f *NumericRangeFilter) Max() interface{} { return f.query.Max() }
+
+// Returns the precision step.
+func (f *NumericRangeFilter) PrecisionStep() int { return f.query.PrecisionStep() }
@@ -0,0 +1,461 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gzg1984/golucene/core/document"
+	. "github.com/gzg1984/golucene/core/index/model"
+	"github.com/gzg1984/golucene/core/util"
+	"math"
+)
+
+// search/NumericRangeQuery.java
+
+/*
+A Query that matches numeric values within a specified range. To use
+this, you must first index the numeric values using IntField,
+FloatField, LongField or DoubleField (expert: NumericTokenStream). If
+your terms are instead textual, you should use TermRangeQuery.
+NumericRangeFilter is the filter equivalent of this query.
+
+You create a new NumericRangeQuery with one of the typed constructors,
+for example to match all documents whose float32 valued "weight"
+field ranges from 0.03 to 0.10, inclusive:
+
+	min, max := float32(0.03), float32(0.10)
+	q := NewNumericRangeQueryFloat("weight", &min, &max, true, true)
+
+A nil bound means the range is open on that side.
+
+The numeric values are indexed as trie terms at several precisions,
+one for each precisionStep bits of the value. The range is split into
+sub-ranges (see SplitLongRange()): the center of the range is matched
+with the lowest possible precision, while its boundaries are matched
+more exactly. This reduces the number of terms to visit dramatically,
+compared to visiting every full precision term in the range.
+
+You can choose any precisionStep when encoding values. Lower step
+values mean more precisions and so more terms in the index (and index
+gets larger), but range queries visit fewer terms. The query must use
+the same precisionStep the field was indexed with, otherwise it will
+not match. The defaults are NUMERIC_PRECISION_STEP_DEFAULT (16) for
+64 bit types and NUMERIC_PRECISION_STEP_DEFAULT_32 (8) for 32 bit
+types, which are good values for most use cases.
+
+This query uses CONSTANT_SCORE_FILTER_REWRITE by default, so it
+never hits TooManyClauses.
+*/
+type NumericRangeQuery struct {
+	*MultiTermQuery
+	precisionStep              int
+	dataType                   document.NumericType
+	min, max                   interface{}
+	minInclusive, maxInclusive bool
+}
+
+func newNumericRangeQuery(field string, precisionStep int, dataType document.NumericType,
+	min, max interface{}, minInclusive, maxInclusive bool) *NumericRangeQuery {
+
+	assert2(precisionStep >= 1, "precisionStep must be >=1")
+	ans := &NumericRangeQuery{
+		precisionStep: precisionStep,
+		dataType:      dataType,
+		min:           min,
+		max:           max,
+		minInclusive:  minInclusive,
+		maxInclusive:  maxInclusive,
+	}
+	ans.MultiTermQuery = newMultiTermQuery(ans, field)
+	return ans
+}
+
+/*
+Factory that creates a NumericRangeQuery, that queries an int64 range
+using the given precisionStep. You can have half-open ranges (which
+are in fact </<= or >/>= queries) by setting the min or max value to
+nil. By setting inclusive to false, it will match all documents
+excluding the bounds, with inclusive on, the boundaries are hits, too.
+*/
+func NewNumericRangeQueryLongWithStep(field string, precisionStep int,
+	min, max *int64, minInclusive, maxInclusive bool) *NumericRangeQuery {
+
+	var lower, upper interface{}
+	if min != nil {
+		lower = *min
+	}
+	if max != nil {
+		upper = *max
+	}
+	return newNumericRangeQuery(field, precisionStep, document.FIELD_TYPE_NUMERIC_LONG,
+		lower, upper, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeQuery, that queries an int64 range
+using the default precisionStep NUMERIC_PRECISION_STEP_DEFAULT (16).
+*/
+func NewNumericRangeQueryLong(field string, min, max *int64,
+	minInclusive, maxInclusive bool) *NumericRangeQuery {
+
+	return NewNumericRangeQueryLongWithStep(field, util.NUMERIC_PRECISION_STEP_DEFAULT,
+		min, max, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeQuery, that queries an int32 range
+using the given precisionStep. You can have half-open ranges (which
+are in fact </<= or >/>= queries) by setting the min or max value to
+nil. By setting inclusive to false, it will match all documents
+excluding the bounds, with inclusive on, the boundaries are hits, too.
+*/
+func NewNumericRangeQueryIntWithStep(field string, precisionStep int,
+	min, max *int32, minInclusive, maxInclusive bool) *NumericRangeQuery {
+
+	var lower, upper interface{}
+	if min != nil {
+		lower = *min
+	}
+	if max != nil {
+		upper = *max
+	}
+	return newNumericRangeQuery(field, precisionStep, document.FIELD_TYPE_NUMERIC_INT,
+		lower, upper, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeQuery, that queries an int32 range
+using the default precisionStep NUMERIC_PRECISION_STEP_DEFAULT_32 (8).
+*/
+func NewNumericRangeQueryInt(field string, min, max *int32,
+	minInclusive, maxInclusive bool) *NumericRangeQuery {
+
+	return NewNumericRangeQueryIntWithStep(field, util.NUMERIC_PRECISION_STEP_DEFAULT_32,
+		min, max, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeQuery, that queries a float64
+range using the given precisionStep. You can have half-open ranges
+(which are in fact </<= or >/>= queries) by setting the min or max
+value to nil. math.NaN() will never match a half-open range, to hit
+NaN use a query with min == max == math.NaN(). By setting inclusive
+to false, it will match all documents excluding the bounds, with
+inclusive on, the boundaries are hits, too.
+*/
+func NewNumericRangeQueryDoubleWithStep(field string, precisionStep int,
+	min, max *float64, minInclusive, maxInclusive bool) *NumericRangeQuery {
+
+	var lower, upper interface{}
+	if min != nil {
+		lower = *min
+	}
+	if max != nil {
+		upper = *max
+	}
+	return newNumericRangeQuery(field, precisionStep, document.FIELD_TYPE_NUMERIC_DOUBLE,
+		lower, upper, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeQuery, that queries a float64
+range using the default precisionStep NUMERIC_PRECISION_STEP_DEFAULT
+(16).
+*/
+func NewNumericRangeQueryDouble(field string, min, max *float64,
+	minInclusive, maxInclusive bool) *NumericRangeQuery {
+
+	return NewNumericRangeQueryDoubleWithStep(field, util.NUMERIC_PRECISION_STEP_DEFAULT,
+		min, max, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeQuery, that queries a float32
+range using the given precisionStep. You can have half-open ranges
+(which are in fact </<= or >/>= queries) by setting the min or max
+value to nil. NaN will never match a half-open range, to hit NaN use
+a query with min == max == NaN. By setting inclusive to false, it
+will match all documents excluding the bounds, with inclusive on, the
+boundaries are hits, too.
+*/
+func NewNumericRangeQueryFloatWithStep(field string, precisionStep int,
+	min, max *float32, minInclusive, maxInclusive bool) *NumericRangeQuery {
+
+	var lower, upper interface{}
+	if min != nil {
+		lower = *min
+	}
+	if max != nil {
+		upper = *max
+	}
+	return newNumericRangeQuery(field, precisionStep, document.FIELD_TYPE_NUMERIC_FLOAT,
+		lower, upper, minInclusive, maxInclusive)
+}
+
+/*
+Factory that creates a NumericRangeQuery, that queries a float32
+range using the default precisionStep NUMERIC_PRECISION_STEP_DEFAULT_32
+(8).
+*/
+func NewNumericRangeQueryFloat(field string, min, max *float32,
+	minInclusive, maxInclusive bool) *NumericRangeQuery {
+
+	return NewNumericRangeQueryFloatWithStep(field, util.NUMERIC_PRECISION_STEP_DEFAULT_32,
+		min, max, minInclusive, maxInclusive)
+}
+
+func (q *NumericRangeQuery) TermsEnum(terms Terms) (TermsEnum, error) {
+	if q.min != nil && q.max != nil && compareNumbers(q.min, q.max) > 0 {
+		return EMPTY_TERMS_ENUM, nil
+	}
+	return newNumericRangeTermsEnum(terms.Iterator(nil), q), nil
+}
+
+// Returns true if the lower endpoint is inclusive
+func (q *NumericRangeQuery) IncludesMin() bool { return q.minInclusive }
+
+// Returns true if the upper endpoint is inclusive
+func (q *NumericRangeQuery) IncludesMax() bool { return q.maxInclusive }
+
+// Returns the lower value of this range query, or nil if open.
+func (q *NumericRangeQuery) Min() interface{} { return q.min }
+
+// Returns the upper value of this range query, or nil if open.
+func (q *NumericRangeQuery) Max() interface{} { return q.max }
+
+// Returns the precision step.
+func (q *NumericRangeQuery) PrecisionStep() int { return q.precisionStep }
+
+func (q *NumericRangeQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	if q.field != field {
+		buf.WriteString(q.field)
+		buf.WriteRune(':')
+	}
+	if q.minInclusive {
+		buf.WriteRune('[')
+	} else {
+		buf.WriteRune('{')
+	}
+	if q.min == nil {
+		buf.WriteRune('*')
+	} else {
+		fmt.Fprintf(&buf, "%v", q.min)
+	}
+	buf.WriteString(" TO ")
+	if q.max == nil {
+		buf.WriteRune('*')
+	} else {
+		fmt.Fprintf(&buf, "%v", q.max)
+	}
+	if q.maxInclusive {
+		buf.WriteRune(']')
+	} else {
+		buf.WriteRune('}')
+	}
+	if q.boost != 1.0 {
+		fmt.Fprintf(&buf, "^%v", q.boost)
+	}
+	return buf.String()
+}
+
+// Compares two bounds of the same numeric type.
+func compareNumbers(a, b interface{}) int {
+	var less, greater bool
+	switch a := a.(type) {
+	case int32:
+		less, greater = a < b.(int32), a > b.(int32)
+	case int64:
+		less, greater = a < b.(int64), a > b.(int64)
+	case float32:
+		// compare like Float.compareTo, so NaN is greatest
+		x, y := util.FloatToSortableInt(a), util.FloatToSortableInt(b.(float32))
+		less, greater = x < y, x > y
+	case float64:
+		x, y := util.DoubleToSortableLong(a), util.DoubleToSortableLong(b.(float64))
+		less, greater = x < y, x > y
+	default:
+		panic(fmt.Sprintf("unsupported numeric type %T", a))
+	}
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+var (
+	longNegativeInfinity = util.DoubleToSortableLong(math.Inf(-1))
+	longPositiveInfinity = util.DoubleToSortableLong(math.Inf(1))
+	intNegativeInfinity  = util.FloatToSortableInt(float32(math.Inf(-1)))
+	intPositiveInfinity  = util.FloatToSortableInt(float32(math.Inf(1)))
+)
+
+/*
+Subclass of TermsEnum for enumerating all terms that match the
+sub-ranges for trie range queries, using the term dictionary.
+
+WARNING: This term enumeration is not guaranteed to be always ordered
+by Comparator(). The ordering depends on how SplitLongRange() and
+SplitIntRange() generates the sub-ranges. For MultiTermQuery ordering
+is not relevant.
+*/
+type numericRangeTermsEnum struct {
+	*filteredTermsEnum
+	currentLowerBound, currentUpperBound []byte
+	// pairs of prefix coded lower and upper bounds
+	rangeBounds [][]byte
+}
+
+func newNumericRangeTermsEnum(tenum TermsEnum, q *NumericRangeQuery) *numericRangeTermsEnum {
+	e := new(numericRangeTermsEnum)
+	e.filteredTermsEnum = newFilteredTermsEnum(e, tenum, true)
+	builder := func(minPrefixCoded, maxPrefixCoded []byte) {
+		e.rangeBounds = append(e.rangeBounds,
+			append([]byte(nil), minPrefixCoded...),
+			append([]byte(nil), maxPrefixCoded...))
+	}
+	switch q.dataType {
+	case document.FIELD_TYPE_NUMERIC_LONG, document.FIELD_TYPE_NUMERIC_DOUBLE:
+		// lower
+		var minBound int64
+		if q.dataType == document.FIELD_TYPE_NUMERIC_LONG {
+			minBound = math.MinInt64
+			if q.min != nil {
+				minBound = q.min.(int64)
+			}
+		} else {
+			minBound = longNegativeInfinity
+			if q.min != nil {
+				minBound = util.DoubleToSortableLong(q.min.(float64))
+			}
+		}
+		if !q.minInclusive && q.min != nil {
+			if minBound == math.MaxInt64 {
+				break
+			}
+			minBound++
+		}
+
+		// upper
+		var maxBound int64
+		if q.dataType == document.FIELD_TYPE_NUMERIC_LONG {
+			maxBound = math.MaxInt64
+			if q.max != nil {
+				maxBound = q.max.(int64)
+			}
+		} else {
+			maxBound = longPositiveInfinity
+			if q.max != nil {
+				maxBound = util.DoubleToSortableLong(q.max.(float64))
+			}
+		}
+		if !q.maxInclusive && q.max != nil {
+			if maxBound == math.MinInt64 {
+				break
+			}
+			maxBound--
+		}
+
+		util.SplitLongRange(builder, q.precisionStep, minBound, maxBound)
+
+	case document.FIELD_TYPE_NUMERIC_INT, document.FIELD_TYPE_NUMERIC_FLOAT:
+		// lower
+		var minBound int32
+		if q.dataType == document.FIELD_TYPE_NUMERIC_INT {
+			minBound = math.MinInt32
+			if q.min != nil {
+				minBound = q.min.(int32)
+			}
+		} else {
+			minBound = intNegativeInfinity
+			if q.min != nil {
+				minBound = util.FloatToSortableInt(q.min.(float32))
+			}
+		}
+		if !q.minInclusive && q.min != nil {
+			if minBound == math.MaxInt32 {
+				break
+			}
+			minBound++
+		}
+
+		// upper
+		var maxBound int32
+		if q.dataType == document.FIELD_TYPE_NUMERIC_INT {
+			maxBound = math.MaxInt32
+			if q.max != nil {
+				maxBound = q.max.(int32)
+			}
+		} else {
+			maxBound = intPositiveInfinity
+			if q.max != nil {
+				maxBound = util.FloatToSortableInt(q.max.(float32))
+			}
+		}
+		if !q.maxInclusive && q.max != nil {
+			if maxBound == math.MinInt32 {
+				break
+			}
+			maxBound--
+		}
+
+		util.SplitIntRange(builder, q.precisionStep, minBound, maxBound)
+
+	default:
+		// should never happen
+		panic("Invalid NumericType")
+	}
+	return e
+}
+
+func (e *numericRangeTermsEnum) nextRange() {
+	assert(len(e.rangeBounds)%2 == 0)
+	e.currentLowerBound, e.currentUpperBound = e.rangeBounds[0], e.rangeBounds[1]
+	e.rangeBounds = e.rangeBounds[2:]
+}
+
+/*
+Returns the term to seek to for the next sub-range, skipping those
+entirely before term, or nil if there are no more sub-ranges.
+*/
+func (e *numericRangeTermsEnum) nextSeekTerm(term []byte) []byte {
+	for len(e.rangeBounds) >= 2 {
+		e.nextRange()
+
+		// if the new upper bound is before the term parameter, the
+		// sub-range is never a hit
+		if term != nil && bytes.Compare(term, e.currentUpperBound) > 0 {
+			continue
+		}
+		// never seek backwards, so use current term if lower bound is
+		// smaller
+		if term != nil && bytes.Compare(term, e.currentLowerBound) > 0 {
+			return append([]byte(nil), term...)
+		}
+		return e.currentLowerBound
+	}
+
+	// no more sub-range enums available
+	assert(len(e.rangeBounds) == 0)
+	e.currentLowerBound, e.currentUpperBound = nil, nil
+	return nil
+}
+
+func (e *numericRangeTermsEnum) accept(term []byte) acceptStatus {
+	for e.currentUpperBound == nil || bytes.Compare(term, e.currentUpperBound) > 0 {
+		if len(e.rangeBounds) == 0 {
+			return ACCEPT_STATUS_END
+		}
+		// peek next sub-range, only seek if the current term is smaller
+		// than next lower bound
+		if bytes.Compare(term, e.rangeBounds[0]) < 0 {
+			return ACCEPT_STATUS_NO_AND_SEEK
+		}
+		// step forward to next range without seeking, as next lower
+		// range bound is less or equal current term
+		e.nextRange()
+	}
+	return ACCEPT_STATUS_YES
+}
@@ -0,0 +1,889 @@
+package search
+
+import (
+	"container/heap"
+	"fmt"
+	. "github.com/gzg1984/golucene/core/index/model"
+	. "github.com/gzg1984/golucene/core/search/model"
+	"math"
+	"sort"
+)
+
+// search/ExactPhraseScorer.java
+
+type phrasePostings struct {
+	postings DocsAndPositionsEnum
+	offset   int // relative position of the term within the phrase
+	freq     int // number of positions in the current doc
+	upTo     int // number of positions read so far
+	pos      int // current position
+}
+
+/*
+Scorer for PhraseQuery with zero slop: a document matches when all
+terms occur at exactly their relative positions.
+
+Documents are intersected by leap-frogging over the postings, rarest
+first; the positions of each candidate document are then intersected
+to count the phrase occurrences.
+*/
+type ExactPhraseScorer struct {
+	*abstractScorer
+	postings  []*phrasePostings
+	docScorer SimScorer
+	doc       int
+	freq      int
+}
+
+func newExactPhraseScorer(w Weight, postings []*postingsAndFreq,
+	docScorer SimScorer) *ExactPhraseScorer {
+
+	ans := &ExactPhraseScorer{
+		postings:  make([]*phrasePostings, len(postings)),
+		docScorer: docScorer,
+		doc:       -1,
+	}
+	for i, p := range postings {
+		ans.postings[i] = &phrasePostings{postings: p.postings, offset: p.position}
+	}
+	ans.abstractScorer = newScorer(ans, w)
+	return ans
+}
+
+func (s *ExactPhraseScorer) DocId() int {
+	return s.doc
+}
+
+func (s *ExactPhraseScorer) Freq() (int, error) {
+	return s.freq, nil
+}
+
+func (s *ExactPhraseScorer) Score() (float32, error) {
+	return s.docScorer.Score(s.doc, float32(s.freq)), nil
+}
+
+func (s *ExactPhraseScorer) NextDoc() (int, error) {
+	doc, err := s.postings[0].postings.NextDoc()
+	if err != nil {
+		return 0, err
+	}
+	return s.doNext(doc)
+}
+
+func (s *ExactPhraseScorer) Advance(target int) (int, error) {
+	doc, err := s.postings[0].postings.Advance(target)
+	if err != nil {
+		return 0, err
+	}
+	return s.doNext(doc)
+}
+
+/*
+Leap-frogs all postings to the lead's doc until every one of them is
+on the same document, and stops at the first such document that
+actually contains the phrase.
+*/
+func (s *ExactPhraseScorer) doNext(doc int) (int, error) {
+	lead := s.postings[0].postings
+	var err error
+
+advanceHead:
+	for doc != NO_MORE_DOCS {
+		for _, p := range s.postings[1:] {
+			if other := p.postings; other.DocId() < doc {
+				next, err := other.Advance(doc)
+				if err != nil {
+					return 0, err
+				}
+				if next > doc {
+					// iterator beyond the current doc - advance lead and continue to the new highest doc.
+					if doc, err = lead.Advance(next); err != nil {
+						return 0, err
+					}
+					continue advanceHead
+				}
+			}
+		}
+
+		// all postings are on the same doc: check positions
+		if s.freq, err = s.phraseFreq(); err != nil {
+			return 0, err
+		}
+		if s.freq > 0 {
+			s.doc = doc
+			return doc, nil
+		}
+		if doc, err = lead.NextDoc(); err != nil {
+			return 0, err
+		}
+	}
+	s.doc = NO_MORE_DOCS
+	s.freq = 0
+	return s.doc, nil
+}
+
+/*
+Advances the given postings up to the first position which is
+greater than or equal to target. Returns false if the positions of
+the current doc are exhausted.
+*/
+func advancePosition(p *phrasePostings, target int) (ok bool, err error) {
+	for p.pos < target {
+		if p.upTo == p.freq {
+			return false, nil
+		}
+		if p.pos, err = p.postings.NextPosition(); err != nil {
+			return false, err
+		}
+		p.upTo++
+	}
+	return true, nil
+}
+
+func (s *ExactPhraseScorer) phraseFreq() (freq int, err error) {
+	// reset state
+	for _, p := range s.postings {
+		if p.freq, err = p.postings.Freq(); err != nil {
+			return 0, err
+		}
+		if p.pos, err = p.postings.NextPosition(); err != nil {
+			return 0, err
+		}
+		p.upTo = 1
+	}
+
+	lead := s.postings[0]
+	var ok bool
+
+advanceHead:
+	for {
+		phrasePos := lead.pos - lead.offset
+		for _, p := range s.postings[1:] {
+			expectedPos := phrasePos + p.offset
+
+			// advance up to the same position as the lead
+			if ok, err = advancePosition(p, expectedPos); err != nil || !ok {
+				break advanceHead
+			}
+
+			if p.pos != expectedPos { // we advanced too far
+				if ok, err = advancePosition(lead, p.pos-p.offset+lead.offset); err != nil || !ok {
+					break advanceHead
+				}
+				continue advanceHead
+			}
+		}
+
+		freq++
+
+		if lead.upTo == lead.freq {
+			break
+		}
+		if lead.pos, err = lead.postings.NextPosition(); err != nil {
+			break
+		}
+		lead.upTo++
+	}
+	return freq, err
+}
+
+func (s *ExactPhraseScorer) String() string {
+	return fmt.Sprintf("ExactPhraseScorer(%v)", s.weight)
+}
+
+// search/PhrasePositions.java
+
+/*
+Position of a term in a document that takes into account the term
+offset within the phrase.
+*/
+type PhrasePositions struct {
+	doc      int                  // current doc
+	position int                  // position in doc
+	count    int                  // remaining pos in this doc
+	offset   int                  // position in phrase
+	ord      int                  // unique across all PhrasePositions instances
+	postings DocsAndPositionsEnum // stream of docs & positions
+	next     *PhrasePositions     // used to make lists
+	rptGroup int                  // >=0 indicates that this is a repeating PP
+	rptInd   int                  // index in the rptGroup
+	terms    []string             // for repetitions initialization
+}
+
+func newPhrasePositions(postings DocsAndPositionsEnum, o, ord int,
+	terms []string) *PhrasePositions {
+
+	return &PhrasePositions{
+		postings: postings,
+		offset:   o,
+		ord:      ord,
+		terms:    terms,
+		rptGroup: -1,
+	}
+}
+
+func (pp *PhrasePositions) skipTo(target int) (ok bool, err error) {
+	if pp.doc, err = pp.postings.Advance(target); err != nil {
+		return false, err
+	}
+	return pp.doc != NO_MORE_DOCS, nil
+}
+
+func (pp *PhrasePositions) firstPosition() (err error) {
+	if pp.count, err = pp.postings.Freq(); err != nil {
+		return err
+	}
+	_, err = pp.nextPosition() // read first pos
+	return err
+}
+
+/*
+Go to next location of this term current document, and set position
+as location - offset, so that a matching exact phrase is easily
+identified when all PhrasePositions have exactly the same position.
+*/
+func (pp *PhrasePositions) nextPosition() (bool, error) {
+	if pp.count <= 0 { // read subsequent pos's
+		return false, nil
+	}
+	pp.count--
+	pos, err := pp.postings.NextPosition()
+	if err != nil {
+		return false, err
+	}
+	pp.position = pos - pp.offset
+	return true, nil
+}
+
+func (pp *PhrasePositions) String() string {
+	s := fmt.Sprintf("d:%v o:%v p:%v c:%v", pp.doc, pp.offset, pp.position, pp.count)
+	if pp.rptGroup >= 0 {
+		s = fmt.Sprintf("%v rpt:%v,i%v", s, pp.rptGroup, pp.rptInd)
+	}
+	return s
+}
+
+// search/PhraseQueue.java
+
+type PhraseQueue struct {
+	*PriorityQueue
+}
+
+func newPhraseQueue(size int) *PhraseQueue {
+	pq := &PriorityQueue{items: make([]interface{}, 0, size)}
+	pq.less = func(i, j int) bool {
+		pp1 := pq.items[i].(*PhrasePositions)
+		pp2 := pq.items[j].(*PhrasePositions)
+		if pp1.position == pp2.position {
+			// same doc and pp.position, so decide by actual term positions.
+			// rely on: pp.position == tp.position - offset.
+			if pp1.offset == pp2.offset {
+				return pp1.ord < pp2.ord
+			}
+			return pp1.offset < pp2.offset
+		}
+		return pp1.position < pp2.position
+	}
+	return &PhraseQueue{pq}
+}
+
+func (q *PhraseQueue) add(pp *PhrasePositions) {
+	heap.Push(q.PriorityQueue, pp)
+}
+
+func (q *PhraseQueue) pop() *PhrasePositions {
+	return heap.Pop(q.PriorityQueue).(*PhrasePositions)
+}
+
+func (q *PhraseQueue) top() *PhrasePositions {
+	return q.items[0].(*PhrasePositions)
+}
+
+func (q *PhraseQueue) clear() {
+	q.items = q.items[:0]
+}
+
+// search/SloppyPhraseScorer.java
+
+/*
+Scorer for PhraseQuery with non-zero slop. Each matching document is
+scored by the sum of Similarity's slop factors of all the phrase
+occurrences found within the allowed edit distance.
+*/
+type SloppyPhraseScorer struct {
+	*abstractScorer
+	min, max *PhrasePositions
+
+	sloppyFreq float32 // phrase frequency in current doc as computed by phraseFreq()
+
+	docScorer SimScorer
+
+	slop        int
+	numPostings int
+	pq          *PhraseQueue // for advancing min position
+
+	end int // current largest phrase position
+
+	hasRpts          bool // flag indicating that there are repetitions (as checked in first candidate doc)
+	checkedRpts      bool // flag to only check for repetitions in first candidate doc
+	hasMultiTermRpts bool
+	rptGroups        [][]*PhrasePositions // in each group are PPs that repeats each other (i.e. same term), sorted by (query) offset
+	rptStack         []*PhrasePositions   // temporary stack for switching colliding PPs
+
+	numMatches int
+}
+
+func newSloppyPhraseScorer(w Weight, postings []*postingsAndFreq, slop int,
+	docScorer SimScorer) *SloppyPhraseScorer {
+
+	ans := &SloppyPhraseScorer{
+		docScorer:   docScorer,
+		slop:        slop,
+		numPostings: len(postings),
+		pq:          newPhraseQueue(len(postings)),
+	}
+	// min(cyclic list) and max(cyclic list) are initialized here
+	for i, p := range postings {
+		terms := make([]string, len(p.terms))
+		for k, t := range p.terms {
+			terms[k] = t.String()
+		}
+		pp := newPhrasePositions(p.postings, p.position, i, terms)
+		pp.doc = -1
+		if ans.min == nil {
+			ans.min = pp
+		} else {
+			ans.max.next = pp
+		}
+		ans.max = pp
+	}
+	ans.max.next = ans.min // make it cyclic for easier manipulation
+	ans.abstractScorer = newScorer(ans, w)
+	return ans
+}
+
+/*
+Score a candidate doc for all slop-valid position-combinations
+(matches) encountered while traversing/hopping the PhrasePositions.
+
+The score contribution of a match depends on the distance:
+- highest score for distance=0 (exact match).
+- score gets lower as distance gets higher.
+
+Example: for query "a b"~2, a document "x a b a y" can be scored
+twice: once for "a b" (distance=0), and once for "b a" (distance=2).
+
+Possibly not all valid combinations are encountered, because for
+efficiency we always propagate the least PhrasePosition. This allows
+to base on PriorityQueue and move forward faster. As result, for
+example, document "a b c b a" would score differently for queries
+"a b c"~4 and "c b a"~4, although they really are equivalent.
+Similarly, for doc "a b c b a f g", query "c b"~2 would get same
+score as "g f"~2, although "c b"~2 could be matched twice. We may
+want to fix this in the future (currently not, for performance
+reasons).
+*/
+func (s *SloppyPhraseScorer) phraseFreq() (float32, error) {
+	ok, err := s.initPhrasePositions()
+	if err != nil || !ok {
+		return 0, err
+	}
+	var freq float32
+	s.numMatches = 0
+	pp := s.pq.pop()
+	matchLength := s.end - pp.position
+	next := s.pq.top().position
+	for {
+		if ok, err = s.advancePP(pp); err != nil {
+			return 0, err
+		} else if !ok {
+			break
+		}
+		if s.hasRpts {
+			if ok, err = s.advanceRpts(pp); err != nil {
+				return 0, err
+			} else if !ok {
+				break // pps exhausted
+			}
+		}
+		if pp.position > next { // done minimizing current match-length
+			if matchLength <= s.slop {
+				freq += s.docScorer.computeSlopFactor(matchLength) // score match
+				s.numMatches++
+			}
+			s.pq.add(pp)
+			pp = s.pq.pop()
+			next = s.pq.top().position
+			matchLength = s.end - pp.position
+		} else if matchLength2 := s.end - pp.position; matchLength2 < matchLength {
+			matchLength = matchLength2
+		}
+	}
+	if matchLength <= s.slop {
+		freq += s.docScorer.computeSlopFactor(matchLength) // score match
+		s.numMatches++
+	}
+	return freq, nil
+}
+
+// advance a PhrasePosition and update 'end', return false if exhausted
+func (s *SloppyPhraseScorer) advancePP(pp *PhrasePositions) (bool, error) {
+	ok, err := pp.nextPosition()
+	if err != nil || !ok {
+		return false, err
+	}
+	if pp.position > s.end {
+		s.end = pp.position
+	}
+	return true, nil
+}
+
+/*
+pp was just advanced. If that caused a repeater collision, resolve
+by advancing the lesser of the two colliding pps. Note that there can
+only be one collision, as by the initialization there were no
+collisions before pp was advanced.
+*/
+func (s *SloppyPhraseScorer) advanceRpts(pp *PhrasePositions) (bool, error) {
+	if pp.rptGroup < 0 {
+		return true, nil // not a repeater
+	}
+	rg := s.rptGroups[pp.rptGroup]
+	requeue := make(map[int]bool) // for re-queuing after collisions are resolved
+	k0 := pp.rptInd
+	for k := s.collide(pp); k >= 0; k = s.collide(pp) {
+		pp = s.lesser(pp, rg[k]) // always advance the lesser of the (only) two colliding pps
+		if ok, err := s.advancePP(pp); err != nil || !ok {
+			return false, err // exhausted
+		}
+		if k != k0 { // careful: mark only those currently in the queue
+			requeue[k] = true // mark that pp2 need to be re-queued
+		}
+	}
+	// collisions resolved, now re-queue
+	// empty (partially) the queue until seeing all pps advanced for resolving collisions
+	n := 0
+	for len(requeue) > 0 {
+		pp2 := s.pq.pop()
+		s.rptStack[n] = pp2
+		n++
+		if pp2.rptGroup >= 0 {
+			delete(requeue, pp2.rptInd)
+		}
+	}
+	// add back to queue
+	for i := n - 1; i >= 0; i-- {
+		s.pq.add(s.rptStack[i])
+	}
+	return true, nil
+}
+
+// compare two pps, but only by position and offset
+func (s *SloppyPhraseScorer) lesser(pp, pp2 *PhrasePositions) *PhrasePositions {
+	if pp.position < pp2.position ||
+		(pp.position == pp2.position && pp.offset < pp2.offset) {
+		return pp
+	}
+	return pp2
+}
+
+// index of a pp2 colliding with pp, or -1 if none
+func (s *SloppyPhraseScorer) collide(pp *PhrasePositions) int {
+	tpPos := s.tpPos(pp)
+	for _, pp2 := range s.rptGroups[pp.rptGroup] {
+		if pp2 != pp && s.tpPos(pp2) == tpPos {
+			return pp2.rptInd
+		}
+	}
+	return -1
+}
+
+/*
+Initialize PhrasePositions in place. A one time initialization for
+this scorer (on first doc matching all terms):
+- Check if there are repetitions
+- If there are, find groups of repetitions.
+
+Examples:
+1. no repetitions: "ho my"~2
+2. repetitions: "ho my my"~2
+3. repetitions: "my ho my"~2
+
+Returns false if PPs are exhausted (and so current doc will not be a
+match).
+*/
+func (s *SloppyPhraseScorer) initPhrasePositions() (bool, error) {
+	s.end = math.MinInt32
+	if !s.checkedRpts {
+		return s.initFirstTime()
+	}
+	if !s.hasRpts {
+		return true, s.initSimple() // PPs available
+	}
+	return s.initComplex()
+}
+
+// no repeats: simplest case, and most common. It is important to keep this piece of the code simple and efficient
+func (s *SloppyPhraseScorer) initSimple() error {
+	s.pq.clear()
+	// position pps and build queue from list
+	for pp, prev := s.min, (*PhrasePositions)(nil); prev != s.max; pp, prev = pp.next, pp { // iterate cyclic list: done once handled max
+		if err := pp.firstPosition(); err != nil {
+			return err
+		}
+		if pp.position > s.end {
+			s.end = pp.position
+		}
+		s.pq.add(pp)
+	}
+	return nil
+}
+
+// with repeats: not so simple.
+func (s *SloppyPhraseScorer) initComplex() (bool, error) {
+	if err := s.placeFirstPositions(); err != nil {
+		return false, err
+	}
+	if ok, err := s.advanceRepeatGroups(); err != nil || !ok {
+		return false, err // PPs exhausted
+	}
+	s.fillQueue()
+	return true, nil // PPs available
+}
+
+// move all PPs to their first position
+func (s *SloppyPhraseScorer) placeFirstPositions() error {
+	for pp, prev := s.min, (*PhrasePositions)(nil); prev != s.max; pp, prev = pp.next, pp { // iterate cyclic list: done once handled max
+		if err := pp.firstPosition(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fill the queue (all pps are already placed)
+func (s *SloppyPhraseScorer) fillQueue() {
+	s.pq.clear()
+	for pp, prev := s.min, (*PhrasePositions)(nil); prev != s.max; pp, prev = pp.next, pp { // iterate cyclic list: done once handled max
+		if pp.position > s.end {
+			s.end = pp.position
+		}
+		s.pq.add(pp)
+	}
+}
+
+/*
+At initialization (each doc), each repetition group is sorted by
+(query) offset. This provides the start condition: no collisions.
+
+Case 1: no multi-term repeats
+It is sufficient to advance each pp in the group by one less than
+its group index. So lesser pp is not advanced, 2nd one advance once,
+3rd one advanced twice, etc.
+
+Case 2: multi-term repeats
+*/
+func (s *SloppyPhraseScorer) advanceRepeatGroups() (bool, error) {
+	for _, rg := range s.rptGroups {
+		if s.hasMultiTermRpts {
+			// more involved, some may not collide
+			for i, incr := 0, 1; i < len(rg); i += incr {
+				incr = 1
+				pp := rg[i]
+				for k := s.collide(pp); k >= 0; k = s.collide(pp) {
+					pp2 := s.lesser(pp, rg[k])
+					if ok, err := s.advancePP(pp2); err != nil || !ok { // at initialization always advance pp with higher offset
+						return false, err // exhausted
+					}
+					if pp2.rptInd < i { // should not happen?
+						incr = 0
+						break
+					}
+				}
+			}
+		} else {
+			// simpler, we know exactly how much to advance
+			for j := 1; j < len(rg); j++ {
+				for k := 0; k < j; k++ {
+					if ok, err := rg[j].nextPosition(); err != nil || !ok {
+						return false, err // PPs exhausted
+					}
+				}
+			}
+		}
+	}
+	return true, nil // PPs available
+}
+
+/*
+Initialize with checking for repeats. Heavy work, but done only for
+the first candidate doc.
+
+If there are repetitions, check if multi-term postings (MTP) are
+involved.
+
+Without MTP, once PPs are placed in the first candidate doc, repeats
+(and groups) are visible.
+
+With MTP, a more complex check is needed, up-front, as there may be
+"hidden collisions".
+
+For example P1 has {A,B}, P1 has {B,C}, and the first doc is:
+"A C B". At start, P1 would point to "A", p2 to "C", and it will not
+be identified that P1 and P2 are repetitions of each other.
+
+The more complex initialization has two parts:
+(1) identification of repetition groups.
+(2) advancing repeat groups at the start of the doc.
+
+For (1), a possible solution is by creating an 'early' term
+occurrence map, and grouping terms that occur together.
+*/
+func (s *SloppyPhraseScorer) initFirstTime() (bool, error) {
+	s.checkedRpts = true
+	if err := s.placeFirstPositions(); err != nil {
+		return false, err
+	}
+
+	rptTerms, tord := s.repeatingTerms()
+	s.hasRpts = len(rptTerms) > 0
+
+	if s.hasRpts {
+		s.rptStack = make([]*PhrasePositions, s.numPostings) // needed with repetitions
+		rgs := s.gatherRptGroups(rptTerms, tord)
+		s.sortRptGroups(rgs)
+		if ok, err := s.advanceRepeatGroups(); err != nil || !ok {
+			return false, err // PPs exhausted
+		}
+	}
+
+	s.fillQueue()
+	return true, nil // PPs available
+}
+
+type ppsByOffset []*PhrasePositions
+
+func (s ppsByOffset) Len() int           { return len(s) }
+func (s ppsByOffset) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s ppsByOffset) Less(i, j int) bool { return s[i].offset < s[j].offset }
+
+/*
+sort each repetition group by (query) offset. Done only once (at
+first doc) and allows to initialize faster for each doc.
+*/
+func (s *SloppyPhraseScorer) sortRptGroups(rgs [][]*PhrasePositions) {
+	s.rptGroups = rgs
+	for _, rg := range rgs {
+		sort.Stable(ppsByOffset(rg))
+		for j, pp := range rg {
+			pp.rptInd = j // we use this index for efficient re-queuing
+		}
+	}
+}
+
+// Detect repetition groups. Done once - for first doc
+func (s *SloppyPhraseScorer) gatherRptGroups(rptTerms map[string]int,
+	tord []string) [][]*PhrasePositions {
+
+	rpp := s.repeatingPPs(rptTerms)
+	var res [][]*PhrasePositions
+	if !s.hasMultiTermRpts {
+		// simpler - no multi-terms - can base on positions in first doc
+		for i, pp := range rpp {
+			if pp.rptGroup >= 0 {
+				continue // already marked as a repetition
+			}
+			tpPos := s.tpPos(pp)
+			for _, pp2 := range rpp[i+1:] {
+				if pp2.rptGroup >= 0 || // already marked as a repetition
+					pp2.offset == pp.offset || // not a repetition: two PPs are originally in same offset in the query!
+					s.tpPos(pp2) != tpPos { // not a repetition
+					continue
+				}
+				// a repetition
+				g := pp.rptGroup
+				if g < 0 {
+					g = len(res)
+					pp.rptGroup = g
+					res = append(res, []*PhrasePositions{pp})
+				}
+				pp2.rptGroup = g
+				res[g] = append(res[g], pp2)
+			}
+		}
+	} else {
+		// more involved - has multi-terms
+		bb := s.ppTermsBitSets(rpp, rptTerms)
+		bb = s.unionTermGroups(bb)
+		tg := s.termGroups(tord, bb)
+		res = make([][]*PhrasePositions, len(bb))
+		for _, pp := range rpp {
+			for _, t := range pp.terms {
+				if _, ok := rptTerms[t]; ok {
+					g := tg[t]
+					if pp.rptGroup != g {
+						assert(pp.rptGroup == -1)
+						res[g] = append(res[g], pp)
+					}
+					pp.rptGroup = g
+				}
+			}
+		}
+	}
+	return res
+}
+
+// Actual position in doc of a PhrasePosition, relies on that position = tpPos - offset)
+func (s *SloppyPhraseScorer) tpPos(pp *PhrasePositions) int {
+	return pp.position + pp.offset
+}
+
+// find repeating terms and assign them ordinal values
+func (s *SloppyPhraseScorer) repeatingTerms() (map[string]int, []string) {
+	tord := make(map[string]int)
+	var terms []string
+	tcnt := make(map[string]int)
+	for pp, prev := s.min, (*PhrasePositions)(nil); prev != s.max; pp, prev = pp.next, pp { // iterate cyclic list: done once handled max
+		for _, t := range pp.terms {
+			tcnt[t]++
+			if tcnt[t] == 2 {
+				tord[t] = len(terms)
+				terms = append(terms, t)
+			}
+		}
+	}
+	return tord, terms
+}
+
+// find repeating pps, and for each, if has multi-terms, update this.hasMultiTermRpts
+func (s *SloppyPhraseScorer) repeatingPPs(rptTerms map[string]int) []*PhrasePositions {
+	var rp []*PhrasePositions
+	for pp, prev := s.min, (*PhrasePositions)(nil); prev != s.max; pp, prev = pp.next, pp { // iterate cyclic list: done once handled max
+		for _, t := range pp.terms {
+			if _, ok := rptTerms[t]; ok {
+				rp = append(rp, pp)
+				s.hasMultiTermRpts = s.hasMultiTermRpts || len(pp.terms) > 1
+				break
+			}
+		}
+	}
+	return rp
+}
+
+// bit-sets - for each repeating pp, for each of its repeating terms, the term ordinal values is set
+func (s *SloppyPhraseScorer) ppTermsBitSets(rpp []*PhrasePositions,
+	tord map[string]int) [][]bool {
+
+	bb := make([][]bool, len(rpp))
+	for i, pp := range rpp {
+		b := make([]bool, len(tord))
+		for _, t := range pp.terms {
+			if ord, ok := tord[t]; ok {
+				b[ord] = true
+			}
+		}
+		bb[i] = b
+	}
+	return bb
+}
+
+// union (term group) bit-sets until they are disjoint (O(n^^2)), and each group have different terms
+func (s *SloppyPhraseScorer) unionTermGroups(bb [][]bool) [][]bool {
+	for i, incr := 0, 1; i < len(bb)-1; i += incr {
+		incr = 1
+		for j := i + 1; j < len(bb); {
+			if intersects(bb[i], bb[j]) {
+				for k, v := range bb[j] {
+					bb[i][k] = bb[i][k] || v
+				}
+				bb = append(bb[:j], bb[j+1:]...)
+				incr = 0
+			} else {
+				j++
+			}
+		}
+	}
+	return bb
+}
+
+func intersects(a, b []bool) bool {
+	for k, v := range a {
+		if v && b[k] {
+			return true
+		}
+	}
+	return false
+}
+
+// map each term to the single group that contains it
+func (s *SloppyPhraseScorer) termGroups(tord []string, bb [][]bool) map[string]int {
+	tg := make(map[string]int)
+	for i, b := range bb { // i is the group no.
+		for ord, v := range b {
+			if v {
+				tg[tord[ord]] = i
+			}
+		}
+	}
+	return tg
+}
+
+func (s *SloppyPhraseScorer) Freq() (int, error) {
+	return s.numMatches, nil
+}
+
+func (s *SloppyPhraseScorer) DocId() int {
+	return s.max.doc
+}
+
+func (s *SloppyPhraseScorer) NextDoc() (int, error) {
+	return s.Advance(s.max.doc + 1) // advance to the next doc after DocId()
+}
+
+func (s *SloppyPhraseScorer) Score() (float32, error) {
+	return s.docScorer.Score(s.max.doc, s.sloppyFreq), nil
+}
+
+func (s *SloppyPhraseScorer) Advance(target int) (int, error) {
+	assert(target > s.DocId())
+	for {
+		if ok, err := s.advanceMin(target); err != nil || !ok {
+			return NO_MORE_DOCS, err
+		}
+		for s.min.doc < s.max.doc {
+			if ok, err := s.advanceMin(s.max.doc); err != nil || !ok {
+				return NO_MORE_DOCS, err
+			}
+		}
+		// found a doc with all of the terms
+		var err error
+		if s.sloppyFreq, err = s.phraseFreq(); err != nil { // check for phrase
+			return 0, err
+		}
+		if s.sloppyFreq != 0 {
+			break
+		}
+		target = s.min.doc + 1 // next target in case sloppyFreq is still 0
+	}
+
+	// found a match
+	return s.max.doc, nil
+}
+
+func (s *SloppyPhraseScorer) advanceMin(target int) (bool, error) {
+	ok, err := s.min.skipTo(target)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		s.max.doc = NO_MORE_DOCS // for further calls to DocId()
+		return false, nil
+	}
+	s.min = s.min.next // cyclic
+	s.max = s.max.next // cyclic
+	return true, nil
+}
+
+func (s *SloppyPhraseScorer) String() string {
+	return fmt.Sprintf("scorer(%v)", s.weight)
+}
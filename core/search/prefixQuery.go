@@ -0,0 +1,50 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	"github.com/gzg1984/golucene/core/util/automaton"
+)
+
+// search/PrefixQuery.java
+
+/*
+A Query that matches documents containing terms with a specified
+prefix. A PrefixQuery is built by QueryParser for input like app*.
+*/
+type PrefixQuery struct {
+	*AutomatonQuery
+}
+
+// Constructs a query for terms starting with prefix.
+func NewPrefixQuery(prefix *index.Term) *PrefixQuery {
+	ans := &PrefixQuery{}
+	ans.AutomatonQuery = newAutomatonQuery(ans, prefix, ToPrefixAutomaton(prefix.Bytes), true)
+	return ans
+}
+
+// Build an automaton accepting all terms with the specified prefix.
+func ToPrefixAutomaton(prefix []byte) *automaton.Automaton {
+	return automaton.Concatenate(automaton.MakeBinary(prefix), automaton.MakeAnyBinary())
+}
+
+// Returns the prefix of this query.
+func (q *PrefixQuery) Prefix() *index.Term {
+	return q.term
+}
+
+// Prints a user-readable version of this query.
+func (q *PrefixQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	if q.Field() != field {
+		buf.WriteString(q.Field())
+		buf.WriteRune(':')
+	}
+	buf.WriteString(string(q.term.Bytes))
+	buf.WriteRune('*')
+	if q.boost != 1.0 {
+		buf.WriteString(fmt.Sprintf("^%v", q.boost))
+	}
+	return buf.String()
+}
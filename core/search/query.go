@@ -15,7 +15,12 @@ type Query interface {
 	Boost() float32
 	QuerySPI
 	CreateWeight(ss *IndexSearcher) (w Weight, err error)
-	Rewrite(r index.IndexReader) Query
+	/*
+		Expert: called to re-write queries into primitive queries. For
+		example, a PrefixQuery will be rewritten into a BooleanQuery that
+		consists of TermQuerys.
+	*/
+	Rewrite(r index.IndexReader) (Query, error)
 }
 
 type QuerySPI interface {
@@ -45,6 +50,50 @@ func (q *AbstractQuery) CreateWeight(ss *IndexSearcher) (w Weight, err error) {
 	panic(fmt.Sprintf("Query %v does not implement createWeight", q))
 }
 
-func (q *AbstractQuery) Rewrite(r index.IndexReader) Query {
-	return q.value
+func (q *AbstractQuery) Rewrite(r index.IndexReader) (Query, error) {
+	return q.value, nil
+}
+
+/*
+Returns a shallow copy of q, as Query.clone() does in Java, or nil if
+the query type does not support copying. The copy can be re-boosted
+without changing a query that the caller still owns.
+*/
+func cloneQuery(q Query) Query {
+	switch q := q.(type) {
+	case *TermQuery:
+		ans := NewTermQueryWithDocFreq(q.term, q.docFreq)
+		ans.perReaderTermState = q.perReaderTermState
+		ans.boost = q.boost
+		return ans
+	case *BooleanQuery:
+		return q.clone()
+	case *PhraseQuery:
+		ans := NewPhraseQuery()
+		ans.field = q.field
+		ans.terms = append([]*index.Term(nil), q.terms...)
+		ans.positions = append([]int(nil), q.positions...)
+		ans.maxPosition = q.maxPosition
+		ans.slop = q.slop
+		ans.boost = q.boost
+		return ans
+	case *MultiPhraseQuery:
+		ans := NewMultiPhraseQuery()
+		ans.field = q.field
+		ans.termArrays = append([][]*index.Term(nil), q.termArrays...)
+		ans.positions = append([]int(nil), q.positions...)
+		ans.slop = q.slop
+		ans.boost = q.boost
+		return ans
+	case *ConstantScoreQuery:
+		ans := &ConstantScoreQuery{filter: q.filter, query: q.query}
+		ans.AbstractQuery = NewAbstractQuery(ans)
+		ans.boost = q.boost
+		return ans
+	case *MatchAllDocsQuery:
+		ans := NewMatchAllDocsQuery()
+		ans.boost = q.boost
+		return ans
+	}
+	return nil
 }
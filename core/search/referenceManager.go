@@ -0,0 +1,278 @@
+package search
+
+import (
+	"errors"
+	"sync"
+)
+
+// search/ReferenceManager.java
+
+var errReferenceManagerClosed = errors.New("this ReferenceManager is closed")
+
+/*
+Use to receive notification when a refresh has finished. See
+ReferenceManager.AddListener().
+*/
+type RefreshListener interface {
+	// Called right before a refresh attempt starts.
+	BeforeRefresh() error
+	// Called after the attempted refresh; if the refresh did open a
+	// new reference then didRefresh will be true and Acquire() is
+	// guaranteed to return the new reference.
+	AfterRefresh(didRefresh bool) error
+}
+
+/* Reference specific operations a ReferenceManager relies on. */
+type ReferenceManagerSPI interface {
+	// Decrement reference counting on the given reference.
+	decRef(ref interface{}) error
+	// Refresh the given reference if needed. Returns nil if no refresh
+	// was needed, otherwise a new refreshed reference.
+	refreshIfNeeded(referenceToRefresh interface{}) (interface{}, error)
+	// Try to increment reference counting on the given reference.
+	// Return true if the operation was successful.
+	tryIncRef(ref interface{}) bool
+	// Returns the current reference count of the given reference.
+	refCount(ref interface{}) int
+}
+
+/*
+Utility class to safely share instances of a certain type across
+multiple goroutines, while periodically refreshing them. This class
+ensures each reference is closed only once all goroutines have
+finished using it. It is recommended to consult the documentation of
+ReferenceManager implementations for their MaybeRefresh() semantics.
+*/
+type ReferenceManager struct {
+	spi ReferenceManagerSPI
+
+	sync.Mutex  // synchronized
+	current     interface{}
+	refreshLock sync.Mutex
+
+	listenersLock    sync.Mutex
+	refreshListeners []RefreshListener
+}
+
+func newReferenceManager(spi ReferenceManagerSPI, current interface{}) *ReferenceManager {
+	return &ReferenceManager{spi: spi, current: current}
+}
+
+func (m *ReferenceManager) ensureOpen() error {
+	if m.current == nil {
+		return errReferenceManagerClosed
+	}
+	return nil
+}
+
+func (m *ReferenceManager) swapReference(newReference interface{}) error {
+	m.Lock() // synchronized
+	defer m.Unlock()
+	if err := m.ensureOpen(); err != nil {
+		return err
+	}
+	return m._swapReference(newReference)
+}
+
+func (m *ReferenceManager) _swapReference(newReference interface{}) error {
+	oldReference := m.current
+	m.current = newReference
+	return m.release(oldReference)
+}
+
+/*
+Obtain the current reference. You must match every call to acquire
+with one call to release; it's best to do so in a defer statement,
+and it's recommended to assign nil to the reference afterwards, so
+that you don't accidentally keep using it.
+*/
+func (m *ReferenceManager) acquire() (interface{}, error) {
+	for {
+		m.Lock()
+		ref := m.current
+		m.Unlock()
+		if ref == nil {
+			return nil, errReferenceManagerClosed
+		}
+		if m.spi.tryIncRef(ref) {
+			return ref, nil
+		}
+		if m.spi.refCount(ref) == 0 {
+			m.Lock()
+			closed := m.current == ref
+			m.Unlock()
+			// the reference has been closed but is still the current
+			// one; nothing will ever swap it out
+			assert2(!closed, "The managed reference has already closed - this is likely a bug when the reference count is modified outside of the ReferenceManager")
+		}
+	}
+}
+
+/*
+Closes this ReferenceManager to prevent future acquiring. A reference
+manager should be closed if the reference to the managed resource
+should be disposed or the application using the ReferenceManager is
+shutting down. The managed resource might not be released
+immediately, if the ReferenceManager user is holding on to a
+previously acquired reference. The resource will be released once
+when the last reference is released.
+
+NOTE: If the underlying reference holds resources, they may be left
+open even after the reference manager is closed.
+*/
+func (m *ReferenceManager) Close() error {
+	m.Lock() // synchronized
+	defer m.Unlock()
+	if m.current != nil {
+		// make sure we can call this more than once
+		// closeable javadoc says:
+		//   if this is already closed then invoking this method has no effect.
+		return m._swapReference(nil)
+	}
+	return nil
+}
+
+func (m *ReferenceManager) doMaybeRefresh() (err error) {
+	reference, err := m.acquire()
+	if err != nil {
+		return err
+	}
+	refreshed := false
+	defer func() {
+		if err2 := m.release(reference); err == nil {
+			err = err2
+		}
+		if err2 := m.notifyRefreshListenersRefreshed(refreshed); err == nil {
+			err = err2
+		}
+	}()
+
+	if err = m.notifyRefreshListenersBefore(); err != nil {
+		return err
+	}
+	newReference, err := m.spi.refreshIfNeeded(reference)
+	if err != nil || newReference == nil {
+		return err
+	}
+	assert2(newReference != reference, "refreshIfNeeded should return nil if refresh wasn't needed")
+	if err = m.swapReference(newReference); err != nil {
+		m.release(newReference) // keep the original error
+		return err
+	}
+	refreshed = true
+	return nil
+}
+
+/*
+You must call this (or MaybeRefreshBlocking()), periodically, if you
+want that Acquire() will return refreshed instances.
+
+Goroutine-safe: it's fine for more than one goroutine to call this at
+once. Only the first goroutine will attempt the refresh; subsequent
+goroutines will see that another goroutine is already handling
+refresh and will return immediately. Note that this means if another
+goroutine is already refreshing then subsequent goroutines will
+return right away without waiting for the refresh to complete.
+
+If this method returns true it means the calling goroutine either
+refreshed or that there were no changes to refresh. If it returns
+false it means another goroutine is currently refreshing.
+*/
+func (m *ReferenceManager) MaybeRefresh() (bool, error) {
+	m.Lock()
+	err := m.ensureOpen()
+	m.Unlock()
+	if err != nil {
+		return false, err
+	}
+
+	// Ensure only 1 goroutine does refresh at once; other goroutines
+	// just return immediately:
+	if !m.refreshLock.TryLock() {
+		return false, nil
+	}
+	defer m.refreshLock.Unlock()
+	return true, m.doMaybeRefresh()
+}
+
+/*
+You must call this (or MaybeRefresh()), periodically, if you want
+that Acquire() will return refreshed instances.
+
+Goroutine-safe: multiple goroutines can call this method at once.
+This method will return when the refresh has completed; if another
+goroutine is already refreshing, the calling goroutine blocks until
+that refresh completes and then runs its own refresh.
+*/
+func (m *ReferenceManager) MaybeRefreshBlocking() error {
+	m.Lock()
+	err := m.ensureOpen()
+	m.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Ensure only 1 goroutine does refresh at once
+	m.refreshLock.Lock()
+	defer m.refreshLock.Unlock()
+	return m.doMaybeRefresh()
+}
+
+/*
+Release the reference previously obtained via acquire().
+
+NOTE: it's safe to call this after Close().
+*/
+func (m *ReferenceManager) release(reference interface{}) error {
+	assert(reference != nil)
+	return m.spi.decRef(reference)
+}
+
+func (m *ReferenceManager) notifyRefreshListenersBefore() error {
+	for _, listener := range m.listeners() {
+		if err := listener.BeforeRefresh(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *ReferenceManager) notifyRefreshListenersRefreshed(didRefresh bool) error {
+	for _, listener := range m.listeners() {
+		if err := listener.AfterRefresh(didRefresh); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *ReferenceManager) listeners() []RefreshListener {
+	m.listenersLock.Lock()
+	defer m.listenersLock.Unlock()
+	return m.refreshListeners
+}
+
+/* Adds a listener, to be notified when a reference is refreshed/swapped. */
+func (m *ReferenceManager) AddListener(listener RefreshListener) {
+	assert2(listener != nil, "Listener cannot be nil")
+	m.listenersLock.Lock()
+	defer m.listenersLock.Unlock()
+	// copy on write, so that notifications in flight are not affected
+	listeners := make([]RefreshListener, len(m.refreshListeners), len(m.refreshListeners)+1)
+	copy(listeners, m.refreshListeners)
+	m.refreshListeners = append(listeners, listener)
+}
+
+/* Remove a listener added with AddListener(). */
+func (m *ReferenceManager) RemoveListener(listener RefreshListener) {
+	assert2(listener != nil, "Listener cannot be nil")
+	m.listenersLock.Lock()
+	defer m.listenersLock.Unlock()
+	listeners := make([]RefreshListener, 0, len(m.refreshListeners))
+	for _, l := range m.refreshListeners {
+		if l != listener {
+			listeners = append(listeners, l)
+		}
+	}
+	m.refreshListeners = listeners
+}
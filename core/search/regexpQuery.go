@@ -0,0 +1,59 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	"github.com/gzg1984/golucene/core/util/automaton"
+)
+
+// search/RegexpQuery.java
+
+/*
+A fast regular expression query based on the automaton package.
+Comparisons are fast, and the term dictionary is enumerated in an
+intelligent way to avoid comparisons. See AutomatonQuery for more
+details.
+
+The supported syntax is documented in the automaton.RegExp type.
+Note this might be different than other regular expression
+implementations.
+
+Note this query can be slow, as it needs to iterate over many terms.
+In order to prevent extremely slow RegexpQueries, a Regexp term
+should not start with the expression .*
+*/
+type RegexpQuery struct {
+	*AutomatonQuery
+}
+
+// Constructs a query for terms matching term, with all optional
+// regexp syntax enabled.
+func NewRegexpQuery(term *index.Term) *RegexpQuery {
+	return NewRegexpQueryWithFlags(term, automaton.ALL)
+}
+
+// Constructs a query for terms matching term, with the given optional
+// regexp syntax flags.
+func NewRegexpQueryWithFlags(term *index.Term, flags int) *RegexpQuery {
+	ans := &RegexpQuery{}
+	a := automaton.NewRegExpWithFlag(string(term.Bytes), flags).ToAutomaton()
+	ans.AutomatonQuery = newAutomatonQuery(ans, term, a, false)
+	return ans
+}
+
+// Prints a user-readable version of this query.
+func (q *RegexpQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	if q.Field() != field {
+		buf.WriteString(q.Field())
+		buf.WriteRune(':')
+	}
+	buf.WriteRune('/')
+	buf.WriteString(string(q.term.Bytes))
+	buf.WriteRune('/')
+	if q.boost != 1.0 {
+		buf.WriteString(fmt.Sprintf("^%v", q.boost))
+	}
+	return buf.String()
+}
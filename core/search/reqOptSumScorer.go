@@ -0,0 +1,98 @@
+package search
+
+import (
+	. "github.com/gzg1984/golucene/core/search/model"
+)
+
+// search/ReqOptSumScorer.java
+
+/*
+A Scorer for queries with a required part and an optional part.
+Delays Advance() on the optional part until a Score() is needed.
+
+This Scorer implements Scorer.Advance().
+*/
+type ReqOptSumScorer struct {
+	*abstractScorer
+	// The scorers passed from the constructor.
+	// These are set to nil as soon as their Next() or Advance() returns
+	// false.
+	reqScorer Scorer
+	optScorer Scorer
+}
+
+/*
+Construct a ReqOptScorer.
+reqScorer: The required scorer. This must match.
+optScorer: The optional scorer. This is used for scoring only.
+*/
+func newReqOptSumScorer(reqScorer, optScorer Scorer) *ReqOptSumScorer {
+	assert(reqScorer != nil)
+	assert(optScorer != nil)
+	ans := &ReqOptSumScorer{
+		reqScorer: reqScorer,
+		optScorer: optScorer,
+	}
+	ans.abstractScorer = newScorer(ans, nil)
+	return ans
+}
+
+func (s *ReqOptSumScorer) NextDoc() (int, error) {
+	return s.reqScorer.NextDoc()
+}
+
+func (s *ReqOptSumScorer) Advance(target int) (int, error) {
+	return s.reqScorer.Advance(target)
+}
+
+func (s *ReqOptSumScorer) DocId() int {
+	return s.reqScorer.DocId()
+}
+
+/*
+Returns the score of the current document matching the query.
+Initially invalid, until NextDoc() is called the first time.
+*/
+func (s *ReqOptSumScorer) Score() (float32, error) {
+	// TODO: sum into a double and cast to float if we ever send
+	// required clauses to BS1
+	curDoc := s.reqScorer.DocId()
+	reqScore, err := s.reqScorer.Score()
+	if err != nil {
+		return 0, err
+	}
+	if s.optScorer == nil {
+		return reqScore, nil
+	}
+
+	optScorerDoc := s.optScorer.DocId()
+	if optScorerDoc < curDoc {
+		if optScorerDoc, err = s.optScorer.Advance(curDoc); err != nil {
+			return 0, err
+		}
+		if optScorerDoc == NO_MORE_DOCS {
+			s.optScorer = nil
+			return reqScore, nil
+		}
+	}
+
+	if optScorerDoc == curDoc {
+		optScore, err := s.optScorer.Score()
+		if err != nil {
+			return 0, err
+		}
+		return reqScore + optScore, nil
+	}
+	return reqScore, nil
+}
+
+func (s *ReqOptSumScorer) Freq() (int, error) {
+	// we might have deferred advance()
+	if _, err := s.Score(); err != nil {
+		return 0, err
+	}
+	if s.optScorer != nil && s.optScorer.DocId() == s.reqScorer.DocId() {
+		return 2, nil
+	}
+	return 1, nil
+}
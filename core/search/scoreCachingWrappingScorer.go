@@ -0,0 +1,38 @@
+package search
+
+// search/ScoreCachingWrappingScorer.java
+
+/*
+A Scorer which wraps another scorer and caches the score of the
+current document. Successive calls to Score() will return the same
+result and will not invoke the wrapped Scorer's Score() method,
+unless the current document has changed.
+
+This is useful for collectors which need the score of a document in
+several places, while all they have in hand is a Scorer, and might
+otherwise end up computing the score of a document more than once.
+*/
+type ScoreCachingWrappingScorer struct {
+	Scorer
+	curDoc   int
+	curScore float32
+}
+
+// Creates a new instance by wrapping the given scorer.
+func NewScoreCachingWrappingScorer(scorer Scorer) *ScoreCachingWrappingScorer {
+	return &ScoreCachingWrappingScorer{
+		Scorer: scorer,
+		curDoc: -1,
+	}
+}
+
+func (s *ScoreCachingWrappingScorer) Score() (float32, error) {
+	if doc := s.Scorer.DocId(); doc != s.curDoc {
+		score, err := s.Scorer.Score()
+		if err != nil {
+			return 0, err
+		}
+		s.curScore, s.curDoc = score, doc
+	}
+	return s.curScore, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/gzg1984/golucene/core/util"
 	"log"
 	"math"
+	"sync"
 )
 
 /* Define service that can be overrided */
@@ -24,6 +25,11 @@ type IndexSearcher struct {
 	readerContext index.IndexReaderContext
 	leafContexts  []*index.AtomicReaderContext
 	similarity    Similarity
+
+	// used with concurrent search - each slice holds a set of leafs
+	// searched by a single goroutine
+	leafSlices []*LeafSlice
+	workers    int
 }
 
 func NewIndexSearcher(r index.IndexReader) *IndexSearcher {
@@ -34,11 +40,79 @@ func NewIndexSearcher(r index.IndexReader) *IndexSearcher {
 func NewIndexSearcherFromContext(context index.IndexReaderContext) *IndexSearcher {
 	// assert2(context.isTopLevel, "IndexSearcher's ReaderContext must be topLevel for reader %v", context.reader())
 	defaultSimilarity := NewDefaultSimilarity()
-	ss := &IndexSearcher{nil, context.Reader(), context, context.Leaves(), defaultSimilarity}
+	ss := &IndexSearcher{
+		reader:        context.Reader(),
+		readerContext: context,
+		leafContexts:  context.Leaves(),
+		similarity:    defaultSimilarity,
+	}
 	ss.spi = ss
+	ss.leafSlices = ss.slices(ss.leafContexts, DEFAULT_MAX_DOCS_PER_SLICE)
 	return ss
 }
 
+const (
+	// Default maximum number of documents searched by one goroutine.
+	DEFAULT_MAX_DOCS_PER_SLICE = 250000
+	// Maximum number of segments searched by one goroutine.
+	MAX_SEGMENTS_PER_SLICE = 5
+)
+
+/*
+A class holding a subset of the IndexSearcher's leaf contexts to be
+searched by a single goroutine.
+*/
+type LeafSlice struct {
+	Leaves []*index.AtomicReaderContext
+}
+
+/*
+Expert: searches leaf slices in parallel, using at most workers
+goroutines per search. Leaves are grouped into slices of consecutive
+segments, up to maxDocsPerSlice documents (or MAX_SEGMENTS_PER_SLICE
+segments) each; a segment larger than maxDocsPerSlice gets a slice of
+its own. A non-positive maxDocsPerSlice means
+DEFAULT_MAX_DOCS_PER_SLICE. workers <= 1 searches all leaves in the
+calling goroutine, which is the default.
+
+Each slice is collected by its own Collector, as created by a
+CollectorManager, and the results are merged once all slices are
+done. Hits are returned in the same order as with a sequential
+search.
+*/
+func (ss *IndexSearcher) SetConcurrency(workers, maxDocsPerSlice int) {
+	if maxDocsPerSlice <= 0 {
+		maxDocsPerSlice = DEFAULT_MAX_DOCS_PER_SLICE
+	}
+	ss.workers = workers
+	ss.leafSlices = ss.slices(ss.leafContexts, maxDocsPerSlice)
+}
+
+/*
+Expert: Creates an array of leaf slices each holding a subset of the
+given leaves. Each LeafSlice is executed in a single goroutine.
+Slices keep the leaves in order, so that merging their hits breaks
+ties by doc ID, just like a sequential search.
+*/
+func (ss *IndexSearcher) slices(leaves []*index.AtomicReaderContext, maxDocsPerSlice int) []*LeafSlice {
+	var slices []*LeafSlice
+	var group []*index.AtomicReaderContext
+	docSum := 0
+	for _, ctx := range leaves {
+		maxDoc := ctx.Reader().MaxDoc()
+		if len(group) > 0 && (docSum+maxDoc > maxDocsPerSlice || len(group) >= MAX_SEGMENTS_PER_SLICE) {
+			slices = append(slices, &LeafSlice{group})
+			group, docSum = nil, 0
+		}
+		group = append(group, ctx)
+		docSum += maxDoc
+	}
+	if len(group) > 0 {
+		slices = append(slices, &LeafSlice{group})
+	}
+	return slices
+}
+
 /* Expert: set the similarity implementation used by this IndexSearcher. */
 func (ss *IndexSearcher) SetSimilarity(similarity Similarity) {
 	ss.similarity = similarity
@@ -53,52 +127,242 @@ func (ss *IndexSearcher) Search(q Query, f Filter, n int) (topDocs TopDocs, err
 	if err != nil {
 		return TopDocs{}, err
 	}
-	return ss.searchWSI(w, nil, n), nil
+	return ss.searchWSI(w, nil, n)
 }
 
-/** Expert: Low-level search implementation.  Finds the top <code>n</code>
- * hits for <code>query</code>, applying <code>filter</code> if non-null.
- *
- * <p>Applications should usually call {@link IndexSearcher#search(Query,int)} or
- * {@link IndexSearcher#search(Query,Filter,int)} instead.
- * @throws BooleanQuery.TooManyClauses If a query would exceed
- *         {@link BooleanQuery#getMaxClauseCount()} clauses.
- */
-func (ss *IndexSearcher) searchWSI(w Weight, after *ScoreDoc, nDocs int) TopDocs {
-	// TODO support concurrent search
-	return ss.searchLWSI(ss.leafContexts, w, after, nDocs)
+/*
+Finds the top n hits for query, applying filter if non-nil, where all
+results are after a previous result (after).
+
+By passing the bottom result from a previous page as after, this
+method can be used for efficient 'deep-paging' across potentially
+large result sets.
+*/
+func (ss *IndexSearcher) SearchAfter(after *ScoreDoc, q Query, f Filter, n int) (TopDocs, error) {
+	w, err := ss.spi.CreateNormalizedWeight(ss.spi.WrapFilter(q, f))
+	if err != nil {
+		return TopDocs{}, err
+	}
+	return ss.searchWSI(w, after, n)
+}
+
+/*
+Lower-level search API. Search all leaves using the given
+CollectorManager, applying filter if non-nil. In contrast to
+SearchLWC(), this method will use the searcher's concurrency setting
+in order to parallelize execution of the collection on the configured
+leaf slices.
+
+Returns the result of CollectorManager.Reduce().
+*/
+func (ss *IndexSearcher) SearchWithCollectorManager(q Query, f Filter,
+	manager CollectorManager) (interface{}, error) {
+
+	w, err := ss.spi.CreateNormalizedWeight(ss.spi.WrapFilter(q, f))
+	if err != nil {
+		return nil, err
+	}
+	return ss.searchCM(w, manager)
+}
+
+func (ss *IndexSearcher) searchCM(w Weight, manager CollectorManager) (interface{}, error) {
+	if ss.workers <= 1 || len(ss.leafSlices) <= 1 {
+		// use all leaves in the calling goroutine
+		collector, err := manager.NewCollector()
+		if err != nil {
+			return nil, err
+		}
+		if err = ss.spi.SearchLWC(ss.leafContexts, w, collector); err != nil {
+			return nil, err
+		}
+		return manager.Reduce([]Collector{collector})
+	}
+
+	collectors := make([]Collector, len(ss.leafSlices))
+	for i := range collectors {
+		var err error
+		if collectors[i], err = manager.NewCollector(); err != nil {
+			return nil, err
+		}
+	}
+	errs := make([]error, len(ss.leafSlices))
+	panics := make([]interface{}, len(ss.leafSlices))
+	workers := make(chan bool, ss.workers)
+	var wg sync.WaitGroup
+	for i, slice := range ss.leafSlices {
+		workers <- true
+		wg.Add(1)
+		go func(i int, leaves []*index.AtomicReaderContext) {
+			defer func() {
+				panics[i] = recover()
+				<-workers
+				wg.Done()
+			}()
+			errs[i] = ss.spi.SearchLWC(leaves, w, collectors[i])
+		}(i, slice.Leaves)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if panics[i] != nil {
+			// re-panic in the searching goroutine
+			panic(panics[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return manager.Reduce(collectors)
+}
+
+/*
+Search implementation with arbitrary sorting. Finds the top n hits
+for query, applying filter if non-nil, and sorting the hits by the
+criteria in sort. Document scores and the max score are not computed;
+use SearchSortedScores() if they are needed.
+*/
+func (ss *IndexSearcher) SearchSorted(q Query, f Filter, n int, sort *Sort) (TopFieldDocs, error) {
+	return ss.SearchSortedScores(q, f, n, sort, false, false)
+}
+
+/*
+Search implementation with arbitrary sorting, plus control over
+whether hit scores and max score should be computed. Finds the top n
+hits for query, applying filter if non-nil, and sorting the hits by
+the criteria in sort. If doDocScores is true then the score of each
+hit will be computed and returned. If doMaxScore is true then the
+maximum score over all collected hits will be computed.
+*/
+func (ss *IndexSearcher) SearchSortedScores(q Query, f Filter, n int, sort *Sort,
+	doDocScores, doMaxScore bool) (TopFieldDocs, error) {
+
+	w, err := ss.spi.CreateNormalizedWeight(ss.spi.WrapFilter(q, f))
+	if err != nil {
+		return TopFieldDocs{}, err
+	}
+	return ss.searchSorted(w, nil, n, sort, true, doDocScores, doMaxScore)
+}
+
+/*
+Finds the top n hits for query, applying filter if non-nil, where all
+results are after a previous result (after), sorting the hits by the
+criteria in sort. Document scores and the max score are not computed;
+use SearchAfterSortedScores() if they are needed.
+
+By passing the bottom result from a previous page as after, this
+method can be used for efficient 'deep-paging' across potentially
+large result sets. after must come from a search with the same sort,
+which fills its sort values.
+*/
+func (ss *IndexSearcher) SearchAfterSorted(after *FieldDoc, q Query, f Filter, n int,
+	sort *Sort) (TopFieldDocs, error) {
+
+	return ss.SearchAfterSortedScores(after, q, f, n, sort, false, false)
+}
+
+/*
+Like SearchAfterSorted(), plus control over whether hit scores and
+max score should be computed. If doDocScores is true then the score
+of each hit will be computed and returned. If doMaxScore is true then
+the maximum score over all collected hits, including the ones of
+previous pages, will be computed.
+*/
+func (ss *IndexSearcher) SearchAfterSortedScores(after *FieldDoc, q Query, f Filter, n int,
+	sort *Sort, doDocScores, doMaxScore bool) (TopFieldDocs, error) {
+
+	w, err := ss.spi.CreateNormalizedWeight(ss.spi.WrapFilter(q, f))
+	if err != nil {
+		return TopFieldDocs{}, err
+	}
+	return ss.searchSorted(w, after, n, sort, true, doDocScores, doMaxScore)
+}
+
+/*
+Just like searchWSI(), but you choose whether or not the fields in
+the returned FieldDoc instances should be set, and whether scores
+are computed.
+*/
+func (ss *IndexSearcher) searchSorted(w Weight, after *FieldDoc, nDocs int, sort *Sort,
+	fillFields, doDocScores, doMaxScore bool) (TopFieldDocs, error) {
+
+	assert2(sort != nil, "Sort must not be nil")
+	limit := ss.reader.MaxDoc()
+	if limit == 0 {
+		limit = 1
+	}
+	var afterDoc *ScoreDoc
+	if after != nil {
+		afterDoc = after.ScoreDoc
+	}
+	if err := checkAfter(afterDoc, limit); err != nil {
+		return TopFieldDocs{}, err
+	}
+	if nDocs > limit {
+		nDocs = limit
+	}
+	manager := &topFieldCollectorManager{
+		sort:    sort,
+		numHits: nDocs,
+		after:   after,
+		// sort values are needed to merge the hits of several slices
+		fillFields:        fillFields || ss.workers > 1 && len(ss.leafSlices) > 1,
+		trackDocScores:    doDocScores,
+		trackMaxScore:     doMaxScore,
+		docsScoredInOrder: !w.IsScoresDocsOutOfOrder(),
+	}
+	res, err := ss.searchCM(w, manager)
+	if err != nil {
+		return TopFieldDocs{}, err
+	}
+	return res.(TopFieldDocs), nil
 }
 
 /** Expert: Low-level search implementation.  Finds the top <code>n</code>
- * hits for <code>query</code>.
+ * hits for <code>query</code>, applying <code>filter</code> if non-null.
  *
  * <p>Applications should usually call {@link IndexSearcher#search(Query,int)} or
  * {@link IndexSearcher#search(Query,Filter,int)} instead.
  * @throws BooleanQuery.TooManyClauses If a query would exceed
  *         {@link BooleanQuery#getMaxClauseCount()} clauses.
  */
-func (ss *IndexSearcher) searchLWSI(leaves []*index.AtomicReaderContext,
-	w Weight, after *ScoreDoc, nDocs int) TopDocs {
-	// single thread
+func (ss *IndexSearcher) searchWSI(w Weight, after *ScoreDoc, nDocs int) (TopDocs, error) {
 	limit := ss.reader.MaxDoc()
 	if limit == 0 {
 		limit = 1
 	}
+	if err := checkAfter(after, limit); err != nil {
+		return TopDocs{}, err
+	}
 	if nDocs > limit {
 		nDocs = limit
 	}
-	collector := NewTopScoreDocCollector(nDocs, after, !w.IsScoresDocsOutOfOrder())
-	ss.spi.SearchLWC(leaves, w, collector)
-	return collector.TopDocs()
+	res, err := ss.searchCM(w, &topScoreDocCollectorManager{
+		numHits:           nDocs,
+		after:             after,
+		docsScoredInOrder: !w.IsScoresDocsOutOfOrder(),
+	})
+	if err != nil {
+		return TopDocs{}, err
+	}
+	return res.(TopDocs), nil
+}
+
+// Checks that after, if non-nil, is a hit of a reader of maxDoc limit.
+func checkAfter(after *ScoreDoc, limit int) error {
+	if after != nil && after.Doc >= limit {
+		return fmt.Errorf("after.Doc exceeds the number of documents in the reader: after.Doc=%v limit=%v",
+			after.Doc, limit)
+	}
+	return nil
 }
 
 func (ss *IndexSearcher) SearchLWC(leaves []*index.AtomicReaderContext, w Weight, c Collector) (err error) {
-	// TODO: should we make this
-	// threaded...?  the Collector could be sync'd?
-	// always use single thread:
+	// the leaves are searched by the calling goroutine; see searchCM()
+	// for searching leaf slices concurrently
 	for _, ctx := range leaves { // search each subreader
 		// TODO catch CollectionTerminatedException
-		c.SetNextReader(ctx)
+		if err = c.SetNextReader(ctx); err != nil {
+			return err
+		}
 
 		scorer, err := w.BulkScorer(ctx, !c.AcceptsDocsOutOfOrder(),
 			ctx.Reader().(index.AtomicReader).LiveDocs())
@@ -106,7 +370,9 @@ func (ss *IndexSearcher) SearchLWC(leaves []*index.AtomicReaderContext, w Weight
 			return err
 		}
 		if scorer != nil {
-			err = scorer.ScoreAndCollect(c)
+			if err = scorer.ScoreAndCollect(c); err != nil {
+				return err
+			}
 		} // TODO catch CollectionTerminatedException
 	}
 	return
@@ -116,7 +382,7 @@ func (ss *IndexSearcher) WrapFilter(q Query, f Filter) Query {
 	if f == nil {
 		return q
 	}
-	panic("FilteredQuery not supported yet")
+	return NewFilteredQuery(q, f)
 }
 
 /*
@@ -170,16 +436,29 @@ func (ss *IndexSearcher) CreateNormalizedWeight(q Query) (w Weight, err error) {
 	return w, nil
 }
 
+/*
+Expert: called to re-write queries into primitive queries, until the
+query no longer changes. Returns a TooManyClauses error if a query
+expands to more than MaxClauseCount() clauses.
+*/
 func (ss *IndexSearcher) Rewrite(q Query) (Query, error) {
 	log.Printf("Rewriting '%v'...", q)
-	after := q.Rewrite(ss.reader)
-	for after != q {
+	after, err := q.Rewrite(ss.reader)
+	for err == nil && after != q {
 		q = after
-		after = q.Rewrite(ss.reader)
+		after, err = q.Rewrite(ss.reader)
+	}
+	if err != nil {
+		return nil, err
 	}
 	return q, nil
 }
 
+// Return the IndexReader this searches.
+func (ss *IndexSearcher) IndexReader() index.IndexReader {
+	return ss.reader
+}
+
 // Returns this searhcers the top-level IndexReaderContext
 func (ss *IndexSearcher) TopReaderContext() index.IndexReaderContext {
 	return ss.readerContext
@@ -241,6 +520,8 @@ type SimScorer interface {
 	 * @return document's score
 	 */
 	Score(doc int, freq float32) float32
+	// Computes the amount of a sloppy phrase match, based on an edit distance.
+	computeSlopFactor(distance int) float32
 	// Explain the score for a single document
 	explain(int, Explanation) Explanation
 }
@@ -281,6 +562,20 @@ type ITFIDFSimilarity interface {
 	 * @return a score factor based on the term's document frequency
 	 */
 	idf(docFreq int64, numDocs int64) float32
+	/** Computes the amount of a sloppy phrase match, based on an edit distance.
+	 * This value is summed for each sloppy phrase match in a document to form
+	 * the frequency to be passed to {@link #tf(float)}.
+	 *
+	 * <p>A phrase match with a small edit distance to a document passage more
+	 * closely matches the document, so implementations of this method usually
+	 * return larger values when the edit distance is small and smaller values
+	 * when it is large.
+	 *
+	 * @see PhraseQuery#setSlop(int)
+	 * @param distance the edit distance of this sloppy phrase match
+	 * @return the frequency increment for this match
+	 */
+	sloppyFreq(distance int) float32
 	// Compute an index-time normalization value for this field instance.
 	//
 	// This value will be stored in a single byte lossy representation
@@ -360,6 +655,10 @@ func (ss *tfIDFSimScorer) Score(doc int, freq float32) float32 {
 	return raw * ss.owner.spi.decodeNormValue(ss.norms(doc)) // normalize for field
 }
 
+func (ss *tfIDFSimScorer) computeSlopFactor(distance int) float32 {
+	return ss.owner.spi.sloppyFreq(distance)
+}
+
 func (ss *tfIDFSimScorer) explain(doc int, freq Explanation) Explanation {
 	return ss.owner.explainScore(doc, freq, ss.stats, ss.norms)
 }
@@ -510,6 +809,11 @@ func (ds *DefaultSimilarity) tf(freq float32) float32 {
 	return float32(math.Sqrt(float64(freq)))
 }
 
+// Implemented as 1 / (distance + 1).
+func (ds *DefaultSimilarity) sloppyFreq(distance int) float32 {
+	return 1.0 / float32(distance+1)
+}
+
 func (ds *DefaultSimilarity) idf(docFreq int64, numDocs int64) float32 {
 	return float32(math.Log(float64(numDocs)/float64(docFreq+1))) + 1.0
 }
@@ -1,9 +1,17 @@
 package search
 
 import (
+	"bytes"
+	"fmt"
+	"github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
 	_ "github.com/gzg1984/golucene/core/codec/lucene42"
 	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/index/model"
 	"github.com/gzg1984/golucene/core/store"
+	"github.com/gzg1984/golucene/core/util"
+	"math"
+	"sort"
 	"testing"
 )
 
@@ -53,6 +61,924 @@ func TestKeywordSearch(t *testing.T) {
 	assertEquals(t, "Bat recycling", doc.Get("title"))
 }
 
+func TestPhraseSearch(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+
+	q := NewPhraseQuery()
+	q.Add(index.NewTerm("content", "fruit"))
+	q.Add(index.NewTerm("content", "bat"))
+	assertEquals(t, `content:"fruit bat"`, q.String())
+	docs, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, docs.TotalHits)
+	doc, err := r.Document(docs.ScoreDocs[0].Doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Caring for your fruit bat", doc.Get("title"))
+
+	exp, err := ss.Explain(q, docs.ScoreDocs[0].Doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, true, exp.IsMatch())
+	assertEquals(t, docs.ScoreDocs[0].Score, exp.Value())
+
+	// reversed order needs some slop
+	q = NewPhraseQuery()
+	q.Add(index.NewTerm("content", "bat"))
+	q.Add(index.NewTerm("content", "fruit"))
+	if docs, err = ss.SearchTop(q, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, docs.TotalHits)
+}
+
+func TestSloppyPhraseSearch(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+
+	q := NewPhraseQuery()
+	q.Add(index.NewTerm("content", "bat"))
+	q.Add(index.NewTerm("content", "fruit"))
+	q.SetSlop(2)
+	assertEquals(t, `content:"bat fruit"~2`, q.String())
+	docs, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, docs.TotalHits)
+
+	exp, err := ss.Explain(q, docs.ScoreDocs[0].Doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, docs.ScoreDocs[0].Score, exp.Value())
+
+	// repeating terms
+	q = NewPhraseQuery()
+	q.Add(index.NewTerm("content", "bat"))
+	q.Add(index.NewTerm("content", "bat"))
+	if docs, err = ss.SearchTop(q, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, docs.TotalHits)
+	q.SetSlop(5)
+	if docs, err = ss.SearchTop(q, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 5, docs.TotalHits)
+}
+
+func TestMultiPhraseSearch(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+
+	q := NewMultiPhraseQuery()
+	q.AddTerms(index.NewTerm("content", "fruit"), index.NewTerm("content", "vampire"))
+	q.Add(index.NewTerm("content", "bat"))
+	assertEquals(t, `content:"(fruit vampire) bat"`, q.String())
+	docs, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, docs.TotalHits)
+
+	q = NewMultiPhraseQuery()
+	q.AddTerms(index.NewTerm("content", "fruit"), index.NewTerm("content", "the"))
+	q.Add(index.NewTerm("content", "bat"))
+	if docs, err = ss.SearchTop(q, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, docs.TotalHits)
+	doc, err := r.Document(docs.ScoreDocs[0].Doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Feeding your bat", doc.Get("title"))
+
+	exp, err := ss.Explain(q, docs.ScoreDocs[0].Doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, docs.ScoreDocs[0].Score, exp.Value())
+}
+
+func TestBooleanSearch(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+	term := func(text string) Query {
+		return NewTermQuery(index.NewTerm("content", text))
+	}
+
+	q := NewBooleanQuery()
+	q.Add(term("fruit"), MUST)
+	q.Add(term("bat"), MUST)
+	assertEquals(t, "+content:fruit +content:bat", q.String())
+	docs, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 4, docs.TotalHits)
+	doc, err := r.Document(docs.ScoreDocs[0].Doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Feeding your bat", doc.Get("title"))
+
+	exp, err := ss.Explain(q, docs.ScoreDocs[0].Doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, true, exp.IsMatch())
+	if diff := docs.ScoreDocs[0].Score - exp.Value(); diff > 1e-6 || diff < -1e-6 {
+		t.Errorf("Expected score %v, but explained %v", docs.ScoreDocs[0].Score, exp.Value())
+	}
+
+	q = NewBooleanQuery()
+	q.Add(term("bat"), MUST)
+	q.Add(term("fruit"), MUST_NOT)
+	if docs, err = ss.SearchTop(q, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 4, docs.TotalHits)
+	for _, sd := range docs.ScoreDocs {
+		if exp, err = ss.Explain(q, sd.Doc); err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, true, exp.IsMatch())
+	}
+	if exp, err = ss.Explain(q, 0); err != nil { // contains "fruit"
+		t.Fatal(err)
+	}
+	assertEquals(t, false, exp.IsMatch())
+
+	q = NewBooleanQuery()
+	q.Add(term("bat"), MUST)
+	q.Add(term("fruit"), SHOULD)
+	if docs, err = ss.SearchTop(q, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 8, docs.TotalHits)
+
+	q = NewBooleanQuery()
+	q.Add(term("bat"), SHOULD)
+	q.Add(term("fruit"), SHOULD)
+	q.Add(term("your"), SHOULD)
+	q.SetMinimumNumberShouldMatch(2)
+	assertEquals(t, "(content:bat content:fruit content:your)~2", q.String())
+	if docs, err = ss.SearchTop(q, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 6, docs.TotalHits)
+
+	q = NewBooleanQuery()
+	q.Add(term("bat"), MUST)
+	q.Add(term("fruit"), SHOULD)
+	q.Add(term("your"), SHOULD)
+	q.Add(term("the"), MUST_NOT)
+	if docs, err = ss.SearchTop(q, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 2, docs.TotalHits)
+
+	// a boosted single clause query rewrites to a boosted copy of its
+	// clause, leaving the caller's query untouched
+	bat := term("bat")
+	inner := NewBooleanQuery()
+	inner.Add(bat, MUST)
+	q = NewBooleanQuery()
+	q.Add(inner, SHOULD)
+	q.SetBoost(3)
+	rewritten, err := q.Rewrite(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rewritten.(*TermQuery); !ok || rewritten == bat {
+		t.Errorf("expected a copy of the TermQuery, but %v (%T)", rewritten, rewritten)
+	}
+	assertEquals(t, float32(3), rewritten.Boost())
+	assertEquals(t, float32(1), bat.Boost())
+	assertEquals(t, "+content:bat", inner.String())
+}
+
+func TestFilteredSearch(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+
+	q := NewTermQuery(index.NewTerm("content", "bat"))
+	all, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 8, all.TotalHits)
+	scores := make(map[int]float32)
+	for _, sd := range all.ScoreDocs {
+		scores[sd.Doc] = sd.Score
+	}
+
+	check := func(f Filter, strategy FilterStrategy) {
+		fq := NewFilteredQueryWithStrategy(q, f, strategy)
+		docs, err := ss.Search(fq, nil, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, 4, docs.TotalHits)
+		for _, sd := range docs.ScoreDocs {
+			// filtering must not affect scores
+			assertEquals(t, scores[sd.Doc], sd.Score)
+		}
+	}
+	termsFilter := NewTermsFilterOf("content", "fruit")
+	assertEquals(t, "content:fruit", termsFilter.String())
+	queryFilter := NewQueryWrapperFilter(NewTermQuery(index.NewTerm("content", "fruit")))
+	for _, f := range []Filter{termsFilter, queryFilter} {
+		check(f, RANDOM_ACCESS_FILTER_STRATEGY)
+		check(f, LEAP_FROG_FILTER_FIRST_STRATEGY)
+		check(f, LEAP_FROG_QUERY_FIRST_STRATEGY)
+		check(f, QUERY_FIRST_FILTER_STRATEGY)
+	}
+
+	fq := NewFilteredQuery(q, termsFilter)
+	assertEquals(t, "filtered(content:bat)->content:fruit", fq.String())
+	exp, err := ss.Explain(fq, 3) // "Bat sonar" doesn't contain "fruit"
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, false, exp.IsMatch())
+
+	cachingFilter := NewCachingWrapperFilter(queryFilter)
+	docs, err := ss.Search(q, cachingFilter, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 4, docs.TotalHits)
+	assertEquals(t, 0, cachingFilter.hitCount)
+	if docs, err = ss.Search(q, cachingFilter, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 4, docs.TotalHits)
+	assertEquals(t, cachingFilter.missCount, cachingFilter.hitCount)
+
+	// several terms, including one missing from the index
+	multiFilter := NewTermsFilterOf("content", "sonar", "guano", "fruit", "vampire")
+	if docs, err = ss.Search(q, multiFilter, 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 5, docs.TotalHits)
+
+	// no document matches the filter
+	if docs, err = ss.Search(q, NewTermsFilterOf("content", "vampire"), 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, docs.TotalHits)
+
+	// closing the reader evicts the cached segments
+	assertEquals(t, len(r.Leaves()), len(cachingFilter.cache))
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, len(cachingFilter.cache))
+}
+
+func TestWildcardSearch(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+
+	for _, c := range []struct {
+		pattern string
+		hits    int
+	}{
+		{"b?t", 8},     // bat, but
+		{"*uano", 1},   // guano, batguano
+		{"fruit", 4},   // a single term
+		{"*", 8},       // every term
+		{"vamp*re", 0}, // no such term
+	} {
+		q := NewWildcardQuery(index.NewTerm("content", c.pattern))
+		assertEquals(t, "content:"+c.pattern, q.String())
+		docs, err := ss.SearchTop(q, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertEquals(t, c.hits, docs.TotalHits)
+	}
+
+	docs, err := ss.SearchTop(NewWildcardQuery(index.NewTerm("content", "s?nar")), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, docs.TotalHits)
+	doc, err := r.Document(docs.ScoreDocs[0].Doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Bat sonar", doc.Get("title"))
+}
+
+func TestPrefixSearch(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+
+	q := NewPrefixQuery(index.NewTerm("content", "gua"))
+	assertEquals(t, "content:gua*", q.String())
+	docs, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, docs.TotalHits)
+	doc, err := r.Document(docs.ScoreDocs[0].Doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, "Bat guano", doc.Get("title"))
+
+	if docs, err = ss.SearchTop(NewPrefixQuery(index.NewTerm("content", "fru")), 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 4, docs.TotalHits)
+}
+
+func TestRegexpSearch(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+
+	q := NewRegexpQuery(index.NewTerm("content", "s[a-z]nar"))
+	assertEquals(t, "content:/s[a-z]nar/", q.String())
+	docs, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, docs.TotalHits)
+
+	if docs, err = ss.SearchTop(NewRegexpQuery(index.NewTerm("content", "gu(a|e)no|vampire")), 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, docs.TotalHits)
+
+	if docs, err = ss.SearchTop(NewRegexpQuery(index.NewTerm("content", "fruit|sonar")), 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 5, docs.TotalHits)
+}
+
+func TestFuzzySearch(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+
+	q := NewFuzzyQuery(index.NewTerm("content", "guana"))
+	assertEquals(t, "content:guana~2", q.String())
+	docs, err := ss.SearchTop(NewFuzzyQueryWith(index.NewTerm("content", "guana"), 1, 0, 50, true), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, docs.TotalHits)
+
+	// a transposition is a single edit, unless disabled
+	if docs, err = ss.SearchTop(NewFuzzyQueryWith(index.NewTerm("content", "sonra"), 1, 0, 50, true), 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 1, docs.TotalHits)
+	if docs, err = ss.SearchTop(NewFuzzyQueryWith(index.NewTerm("content", "sonra"), 1, 0, 50, false), 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, docs.TotalHits)
+
+	// the prefix must match exactly
+	if docs, err = ss.SearchTop(NewFuzzyQueryWith(index.NewTerm("content", "frut"), 1, 0, 50, true), 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 4, docs.TotalHits)
+	if docs, err = ss.SearchTop(NewFuzzyQueryWith(index.NewTerm("content", "grut"), 1, 1, 50, true), 10); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, docs.TotalHits)
+
+	// expansions are capped, and boosted by similarity
+	rewritten, err := NewFuzzyQueryWith(index.NewTerm("content", "bat"), 1, 0, 1, true).Rewrite(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bq, ok := rewritten.(*BooleanQuery)
+	if !ok {
+		t.Fatal("expected a BooleanQuery")
+	}
+	assertEquals(t, 1, len(bq.Clauses()))
+	assertEquals(t, "content:bat", bq.Clauses()[0].query.ToString(""))
+	if rewritten, err = NewFuzzyQueryWith(index.NewTerm("content", "guana"), 1, 0, 50, true).Rewrite(r); err != nil {
+		t.Fatal(err)
+	}
+	if bq, ok = rewritten.(*BooleanQuery); !ok {
+		t.Fatal("expected a BooleanQuery")
+	}
+	assertEquals(t, 1, len(bq.Clauses()))
+	assertEquals(t, float32(0.8), bq.Clauses()[0].query.Boost())
+}
+
+func TestMultiTermRewriteMethods(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+
+	for _, method := range []RewriteMethod{
+		CONSTANT_SCORE_FILTER_REWRITE,
+		CONSTANT_SCORE_BOOLEAN_QUERY_REWRITE,
+		SCORING_BOOLEAN_QUERY_REWRITE,
+		NewTopTermsScoringBooleanQueryRewrite(10),
+	} {
+		q := NewPrefixQuery(index.NewTerm("content", "fru"))
+		q.SetRewriteMethod(method)
+		docs, err := ss.SearchTop(q, 10)
+		if err != nil {
+			t.Fatalf("%v: %v", method, err)
+		}
+		assertEquals(t, 4, docs.TotalHits)
+	}
+
+	// constant score rewrites score every hit with the query boost
+	q := NewPrefixQuery(index.NewTerm("content", "b"))
+	q.SetBoost(2)
+	docs, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 8, docs.TotalHits)
+	for _, sd := range docs.ScoreDocs {
+		assertEquals(t, docs.ScoreDocs[0].Score, sd.Score)
+	}
+
+	// boolean rewrites are limited by the max clause count
+	defer SetMaxClauseCount(MaxClauseCount())
+	SetMaxClauseCount(2)
+	bq := NewBooleanQuery()
+	for _, text := range []string{"a", "b"} {
+		if err = bq.Add(NewTermQuery(index.NewTerm("content", text)), SHOULD); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = bq.Add(NewTermQuery(index.NewTerm("content", "c")), SHOULD); err == nil {
+		t.Fatal("expected TooManyClauses")
+	} else if _, ok := err.(*TooManyClauses); !ok {
+		t.Fatalf("expected TooManyClauses, but %v", err)
+	}
+	assertEquals(t, 2, len(bq.Clauses()))
+	q.SetRewriteMethod(SCORING_BOOLEAN_QUERY_REWRITE)
+	if _, err = ss.SearchTop(q, 10); err == nil {
+		t.Fatal("expected TooManyClauses")
+	} else if _, ok := err.(*TooManyClauses); !ok {
+		t.Fatalf("expected TooManyClauses, but %v", err)
+	}
+	q.SetRewriteMethod(NewTopTermsScoringBooleanQueryRewrite(10))
+	rewritten, err := q.Rewrite(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 2, len(rewritten.(*BooleanQuery).Clauses()))
+}
+
+// A sorted in-memory term dictionary, enough to drive a
+// numericRangeTermsEnum.
+type sliceTermsEnum struct {
+	TermsEnum
+	terms [][]byte
+	upto  int
+}
+
+func (e *sliceTermsEnum) Next() ([]byte, error) {
+	if e.upto++; e.upto >= len(e.terms) {
+		return nil, nil
+	}
+	return e.terms[e.upto], nil
+}
+
+func (e *sliceTermsEnum) SeekCeil(text []byte) (SeekStatus, error) {
+	e.upto = sort.Search(len(e.terms), func(i int) bool {
+		return bytes.Compare(e.terms[i], text) >= 0
+	})
+	if e.upto == len(e.terms) {
+		return SEEK_STATUS_END, nil
+	} else if bytes.Equal(e.terms[e.upto], text) {
+		return SEEK_STATUS_FOUND, nil
+	}
+	return SEEK_STATUS_NOT_FOUND, nil
+}
+
+func (e *sliceTermsEnum) Term() []byte {
+	return e.terms[e.upto]
+}
+
+// Returns the values whose trie terms are matched by q.
+func numericRangeMatches(t *testing.T, q *NumericRangeQuery, values map[string][]int64, sorted [][]byte) map[int64]bool {
+	te := newNumericRangeTermsEnum(&sliceTermsEnum{terms: sorted, upto: -1}, q)
+	ans := make(map[int64]bool)
+	for {
+		term, err := te.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if term == nil {
+			return ans
+		}
+		for _, v := range values[string(term)] {
+			if ans[v] {
+				t.Fatalf("%v: value %v matched by more than one term", q, v)
+			}
+			ans[v] = true
+		}
+	}
+}
+
+func TestNumericRangeQuery(t *testing.T) {
+	// index the trie terms of the values like a LongField would
+	const precisionStep = 4
+	values := make(map[string][]int64)
+	for v := int64(-300); v < 5000; v += 7 {
+		stream := analysis.NewNumericTokenStreamWithStep(precisionStep).SetLongValue(v)
+		termAtt := stream.Attributes().Get("TermToBytesRefAttribute").(TermToBytesRefAttribute)
+		ref := termAtt.BytesRef()
+		if err := stream.Reset(); err != nil {
+			t.Fatal(err)
+		}
+		count := 0
+		for {
+			ok, err := stream.IncrementToken()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				break
+			}
+			termAtt.FillBytesRef()
+			values[string(ref.ToBytes())] = append(values[string(ref.ToBytes())], v)
+			count++
+		}
+		assertEquals(t, 64/precisionStep, count)
+	}
+	sorted := make([][]byte, 0, len(values))
+	for term, _ := range values {
+		sorted = append(sorted, []byte(term))
+	}
+	sort.Sort(util.BytesRefs(sorted))
+
+	for _, v := range []struct {
+		min, max                   *int64
+		minInclusive, maxInclusive bool
+	}{
+		{newInt64(0), newInt64(1000), true, true},
+		{newInt64(-300), newInt64(4999), true, false},
+		{newInt64(-300), newInt64(-300), true, true},
+		{newInt64(-300), newInt64(-300), false, true},
+		{newInt64(7), newInt64(4000), false, false},
+		{nil, newInt64(123), true, true},
+		{newInt64(2345), nil, false, true},
+		{nil, nil, true, true},
+		{newInt64(10), newInt64(5), true, true},
+	} {
+		q := NewNumericRangeQueryLongWithStep("ts", precisionStep, v.min, v.max, v.minInclusive, v.maxInclusive)
+		got := numericRangeMatches(t, q, values, sorted)
+		for _, vals := range values {
+			for _, val := range vals {
+				want := (v.min == nil || val > *v.min || v.minInclusive && val == *v.min) &&
+					(v.max == nil || val < *v.max || v.maxInclusive && val == *v.max)
+				if got[val] != want {
+					t.Errorf("%v: value %v should match: %v", q, val, want)
+				}
+			}
+		}
+	}
+
+	min, max := 0.5, 10.0
+	q := NewNumericRangeQueryDouble("price", &min, &max, true, false)
+	assertEquals(t, "price:[0.5 TO 10}", q.ToString(""))
+	assertEquals(t, util.NUMERIC_PRECISION_STEP_DEFAULT, q.PrecisionStep())
+	f := NewNumericRangeFilterInt("n", nil, nil, true, true)
+	assertEquals(t, util.NUMERIC_PRECISION_STEP_DEFAULT_32, f.PrecisionStep())
+	assertEquals(t, "n:[* TO *]", f.String())
+}
+
+func TestTermRangeSearch(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+
+	for _, v := range []struct {
+		lower, upper               []byte
+		includeLower, includeUpper bool
+		want                       int
+	}{
+		{[]byte("fruit"), []byte("fruit"), true, true, 4},
+		{[]byte("fruit"), []byte("fruit"), false, true, 0},
+		{[]byte("fruit"), []byte("fruit"), true, false, 0},
+		{[]byte("gua"), []byte("guano"), false, true, 1},
+		{[]byte("gua"), []byte("guano"), true, false, 0},
+		{[]byte("guano"), []byte("guanp"), false, true, 0},
+		{[]byte("fru"), []byte("frv"), true, true, 4},
+		{[]byte("sonar"), []byte("guano"), true, true, 0},
+		{[]byte("zzz"), nil, true, true, 0},
+		{nil, []byte("bat"), true, true, 8},
+	} {
+		q := NewTermRangeQuery("content", v.lower, v.upper, v.includeLower, v.includeUpper)
+		docs, err := ss.SearchTop(q, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if docs.TotalHits != v.want {
+			t.Errorf("%v: expected %v hits, but %v", q, v.want, docs.TotalHits)
+		}
+	}
+
+	q := NewTermRangeQuery("content", []byte("bat"), nil, false, true)
+	assertEquals(t, "content:{bat TO *]", q.String())
+	assertEquals(t, "[* TO \\*}", NewTermRangeQuery("content", nil, []byte("*"), true, false).ToString("content"))
+}
+
+func TestMatchAllDocsSearch(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+
+	q := NewMatchAllDocsQuery()
+	assertEquals(t, "*:*", q.String())
+	docs, err := ss.SearchTop(q, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, r.NumDocs(), docs.TotalHits)
+	for _, doc := range docs.ScoreDocs {
+		assertEquals(t, docs.ScoreDocs[0].Score, doc.Score)
+	}
+}
+
+func docIds(docs []*ScoreDoc) []int {
+	ans := make([]int, len(docs))
+	for i, doc := range docs {
+		ans[i] = doc.Doc
+	}
+	return ans
+}
+
+func assertDocIds(t *testing.T, msg string, expected []int, docs []*ScoreDoc) {
+	if got := docIds(docs); fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("%v: expected docs %v, but %v", msg, expected, got)
+	}
+}
+
+func TestSortedSearch(t *testing.T) {
+	d, err := store.OpenFSDirectory("testdata/belfrysample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := index.OpenDirectoryReader(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := NewIndexSearcher(r)
+	q := NewTermQuery(index.NewTerm("content", "bat"))
+
+	byScore, err := ss.SearchTop(q, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 8, byScore.TotalHits)
+	docs, err := ss.SearchSortedScores(q, nil, 10, SORT_RELEVANCE, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 8, docs.TotalHits)
+	assertDocIds(t, "relevance", docIds(byScore.ScoreDocs), docs.ScoreDocs)
+	assertEquals(t, byScore.ScoreDocs[0].Score, docs.FieldDocs[0].Fields[0])
+	assertEquals(t, float64(byScore.ScoreDocs[0].Score), docs.MaxScore())
+
+	ascending := docIds(byScore.ScoreDocs)
+	sort.Ints(ascending)
+	if docs, err = ss.SearchSorted(q, nil, 10, SORT_INDEXORDER); err != nil {
+		t.Fatal(err)
+	}
+	assertDocIds(t, "index order", ascending, docs.ScoreDocs)
+	assertEquals(t, ascending[0], docs.FieldDocs[0].Fields[0])
+	assertEquals(t, true, math.IsNaN(float64(docs.ScoreDocs[0].Score)))
+	assertEquals(t, true, math.IsNaN(docs.MaxScore()))
+
+	descending := make([]int, len(ascending))
+	for i, doc := range ascending {
+		descending[len(ascending)-1-i] = doc
+	}
+	reverse := NewSort(NewSortField("", SORT_FIELD_TYPE_DOC, true))
+	if docs, err = ss.SearchSorted(q, nil, 3, reverse); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 8, docs.TotalHits)
+	assertDocIds(t, "reverse index order", descending[:3], docs.ScoreDocs)
+
+	// fields without doc values sort as missing, ties broken by doc id
+	price := NewSortField("price", SORT_FIELD_TYPE_LONG, true)
+	if err = price.SetMissingValue(int64(5)); err != nil {
+		t.Fatal(err)
+	}
+	sortByPrice := NewSort(price, NewSortField("title", SORT_FIELD_TYPE_STRING, false))
+	assertEquals(t, "<long: \"price\">! missingValue=5,<string: \"title\">", sortByPrice.String())
+	if docs, err = ss.SearchSorted(q, nil, 10, sortByPrice); err != nil {
+		t.Fatal(err)
+	}
+	assertDocIds(t, "missing values", ascending, docs.ScoreDocs)
+	assertEquals(t, int64(5), docs.FieldDocs[0].Fields[0])
+	assertEquals(t, true, docs.FieldDocs[0].Fields[1].([]byte) == nil)
+
+	if err = price.SetMissingValue(5); err == nil {
+		t.Error("int missing value should be rejected for a LONG sort")
+	}
+}
+
+type sliceSortedDocValues struct {
+	ords  []int
+	terms [][]byte
+}
+
+func (v *sliceSortedDocValues) Get(doc int) []byte {
+	if ord := v.ords[doc]; ord >= 0 {
+		return v.terms[ord]
+	}
+	return nil
+}
+
+func (v *sliceSortedDocValues) Ord(doc int) int          { return v.ords[doc] }
+func (v *sliceSortedDocValues) LookupOrd(ord int) []byte { return v.terms[ord] }
+func (v *sliceSortedDocValues) ValueCount() int          { return len(v.terms) }
+
+func TestTopFieldCollector(t *testing.T) {
+	// numeric values, largest first, ties broken by doc id
+	prices := []int64{30, 10, 50, 20, 50, 40}
+	c := NewTopFieldCollector(NewSort(NewSortField("price", SORT_FIELD_TYPE_LONG, true)), 3, nil, true, false, false, true)
+	c.comparators[0].(*longComparator).currentReaderValues = func(doc int) int64 { return prices[doc] }
+	for doc := range prices {
+		if err := c.Collect(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	docs := c.TopFieldDocs()
+	assertEquals(t, 6, docs.TotalHits)
+	assertDocIds(t, "price", []int{2, 4, 5}, docs.ScoreDocs)
+	assertEquals(t, int64(40), docs.FieldDocs[2].Fields[0])
+
+	// string values over two segments, missing values last
+	title := NewSortField("title", SORT_FIELD_TYPE_STRING, false)
+	if err := title.SetMissingValue(SORT_FIELD_STRING_LAST); err != nil {
+		t.Fatal(err)
+	}
+	c = NewTopFieldCollector(NewSort(title), 4, nil, true, false, false, true)
+	comp := c.comparators[0].(*termOrdValComparator)
+	segments := []*sliceSortedDocValues{
+		{[]int{1, -1, 0, 2}, [][]byte{[]byte("b"), []byte("d"), []byte("f")}},
+		{[]int{0, 2, -1, 1}, [][]byte{[]byte("a"), []byte("c"), []byte("e")}},
+	}
+	for i, values := range segments {
+		// what SetNextReader() does for a segment of 4 docs
+		c.docBase = i * 4
+		comp.termsIndex = values
+		comp.currentReaderGen++
+		if comp.bottomSlot != -1 {
+			comp.SetBottom(comp.bottomSlot)
+		}
+		for doc := range values.ords {
+			if err := c.Collect(doc); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	docs = c.TopFieldDocs()
+	assertEquals(t, 8, docs.TotalHits)
+	assertDocIds(t, "title", []int{4, 2, 7, 0}, docs.ScoreDocs)
+	assertEquals(t, "a", string(docs.FieldDocs[0].Fields[0].([]byte)))
+	assertEquals(t, "d", string(docs.FieldDocs[3].Fields[0].([]byte)))
+}
+
+func TestMergeTopDocs(t *testing.T) {
+	shard := func(totalHits int, docs ...*ScoreDoc) TopDocs {
+		maxScore := math.NaN()
+		if len(docs) > 0 {
+			maxScore = float64(docs[0].Score)
+		}
+		return TopDocs{totalHits, docs, maxScore}
+	}
+	merged := MergeTopDocs(4, []TopDocs{
+		shard(3, newScoreDoc(1, 3), newScoreDoc(4, 2), newScoreDoc(0, 1)),
+		shard(0),
+		shard(5, newScoreDoc(7, 2.5), newScoreDoc(5, 2)),
+	})
+	assertEquals(t, 8, merged.TotalHits)
+	assertEquals(t, 3.0, merged.MaxScore())
+	assertDocIds(t, "by score", []int{1, 7, 4, 5}, merged.ScoreDocs)
+	// ties are broken by shard
+	assertEquals(t, 0, merged.ScoreDocs[2].ShardIndex)
+	assertEquals(t, 2, merged.ScoreDocs[3].ShardIndex)
+
+	sort := NewSort(NewSortField("price", SORT_FIELD_TYPE_LONG, true), FIELD_DOC)
+	fieldShard := func(docs ...*FieldDoc) TopFieldDocs {
+		scoreDocs := make([]*ScoreDoc, len(docs))
+		for i, doc := range docs {
+			scoreDocs[i] = doc.ScoreDoc
+		}
+		return TopFieldDocs{TopDocs{len(docs), scoreDocs, math.NaN()}, sort.Fields(), docs}
+	}
+	nan := float32(math.NaN())
+	fieldMerged := MergeTopFieldDocs(sort, 10, []TopFieldDocs{
+		fieldShard(NewFieldDoc(0, nan, []interface{}{int64(30), 0}), NewFieldDoc(2, nan, []interface{}{int64(10), 2})),
+		fieldShard(NewFieldDoc(3, nan, []interface{}{int64(30), 3}), NewFieldDoc(5, nan, []interface{}{int64(20), 5})),
+	})
+	assertEquals(t, 4, fieldMerged.TotalHits)
+	assertDocIds(t, "by price", []int{0, 3, 5, 2}, fieldMerged.ScoreDocs)
+	assertEquals(t, int64(20), fieldMerged.FieldDocs[2].Fields[0])
+	assertEquals(t, true, math.IsNaN(fieldMerged.MaxScore()))
+}
+
+func newInt64(v int64) *int64 {
+	return &v
+}
+
 // func TestSingleSearch(t *testing.T) {
 // 	ss := NewSearcher()
 // 	ss.IncludeIndex("testdata/belfrysample")
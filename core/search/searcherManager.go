@@ -0,0 +1,201 @@
+package search
+
+import (
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	"github.com/gzg1984/golucene/core/store"
+)
+
+// search/SearcherFactory.java
+
+/*
+Factory class used by SearcherManager to create new IndexSearchers.
+The implementation can be used to perform custom operations on a new
+IndexSearcher, before it's returned to the application:
+
+  - Setting a custom scoring model: IndexSearcher.SetSimilarity()
+  - Run queries to warm your IndexSearcher before it is used, so
+    that the first application queries don't pay the cost of
+    loading the new segments.
+
+The returned searcher must search exactly the provided reader.
+*/
+type SearcherFactory interface {
+	// Returns a new IndexSearcher over the given reader.
+	NewSearcher(reader index.IndexReader) (*IndexSearcher, error)
+}
+
+/* The SearcherFactory used when none is given: a plain IndexSearcher. */
+type defaultSearcherFactory struct{}
+
+func (f defaultSearcherFactory) NewSearcher(reader index.IndexReader) (*IndexSearcher, error) {
+	return NewIndexSearcher(reader), nil
+}
+
+// search/SearcherManager.java
+
+/*
+Utility class to safely share IndexSearcher instances across multiple
+goroutines, while periodically reopening. This class ensures each
+searcher is closed only once all goroutines have finished using it.
+
+Use Acquire() to obtain the current searcher, and Release() to
+release it, like this:
+
+	s, err := manager.Acquire()
+	if err != nil {
+		return err
+	}
+	defer manager.Release(s)
+	// Do searching, doc retrieval, etc. with s
+
+In addition you should periodically call MaybeRefresh(). While it's
+possible to call this just before running each query, this is
+discouraged since it penalizes the unlucky queries that need to
+refresh. It's better to use a separate background goroutine, that
+periodically calls MaybeRefresh(). Finally, be sure to call Close()
+once you are done.
+*/
+type SearcherManager struct {
+	*ReferenceManager
+	searcherFactory SearcherFactory
+}
+
+/*
+Creates and returns a new SearcherManager from the given IndexWriter.
+
+If applyAllDeletes is true, all buffered deletes will be applied
+(made visible) in the IndexSearcher / DirectoryReader. If false, the
+deletes may or may not be applied, but remain buffered (in
+IndexWriter) so that they will be applied in the future. Applying
+deletes can be costly, so if your app can tolerate deleted documents
+being returned you might gain some performance by passing false.
+
+searcherFactory is used to create new searchers; nil means a plain
+IndexSearcher is used.
+*/
+func NewSearcherManager(writer *index.IndexWriter, applyAllDeletes bool,
+	searcherFactory SearcherFactory) (*SearcherManager, error) {
+
+	reader, err := index.OpenDirectoryReaderFromWriter(writer, applyAllDeletes)
+	if err != nil {
+		return nil, err
+	}
+	return newSearcherManager(reader, searcherFactory)
+}
+
+/*
+Creates and returns a new SearcherManager from the given Directory.
+
+searcherFactory is used to create new searchers; nil means a plain
+IndexSearcher is used.
+*/
+func NewSearcherManagerFromDirectory(dir store.Directory,
+	searcherFactory SearcherFactory) (*SearcherManager, error) {
+
+	reader, err := index.OpenDirectoryReader(dir)
+	if err != nil {
+		return nil, err
+	}
+	return newSearcherManager(reader, searcherFactory)
+}
+
+func newSearcherManager(reader index.DirectoryReader,
+	searcherFactory SearcherFactory) (*SearcherManager, error) {
+
+	if searcherFactory == nil {
+		searcherFactory = defaultSearcherFactory{}
+	}
+	searcher, err := searcherOf(searcherFactory, reader)
+	if err != nil {
+		return nil, err
+	}
+	sm := &SearcherManager{searcherFactory: searcherFactory}
+	sm.ReferenceManager = newReferenceManager(sm, searcher)
+	return sm, nil
+}
+
+/*
+Obtain the current IndexSearcher. You must match every call to
+Acquire with one call to Release; it's best to do so in a defer
+statement.
+*/
+func (sm *SearcherManager) Acquire() (*IndexSearcher, error) {
+	ref, err := sm.acquire()
+	if err != nil {
+		return nil, err
+	}
+	return ref.(*IndexSearcher), nil
+}
+
+/*
+Release the searcher previously obtained with Acquire().
+
+NOTE: it's safe to call this after Close().
+*/
+func (sm *SearcherManager) Release(searcher *IndexSearcher) error {
+	return sm.release(searcher)
+}
+
+func (sm *SearcherManager) decRef(ref interface{}) error {
+	return ref.(*IndexSearcher).IndexReader().DecRef()
+}
+
+func (sm *SearcherManager) refreshIfNeeded(referenceToRefresh interface{}) (interface{}, error) {
+	r := referenceToRefresh.(*IndexSearcher).IndexReader()
+	dr, ok := r.(index.DirectoryReader)
+	assert2(ok, "searcher's IndexReader should be a DirectoryReader, but got %v", r)
+	newReader, err := index.OpenDirectoryReaderIfChanged(dr)
+	if err != nil || newReader == nil {
+		return nil, err
+	}
+	return searcherOf(sm.searcherFactory, newReader)
+}
+
+func (sm *SearcherManager) tryIncRef(ref interface{}) bool {
+	return ref.(*IndexSearcher).IndexReader().TryIncRef()
+}
+
+func (sm *SearcherManager) refCount(ref interface{}) int {
+	return ref.(*IndexSearcher).IndexReader().RefCount()
+}
+
+/*
+Returns true if no changes have occured since this searcher ie.
+reader was opened, otherwise false.
+*/
+func (sm *SearcherManager) IsSearcherCurrent() (bool, error) {
+	searcher, err := sm.Acquire()
+	if err != nil {
+		return false, err
+	}
+	defer sm.Release(searcher)
+	r := searcher.IndexReader()
+	dr, ok := r.(index.DirectoryReader)
+	assert2(ok, "searcher's IndexReader should be a DirectoryReader, but got %v", r)
+	return dr.IsCurrent(), nil
+}
+
+/*
+Expert: creates a searcher from the provided IndexReader using the
+provided SearcherFactory. The reader is released (DecRef'd) if the
+factory fails.
+*/
+func searcherOf(searcherFactory SearcherFactory, reader index.IndexReader) (searcher *IndexSearcher, err error) {
+	var success = false
+	defer func() {
+		if !success {
+			reader.DecRef() // keep the original error
+		}
+	}()
+	if searcher, err = searcherFactory.NewSearcher(reader); err != nil {
+		return nil, err
+	}
+	if searcher.IndexReader() != reader {
+		return nil, fmt.Errorf(
+			"SearcherFactory must wrap exactly the provided reader (got %v but expected %v)",
+			searcher.IndexReader(), reader)
+	}
+	success = true
+	return searcher, nil
+}
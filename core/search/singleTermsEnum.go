@@ -0,0 +1,45 @@
+package search
+
+import (
+	. "github.com/gzg1984/golucene/core/index/model"
+)
+
+// search/SingleTermsEnum.java
+
+/*
+Subclass of TermsEnum for enumerating a single term.
+
+For example, this can be used by MultiTermQuery's that need only
+visit one term, but want to preserve MultiTermQuery semantics such
+as MultiTermQuery's rewrite.
+*/
+type singleTermsEnum struct {
+	TermsEnum
+	singleRef []byte
+	done      bool
+}
+
+/*
+Creates a new singleTermsEnum.
+
+After calling the constructor the enumeration is not yet positioned;
+the first call to Next() seeks to the single term.
+*/
+func newSingleTermsEnum(tenum TermsEnum, termText []byte) *singleTermsEnum {
+	return &singleTermsEnum{
+		TermsEnum: tenum,
+		singleRef: termText,
+	}
+}
+
+func (e *singleTermsEnum) Next() ([]byte, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	ok, err := e.TermsEnum.SeekExact(e.singleRef)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return e.TermsEnum.Term(), nil
+}
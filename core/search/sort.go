@@ -0,0 +1,311 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// search/Sort.java
+
+/*
+Encapsulates sort criteria for returned hits.
+
+The fields used to determine sort order must be indexed with doc
+values of the matching type: NumericDocValues for SORT_FIELD_INT,
+SORT_FIELD_LONG, SORT_FIELD_FLOAT and SORT_FIELD_DOUBLE,
+SortedDocValues for SORT_FIELD_STRING, and BinaryDocValues (or
+SortedDocValues) for SORT_FIELD_STRING_VAL. Documents without a value
+for the field sort as the field's missing value.
+
+Float and double values are expected to be stored as the bits of the
+value, as FloatDocValuesField and DoubleDocValuesField do.
+
+Sorting uses more memory than relevance ranking: a slot per hit for
+each sort field is held while collecting.
+*/
+type Sort struct {
+	fields []*SortField
+}
+
+var (
+	// Represents sorting by computed relevance. Using this sort
+	// criteria returns the same results as calling
+	// IndexSearcher.Search() without a sort criteria, only with slightly
+	// more overhead.
+	SORT_RELEVANCE = NewSort()
+
+	// Represents sorting by index order.
+	SORT_INDEXORDER = NewSort(FIELD_DOC)
+)
+
+/*
+Sets the sort to the given criteria in succession: the first
+SortField is checked first, but if it produces a tie, then the second
+SortField is used to break the tie, etc. Finally, if there is still a
+tie after all SortFields are checked, the internal Lucene docid is
+used to break it. Without any field, it sorts by computed relevance.
+*/
+func NewSort(fields ...*SortField) *Sort {
+	if len(fields) == 0 {
+		fields = []*SortField{FIELD_SCORE}
+	}
+	return &Sort{fields}
+}
+
+// Representation of the sort criteria.
+func (s *Sort) Fields() []*SortField {
+	return s.fields
+}
+
+// Returns true if the relevance score is needed to sort documents.
+func (s *Sort) NeedsScores() bool {
+	for _, field := range s.fields {
+		if field.NeedsScores() {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Sort) String() string {
+	var buf bytes.Buffer
+	for i, field := range s.fields {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		buf.WriteString(field.String())
+	}
+	return buf.String()
+}
+
+// search/SortField.java
+
+// Specifies the type of the terms to be sorted, or special types such
+// as relevancy or document index order.
+type SortFieldType int
+
+const (
+	// Sort by document score (relevance). Sort values are float32 and
+	// higher values are at the front.
+	SORT_FIELD_TYPE_SCORE = SortFieldType(iota)
+	// Sort by document number (index order). Sort values are int and
+	// lower values are at the front.
+	SORT_FIELD_TYPE_DOC
+	// Sort using term values as encoded []byte. Sort values are []byte
+	// and lower values are at the front.
+	SORT_FIELD_TYPE_STRING
+	// Sort using term values as encoded int32. Sort values are int32
+	// and lower values are at the front.
+	SORT_FIELD_TYPE_INT
+	// Sort using term values as encoded float32. Sort values are
+	// float32 and lower values are at the front.
+	SORT_FIELD_TYPE_FLOAT
+	// Sort using term values as encoded int64. Sort values are int64
+	// and lower values are at the front.
+	SORT_FIELD_TYPE_LONG
+	// Sort using term values as encoded float64. Sort values are
+	// float64 and lower values are at the front.
+	SORT_FIELD_TYPE_DOUBLE
+	// Sort using term values as []byte, comparing the values of
+	// BinaryDocValues directly rather than their ordinals. This is
+	// typically slower than SORT_FIELD_TYPE_STRING.
+	SORT_FIELD_TYPE_STRING_VAL
+)
+
+func (t SortFieldType) String() string {
+	switch t {
+	case SORT_FIELD_TYPE_SCORE:
+		return "SCORE"
+	case SORT_FIELD_TYPE_DOC:
+		return "DOC"
+	case SORT_FIELD_TYPE_STRING:
+		return "STRING"
+	case SORT_FIELD_TYPE_INT:
+		return "INT"
+	case SORT_FIELD_TYPE_FLOAT:
+		return "FLOAT"
+	case SORT_FIELD_TYPE_LONG:
+		return "LONG"
+	case SORT_FIELD_TYPE_DOUBLE:
+		return "DOUBLE"
+	case SORT_FIELD_TYPE_STRING_VAL:
+		return "STRING_VAL"
+	}
+	return fmt.Sprintf("SortFieldType(%d)", int(t))
+}
+
+type missingStringValue string
+
+var (
+	// Pass this to SetMissingValue() to have missing string values sort
+	// first.
+	SORT_FIELD_STRING_FIRST = missingStringValue("SortField.STRING_FIRST")
+	// Pass this to SetMissingValue() to have missing string values sort
+	// last.
+	SORT_FIELD_STRING_LAST = missingStringValue("SortField.STRING_LAST")
+)
+
+var (
+	// Represents sorting by document score (relevance).
+	FIELD_SCORE = NewSortField("", SORT_FIELD_TYPE_SCORE, false)
+
+	// Represents sorting by document number (index order).
+	FIELD_DOC = NewSortField("", SORT_FIELD_TYPE_DOC, false)
+)
+
+/*
+Stores information about how to sort documents by terms in an
+individual field. Fields must be indexed with doc values in order to
+sort by them.
+*/
+type SortField struct {
+	field        string
+	typ          SortFieldType
+	reverse      bool
+	missingValue interface{}
+}
+
+/*
+Creates a sort, possibly in reverse, by terms in the given field with
+the type of term values explicitly given. The field is ignored for
+SORT_FIELD_TYPE_SCORE and SORT_FIELD_TYPE_DOC, and required for all
+other types.
+*/
+func NewSortField(field string, typ SortFieldType, reverse bool) *SortField {
+	if typ != SORT_FIELD_TYPE_SCORE && typ != SORT_FIELD_TYPE_DOC {
+		assert2(field != "", "field can only be empty when type is SCORE or DOC")
+	} else {
+		field = ""
+	}
+	return &SortField{
+		field:   field,
+		typ:     typ,
+		reverse: reverse,
+	}
+}
+
+/*
+Set the value to use for documents that don't have a value. The value
+must be of the sort values' type (e.g. int64 for SORT_FIELD_TYPE_LONG),
+or SORT_FIELD_STRING_FIRST/SORT_FIELD_STRING_LAST for
+SORT_FIELD_TYPE_STRING and SORT_FIELD_TYPE_STRING_VAL. By default,
+numeric fields use zero and strings sort first.
+*/
+func (f *SortField) SetMissingValue(missingValue interface{}) error {
+	var ok bool
+	switch f.typ {
+	case SORT_FIELD_TYPE_STRING, SORT_FIELD_TYPE_STRING_VAL:
+		_, ok = missingValue.(missingStringValue)
+		if !ok {
+			return fmt.Errorf("For STRING type, missing value must be either SORT_FIELD_STRING_FIRST or SORT_FIELD_STRING_LAST")
+		}
+	case SORT_FIELD_TYPE_INT:
+		_, ok = missingValue.(int32)
+	case SORT_FIELD_TYPE_LONG:
+		_, ok = missingValue.(int64)
+	case SORT_FIELD_TYPE_FLOAT:
+		_, ok = missingValue.(float32)
+	case SORT_FIELD_TYPE_DOUBLE:
+		_, ok = missingValue.(float64)
+	default:
+		return fmt.Errorf("Missing value only works for numeric or STRING types")
+	}
+	if !ok {
+		return fmt.Errorf("Missing value %v (%T) doesn't match sort type %v",
+			missingValue, missingValue, f.typ)
+	}
+	f.missingValue = missingValue
+	return nil
+}
+
+// Returns the value used for documents missing the field, or nil if
+// the default is used.
+func (f *SortField) MissingValue() interface{} {
+	return f.missingValue
+}
+
+// Returns the name of the field. Could return "" if the sort is by
+// SCORE or DOC.
+func (f *SortField) Field() string {
+	return f.field
+}
+
+// Returns the type of contents in the field.
+func (f *SortField) Type() SortFieldType {
+	return f.typ
+}
+
+// Returns whether the sort should be reversed.
+func (f *SortField) Reverse() bool {
+	return f.reverse
+}
+
+// Whether the relevance score is needed to sort documents.
+func (f *SortField) NeedsScores() bool {
+	return f.typ == SORT_FIELD_TYPE_SCORE
+}
+
+func (f *SortField) String() string {
+	var buf bytes.Buffer
+	switch f.typ {
+	case SORT_FIELD_TYPE_SCORE:
+		buf.WriteString("<score>")
+	case SORT_FIELD_TYPE_DOC:
+		buf.WriteString("<doc>")
+	case SORT_FIELD_TYPE_STRING:
+		fmt.Fprintf(&buf, "<string: \"%v\">", f.field)
+	case SORT_FIELD_TYPE_STRING_VAL:
+		fmt.Fprintf(&buf, "<string_val: \"%v\">", f.field)
+	case SORT_FIELD_TYPE_INT:
+		fmt.Fprintf(&buf, "<int: \"%v\">", f.field)
+	case SORT_FIELD_TYPE_LONG:
+		fmt.Fprintf(&buf, "<long: \"%v\">", f.field)
+	case SORT_FIELD_TYPE_FLOAT:
+		fmt.Fprintf(&buf, "<float: \"%v\">", f.field)
+	case SORT_FIELD_TYPE_DOUBLE:
+		fmt.Fprintf(&buf, "<double: \"%v\">", f.field)
+	default:
+		buf.WriteString("<???: \"" + f.field + "\">")
+	}
+	if f.reverse {
+		buf.WriteRune('!')
+	}
+	if f.missingValue != nil {
+		fmt.Fprintf(&buf, " missingValue=%v", f.missingValue)
+	}
+	return buf.String()
+}
+
+/*
+Returns the FieldComparator to use for sorting.
+
+numHits is the number of top hits the queue will store; sortPos is
+the position of this SortField within Sort. The comparator is primary
+if sortPos == 0, secondary if sortPos == 1, etc. Some comparators can
+optimize themselves when they are the primary sort.
+*/
+func (f *SortField) Comparator(numHits, sortPos int) FieldComparator {
+	switch f.typ {
+	case SORT_FIELD_TYPE_SCORE:
+		return newRelevanceComparator(numHits)
+	case SORT_FIELD_TYPE_DOC:
+		return newDocComparator(numHits)
+	case SORT_FIELD_TYPE_INT:
+		missing, _ := f.missingValue.(int32)
+		return newIntComparator(numHits, f.field, missing)
+	case SORT_FIELD_TYPE_FLOAT:
+		missing, _ := f.missingValue.(float32)
+		return newFloatComparator(numHits, f.field, missing)
+	case SORT_FIELD_TYPE_LONG:
+		missing, _ := f.missingValue.(int64)
+		return newLongComparator(numHits, f.field, missing)
+	case SORT_FIELD_TYPE_DOUBLE:
+		missing, _ := f.missingValue.(float64)
+		return newDoubleComparator(numHits, f.field, missing)
+	case SORT_FIELD_TYPE_STRING:
+		return newTermOrdValComparator(numHits, f.field, f.missingValue == SORT_FIELD_STRING_LAST)
+	case SORT_FIELD_TYPE_STRING_VAL:
+		return newTermValComparator(numHits, f.field, f.missingValue == SORT_FIELD_STRING_LAST)
+	}
+	panic(fmt.Sprintf("Illegal sort type: %v", f.typ))
+}
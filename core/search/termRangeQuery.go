@@ -0,0 +1,175 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	. "github.com/gzg1984/golucene/core/index/model"
+)
+
+// search/TermRangeQuery.java
+
+/*
+A Query that matches documents within a range of terms.
+
+This query matches the documents looking for terms that fall into the
+supplied range according to the byte order of the terms (i.e. Unicode
+code point order for UTF-8 terms). It is not intended for numerical
+ranges; use NumericRangeQuery instead.
+
+This query uses CONSTANT_SCORE_FILTER_REWRITE by default.
+*/
+type TermRangeQuery struct {
+	*MultiTermQuery
+	lowerTerm, upperTerm       []byte
+	includeLower, includeUpper bool
+}
+
+/*
+Constructs a query selecting all terms greater/equal than lowerTerm
+but less/equal than upperTerm.
+
+If an endpoint is nil, it is said to be "open". Either or both
+endpoints may be open. Open endpoints may not be exclusive (you can't
+select all but the first or last term without explicitly specifying
+the term to exclude.)
+*/
+func NewTermRangeQuery(field string, lowerTerm, upperTerm []byte,
+	includeLower, includeUpper bool) *TermRangeQuery {
+
+	ans := &TermRangeQuery{
+		lowerTerm:    lowerTerm,
+		upperTerm:    upperTerm,
+		includeLower: includeLower,
+		includeUpper: includeUpper,
+	}
+	ans.MultiTermQuery = newMultiTermQuery(ans, field)
+	return ans
+}
+
+// Returns the lower value of this range query, or nil if open.
+func (q *TermRangeQuery) LowerTerm() []byte { return q.lowerTerm }
+
+// Returns the upper value of this range query, or nil if open.
+func (q *TermRangeQuery) UpperTerm() []byte { return q.upperTerm }
+
+// Returns true if the lower endpoint is inclusive
+func (q *TermRangeQuery) IncludesLower() bool { return q.includeLower }
+
+// Returns true if the upper endpoint is inclusive
+func (q *TermRangeQuery) IncludesUpper() bool { return q.includeUpper }
+
+func (q *TermRangeQuery) TermsEnum(terms Terms) (TermsEnum, error) {
+	if q.lowerTerm != nil && q.upperTerm != nil && bytes.Compare(q.lowerTerm, q.upperTerm) > 0 {
+		return EMPTY_TERMS_ENUM, nil
+	}
+
+	tenum := terms.Iterator(nil)
+	if (q.lowerTerm == nil || (q.includeLower && len(q.lowerTerm) == 0)) && q.upperTerm == nil {
+		return tenum, nil
+	}
+	return newTermRangeTermsEnum(tenum, q.lowerTerm, q.upperTerm, q.includeLower, q.includeUpper), nil
+}
+
+// Prints a user-readable version of this query.
+func (q *TermRangeQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	if q.field != field {
+		buf.WriteString(q.field)
+		buf.WriteRune(':')
+	}
+	if q.includeLower {
+		buf.WriteRune('[')
+	} else {
+		buf.WriteRune('{')
+	}
+	writeRangeTerm(&buf, q.lowerTerm)
+	buf.WriteString(" TO ")
+	writeRangeTerm(&buf, q.upperTerm)
+	if q.includeUpper {
+		buf.WriteRune(']')
+	} else {
+		buf.WriteRune('}')
+	}
+	if q.boost != 1.0 {
+		fmt.Fprintf(&buf, "^%v", q.boost)
+	}
+	return buf.String()
+}
+
+// Writes an endpoint, escaping a literal "*" so it is not mistaken for
+// an open end.
+func writeRangeTerm(buf *bytes.Buffer, term []byte) {
+	switch {
+	case term == nil:
+		buf.WriteRune('*')
+	case string(term) == "*":
+		buf.WriteString("\\*")
+	default:
+		buf.Write(term)
+	}
+}
+
+// search/TermRangeTermsEnum.java
+
+/*
+Subclass of filteredTermsEnum for enumerating all terms that match
+the specified range parameters. Each term in the enumeration is
+greater than all that precede it.
+*/
+type termRangeTermsEnum struct {
+	*filteredTermsEnum
+	includeLower, includeUpper bool
+	lowerBytesRef              []byte
+	upperBytesRef              []byte
+}
+
+/*
+Enumerates all terms greater/equal than lowerTerm but less/equal than
+upperTerm.
+
+If an endpoint is nil, it is said to be "open". Either or both
+endpoints may be open. Open endpoints may not be exclusive (you can't
+select all but the first or last term without explicitly specifying
+the term to exclude.)
+*/
+func newTermRangeTermsEnum(tenum TermsEnum, lowerTerm, upperTerm []byte,
+	includeLower, includeUpper bool) *termRangeTermsEnum {
+
+	ans := &termRangeTermsEnum{
+		includeLower:  includeLower,
+		includeUpper:  includeUpper,
+		lowerBytesRef: lowerTerm,
+		upperBytesRef: upperTerm,
+	}
+	ans.filteredTermsEnum = newFilteredTermsEnum(ans, tenum, true)
+
+	// do a little bit of normalization...
+	// open ended range queries should always be inclusive.
+	if lowerTerm == nil {
+		ans.lowerBytesRef = []byte{}
+		ans.includeLower = true
+	}
+	if upperTerm == nil {
+		ans.includeUpper = true
+	}
+
+	ans.setInitialSeekTerm(ans.lowerBytesRef)
+	return ans
+}
+
+func (e *termRangeTermsEnum) accept(term []byte) acceptStatus {
+	if !e.includeLower && bytes.Equal(term, e.lowerBytesRef) {
+		return ACCEPT_STATUS_NO
+	}
+
+	// Use this field's default sort ordering
+	if e.upperBytesRef != nil {
+		cmp := bytes.Compare(e.upperBytesRef, term)
+		// if beyond the upper term, or is exclusive and this is equal to
+		// the upper term, break out
+		if cmp < 0 || (!e.includeUpper && cmp == 0) {
+			return ACCEPT_STATUS_END
+		}
+	}
+	return ACCEPT_STATUS_YES
+}
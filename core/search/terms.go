@@ -29,6 +29,11 @@ func NewTermQueryWithDocFreq(t *index.Term, docFreq int) *TermQuery {
 	return ans
 }
 
+// Returns the term of this query.
+func (q *TermQuery) Term() *index.Term {
+	return q.term
+}
+
 func (q *TermQuery) CreateWeight(ss *IndexSearcher) (w Weight, err error) {
 	ctx := ss.TopReaderContext()
 	var termState *index.TermContext
@@ -0,0 +1,126 @@
+package search
+
+import (
+	"bytes"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/index/model"
+	. "github.com/gzg1984/golucene/core/search/model"
+	"github.com/gzg1984/golucene/core/util"
+	"sort"
+)
+
+// queries/TermsFilter.java
+
+/*
+Constructs a filter for docs matching any of the terms added to this
+class. Unlike a RangeFilter this can be used for filtering on
+multiple terms that are not necessarily in a sequence. An example
+might be a collection of primary keys from a database query result or
+perhaps a choice of "category" labels picked by the end user. As a
+filter, this is much faster than the equivalent query (a BooleanQuery
+with many "should" TermQueries).
+*/
+type TermsFilter struct {
+	terms []*index.Term // sorted by field, then by bytes
+}
+
+// Creates a new TermsFilter from the given terms.
+func NewTermsFilter(terms ...*index.Term) *TermsFilter {
+	assert2(len(terms) > 0, "You must specify at least one term")
+	sorted := make([]*index.Term, 0, len(terms))
+	sorted = append(sorted, terms...)
+	sort.Sort(termSorter(sorted))
+	// dedup
+	n := 1
+	for _, t := range sorted[1:] {
+		if last := sorted[n-1]; t.Field != last.Field || !bytes.Equal(t.Bytes, last.Bytes) {
+			sorted[n] = t
+			n++
+		}
+	}
+	return &TermsFilter{sorted[:n]}
+}
+
+// Creates a new TermsFilter from the given texts for a single field.
+func NewTermsFilterOf(field string, texts ...string) *TermsFilter {
+	terms := make([]*index.Term, len(texts))
+	for i, text := range texts {
+		terms[i] = index.NewTerm(field, text)
+	}
+	return NewTermsFilter(terms...)
+}
+
+type termSorter []*index.Term
+
+func (s termSorter) Len() int      { return len(s) }
+func (s termSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s termSorter) Less(i, j int) bool {
+	if s[i].Field != s[j].Field {
+		return s[i].Field < s[j].Field
+	}
+	return bytes.Compare(s[i].Bytes, s[j].Bytes) < 0
+}
+
+func (f *TermsFilter) DocIdSet(ctx *index.AtomicReaderContext,
+	acceptDocs util.Bits) (DocIdSet, error) {
+
+	reader := ctx.Reader().(index.AtomicReader)
+	var result *util.FixedBitSet // lazy init if needed - no need to create a big bitset ahead of time
+	fields := reader.Fields()
+	if fields == nil {
+		return nil, nil
+	}
+	var field string
+	var termsEnum TermsEnum
+	var docs DocsEnum
+	for i, term := range f.terms {
+		if i == 0 || term.Field != field {
+			field = term.Field
+			termsEnum = nil
+			if terms := fields.Terms(field); terms != nil {
+				termsEnum = terms.Iterator(nil)
+			}
+		}
+		if termsEnum == nil {
+			continue
+		}
+		ok, err := termsEnum.SeekExact(term.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		// no freq since we don't need them
+		if docs, err = termsEnum.DocsByFlags(acceptDocs, docs, DOCS_ENUM_FLAG_NONE); err != nil {
+			return nil, err
+		}
+		doc, err := docs.NextDoc()
+		for ; doc != NO_MORE_DOCS && err == nil; doc, err = docs.NextDoc() {
+			if result == nil {
+				result = util.NewFixedBitSetOf(reader.MaxDoc())
+			}
+			result.Set(doc)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if result == nil {
+		return nil, nil
+	}
+	return result, nil
+}
+
+func (f *TermsFilter) String() string {
+	var buf bytes.Buffer
+	for i, term := range f.terms {
+		if i > 0 {
+			buf.WriteRune(' ')
+		}
+		buf.WriteString(term.Field)
+		buf.WriteRune(':')
+		buf.Write(term.Bytes)
+	}
+	return buf.String()
+}
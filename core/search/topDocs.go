@@ -0,0 +1,180 @@
+package search
+
+import (
+	"container/heap"
+	"math"
+)
+
+// search/TopDocs.java
+
+/* A cursor over the hits of one shard, while merging. */
+type shardRef struct {
+	// Which shard (index into shardHits[]):
+	shardIndex int
+	// Which hit within the shard:
+	hitIndex int
+}
+
+/*
+Returns a new TopDocs, containing topN results across the provided
+TopDocs, sorting by score. Each ScoreDoc's ShardIndex is set to the
+position of its TopDocs in shardHits; ties in score are broken by
+ShardIndex, then by the position of the hit within its shard.
+
+To page through the merged hits, search each shard after the last of
+its own hits returned so far (found by ShardIndex), with
+IndexSearcher.SearchAfter(), and merge these pages again.
+*/
+func MergeTopDocs(topN int, shardHits []TopDocs) TopDocs {
+	totalHitCount := 0
+	availHitCount := 0
+	maxScore := math.NaN()
+	for _, shard := range shardHits {
+		// totalHits can be non-zero even if no hits were collected, when
+		// topN is 0
+		totalHitCount += shard.TotalHits
+		if len(shard.ScoreDocs) > 0 {
+			availHitCount += len(shard.ScoreDocs)
+			if math.IsNaN(maxScore) || shard.maxScore > maxScore {
+				maxScore = shard.maxScore
+			}
+		}
+	}
+
+	hits := mergeShards(topN, availHitCount, shardHits, func(first, second *shardRef) bool {
+		firstScore := shardHits[first.shardIndex].ScoreDocs[first.hitIndex].Score
+		secondScore := shardHits[second.shardIndex].ScoreDocs[second.hitIndex].Score
+		if firstScore != secondScore {
+			return firstScore > secondScore
+		}
+		return tieBreakShards(first, second)
+	})
+	scoreDocs := make([]*ScoreDoc, len(hits))
+	for i, ref := range hits {
+		scoreDocs[i] = shardHits[ref.shardIndex].ScoreDocs[ref.hitIndex]
+	}
+	if len(scoreDocs) == 0 {
+		maxScore = math.NaN()
+	}
+	return TopDocs{totalHitCount, scoreDocs, maxScore}
+}
+
+/*
+Returns a new TopFieldDocs, containing topN results across the
+provided TopFieldDocs, sorting by the specified Sort. Each of the
+TopFieldDocs must have been sorted by the same Sort, with its
+FieldDocs filled. Each ScoreDoc's ShardIndex is set to the position
+of its TopFieldDocs in shardHits; ties are broken by ShardIndex, then
+by the position of the hit within its shard. Pages of sharded hits
+are searched with IndexSearcher.SearchAfterSorted(), as described in
+MergeTopDocs().
+*/
+func MergeTopFieldDocs(sort *Sort, topN int, shardHits []TopFieldDocs) TopFieldDocs {
+	assert2(sort != nil, "sort must be non-nil when merging TopFieldDocs")
+	sortFields := sort.fields
+	comparators := make([]FieldComparator, len(sortFields))
+	reverseMul := make([]int, len(sortFields))
+	for i, sortField := range sortFields {
+		comparators[i] = sortField.Comparator(1, i)
+		reverseMul[i] = 1
+		if sortField.reverse {
+			reverseMul[i] = -1
+		}
+	}
+
+	totalHitCount := 0
+	availHitCount := 0
+	maxScore := math.NaN()
+	for i, shard := range shardHits {
+		totalHitCount += shard.TotalHits
+		if len(shard.ScoreDocs) > 0 {
+			availHitCount += len(shard.ScoreDocs)
+			if math.IsNaN(maxScore) || shard.maxScore > maxScore {
+				maxScore = shard.maxScore
+			}
+			assert2(len(shard.FieldDocs) == len(shard.ScoreDocs),
+				"shard %v did not return FieldDocs; was fillFields set?", i)
+			assert2(len(shard.FieldDocs[0].Fields) == len(sortFields),
+				"shard %v was not sorted by the provided Sort (expected %v sort fields, got %v)",
+				i, len(sortFields), len(shard.FieldDocs[0].Fields))
+		}
+	}
+
+	hits := mergeShards(topN, availHitCount, shardsOf(shardHits), func(first, second *shardRef) bool {
+		firstFD := shardHits[first.shardIndex].FieldDocs[first.hitIndex]
+		secondFD := shardHits[second.shardIndex].FieldDocs[second.hitIndex]
+		for i, comparator := range comparators {
+			if cmp := reverseMul[i] * comparator.CompareValues(firstFD.Fields[i], secondFD.Fields[i]); cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return tieBreakShards(first, second)
+	})
+	scoreDocs := make([]*ScoreDoc, len(hits))
+	fieldDocs := make([]*FieldDoc, len(hits))
+	for i, ref := range hits {
+		fieldDocs[i] = shardHits[ref.shardIndex].FieldDocs[ref.hitIndex]
+		scoreDocs[i] = fieldDocs[i].ScoreDoc
+	}
+	if len(scoreDocs) == 0 {
+		maxScore = math.NaN()
+	}
+	return TopFieldDocs{TopDocs{totalHitCount, scoreDocs, maxScore}, sortFields, fieldDocs}
+}
+
+func shardsOf(shardHits []TopFieldDocs) []TopDocs {
+	ans := make([]TopDocs, len(shardHits))
+	for i, shard := range shardHits {
+		ans[i] = shard.TopDocs
+	}
+	return ans
+}
+
+func tieBreakShards(first, second *shardRef) bool {
+	// Tie break: earlier shard wins
+	if first.shardIndex != second.shardIndex {
+		return first.shardIndex < second.shardIndex
+	}
+	// Tie break in same shard: resolve however the shard had resolved
+	// it:
+	assert(first.hitIndex != second.hitIndex)
+	return first.hitIndex < second.hitIndex
+}
+
+/*
+Merges the already sorted hits of all shards, given a function that
+returns true if the first hit sorts before the second one. Returns
+the cursors of the first topN hits, in order, and sets the
+ShardIndex of each of them.
+*/
+func mergeShards(topN, availHitCount int, shardHits []TopDocs,
+	before func(first, second *shardRef) bool) []*shardRef {
+
+	queue := &PriorityQueue{items: make([]interface{}, 0, len(shardHits))}
+	queue.less = func(i, j int) bool {
+		return before(queue.items[i].(*shardRef), queue.items[j].(*shardRef))
+	}
+	for i, shard := range shardHits {
+		if len(shard.ScoreDocs) > 0 {
+			queue.items = append(queue.items, &shardRef{shardIndex: i})
+		}
+	}
+	heap.Init(queue)
+
+	if availHitCount > topN {
+		availHitCount = topN
+	}
+	hits := make([]*shardRef, 0, availHitCount)
+	for len(hits) < availHitCount {
+		ref := queue.items[0].(*shardRef)
+		hits = append(hits, &shardRef{ref.shardIndex, ref.hitIndex})
+		shardHits[ref.shardIndex].ScoreDocs[ref.hitIndex].ShardIndex = ref.shardIndex
+		if ref.hitIndex++; ref.hitIndex < len(shardHits[ref.shardIndex].ScoreDocs) {
+			// Not done with this shard: re-sort its next hit
+			heap.Fix(queue, 0)
+		} else {
+			heap.Pop(queue)
+		}
+	}
+	return hits
+}
@@ -0,0 +1,281 @@
+package search
+
+import (
+	"container/heap"
+	"github.com/gzg1984/golucene/core/index"
+	"math"
+)
+
+// search/TopFieldCollector.java
+
+/*
+A Collector that sorts by SortField using FieldComparators.
+
+See the NewTopFieldCollector() method for instantiating a
+TopFieldCollector.
+*/
+type TopFieldCollector struct {
+	*abstractTopDocsCollector
+	queue       *fieldValueHitQueue
+	comparators []FieldComparator
+	reverseMul  []int
+
+	numHits           int
+	fillFields        bool
+	trackDocScores    bool
+	trackMaxScore     bool
+	docsScoredInOrder bool
+
+	// Stores the maximum score value encountered, needed for
+	// normalizing. If document scores are not tracked, this value is
+	// initialized to NaN.
+	maxScore float32
+
+	bottom    *fieldValueHitQueueEntry
+	queueFull bool
+	docBase   int
+	scorer    Scorer
+
+	// Only hits sorting after this one are collected; nil unless
+	// paging.
+	after *FieldDoc
+	// after.Doc relative to the current reader
+	afterDoc int
+	// Number of hits that entered the queue; unlike TotalHits, this
+	// doesn't count the hits of previous pages.
+	collectedHits int
+
+	// the hits with their sort values, as popped by populateResults()
+	fieldDocs []*FieldDoc
+}
+
+/*
+Creates a new TopFieldCollector from the given arguments.
+
+NOTE: The instances returned by this method pre-allocate a full
+slice of length numHits.
+
+sort is the Sort object; numHits the number of results to collect.
+If after is non-nil, only hits sorting after it are collected; its
+Fields must hold its sort values, as returned by a previous search
+with fillFields set. If fillFields is true, the sort values of the hits are returned in
+TopFieldDocs.FieldDocs. If trackDocScores is true, then document
+scores will be tracked; note that this incurs a CPU cost, as scores
+have to be computed for each document that is competitive. If
+trackMaxScore is true, then the max score is tracked too; this incurs
+a CPU cost of computing the score of every hit. docsScoredInOrder
+specifies whether documents are scored in doc Id order or not by the
+given Scorer in SetScorer().
+*/
+func NewTopFieldCollector(sort *Sort, numHits int, after *FieldDoc, fillFields,
+	trackDocScores, trackMaxScore, docsScoredInOrder bool) *TopFieldCollector {
+
+	assert2(len(sort.fields) > 0, "Sort must contain at least one field")
+	assert2(numHits > 0, "numHits must be > 0; please use TotalHitCountCollector if you just need the total hit count")
+
+	queue := newFieldValueHitQueue(sort.fields, numHits)
+	c := &TopFieldCollector{
+		queue:             queue,
+		comparators:       queue.comparators,
+		reverseMul:        queue.reverseMul,
+		numHits:           numHits,
+		fillFields:        fillFields,
+		trackDocScores:    trackDocScores,
+		trackMaxScore:     trackMaxScore,
+		docsScoredInOrder: docsScoredInOrder,
+		maxScore:          float32(math.NaN()),
+		after:             after,
+	}
+	if trackMaxScore {
+		c.maxScore = float32(math.Inf(-1))
+	}
+	if after != nil {
+		assert2(after.Fields != nil,
+			"after.Fields wasn't set; you must pass fillFields=true for the previous search")
+		assert2(len(after.Fields) == len(sort.fields),
+			"after.Fields has %v values but sort has %v", len(after.Fields), len(sort.fields))
+		for i, comparator := range c.comparators {
+			comparator.SetTopValue(after.Fields[i])
+		}
+	}
+	c.abstractTopDocsCollector = newTopDocsCollector(c, queue.PriorityQueue)
+	return c
+}
+
+func (c *TopFieldCollector) SetScorer(scorer Scorer) {
+	c.scorer = scorer
+	for _, comparator := range c.comparators {
+		comparator.SetScorer(scorer)
+	}
+}
+
+func (c *TopFieldCollector) SetNextReader(ctx *index.AtomicReaderContext) error {
+	c.docBase = ctx.DocBase
+	if c.after != nil {
+		c.afterDoc = c.after.Doc - ctx.DocBase
+	}
+	for _, comparator := range c.comparators {
+		if err := comparator.SetNextReader(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *TopFieldCollector) Collect(doc int) (err error) {
+	score := float32(math.NaN())
+	if c.trackMaxScore {
+		if score, err = c.scorer.Score(); err != nil {
+			return err
+		}
+		if score > c.maxScore {
+			c.maxScore = score
+		}
+	}
+	c.TotalHits++
+	if c.queueFull {
+		// Fastmatch: return if this hit is not competitive
+		for i, comparator := range c.comparators {
+			cmp, err := comparator.CompareBottom(doc)
+			if err != nil {
+				return err
+			}
+			if cmp *= c.reverseMul[i]; cmp < 0 {
+				// Definitely not competitive.
+				return nil
+			} else if cmp > 0 {
+				// Definitely competitive.
+				break
+			} else if i == len(c.comparators)-1 {
+				// Here cmp=0. If we're at the last comparator, this doc is
+				// not competitive when docs are visited in doc Id order,
+				// since it cannot compete with any other document in the
+				// queue. Otherwise the doc Id breaks the tie.
+				if c.docsScoredInOrder || doc+c.docBase > c.bottom.Doc {
+					return nil
+				}
+			}
+		}
+	}
+
+	if c.after != nil {
+		if collected, err := c.collectedBefore(doc); err != nil || collected {
+			return err
+		}
+	}
+
+	if c.queueFull {
+		// This hit is competitive - replace bottom element in queue &
+		// adjustTop
+		for _, comparator := range c.comparators {
+			if err = comparator.Copy(c.bottom.slot, doc); err != nil {
+				return err
+			}
+		}
+		if c.trackDocScores && !c.trackMaxScore {
+			if score, err = c.scorer.Score(); err != nil {
+				return err
+			}
+		}
+		c.updateBottom(doc, score)
+		for _, comparator := range c.comparators {
+			comparator.SetBottom(c.bottom.slot)
+		}
+	} else {
+		// Startup transient: queue hasn't gathered numHits yet
+		c.collectedHits++
+		slot := c.collectedHits - 1
+		for _, comparator := range c.comparators {
+			if err = comparator.Copy(slot, doc); err != nil {
+				return err
+			}
+		}
+		if c.trackDocScores && !c.trackMaxScore {
+			if score, err = c.scorer.Score(); err != nil {
+				return err
+			}
+		}
+		c.add(slot, doc, score)
+		if c.queueFull {
+			for _, comparator := range c.comparators {
+				comparator.SetBottom(c.bottom.slot)
+			}
+		}
+	}
+	return nil
+}
+
+/*
+Returns true if doc doesn't sort after c.after, ie it was already
+collected for a previous page.
+*/
+func (c *TopFieldCollector) collectedBefore(doc int) (bool, error) {
+	for i, comparator := range c.comparators {
+		cmp, err := comparator.CompareTop(doc)
+		if err != nil {
+			return false, err
+		}
+		if cmp *= c.reverseMul[i]; cmp > 0 {
+			return true, nil
+		} else if cmp < 0 {
+			return false, nil
+		}
+	}
+	// Same values as after: the doc Id breaks the tie
+	return doc <= c.afterDoc, nil
+}
+
+func (c *TopFieldCollector) add(slot, doc int, score float32) {
+	heap.Push(c.pq, newFieldValueHitQueueEntry(slot, c.docBase+doc, score))
+	if c.queueFull = c.collectedHits == c.numHits; c.queueFull {
+		c.bottom = c.pq.items[0].(*fieldValueHitQueueEntry)
+	}
+}
+
+func (c *TopFieldCollector) updateBottom(doc int, score float32) {
+	c.bottom.Doc = c.docBase + doc
+	c.bottom.Score = score
+	c.bottom = c.pq.updateTop().(*fieldValueHitQueueEntry)
+}
+
+func (c *TopFieldCollector) AcceptsDocsOutOfOrder() bool {
+	return !c.docsScoredInOrder
+}
+
+/*
+Only the following callback methods need to be overridden since
+TopDocs() and TopDocsRange() are implemented by
+abstractTopDocsCollector.
+*/
+func (c *TopFieldCollector) populateResults(results []*ScoreDoc, howMany int) {
+	c.fieldDocs = make([]*FieldDoc, howMany)
+	for i := howMany - 1; i >= 0; i-- {
+		entry := heap.Pop(c.pq).(*fieldValueHitQueueEntry)
+		if c.fillFields {
+			c.fieldDocs[i] = c.queue.fillFields(entry)
+		} else {
+			c.fieldDocs[i] = NewFieldDoc(entry.Doc, entry.Score, nil)
+		}
+		results[i] = c.fieldDocs[i].ScoreDoc
+	}
+}
+
+func (c *TopFieldCollector) newTopDocs(results []*ScoreDoc, start int) TopDocs {
+	if results == nil {
+		c.fieldDocs = []*FieldDoc{}
+		// Set maxScore to NaN, in case this is a maxScore tracking
+		// collector.
+		return TopDocs{c.TotalHits, []*ScoreDoc{}, math.NaN()}
+	}
+	return TopDocs{c.TotalHits, results, float64(c.maxScore)}
+}
+
+/*
+Returns the top docs that were collected by this collector, with the
+SortFields used and, if fillFields was set, the sort values of each
+hit.
+*/
+func (c *TopFieldCollector) TopFieldDocs() TopFieldDocs {
+	docs := c.TopDocs()
+	return TopFieldDocs{docs, c.queue.fields, c.fieldDocs}
+}
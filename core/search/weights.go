@@ -38,7 +38,11 @@ type Weight interface {
 	ValueForNormalization() float32
 	/** Assigns the query normalization factor and boost from parent queries to this. */
 	Normalize(norm float32, topLevelBoost float32)
-	// Scorer(*index.AtomicReaderContext, util.Bits) (Scorer, error)
+	/*
+		Returns a Scorer which scores documents in order, or nil if no
+		documents will be scored by this query.
+	*/
+	Scorer(*index.AtomicReaderContext, util.Bits) (Scorer, error)
 	/**
 	 * Returns a {@link Scorer} which scores documents in/out-of order according
 	 * to <code>scoreDocsInOrder</code>.
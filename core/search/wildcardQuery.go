@@ -0,0 +1,84 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	"github.com/gzg1984/golucene/core/util/automaton"
+)
+
+// search/WildcardQuery.java
+
+const (
+	WILDCARD_STRING = '*'  // String equality with support for wildcards
+	WILDCARD_CHAR   = '?'  // Char equality with support for wildcards
+	WILDCARD_ESCAPE = '\\' // Escape character
+)
+
+/*
+Implements the wildcard search query. Supported wildcards are *,
+which matches any character sequence (including the empty one), and
+?, which matches any single character. '\' is the escape character.
+
+Note this query can be slow, as it needs to iterate over many terms.
+In order to prevent extremely slow WildcardQueries, a Wildcard term
+should not start with the wildcard *
+*/
+type WildcardQuery struct {
+	*AutomatonQuery
+}
+
+// Constructs a query for terms matching term.
+func NewWildcardQuery(term *index.Term) *WildcardQuery {
+	ans := &WildcardQuery{}
+	ans.AutomatonQuery = newAutomatonQuery(ans, term, ToWildcardAutomaton(term), false)
+	return ans
+}
+
+// Convert Lucene wildcard syntax into an automaton.
+func ToWildcardAutomaton(wildcardquery *index.Term) *automaton.Automaton {
+	var automata []*automaton.Automaton
+
+	wildcardText := []rune(string(wildcardquery.Bytes))
+	for i := 0; i < len(wildcardText); i++ {
+		c := wildcardText[i]
+		switch c {
+		case WILDCARD_STRING:
+			automata = append(automata, automaton.MakeAnyString())
+		case WILDCARD_CHAR:
+			automata = append(automata, automaton.MakeAnyChar())
+		case WILDCARD_ESCAPE:
+			// add the next codepoint instead, if it exists
+			if i+1 < len(wildcardText) {
+				i++
+				automata = append(automata, automaton.MakeChar(int(wildcardText[i])))
+				break
+			}
+			// else fallthru, lenient parsing with a trailing \
+			fallthrough
+		default:
+			automata = append(automata, automaton.MakeChar(int(c)))
+		}
+	}
+
+	return automaton.ConcatenateN(automata)
+}
+
+// Returns the pattern term.
+func (q *WildcardQuery) Term() *index.Term {
+	return q.term
+}
+
+// Prints a user-readable version of this query.
+func (q *WildcardQuery) ToString(field string) string {
+	var buf bytes.Buffer
+	if q.Field() != field {
+		buf.WriteString(q.Field())
+		buf.WriteRune(':')
+	}
+	buf.WriteString(string(q.term.Bytes))
+	if q.boost != 1.0 {
+		buf.WriteString(fmt.Sprintf("^%v", q.boost))
+	}
+	return buf.String()
+}
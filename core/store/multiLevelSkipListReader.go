@@ -0,0 +1,257 @@
+package store
+
+import (
+	"github.com/gzg1984/golucene/core/util"
+	"math"
+)
+
+type MultiLevelSkipListReaderSPI interface {
+	// Subclasses must implement the actual skip data encoding in this
+	// method. Returns the delta of the doc of the skip entry read.
+	ReadSkipData(level int, skipStream IndexInput) (int, error)
+	// Seeks the skip entry on the given level
+	SeekChild(level int) error
+	// Copies the values of the last read skip entry on this level
+	SetLastSkipData(level int)
+}
+
+/*
+This abstract class reads skip lists with multiple levels.
+
+See MultiLevelSkipListWriter for the information about the encoding
+of the multi level skip lists.
+
+Subclasses must implement the abstract method ReadSkipData(), which
+reads the skip data of one skip entry.
+
+Note: this class was moved from package codec to store, along with
+MultiLevelSkipListWriter.
+*/
+type MultiLevelSkipListReader struct {
+	spi MultiLevelSkipListReaderSPI
+	// the maximum number of skip levels possible for this index
+	maxNumberOfSkipLevels int
+	// number of levels in this skip list
+	numberOfSkipLevels int
+
+	docCount    int
+	haveSkipped bool
+
+	// skipStream for each level
+	skipStream []IndexInput
+	// the start pointer of each skip level
+	skipPointer []int64
+	// skipInterval of each level
+	skipInterval []int
+	// number of docs skipped per level
+	numSkipped []int
+	// doc id of current skip entry per level
+	skipDoc []int
+	// doc id of last read skip entry with docId <= target
+	lastDoc int
+	// child pointer of current skip entry per level
+	childPointer []int64
+	// child pointer of last read skip entry with docId <= target
+	lastChildPointer int64
+
+	skipMultiplier int
+}
+
+/*
+Creates a MultiLevelSkipListReader, where skipInterval is the
+interval of the lowest level, and each higher level is
+skipMultiplier times sparser.
+*/
+func NewMultiLevelSkipListReader(spi MultiLevelSkipListReaderSPI,
+	skipStream IndexInput, maxSkipLevels, skipInterval,
+	skipMultiplier int) *MultiLevelSkipListReader {
+
+	ans := &MultiLevelSkipListReader{
+		spi:                   spi,
+		maxNumberOfSkipLevels: maxSkipLevels,
+		skipStream:            make([]IndexInput, maxSkipLevels),
+		skipPointer:           make([]int64, maxSkipLevels),
+		skipInterval:          make([]int, maxSkipLevels),
+		numSkipped:            make([]int, maxSkipLevels),
+		skipDoc:               make([]int, maxSkipLevels),
+		childPointer:          make([]int64, maxSkipLevels),
+		skipMultiplier:        skipMultiplier,
+	}
+	ans.skipStream[0] = skipStream
+	ans.skipInterval[0] = skipInterval
+	for i := 1; i < maxSkipLevels; i++ {
+		// cache skip intervals
+		ans.skipInterval[i] = ans.skipInterval[i-1] * skipMultiplier
+	}
+	return ans
+}
+
+// Returns the id of the doc to which the last call of SkipTo() has
+// skipped.
+func (r *MultiLevelSkipListReader) Doc() int {
+	return r.lastDoc
+}
+
+// Returns the doc id of the current skip entry on the given level.
+func (r *MultiLevelSkipListReader) SkipDoc(level int) int {
+	return r.skipDoc[level]
+}
+
+/*
+Skips entries to the first beyond the current whose document number
+is greater than or equal to target. Returns the entry's document
+number.
+*/
+func (r *MultiLevelSkipListReader) SkipTo(target int) (int, error) {
+	if !r.haveSkipped {
+		// first time, load skip levels
+		if err := r.loadSkipLevels(); err != nil {
+			return 0, err
+		}
+		r.haveSkipped = true
+	}
+
+	// walk up the levels until highest level is found that has a skip
+	// for this target
+	level := 0
+	for level < r.numberOfSkipLevels-1 && target > r.skipDoc[level+1] {
+		level++
+	}
+
+	for level >= 0 {
+		if target > r.skipDoc[level] {
+			ok, err := r.loadNextSkip(level)
+			if err != nil {
+				return 0, err
+			}
+			if !ok {
+				continue
+			}
+		} else {
+			// no more skips on this level, go down one level
+			if level > 0 && r.lastChildPointer > r.skipStream[level-1].FilePointer() {
+				if err := r.spi.SeekChild(level - 1); err != nil {
+					return 0, err
+				}
+			}
+			level--
+		}
+	}
+
+	return r.numSkipped[0] - r.skipInterval[0] - 1, nil
+}
+
+func (r *MultiLevelSkipListReader) loadNextSkip(level int) (bool, error) {
+	// we have to skip, the target document is greater than the current
+	// skip list entry
+	r.spi.SetLastSkipData(level)
+
+	r.numSkipped[level] += r.skipInterval[level]
+
+	if r.numSkipped[level] > r.docCount {
+		// this skip list is exhausted
+		r.skipDoc[level] = math.MaxInt32
+		if r.numberOfSkipLevels > level {
+			r.numberOfSkipLevels = level
+		}
+		return false, nil
+	}
+
+	// read next skip entry
+	delta, err := r.spi.ReadSkipData(level, r.skipStream[level])
+	if err != nil {
+		return false, err
+	}
+	r.skipDoc[level] += delta
+
+	if level != 0 {
+		// read the child pointer if we are not on the leaf level
+		childPointer, err := r.skipStream[level].ReadVLong()
+		if err != nil {
+			return false, err
+		}
+		r.childPointer[level] = childPointer + r.skipPointer[level-1]
+	}
+	return true, nil
+}
+
+// Seeks the skip entry on the given level
+func (r *MultiLevelSkipListReader) SeekChild(level int) (err error) {
+	if err = r.skipStream[level].Seek(r.lastChildPointer); err != nil {
+		return
+	}
+	r.numSkipped[level] = r.numSkipped[level+1] - r.skipInterval[level+1]
+	r.skipDoc[level] = r.lastDoc
+	if level > 0 {
+		var childPointer int64
+		if childPointer, err = r.skipStream[level].ReadVLong(); err != nil {
+			return
+		}
+		r.childPointer[level] = childPointer + r.skipPointer[level-1]
+	}
+	return nil
+}
+
+// Initializes the reader, for reuse on a new term.
+func (r *MultiLevelSkipListReader) Init(skipPointer int64, df int) {
+	r.skipPointer[0] = skipPointer
+	r.docCount = df
+	assert2(skipPointer >= 0 && skipPointer <= r.skipStream[0].Length(),
+		"invalid skip pointer: %v, length=%v", skipPointer, r.skipStream[0].Length())
+	for i, _ := range r.skipDoc {
+		r.skipDoc[i] = 0
+		r.numSkipped[i] = 0
+		r.childPointer[i] = 0
+	}
+
+	r.haveSkipped = false
+	for i := 1; i < r.numberOfSkipLevels; i++ {
+		r.skipStream[i] = nil
+	}
+}
+
+// Loads the skip levels
+func (r *MultiLevelSkipListReader) loadSkipLevels() (err error) {
+	if r.docCount <= r.skipInterval[0] {
+		r.numberOfSkipLevels = 1
+	} else {
+		r.numberOfSkipLevels = 1 + util.Log(int64(r.docCount/r.skipInterval[0]), r.skipMultiplier)
+	}
+
+	if r.numberOfSkipLevels > r.maxNumberOfSkipLevels {
+		r.numberOfSkipLevels = r.maxNumberOfSkipLevels
+	}
+
+	if err = r.skipStream[0].Seek(r.skipPointer[0]); err != nil {
+		return
+	}
+
+	for i := r.numberOfSkipLevels - 1; i > 0; i-- {
+		// the length of the current level
+		var length int64
+		if length, err = r.skipStream[0].ReadVLong(); err != nil {
+			return
+		}
+
+		// the start pointer of the current level
+		r.skipPointer[i] = r.skipStream[0].FilePointer()
+		// clone this stream, it is already at the start of the current
+		// level
+		r.skipStream[i] = r.skipStream[0].Clone()
+
+		// move base stream beyond the current level
+		if err = r.skipStream[0].Seek(r.skipStream[0].FilePointer() + length); err != nil {
+			return
+		}
+	}
+
+	// use base stream for the lowest level
+	r.skipPointer[0] = r.skipStream[0].FilePointer()
+	return nil
+}
+
+// Copies the values of the last read skip entry on this level
+func (r *MultiLevelSkipListReader) SetLastSkipData(level int) {
+	r.lastDoc = r.skipDoc[level]
+	r.lastChildPointer = r.childPointer[level]
+}
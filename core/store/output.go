@@ -47,6 +47,13 @@ func (ios *IndexOutputStream) WriteLong(l int64) *IndexOutputStream {
 	return ios
 }
 
+func (ios *IndexOutputStream) WriteVLong(l int64) *IndexOutputStream {
+	if ios.err == nil {
+		ios.err = ios.out.WriteVLong(l)
+	}
+	return ios
+}
+
 func (ios *IndexOutputStream) WriteByte(b byte) *IndexOutputStream {
 	if ios.err == nil {
 		ios.err = ios.out.WriteByte(b)
@@ -356,6 +356,10 @@ type RAMInputStream struct {
 	bufferLength   int
 }
 
+func NewRAMInputStream(name string, f *RAMFile) (in *RAMInputStream, err error) {
+	return newRAMInputStream(name, f)
+}
+
 func newRAMInputStream(name string, f *RAMFile) (in *RAMInputStream, err error) {
 	if !(f.length/BUFFER_SIZE < math.MaxInt32) {
 		return nil, errors.New(fmt.Sprintf("RAMInputStream too large length=%v: %v", f.length, name))
@@ -42,10 +42,13 @@ func (lock *SimpleFSLock) Obtain() (ok bool, err error) {
 		return
 	}
 	var f *os.File
-	if f, err = os.Create(lock.file); err == nil {
+	if f, err = os.OpenFile(lock.file, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644); err == nil {
 		fmt.Printf("File '%v' is created.\n", f.Name())
 		ok = true
 		defer f.Close()
+	} else if os.IsExist(err) {
+		// somebody else holds the lock
+		err = nil
 	}
 	return
 
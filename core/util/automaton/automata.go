@@ -14,17 +14,40 @@ func MakeEmpty() *Automaton {
 }
 
 // Returns a new (deterministic) automaton that accepts only the empty string.
-func makeEmptyString() *Automaton {
-	panic("not implemented yet")
+func MakeEmptyString() *Automaton {
+	a := newEmptyAutomaton()
+	a.createState()
+	a.setAccept(0, true)
+	return a
+}
+
+// Returns a new (deterministic) automaton that accepts all strings.
+func MakeAnyString() *Automaton {
+	a := newEmptyAutomaton()
+	s := a.createState()
+	a.setAccept(s, true)
+	a.addTransitionRange(s, s, MIN_CODE_POINT, unicode.MaxRune)
+	a.finishState()
+	return a
+}
+
+// Returns a new (deterministic) automaton that accepts all binary terms.
+func MakeAnyBinary() *Automaton {
+	a := newEmptyAutomaton()
+	s := a.createState()
+	a.setAccept(s, true)
+	a.addTransitionRange(s, s, 0, 255)
+	a.finishState()
+	return a
 }
 
 // Returns a new (deterministic) automaton that accepts any single codepoint.
-func makeAnyChar() *Automaton {
+func MakeAnyChar() *Automaton {
 	return makeCharRange(MIN_CODE_POINT, unicode.MaxRune)
 }
 
 // Returns a new (deterministic) automaton that accepts a single codepoint of the given value.
-func makeChar(c int) *Automaton {
+func MakeChar(c int) *Automaton {
 	return makeCharRange(c, c)
 }
 
@@ -48,7 +71,7 @@ func makeCharRange(min, max int) *Automaton {
 
 // L237
 // Returns a new (deterministic) automaton that accepts the single given string
-func makeString(s string) *Automaton {
+func MakeString(s string) *Automaton {
 	a := newEmptyAutomaton()
 	lastState := a.createState()
 	for _, r := range s {
@@ -66,6 +89,28 @@ func makeString(s string) *Automaton {
 	return a
 }
 
+/*
+Returns a new (deterministic) automaton that accepts the single given
+binary term.
+*/
+func MakeBinary(term []byte) *Automaton {
+	a := newEmptyAutomaton()
+	lastState := a.createState()
+	for _, b := range term {
+		state := a.createState()
+		a.addTransition(lastState, state, int(b))
+		lastState = state
+	}
+
+	a.setAccept(lastState, true)
+	a.finishState()
+
+	assert(a.deterministic)
+	assert(!hasDeadStates(a))
+
+	return a
+}
+
 // L271
 /*
 Returns a new (deterministic and minimal) automaton that accepts the
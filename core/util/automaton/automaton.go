@@ -68,10 +68,10 @@ func (a *Automaton) sortedTransitions() [][]*Transition {
 	numStates := a.numStates()
 	transitions := make([][]*Transition, numStates)
 	for s := 0; s < numStates; s++ {
-		numTransitions := a.numTransitions(s)
+		numTransitions := a.NumTransitions(s)
 		transitions[s] = make([]*Transition, numTransitions)
 		for t := 0; t < numTransitions; t++ {
-			transition := newTransition()
+			transition := NewTransition()
 			a.transition(s, t, transition)
 			transitions[s][t] = transition
 		}
@@ -119,10 +119,10 @@ state must already have all transitions added because this method
 simply copies those same transitions over to source.
 */
 func (a *Automaton) addEpsilon(source, dest int) {
-	t := newTransition()
-	count := a.initTransition(dest, t)
+	t := NewTransition()
+	count := a.InitTransition(dest, t)
 	for i := 0; i < count; i++ {
-		a.nextTransition(t)
+		a.NextTransition(t)
 		a.addTransitionRange(source, t.dest, t.min, t.max)
 	}
 	if a.IsAccept(dest) {
@@ -248,7 +248,7 @@ func (a *Automaton) numStates() int {
 }
 
 /* How many transitions this state has. */
-func (a *Automaton) numTransitions(state int) int {
+func (a *Automaton) NumTransitions(state int) int {
 	if count := a.states[2*state+1]; count != -1 {
 		return count
 	}
@@ -332,18 +332,18 @@ func (s minMaxDestSorter) Less(i, j int) bool {
 
 /*
 Initialize the provided Transition to iterate through all transitions
-leaving the specified state. You must call nextTransition() to get
+leaving the specified state. You must call NextTransition() to get
 each transition. Returns the number of transitions leaving this tate.
 */
-func (a *Automaton) initTransition(state int, t *Transition) int {
+func (a *Automaton) InitTransition(state int, t *Transition) int {
 	assert2(state < a.numStates(), "state=%v nextState=%v", state, a.numStates())
 	t.source = state
 	t.transitionUpto = a.states[2*state]
-	return a.numTransitions(state)
+	return a.NumTransitions(state)
 }
 
 /* Iterate to the next transition after the provided one */
-func (a *Automaton) nextTransition(t *Transition) {
+func (a *Automaton) NextTransition(t *Transition) {
 	// make sure there is still a transition left
 	assert((t.transitionUpto + 3 - a.states[2*t.source]) <= 3*a.states[2*t.source+1])
 	t.dest = a.transitions[t.transitionUpto]
@@ -501,6 +501,23 @@ func (b *AutomatonBuilder) isAccept(state int) bool {
 	return b.a.IsAccept(state)
 }
 
+/* Add a [virtual] epsilon transition between source and dest. */
+func (b *AutomatonBuilder) addEpsilon(source, dest int) {
+	for upto, limit := 0, len(b.transitions); upto < limit; upto += 4 {
+		if b.transitions[upto] == dest {
+			b.addTransitionRange(source, b.transitions[upto+1],
+				b.transitions[upto+2], b.transitions[upto+3])
+		}
+	}
+	if b.isAccept(dest) {
+		b.setAccept(source, true)
+	}
+}
+
+func (b *AutomatonBuilder) numStates() int {
+	return b.a.numStates()
+}
+
 func (b *AutomatonBuilder) copy(other *Automaton) {
 	offset := b.a.numStates()
 	otherNumStates := other.numStates()
@@ -508,11 +525,11 @@ func (b *AutomatonBuilder) copy(other *Automaton) {
 		newState := b.createState()
 		b.setAccept(newState, other.IsAccept(s))
 	}
-	t := newTransition()
+	t := NewTransition()
 	for s := 0; s < otherNumStates; s++ {
-		count := other.initTransition(s, t)
+		count := other.InitTransition(s, t)
 		for i := 0; i < count; i++ {
-			other.nextTransition(t)
+			other.NextTransition(t)
 			b.addTransitionRange(offset+s, offset+t.dest, t.min, t.max)
 		}
 	}
@@ -18,19 +18,19 @@ func TestRegExpToAutomaton(t *testing.T) {
 }
 
 func TestMinusSimple(t *testing.T) {
-	assert(sameLanguage(makeChar('b'), minus(makeCharRange('a', 'b'), makeChar('a'))))
-	assert(sameLanguage(MakeEmpty(), minus(makeChar('a'), makeChar('a'))))
+	assert(sameLanguage(MakeChar('b'), minus(makeCharRange('a', 'b'), MakeChar('a'))))
+	assert(sameLanguage(MakeEmpty(), minus(MakeChar('a'), MakeChar('a'))))
 }
 
 func TestComplementSimple(t *testing.T) {
-	a := makeChar('a')
+	a := MakeChar('a')
 	assert(sameLanguage(a, complement(complement(a))))
 }
 
 func TestDeterminizeSimple(t *testing.T) {
 	a1 := complement(NewRegExpWithFlag("-", NONE).ToAutomaton())
 	a2 := NewRegExpWithFlag("ݖ|+", NONE).ToAutomaton()
-	a := concatenate(a1, a2)
+	a := Concatenate(a1, a2)
 	a = removeDeadStates(a)
 	a = determinize(a)
 	assert(a.numStates() == 4)
@@ -154,7 +154,7 @@ func randomAutomaton(r *rand.Rand) *Automaton {
 	switch r.Intn(4) {
 	case 0:
 		// fmt.Println("DEBUG way 0")
-		return concatenate(a1, a2)
+		return Concatenate(a1, a2)
 	case 1:
 		// fmt.Println("DEBUG way 1")
 		return union(a1, a2)
@@ -238,7 +238,7 @@ func determinizeSimple(a *Automaton, initialset map[int]bool) *Automaton {
 	b := newAutomatonBuilder()
 	b.createState()
 	newstate[hash(initialset)] = 0
-	t := newTransition()
+	t := NewTransition()
 	for worklist.Len() > 0 {
 		s := worklist.Remove(worklist.Front()).(map[int]bool)
 		r := newstate[hash(s)]
@@ -251,9 +251,9 @@ func determinizeSimple(a *Automaton, initialset map[int]bool) *Automaton {
 		for n, point := range points {
 			p := make(map[int]bool)
 			for q, _ := range s {
-				count := a.initTransition(q, t)
+				count := a.InitTransition(q, t)
 				for i := 0; i < count; i++ {
-					a.nextTransition(t)
+					a.NextTransition(t)
 					if t.min <= point && point <= t.max {
 						p[t.dest] = true
 					}
@@ -0,0 +1,167 @@
+package automaton
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// util/automaton/CompiledAutomaton.java
+
+// Automata classification for query optimization.
+type AutomatonType int
+
+const (
+	AUTOMATON_TYPE_NONE   = AutomatonType(1) // Automaton that accepts no strings.
+	AUTOMATON_TYPE_ALL    = AutomatonType(2) // Automaton that accepts all possible strings.
+	AUTOMATON_TYPE_SINGLE = AutomatonType(3) // Automaton that accepts only a single fixed string.
+	AUTOMATON_TYPE_NORMAL = AutomatonType(4) // Catch-all for any other automata.
+)
+
+func (t AutomatonType) String() string {
+	switch t {
+	case AUTOMATON_TYPE_NONE:
+		return "NONE"
+	case AUTOMATON_TYPE_ALL:
+		return "ALL"
+	case AUTOMATON_TYPE_SINGLE:
+		return "SINGLE"
+	case AUTOMATON_TYPE_NORMAL:
+		return "NORMAL"
+	}
+	panic(fmt.Sprintf("unknown automaton type: %v", int(t)))
+}
+
+/*
+Immutable class holding compiled details for a given Automaton. The
+Automaton is deterministic, must not have dead states but is not
+necessarily minimal.
+*/
+type CompiledAutomaton struct {
+	// If simplify is true this will be the "simplified" type; else,
+	// this is NORMAL
+	Type AutomatonType
+	// For SINGLE this is the singleton term.
+	Term []byte
+	/*
+		Matcher for quickly determining if a []byte is accepted. only
+		valid for AUTOMATON_TYPE_NORMAL.
+	*/
+	RunAutomaton *ByteRunAutomaton
+	/*
+		Two dimensional array of transitions, indexed by state number
+		for traversal. The state numbering is consistent with
+		RunAutomaton. Only valid for AUTOMATON_TYPE_NORMAL.
+	*/
+	Automaton *Automaton
+	/*
+		Shared common suffix accepted by the automaton. Only valid for
+		AUTOMATON_TYPE_NORMAL, and only when the automaton accepts an
+		infinite language.
+	*/
+	CommonSuffixRef []byte
+	/*
+		Indicates if the automaton accepts a finite set of strings.
+		Only valid for AUTOMATON_TYPE_NORMAL.
+	*/
+	Finite bool
+}
+
+/*
+Create this, passing simplify=true and finite=nil, so that we try to
+simplify the automaton and determine if it is finite.
+*/
+func NewCompiledAutomaton(a *Automaton) *CompiledAutomaton {
+	return NewCompiledAutomatonWith(a, nil, true, false)
+}
+
+/*
+Create this. If finite is nil, we use isFinite() to determine
+whether it is finite. If simplify is true, we run possibly expensive
+operations to determine if the automaton is one the cases in
+AutomatonType. If isBinary is true, the input automaton is already
+byte-based (each label is a byte); else it's UTF-32 and will be
+converted to UTF-8 first.
+*/
+func NewCompiledAutomatonWith(a *Automaton, finite *bool,
+	simplify, isBinary bool) *CompiledAutomaton {
+
+	if a.numStates() == 0 {
+		a = newEmptyAutomaton()
+		a.createState()
+	}
+
+	ans := &CompiledAutomaton{Type: AUTOMATON_TYPE_NORMAL}
+
+	if simplify {
+		// Test whether the automaton is a "simple" form and if so,
+		// don't create a runAutomaton. Note that on a large automaton
+		// these tests could be costly:
+
+		if isEmpty(a) {
+			// matches nothing
+			ans.Type = AUTOMATON_TYPE_NONE
+			return ans
+		}
+
+		// NOTE: only approximate, because automaton may not be minimal:
+		a = determinize(a)
+		maxLabel := int(unicode.MaxRune)
+		if isBinary {
+			maxLabel = 0xff
+		}
+		if isTotal(a, 0, maxLabel) {
+			// matches all possible strings
+			ans.Type = AUTOMATON_TYPE_ALL
+			return ans
+		}
+
+		if singleton := getSingleton(a); singleton != nil {
+			// matches a fixed string
+			ans.Type = AUTOMATON_TYPE_SINGLE
+			if isBinary {
+				ans.Term = make([]byte, len(singleton))
+				for i, label := range singleton {
+					ans.Term[i] = byte(label)
+				}
+			} else {
+				ans.Term = make([]byte, 0, len(singleton))
+				buf := make([]byte, utf8.UTFMax)
+				for _, label := range singleton {
+					n := utf8.EncodeRune(buf, rune(label))
+					ans.Term = append(ans.Term, buf[:n]...)
+				}
+			}
+			return ans
+		}
+	}
+
+	if finite == nil {
+		ans.Finite = isFinite(a)
+	} else {
+		ans.Finite = *finite
+	}
+
+	binary := a
+	if !isBinary {
+		binary = NewUTF32ToUTF8().Convert(a)
+	}
+
+	if !ans.Finite {
+		// NOTE: this is a very costly operation! We should test if
+		// it's really warranted in practice...
+		ans.CommonSuffixRef = getCommonSuffixBytesRef(binary)
+	}
+
+	// This will determinize the binary automaton for us:
+	ans.RunAutomaton = NewByteRunAutomaton(binary, true)
+	ans.Automaton = ans.RunAutomaton.automaton
+	return ans
+}
+
+func (ca *CompiledAutomaton) String() string {
+	if ca.Type == AUTOMATON_TYPE_SINGLE {
+		return fmt.Sprintf("CompiledAutomaton(type=%v, term=%v)", ca.Type, string(ca.Term))
+	}
+	return fmt.Sprintf("CompiledAutomaton(type=%v)", ca.Type)
+}
@@ -0,0 +1,63 @@
+package automaton
+
+import (
+	"testing"
+)
+
+func TestCompiledAutomatonTypes(t *testing.T) {
+	if c := NewCompiledAutomaton(MakeEmpty()); c.Type != AUTOMATON_TYPE_NONE {
+		t.Errorf("empty: expected NONE, got %v", c.Type)
+	}
+	if c := NewCompiledAutomaton(MakeAnyString()); c.Type != AUTOMATON_TYPE_ALL {
+		t.Errorf("any string: expected ALL, got %v", c.Type)
+	}
+	c := NewCompiledAutomaton(MakeString("fruit"))
+	if c.Type != AUTOMATON_TYPE_SINGLE || string(c.Term) != "fruit" {
+		t.Errorf("string: expected SINGLE fruit, got %v", c)
+	}
+	c = NewCompiledAutomaton(NewRegExp("s[a-z]nar").ToAutomaton())
+	if c.Type != AUTOMATON_TYPE_NORMAL || !c.Finite {
+		t.Errorf("regexp: expected finite NORMAL, got %v", c)
+	}
+	c = NewCompiledAutomaton(Concatenate(MakeAnyString(), MakeString("bat")))
+	if c.Type != AUTOMATON_TYPE_NORMAL || c.Finite || string(c.CommonSuffixRef) != "bat" {
+		t.Errorf("suffix: expected infinite NORMAL with suffix bat, got %v suffix=%v",
+			c, string(c.CommonSuffixRef))
+	}
+}
+
+func TestByteRunAutomatonUTF8(t *testing.T) {
+	ra := NewByteRunAutomaton(NewRegExp("gr[äa]n.*").ToAutomaton(), false)
+	for _, s := range []string{"gran", "grän", "gränsen", "grandé", "gran€𝄞"} {
+		if !ra.Run([]byte(s)) {
+			t.Errorf("%v should be accepted", s)
+		}
+	}
+	for _, s := range []string{"grn", "grön", "agran", ""} {
+		if ra.Run([]byte(s)) {
+			t.Errorf("%v should not be accepted", s)
+		}
+	}
+}
+
+func TestRegExpRepeatRange(t *testing.T) {
+	ra := NewByteRunAutomaton(NewRegExp("ab{2,3}c").ToAutomaton(), false)
+	for s, expected := range map[string]bool{
+		"abc": false, "abbc": true, "abbbc": true, "abbbbc": false,
+	} {
+		if ra.Run([]byte(s)) != expected {
+			t.Errorf("%v: expected %v", s, expected)
+		}
+	}
+}
+
+func TestRegExpConcatenatedString(t *testing.T) {
+	re := NewRegExp("s[a-z]nar")
+	if s := re.String(); s != `\s[\a-\z]"nar"` {
+		t.Errorf("unexpected regexp: %v", s)
+	}
+	ra := NewByteRunAutomaton(re.ToAutomaton(), false)
+	if !ra.Run([]byte("sonar")) || ra.Run([]byte("son")) {
+		t.Error("s[a-z]nar should accept sonar only")
+	}
+}
@@ -0,0 +1,149 @@
+package automaton
+
+import (
+	"unicode"
+)
+
+// util/automaton/LevenshteinAutomata.java
+
+// Maximum edit distance this type can generate an automaton for.
+const MAXIMUM_SUPPORTED_DISTANCE = 2
+
+/*
+Type to construct DFAs that match a word within some edit distance.
+
+Implements the algorithm described in: Schulz and Mihov: Fast String
+Correction with Levenshtein Automata.
+
+Unlike Lucene, which precomputes parametric descriptions for each
+supported distance, the automaton is built here as a nondeterministic
+automaton whose states track (position in word, edits used so far),
+and then determinized. The accepted language is the same.
+*/
+type LevenshteinAutomata struct {
+	// the input word, as code points
+	word []int
+	// true to count a transposition of adjacent characters as one
+	// edit (Damerau-Levenshtein), rather than two
+	withTranspositions bool
+}
+
+/*
+Create a new LevenshteinAutomata for some input string. Optionally
+count transpositions as a primitive edit.
+*/
+func NewLevenshteinAutomata(input string, withTranspositions bool) *LevenshteinAutomata {
+	word := make([]int, 0, len(input))
+	for _, r := range input {
+		word = append(word, int(r))
+	}
+	return &LevenshteinAutomata{word, withTranspositions}
+}
+
+/*
+Compute a DFA that accepts all strings within an edit distance of n.
+
+All automata have the following properties: they are deterministic,
+have no transitions to dead states, and are not minimal.
+
+Returns nil if the edit distance is not supported.
+*/
+func (lev *LevenshteinAutomata) ToAutomaton(n int) *Automaton {
+	return lev.ToAutomatonWithPrefix(n, "")
+}
+
+/*
+Compute a DFA that accepts all strings within an edit distance of n,
+matching the specified exact prefix.
+
+Returns nil if the edit distance is not supported.
+*/
+func (lev *LevenshteinAutomata) ToAutomatonWithPrefix(n int, prefix string) *Automaton {
+	assert(n >= 0)
+	if n > MAXIMUM_SUPPORTED_DISTANCE {
+		return nil
+	}
+
+	if n == 0 {
+		var b []rune
+		for _, c := range lev.word {
+			b = append(b, rune(c))
+		}
+		return MakeString(prefix + string(b))
+	}
+
+	a := lev.buildNFA(n)
+	if prefix != "" {
+		a = Concatenate(MakeString(prefix), a)
+	}
+	return determinize(a)
+}
+
+/*
+Builds the nondeterministic automaton. State (i, e) means i
+characters of the word have been consumed using e edits. Deletions
+are folded into the outgoing transitions (a deletion consumes a word
+character without reading input), so no epsilon transitions are
+needed. With transpositions, an extra state (i, e) remembers that the
+character at i+1 was read, so that reading the character at i next
+completes the swap.
+*/
+func (lev *LevenshteinAutomata) buildNFA(n int) *Automaton {
+	word := lev.word
+	length := len(word)
+
+	b := newAutomatonBuilder()
+	states := make([][]int, length+1)
+	for i, _ := range states {
+		states[i] = make([]int, n+1)
+		for e, _ := range states[i] {
+			states[i][e] = b.createState()
+		}
+	}
+	var swaps [][]int
+	if lev.withTranspositions && length > 1 {
+		swaps = make([][]int, length-1)
+		for i, _ := range swaps {
+			swaps[i] = make([]int, n)
+			for e, _ := range swaps[i] {
+				swaps[i][e] = b.createState()
+			}
+		}
+	}
+
+	for i := 0; i <= length; i++ {
+		for e := 0; e <= n; e++ {
+			source := states[i][e]
+			// walk the deletion closure of (i, e)
+			for p, f := i, e; p <= length && f <= n; p, f = p+1, f+1 {
+				if p == length {
+					b.setAccept(source, true)
+				} else {
+					// match
+					b.addTransitionRange(source, states[p+1][f], word[p], word[p])
+				}
+				if f == n {
+					continue
+				}
+				// insertion
+				b.addTransitionRange(source, states[p][f+1], MIN_CODE_POINT, unicode.MaxRune)
+				if p < length {
+					// substitution
+					b.addTransitionRange(source, states[p+1][f+1], MIN_CODE_POINT, unicode.MaxRune)
+				}
+				if swaps != nil && p+1 < length {
+					// transposition, first half
+					b.addTransitionRange(source, swaps[p][f], word[p+1], word[p+1])
+				}
+			}
+		}
+	}
+	for i, _ := range swaps {
+		for e, swap := range swaps[i] {
+			// transposition, second half
+			b.addTransitionRange(swap, states[i+2][e+1], word[i], word[i])
+		}
+	}
+
+	return b.finish()
+}
@@ -0,0 +1,89 @@
+package automaton
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Optimal string alignment distance, counting a transposition of
+// adjacent characters as one edit if transpositions is true.
+func editDistance(s1, s2 []rune, transpositions bool) int {
+	d := make([][]int, len(s1)+1)
+	for i, _ := range d {
+		d[i] = make([]int, len(s2)+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= len(s2); j++ {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s1); i++ {
+		for j := 1; j <= len(s2); j++ {
+			cost := 1
+			if s1[i-1] == s2[j-1] {
+				cost = 0
+			}
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if transpositions && i > 1 && j > 1 &&
+				s1[i-1] == s2[j-2] && s1[i-2] == s2[j-1] {
+				if v := d[i-2][j-2] + 1; v < d[i][j] {
+					d[i][j] = v
+				}
+			}
+		}
+	}
+	return d[len(s1)][len(s2)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func randomWord(r *rand.Rand, alphabet []rune, maxLength int) []rune {
+	ans := make([]rune, r.Intn(maxLength+1))
+	for i, _ := range ans {
+		ans[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return ans
+}
+
+func TestLevenshteinAutomata(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	alphabet := []rune("abcé")
+	for iter := 0; iter < 30; iter++ {
+		word := randomWord(r, alphabet, 5)
+		for _, transpositions := range []bool{false, true} {
+			lev := NewLevenshteinAutomata(string(word), transpositions)
+			for n := 0; n <= MAXIMUM_SUPPORTED_DISTANCE; n++ {
+				ra := NewByteRunAutomaton(lev.ToAutomaton(n), false)
+				for j := 0; j < 100; j++ {
+					other := randomWord(r, alphabet, 7)
+					expected := editDistance(word, other, transpositions) <= n
+					if ra.Run([]byte(string(other))) != expected {
+						t.Fatalf("word=%v other=%v n=%v transpositions=%v: expected %v",
+							string(word), string(other), n, transpositions, expected)
+					}
+				}
+			}
+		}
+	}
+	if NewLevenshteinAutomata("bat", true).ToAutomaton(MAXIMUM_SUPPORTED_DISTANCE+1) != nil {
+		t.Error("unsupported distance should give nil")
+	}
+}
+
+func TestLevenshteinAutomataPrefix(t *testing.T) {
+	ra := NewByteRunAutomaton(NewLevenshteinAutomata("it", true).ToAutomatonWithPrefix(1, "fru"), false)
+	for s, expected := range map[string]bool{
+		"fruit": true, "fruti": true, "frit": false, "frui": true, "fruits": true, "bruit": false,
+	} {
+		if ra.Run([]byte(s)) != expected {
+			t.Errorf("%v: expected %v", s, expected)
+		}
+	}
+}
@@ -17,13 +17,13 @@ func minimize(a *Automaton) *Automaton {
 
 // Minimizes the given automaton using Hopcroft's alforithm.
 func minimizeHopcroft(a *Automaton) *Automaton {
-	if a.numStates() == 0 || !a.IsAccept(0) && a.numTransitions(0) == 0 {
+	if a.numStates() == 0 || !a.IsAccept(0) && a.NumTransitions(0) == 0 {
 		// fastmatch for common case
 		return newEmptyAutomaton()
 	}
 	a = determinize(a)
-	if a.numTransitions(0) == 1 {
-		t := newTransition()
+	if a.NumTransitions(0) == 1 {
+		t := NewTransition()
 		a.transition(0, 0, t)
 		if t.dest == 0 && t.min == MIN_CODE_POINT &&
 			t.max == unicode.MaxRune {
@@ -157,7 +157,7 @@ func minimizeHopcroft(a *Automaton) *Automaton {
 	}
 
 	ans := newEmptyAutomaton()
-	t := newTransition()
+	t := NewTransition()
 	// fmt.Printf("  k=%v\n", k)
 
 	// make a new state for each equivalence class, set initial state
@@ -196,9 +196,9 @@ func minimizeHopcroft(a *Automaton) *Automaton {
 
 	// build transitions and set acceptance
 	for n := 0; n < k; n++ {
-		numTransitions := a.initTransition(stateRep[n], t)
+		numTransitions := a.InitTransition(stateRep[n], t)
 		for i := 0; i < numTransitions; i++ {
-			a.nextTransition(t)
+			a.NextTransition(t)
 			// fmt.Println("  add trans")
 			ans.addTransitionRange(n, stateMap[t.dest], t.min, t.max)
 		}
@@ -79,11 +79,11 @@ func TestAgainstBrzozowski(t *testing.T) {
 
 		sum1 := 0
 		for s := 0; s < a.numStates(); s++ {
-			sum1 += a.numTransitions(s)
+			sum1 += a.NumTransitions(s)
 		}
 		sum2 := 0
 		for s := 0; s < b.numStates(); s++ {
-			sum2 += b.numTransitions(s)
+			sum2 += b.NumTransitions(s)
 		}
 		It(t).Should("have same number of transitions (%v vs %v)", sum1, sum2).
 			Verify(sum1 == sum2)
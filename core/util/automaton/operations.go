@@ -15,8 +15,8 @@ languages of the given automata.
 
 Complexity: linear in total number of states.
 */
-func concatenate(a1, a2 *Automaton) *Automaton {
-	return concatenateN([]*Automaton{a1, a2})
+func Concatenate(a1, a2 *Automaton) *Automaton {
+	return ConcatenateN([]*Automaton{a1, a2})
 }
 
 /*
@@ -25,7 +25,7 @@ languages of the given automata.
 
 Complexity: linear in total number of states.
 */
-func concatenateN(l []*Automaton) *Automaton {
+func ConcatenateN(l []*Automaton) *Automaton {
 	ans := newEmptyAutomaton()
 
 	// first pass: create all states
@@ -43,7 +43,7 @@ func concatenateN(l []*Automaton) *Automaton {
 	// second pass: add transitions, carefully linking accept
 	// states of A to init state of next A:
 	stateOffset := 0
-	t := newTransition()
+	t := NewTransition()
 	for i, a := range l {
 		numStates := a.numStates()
 
@@ -53,9 +53,9 @@ func concatenateN(l []*Automaton) *Automaton {
 		}
 
 		for s := 0; s < numStates; s++ {
-			numTransitions := a.initTransition(s, t)
+			numTransitions := a.InitTransition(s, t)
 			for j := 0; j < numTransitions; j++ {
-				a.nextTransition(t)
+				a.NextTransition(t)
 				ans.addTransitionRange(stateOffset+s, stateOffset+t.dest, t.min, t.max)
 			}
 
@@ -66,9 +66,9 @@ func concatenateN(l []*Automaton) *Automaton {
 				for {
 					if followA != nil {
 						// adds a "virtual" epsilon transition:
-						numTransitions = followA.initTransition(0, t)
+						numTransitions = followA.InitTransition(0, t)
 						for j := 0; j < numTransitions; j++ {
-							followA.nextTransition(t)
+							followA.NextTransition(t)
 							ans.addTransitionRange(stateOffset+s, followOffset+numStates+t.dest, t.min, t.max)
 						}
 						if followA.IsAccept(0) {
@@ -137,19 +137,19 @@ func repeat(a *Automaton) *Automaton {
 	b.setAccept(0, true)
 	b.copy(a)
 
-	t := newTransition()
-	count := a.initTransition(0, t)
+	t := NewTransition()
+	count := a.InitTransition(0, t)
 	for i := 0; i < count; i++ {
-		a.nextTransition(t)
+		a.NextTransition(t)
 		b.addTransitionRange(0, t.dest+1, t.min, t.max)
 	}
 
 	numStates := a.numStates()
 	for s := 0; s < numStates; s++ {
 		if a.IsAccept(s) {
-			count = a.initTransition(0, t)
+			count = a.InitTransition(0, t)
 			for i := 0; i < count; i++ {
-				a.nextTransition(t)
+				a.NextTransition(t)
 				b.addTransitionRange(s+1, t.dest+1, t.min, t.max)
 			}
 		}
@@ -174,7 +174,57 @@ func repeatMin(a *Automaton, min int) *Automaton {
 		min--
 	}
 	as = append(as, repeat(a))
-	return concatenateN(as)
+	return ConcatenateN(as)
+}
+
+/*
+Returns an automaton that accepts between min and max (including
+both) concatenated repetitions of the language of the given
+automaton.
+
+Complexity: linear in number of states and in min and max.
+*/
+func repeatMinMax(a *Automaton, min, max int) *Automaton {
+	if min > max {
+		return MakeEmpty()
+	}
+
+	var b *Automaton
+	switch min {
+	case 0:
+		b = MakeEmptyString()
+	case 1:
+		b = newEmptyAutomaton()
+		b.copy(a)
+	default:
+		as := make([]*Automaton, min)
+		for i, _ := range as {
+			as[i] = a
+		}
+		b = ConcatenateN(as)
+	}
+
+	prevAcceptStates := acceptStates(b, 0)
+	builder := newAutomatonBuilder()
+	builder.copy(b)
+	for i := min; i < max; i++ {
+		numStates := builder.numStates()
+		builder.copy(a)
+		for _, s := range prevAcceptStates {
+			builder.addEpsilon(s, numStates)
+		}
+		prevAcceptStates = acceptStates(a, numStates)
+	}
+
+	return builder.finish()
+}
+
+func acceptStates(a *Automaton, offset int) []int {
+	var ans []int
+	for s := a.isAccept.NextSetBit(0); s != -1; s = a.isAccept.NextSetBit(s + 1) {
+		ans = append(ans, offset+int(s))
+	}
+	return ans
 }
 
 /*
@@ -553,7 +603,7 @@ func determinize(a *Automaton) *Automaton {
 	// like sorted map[int]int
 	statesSet := newSortedIntSet(5)
 
-	t := newTransition()
+	t := NewTransition()
 
 	for worklist.Len() > 0 {
 		s := worklist.Remove(worklist.Front()).(*FrozenIntSet)
@@ -561,10 +611,10 @@ func determinize(a *Automaton) *Automaton {
 
 		// Collate all outgoing transitions by min/1+max
 		for _, s0 := range s.values {
-			numTransitions := a.numTransitions(s0)
-			a.initTransition(s0, t)
+			numTransitions := a.NumTransitions(s0)
+			a.InitTransition(s0, t)
 			for j := 0; j < numTransitions; j++ {
-				a.nextTransition(t)
+				a.NextTransition(t)
 				points.add(t)
 			}
 		}
@@ -647,7 +697,7 @@ func isEmpty(a *Automaton) bool {
 		// common case: no states
 		return true
 	}
-	if !a.IsAccept(0) && a.numTransitions(0) == 0 {
+	if !a.IsAccept(0) && a.NumTransitions(0) == 0 {
 		// common case: just one initial state
 		return true
 	}
@@ -661,15 +711,15 @@ func isEmpty(a *Automaton) bool {
 	workList.PushBack(0)
 	seen.Set(0)
 
-	t := newTransition()
+	t := NewTransition()
 	for workList.Len() > 0 {
 		state := workList.Remove(workList.Front()).(int)
 		if a.IsAccept(state) {
 			return false
 		}
-		count := a.initTransition(state, t)
+		count := a.InitTransition(state, t)
 		for i := 0; i < count; i++ {
-			a.nextTransition(t)
+			a.NextTransition(t)
 			if !seen.Get(int64(t.dest)) {
 				workList.PushBack(t.dest)
 				seen.Set(int64(t.dest))
@@ -727,12 +777,12 @@ func liveStatesFromInitial(a *Automaton) *util.OpenBitSet {
 	live.Set(0)
 	workList.PushBack(0)
 
-	t := newTransition()
+	t := NewTransition()
 	for workList.Len() > 0 {
 		s := workList.Remove(workList.Front()).(int)
-		count := a.initTransition(s, t)
+		count := a.InitTransition(s, t)
 		for i := 0; i < count; i++ {
-			a.nextTransition(t)
+			a.NextTransition(t)
 			if !live.Get(int64(t.dest)) {
 				live.Set(int64(t.dest))
 				workList.PushBack(t.dest)
@@ -748,15 +798,15 @@ func liveStatesToAccept(a *Automaton) *util.OpenBitSet {
 	builder := newAutomatonBuilder()
 
 	// NOTE: not quite the same thing as what SpecialOperations.reverse does:
-	t := newTransition()
+	t := NewTransition()
 	numStates := a.numStates()
 	for s := 0; s < numStates; s++ {
 		builder.createState()
 	}
 	for s := 0; s < numStates; s++ {
-		count := a.initTransition(s, t)
+		count := a.InitTransition(s, t)
 		for i := 0; i < count; i++ {
-			a.nextTransition(t)
+			a.NextTransition(t)
 			builder.addTransitionRange(t.dest, s, t.min, t.max)
 		}
 	}
@@ -778,9 +828,9 @@ func liveStatesToAccept(a *Automaton) *util.OpenBitSet {
 
 	for workList.Len() > 0 {
 		s = workList.Remove(workList.Front()).(int)
-		count := a2.initTransition(s, t)
+		count := a2.InitTransition(s, t)
 		for i := 0; i < count; i++ {
-			a2.nextTransition(t)
+			a2.NextTransition(t)
 			if !live.Get(int64(t.dest)) {
 				live.Set(int64(t.dest))
 				workList.PushBack(t.dest)
@@ -811,14 +861,14 @@ func removeDeadStates(a *Automaton) *Automaton {
 		}
 	}
 
-	t := newTransition()
+	t := NewTransition()
 
 	for i := 0; i < numStates; i++ {
 		if liveSet.Get(int64(i)) {
-			numTransitions := a.initTransition(i, t)
+			numTransitions := a.InitTransition(i, t)
 			// filter out transitions to dead states:
 			for j := 0; j < numTransitions; j++ {
-				a.nextTransition(t)
+				a.NextTransition(t)
 				if liveSet.Get(int64(t.dest)) {
 					ans.addTransitionRange(m[i], m[t.dest], t.min, t.max)
 				}
@@ -870,12 +920,12 @@ func reverse(a *Automaton) (*Automaton, map[int]bool) {
 	// old initial state becomes new accept state:
 	b.setAccept(1, true)
 
-	t := newTransition()
+	t := NewTransition()
 	for s := 0; s < numStates; s++ {
-		numTransitions := a.numTransitions(s)
-		a.initTransition(s, t)
+		numTransitions := a.NumTransitions(s)
+		a.InitTransition(s, t)
 		for i := 0; i < numTransitions; i++ {
-			a.nextTransition(t)
+			a.NextTransition(t)
 			b.addTransitionRange(t.dest+1, s+1, t.min, t.max)
 		}
 	}
@@ -909,12 +959,12 @@ func totalize(a *Automaton) *Automaton {
 	deadState := ans.createState()
 	ans.addTransitionRange(deadState, deadState, MIN_CODE_POINT, unicode.MaxRune)
 
-	t := newTransition()
+	t := NewTransition()
 	for i := 0; i < numStates; i++ {
 		maxi := MIN_CODE_POINT
-		count := a.initTransition(i, t)
+		count := a.InitTransition(i, t)
 		for j := 0; j < count; j++ {
-			a.nextTransition(t)
+			a.NextTransition(t)
 			ans.addTransitionRange(i, t.dest, t.min, t.max)
 			if t.min > maxi {
 				ans.addTransitionRange(i, deadState, maxi, t.min-1)
@@ -932,3 +982,124 @@ func totalize(a *Automaton) *Automaton {
 	ans.finishState()
 	return ans
 }
+
+/*
+Returns true if the given automaton accepts all strings for the
+specified min/max range of the alphabet. The automaton must be
+minimized.
+*/
+func isTotal(a *Automaton, minAlphabet, maxAlphabet int) bool {
+	if a.IsAccept(0) && a.NumTransitions(0) == 1 {
+		t := NewTransition()
+		a.transition(0, 0, t)
+		return t.dest == 0 && t.min == minAlphabet && t.max == maxAlphabet
+	}
+	return false
+}
+
+/*
+If this automaton accepts a single input, return it. Else, return
+nil. The automaton must be deterministic.
+*/
+func getSingleton(a *Automaton) []int {
+	assert2(a.deterministic, "input automaton must be deterministic")
+	var ans []int
+	visited := make(map[int]bool)
+	s := 0
+	t := NewTransition()
+	for {
+		visited[s] = true
+		if !a.IsAccept(s) {
+			if a.NumTransitions(s) == 1 {
+				a.transition(s, 0, t)
+				if t.min == t.max && !visited[t.dest] {
+					ans = append(ans, t.min)
+					s = t.dest
+					continue
+				}
+			}
+		} else if a.NumTransitions(s) == 0 {
+			if ans == nil {
+				ans = []int{}
+			}
+			return ans
+		}
+
+		// Automaton accepts more than one string:
+		return nil
+	}
+}
+
+/*
+Returns true if the language of this automaton is finite. The
+automaton must not have any dead states.
+*/
+func isFinite(a *Automaton) bool {
+	if a.numStates() == 0 {
+		return true
+	}
+	return isFiniteFrom(NewTransition(), a, 0,
+		util.NewOpenBitSet(), util.NewOpenBitSet())
+}
+
+/*
+Checks whether there is a loop containing state. (This is sufficient
+since there are never transitions to dead states.)
+*/
+func isFiniteFrom(scratch *Transition, a *Automaton, state int,
+	path, visited *util.OpenBitSet) bool {
+
+	path.Set(int64(state))
+	numTransitions := a.NumTransitions(state)
+	for t := 0; t < numTransitions; t++ {
+		a.transition(state, t, scratch)
+		dest := scratch.dest
+		if path.Get(int64(dest)) || !visited.Get(int64(dest)) &&
+			!isFiniteFrom(scratch, a, dest, path, visited) {
+			return false
+		}
+	}
+	path.Clear(int64(state))
+	visited.Set(int64(state))
+	return true
+}
+
+/*
+Returns the longest BytesRef that is a prefix of all accepted strings
+and visits each state at most once. The automaton must be
+deterministic.
+*/
+func getCommonPrefixBytesRef(a *Automaton) []byte {
+	var ans []byte
+	visited := make(map[int]bool)
+	s := 0
+	t := NewTransition()
+	for done := false; !done; {
+		done = true
+		visited[s] = true
+		if !a.IsAccept(s) && a.NumTransitions(s) == 1 {
+			a.transition(s, 0, t)
+			if t.min == t.max && !visited[t.dest] {
+				ans = append(ans, byte(t.min))
+				s = t.dest
+				done = false
+			}
+		}
+	}
+	return ans
+}
+
+/*
+Returns the longest BytesRef that is a suffix of all accepted strings.
+Worst case complexity: exponential in number of states (this calls
+determinize).
+*/
+func getCommonSuffixBytesRef(a *Automaton) []byte {
+	// reverse the language of the automaton, then reverse its common prefix.
+	r, _ := reverse(a)
+	ans := getCommonPrefixBytesRef(determinize(r))
+	for i, j := 0, len(ans)-1; i < j; i, j = i+1, j-1 {
+		ans[i], ans[j] = ans[j], ans[i]
+	}
+	return ans
+}
@@ -58,11 +58,33 @@ func newRunAutomaton(a *Automaton, maxInterval int, tablesize bool) *RunAutomato
 	}
 	// Set alphabet table for optimal run performance.
 	if tablesize {
-		panic("not implemented yet")
+		ans.classmap = make([]int, maxInterval+1)
+		i := 0
+		for j := 0; j <= maxInterval; j++ {
+			if i+1 < nPoints && j == points[i+1] {
+				i++
+			}
+			ans.classmap[j] = i
+		}
 	}
 	return ans
 }
 
+// Returns number of states in automaton.
+func (ra *RunAutomaton) Size() int {
+	return ra.size
+}
+
+// Returns acceptance status for given state.
+func (ra *RunAutomaton) IsAccept(state int) bool {
+	return ra.accept[state]
+}
+
+// Returns initial state.
+func (ra *RunAutomaton) InitialState() int {
+	return ra.initial
+}
+
 /*
 Returns the state obtained by reading the given char from the given
 state. Returns -1 if not obtaining any such state. (If the original
@@ -70,7 +92,7 @@ Automaton had no dead states, -1 is returned here if and only if a
 dead state is entered in an equivalent automaton with a total
 transition function.)
 */
-func (ra *RunAutomaton) step(state, c int) int {
+func (ra *RunAutomaton) Step(state, c int) int {
 	if ra.classmap == nil {
 		return ra.transitions[state*len(ra.points)+ra.charClass(c)]
 	} else {
@@ -88,3 +110,32 @@ func NewCharacterRunAutomaton(a *Automaton) *CharacterRunAutomaton {
 	ans.RunAutomaton = newRunAutomaton(a, unicode.MaxRune, false)
 	return ans
 }
+
+// util/automaton/ByteRunAutomaton.java
+
+// Automaton representation for matching UTF-8 []byte.
+type ByteRunAutomaton struct {
+	*RunAutomaton
+}
+
+/*
+Expert: if isBinary is true, the input is already byte-based; else
+it's converted from UTF-32 to UTF-8 first.
+*/
+func NewByteRunAutomaton(a *Automaton, isBinary bool) *ByteRunAutomaton {
+	if !isBinary {
+		a = NewUTF32ToUTF8().Convert(a)
+	}
+	return &ByteRunAutomaton{newRunAutomaton(a, 256, true)}
+}
+
+// Returns true if the given byte array is accepted by this automaton
+func (ra *ByteRunAutomaton) Run(s []byte) bool {
+	p := ra.initial
+	for _, b := range s {
+		if p = ra.Step(p, int(b)); p == -1 {
+			return false
+		}
+	}
+	return ra.accept[p]
+}
@@ -14,12 +14,24 @@ type Transition struct {
 }
 
 // Constructs a new singleton interval transition.
-func newTransition() *Transition {
+func NewTransition() *Transition {
 	return &Transition{
 		transitionUpto: -1,
 	}
 }
 
+// Source state.
+func (t *Transition) Source() int { return t.source }
+
+// Destination state.
+func (t *Transition) Dest() int { return t.dest }
+
+// Minimum accepted label (inclusive).
+func (t *Transition) Min() int { return t.min }
+
+// Maximum accepted label (inclusive).
+func (t *Transition) Max() int { return t.max }
+
 func (t *Transition) String() string {
 	panic("niy")
 	// var b bytes.Buffer
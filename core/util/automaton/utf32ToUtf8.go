@@ -0,0 +1,259 @@
+package automaton
+
+// util/automaton/UTF32ToUTF8.java
+
+// Unicode boundaries for UTF8 bytes 1,2,3,4
+var (
+	startCodes = []int{0, 128, 2048, 65536}
+	endCodes   = []int{127, 2047, 65535, 1114111}
+)
+
+var utf8Masks = func() []int {
+	ans := make([]int, 32)
+	v := 2
+	for i, _ := range ans {
+		ans[i] = v - 1
+		v *= 2
+	}
+	return ans
+}()
+
+/*
+Represents one of the N utf8 bytes that (in sequence) define a code
+point. value is the byte value; bits is how many bits are "used" by
+utf8 at that byte.
+*/
+type utf8Byte struct {
+	value int // TODO: change to byte
+	bits  int
+}
+
+// Holds a single code point, as a sequence of 1-4 utf8 bytes:
+type utf8Sequence struct {
+	bytes  [4]utf8Byte
+	length int
+}
+
+func (seq *utf8Sequence) byteAt(idx int) int {
+	return seq.bytes[idx].value
+}
+
+func (seq *utf8Sequence) numBits(idx int) int {
+	return seq.bytes[idx].bits
+}
+
+func (seq *utf8Sequence) set(code int) {
+	if code < 128 {
+		// 0xxxxxxx
+		seq.bytes[0].value = code
+		seq.bytes[0].bits = 7
+		seq.length = 1
+	} else if code < 2048 {
+		// 110yyyxx 10xxxxxx
+		seq.bytes[0].value = (6 << 5) | (code >> 6)
+		seq.bytes[0].bits = 5
+		seq.setRest(code, 1)
+		seq.length = 2
+	} else if code < 65536 {
+		// 1110yyyy 10yyyyxx 10xxxxxx
+		seq.bytes[0].value = (14 << 4) | (code >> 12)
+		seq.bytes[0].bits = 4
+		seq.setRest(code, 2)
+		seq.length = 3
+	} else {
+		// 11110zzz 10zzyyyy 10yyyyxx 10xxxxxx
+		seq.bytes[0].value = (30 << 3) | (code >> 18)
+		seq.bytes[0].bits = 3
+		seq.setRest(code, 3)
+		seq.length = 4
+	}
+}
+
+func (seq *utf8Sequence) setRest(code, numBytes int) {
+	for i := 0; i < numBytes; i++ {
+		seq.bytes[numBytes-i].value = 128 | (code & utf8Masks[5])
+		seq.bytes[numBytes-i].bits = 6
+		code = code >> 6
+	}
+}
+
+/*
+Converts UTF-32 automata to the equivalent UTF-8 representation.
+*/
+type UTF32ToUTF8 struct {
+	startUTF8, endUTF8 utf8Sequence
+	tmpUTF8a, tmpUTF8b utf8Sequence
+
+	utf8 *AutomatonBuilder
+}
+
+func NewUTF32ToUTF8() *UTF32ToUTF8 {
+	return &UTF32ToUTF8{}
+}
+
+// Builds necessary utf8 edges between start & end
+func (c *UTF32ToUTF8) convertOneEdge(start, end, startCodePoint, endCodePoint int) {
+	c.startUTF8.set(startCodePoint)
+	c.endUTF8.set(endCodePoint)
+	c.build(start, end, &c.startUTF8, &c.endUTF8, 0)
+}
+
+func (c *UTF32ToUTF8) build(start, end int, startUTF8, endUTF8 *utf8Sequence, upto int) {
+	// Break into start, middle, end:
+	if startUTF8.byteAt(upto) == endUTF8.byteAt(upto) {
+		// Degen case: lead with the same byte:
+		if upto == startUTF8.length-1 && upto == endUTF8.length-1 {
+			// Super degen: just single edge, one UTF8 byte:
+			c.utf8.addTransitionRange(start, end, startUTF8.byteAt(upto), endUTF8.byteAt(upto))
+			return
+		}
+		assert(startUTF8.length > upto+1)
+		assert(endUTF8.length > upto+1)
+		n := c.utf8.createState()
+
+		// Single value leading edge
+		c.utf8.addTransitionRange(start, n, startUTF8.byteAt(upto), startUTF8.byteAt(upto))
+
+		// Recurse for the rest
+		c.build(n, end, startUTF8, endUTF8, 1+upto)
+	} else if startUTF8.length == endUTF8.length {
+		if upto == startUTF8.length-1 {
+			c.utf8.addTransitionRange(start, end, startUTF8.byteAt(upto), endUTF8.byteAt(upto))
+		} else {
+			c.start(start, end, startUTF8, upto, false)
+			if endUTF8.byteAt(upto)-startUTF8.byteAt(upto) > 1 {
+				// There is a middle
+				c.all(start, end, startUTF8.byteAt(upto)+1, endUTF8.byteAt(upto)-1, startUTF8.length-upto-1)
+			}
+			c.end(start, end, endUTF8, upto, false)
+		}
+	} else {
+		// start
+		c.start(start, end, startUTF8, upto, true)
+
+		// possibly middle, spanning multiple num bytes
+		byteCount := 1 + startUTF8.length - upto
+		limit := endUTF8.length - upto
+		for byteCount < limit {
+			// wasteful: we only need first byte, and, we should
+			// statically encode this first byte:
+			c.tmpUTF8a.set(startCodes[byteCount-1])
+			c.tmpUTF8b.set(endCodes[byteCount-1])
+			c.all(start, end, c.tmpUTF8a.byteAt(0), c.tmpUTF8b.byteAt(0), c.tmpUTF8a.length-1)
+			byteCount++
+		}
+
+		// end
+		c.end(start, end, endUTF8, upto, true)
+	}
+}
+
+func (c *UTF32ToUTF8) start(start, end int, startUTF8 *utf8Sequence, upto int, doAll bool) {
+	if upto == startUTF8.length-1 {
+		// Done recursing
+		c.utf8.addTransitionRange(start, end, startUTF8.byteAt(upto),
+			startUTF8.byteAt(upto)|utf8Masks[startUTF8.numBits(upto)-1]) // type=start
+	} else {
+		n := c.utf8.createState()
+		c.utf8.addTransitionRange(start, n, startUTF8.byteAt(upto), startUTF8.byteAt(upto))
+		c.start(n, end, startUTF8, 1+upto, true)
+		endCode := startUTF8.byteAt(upto) | utf8Masks[startUTF8.numBits(upto)-1]
+		if doAll && startUTF8.byteAt(upto) != endCode {
+			c.all(start, end, startUTF8.byteAt(upto)+1, endCode, startUTF8.length-upto-1)
+		}
+	}
+}
+
+func (c *UTF32ToUTF8) end(start, end int, endUTF8 *utf8Sequence, upto int, doAll bool) {
+	if upto == endUTF8.length-1 {
+		// Done recursing
+		c.utf8.addTransitionRange(start, end,
+			endUTF8.byteAt(upto) & ^utf8Masks[endUTF8.numBits(upto)-1],
+			endUTF8.byteAt(upto)) // type=end
+	} else {
+		var startCode int
+		if endUTF8.numBits(upto) == 5 {
+			// special case -- avoid created unused edges (endUTF8
+			// doesn't accept certain byte sequences) -- there
+			// are other cases we could optimize too:
+			startCode = 194
+		} else {
+			startCode = endUTF8.byteAt(upto) & ^utf8Masks[endUTF8.numBits(upto)-1]
+		}
+		if doAll && endUTF8.byteAt(upto) != startCode {
+			c.all(start, end, startCode, endUTF8.byteAt(upto)-1, endUTF8.length-upto-1)
+		}
+		n := c.utf8.createState()
+		c.utf8.addTransitionRange(start, n, endUTF8.byteAt(upto), endUTF8.byteAt(upto)) // type=end
+		c.end(n, end, endUTF8, 1+upto, true)
+	}
+}
+
+func (c *UTF32ToUTF8) all(start, end, startCode, endCode, left int) {
+	if left == 0 {
+		c.utf8.addTransitionRange(start, end, startCode, endCode) // type=all
+	} else {
+		lastN := c.utf8.createState()
+		c.utf8.addTransitionRange(start, lastN, startCode, endCode) // type=all
+		for left > 1 {
+			n := c.utf8.createState()
+			c.utf8.addTransitionRange(lastN, n, 128, 191) // type=all*
+			left--
+			lastN = n
+		}
+		c.utf8.addTransitionRange(lastN, end, 128, 191) // type=all*
+	}
+}
+
+/*
+Converts an incoming utf32 automaton to an equivalent utf8 one. The
+incoming automaton need not be deterministic. Note that the returned
+automaton will not in general be deterministic, so you must
+determinize it if that's needed.
+*/
+func (c *UTF32ToUTF8) Convert(utf32 *Automaton) *Automaton {
+	if utf32.numStates() == 0 {
+		return utf32
+	}
+
+	m := make([]int, utf32.numStates())
+	for i, _ := range m {
+		m[i] = -1
+	}
+
+	utf32State := 0
+	pending := []int{utf32State}
+	c.utf8 = newAutomatonBuilder()
+
+	utf8State := c.utf8.createState()
+	c.utf8.setAccept(utf8State, utf32.IsAccept(utf32State))
+
+	m[utf32State] = utf8State
+
+	scratch := NewTransition()
+
+	for len(pending) > 0 {
+		utf32State = pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		utf8State = m[utf32State]
+		assert(utf8State != -1)
+
+		numTransitions := utf32.InitTransition(utf32State, scratch)
+		for i := 0; i < numTransitions; i++ {
+			utf32.NextTransition(scratch)
+			destUTF32 := scratch.dest
+			destUTF8 := m[destUTF32]
+			if destUTF8 == -1 {
+				destUTF8 = c.utf8.createState()
+				c.utf8.setAccept(destUTF8, utf32.IsAccept(destUTF32))
+				m[destUTF32] = destUTF8
+				pending = append(pending, destUTF32)
+			}
+
+			// Writes new transitions into pendingTransitions:
+			c.convertOneEdge(utf8State, destUTF8, scratch.min, scratch.max)
+		}
+	}
+
+	return c.utf8.finish()
+}
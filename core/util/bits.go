@@ -20,6 +20,22 @@ type Bits interface {
 	Length() int
 }
 
+// Bits impl of the specified length with all bits set.
+type MatchAllBits int
+
+func NewMatchAllBits(length int) MatchAllBits { return MatchAllBits(length) }
+
+func (b MatchAllBits) At(index int) bool { return true }
+func (b MatchAllBits) Length() int       { return int(b) }
+
+// Bits impl of the specified length with no bits set.
+type MatchNoBits int
+
+func NewMatchNoBits(length int) MatchNoBits { return MatchNoBits(length) }
+
+func (b MatchNoBits) At(index int) bool { return false }
+func (b MatchNoBits) Length() int       { return int(b) }
+
 // util/MutableBits.java
 
 /* Extension of Bits for live documents. */
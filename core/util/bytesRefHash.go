@@ -31,6 +31,8 @@ type BytesRefHash struct {
 	bytesUsed       Counter
 }
 
+const BYTES_REF_HASH_DEFAULT_CAPACITY = 16
+
 func NewBytesRefHash(pool *ByteBlockPool, capacity int,
 	bytesStartArray BytesStartArray) *BytesRefHash {
 	ids := make([]int, capacity)
@@ -56,6 +58,19 @@ func NewBytesRefHash(pool *ByteBlockPool, capacity int,
 	}
 }
 
+/*
+Populates and returns a BytesRef with the bytes for the given bytesID.
+
+Note: the given bytesID must be a positive integer less than the
+current size (Size())
+*/
+func (h *BytesRefHash) Get(bytesId int, ref *BytesRef) *BytesRef {
+	assert2(h.bytesStart != nil, "bytesStart is null - not initialized")
+	assert2(bytesId < len(h.bytesStart), "bytesID exceeds byteStart len: %v", len(h.bytesStart))
+	h.pool.SetBytesRef(ref, h.bytesStart[bytesId])
+	return ref
+}
+
 /* Returns the number of values in this hash. */
 func (h *BytesRefHash) Size() int {
 	return h.count
@@ -377,3 +392,34 @@ type BytesStartArray interface {
 	// clears the BytesStartArray and returns the cleared instance.
 	Clear() []int
 }
+
+/* A simple BytesStartArray that tracks memory allocation using a private Counter instance. */
+type DirectBytesStartArray struct {
+	initSize   int
+	bytesStart []int
+	bytesUsed  Counter
+}
+
+func NewDirectBytesStartArray(initSize int, counter Counter) *DirectBytesStartArray {
+	return &DirectBytesStartArray{initSize: initSize, bytesUsed: counter}
+}
+
+func (a *DirectBytesStartArray) Clear() []int {
+	a.bytesStart = nil
+	return nil
+}
+
+func (a *DirectBytesStartArray) Grow() []int {
+	assert(a.bytesStart != nil)
+	a.bytesStart = GrowIntSlice(a.bytesStart, len(a.bytesStart)+1)
+	return a.bytesStart
+}
+
+func (a *DirectBytesStartArray) Init() []int {
+	a.bytesStart = make([]int, Oversize(a.initSize, NUM_BYTES_INT))
+	return a.bytesStart
+}
+
+func (a *DirectBytesStartArray) BytesUsed() Counter {
+	return a.bytesUsed
+}
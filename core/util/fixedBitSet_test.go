@@ -0,0 +1,27 @@
+package util
+
+import (
+	. "github.com/gzg1984/golucene/core/search/model"
+	. "github.com/gzg1984/gounit"
+	"testing"
+)
+
+func TestFixedBitSetIterator(t *testing.T) {
+	b := NewFixedBitSetOf(130)
+	b.Set(3)
+	b.Set(64)
+	b.Set(129)
+	It(t).Should("Bit 64 is set").Verify(b.At(64))
+	It(t).Should("Bit 65 is not set").Verify(!b.At(65))
+
+	it, _ := b.Iterator()
+	doc, _ := it.NextDoc()
+	It(t).Should("First doc is 3 (got %v)", doc).Verify(doc == 3)
+	doc, _ = it.Advance(65)
+	It(t).Should("Advance to 129 (got %v)", doc).Verify(doc == 129)
+	doc, _ = it.NextDoc()
+	It(t).Should("Exhausted (got %v)", doc).Verify(doc == NO_MORE_DOCS)
+
+	b.Clear(64)
+	It(t).Should("Cardinality is 2 (got %v)", b.Cardinality()).Verify(b.Cardinality() == 2)
+}
@@ -1,9 +1,301 @@
 package util
 
+import (
+	"math"
+)
+
 // util/NumericUtils.java
 
+/*
+This is a helper class to generate prefix-encoded representations for
+numerical values and supplies converters to represent float/double
+values as sortable integers/longs.
+
+To quickly execute range queries in Apache Lucene, a range is divided
+recursively into multiple intervals for searching: The center of the
+range is searched only with the lowest possible precision in the trie,
+while the boundaries are matched more exactly. This reduces the number
+of terms dramatically.
+
+This type generates terms to achieve this: First the numerical integer
+values need to be converted to bytes. For that integer values (32 bit
+or 64 bit) are made unsigned and the bits are converted to ASCII
+chars with each 7 bit. The resulting byte[] is sortable like the
+original integer value (even using UTF-8 sort order). Each value is
+also prefixed (in the first char) by the shift value (number of bits
+removed) used during encoding.
+
+To also index floating point numbers, this type supplies two methods
+to convert them to integer values by changing their bit layout:
+DoubleToSortableLong(), FloatToSortableInt(). You will have no
+precision loss by converting floating point numbers to integers and
+back (only that the integer form is not usable). Other data types
+like dates can easily converted to longs or ints (e.g. date to long).
+
+For easy usage, the trie algorithm is implemented for indexing inside
+NumericTokenStream that can index int, long, float, and double. For
+querying, NumericRangeQuery and NumericRangeFilter implement the query
+part for the same data types.
+*/
+
 const (
 	// The default precision step used by LongField, DoubleField,
 	// NumericTokenStream, NumericRangeQuery, and NumericRangeFilter.
 	NUMERIC_PRECISION_STEP_DEFAULT = 16
+
+	// The default precision step used by IntField and FloatField.
+	NUMERIC_PRECISION_STEP_DEFAULT_32 = 8
+
+	// Longs are stored at lower precision by shifting off lower bits.
+	// The shift count is stored as SHIFT_START_LONG+shift in the first
+	// byte
+	SHIFT_START_LONG = 0x20
+
+	// The maximum term length (used for []byte buffer size) for
+	// encoding long values.
+	BUF_SIZE_LONG = 63/7 + 2
+
+	// Integers are stored at lower precision by shifting off lower
+	// bits. The shift count is stored as SHIFT_START_INT+shift in the
+	// first byte
+	SHIFT_START_INT = 0x60
+
+	// The maximum term length (used for []byte buffer size) for
+	// encoding int values.
+	BUF_SIZE_INT = 31/7 + 2
 )
+
+/*
+Returns prefix coded bits after reducing the precision by shift bits.
+This is method is used by NumericTokenStream. After encoding, bytes
+contains the encoded value.
+*/
+func LongToPrefixCoded(val int64, shift int, bytes *BytesRefBuilder) {
+	assert2(shift&^0x3f == 0, "Illegal shift value, must be 0..63; got shift=%v", shift)
+	nChars := (((63 - shift) * 37) >> 8) + 1 // i/7 is the same as (i*37)>>8 for i in 0..63
+	bytes.SetLength(nChars + 1)              // one extra for the byte that contains the shift info
+	bytes.Grow(BUF_SIZE_LONG)
+	bytes.Set(0, byte(SHIFT_START_LONG+shift))
+	sortableBits := uint64(val) ^ 0x8000000000000000
+	sortableBits >>= uint(shift)
+	for ; nChars > 0; nChars-- {
+		// Store 7 bits per byte for compatibility
+		// with UTF-8 encoding of terms
+		bytes.Set(nChars, byte(sortableBits&0x7f))
+		sortableBits >>= 7
+	}
+}
+
+/*
+Returns prefix coded bits after reducing the precision by shift bits.
+This is method is used by NumericTokenStream. After encoding, bytes
+contains the encoded value.
+*/
+func IntToPrefixCoded(val int32, shift int, bytes *BytesRefBuilder) {
+	assert2(shift&^0x1f == 0, "Illegal shift value, must be 0..31; got shift=%v", shift)
+	nChars := (((31 - shift) * 37) >> 8) + 1 // i/7 is the same as (i*37)>>8 for i in 0..63
+	bytes.SetLength(nChars + 1)              // one extra for the byte that contains the shift info
+	bytes.Grow(BUF_SIZE_LONG)                // use the max
+	bytes.Set(0, byte(SHIFT_START_INT+shift))
+	sortableBits := uint32(val) ^ 0x80000000
+	sortableBits >>= uint(shift)
+	for ; nChars > 0; nChars-- {
+		// Store 7 bits per byte for compatibility
+		// with UTF-8 encoding of terms
+		bytes.Set(nChars, byte(sortableBits&0x7f))
+		sortableBits >>= 7
+	}
+}
+
+/*
+Returns the shift value from a prefix encoded long. It panics if the
+supplied value is not correctly prefix encoded.
+*/
+func PrefixCodedLongShift(val []byte) int {
+	shift := int(val[0]) - SHIFT_START_LONG
+	assert2(shift >= 0 && shift <= 63,
+		"Invalid shift value (%v) in prefixCoded bytes (is encoded value really an INT?)", shift)
+	return shift
+}
+
+/*
+Returns the shift value from a prefix encoded int. It panics if the
+supplied value is not correctly prefix encoded.
+*/
+func PrefixCodedIntShift(val []byte) int {
+	shift := int(val[0]) - SHIFT_START_INT
+	assert2(shift >= 0 && shift <= 31,
+		"Invalid shift value (%v) in prefixCoded bytes (is encoded value really an INT?)", shift)
+	return shift
+}
+
+/*
+Returns a long from prefixCoded bytes. Rightmost bits will be zero
+for lower precision codes. This method can be used to decode a term's
+value.
+*/
+func PrefixCodedToLong(val []byte) int64 {
+	var sortableBits uint64
+	for i, b := range val[1:] {
+		assert2(b < 0x80,
+			"Invalid prefixCoded numerical value representation (byte %x at position %v is invalid)", b, i+1)
+		sortableBits = (sortableBits << 7) | uint64(b)
+	}
+	return int64((sortableBits << uint(PrefixCodedLongShift(val))) ^ 0x8000000000000000)
+}
+
+/*
+Returns an int from prefixCoded bytes. Rightmost bits will be zero
+for lower precision codes. This method can be used to decode a term's
+value.
+*/
+func PrefixCodedToInt(val []byte) int32 {
+	var sortableBits uint32
+	for i, b := range val[1:] {
+		assert2(b < 0x80,
+			"Invalid prefixCoded numerical value representation (byte %x at position %v is invalid)", b, i+1)
+		sortableBits = (sortableBits << 7) | uint32(b)
+	}
+	return int32((sortableBits << uint(PrefixCodedIntShift(val))) ^ 0x80000000)
+}
+
+/*
+Converts a float64 value to a sortable signed int64. The value is
+converted by getting their IEEE 754 floating-point "double format"
+bit layout and then some bits are swapped, to be able to compare the
+result as int64. By this the precision is not reduced, but the value
+can easily used as an int64. The sort order (including NaN) is
+defined by comparing the float64 values bitwise; NaN is greater than
+positive infinity.
+*/
+func DoubleToSortableLong(val float64) int64 {
+	f := int64(math.Float64bits(val))
+	if f < 0 {
+		f ^= 0x7fffffffffffffff
+	}
+	return f
+}
+
+// Converts a sortable int64 back to a float64.
+func SortableLongToDouble(val int64) float64 {
+	if val < 0 {
+		val ^= 0x7fffffffffffffff
+	}
+	return math.Float64frombits(uint64(val))
+}
+
+/*
+Converts a float32 value to a sortable signed int32. The value is
+converted by getting their IEEE 754 floating-point "float format" bit
+layout and then some bits are swapped, to be able to compare the
+result as int32. By this the precision is not reduced, but the value
+can easily used as an int32.
+*/
+func FloatToSortableInt(val float32) int32 {
+	f := int32(math.Float32bits(val))
+	if f < 0 {
+		f ^= 0x7fffffff
+	}
+	return f
+}
+
+// Converts a sortable int32 back to a float32.
+func SortableIntToFloat(val int32) float32 {
+	if val < 0 {
+		val ^= 0x7fffffff
+	}
+	return math.Float32frombits(uint32(val))
+}
+
+/*
+Callback for SplitLongRange() and SplitIntRange(). It is called with
+the prefix coded lower and upper bound of each sub-range, both
+inclusive. The slices are only valid during the call.
+*/
+type NumericRangeBuilder func(minPrefixCoded, maxPrefixCoded []byte)
+
+/*
+Splits a long range recursively. You may implement a builder that
+adds clauses to a BooleanQuery for each call to its AddRange() method.
+
+This method is used by NumericRangeQuery.
+*/
+func SplitLongRange(builder NumericRangeBuilder, precisionStep int, minBound, maxBound int64) {
+	splitRange(builder, 64, precisionStep, minBound, maxBound)
+}
+
+/*
+Splits an int range recursively. You may implement a builder that
+adds clauses to a BooleanQuery for each call to its AddRange() method.
+
+This method is used by NumericRangeQuery.
+*/
+func SplitIntRange(builder NumericRangeBuilder, precisionStep int, minBound, maxBound int32) {
+	splitRange(builder, 32, precisionStep, int64(minBound), int64(maxBound))
+}
+
+// This helper does the splitting for both 32 and 64 bit.
+func splitRange(builder NumericRangeBuilder, valSize, precisionStep int, minBound, maxBound int64) {
+	assert2(precisionStep >= 1, "precisionStep must be >=1")
+	if minBound > maxBound {
+		return
+	}
+	for shift := 0; ; shift += precisionStep {
+		// calculate new bounds for inner precision
+		diff := int64(1) << uint(shift+precisionStep)
+		mask := ((int64(1) << uint(precisionStep)) - 1) << uint(shift)
+		hasLower := (minBound & mask) != 0
+		hasUpper := (maxBound & mask) != mask
+		nextMinBound, nextMaxBound := minBound, maxBound
+		if hasLower {
+			nextMinBound += diff
+		}
+		if hasUpper {
+			nextMaxBound -= diff
+		}
+		nextMinBound &^= mask
+		nextMaxBound &^= mask
+		lowerWrapped := nextMinBound < minBound
+		upperWrapped := nextMaxBound > maxBound
+
+		if shift+precisionStep >= valSize || nextMinBound > nextMaxBound || lowerWrapped || upperWrapped {
+			// We are in the lowest precision or the next precision is not
+			// available.
+			addRange(builder, valSize, minBound, maxBound, shift)
+			// exit the split recursion loop
+			break
+		}
+
+		if hasLower {
+			addRange(builder, valSize, minBound, minBound|mask, shift)
+		}
+		if hasUpper {
+			addRange(builder, valSize, maxBound&^mask, maxBound, shift)
+		}
+
+		// recurse to next precision
+		minBound = nextMinBound
+		maxBound = nextMaxBound
+	}
+}
+
+// Helper that delegates to correct range builder
+func addRange(builder NumericRangeBuilder, valSize int, minBound, maxBound int64, shift int) {
+	// for the max bound set all lower bits (that were shifted away):
+	// this is important for testing or other usages of the splitted range
+	// (e.g. to reconstruct the full range). The prefixEncoding will remove
+	// the bits anyway, so they do not hurt!
+	maxBound |= (int64(1) << uint(shift)) - 1
+	minBytes, maxBytes := NewBytesRefBuilder(), NewBytesRefBuilder()
+	switch valSize {
+	case 64:
+		LongToPrefixCoded(minBound, shift, minBytes)
+		LongToPrefixCoded(maxBound, shift, maxBytes)
+	case 32:
+		IntToPrefixCoded(int32(minBound), shift, minBytes)
+		IntToPrefixCoded(int32(maxBound), shift, maxBytes)
+	default:
+		panic("valSize must be 32 or 64.")
+	}
+	builder(minBytes.Get().ToBytes(), maxBytes.Get().ToBytes())
+}
@@ -0,0 +1,173 @@
+package util
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestLongConversionAndOrdering(t *testing.T) {
+	// generate a series of encoded longs, each numerical one bigger
+	// than the one before
+	var last []byte
+	for l := int64(-100000); l < 100000; l++ {
+		act := NewBytesRefBuilder()
+		LongToPrefixCoded(l, 0, act)
+		cur := act.Get().ToBytes()
+		if last != nil && bytes.Compare(last, cur) >= 0 {
+			t.Fatalf("actual bigger than last (as []byte) for %v", l)
+		}
+		if v := PrefixCodedToLong(cur); v != l {
+			t.Fatalf("forward and back conversion should generate same long: %v != %v", v, l)
+		}
+		last = append(last[:0], cur...)
+	}
+}
+
+func TestIntConversionAndOrdering(t *testing.T) {
+	var last []byte
+	for i := int32(-100000); i < 100000; i++ {
+		act := NewBytesRefBuilder()
+		IntToPrefixCoded(i, 0, act)
+		cur := act.Get().ToBytes()
+		if last != nil && bytes.Compare(last, cur) >= 0 {
+			t.Fatalf("actual bigger than last (as []byte) for %v", i)
+		}
+		if v := PrefixCodedToInt(cur); v != i {
+			t.Fatalf("forward and back conversion should generate same int: %v != %v", v, i)
+		}
+		last = append(last[:0], cur...)
+	}
+}
+
+func TestLongSpecialValues(t *testing.T) {
+	vals := []int64{math.MinInt64, math.MinInt64 + 1, math.MinInt64 + 2, -5003400000000,
+		-4000, -3000, -2000, -1000, -1, 0, 1, 10, 300, 50006789999999999,
+		math.MaxInt64 - 2, math.MaxInt64 - 1, math.MaxInt64}
+	for shift := 0; shift < 64; shift++ {
+		var last []byte
+		for i, v := range vals {
+			act := NewBytesRefBuilder()
+			LongToPrefixCoded(v, shift, act)
+			cur := act.Get().ToBytes()
+			if PrefixCodedLongShift(cur) != shift {
+				t.Fatalf("shift not decoded for %v", v)
+			}
+			if i > 0 && bytes.Compare(last, cur) > 0 {
+				t.Fatalf("ordering broken at shift %v for %v", shift, v)
+			}
+			want := v &^ ((int64(1) << uint(shift)) - 1)
+			if got := PrefixCodedToLong(cur); got != want {
+				t.Fatalf("shift %v: %v != %v", shift, got, want)
+			}
+			last = append(last[:0], cur...)
+		}
+	}
+}
+
+func TestDoubles(t *testing.T) {
+	vals := []float64{math.Inf(-1), -2.3e25, -1.0e15, -1.0, -1.0e-1, -1.0e-2,
+		math.Copysign(0, -1), 0, 1.0e-2, 1.0e-1, 1.0, 1.0e15, 2.3e25, math.Inf(1), math.NaN()}
+	sortable := make([]int64, len(vals))
+	for i, v := range vals {
+		sortable[i] = DoubleToSortableLong(v)
+		if back := SortableLongToDouble(sortable[i]); math.Float64bits(back) != math.Float64bits(v) {
+			t.Errorf("forward and back conversion should generate same double: %v", v)
+		}
+	}
+	for i := 1; i < len(sortable); i++ {
+		if sortable[i-1] >= sortable[i] {
+			t.Errorf("check sort order of %v and %v", vals[i-1], vals[i])
+		}
+	}
+}
+
+func TestFloats(t *testing.T) {
+	vals := []float32{float32(math.Inf(-1)), -2.3e25, -1.0e15, -1.0, -1.0e-1, -1.0e-2,
+		0, 1.0e-2, 1.0e-1, 1.0, 1.0e15, 2.3e25, float32(math.Inf(1)), float32(math.NaN())}
+	sortable := make([]int32, len(vals))
+	for i, v := range vals {
+		sortable[i] = FloatToSortableInt(v)
+		if back := SortableIntToFloat(sortable[i]); math.Float32bits(back) != math.Float32bits(v) {
+			t.Errorf("forward and back conversion should generate same float: %v", v)
+		}
+	}
+	for i := 1; i < len(sortable); i++ {
+		if sortable[i-1] >= sortable[i] {
+			t.Errorf("check sort order of %v and %v", vals[i-1], vals[i])
+		}
+	}
+}
+
+// Checks that the splitted ranges cover exactly [lower, upper] without
+// gaps or overlaps, by decoding every sub-range back.
+func assertLongRangeSplit(t *testing.T, lower, upper int64, precisionStep int, expectedShifts []int) {
+	var covered [][2]int64
+	var shifts []int
+	SplitLongRange(func(minPrefixCoded, maxPrefixCoded []byte) {
+		shift := PrefixCodedLongShift(minPrefixCoded)
+		if PrefixCodedLongShift(maxPrefixCoded) != shift {
+			t.Fatalf("shift of bounds differs")
+		}
+		min := PrefixCodedToLong(minPrefixCoded)
+		max := PrefixCodedToLong(maxPrefixCoded) | ((int64(1) << uint(shift)) - 1)
+		covered = append(covered, [2]int64{min, max})
+		shifts = append(shifts, shift)
+	}, precisionStep, lower, upper)
+
+	// sort sub-ranges by lower bound, then check they tile the range
+	for i := 1; i < len(covered); i++ {
+		for j := i; j > 0 && covered[j][0] < covered[j-1][0]; j-- {
+			covered[j], covered[j-1] = covered[j-1], covered[j]
+		}
+	}
+	next := lower
+	for _, r := range covered {
+		if r[0] != next {
+			t.Fatalf("range [%v,%v] step %v: gap or overlap at %v", lower, upper, precisionStep, next)
+		}
+		next = r[1] + 1
+	}
+	if next-1 != upper {
+		t.Fatalf("range [%v,%v] step %v: covered up to %v", lower, upper, precisionStep, next-1)
+	}
+	if expectedShifts != nil {
+		if len(shifts) != len(expectedShifts) {
+			t.Fatalf("shifts %v, expected %v", shifts, expectedShifts)
+		}
+		for i, s := range expectedShifts {
+			if shifts[i] != s {
+				t.Fatalf("shifts %v, expected %v", shifts, expectedShifts)
+			}
+		}
+	}
+}
+
+func TestSplitLongRange(t *testing.T) {
+	// a hard-coded "standard" range
+	assertLongRangeSplit(t, -5000, 9500, 4, []int{0, 0, 4, 4, 8, 8, 12})
+	// the same with no range splitting
+	assertLongRangeSplit(t, -5000, 9500, 64, []int{0})
+	// some other ranges
+	assertLongRangeSplit(t, 0, 1024+63, 4, []int{4, 8})
+	assertLongRangeSplit(t, -1000, 1000000, 8, nil)
+	assertLongRangeSplit(t, math.MinInt64, math.MinInt64+0xf, 4, []int{4})
+	assertLongRangeSplit(t, math.MaxInt64-0xf, math.MaxInt64, 4, []int{4})
+	assertLongRangeSplit(t, 1, 1, 4, []int{0})
+}
+
+func TestSplitIntRange(t *testing.T) {
+	var n int
+	SplitIntRange(func(minPrefixCoded, maxPrefixCoded []byte) {
+		if PrefixCodedIntShift(minPrefixCoded) != PrefixCodedIntShift(maxPrefixCoded) {
+			t.Fatalf("shift of bounds differs")
+		}
+		if bytes.Compare(minPrefixCoded, maxPrefixCoded) > 0 {
+			t.Fatalf("lower bound after upper bound")
+		}
+		n++
+	}, 4, -5000, 9500)
+	if n != 7 {
+		t.Errorf("expected 7 sub-ranges, got %v", n)
+	}
+}
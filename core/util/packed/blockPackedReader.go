@@ -0,0 +1,219 @@
+package packed
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gzg1984/golucene/core/util"
+	"math"
+)
+
+/* Same as DataInput.ReadVLong but supports negative values */
+func readBlockVLong(in DataInput) (int64, error) {
+	var i int64
+	for shift := uint(0); shift < 56; shift += 7 {
+		b, err := in.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		i |= int64(b&0x7F) << shift
+		if b < 0x80 {
+			return i, nil
+		}
+	}
+	b, err := in.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	return i | (int64(b) << 56), nil
+}
+
+/* Reads a zig-zag encoded long written by writeZLong(). */
+func readZLong(in DataInput) (int64, error) {
+	var n uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b, err := in.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n |= uint64(b&0x7F) << shift
+		if b < 0x80 {
+			return util.ZigZagDecodeLong(int64(n)), nil
+		}
+	}
+	return 0, errors.New("Invalid vLong detected (more than 64 bits)")
+}
+
+// util/packed/BlockPackedReader.java
+
+/* Provides random access to a stream written with BlockPackedWriter. */
+type BlockPackedReader struct {
+	blockShift, blockMask int
+	valueCount            int64
+	minValues             []int64
+	subReaders            []PackedIntsReader
+}
+
+/*
+Reads all blocks of a stream written with BlockPackedWriter into
+memory.
+*/
+func NewBlockPackedReader(in DataInput, packedIntsVersion int32,
+	blockSize int, valueCount int64) (r *BlockPackedReader, err error) {
+
+	r = &BlockPackedReader{
+		valueCount: valueCount,
+		blockShift: checkBlockSize(blockSize, BLOCK_PACKED_MIN_BLOCK_SIZE, BLOCK_PACKED_MAX_BLOCK_SIZE),
+		blockMask:  blockSize - 1,
+	}
+	n := numBlocks(valueCount, blockSize)
+	r.subReaders = make([]PackedIntsReader, n)
+	for i := 0; i < n; i++ {
+		var token byte
+		if token, err = in.ReadByte(); err != nil {
+			return nil, err
+		}
+		bitsPerValue := int(token >> bp_BPV_SHIFT)
+		if bitsPerValue > 64 {
+			return nil, errors.New(fmt.Sprintf("Corrupted: bitsPerValue=%v", bitsPerValue))
+		}
+		if (token & bp_MIN_VALUE_EQUALS_0) == 0 {
+			if r.minValues == nil {
+				r.minValues = make([]int64, n)
+			}
+			var v int64
+			if v, err = readBlockVLong(in); err != nil {
+				return nil, err
+			}
+			r.minValues[i] = util.ZigZagDecodeLong(1 + v)
+		}
+		if bitsPerValue == 0 {
+			r.subReaders[i] = newNilReader(blockSize)
+		} else {
+			size := blockSize
+			if rest := valueCount - int64(i)*int64(blockSize); rest < int64(size) {
+				size = int(rest)
+			}
+			if r.subReaders[i], err = ReaderNoHeader(in, PackedFormat(PACKED),
+				packedIntsVersion, int32(size), uint32(bitsPerValue)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+func (r *BlockPackedReader) Get(index int64) int64 {
+	assert(index >= 0 && index < r.valueCount)
+	block := int(uint64(index) >> uint(r.blockShift))
+	idx := int(index & int64(r.blockMask))
+	var min int64
+	if r.minValues != nil {
+		min = r.minValues[block]
+	}
+	return min + r.subReaders[block].Get(idx)
+}
+
+func (r *BlockPackedReader) Size() int64 {
+	return r.valueCount
+}
+
+func (r *BlockPackedReader) RamBytesUsed() int64 {
+	size := util.SizeOf(r.minValues)
+	for _, sub := range r.subReaders {
+		size += sub.RamBytesUsed()
+	}
+	return size
+}
+
+// util/packed/MonotonicBlockPackedReader.java
+
+/*
+Provides random access to a stream written with
+MonotonicBlockPackedWriter.
+*/
+type MonotonicBlockPackedReader struct {
+	blockShift, blockMask int
+	valueCount            int64
+	packedIntsVersion     int32
+	minValues             []int64
+	averages              []float32
+	subReaders            []PackedIntsReader
+}
+
+/*
+Reads all blocks of a stream written with MonotonicBlockPackedWriter
+into memory.
+*/
+func NewMonotonicBlockPackedReader(in DataInput, packedIntsVersion int32,
+	blockSize int, valueCount int64) (r *MonotonicBlockPackedReader, err error) {
+
+	r = &MonotonicBlockPackedReader{
+		valueCount:        valueCount,
+		packedIntsVersion: packedIntsVersion,
+		blockShift:        checkBlockSize(blockSize, BLOCK_PACKED_MIN_BLOCK_SIZE, BLOCK_PACKED_MAX_BLOCK_SIZE),
+		blockMask:         blockSize - 1,
+	}
+	n := numBlocks(valueCount, blockSize)
+	r.minValues = make([]int64, n)
+	r.averages = make([]float32, n)
+	r.subReaders = make([]PackedIntsReader, n)
+	for i := 0; i < n; i++ {
+		if packedIntsVersion < VERSION_MONOTONIC_WITHOUT_ZIGZAG {
+			if r.minValues[i], err = readBlockVLong(in); err != nil {
+				return nil, err
+			}
+		} else {
+			if r.minValues[i], err = readZLong(in); err != nil {
+				return nil, err
+			}
+		}
+		var bits int32
+		if bits, err = in.ReadInt(); err != nil {
+			return nil, err
+		}
+		r.averages[i] = math.Float32frombits(uint32(bits))
+		var bitsPerValue int32
+		if bitsPerValue, err = in.ReadVInt(); err != nil {
+			return nil, err
+		}
+		if bitsPerValue > 64 {
+			return nil, errors.New(fmt.Sprintf("Corrupted: bitsPerValue=%v", bitsPerValue))
+		}
+		if bitsPerValue == 0 {
+			r.subReaders[i] = newNilReader(blockSize)
+		} else {
+			size := blockSize
+			if rest := valueCount - int64(i)*int64(blockSize); rest < int64(size) {
+				size = int(rest)
+			}
+			if r.subReaders[i], err = ReaderNoHeader(in, PackedFormat(PACKED),
+				packedIntsVersion, int32(size), uint32(bitsPerValue)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+func (r *MonotonicBlockPackedReader) Get(index int64) int64 {
+	assert(index >= 0 && index < r.valueCount)
+	block := int(uint64(index) >> uint(r.blockShift))
+	idx := int(index & int64(r.blockMask))
+	delta := r.subReaders[block].Get(idx)
+	if r.packedIntsVersion < VERSION_MONOTONIC_WITHOUT_ZIGZAG {
+		delta = util.ZigZagDecodeLong(delta)
+	}
+	return monotonicExpected(r.minValues[block], r.averages[block], idx) + delta
+}
+
+func (r *MonotonicBlockPackedReader) Size() int64 {
+	return r.valueCount
+}
+
+func (r *MonotonicBlockPackedReader) RamBytesUsed() int64 {
+	size := util.SizeOf(r.minValues) + int64(4*len(r.averages))
+	for _, sub := range r.subReaders {
+		size += sub.RamBytesUsed()
+	}
+	return size
+}
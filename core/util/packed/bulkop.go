@@ -84,6 +84,34 @@ func (p *BulkOperationPacked) decodeByteToLong(blocks []byte, values []int64, it
 	panic("niy")
 }
 
+func (p *BulkOperationPacked) DecodeByteToInt(blocks []byte, values []int, iterations int) {
+	blocksOff, valuesOff := 0, 0
+	nextValue, bitsLeft := 0, p.bitsPerValue
+	for i := 0; i < iterations*p.byteBlockCount; i++ {
+		bytes := int(blocks[blocksOff])
+		blocksOff++
+		if bitsLeft > 8 {
+			// just buffer
+			bitsLeft -= 8
+			nextValue |= (bytes << uint(bitsLeft))
+		} else {
+			// flush
+			bits := 8 - bitsLeft
+			values[valuesOff] = nextValue | (bytes >> uint(bits))
+			valuesOff++
+			for bits >= p.bitsPerValue {
+				bits -= p.bitsPerValue
+				values[valuesOff] = (bytes >> uint(bits)) & p.intMask
+				valuesOff++
+			}
+			// then buffer
+			bitsLeft = p.bitsPerValue - bits
+			nextValue = (bytes & ((1 << uint(bits)) - 1)) << uint(bitsLeft)
+		}
+	}
+	assert(bitsLeft == p.bitsPerValue)
+}
+
 func (p *BulkOperationPacked) encodeLongToLong(values, blocks []int64, iterations int) {
 	var nextBlock int64 = 0
 	var bitsLeft int = 64
@@ -247,7 +275,7 @@ func (p *BulkOperationPackedSingleBlock) decodeLongToLong(blocks, values []int64
 	for i := 0; i < iterations; i++ {
 		block := blocks[blocksOffset]
 		blocksOffset++
-		valuesOffset = p.decodeLongs(block, values[valuesOffset:])
+		valuesOffset += p.decodeLongs(block, values[valuesOffset:])
 	}
 }
 
@@ -256,6 +284,26 @@ func (p *BulkOperationPackedSingleBlock) decodeByteToLong(blocks []byte,
 	panic("niy")
 }
 
+func (p *BulkOperationPackedSingleBlock) DecodeByteToInt(blocks []byte,
+	values []int, iterations int) {
+
+	blocksOffset, valuesOffset := 0, 0
+	for i := 0; i < iterations; i++ {
+		var block int64
+		for j := 0; j < 8; j++ {
+			block = (block << 8) | int64(blocks[blocksOffset])
+			blocksOffset++
+		}
+		values[valuesOffset] = int(block & p.mask)
+		valuesOffset++
+		for j := 1; j < p.valueCount; j++ {
+			block = int64(uint64(block) >> uint(p.bitsPerValue))
+			values[valuesOffset] = int(block & p.mask)
+			valuesOffset++
+		}
+	}
+}
+
 func (p *BulkOperationPackedSingleBlock) encodeLongToLong(values,
 	blocks []int64, iterations int) {
 	valuesOffset, blocksOffset := 0, 0
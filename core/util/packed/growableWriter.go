@@ -70,15 +70,27 @@ func (w *GrowableWriter) Clear() {
 }
 
 func (w *GrowableWriter) getBulk(index int, arr []int64) int {
-	panic("niy")
+	return w.current.getBulk(index, arr)
 }
 
 func (w *GrowableWriter) setBulk(index int, arr []int64) int {
-	panic("niy")
+	max := int64(0)
+	for _, v := range arr {
+		// bitwise or is nice because either all values are positive and
+		// the or-ed result will require as many bits per value as the max
+		// of the values, or one of them is negative and the result will
+		// be negative, forcing GrowableWriter to use 64 bits per value
+		max |= v
+	}
+	w.ensureCapacity(max)
+	return w.current.setBulk(index, arr)
 }
 
 func (w *GrowableWriter) fill(from, to int, val int64) {
-	panic("niy")
+	w.ensureCapacity(val)
+	for i := from; i < to; i++ {
+		w.current.Set(i, val)
+	}
 }
 
 func (w *GrowableWriter) RamBytesUsed() int64 {
@@ -229,6 +229,9 @@ type PackedIntsDecoder interface {
 	// Read 8 * iterations * blockCount() blocks from blocks, decodethem and write
 	// iterations * valueCount() values inot values.
 	decodeByteToLong(blocks []byte, values []int64, iterations int)
+	// Read iterations * blockCount() blocks from blocks, decode them and
+	// write iterations * valueCount() values into values.
+	DecodeByteToInt(blocks []byte, values []int, iterations int)
 }
 
 func GetPackedIntsEncoder(format PackedFormat, version int32, bitsPerValue uint32) PackedIntsEncoder {
@@ -379,18 +382,15 @@ func (m *abstractMutable) setBulk(index int, arr []int64) int {
 
 /* Fill the mutable [from,to) with val. */
 func (m *abstractMutable) fill(from, to int, val int64) {
-	panic("niy")
-	// assert(val < MaxValue(m.BitsPerValue()))
-	// assert(from <= to)
-	// for i := from; i < to; i++ {
-	// 	m.spi.Set(i, val)
-	// }
+	assert(from <= to)
+	for i := from; i < to; i++ {
+		m.spi.Set(i, val)
+	}
 }
 
 /* Sets all values to 0 */
 func (m *abstractMutable) Clear() {
-	panic("niy")
-	// m.fill(0, int(m.Size()), 0)
+	m.fill(0, m.spi.Size(), 0)
 }
 
 func (m *abstractMutable) Save(out util.DataOutput) error {
@@ -41,27 +41,56 @@ func newPacked16ThreeBlocksFromInput(version int32, in DataInput, valueCount int
 
 func (p *Packed16ThreeBlocks) Get(index int) int64 {
 	o := index * 3
-	return int64(p.blocks[o])<<32 | int64(p.blocks[o+1])<<16 | int64(p.blocks[o])
+	return int64(uint16(p.blocks[o]))<<32 |
+		int64(uint16(p.blocks[o+1]))<<16 |
+		int64(uint16(p.blocks[o+2]))
 }
 
 func (r *Packed16ThreeBlocks) getBulk(index int, arr []int64) int {
-	panic("niy")
+	assert2(len(arr) > 0, "len must be > 0 (got %v)", len(arr))
+	assert(index >= 0 && index < r.valueCount)
+
+	gets := r.valueCount - index
+	if len(arr) < gets {
+		gets = len(arr)
+	}
+	for i := 0; i < gets; i++ {
+		arr[i] = r.Get(index + i)
+	}
+	return gets
 }
 
 func (r *Packed16ThreeBlocks) Set(index int, value int64) {
-	panic("not implemented yet")
+	o := index * 3
+	r.blocks[o] = int16(uint64(value) >> 32)
+	r.blocks[o+1] = int16(uint64(value) >> 16)
+	r.blocks[o+2] = int16(value)
 }
 
 func (r *Packed16ThreeBlocks) setBulk(index int, arr []int64) int {
-	panic("not implemented yet")
+	assert2(len(arr) > 0, "len must be > 0 (got %v)", len(arr))
+	assert(index >= 0 && index < r.valueCount)
+
+	sets := r.valueCount - index
+	if len(arr) < sets {
+		sets = len(arr)
+	}
+	for i := 0; i < sets; i++ {
+		r.Set(index+i, arr[i])
+	}
+	return sets
 }
 
 func (r *Packed16ThreeBlocks) fill(from, to int, val int64) {
-	panic("niy")
+	for i := from; i < to; i++ {
+		r.Set(i, val)
+	}
 }
 
 func (r *Packed16ThreeBlocks) Clear() {
-	panic("niy")
+	for i := range r.blocks {
+		r.blocks[i] = 0
+	}
 }
 
 func (p *Packed16ThreeBlocks) RamBytesUsed() int64 {
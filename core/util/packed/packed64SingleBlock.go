@@ -151,7 +151,10 @@ func (p *Packed64SingleBlock) setBulk(index int, arr []int64) int {
 }
 
 func (p *Packed64SingleBlock) fill(from, to int, val int64) {
-	panic("niy")
+	assert(from <= to)
+	for i := from; i < to; i++ {
+		p.Set(i, val)
+	}
 }
 
 func (p *Packed64SingleBlock) Format() PackedFormat {
@@ -40,23 +40,50 @@ func (r *Packed8ThreeBlocks) Get(index int) int64 {
 }
 
 func (r *Packed8ThreeBlocks) getBulk(index int, arr []int64) int {
-	panic("niy")
+	assert2(len(arr) > 0, "len must be > 0 (got %v)", len(arr))
+	assert(index >= 0 && index < r.valueCount)
+
+	gets := r.valueCount - index
+	if len(arr) < gets {
+		gets = len(arr)
+	}
+	for i, o := 0, index*3; i < gets; i, o = i+1, o+3 {
+		arr[i] = int64(r.blocks[o])<<16 | int64(r.blocks[o+1])<<8 | int64(r.blocks[o+2])
+	}
+	return gets
 }
 
 func (r *Packed8ThreeBlocks) Set(index int, value int64) {
-	panic("not implemented yet")
+	o := index * 3
+	r.blocks[o] = byte(uint64(value) >> 16)
+	r.blocks[o+1] = byte(uint64(value) >> 8)
+	r.blocks[o+2] = byte(value)
 }
 
-func (r *Packed8ThreeBlocks) setBulk(idnex int, arr []int64) int {
-	panic("niy")
+func (r *Packed8ThreeBlocks) setBulk(index int, arr []int64) int {
+	assert2(len(arr) > 0, "len must be > 0 (got %v)", len(arr))
+	assert(index >= 0 && index < r.valueCount)
+
+	sets := r.valueCount - index
+	if len(arr) < sets {
+		sets = len(arr)
+	}
+	for i := 0; i < sets; i++ {
+		r.Set(index+i, arr[i])
+	}
+	return sets
 }
 
 func (r *Packed8ThreeBlocks) fill(from, to int, val int64) {
-	panic("niy")
+	for i := from; i < to; i++ {
+		r.Set(i, val)
+	}
 }
 
 func (r *Packed8ThreeBlocks) Clear() {
-	panic("niy")
+	for i := range r.blocks {
+		r.blocks[i] = 0
+	}
 }
 
 func (r *Packed8ThreeBlocks) RamBytesUsed() int64 {
@@ -75,7 +75,7 @@ func (p *PackedLongValuesImpl) Iterator() func() (interface{}, bool) {
 	}
 	fillBlock()
 	return func() (v interface{}, ok bool) {
-		if pOff < currentCount {
+		if ok = pOff < currentCount; ok {
 			v = currentValues[pOff]
 			if pOff++; pOff == currentCount {
 				vOff++
@@ -83,7 +83,7 @@ func (p *PackedLongValuesImpl) Iterator() func() (interface{}, bool) {
 				fillBlock()
 			}
 		}
-		return v, pOff < currentCount
+		return
 	}
 }
 
@@ -196,7 +196,7 @@ func (b *PackedLongValuesBuilderImpl) packFrom(values []int64,
 			bitsRequired = BitsRequired(maxValue)
 		}
 		mutable := MutableFor(len(values), bitsRequired, acceptableOverheadRatio)
-		for i := 0; i < len(values); i++ {
+		for i := 0; i < len(values); {
 			i += mutable.setBulk(i, values[i:])
 		}
 		b.values[block] = mutable
@@ -204,7 +204,10 @@ func (b *PackedLongValuesBuilderImpl) packFrom(values []int64,
 }
 
 func (b *PackedLongValuesBuilderImpl) grow(newBlockCount int) {
-	panic("niy")
+	values := make([]PackedIntsReader, newBlockCount)
+	copy(values, b.values)
+	b.ramBytesUsed += util.ShallowSizeOf(values) - util.ShallowSizeOf(b.values)
+	b.values = values
 }
 
 // util/packed/DeltaPackedLongValues.java
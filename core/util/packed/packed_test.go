@@ -2,6 +2,7 @@ package packed
 
 import (
 	"fmt"
+	"github.com/gzg1984/golucene/core/store"
 	"math"
 	"math/rand"
 	"testing"
@@ -59,3 +60,193 @@ func TestUnsignedBitsRequired(t *testing.T) {
 		t.Errorf("-158146830731166066 -> 64bit (got %v)", n)
 	}
 }
+
+func TestEncodeDecodeIntToByte(t *testing.T) {
+	for format := PACKED; format <= PACKED_SINGLE_BLOCK; format++ {
+		for bpv := uint32(1); bpv <= 32; bpv++ {
+			if !PackedFormat(format).IsSupported(int(bpv)) {
+				continue
+			}
+			op := newBulkOperation(PackedFormat(format), bpv)
+			iterations := 3
+			values := make([]int, iterations*op.ByteValueCount())
+			for i, _ := range values {
+				values[i] = int(rand.Int63n(int64(1) << bpv))
+			}
+			blocks := make([]byte, iterations*op.ByteBlockCount())
+			op.EncodeIntToByte(values, blocks, iterations)
+			decoded := make([]int, len(values))
+			op.DecodeByteToInt(blocks, decoded, iterations)
+			for i, v := range values {
+				if decoded[i] != v {
+					t.Errorf("format=%v bpv=%v: value %v should be %v (got %v)",
+						format, bpv, i, v, decoded[i])
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestBlockPackedReaderWriter(t *testing.T) {
+	dir := store.NewRAMDirectory()
+	defer dir.Close()
+
+	blockSize := 64
+	values := make([]int64, 3*blockSize+17)
+	for i := range values {
+		switch i / blockSize {
+		case 0: // all equal
+			values[i] = 42
+		case 1: // negative and positive
+			values[i] = rand.Int63n(1000) - 500
+		case 2: // full range
+			values[i] = int64(rand.Uint32())<<32 | int64(rand.Uint32())
+		default:
+			values[i] = 1<<40 + rand.Int63n(1<<10)
+		}
+	}
+
+	out, err := dir.CreateOutput("bp", store.IO_CONTEXT_DEFAULT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewBlockPackedWriter(out, blockSize)
+	for _, v := range values {
+		if err = w.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if err = out.WriteByte(7); err != nil {
+		t.Fatal(err)
+	}
+	if err = out.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	in, err := dir.OpenInput("bp", store.IO_CONTEXT_DEFAULT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	r, err := NewBlockPackedReader(in, VERSION_CURRENT, blockSize, int64(len(values)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range values {
+		if got := r.Get(int64(i)); got != v {
+			t.Fatalf("value %v should be %v (got %v)", i, v, got)
+		}
+	}
+	if b, err := in.ReadByte(); err != nil || b != 7 {
+		t.Errorf("reader should consume exactly the written blocks (got %v, %v)", b, err)
+	}
+}
+
+func TestMonotonicBlockPackedReaderWriter(t *testing.T) {
+	dir := store.NewRAMDirectory()
+	defer dir.Close()
+
+	blockSize := 64
+	values := make([]int64, 2*blockSize+5)
+	for i := 1; i < len(values); i++ {
+		values[i] = values[i-1] + rand.Int63n(100)
+	}
+
+	out, err := dir.CreateOutput("mbp", store.IO_CONTEXT_DEFAULT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewMonotonicBlockPackedWriter(out, blockSize)
+	for _, v := range values {
+		if err = w.Add(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if err = out.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	in, err := dir.OpenInput("mbp", store.IO_CONTEXT_DEFAULT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	r, err := NewMonotonicBlockPackedReader(in, VERSION_CURRENT, blockSize, int64(len(values)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range values {
+		if got := r.Get(int64(i)); got != v {
+			t.Fatalf("value %v should be %v (got %v)", i, v, got)
+		}
+	}
+}
+
+func TestMutableBulkUnaligned(t *testing.T) {
+	for bpv := 1; bpv <= 64; bpv++ {
+		for _, ratio := range []float32{PackedInts.COMPACT, PackedInts.DEFAULT, PackedInts.FASTEST} {
+			valueCount := 200 + rand.Intn(300)
+			values := make([]int64, valueCount)
+			for i := range values {
+				values[i] = rand.Int63() & MaxValue(bpv)
+			}
+			m := MutableFor(valueCount, bpv, ratio)
+			for i := 0; i < valueCount; {
+				end := i + 1 + rand.Intn(70)
+				if end > valueCount {
+					end = valueCount
+				}
+				i += m.setBulk(i, values[i:end])
+			}
+			for i, v := range values {
+				if got := m.Get(i); got != v {
+					t.Fatalf("%v: value %v should be %v (got %v)", m, i, v, got)
+				}
+			}
+			got := make([]int64, valueCount)
+			for i := 0; i < valueCount; {
+				end := i + 1 + rand.Intn(70)
+				if end > valueCount {
+					end = valueCount
+				}
+				i += m.getBulk(i, got[i:end])
+			}
+			for i, v := range values {
+				if got[i] != v {
+					t.Fatalf("%v: bulk value %v should be %v (got %v)", m, i, v, got[i])
+				}
+			}
+		}
+	}
+}
+
+func TestPackedLongValues(t *testing.T) {
+	valueCount := 20*DEFAULT_PAGE_SIZE + 17
+	values := make([]int64, valueCount)
+	builder := DeltaPackedBuilder(PackedInts.COMPACT)
+	for i := range values {
+		values[i] = rand.Int63n(int64(1) << uint(1+i%40))
+		builder.Add(values[i])
+	}
+	packed := builder.Build()
+	if n := packed.Size(); n != int64(valueCount) {
+		t.Fatalf("size should be %v (got %v)", valueCount, n)
+	}
+	it := packed.Iterator()
+	for i, v := range values {
+		got, ok := it()
+		if !ok || got.(int64) != v {
+			t.Fatalf("value %v should be %v (got %v, %v)", i, v, got, ok)
+		}
+	}
+	if _, ok := it(); ok {
+		t.Fatal("iterator should be exhausted")
+	}
+}
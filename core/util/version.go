@@ -16,6 +16,8 @@ var (
 	VERSION_4_0 = Version([4]int{4, 0, 0, 0})
 	// Match settings and bugs in Lucene's 4.5 release.
 	VERSION_45 = Version([4]int{4, 5, 0, 0})
+	// Match settings and bugs in Lucene's 4.8 release.
+	VERSION_48 = Version([4]int{4, 8, 0, 0})
 	// Match settings and bugs in Lucene's 4.9 release.
 	// Use this to get the latest and greatest settings, bug fixes, etc,
 	// for Lucnee.
@@ -1,20 +1,28 @@
 package core_test
 
 import (
+	"bytes"
 	"fmt"
 	std "github.com/gzg1984/golucene/analysis/standard"
-	_ "github.com/gzg1984/golucene/core/codec/lucene410"
+	_ "github.com/gzg1984/golucene/core/codec/lucene71"
+	"github.com/gzg1984/golucene/core/codec/spi"
 	docu "github.com/gzg1984/golucene/core/document"
 	"github.com/gzg1984/golucene/core/index"
 	"github.com/gzg1984/golucene/core/search"
+	"github.com/gzg1984/golucene/core/search/model"
 	"github.com/gzg1984/golucene/core/store"
 	"github.com/gzg1984/golucene/core/util"
+	"math"
 	// . "github.com/gzg1984/golucene/test_framework"
 	// "github.com/gzg1984/golucene/test_framework/analysis"
 	// . "github.com/gzg1984/golucene/test_framework/util"
 	. "github.com/gzg1984/gounit"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // Hook up custom test logic into Go's test runner.
@@ -122,11 +130,1109 @@ func TestBasicIndexAndSearch(t *testing.T) {
 // 	})
 // }
 
+func TestDocValuesIndexAndRead(t *testing.T) {
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	colors := []string{"red", "green", "blue"}
+	for i := 0; i < 10; i++ {
+		d := docu.NewDocument()
+		d.Add(docu.NewTextFieldFromString("body", "some text", docu.STORE_NO))
+		if i != 3 {
+			d.Add(docu.NewNumericDocValuesField("price", int64(i*10)))
+		}
+		d.Add(docu.NewBinaryDocValuesField("payload", []byte(fmt.Sprintf("p%v", i))))
+		d.Add(docu.NewSortedDocValuesField("color", []byte(colors[i%3])))
+		d.Add(docu.NewSortedSetDocValuesField("tags", []byte(colors[i%3])))
+		d.Add(docu.NewSortedSetDocValuesField("tags", []byte("all")))
+		err = writer.AddDocument(d.Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+	}
+
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+	It(t).Should("expect 1 leaf, got %v", len(reader.Leaves())).Assert(len(reader.Leaves()) == 1)
+	leaf := reader.Leaves()[0].Reader().(index.AtomicReader)
+
+	prices, err := leaf.NumericDocValues("price")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	docsWithPrice, err := leaf.DocsWithField("price")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	payloads, err := leaf.BinaryDocValues("payload")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	color, err := leaf.SortedDocValues("color")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 3 colors, got %v", color.ValueCount()).Verify(color.ValueCount() == 3)
+	tags, err := leaf.SortedSetDocValues("tags")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	for doc := 0; doc < 10; doc++ {
+		expected := int64(doc * 10)
+		if doc == 3 {
+			expected = 0
+		}
+		It(t).Should("doc %v: expect price %v, got %v", doc, expected, prices(doc)).Verify(prices(doc) == expected)
+		It(t).Should("doc %v: wrong docsWithField", doc).Verify(docsWithPrice.At(doc) == (doc != 3))
+		payload := string(payloads.Get(doc))
+		It(t).Should("doc %v: expect payload p%v, got %v", doc, doc, payload).Verify(payload == fmt.Sprintf("p%v", doc))
+		c := string(color.Get(doc))
+		It(t).Should("doc %v: expect color %v, got %v", doc, colors[doc%3], c).Verify(c == colors[doc%3])
+
+		var values []string
+		tags.SetDocument(doc)
+		for ord := tags.NextOrd(); ord != spi.NO_MORE_ORDS; ord = tags.NextOrd() {
+			values = append(values, string(tags.LookupOrd(ord)))
+		}
+		It(t).Should("doc %v: expect tags [all %v], got %v", doc, colors[doc%3], values).Verify(
+			len(values) == 2 && values[0] == "all" && values[1] == colors[doc%3])
+	}
+}
+
+func TestDocValuesManyDocs(t *testing.T) {
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// several hundred docs per segment, with enough distinct values to
+	// span multiple packed blocks and bit widths
+	const numDocs, perSegment = 1500, 700
+	price := func(doc int) int64 { return int64(doc*doc)*7919 - 1000000 }
+	payload := func(doc int) string { return strings.Repeat(fmt.Sprintf("p%v", doc), 1+doc%5) }
+	color := func(doc int) string { return fmt.Sprintf("c%04d", doc%373) }
+	tags := func(doc int) []string {
+		ans := []string{fmt.Sprintf("t%04d", doc%511)}
+		if doc%3 == 0 {
+			ans = append(ans, fmt.Sprintf("t%04d", (doc+7)%511+511))
+		}
+		return ans
+	}
+	for i := 0; i < numDocs; i++ {
+		d := docu.NewDocument()
+		d.Add(docu.NewNumericDocValuesField("price", price(i)))
+		d.Add(docu.NewBinaryDocValuesField("payload", []byte(payload(i))))
+		d.Add(docu.NewSortedDocValuesField("color", []byte(color(i))))
+		for _, tag := range tags(i) {
+			d.Add(docu.NewSortedSetDocValuesField("tags", []byte(tag)))
+		}
+		err = writer.AddDocument(d.Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		if (i+1)%perSegment == 0 {
+			err = writer.Commit()
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+		}
+	}
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+	It(t).Should("expect %v docs, got %v", numDocs, reader.MaxDoc()).Assert(reader.MaxDoc() == numDocs)
+	It(t).Should("expect 3 leaves, got %v", len(reader.Leaves())).Assert(len(reader.Leaves()) == 3)
+
+	for _, ctx := range reader.Leaves() {
+		leaf := ctx.Reader().(index.AtomicReader)
+		prices, err := leaf.NumericDocValues("price")
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		payloads, err := leaf.BinaryDocValues("payload")
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		colors, err := leaf.SortedDocValues("color")
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		tagValues, err := leaf.SortedSetDocValues("tags")
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+
+		for doc := 0; doc < leaf.MaxDoc(); doc++ {
+			id := ctx.DocBase + doc
+			It(t).Should("doc %v: expect price %v, got %v", id, price(id), prices(doc)).Assert(prices(doc) == price(id))
+			p := string(payloads.Get(doc))
+			It(t).Should("doc %v: expect payload %v, got %v", id, payload(id), p).Assert(p == payload(id))
+			c := string(colors.Get(doc))
+			It(t).Should("doc %v: expect color %v, got %v", id, color(id), c).Assert(c == color(id))
+
+			var values []string
+			tagValues.SetDocument(doc)
+			for ord := tagValues.NextOrd(); ord != spi.NO_MORE_ORDS; ord = tagValues.NextOrd() {
+				values = append(values, string(tagValues.LookupOrd(ord)))
+			}
+			expected := tags(id)
+			It(t).Should("doc %v: expect tags %v, got %v", id, expected, values).Assert(
+				fmt.Sprint(values) == fmt.Sprint(expected))
+		}
+	}
+}
+
+func TestPostingsAdvance(t *testing.T) {
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// enough docs for several skip levels; "first" has a docFreq that
+	// is an exact multiple of the block size, and "tens" leaves gaps
+	// between its docs
+	const numDocs = 5000
+	tokens := func(doc int) []string {
+		ans := strings.Split(strings.Repeat("all ", 1+doc%3), " ")
+		ans = ans[:len(ans)-1]
+		if doc%2 == 0 {
+			ans = append(ans, "even")
+		}
+		if doc%10 == 0 {
+			ans = append(ans, "tens")
+		}
+		if doc < 1024 {
+			ans = append(ans, "first")
+		}
+		return ans
+	}
+	positions := func(doc int, term string) []int {
+		var ans []int
+		for i, token := range tokens(doc) {
+			if token == term {
+				ans = append(ans, i)
+			}
+		}
+		return ans
+	}
+	for i := 0; i < numDocs; i++ {
+		d := docu.NewDocument()
+		d.Add(docu.NewTextFieldFromString("body", strings.Join(tokens(i), " "), docu.STORE_NO))
+		err = writer.AddDocument(d.Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+	}
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+
+	for _, ctx := range reader.Leaves() {
+		leaf := ctx.Reader().(index.AtomicReader)
+		for _, term := range []string{"all", "even", "tens", "first"} {
+			var expected []int
+			for doc := 0; doc < leaf.MaxDoc(); doc++ {
+				if len(positions(ctx.DocBase+doc, term)) > 0 {
+					expected = append(expected, doc)
+				}
+			}
+			// first expected doc >= target
+			next := func(target int) int {
+				for _, doc := range expected {
+					if doc >= target {
+						return doc
+					}
+				}
+				return model.NO_MORE_DOCS
+			}
+
+			termsEnum := leaf.Terms("body").Iterator(nil)
+			ok, err := termsEnum.SeekExact([]byte(term))
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+			It(t).Should("expect term %v", term).Assert(ok)
+
+			docs, err := termsEnum.Docs(nil, nil)
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+			postings, err := termsEnum.DocsAndPositions(nil, nil)
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+
+			doc, target := -1, 0
+			for k := 0; doc != model.NO_MORE_DOCS; k++ {
+				var got, gotPos int
+				want := next(target)
+				if k%3 == 2 {
+					// interleave NextDoc with Advance
+					want = next(doc + 1)
+					got, err = docs.NextDoc()
+					It(t).Should("has no error: %v", err).Assert(err == nil)
+					gotPos, err = postings.NextDoc()
+				} else {
+					got, err = docs.Advance(target)
+					It(t).Should("has no error: %v", err).Assert(err == nil)
+					gotPos, err = postings.Advance(target)
+				}
+				It(t).Should("has no error: %v", err).Assert(err == nil)
+				It(t).Should("%v: expect doc %v after %v, got %v", term, want, doc, got).Assert(got == want)
+				It(t).Should("%v: expect doc %v after %v, got %v", term, want, doc, gotPos).Assert(gotPos == want)
+
+				if doc = got; doc != model.NO_MORE_DOCS {
+					expectedPos := positions(ctx.DocBase+doc, term)
+					freq, err := docs.Freq()
+					It(t).Should("has no error: %v", err).Assert(err == nil)
+					It(t).Should("%v: doc %v expect freq %v, got %v", term, doc, len(expectedPos), freq).Assert(
+						freq == len(expectedPos))
+					freq, err = postings.Freq()
+					It(t).Should("has no error: %v", err).Assert(err == nil)
+					It(t).Should("%v: doc %v expect freq %v, got %v", term, doc, len(expectedPos), freq).Assert(
+						freq == len(expectedPos))
+					for _, want := range expectedPos {
+						pos, err := postings.NextPosition()
+						It(t).Should("has no error: %v", err).Assert(err == nil)
+						It(t).Should("%v: doc %v expect position %v, got %v", term, doc, want, pos).Assert(pos == want)
+					}
+				}
+				target = doc + 1 + (k*k*37)%700
+			}
+		}
+	}
+}
+
+func TestDocValuesTypeMismatch(t *testing.T) {
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	d := docu.NewDocument()
+	d.Add(docu.NewNumericDocValuesField("dv", 17))
+	err = writer.AddDocument(d.Fields())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	defer func() {
+		msg, _ := recover().(string)
+		It(t).Should("reject changing the doc values type of a field, got '%v'", msg).Verify(
+			strings.Contains(msg, "cannot change DocValues type from"))
+	}()
+	d = docu.NewDocument()
+	d.Add(docu.NewSortedDocValuesField("dv", []byte("foo")))
+	writer.AddDocument(d.Fields())
+}
+
+func TestNumericDocValuesRequireInteger(t *testing.T) {
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	ft := docu.NewFieldTypeFrom(docu.FLOAT_FIELD_TYPE_NOT_STORED)
+	ft.SetDocValueType(docu.NUMERIC_DOC_VALUES_FIELD_TYPE.DocValueType())
+	d := docu.NewDocument()
+	d.Add(docu.NewFloatFieldWithType("dv", 1.5, ft))
+	err = writer.AddDocument(d.Fields())
+	It(t).Should("reject a float numeric doc value, got %v", err).Assert(
+		err != nil && strings.Contains(err.Error(), "numeric doc values require an integer value"))
+
+	// the writer is still usable, and the rejected document is dropped
+	d = docu.NewDocument()
+	d.Add(docu.NewNumericDocValuesField("dv", 17))
+	err = writer.AddDocument(d.Fields())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+	It(t).Should("expect 1 live doc, got %v", reader.NumDocs()).Assert(reader.NumDocs() == 1)
+}
+
+func TestDocValuesUpdates(t *testing.T) {
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	addDoc := func(i int) {
+		d := docu.NewDocument()
+		d.Add(docu.NewFieldFromString("id", fmt.Sprintf("doc%v", i), docu.STRING_FIELD_TYPE_NOT_STORED))
+		if i != 3 {
+			d.Add(docu.NewNumericDocValuesField("price", int64(i*10)))
+		}
+		d.Add(docu.NewBinaryDocValuesField("payload", []byte(fmt.Sprintf("p%v", i))))
+		err := writer.AddDocument(d.Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+	}
+	for i := 0; i < 5; i++ {
+		addDoc(i)
+	}
+	err = writer.Commit()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// updates of flushed documents
+	err = writer.UpdateNumericDocValue(index.NewTerm("id", "doc1"), "price", 100)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = writer.UpdateNumericDocValue(index.NewTerm("id", "doc3"), "price", 300)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = writer.UpdateBinaryDocValue(index.NewTerm("id", "doc2"), "payload", []byte("updated"))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	// the last update of a document wins
+	err = writer.UpdateNumericDocValue(index.NewTerm("id", "doc1"), "price", 110)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// updates of documents still buffered in RAM
+	for i := 5; i < 8; i++ {
+		addDoc(i)
+	}
+	err = writer.UpdateNumericDocValue(index.NewTerm("id", "doc6"), "price", 600)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	// documents added after an update are not affected by it
+	err = writer.UpdateBinaryDocValue(index.NewTerm("id", "doc8"), "payload", []byte("early"))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	addDoc(8)
+
+	err = writer.UpdateNumericDocValue(index.NewTerm("id", "doc0"), "missing", 1)
+	It(t).Should("reject updating a non-existent field").Verify(err != nil)
+	err = writer.UpdateBinaryDocValue(index.NewTerm("id", "doc0"), "price", []byte("x"))
+	It(t).Should("reject updating a field of another type").Verify(err != nil)
+
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+
+	prices := map[int]int64{1: 110, 3: 300, 6: 600}
+	payloads := map[int]string{2: "updated"}
+	seen := 0
+	for _, ctx := range reader.Leaves() {
+		leaf := ctx.Reader().(index.AtomicReader)
+		price, err := leaf.NumericDocValues("price")
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		docsWithPrice, err := leaf.DocsWithField("price")
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		payload, err := leaf.BinaryDocValues("payload")
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+
+		for doc := 0; doc < leaf.MaxDoc(); doc++ {
+			i := ctx.DocBase + doc
+			expected, ok := prices[i]
+			if !ok && i != 3 {
+				expected = int64(i * 10)
+			}
+			It(t).Should("doc %v: expect price %v, got %v", i, expected, price(doc)).Verify(price(doc) == expected)
+			It(t).Should("doc %v: expect a price", i).Verify(docsWithPrice.At(doc))
+			expectedPayload, ok := payloads[i]
+			if !ok {
+				expectedPayload = fmt.Sprintf("p%v", i)
+			}
+			got := string(payload.Get(doc))
+			It(t).Should("doc %v: expect payload %v, got %v", i, expectedPayload, got).Verify(got == expectedPayload)
+			seen++
+		}
+	}
+	It(t).Should("expect 9 docs, got %v", seen).Verify(seen == 9)
+}
+
+func TestDeleteDocuments(t *testing.T) {
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	newDoc := func(i int, color string) *docu.Document {
+		d := docu.NewDocument()
+		d.Add(docu.NewFieldFromString("id", fmt.Sprintf("doc%v", i), docu.STRING_FIELD_TYPE_NOT_STORED))
+		d.Add(docu.NewFieldFromString("color", color, docu.STRING_FIELD_TYPE_NOT_STORED))
+		return d
+	}
+	colorOf := func(i int) string {
+		return map[bool]string{true: "red", false: "blue"}[i%2 == 0]
+	}
+	for i := 0; i < 6; i++ {
+		err = writer.AddDocument(newDoc(i, colorOf(i)).Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+	}
+	err = writer.Commit()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// delete of a flushed document
+	err = writer.DeleteDocuments(index.NewTerm("id", "doc1"))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// delete of a document still buffered in RAM
+	for i := 6; i < 10; i++ {
+		err = writer.AddDocument(newDoc(i, colorOf(i)).Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+	}
+	err = writer.DeleteDocuments(index.NewTerm("id", "doc7"))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// delete by query, of both flushed and buffered documents
+	err = writer.DeleteDocumentsByQuery(search.NewTermQuery(index.NewTerm("color", "red")))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// documents added after a delete are not affected by it
+	err = writer.UpdateDocument(index.NewTerm("id", "doc9"), newDoc(9, "red").Fields(), std.NewStandardAnalyzer())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	countHits := func(reader index.IndexReader, field, text string) int {
+		searcher := search.NewIndexSearcher(reader)
+		res, err := searcher.Search(search.NewTermQuery(index.NewTerm(field, text)), nil, 100)
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		return res.TotalHits
+	}
+
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	// docs 3, 5 and the updated doc9 remain
+	It(t).Should("expect 3 live docs, got %v", reader.NumDocs()).Verify(reader.NumDocs() == 3)
+	It(t).Should("expect 10+ docs, got %v", reader.MaxDoc()).Verify(reader.MaxDoc() >= 10)
+	n := countHits(reader, "color", "blue")
+	It(t).Should("expect 2 blue docs, got %v", n).Verify(n == 2)
+	n = countHits(reader, "color", "red")
+	It(t).Should("expect 1 red doc, got %v", n).Verify(n == 1)
+	for _, id := range []string{"doc0", "doc1", "doc7"} {
+		n = countHits(reader, "id", id)
+		It(t).Should("expect %v to be deleted, got %v hits", id, n).Verify(n == 0)
+	}
+	n = countHits(reader, "id", "doc9")
+	It(t).Should("expect a single doc9, got %v", n).Verify(n == 1)
+	err = reader.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// delete all, on a reopened index
+	conf = index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err = index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = writer.AddDocument(newDoc(10, "blue").Fields())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = writer.DeleteAll()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = writer.AddDocument(newDoc(11, "green").Fields())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	reader, err = index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+	It(t).Should("expect 1 doc, got %v", reader.NumDocs()).Verify(reader.NumDocs() == 1 && reader.MaxDoc() == 1)
+	n = countHits(reader, "color", "green")
+	It(t).Should("expect 1 green doc, got %v", n).Verify(n == 1)
+}
+
+func TestNRTReader(t *testing.T) {
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	newDoc := func(i int) *docu.Document {
+		d := docu.NewDocument()
+		d.Add(docu.NewFieldFromString("id", fmt.Sprintf("doc%v", i), docu.STRING_FIELD_TYPE_NOT_STORED))
+		return d
+	}
+	for i := 0; i < 5; i++ {
+		err = writer.AddDocument(newDoc(i).Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+	}
+
+	// uncommitted documents are visible to a near real-time reader
+	reader, err := index.OpenDirectoryReaderFromWriter(writer, true)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 5 docs, got %v", reader.NumDocs()).Verify(reader.NumDocs() == 5)
+	It(t).Should("expect reader to be current").Verify(reader.IsCurrent())
+
+	// nothing changed
+	changed, err := index.OpenDirectoryReaderIfChanged(reader)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect no new reader").Verify(changed == nil)
+
+	for i := 5; i < 8; i++ {
+		err = writer.AddDocument(newDoc(i).Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+	}
+	err = writer.DeleteDocuments(index.NewTerm("id", "doc0"), index.NewTerm("id", "doc6"))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect reader not to be current").Verify(!reader.IsCurrent())
+
+	changed, err = index.OpenDirectoryReaderIfChangedFromWriter(reader, writer, true)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect a new reader").Assert(changed != nil)
+	It(t).Should("expect 6 docs, got %v", changed.NumDocs()).Verify(changed.NumDocs() == 6)
+	// the old reader still sees its point-in-time view
+	It(t).Should("expect 5 docs, got %v", reader.NumDocs()).Verify(reader.NumDocs() == 5)
+	err = reader.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	reader = changed
+
+	searcher := search.NewIndexSearcher(reader)
+	res, err := searcher.Search(search.NewTermQuery(index.NewTerm("id", "doc6")), nil, 10)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect doc6 to be deleted, got %v hits", res.TotalHits).Verify(res.TotalHits == 0)
+
+	// a reader opened on the last commit picks up the next commit
+	err = writer.Commit()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	committed, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 6 docs, got %v", committed.NumDocs()).Verify(committed.NumDocs() == 6)
+
+	err = writer.DeleteDocuments(index.NewTerm("id", "doc1"))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = writer.AddDocument(newDoc(8).Fields())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	changed, err = index.OpenDirectoryReaderIfChanged(committed)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect no new reader before commit").Verify(changed == nil)
+	err = writer.Commit()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	changed, err = index.OpenDirectoryReaderIfChanged(committed)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect a new reader").Assert(changed != nil)
+	It(t).Should("expect 6 docs, got %v", changed.NumDocs()).Verify(changed.NumDocs() == 6)
+	err = committed.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = changed.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// NRT readers stay usable after the writer is closed
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 6 docs, got %v", reader.NumDocs()).Verify(reader.NumDocs() == 6)
+	// the reader predates the last commits, so it falls back to the segments file
+	It(t).Should("expect reader not to be current").Verify(!reader.IsCurrent())
+	err = reader.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+}
+
+func TestSearcherManager(t *testing.T) {
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	tracking := index.NewTrackingIndexWriter(writer)
+
+	newDoc := func(i int) *docu.Document {
+		d := docu.NewDocument()
+		d.Add(docu.NewFieldFromString("id", fmt.Sprintf("doc%v", i), docu.STRING_FIELD_TYPE_NOT_STORED))
+		d.Add(docu.NewFieldFromString("body", "shared", docu.STRING_FIELD_TYPE_NOT_STORED))
+		return d
+	}
+	_, err = tracking.AddDocument(newDoc(0).Fields())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	manager, err := search.NewSearcherManager(writer, true, nil)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	searcher, err := manager.Acquire()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	n := searcher.IndexReader().NumDocs()
+	It(t).Should("expect 1 doc, got %v", n).Verify(n == 1)
+	current, err := manager.IsSearcherCurrent()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect searcher to be current").Verify(current)
+
+	// searches from many goroutines while the index changes and is
+	// refreshed
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				s, err := manager.Acquire()
+				if err != nil {
+					errs <- err
+					return
+				}
+				res, err := s.Search(search.NewTermQuery(index.NewTerm("body", "shared")), nil, 10)
+				if err == nil && res.TotalHits != s.IndexReader().NumDocs() {
+					err = fmt.Errorf("expect %v hits, got %v", s.IndexReader().NumDocs(), res.TotalHits)
+				}
+				if err2 := manager.Release(s); err == nil {
+					err = err2
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	reopener := search.NewControlledRealTimeReopenThread(tracking,
+		manager.ReferenceManager, time.Second, 10*time.Millisecond)
+	var gen int64
+	for i := 1; i < 20; i++ {
+		gen, err = tracking.AddDocument(newDoc(i).Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		if i%5 == 0 {
+			_, err = manager.MaybeRefresh()
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+		}
+	}
+	gen, err = tracking.DeleteDocuments(index.NewTerm("id", "doc3"))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	ok := reopener.WaitForGenerationTimeout(gen, 10*time.Second)
+	It(t).Should("expect generation %v to become visible", gen).Assert(ok)
+	close(stop)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	err = reopener.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// the old searcher still sees its point-in-time view
+	n = searcher.IndexReader().NumDocs()
+	It(t).Should("expect 1 doc, got %v", n).Verify(n == 1)
+	err = manager.Release(searcher)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	searcher, err = manager.Acquire()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	n = searcher.IndexReader().NumDocs()
+	It(t).Should("expect 19 docs, got %v", n).Verify(n == 19)
+	err = manager.Release(searcher)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	err = manager.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	_, err = manager.Acquire()
+	It(t).Should("expect an error after close").Verify(err != nil)
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+}
+
+type countingCollector struct {
+	count int
+}
+
+func (c *countingCollector) SetScorer(s search.Scorer)                      {}
+func (c *countingCollector) SetNextReader(*index.AtomicReaderContext) error { return nil }
+func (c *countingCollector) AcceptsDocsOutOfOrder() bool                    { return true }
+func (c *countingCollector) Collect(doc int) error {
+	c.count++
+	return nil
+}
+
+type countingCollectorManager struct{}
+
+func (m countingCollectorManager) NewCollector() (search.Collector, error) {
+	return &countingCollector{}, nil
+}
+
+func (m countingCollectorManager) Reduce(collectors []search.Collector) (interface{}, error) {
+	total := 0
+	for _, c := range collectors {
+		total += c.(*countingCollector).count
+	}
+	return total, nil
+}
+
+func TestConcurrentSearch(t *testing.T) {
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// 6 segments, with repeated scores and prices
+	for i := 0; i < 60; i++ {
+		d := docu.NewDocument()
+		d.Add(docu.NewTextFieldFromString("body", strings.Repeat("word ", i%4+1)+"filler", docu.STORE_NO))
+		d.Add(docu.NewNumericDocValuesField("price", int64(i%7)))
+		err = writer.AddDocument(d.Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		if i%10 == 9 {
+			err = writer.Commit()
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+		}
+	}
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+	It(t).Should("expect 6 leaves, got %v", len(reader.Leaves())).Assert(len(reader.Leaves()) == 6)
+
+	sequential := search.NewIndexSearcher(reader)
+	concurrent := search.NewIndexSearcher(reader)
+	// one slice per segment
+	concurrent.SetConcurrency(4, 1)
+
+	sameHits := func(msg string, expected, actual []*search.ScoreDoc) {
+		It(t).Should("%v: expect %v hits, got %v", msg, len(expected), len(actual)).Assert(len(expected) == len(actual))
+		for i := range expected {
+			It(t).Should("%v: hit %v: expect doc %v (%v), got doc %v (%v)", msg, i,
+				expected[i].Doc, expected[i].Score, actual[i].Doc, actual[i].Score).Verify(
+				expected[i].Doc == actual[i].Doc && (isSimilar(expected[i].Score, actual[i].Score, 0.0001) ||
+					math.IsNaN(float64(expected[i].Score)) && math.IsNaN(float64(actual[i].Score))))
+		}
+	}
+
+	q := search.NewTermQuery(index.NewTerm("body", "word"))
+	expected, err := sequential.SearchTop(q, 25)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	actual, err := concurrent.SearchTop(q, 25)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 60 hits, got %v", actual.TotalHits).Verify(actual.TotalHits == 60)
+	It(t).Should("expect max score %v, got %v", expected.MaxScore(), actual.MaxScore()).Verify(
+		isSimilar(float32(expected.MaxScore()), float32(actual.MaxScore()), 0.0001))
+	sameHits("by score", expected.ScoreDocs, actual.ScoreDocs)
+
+	sort := search.NewSort(search.NewSortField("price", search.SORT_FIELD_TYPE_LONG, true))
+	expectedSorted, err := sequential.SearchSorted(q, nil, 15, sort)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	actualSorted, err := concurrent.SearchSorted(q, nil, 15, sort)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 60 hits, got %v", actualSorted.TotalHits).Verify(actualSorted.TotalHits == 60)
+	sameHits("by price", expectedSorted.ScoreDocs, actualSorted.ScoreDocs)
+	for i, fd := range actualSorted.FieldDocs {
+		It(t).Should("hit %v: expect price 6, got %v", i, fd.Fields[0]).Verify(i >= 8 || fd.Fields[0] == int64(6))
+	}
+
+	count, err := concurrent.SearchWithCollectorManager(q, nil, countingCollectorManager{})
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 60 collected docs, got %v", count).Verify(count == 60)
+}
+
+func TestSearchAfter(t *testing.T) {
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	// 6 segments, with repeated scores, prices and tags, and some docs
+	// without tag
+	for i := 0; i < 60; i++ {
+		d := docu.NewDocument()
+		d.Add(docu.NewTextFieldFromString("body", strings.Repeat("word ", i%4+1)+"filler", docu.STORE_NO))
+		d.Add(docu.NewNumericDocValuesField("price", int64(i%7)))
+		if i%5 != 0 {
+			d.Add(docu.NewSortedDocValuesField("tag", []byte(fmt.Sprintf("tag%v", i%3))))
+		}
+		err = writer.AddDocument(d.Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		if i%10 == 9 {
+			err = writer.Commit()
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+		}
+	}
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+
+	sameDocs := func(msg string, expected, actual []*search.ScoreDoc) {
+		It(t).Should("%v: expect %v hits, got %v", msg, len(expected), len(actual)).Assert(len(expected) == len(actual))
+		for i := range expected {
+			It(t).Should("%v: hit %v: expect doc %v, got doc %v", msg, i,
+				expected[i].Doc, actual[i].Doc).Verify(expected[i].Doc == actual[i].Doc)
+		}
+	}
+
+	tag := search.NewSortField("tag", search.SORT_FIELD_TYPE_STRING, false)
+	err = tag.SetMissingValue(search.SORT_FIELD_STRING_LAST)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	price := search.NewSortField("price", search.SORT_FIELD_TYPE_LONG, true)
+	sorts := []*search.Sort{
+		search.NewSort(price),
+		search.NewSort(tag, price),
+		search.NewSort(price, search.FIELD_SCORE),
+	}
+
+	q := search.NewTermQuery(index.NewTerm("body", "word"))
+	for _, workers := range []int{1, 4} {
+		searcher := search.NewIndexSearcher(reader)
+		searcher.SetConcurrency(workers, 1)
+
+		all, err := searcher.SearchTop(q, 60)
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		var paged []*search.ScoreDoc
+		var after *search.ScoreDoc
+		for pages := 0; ; pages++ {
+			It(t).Should("expect at most 9 pages").Assert(pages < 10)
+			page, err := searcher.SearchAfter(after, q, nil, 7)
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+			It(t).Should("expect 60 hits, got %v", page.TotalHits).Verify(page.TotalHits == 60)
+			if len(page.ScoreDocs) == 0 {
+				break
+			}
+			paged = append(paged, page.ScoreDocs...)
+			after = page.ScoreDocs[len(page.ScoreDocs)-1]
+		}
+		sameDocs(fmt.Sprintf("%v workers, by score", workers), all.ScoreDocs, paged)
+
+		for _, sort := range sorts {
+			allSorted, err := searcher.SearchSorted(q, nil, 60, sort)
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+			var pagedSorted []*search.ScoreDoc
+			var afterSorted *search.FieldDoc
+			for pages := 0; ; pages++ {
+				It(t).Should("expect at most 9 pages").Assert(pages < 10)
+				page, err := searcher.SearchAfterSorted(afterSorted, q, nil, 7, sort)
+				It(t).Should("has no error: %v", err).Assert(err == nil)
+				It(t).Should("expect 60 hits, got %v", page.TotalHits).Verify(page.TotalHits == 60)
+				if len(page.FieldDocs) == 0 {
+					break
+				}
+				pagedSorted = append(pagedSorted, page.ScoreDocs...)
+				afterSorted = page.FieldDocs[len(page.FieldDocs)-1]
+			}
+			sameDocs(fmt.Sprintf("%v workers, by %v", workers, sort), allSorted.ScoreDocs, pagedSorted)
+		}
+	}
+}
+
+func TestMergeShards(t *testing.T) {
+	// Two independent indexes; the second one holds ids 30..59
+	var searchers []*search.IndexSearcher
+	for shard := 0; shard < 2; shard++ {
+		directory, err := store.OpenFSDirectory(t.TempDir())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		defer directory.Close()
+
+		conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+		writer, err := index.NewIndexWriter(directory, conf)
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		for i := shard * 30; i < shard*30+30; i++ {
+			d := docu.NewDocument()
+			d.Add(docu.NewTextFieldFromString("body", strings.Repeat("word ", i%4+1)+"filler", docu.STORE_NO))
+			d.Add(docu.NewNumericDocValuesField("price", int64(i%7)))
+			d.Add(docu.NewNumericDocValuesField("id", int64(i)))
+			err = writer.AddDocument(d.Fields())
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+		}
+		err = writer.Close()
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+
+		reader, err := index.OpenDirectoryReader(directory)
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		defer reader.Close()
+		searchers = append(searchers, search.NewIndexSearcher(reader))
+	}
+	q := search.NewTermQuery(index.NewTerm("body", "word"))
+
+	shardHits := make([]search.TopDocs, len(searchers))
+	for i, searcher := range searchers {
+		var err error
+		shardHits[i], err = searcher.SearchTop(q, 10)
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+	}
+	merged := search.MergeTopDocs(10, shardHits)
+	It(t).Should("expect 60 hits, got %v", merged.TotalHits).Verify(merged.TotalHits == 60)
+	It(t).Should("expect 10 merged hits, got %v", len(merged.ScoreDocs)).Assert(len(merged.ScoreDocs) == 10)
+	for i, hit := range merged.ScoreDocs {
+		found := false
+		for _, shardHit := range shardHits[hit.ShardIndex].ScoreDocs {
+			found = found || shardHit == hit
+		}
+		It(t).Should("hit %v (%v) is not a hit of its shard", i, hit).Verify(found)
+		It(t).Should("hit %v: scores are not sorted", i).Verify(i == 0 || merged.ScoreDocs[i-1].Score >= hit.Score)
+	}
+
+	// Page through the shards by price, breaking ties by id: each shard
+	// resumes after the last of its hits that was returned
+	sort := search.NewSort(
+		search.NewSortField("price", search.SORT_FIELD_TYPE_LONG, true),
+		search.NewSortField("id", search.SORT_FIELD_TYPE_LONG, false))
+	var ids []int64
+	cursors := make([]*search.FieldDoc, len(searchers))
+	for n := 0; ; n++ {
+		It(t).Should("expect at most 9 pages").Assert(n < 10)
+		pages := make([]search.TopFieldDocs, len(searchers))
+		for i, searcher := range searchers {
+			var err error
+			pages[i], err = searcher.SearchAfterSorted(cursors[i], q, nil, 7, sort)
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+		}
+		page := search.MergeTopFieldDocs(sort, 7, pages)
+		It(t).Should("expect 60 hits, got %v", page.TotalHits).Verify(page.TotalHits == 60)
+		if len(page.FieldDocs) == 0 {
+			break
+		}
+		for _, hit := range page.FieldDocs {
+			id := hit.Fields[1].(int64)
+			It(t).Should("hit with id %v: expect shard %v, got %v", id, id/30, hit.ShardIndex).Verify(
+				int64(hit.ShardIndex) == id/30)
+			ids = append(ids, id)
+			cursors[hit.ShardIndex] = hit
+		}
+	}
+	It(t).Should("expect 60 ids, got %v", len(ids)).Assert(len(ids) == 60)
+	// prices 6 down to 0, by ascending id:
+	var expected []int64
+	for price := int64(6); price >= 0; price-- {
+		for id := price; id < 60; id += 7 {
+			expected = append(expected, id)
+		}
+	}
+	for i, id := range ids {
+		It(t).Should("hit %v: expect id %v, got %v", i, expected[i], id).Verify(id == expected[i])
+	}
+}
+
 func isSimilar(f1, f2, delta float32) bool {
 	diff := f1 - f2
 	return diff >= 0 && diff < delta || diff < 0 && -diff < delta
 }
 
+func TestCheckIndex(t *testing.T) {
+	path := t.TempDir()
+	directory, err := store.OpenFSDirectory(path)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	colors := []string{"red", "green", "blue"}
+	for i := 0; i < 10; i++ {
+		d := docu.NewDocument()
+		d.Add(docu.NewFieldFromString("id", fmt.Sprintf("doc%v", i), docu.STRING_FIELD_TYPE_STORED))
+		d.Add(docu.NewTextFieldFromString("body", fmt.Sprintf("the quick %v fox jumps over the lazy dog", colors[i%3]), docu.STORE_YES))
+		d.Add(docu.NewNumericDocValuesField("price", int64(i*10)))
+		d.Add(docu.NewBinaryDocValuesField("payload", []byte(fmt.Sprintf("p%v", i))))
+		d.Add(docu.NewSortedDocValuesField("color", []byte(colors[i%3])))
+		d.Add(docu.NewSortedSetDocValuesField("tags", []byte(colors[i%3])))
+		d.Add(docu.NewSortedSetDocValuesField("tags", []byte("all")))
+		err = writer.AddDocument(d.Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		if i == 4 {
+			err = writer.Commit()
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+		}
+	}
+	err = writer.DeleteDocuments(index.NewTerm("id", "doc2"))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	var buf bytes.Buffer
+	checker := index.NewCheckIndex(directory, true, &buf)
+	status, err := checker.CheckIndex(nil)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect a clean index:\n%v", buf.String()).Assert(status.Clean)
+	It(t).Should("expect 2 segments, got %v", len(status.SegmentInfos)).Assert(len(status.SegmentInfos) == 2)
+
+	first := status.SegmentInfos[0]
+	It(t).Should("expect 1 deleted doc, got %v", first.NumDeleted).Verify(first.HasDeletions && first.NumDeleted == 1)
+	It(t).Should("expect norms of 1 field, got %v", first.FieldNormStatus.TotFields).Verify(first.FieldNormStatus.TotFields == 1)
+	It(t).Should("expect terms to be checked").Verify(first.TermIndexStatus.TermCount > 0 && first.TermIndexStatus.TotPos > 0)
+	It(t).Should("expect 4 live docs with stored fields, got %v", first.StoredFieldStatus.DocCount).Verify(
+		first.StoredFieldStatus.DocCount == 4 && first.StoredFieldStatus.TotFields == 8)
+	dvStatus := first.DocValuesStatus
+	It(t).Should("expect 4 docvalues fields, got %v", dvStatus.TotalValueFields).Verify(
+		dvStatus.TotalValueFields == 4 && dvStatus.TotalNumericFields == 1 && dvStatus.TotalBinaryFields == 1 &&
+			dvStatus.TotalSortedFields == 1 && dvStatus.TotalSortedSetFields == 1)
+
+	// check a single segment
+	second := status.SegmentInfos[1].Name
+	status, err = checker.CheckIndex([]string{second})
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect only %v to be checked", second).Verify(
+		status.Clean && status.Partial && len(status.SegmentInfos) == 1 && status.SegmentInfos[0].Name == second)
+
+	// flip a byte of the largest file of the first segment
+	files, err := directory.ListAll()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	var corrupt string
+	var size int64
+	for _, file := range files {
+		if strings.HasPrefix(file, first.Name+".") {
+			n, err := directory.FileLength(file)
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+			if n > size {
+				corrupt, size = file, n
+			}
+		}
+	}
+	f, err := os.OpenFile(filepath.Join(path, corrupt), os.O_RDWR, 0)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	b := make([]byte, 1)
+	_, err = f.ReadAt(b, size/2)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	b[0] ^= 0xff
+	_, err = f.WriteAt(b, size/2)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = f.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	buf.Reset()
+	status, err = checker.CheckIndex(nil)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect %v to be detected as corrupt:\n%v", corrupt, buf.String()).Assert(!status.Clean)
+	It(t).Should("expect 1 bad segment with 4 docs, got %v with %v", status.NumBadSegments, status.TotLoseDocCount).Verify(
+		status.NumBadSegments == 1 && status.TotLoseDocCount == 4)
+
+	checker.SetFailFast(true)
+	_, err = checker.CheckIndex(nil)
+	It(t).Should("expect an error in fail fast mode").Verify(err != nil)
+	checker.SetFailFast(false)
+
+	// the index can't be fixed under a writer, nor from a partial check
+	writeLock := directory.MakeLock(index.WRITE_LOCK_NAME)
+	ok, err := writeLock.Obtain()
+	It(t).Should("expect to obtain the write lock: %v", err).Assert(ok && err == nil)
+	err = checker.FixIndex(status)
+	It(t).Should("expect the write lock to prevent the fix").Verify(err != nil)
+	err = writeLock.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	partial, err := checker.CheckIndex([]string{second})
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect a partial status not to be fixed").Verify(checker.FixIndex(partial) != nil)
+
+	// drop the corrupt segment
+	err = checker.FixIndex(status)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	status, err = checker.CheckIndex(nil)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect the fixed index to be clean").Verify(status.Clean && len(status.SegmentInfos) == 1)
+
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+	It(t).Should("expect the 5 docs of %v, got %v", second, reader.NumDocs()).Verify(reader.NumDocs() == 5)
+}
+
 func TestAfter(t *testing.T) {
 	// AfterSuite(t)
 }
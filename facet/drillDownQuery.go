@@ -0,0 +1,102 @@
+package facet
+
+import (
+	"github.com/gzg1984/golucene/core/index"
+	"github.com/gzg1984/golucene/core/search"
+)
+
+// facet/DrillDownQuery.java
+
+/*
+Builds a query for drilling down into a base query, i.e. for narrowing
+its hits to documents with specific facet labels. Drill-downs on the
+same dimension are OR'ed, while drill-downs on different dimensions
+are AND'ed:
+
+	q := NewDrillDownQuery(config, baseQuery)
+	q.Add("Author", "Lisa")
+	q.Add("Author", "Bob")
+	q.Add("Publish Date", "2010")
+	hits, err := searcher.Search(q.Query(), nil, 10)
+
+matches the hits of baseQuery written by Lisa or Bob, and published
+in 2010. The drill-down clauses do not change the scores of the hits.
+*/
+type DrillDownQuery struct {
+	config *FacetsConfig
+	base   search.Query
+	// the OR'ed terms of each dimension, in the order they were added
+	dims    []*search.BooleanQuery
+	dimOrds map[string]int
+}
+
+/*
+Creates a DrillDownQuery over the given base query. Can be nil, in
+which case the query matches all the documents with the added facet
+labels.
+*/
+func NewDrillDownQuery(config *FacetsConfig, baseQuery search.Query) *DrillDownQuery {
+	return &DrillDownQuery{
+		config:  config,
+		base:    baseQuery,
+		dimOrds: make(map[string]int),
+	}
+}
+
+/*
+Creates a drill-down term of the facet label of dimension dim, and
+path, indexed in indexFieldName.
+*/
+func DrillDownTerm(indexFieldName, dim string, path ...string) *index.Term {
+	return index.NewTerm(indexFieldName, PathToString(dim, path...))
+}
+
+/*
+Adds one dimension of drill downs; if you pass the same dimension
+more than once it is OR'd with the previous constraints on that
+dimension, and all dimensions are AND'd against each other and the
+base query. Without a path, all the documents with a label of the
+dimension match.
+*/
+func (q *DrillDownQuery) Add(dim string, path ...string) {
+	ord, ok := q.dimOrds[dim]
+	if !ok {
+		ord = len(q.dims)
+		q.dimOrds[dim] = ord
+		q.dims = append(q.dims, search.NewBooleanQueryDisableCoord(true))
+	}
+	indexFieldName := q.config.DimConfig(dim).IndexFieldName
+	q.dims[ord].Add(search.NewTermQuery(DrillDownTerm(indexFieldName, dim, path...)), search.SHOULD)
+}
+
+/* Returns the number of dimensions added. */
+func (q *DrillDownQuery) Dims() int {
+	return len(q.dims)
+}
+
+/*
+Returns the query to search: the base query, required along with the
+drill-down of each dimension. Returns nil if there is neither a base
+query nor any drill-down.
+*/
+func (q *DrillDownQuery) Query() search.Query {
+	if len(q.dims) == 0 {
+		return q.base
+	}
+	if q.base == nil && len(q.dims) == 1 {
+		return search.NewConstantScoreQuery(q.dims[0])
+	}
+	ans := search.NewBooleanQueryDisableCoord(true)
+	if q.base != nil {
+		ans.Add(q.base, search.MUST)
+	}
+	for _, dim := range q.dims {
+		drillDown := search.NewConstantScoreQuery(dim)
+		if q.base != nil {
+			// only the base query contributes to the scores
+			drillDown.SetBoost(0)
+		}
+		ans.Add(drillDown, search.MUST)
+	}
+	return ans
+}
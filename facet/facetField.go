@@ -0,0 +1,33 @@
+package facet
+
+import (
+	docu "github.com/gzg1984/golucene/core/document"
+)
+
+// facet/sortedset/SortedSetDocValuesFacetField.java
+
+// Field type of facet fields; they are replaced by FacetsConfig.Build().
+var facetFieldType = docu.NewFieldTypeFrom(docu.STRING_FIELD_TYPE_NOT_STORED)
+
+/*
+Add an instance of this to your Document for every facet label to be
+indexed via SortedSetDocValues, then call FacetsConfig.Build() on the
+document before adding it to the index.
+*/
+type SortedSetDocValuesFacetField struct {
+	*docu.Field
+	Dim   string // Dimension.
+	Label string // Label.
+}
+
+/* Sole constructor. */
+func NewSortedSetDocValuesFacetField(dim, label string) *SortedSetDocValuesFacetField {
+	return &SortedSetDocValuesFacetField{
+		docu.NewFieldFromString("dummy", PathToString(dim, label), facetFieldType),
+		dim, label,
+	}
+}
+
+func (f *SortedSetDocValuesFacetField) String() string {
+	return "SortedSetDocValuesFacetField(dim=" + f.Dim + " label=" + f.Label + ")"
+}
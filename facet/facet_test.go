@@ -0,0 +1,283 @@
+package facet
+
+import (
+	"fmt"
+	std "github.com/gzg1984/golucene/analysis/standard"
+	_ "github.com/gzg1984/golucene/core/codec/lucene71"
+	docu "github.com/gzg1984/golucene/core/document"
+	"github.com/gzg1984/golucene/core/index"
+	"github.com/gzg1984/golucene/core/search"
+	"github.com/gzg1984/golucene/core/store"
+	"github.com/gzg1984/golucene/core/util"
+	. "github.com/gzg1984/gounit"
+	"math"
+	"testing"
+)
+
+/*
+Indexes 10 documents in 3 segments, by 4 authors, with 3 tags, and
+prices from 0 to 80; the last document has no price.
+*/
+func newFacetsIndex(t *testing.T, config *FacetsConfig) store.Directory {
+	index.DefaultSimilarity = func() index.Similarity {
+		return search.NewDefaultSimilarity()
+	}
+
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, std.NewStandardAnalyzer())
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	for i := 0; i < 10; i++ {
+		d := docu.NewDocument()
+		body := "common"
+		if i < 6 {
+			body += " first"
+		}
+		d.Add(docu.NewTextFieldFromString("body", body, docu.STORE_NO))
+		d.Add(NewSortedSetDocValuesFacetField("Author", []string{"Bob", "Lisa", "Susan", "Lisa", "Frank"}[i%5]))
+		d.Add(NewSortedSetDocValuesFacetField("Tags", []string{"even", "odd"}[i%2]))
+		if i%3 == 0 {
+			d.Add(NewSortedSetDocValuesFacetField("Tags", "three"))
+		}
+		if i < 9 {
+			d.Add(docu.NewNumericDocValuesField("price", int64(i*10)))
+		}
+		d, err = config.Build(d)
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		err = writer.AddDocument(d.Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		if i == 3 || i == 6 {
+			err = writer.Commit()
+			It(t).Should("has no error: %v", err).Assert(err == nil)
+		}
+	}
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	return directory
+}
+
+func newFacetsConfig() *FacetsConfig {
+	config := NewFacetsConfig()
+	config.SetMultiValued("Tags", true)
+	return config
+}
+
+func sameResult(t *testing.T, expected string, result *FacetResult) {
+	It(t).Should("expect result, got nil").Assert(result != nil)
+	It(t).Should("expect:\n%vgot:\n%v", expected, result).Verify(expected == result.String())
+}
+
+func TestFacetsConfigBuild(t *testing.T) {
+	d := docu.NewDocument()
+	d.Add(NewSortedSetDocValuesFacetField("Author", "Bob"))
+	d.Add(NewSortedSetDocValuesFacetField("Author", "Lisa"))
+	_, err := NewFacetsConfig().Build(d)
+	It(t).Should("expect error for repeated single valued dim").Verify(err != nil)
+
+	for _, path := range [][]string{{"a", "b"}, {"a\u001Fb", "c\u001E"}, {"x"}} {
+		decoded := StringToPath(PathToString(path[0], path[1:]...))
+		It(t).Should("expect %q, got %q", path, decoded).Verify(fmt.Sprint(path) == fmt.Sprint(decoded))
+	}
+}
+
+func TestSortedSetDocValuesFacetCounts(t *testing.T) {
+	config := newFacetsConfig()
+	directory := newFacetsIndex(t, config)
+	defer directory.Close()
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+	It(t).Should("expect 3 segments, got %v", len(reader.Leaves())).Assert(len(reader.Leaves()) == 3)
+
+	state, err := NewSortedSetDocValuesReaderState(reader)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 7 labels, got %v", state.Size()).Verify(state.Size() == 7)
+
+	searcher := search.NewIndexSearcher(reader)
+	q := search.NewTermQuery(index.NewTerm("body", "common"))
+	hits, fc, err := Search(searcher, q, nil, 10, false)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 10 hits, got %v", hits.TotalHits).Verify(hits.TotalHits == 10)
+
+	facets, err := NewSortedSetDocValuesFacetCounts(state, fc)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	result, err := facets.TopChildren(10, "Author")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	sameResult(t, "dim=Author path=[] value=10 childCount=4\n"+
+		"  Lisa (4)\n  Bob (2)\n  Frank (2)\n  Susan (2)\n", result)
+	result, err = facets.TopChildren(2, "Author")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	sameResult(t, "dim=Author path=[] value=10 childCount=4\n  Lisa (4)\n  Bob (2)\n", result)
+	result, err = facets.TopChildren(10, "Tags")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	sameResult(t, "dim=Tags path=[] value=14 childCount=3\n"+
+		"  even (5)\n  odd (5)\n  three (4)\n", result)
+
+	results, err := facets.AllDims(1)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 2 dims, got %v", results).Assert(len(results) == 2)
+	sameResult(t, "dim=Tags path=[] value=14 childCount=3\n  even (5)\n", results[0])
+	sameResult(t, "dim=Author path=[] value=10 childCount=4\n  Lisa (4)\n", results[1])
+
+	for _, c := range []struct {
+		dim, label string
+		count      int
+	}{{"Author", "Lisa", 4}, {"Author", "Frank", 2}, {"Author", "Nobody", -1}, {"Nope", "x", -1}, {"Tags", "three", 4}} {
+		count, err := facets.SpecificValue(c.dim, c.label)
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		It(t).Should("%v/%v: expect %v, got %v", c.dim, c.label, c.count, count).Verify(count == c.count)
+	}
+	_, err = facets.TopChildren(10, "Nope")
+	It(t).Should("expect error for unknown dim").Verify(err != nil)
+
+	// only the hits are counted
+	_, fc, err = Search(searcher, search.NewTermQuery(index.NewTerm("body", "first")), nil, 10, true)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	for _, hits := range fc.MatchingDocs() {
+		It(t).Should("expect a score per hit").Verify(len(hits.Scores) == hits.TotalHits)
+	}
+	facets, err = NewSortedSetDocValuesFacetCounts(state, fc)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	result, err = facets.TopChildren(10, "Author")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	sameResult(t, "dim=Author path=[] value=6 childCount=4\n"+
+		"  Bob (2)\n  Lisa (2)\n  Frank (1)\n  Susan (1)\n", result)
+
+	// concurrent collection gives the same counts
+	searcher.SetConcurrency(4, 1)
+	res, err := searcher.SearchWithCollectorManager(q, nil, NewFacetsCollectorManager(false))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	fc = res.(*FacetsCollector)
+	It(t).Should("expect 3 segments, got %v", len(fc.MatchingDocs())).Verify(len(fc.MatchingDocs()) == 3)
+	facets, err = NewSortedSetDocValuesFacetCounts(state, fc)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	result, err = facets.TopChildren(10, "Author")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	sameResult(t, "dim=Author path=[] value=10 childCount=4\n"+
+		"  Lisa (4)\n  Bob (2)\n  Frank (2)\n  Susan (2)\n", result)
+	hits, fc, err = Search(searcher, q, nil, 3, false)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 10 hits, got %v", hits.TotalHits).Verify(hits.TotalHits == 10 && len(hits.ScoreDocs) == 3)
+	It(t).Should("expect 3 segments, got %v", len(fc.MatchingDocs())).Verify(len(fc.MatchingDocs()) == 3)
+
+	// the state must match the searched reader
+	other, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer other.Close()
+	_, fc, err = Search(search.NewIndexSearcher(other), q, nil, 10, false)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	_, err = NewSortedSetDocValuesFacetCounts(state, fc)
+	It(t).Should("expect error for another reader").Verify(err != nil)
+}
+
+func TestLongRangeFacetCounts(t *testing.T) {
+	directory := newFacetsIndex(t, newFacetsConfig())
+	defer directory.Close()
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+
+	q := search.NewTermQuery(index.NewTerm("body", "common"))
+	_, fc, err := Search(search.NewIndexSearcher(reader), q, nil, 10, false)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	newRange := func(label string, min int64, minInclusive bool, max int64, maxInclusive bool) *LongRange {
+		r, err := NewLongRange(label, min, minInclusive, max, maxInclusive)
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		return r
+	}
+	facets, err := NewLongRangeFacetCounts("price", fc,
+		newRange("less than 30", 0, true, 30, false),
+		newRange("30 to 60", 30, true, 60, true),
+		newRange("over 50", 50, false, math.MaxInt64, true),
+		newRange("negative", math.MinInt64, true, 0, false))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	result, err := facets.TopChildren(10, "price")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	sameResult(t, "dim=price path=[] value=9 childCount=4\n"+
+		"  less than 30 (3)\n  30 to 60 (4)\n  over 50 (3)\n  negative (0)\n", result)
+
+	_, err = facets.TopChildren(10, "cost")
+	It(t).Should("expect error for another dim").Verify(err != nil)
+}
+
+func TestLongRangeMatchingNothing(t *testing.T) {
+	_, err := NewLongRange("empty", 10, true, 5, true)
+	It(t).Should("expect error for min above max").Verify(err != nil)
+	_, err = NewLongRange("empty", 5, false, 6, false)
+	It(t).Should("expect error for an empty exclusive range").Verify(err != nil)
+	_, err = NewLongRange("empty", math.MaxInt64, false, math.MaxInt64, true)
+	It(t).Should("expect error for exclusive min at MaxInt64").Verify(err != nil)
+	_, err = NewLongRange("empty", math.MinInt64, true, math.MinInt64, false)
+	It(t).Should("expect error for exclusive max at MinInt64").Verify(err != nil)
+	r, err := NewLongRange("single", 5, true, 5, true)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 5 to be accepted").Verify(r.Accept(5) && !r.Accept(4) && !r.Accept(6))
+}
+
+func TestDrillDownQuery(t *testing.T) {
+	config := newFacetsConfig()
+	directory := newFacetsIndex(t, config)
+	defer directory.Close()
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+	searcher := search.NewIndexSearcher(reader)
+
+	base := search.NewTermQuery(index.NewTerm("body", "first"))
+	baseHits, err := searcher.Search(base, nil, 10)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	baseScores := make(map[int]float32)
+	for _, hit := range baseHits.ScoreDocs {
+		baseScores[hit.Doc] = hit.Score
+	}
+
+	check := func(q *DrillDownQuery, expected int) {
+		hits, err := searcher.Search(q.Query(), nil, 10)
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		It(t).Should("expect %v hits, got %v", expected, hits.TotalHits).Verify(hits.TotalHits == expected)
+		for _, hit := range hits.ScoreDocs {
+			if q.base != nil {
+				It(t).Should("expect score of base query %v, got %v", baseScores[hit.Doc], hit.Score).Verify(
+					baseScores[hit.Doc] == hit.Score)
+			} else {
+				It(t).Should("expect a score, got %v", hit.Score).Verify(hit.Score > 0)
+			}
+		}
+	}
+
+	q := NewDrillDownQuery(config, base)
+	q.Add("Author", "Lisa")
+	check(q, 2)
+	// OR'ed in the same dimension
+	q.Add("Author", "Bob")
+	check(q, 4)
+	// AND'ed across dimensions
+	q.Add("Tags", "three")
+	check(q, 2)
+
+	q = NewDrillDownQuery(config, nil)
+	q.Add("Author", "Lisa")
+	check(q, 4)
+	q = NewDrillDownQuery(config, nil)
+	q.Add("Tags")
+	check(q, 10)
+	q.Add("Author", "Frank")
+	check(q, 2)
+
+	// drill-down and facet counts together
+	q = NewDrillDownQuery(config, base)
+	q.Add("Tags", "even")
+	_, fc, err := Search(searcher, q.Query(), nil, 10, false)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	state, err := NewSortedSetDocValuesReaderState(reader)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	facets, err := NewSortedSetDocValuesFacetCounts(state, fc)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	result, err := facets.TopChildren(10, "Author")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	sameResult(t, "dim=Author path=[] value=3 childCount=3\n"+
+		"  Bob (1)\n  Frank (1)\n  Susan (1)\n", result)
+}
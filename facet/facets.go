@@ -0,0 +1,75 @@
+package facet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// facet/Facets.java
+
+/* Common interface of all facet implementations, which count the facet labels of the hits of a search. */
+type Facets interface {
+	// Returns the topN child labels under the specified path. Returns
+	// nil if the specified path doesn't exist or if this dimension was
+	// never seen.
+	TopChildren(topN int, dim string, path ...string) (*FacetResult, error)
+	// Return the count or value for a specific path. Returns -1 if
+	// this path doesn't exist, else the count.
+	SpecificValue(dim string, path ...string) (int, error)
+	// Returns topN labels for any dimension that had hits, sorted by
+	// the number of hits that dimension matched; this is used for
+	// "sparse" faceting, where many different dimensions were
+	// indexed, for example depending on the type of document.
+	AllDims(topN int) ([]*FacetResult, error)
+}
+
+// facet/LabelAndValue.java
+
+/* Single label and its value, usually contained in a FacetResult. */
+type LabelAndValue struct {
+	// Facet's label.
+	Label string
+	// Value associated with this label.
+	Value int
+}
+
+func (lv *LabelAndValue) String() string {
+	return fmt.Sprintf("%v (%v)", lv.Label, lv.Value)
+}
+
+// facet/FacetResult.java
+
+/* Counts or aggregates for a single dimension. */
+type FacetResult struct {
+	// Dimension that was requested.
+	Dim string
+	// Path whose children were requested.
+	Path []string
+	// Total value for this path (sum of all child counts, or -1 if
+	// unknown).
+	Value int
+	// How many child labels were encountered.
+	ChildCount int
+	// Child counts.
+	LabelValues []*LabelAndValue
+}
+
+func (r *FacetResult) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "dim=%v path=%v value=%v childCount=%v\n", r.Dim, r.Path, r.Value, r.ChildCount)
+	for _, labelValue := range r.LabelValues {
+		fmt.Fprintf(&sb, "  %v\n", labelValue)
+	}
+	return sb.String()
+}
+
+/* Sorts results by value, descending, then by dimension. */
+func sortFacetResults(results []*FacetResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Value != results[j].Value {
+			return results[i].Value > results[j].Value
+		}
+		return results[i].Dim < results[j].Dim
+	})
+}
@@ -0,0 +1,213 @@
+package facet
+
+import (
+	"github.com/gzg1984/golucene/core/index"
+	"github.com/gzg1984/golucene/core/search"
+	"github.com/gzg1984/golucene/core/util"
+	"sort"
+)
+
+// facet/FacetsCollector.java
+
+/*
+Holds the documents that were matched in the AtomicReaderContext. If
+scores were required, then Scores is not nil.
+*/
+type MatchingDocs struct {
+	// Context for this segment.
+	Context *index.AtomicReaderContext
+	// Which documents were seen, by their docID within the segment.
+	Bits *util.FixedBitSet
+	// Non-sparse scores array, in the order of the documents of Bits.
+	Scores []float32
+	// Total number of hits
+	TotalHits int
+}
+
+/*
+Collects hits for subsequent faceting. Once you've run a search and
+collect hits into this, instantiate one of the Facets types, like
+SortedSetDocValuesFacetCounts, to do the facet counting. Use
+NewFacetsCollectorManager() to collect hits with a concurrent
+IndexSearcher, or Search() to get the top hits along with the
+FacetsCollector.
+*/
+type FacetsCollector struct {
+	context    *index.AtomicReaderContext
+	scorer     search.Scorer
+	totalHits  int
+	scores     []float32
+	bits       *util.FixedBitSet
+	keepScores bool
+	// one per segment, once the segment is done
+	matchingDocs []*MatchingDocs
+}
+
+/* Create this; if keepScores is true then a []float32 is allocated to hold score of all hits. */
+func NewFacetsCollector(keepScores bool) *FacetsCollector {
+	return &FacetsCollector{keepScores: keepScores}
+}
+
+/* True if scores were saved. */
+func (fc *FacetsCollector) KeepScores() bool {
+	return fc.keepScores
+}
+
+/* Returns the documents matched by the query, one MatchingDocs per visited segment. */
+func (fc *FacetsCollector) MatchingDocs() []*MatchingDocs {
+	fc.finishSegment()
+	return fc.matchingDocs
+}
+
+func (fc *FacetsCollector) finishSegment() {
+	if fc.bits != nil {
+		fc.matchingDocs = append(fc.matchingDocs, &MatchingDocs{
+			fc.context, fc.bits, fc.scores, fc.totalHits,
+		})
+		fc.bits, fc.scores, fc.totalHits = nil, nil, 0
+	}
+}
+
+func (fc *FacetsCollector) SetScorer(scorer search.Scorer) {
+	fc.scorer = scorer
+}
+
+func (fc *FacetsCollector) Collect(doc int) error {
+	fc.bits.Set(doc)
+	if fc.keepScores {
+		score, err := fc.scorer.Score()
+		if err != nil {
+			return err
+		}
+		fc.scores = append(fc.scores, score)
+	}
+	fc.totalHits++
+	return nil
+}
+
+func (fc *FacetsCollector) SetNextReader(context *index.AtomicReaderContext) error {
+	fc.finishSegment()
+	fc.context = context
+	fc.bits = util.NewFixedBitSetOf(context.Reader().MaxDoc())
+	return nil
+}
+
+func (fc *FacetsCollector) AcceptsDocsOutOfOrder() bool {
+	// If we are keeping scores then we require in-order because we
+	// append each score to the []float32:
+	return !fc.keepScores
+}
+
+/*
+Returns a CollectorManager of FacetsCollectors, for
+IndexSearcher.SearchWithCollectorManager(). Its Reduce() returns a
+*FacetsCollector with the MatchingDocs of all the collectors, in the
+order of the segments.
+*/
+func NewFacetsCollectorManager(keepScores bool) search.CollectorManager {
+	return facetsCollectorManager(keepScores)
+}
+
+type facetsCollectorManager bool
+
+func (keepScores facetsCollectorManager) NewCollector() (search.Collector, error) {
+	return NewFacetsCollector(bool(keepScores)), nil
+}
+
+func (keepScores facetsCollectorManager) Reduce(collectors []search.Collector) (interface{}, error) {
+	return reduceFacetsCollectors(bool(keepScores), collectors), nil
+}
+
+func reduceFacetsCollectors(keepScores bool, collectors []search.Collector) *FacetsCollector {
+	if len(collectors) == 1 {
+		return collectors[0].(*FacetsCollector)
+	}
+	merged := NewFacetsCollector(keepScores)
+	for _, c := range collectors {
+		merged.matchingDocs = append(merged.matchingDocs, c.(*FacetsCollector).MatchingDocs()...)
+	}
+	sort.SliceStable(merged.matchingDocs, func(i, j int) bool {
+		return merged.matchingDocs[i].Context.Ord < merged.matchingDocs[j].Context.Ord
+	})
+	return merged
+}
+
+/*
+Utility method, to search and also collect all hits into the
+returned FacetsCollector. Returns the top n hits of query, applying
+filter if non-nil, like IndexSearcher.Search().
+*/
+func Search(searcher *search.IndexSearcher, q search.Query, f search.Filter, n int,
+	keepScores bool) (search.TopDocs, *FacetsCollector, error) {
+
+	res, err := searcher.SearchWithCollectorManager(q, f, &hitsAndFacetsCollectorManager{n, keepScores})
+	if err != nil {
+		return search.TopDocs{}, nil, err
+	}
+	ans := res.(*hitsAndFacets)
+	return ans.topDocs, ans.facets, nil
+}
+
+type hitsAndFacets struct {
+	topDocs search.TopDocs
+	facets  *FacetsCollector
+}
+
+/* Collects the top hits and the hits for faceting at the same time. */
+type hitsAndFacetsCollector struct {
+	hits   search.TopDocsCollector
+	facets *FacetsCollector
+}
+
+func (c *hitsAndFacetsCollector) SetScorer(scorer search.Scorer) {
+	c.hits.SetScorer(scorer)
+	c.facets.SetScorer(scorer)
+}
+
+func (c *hitsAndFacetsCollector) Collect(doc int) error {
+	if err := c.hits.Collect(doc); err != nil {
+		return err
+	}
+	return c.facets.Collect(doc)
+}
+
+func (c *hitsAndFacetsCollector) SetNextReader(context *index.AtomicReaderContext) error {
+	if err := c.hits.SetNextReader(context); err != nil {
+		return err
+	}
+	return c.facets.SetNextReader(context)
+}
+
+func (c *hitsAndFacetsCollector) AcceptsDocsOutOfOrder() bool {
+	// the top hits are collected in order
+	return false
+}
+
+type hitsAndFacetsCollectorManager struct {
+	numHits    int
+	keepScores bool
+}
+
+func (m *hitsAndFacetsCollectorManager) NewCollector() (search.Collector, error) {
+	return &hitsAndFacetsCollector{
+		search.NewTopScoreDocCollector(m.numHits, nil, true),
+		NewFacetsCollector(m.keepScores),
+	}, nil
+}
+
+func (m *hitsAndFacetsCollectorManager) Reduce(collectors []search.Collector) (interface{}, error) {
+	facets := make([]search.Collector, len(collectors))
+	shardHits := make([]search.TopDocs, len(collectors))
+	for i, c := range collectors {
+		facets[i] = c.(*hitsAndFacetsCollector).facets
+		shardHits[i] = c.(*hitsAndFacetsCollector).hits.TopDocs()
+	}
+	ans := &hitsAndFacets{shardHits[0], reduceFacetsCollectors(m.keepScores, facets)}
+	if len(collectors) > 1 {
+		ans.topDocs = search.MergeTopDocs(m.numHits, shardHits)
+		for _, hit := range ans.topDocs.ScoreDocs {
+			hit.ShardIndex = -1
+		}
+	}
+	return ans, nil
+}
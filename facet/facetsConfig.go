@@ -0,0 +1,175 @@
+package facet
+
+import (
+	"fmt"
+	docu "github.com/gzg1984/golucene/core/document"
+	"strings"
+)
+
+// facet/FacetsConfig.java
+
+/* Default index field name, used when the dimension has no specific field. */
+const DEFAULT_INDEX_FIELD_NAME = "$facets"
+
+const (
+	// Delimiter of the components of a path, in the index
+	DELIM_CHAR = '\u001F'
+	// Escapes DELIM_CHAR found in a component of a path
+	ESCAPE_CHAR = '\u001E'
+)
+
+/* Holds the configuration for one dimension */
+type DimConfig struct {
+	// True if the same document can have more than one value for this
+	// dimension.
+	MultiValued bool
+	// Actual field where this dimension's facet labels should be
+	// indexed
+	IndexFieldName string
+}
+
+/*
+Records per-dimension configuration. By default a dimension is flat
+and single valued, and is indexed in DEFAULT_INDEX_FIELD_NAME; use
+the setters to change these settings for each dimension.
+
+NOTE: this configuration is not saved into the index, but it's vital,
+and up to the application to ensure, that at search time the provided
+FacetsConfig matches what was used during indexing.
+*/
+type FacetsConfig struct {
+	fieldTypes       map[string]*DimConfig
+	defaultDimConfig *DimConfig
+}
+
+func NewFacetsConfig() *FacetsConfig {
+	return &FacetsConfig{
+		fieldTypes:       make(map[string]*DimConfig),
+		defaultDimConfig: &DimConfig{IndexFieldName: DEFAULT_INDEX_FIELD_NAME},
+	}
+}
+
+/* Get the current configuration for a dimension. */
+func (c *FacetsConfig) DimConfig(dimName string) *DimConfig {
+	if ft, ok := c.fieldTypes[dimName]; ok {
+		return ft
+	}
+	return c.defaultDimConfig
+}
+
+func (c *FacetsConfig) dimConfigForUpdate(dimName string) *DimConfig {
+	ft, ok := c.fieldTypes[dimName]
+	if !ok {
+		ft = &DimConfig{IndexFieldName: DEFAULT_INDEX_FIELD_NAME}
+		c.fieldTypes[dimName] = ft
+	}
+	return ft
+}
+
+/* Pass true if this dimension may have more than one value per document. */
+func (c *FacetsConfig) SetMultiValued(dimName string, v bool) {
+	c.dimConfigForUpdate(dimName).MultiValued = v
+}
+
+/* Specify which index field name should hold the ordinals for this dimension. */
+func (c *FacetsConfig) SetIndexFieldName(dimName, indexFieldName string) {
+	c.dimConfigForUpdate(dimName).IndexFieldName = indexFieldName
+}
+
+/*
+Translates any added facet fields into normal fields for indexing.
+The returned document, and not doc, must be added to the index.
+
+For each SortedSetDocValuesFacetField, a SortedSetDocValuesField
+holding the path is added for counting, and indexed terms of the
+dimension and of the path are added for drill-down.
+*/
+func (c *FacetsConfig) Build(doc *docu.Document) (*docu.Document, error) {
+	result := docu.NewDocument()
+
+	// index field name -> facet fields, in the order they were added
+	byField := make(map[string][]*SortedSetDocValuesFacetField)
+	var indexFieldNames []string
+	seenDims := make(map[string]bool)
+	for _, field := range doc.Fields() {
+		facetField, ok := field.(*SortedSetDocValuesFacetField)
+		if !ok {
+			result.Add(field)
+			continue
+		}
+		dimConfig := c.DimConfig(facetField.Dim)
+		if !dimConfig.MultiValued && seenDims[facetField.Dim] {
+			return nil, fmt.Errorf(
+				"dimension \"%v\" is not multiValued, but it appears more than once in this document",
+				facetField.Dim)
+		}
+		seenDims[facetField.Dim] = true
+		if _, ok := byField[dimConfig.IndexFieldName]; !ok {
+			indexFieldNames = append(indexFieldNames, dimConfig.IndexFieldName)
+		}
+		byField[dimConfig.IndexFieldName] = append(byField[dimConfig.IndexFieldName], facetField)
+	}
+
+	for _, indexFieldName := range indexFieldNames {
+		for _, facetField := range byField[indexFieldName] {
+			fullPath := PathToString(facetField.Dim, facetField.Label)
+			result.Add(docu.NewSortedSetDocValuesField(indexFieldName, []byte(fullPath)))
+
+			// Drill down:
+			result.Add(docu.NewFieldFromString(indexFieldName,
+				PathToString(facetField.Dim), docu.STRING_FIELD_TYPE_NOT_STORED))
+			result.Add(docu.NewFieldFromString(indexFieldName,
+				fullPath, docu.STRING_FIELD_TYPE_NOT_STORED))
+		}
+	}
+	return result, nil
+}
+
+/*
+Turns a dimension plus path into an encoded string, where the
+components are separated by DELIM_CHAR.
+*/
+func PathToString(dim string, path ...string) string {
+	components := append([]string{dim}, path...)
+	var sb strings.Builder
+	for i, s := range components {
+		if s == "" {
+			panic("each path component must have length > 0")
+		}
+		for _, ch := range s {
+			if ch == DELIM_CHAR || ch == ESCAPE_CHAR {
+				sb.WriteRune(ESCAPE_CHAR)
+			}
+			sb.WriteRune(ch)
+		}
+		if i < len(components)-1 {
+			sb.WriteRune(DELIM_CHAR)
+		}
+	}
+	return sb.String()
+}
+
+/* Turns an encoded string (from a previous call to PathToString()) back into the original []string. */
+func StringToPath(s string) []string {
+	var parts []string
+	if s == "" {
+		return parts
+	}
+	var sb strings.Builder
+	escaped := false
+	for _, ch := range s {
+		switch {
+		case escaped:
+			sb.WriteRune(ch)
+			escaped = false
+		case ch == ESCAPE_CHAR:
+			escaped = true
+		case ch == DELIM_CHAR:
+			parts = append(parts, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+	return append(parts, sb.String())
+}
@@ -0,0 +1,70 @@
+package facet
+
+import (
+	"fmt"
+	"math"
+)
+
+// facet/range/LongRange.java
+
+/* Represents a range over int64 values. */
+type LongRange struct {
+	// Label that identifies this range.
+	Label string
+	// Minimum, inclusive.
+	minIncl int64
+	// Maximum, inclusive.
+	maxIncl int64
+	// Minimum and maximum as given, and whether they are inclusive.
+	Min, Max                   int64
+	MinInclusive, MaxInclusive bool
+}
+
+/*
+Create a LongRange. Returns an error if the range can match no value,
+e.g. min is above max, or an exclusive bound sits at the int64 limit.
+*/
+func NewLongRange(label string, minIn int64, minInclusive bool, maxIn int64, maxInclusive bool) (*LongRange, error) {
+	r := &LongRange{
+		Label: label,
+		Min:   minIn, MinInclusive: minInclusive,
+		Max: maxIn, MaxInclusive: maxInclusive,
+	}
+	if !minInclusive {
+		if minIn == math.MaxInt64 {
+			return nil, r.failNoMatch()
+		}
+		minIn++
+	}
+	if !maxInclusive {
+		if maxIn == math.MinInt64 {
+			return nil, r.failNoMatch()
+		}
+		maxIn--
+	}
+	if minIn > maxIn {
+		return nil, r.failNoMatch()
+	}
+	r.minIncl, r.maxIncl = minIn, maxIn
+	return r, nil
+}
+
+func (r *LongRange) failNoMatch() error {
+	return fmt.Errorf("range \"%v\" matches nothing", r.Label)
+}
+
+/* True if this range accepts the provided value. */
+func (r *LongRange) Accept(value int64) bool {
+	return value >= r.minIncl && value <= r.maxIncl
+}
+
+func (r *LongRange) String() string {
+	open, close := "(", ")"
+	if r.MinInclusive {
+		open = "["
+	}
+	if r.MaxInclusive {
+		close = "]"
+	}
+	return fmt.Sprintf("LongRange(%v: %v%v to %v%v)", r.Label, open, r.Min, r.Max, close)
+}
@@ -0,0 +1,117 @@
+package facet
+
+import (
+	"fmt"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/search/model"
+)
+
+// facet/range/LongRangeFacetCounts.java
+
+/*
+Facets implementation that computes counts for dynamic long ranges
+from a provided NumericDocValues field. Use this for dimensions that
+change in real-time (e.g. a relative time based dimension like "Past
+day", "Past 2 days", etc.) or that change for each request (e.g.
+distance from the user's location, "< 1 km", "< 2 km", etc.).
+
+Documents without a value for the field are not counted.
+*/
+type LongRangeFacetCounts struct {
+	// Ranges passed to constructor.
+	ranges []*LongRange
+	// Counts of each range.
+	counts []int
+	// Our field name.
+	field string
+	// Total number of hits.
+	totCount int
+}
+
+/* Create LongRangeFacetCounts, using the NumericDocValues of field. */
+func NewLongRangeFacetCounts(field string, hits *FacetsCollector,
+	ranges ...*LongRange) (*LongRangeFacetCounts, error) {
+
+	f := &LongRangeFacetCounts{
+		ranges: ranges,
+		counts: make([]int, len(ranges)),
+		field:  field,
+	}
+	if err := f.count(hits.MatchingDocs()); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *LongRangeFacetCounts) count(matchingDocs []*MatchingDocs) error {
+	for _, hits := range matchingDocs {
+		reader := hits.Context.Reader().(index.AtomicReader)
+		fv, err := reader.NumericDocValues(f.field)
+		if err != nil {
+			return err
+		} else if fv == nil {
+			continue
+		}
+		docsWithField, err := reader.DocsWithField(f.field)
+		if err != nil {
+			return err
+		}
+
+		docs, err := hits.Bits.Iterator()
+		if err != nil {
+			return err
+		}
+		doc, err := docs.NextDoc()
+		for ; err == nil && doc != NO_MORE_DOCS; doc, err = docs.NextDoc() {
+			// Skip missing docs:
+			if docsWithField != nil && !docsWithField.At(doc) {
+				continue
+			}
+			v, matched := fv(doc), false
+			for i, r := range f.ranges {
+				if r.Accept(v) {
+					f.counts[i]++
+					matched = true
+				}
+			}
+			if matched {
+				f.totCount++
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+Returns the counts of all the ranges, in the order they were given;
+topN is ignored. dim must be the field of the ranges.
+*/
+func (f *LongRangeFacetCounts) TopChildren(topN int, dim string, path ...string) (*FacetResult, error) {
+	if dim != f.field {
+		return nil, fmt.Errorf("invalid dim \"%v\"; should be \"%v\"", dim, f.field)
+	}
+	if len(path) != 0 {
+		return nil, fmt.Errorf("path.length should be 0")
+	}
+	labelValues := make([]*LabelAndValue, len(f.counts))
+	for i, count := range f.counts {
+		labelValues[i] = &LabelAndValue{f.ranges[i].Label, count}
+	}
+	return &FacetResult{dim, []string{}, f.totCount, len(labelValues), labelValues}, nil
+}
+
+func (f *LongRangeFacetCounts) SpecificValue(dim string, path ...string) (int, error) {
+	// TODO: should we impl this?
+	return 0, fmt.Errorf("SpecificValue() is not supported by range facets")
+}
+
+func (f *LongRangeFacetCounts) AllDims(topN int) ([]*FacetResult, error) {
+	result, err := f.TopChildren(topN, f.field)
+	if err != nil {
+		return nil, err
+	}
+	return []*FacetResult{result}, nil
+}
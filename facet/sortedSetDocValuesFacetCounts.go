@@ -0,0 +1,148 @@
+package facet
+
+import (
+	"fmt"
+	. "github.com/gzg1984/golucene/core/codec/spi"
+	. "github.com/gzg1984/golucene/core/search/model"
+	"sort"
+)
+
+// facet/sortedset/SortedSetDocValuesFacetCounts.java
+
+/*
+Compute facets counts from previously indexed
+SortedSetDocValuesFacetField, without requiring a separate taxonomy
+index. There is added cost on every IndexReader open to create a new
+SortedSetDocValuesReaderState. Furthermore, this does not support
+hierarchical facets; only flat (dimension + label) facets.
+
+The counts of each segment are mapped to global ordinals, so the
+labels of all segments are counted together.
+
+NOTE: tie-break is by unicode sort order.
+*/
+type SortedSetDocValuesFacetCounts struct {
+	state  *SortedSetDocValuesReaderState
+	counts []int
+}
+
+/* Sparse facet counting: counts all facets of the hits collected by hits. */
+func NewSortedSetDocValuesFacetCounts(state *SortedSetDocValuesReaderState,
+	hits *FacetsCollector) (*SortedSetDocValuesFacetCounts, error) {
+
+	f := &SortedSetDocValuesFacetCounts{
+		state:  state,
+		counts: make([]int, state.Size()),
+	}
+	if err := f.count(hits.MatchingDocs()); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+/* Does all the "real work" of tallying up the counts. */
+func (f *SortedSetDocValuesFacetCounts) count(matchingDocs []*MatchingDocs) error {
+	for _, hits := range matchingDocs {
+		segValues, globalOrds, err := f.state.leafDocValues(hits.Context)
+		if err != nil {
+			return err
+		}
+		if segValues == nil {
+			continue
+		}
+		docs, err := hits.Bits.Iterator()
+		if err != nil {
+			return err
+		}
+		doc, err := docs.NextDoc()
+		for ; err == nil && doc != NO_MORE_DOCS; doc, err = docs.NextDoc() {
+			segValues.SetDocument(doc)
+			for term := segValues.NextOrd(); term != NO_MORE_ORDS; term = segValues.NextOrd() {
+				f.counts[globalOrds[term]]++
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *SortedSetDocValuesFacetCounts) TopChildren(topN int, dim string, path ...string) (*FacetResult, error) {
+	if topN <= 0 {
+		return nil, fmt.Errorf("topN must be > 0 (got: %v)", topN)
+	}
+	if len(path) > 0 {
+		return nil, fmt.Errorf("path should be 0 length")
+	}
+	ordRange := f.state.OrdRange(dim)
+	if ordRange == nil {
+		return nil, fmt.Errorf("dimension \"%v\" was not indexed", dim)
+	}
+	return f.topChildrenSortByCount(dim, topN, ordRange), nil
+}
+
+func (f *SortedSetDocValuesFacetCounts) topChildrenSortByCount(dim string, topN int,
+	ordRange *OrdRange) *FacetResult {
+
+	var ords []int64
+	dimCount, childCount := 0, 0
+	for ord := ordRange.Start; ord <= ordRange.End; ord++ {
+		if f.counts[ord] > 0 {
+			dimCount += f.counts[ord]
+			childCount++
+			ords = append(ords, ord)
+		}
+	}
+	if dimCount == 0 {
+		return nil
+	}
+
+	// by count, descending; ties are broken by ordinal, which is the
+	// unicode order of the labels
+	sort.SliceStable(ords, func(i, j int) bool {
+		return f.counts[ords[i]] > f.counts[ords[j]]
+	})
+	if len(ords) > topN {
+		ords = ords[:topN]
+	}
+
+	labelValues := make([]*LabelAndValue, len(ords))
+	for i, ord := range ords {
+		parts := StringToPath(string(f.state.LookupOrd(ord)))
+		labelValues[i] = &LabelAndValue{parts[1], f.counts[ord]}
+	}
+	return &FacetResult{dim, []string{}, dimCount, childCount, labelValues}
+}
+
+func (f *SortedSetDocValuesFacetCounts) SpecificValue(dim string, path ...string) (int, error) {
+	if len(path) != 1 {
+		return 0, fmt.Errorf("path must be length=1")
+	}
+	ordRange := f.state.OrdRange(dim)
+	if ordRange == nil {
+		return -1, nil
+	}
+	label := PathToString(dim, path...)
+	// the labels of a dimension are sorted
+	n := int(ordRange.End - ordRange.Start + 1)
+	i := sort.Search(n, func(i int) bool {
+		return string(f.state.LookupOrd(ordRange.Start+int64(i))) >= label
+	})
+	if i == n || string(f.state.LookupOrd(ordRange.Start+int64(i))) != label {
+		return -1, nil
+	}
+	return f.counts[ordRange.Start+int64(i)], nil
+}
+
+func (f *SortedSetDocValuesFacetCounts) AllDims(topN int) ([]*FacetResult, error) {
+	var results []*FacetResult
+	for _, dim := range f.state.Dims() {
+		if fr := f.topChildrenSortByCount(dim, topN, f.state.OrdRange(dim)); fr != nil {
+			results = append(results, fr)
+		}
+	}
+	// Sort by highest count:
+	sortFacetResults(results)
+	return results, nil
+}
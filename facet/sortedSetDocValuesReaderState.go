@@ -0,0 +1,152 @@
+package facet
+
+import (
+	"fmt"
+	. "github.com/gzg1984/golucene/core/codec/spi"
+	"github.com/gzg1984/golucene/core/index"
+	"sort"
+)
+
+// facet/sortedset/SortedSetDocValuesReaderState.java
+
+/*
+Holds start/end range of ords, which maps to one dimension (someday
+we may generalize it to map to hierarchies within one dimension).
+*/
+type OrdRange struct {
+	// Start of range, inclusive:
+	Start int64
+	// End of range, inclusive:
+	End int64
+}
+
+/*
+Wraps a top-level IndexReader and resolves the ordinals of the facet
+labels of one index field: the ordinals of each segment are mapped to
+global ordinals, shared by all segments, with an OrdinalMap.
+
+This is costly to create, so create it once and share it across
+searches, including concurrent ones, until you reopen the IndexReader;
+then create a new one for the new reader.
+*/
+type SortedSetDocValuesReaderState struct {
+	field      string
+	reader     index.IndexReader
+	leaves     []*index.AtomicReaderContext
+	subs       []SortedSetDocValues
+	ordinals   *index.OrdinalMap
+	valueCount int64
+	// dimension -> range of its global ordinals
+	prefixToOrdRange map[string]*OrdRange
+}
+
+/* Creates this, pulling doc values from the default DEFAULT_INDEX_FIELD_NAME. */
+func NewSortedSetDocValuesReaderState(reader index.IndexReader) (*SortedSetDocValuesReaderState, error) {
+	return NewSortedSetDocValuesReaderStateForField(reader, DEFAULT_INDEX_FIELD_NAME)
+}
+
+/* Creates this, pulling doc values from the specified field. */
+func NewSortedSetDocValuesReaderStateForField(reader index.IndexReader,
+	field string) (*SortedSetDocValuesReaderState, error) {
+
+	state := &SortedSetDocValuesReaderState{
+		field:            field,
+		reader:           reader,
+		leaves:           reader.Leaves(),
+		prefixToOrdRange: make(map[string]*OrdRange),
+	}
+	state.subs = make([]SortedSetDocValues, len(state.leaves))
+	found := false
+	for i, ctx := range state.leaves {
+		dv, err := ctx.Reader().(index.AtomicReader).SortedSetDocValues(field)
+		if err != nil {
+			return nil, err
+		}
+		state.subs[i], found = dv, found || dv != nil
+	}
+	if !found {
+		return nil, fmt.Errorf("field \"%v\" was not indexed with SortedSetDocValues", field)
+	}
+	state.ordinals = index.NewOrdinalMap(reader, state.subs)
+	state.valueCount = state.ordinals.ValueCount()
+
+	// the labels are sorted by their path, so the labels of each
+	// dimension have contiguous ordinals
+	var lastDim string
+	startOrd := int64(-1)
+	for ord := int64(0); ord < state.valueCount; ord++ {
+		components := StringToPath(string(state.LookupOrd(ord)))
+		if len(components) != 2 {
+			return nil, fmt.Errorf("this class can only handle 2 level hierarchy (dim/value); got: %v %v",
+				components, state.LookupOrd(ord))
+		}
+		if components[0] != lastDim {
+			if lastDim != "" {
+				state.prefixToOrdRange[lastDim] = &OrdRange{startOrd, ord - 1}
+			}
+			startOrd = ord
+			lastDim = components[0]
+		}
+	}
+	if lastDim != "" {
+		state.prefixToOrdRange[lastDim] = &OrdRange{startOrd, state.valueCount - 1}
+	}
+	return state, nil
+}
+
+/* Indexed field we are reading. */
+func (s *SortedSetDocValuesReaderState) Field() string {
+	return s.field
+}
+
+/* Top-level IndexReader this state was created for. */
+func (s *SortedSetDocValuesReaderState) OrigReader() index.IndexReader {
+	return s.reader
+}
+
+/* Returns the OrdRange for this dimension, or nil if the dimension was never indexed. */
+func (s *SortedSetDocValuesReaderState) OrdRange(dim string) *OrdRange {
+	return s.prefixToOrdRange[dim]
+}
+
+/* Returns the indexed dimensions, sorted. */
+func (s *SortedSetDocValuesReaderState) Dims() []string {
+	dims := make([]string, 0, len(s.prefixToOrdRange))
+	for dim := range s.prefixToOrdRange {
+		dims = append(dims, dim)
+	}
+	sort.Strings(dims)
+	return dims
+}
+
+/* Number of unique labels, which is the number of global ordinals. */
+func (s *SortedSetDocValuesReaderState) Size() int64 {
+	return s.valueCount
+}
+
+/* Returns the encoded path (see PathToString()) of a global ordinal. */
+func (s *SortedSetDocValuesReaderState) LookupOrd(globalOrd int64) []byte {
+	segment := s.ordinals.FirstSegmentNumber(globalOrd)
+	return s.subs[segment].LookupOrd(s.ordinals.FirstSegmentOrd(globalOrd))
+}
+
+/*
+Returns the SortedSetDocValues of the leaf ctx of the reader, and the
+global ordinals of its segment ordinals; or an error if ctx isn't a
+leaf of the reader of this state.
+*/
+func (s *SortedSetDocValuesReaderState) leafDocValues(ctx *index.AtomicReaderContext) (SortedSetDocValues, []int64, error) {
+	if ctx.Ord >= len(s.leaves) || s.leaves[ctx.Ord].Reader() != ctx.Reader() {
+		return nil, nil, fmt.Errorf("the SortedSetDocValuesReaderState provided to this class does not match the reader being searched; you must create a new SortedSetDocValuesReaderState every time you open a new IndexReader")
+	}
+	if s.subs[ctx.Ord] == nil {
+		return nil, nil, nil
+	}
+	// a new instance, as SortedSetDocValues are iterated by one
+	// goroutine at a time
+	dv, err := ctx.Reader().(index.AtomicReader).SortedSetDocValues(s.field)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dv, s.ordinals.GlobalOrds(ctx.Ord), nil
+}
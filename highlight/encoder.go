@@ -0,0 +1,70 @@
+package highlight
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// search/highlight/Encoder.java
+
+/*
+Encodes original text. The Encoder works with the Formatter to
+generate the output.
+*/
+type Encoder interface {
+	EncodeText(originalText string) string
+}
+
+// search/highlight/DefaultEncoder.java
+
+/* Simple Encoder implementation that does not modify the output. */
+type DefaultEncoder struct{}
+
+func (e DefaultEncoder) EncodeText(originalText string) string {
+	return originalText
+}
+
+// search/highlight/SimpleHTMLEncoder.java
+
+/* Simple Encoder implementation to escape text for HTML output. */
+type SimpleHTMLEncoder struct{}
+
+func (e SimpleHTMLEncoder) EncodeText(originalText string) string {
+	return HTMLEncode(originalText)
+}
+
+/*
+Encode string into HTML: escapes the HTML special characters, and
+non-ASCII characters as numeric character references.
+*/
+func HTMLEncode(plainText string) string {
+	if plainText == "" {
+		return ""
+	}
+	var result bytes.Buffer
+	for _, ch := range plainText {
+		switch ch {
+		case '"':
+			result.WriteString("&quot;")
+		case '&':
+			result.WriteString("&amp;")
+		case '<':
+			result.WriteString("&lt;")
+		case '>':
+			result.WriteString("&gt;")
+		case '\'':
+			result.WriteString("&#x27;")
+		case '/':
+			result.WriteString("&#x2F;")
+		default:
+			if ch < 128 {
+				result.WriteRune(ch)
+			} else {
+				result.WriteString("&#")
+				result.WriteString(strconv.Itoa(int(ch)))
+				result.WriteRune(';')
+			}
+		}
+	}
+	return result.String()
+}
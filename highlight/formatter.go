@@ -0,0 +1,42 @@
+package highlight
+
+// search/highlight/Formatter.java
+
+/*
+Processes terms found in the original text, typically by applying
+some form of mark-up to highlight terms in HTML search results pages.
+*/
+type Formatter interface {
+	// Returns the originalText with mark-up applied, if the tokenGroup
+	// was scored as a match. originalText was already encoded by the
+	// Highlighter's Encoder.
+	HighlightTerm(originalText string, tokenGroup *TokenGroup) string
+}
+
+// search/highlight/SimpleHTMLFormatter.java
+
+const (
+	DEFAULT_PRE_TAG  = "<B>"
+	DEFAULT_POST_TAG = "</B>"
+)
+
+/* Simple Formatter which surrounds the matched terms with tags. */
+type SimpleHTMLFormatter struct {
+	preTag, postTag string
+}
+
+/* Default constructor uses HTML: <B> tags to markup terms. */
+func NewSimpleHTMLFormatter() *SimpleHTMLFormatter {
+	return NewSimpleHTMLFormatterWithTags(DEFAULT_PRE_TAG, DEFAULT_POST_TAG)
+}
+
+func NewSimpleHTMLFormatterWithTags(preTag, postTag string) *SimpleHTMLFormatter {
+	return &SimpleHTMLFormatter{preTag, postTag}
+}
+
+func (f *SimpleHTMLFormatter) HighlightTerm(originalText string, tokenGroup *TokenGroup) string {
+	if tokenGroup.TotalScore() <= 0 {
+		return originalText
+	}
+	return f.preTag + originalText + f.postTag
+}
@@ -0,0 +1,80 @@
+package highlight
+
+import (
+	"github.com/gzg1984/golucene/core/analysis"
+	ta "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+)
+
+// search/highlight/Fragmenter.java
+
+/*
+Implements the policy for breaking text into multiple fragments for
+consideration by the Highlighter. A sophisticated implementation may
+do this on the basis of detecting end of sentences in the text.
+*/
+type Fragmenter interface {
+	// Initializes the Fragmenter. You can grab references to the
+	// Attributes you are interested in from tokenStream and then access
+	// the values in IsNewFragment().
+	Start(originalText string, tokenStream analysis.TokenStream)
+	// Test to see if this token from the stream should be held in a
+	// new TextFragment. Every time this is called, the TokenStream
+	// passed to Start() will have been incremented.
+	IsNewFragment() bool
+}
+
+// search/highlight/SimpleFragmenter.java
+
+const DEFAULT_FRAGMENT_SIZE = 100
+
+/*
+Fragmenter implementation which breaks text up into same-size
+fragments with no concerns over spotting sentence boundaries.
+*/
+type SimpleFragmenter struct {
+	currentNumFrags int
+	fragmentSize    int
+	offsetAtt       ta.OffsetAttribute
+}
+
+func NewSimpleFragmenter() *SimpleFragmenter {
+	return NewSimpleFragmenterOfSize(DEFAULT_FRAGMENT_SIZE)
+}
+
+/* fragmentSize is the size in number of characters of each fragment. */
+func NewSimpleFragmenterOfSize(fragmentSize int) *SimpleFragmenter {
+	return &SimpleFragmenter{fragmentSize: fragmentSize}
+}
+
+func (f *SimpleFragmenter) Start(originalText string, stream analysis.TokenStream) {
+	f.offsetAtt = stream.Attributes().Add("OffsetAttribute").(ta.OffsetAttribute)
+	f.currentNumFrags = 1
+}
+
+func (f *SimpleFragmenter) IsNewFragment() bool {
+	isNewFrag := f.offsetAtt.EndOffset() >= f.fragmentSize*f.currentNumFrags
+	if isNewFrag {
+		f.currentNumFrags++
+	}
+	return isNewFrag
+}
+
+// Returns the size in number of characters of each fragment.
+func (f *SimpleFragmenter) FragmentSize() int {
+	return f.fragmentSize
+}
+
+func (f *SimpleFragmenter) SetFragmentSize(size int) {
+	f.fragmentSize = size
+}
+
+// search/highlight/NullFragmenter.java
+
+/*
+Fragmenter implementation which does not fragment the text. This is
+useful for highlighting the entire content of a document or field.
+*/
+type NullFragmenter struct{}
+
+func (f NullFragmenter) Start(originalText string, tokenStream analysis.TokenStream) {}
+func (f NullFragmenter) IsNewFragment() bool                                         { return false }
@@ -0,0 +1,337 @@
+package highlight
+
+import (
+	"fmt"
+	"github.com/gzg1984/golucene/core/analysis"
+	ta "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"sort"
+	"strings"
+)
+
+// search/highlight/Highlighter.java
+
+const DEFAULT_MAX_CHARS_TO_ANALYZE = 50 * 1024
+
+/*
+Highlights terms in text, extracting the most relevant section. The
+document text is analysed in chunks to record hit statistics across
+the document. After accumulating stats, the fragments with the
+highest scores are returned.
+
+Offsets of the tokens, and therefore the positions in the text, are
+in characters (runes), like the ones produced by analyzers.
+*/
+type Highlighter struct {
+	maxDocCharsToAnalyze int
+	formatter            Formatter
+	encoder              Encoder
+	textFragmenter       Fragmenter
+	fragmentScorer       Scorer
+}
+
+func NewHighlighter(fragmentScorer Scorer) *Highlighter {
+	return NewHighlighterWithFormatter(NewSimpleHTMLFormatter(), fragmentScorer)
+}
+
+func NewHighlighterWithFormatter(formatter Formatter, fragmentScorer Scorer) *Highlighter {
+	return NewHighlighterWithEncoder(formatter, DefaultEncoder{}, fragmentScorer)
+}
+
+func NewHighlighterWithEncoder(formatter Formatter, encoder Encoder, fragmentScorer Scorer) *Highlighter {
+	return &Highlighter{
+		maxDocCharsToAnalyze: DEFAULT_MAX_CHARS_TO_ANALYZE,
+		formatter:            formatter,
+		encoder:              encoder,
+		textFragmenter:       NewSimpleFragmenter(),
+		fragmentScorer:       fragmentScorer,
+	}
+}
+
+/*
+Highlights chosen terms in a text, extracting the most relevant
+section. This is a convenience method that calls
+BestFragmentFromTokenStream(), analyzing text with analyzer.
+
+Returns highlighted text fragment or "" if no terms found.
+*/
+func (h *Highlighter) BestFragment(analyzer analysis.Analyzer, fieldName, text string) (string, error) {
+	tokenStream, err := analyzer.TokenStreamForString(fieldName, text)
+	if err != nil {
+		return "", err
+	}
+	return h.BestFragmentFromTokenStream(tokenStream, text)
+}
+
+/*
+Highlights chosen terms in a text, extracting the most relevant
+section. The document text is analysed in chunks to record hit
+statistics across the document. After accumulating stats, the
+fragment with the highest score is returned.
+
+tokenStream is a stream of tokens identified in the text parameter,
+including offset information. This is typically produced by an
+analyzer re-parsing a document's text. Some work may be done on
+retrieving TokenStreams more efficiently by adding support for
+storing original text position data in the Lucene index but this
+support is not currently available (as of Lucene 1.4 rc2).
+
+Returns highlighted text fragment or "" if no terms found.
+*/
+func (h *Highlighter) BestFragmentFromTokenStream(tokenStream analysis.TokenStream, text string) (string, error) {
+	results, err := h.BestFragments(tokenStream, text, 1)
+	if err != nil || len(results) == 0 {
+		return "", err
+	}
+	return results[0], nil
+}
+
+/*
+Highlights chosen terms in a text, extracting the most relevant
+sections. The document text is analysed in chunks to record hit
+statistics across the document. After accumulating stats, the
+fragments with the highest scores are returned as a slice of strings
+in order of score (contiguous fragments are merged into one in their
+original order to improve readability).
+
+Returns up to maxNumFragments highlighted text fragments.
+*/
+func (h *Highlighter) BestFragments(tokenStream analysis.TokenStream, text string,
+	maxNumFragments int) ([]string, error) {
+
+	maxNumFragments = maxInt(1, maxNumFragments) // sanity check
+
+	frags, err := h.BestTextFragments(tokenStream, text, true, maxNumFragments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get text
+	var fragTexts []string
+	for _, frag := range frags {
+		if frag.Score() > 0 {
+			fragTexts = append(fragTexts, frag.String())
+		}
+	}
+	return fragTexts, nil
+}
+
+/*
+Highlights terms in the text, extracting the most relevant sections
+and concatenating the chosen fragments with a separator (typically
+"..."). The document text is analysed in chunks to record hit
+statistics across the document. After accumulating stats, the
+fragments with the highest scores are returned in order as "separator"
+delimited strings.
+
+Returns highlighted text.
+*/
+func (h *Highlighter) BestFragmentsJoined(tokenStream analysis.TokenStream, text string,
+	maxNumFragments int, separator string) (string, error) {
+
+	sections, err := h.BestFragments(tokenStream, text, maxNumFragments)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(sections, separator), nil
+}
+
+/*
+Low level api to get the most relevant (formatted) sections of the
+document. This method has been made public to allow visibility of
+score information held in TextFragment objects. Thanks to Jason
+Calabrese for help in redefining the interface.
+*/
+func (h *Highlighter) BestTextFragments(tokenStream analysis.TokenStream, text string,
+	mergeContiguousFragments bool, maxNumFragments int) (frags []*TextFragment, err error) {
+
+	var docFrags []*TextFragment
+	newText := new(strings.Builder)
+	runes := []rune(text)
+
+	termAtt := tokenStream.Attributes().Add("CharTermAttribute").(ta.CharTermAttribute)
+	offsetAtt := tokenStream.Attributes().Add("OffsetAttribute").(ta.OffsetAttribute)
+	currentFrag := newTextFragment(newText, newText.Len(), len(docFrags))
+
+	newStream, err := h.fragmentScorer.Init(tokenStream)
+	if err != nil {
+		tokenStream.Close()
+		return nil, err
+	}
+	if newStream != nil {
+		tokenStream = newStream
+	}
+	defer func() {
+		tokenStream.End()
+		tokenStream.Close()
+	}()
+	h.fragmentScorer.StartFragment(currentFrag)
+	docFrags = append(docFrags, currentFrag)
+
+	lastEndOffset := 0
+	h.textFragmenter.Start(text, tokenStream)
+
+	tokenGroup := newTokenGroup(tokenStream)
+
+	// appends the cached token group to newText, marked up
+	flushTokenGroup := func() {
+		startOffset := tokenGroup.matchStartOffset
+		endOffset := tokenGroup.matchEndOffset
+		tokenText := string(runes[startOffset:endOffset])
+		markedUpText := h.formatter.HighlightTerm(h.encoder.EncodeText(tokenText), tokenGroup)
+		// store any whitespace etc from between this and last group
+		if startOffset > lastEndOffset {
+			newText.WriteString(h.encoder.EncodeText(string(runes[lastEndOffset:startOffset])))
+		}
+		newText.WriteString(markedUpText)
+		lastEndOffset = maxInt(endOffset, lastEndOffset)
+	}
+
+	if err = tokenStream.Reset(); err != nil {
+		return nil, err
+	}
+	next, err := tokenStream.IncrementToken()
+	for ; next && err == nil && offsetAtt.StartOffset() < h.maxDocCharsToAnalyze; next, err = tokenStream.IncrementToken() {
+		if offsetAtt.EndOffset() > len(runes) || offsetAtt.StartOffset() > len(runes) {
+			return nil, fmt.Errorf("Token %v exceeds length of provided text sized %v",
+				termText(termAtt), len(runes))
+		}
+		if tokenGroup.NumTokens() > 0 && tokenGroup.isDistinct() {
+			// the current token is distinct from previous tokens - markup
+			// the cached token group info
+			flushTokenGroup()
+			tokenGroup.clear()
+
+			// check if current token marks the start of a new fragment
+			if h.textFragmenter.IsNewFragment() {
+				currentFrag.score = h.fragmentScorer.FragmentScore()
+				// record stats for a new fragment
+				currentFrag.textEndPos = newText.Len()
+				currentFrag = newTextFragment(newText, newText.Len(), len(docFrags))
+				h.fragmentScorer.StartFragment(currentFrag)
+				docFrags = append(docFrags, currentFrag)
+			}
+		}
+
+		tokenGroup.addToken(h.fragmentScorer.TokenScore())
+	}
+	if err != nil {
+		return nil, err
+	}
+	currentFrag.score = h.fragmentScorer.FragmentScore()
+
+	if tokenGroup.NumTokens() > 0 {
+		// flush the accumulated text (same code as in above loop)
+		flushTokenGroup()
+	}
+
+	// Test what remains of the original text beyond the point where we
+	// stopped analyzing: if there is text beyond the last token
+	// considered, and that text is not too large...
+	if lastEndOffset < len(runes) && len(runes) <= h.maxDocCharsToAnalyze {
+		// append it to the last fragment
+		newText.WriteString(h.encoder.EncodeText(string(runes[lastEndOffset:])))
+	}
+
+	currentFrag.textEndPos = newText.Len()
+
+	// sort the most relevant sections of the text
+	sort.SliceStable(docFrags, func(i, j int) bool {
+		return docFrags[i].score > docFrags[j].score
+	})
+	if len(docFrags) > maxNumFragments {
+		docFrags = docFrags[:maxNumFragments]
+	}
+
+	// merge any contiguous fragments to improve readability
+	if mergeContiguousFragments {
+		mergeContiguous(docFrags)
+		frags = make([]*TextFragment, 0, len(docFrags))
+		for _, frag := range docFrags {
+			if frag != nil && frag.score > 0 {
+				frags = append(frags, frag)
+			}
+		}
+		return frags, nil
+	}
+	return docFrags, nil
+}
+
+/*
+Improves readability of a score-sorted list of TextFragments by
+merging any fragments that were contiguous in the original text into
+one larger fragment with the correct order. This will leave a
+"null" in the slice entry for the lesser scored fragment.
+*/
+func mergeContiguous(frag []*TextFragment) {
+	for mergingStillBeingDone := len(frag) > 1; mergingStillBeingDone; {
+		mergingStillBeingDone = false
+		// for each fragment, scan other frags looking for contiguous
+		// blocks
+		for i := range frag {
+			if frag[i] == nil {
+				continue
+			}
+			// merge any contiguous blocks
+			for x := range frag {
+				if frag[x] == nil {
+					continue
+				}
+				if frag[i] == nil {
+					break
+				}
+				var frag1, frag2 *TextFragment
+				var frag1Num, frag2Num int
+				// if blocks are contiguous....
+				if frag[i].follows(frag[x]) {
+					frag1, frag1Num, frag2, frag2Num = frag[x], x, frag[i], i
+				} else if frag[x].follows(frag[i]) {
+					frag1, frag1Num, frag2, frag2Num = frag[i], i, frag[x], x
+				}
+				// merging required..
+				if frag1 != nil {
+					bestScoringFragNum, worstScoringFragNum := frag2Num, frag1Num
+					if frag1.score > frag2.score {
+						bestScoringFragNum, worstScoringFragNum = frag1Num, frag2Num
+					}
+					frag1.merge(frag2)
+					frag[worstScoringFragNum] = nil
+					mergingStillBeingDone = true
+					frag[bestScoringFragNum] = frag1
+				}
+			}
+		}
+	}
+}
+
+func (h *Highlighter) MaxDocCharsToAnalyze() int {
+	return h.maxDocCharsToAnalyze
+}
+
+func (h *Highlighter) SetMaxDocCharsToAnalyze(maxDocCharsToAnalyze int) {
+	h.maxDocCharsToAnalyze = maxDocCharsToAnalyze
+}
+
+func (h *Highlighter) TextFragmenter() Fragmenter {
+	return h.textFragmenter
+}
+
+func (h *Highlighter) SetTextFragmenter(fragmenter Fragmenter) {
+	h.textFragmenter = fragmenter
+}
+
+// Returns the Scorer used to score each text fragment.
+func (h *Highlighter) FragmentScorer() Scorer {
+	return h.fragmentScorer
+}
+
+func (h *Highlighter) SetFragmentScorer(scorer Scorer) {
+	h.fragmentScorer = scorer
+}
+
+func (h *Highlighter) Encoder() Encoder {
+	return h.encoder
+}
+
+func (h *Highlighter) SetEncoder(encoder Encoder) {
+	h.encoder = encoder
+}
@@ -0,0 +1,176 @@
+package highlight
+
+import (
+	std "github.com/gzg1984/golucene/analysis/standard"
+	_ "github.com/gzg1984/golucene/core/codec/lucene71"
+	docu "github.com/gzg1984/golucene/core/document"
+	"github.com/gzg1984/golucene/core/index"
+	"github.com/gzg1984/golucene/core/search"
+	"github.com/gzg1984/golucene/core/store"
+	"github.com/gzg1984/golucene/core/util"
+	. "github.com/gzg1984/gounit"
+	"strings"
+	"testing"
+)
+
+const FIELD_NAME = "contents"
+
+func highlight(t *testing.T, h *Highlighter, text string, maxNumFragments int) []string {
+	ts, err := std.NewStandardAnalyzer().TokenStreamForString(FIELD_NAME, text)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	frags, err := h.BestFragments(ts, text, maxNumFragments)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	return frags
+}
+
+func sameFragments(t *testing.T, expected, actual []string) {
+	It(t).Should("expect %v fragments, got %v", expected, actual).Assert(len(expected) == len(actual))
+	for i := range expected {
+		It(t).Should("fragment %v: expect %q, got %q", i, expected[i], actual[i]).Verify(expected[i] == actual[i])
+	}
+}
+
+func TestHighlightTermQuery(t *testing.T) {
+	q := search.NewTermQuery(index.NewTerm(FIELD_NAME, "kennedy"))
+	h := NewHighlighter(NewQueryScorer(q))
+	frags := highlight(t, h, "John Kennedy has been shot", 1)
+	sameFragments(t, []string{"John <B>Kennedy</B> has been shot"}, frags)
+
+	// no match, no fragment
+	frags = highlight(t, h, "Nothing to see here", 1)
+	sameFragments(t, nil, frags)
+
+	// terms of another field are ignored
+	h = NewHighlighter(NewQueryScorerForField(q, "title"))
+	frags = highlight(t, h, "John Kennedy has been shot", 1)
+	sameFragments(t, nil, frags)
+}
+
+func TestHighlightBooleanQuery(t *testing.T) {
+	q := search.NewBooleanQuery()
+	q.Add(search.NewTermQuery(index.NewTerm(FIELD_NAME, "john")), search.SHOULD)
+	q.Add(search.NewTermQuery(index.NewTerm(FIELD_NAME, "shot")), search.SHOULD)
+	q.Add(search.NewTermQuery(index.NewTerm(FIELD_NAME, "kennedy")), search.MUST_NOT)
+	h := NewHighlighterWithFormatter(NewSimpleHTMLFormatterWithTags("[", "]"), NewQueryScorer(q))
+	frags := highlight(t, h, "John Kennedy has been shot", 1)
+	sameFragments(t, []string{"[John] Kennedy has been [shot]"}, frags)
+
+	terms := GetTerms(q, false, "")
+	It(t).Should("expect 2 terms, got %v", len(terms)).Verify(len(terms) == 2)
+	terms = GetTerms(q, true, "")
+	It(t).Should("expect 3 terms, got %v", len(terms)).Verify(len(terms) == 3)
+}
+
+func TestHighlightPhraseQuery(t *testing.T) {
+	q := search.NewPhraseQuery()
+	q.Add(index.NewTerm(FIELD_NAME, "john"))
+	q.Add(index.NewTerm(FIELD_NAME, "kennedy"))
+	h := NewHighlighter(NewQueryScorer(q))
+	// only the terms of the phrase occurrence are highlighted
+	frags := highlight(t, h, "Kennedy met John, then John Kennedy left", 1)
+	sameFragments(t, []string{"Kennedy met John, then <B>John</B> <B>Kennedy</B> left"}, frags)
+
+	// unless the terms are also searched for outside the phrase
+	bq := search.NewBooleanQuery()
+	bq.Add(q, search.SHOULD)
+	bq.Add(search.NewTermQuery(index.NewTerm(FIELD_NAME, "met")), search.SHOULD)
+	bq.Add(search.NewTermQuery(index.NewTerm(FIELD_NAME, "john")), search.SHOULD)
+	h = NewHighlighter(NewQueryScorer(bq))
+	frags = highlight(t, h, "Kennedy met John, then John Kennedy left", 1)
+	sameFragments(t, []string{"Kennedy <B>met</B> <B>John</B>, then <B>John</B> <B>Kennedy</B> left"}, frags)
+
+	// sloppy phrase
+	q = search.NewPhraseQuery()
+	q.Add(index.NewTerm(FIELD_NAME, "john"))
+	q.Add(index.NewTerm(FIELD_NAME, "left"))
+	q.SetSlop(1)
+	h = NewHighlighter(NewQueryScorer(q))
+	frags = highlight(t, h, "John met Kennedy, then John Kennedy left", 1)
+	sameFragments(t, []string{"John met Kennedy, then <B>John</B> Kennedy <B>left</B>"}, frags)
+}
+
+func TestHighlightFragments(t *testing.T) {
+	q := search.NewTermQuery(index.NewTerm(FIELD_NAME, "fox"))
+	h := NewHighlighter(NewQueryScorer(q))
+	h.SetTextFragmenter(NewSimpleFragmenterOfSize(20))
+	text := "The quick brown fox jumps over the lazy dog, " +
+		"Nothing happens in this part of the text at all, " +
+		"Then another fox appears"
+	frags := highlight(t, h, text, 3)
+	It(t).Should("expect 2 fragments, got %v", frags).Assert(len(frags) == 2)
+	for _, frag := range frags {
+		It(t).Should("expect highlighted fox in %q", frag).Verify(
+			len(frag) < len(text)/2 && strings.Contains(frag, "<B>fox</B>"))
+	}
+
+	ts, err := std.NewStandardAnalyzer().TokenStreamForString(FIELD_NAME, text)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	joined, err := h.BestFragmentsJoined(ts, text, 3, "...")
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect joined fragments, got %q", joined).Verify(joined == frags[0]+"..."+frags[1])
+
+	// the whole text with a NullFragmenter
+	h.SetTextFragmenter(NullFragmenter{})
+	frags = highlight(t, h, text, 3)
+	It(t).Should("expect 1 fragment, got %v", frags).Assert(len(frags) == 1)
+	It(t).Should("expect whole text, got %q", frags[0]).Verify(len(frags[0]) == len(text)+2*len("<B></B>"))
+}
+
+func TestHighlightEncoder(t *testing.T) {
+	q := search.NewTermQuery(index.NewTerm(FIELD_NAME, "wörld"))
+	h := NewHighlighterWithEncoder(NewSimpleHTMLFormatter(), SimpleHTMLEncoder{}, NewQueryScorer(q))
+	fragment, err := h.BestFragment(std.NewStandardAnalyzer(), FIELD_NAME, `<hello> "wörld" & co`)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	expected := "&lt;hello&gt; &quot;<B>w&#246;rld</B>&quot; &amp; co"
+	It(t).Should("expect %q, got %q", expected, fragment).Verify(fragment == expected)
+}
+
+func TestHighlightStoredField(t *testing.T) {
+	index.DefaultSimilarity = func() index.Similarity {
+		return search.NewDefaultSimilarity()
+	}
+
+	directory, err := store.OpenFSDirectory(t.TempDir())
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer directory.Close()
+
+	analyzer := std.NewStandardAnalyzer()
+	conf := index.NewIndexWriterConfig(util.VERSION_LATEST, analyzer)
+	writer, err := index.NewIndexWriter(directory, conf)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	for _, text := range []string{"A stored text about Kennedy", "Another text about nothing"} {
+		d := docu.NewDocument()
+		d.Add(docu.NewTextFieldFromString(FIELD_NAME, text, docu.STORE_YES))
+		d.Add(docu.NewTextFieldFromString("unstored", text, docu.STORE_NO))
+		err = writer.AddDocument(d.Fields())
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+	}
+	err = writer.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+
+	reader, err := index.OpenDirectoryReader(directory)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	defer reader.Close()
+
+	q := search.NewTermQuery(index.NewTerm(FIELD_NAME, "kennedy"))
+	searcher := search.NewIndexSearcher(reader)
+	res, err := searcher.Search(q, nil, 10)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect 1 hit, got %v", len(res.ScoreDocs)).Assert(len(res.ScoreDocs) == 1)
+	docId := res.ScoreDocs[0].Doc
+
+	// no term vectors: the stored field is re-analyzed
+	ts, err := GetTokenStreamWithOffsets(reader, docId, FIELD_NAME)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("expect no term vector").Verify(ts == nil)
+	ts, err = GetAnyTokenStream(reader, docId, FIELD_NAME, analyzer)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	doc, err := reader.Document(docId)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	fragment, err := NewHighlighter(NewQueryScorer(q)).BestFragmentFromTokenStream(ts, doc.Get(FIELD_NAME))
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	It(t).Should("got %q", fragment).Verify(fragment == "A stored text about <B>Kennedy</B>")
+
+	_, err = GetAnyTokenStream(reader, docId, "unstored", analyzer)
+	It(t).Should("expect error for unstored field").Verify(err != nil)
+}
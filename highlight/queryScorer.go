@@ -0,0 +1,174 @@
+package highlight
+
+import (
+	"github.com/gzg1984/golucene/core/analysis"
+	ta "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"github.com/gzg1984/golucene/core/search"
+)
+
+// search/highlight/QueryScorer.java
+
+/*
+Scorer implementation which scores text fragments by the number of
+unique query terms found. This type converts appropriate queries to
+weighted terms: the terms of TermQuery and of the non-prohibited
+clauses of BooleanQuery are highlighted wherever they occur, while
+the terms of PhraseQuery and MultiPhraseQuery are only highlighted
+where the phrase matches.
+
+Multi-term queries, like PrefixQuery or WildcardQuery, must be
+rewritten (see Query.Rewrite()) into the terms they match before
+creating a QueryScorer.
+*/
+type QueryScorer struct {
+	// the weighted terms of the query, by term text
+	terms map[string]*WeightedSpanTerm
+	// the phrases to match against each text, to find the positions
+	// where their terms are valid
+	phrases       []*phrase
+	maxTermWeight float32
+
+	totalScore float32
+	foundTerms map[string]bool
+	position   int
+	termAtt    ta.CharTermAttribute
+	posIncAtt  ta.PositionIncrementAttribute
+}
+
+/* Creates a QueryScorer highlighting the terms of query, of any field. */
+func NewQueryScorer(query search.Query) *QueryScorer {
+	return NewQueryScorerForField(query, "")
+}
+
+/*
+Creates a QueryScorer highlighting the terms of query that belong to
+field, or to any field if field is "".
+*/
+func NewQueryScorerForField(query search.Query, field string) *QueryScorer {
+	s := &QueryScorer{
+		terms:      make(map[string]*WeightedSpanTerm),
+		foundTerms: make(map[string]bool),
+	}
+	add := func(text string, boost float32, positionSensitive bool) {
+		t, ok := s.terms[text]
+		if !ok {
+			t = &WeightedSpanTerm{WeightedTerm: WeightedTerm{boost, text}}
+			t.positionSensitive = positionSensitive
+			s.terms[text] = t
+		} else if boost > t.Weight {
+			t.Weight = boost
+		}
+		// a term found outside of any phrase is valid everywhere
+		t.positionSensitive = t.positionSensitive && positionSensitive
+		if t.Weight > s.maxTermWeight {
+			s.maxTermWeight = t.Weight
+		}
+	}
+	walkQuery(query, false, func(f, text string, boost float32) {
+		if field == "" || f == field {
+			add(text, boost, false)
+		}
+	}, func(p *phrase) {
+		if field != "" && p.field != field {
+			return
+		}
+		s.phrases = append(s.phrases, p)
+		for _, alternatives := range p.terms {
+			for _, text := range alternatives {
+				add(text, p.boost, true)
+			}
+		}
+	})
+	return s
+}
+
+/*
+Returns the highest weighted term. Useful for Formatters that scale
+the highlighting of each term by its weight.
+*/
+func (s *QueryScorer) MaxTermWeight() float32 {
+	return s.maxTermWeight
+}
+
+// Returns the weighted term of the given text, or nil if it is not a
+// term of the query.
+func (s *QueryScorer) WeightedSpanTerm(token string) *WeightedSpanTerm {
+	return s.terms[token]
+}
+
+/*
+Reads tokenStream entirely to match the phrases of the query, if it
+has any; then returns a stream replaying the same tokens, which must
+be used instead of tokenStream.
+*/
+func (s *QueryScorer) Init(tokenStream analysis.TokenStream) (analysis.TokenStream, error) {
+	s.position = -1
+	s.termAtt = tokenStream.Attributes().Add("CharTermAttribute").(ta.CharTermAttribute)
+	s.posIncAtt = tokenStream.Attributes().Add("PositionIncrementAttribute").(ta.PositionIncrementAttribute)
+	if len(s.phrases) == 0 {
+		return nil, nil
+	}
+
+	for _, t := range s.terms {
+		t.positionSpans = t.positionSpans[:0]
+	}
+	if err := tokenStream.Reset(); err != nil {
+		return nil, err
+	}
+	cached := analysis.NewCachingTokenFilter(tokenStream)
+	// the query terms found at each position of the text
+	termsAt := make(map[int][]string)
+	position := -1
+	for {
+		ok, err := cached.IncrementToken()
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			break
+		}
+		position += s.posIncAtt.PositionIncrement()
+		if text := termText(s.termAtt); s.terms[text] != nil {
+			termsAt[position] = append(termsAt[position], text)
+		}
+	}
+	for _, p := range s.phrases {
+		for _, span := range p.match(termsAt) {
+			for _, alternatives := range p.terms {
+				for _, text := range alternatives {
+					s.terms[text].addPositionSpan(span)
+				}
+			}
+		}
+	}
+	return cached, nil
+}
+
+func (s *QueryScorer) StartFragment(newFragment *TextFragment) {
+	s.foundTerms = make(map[string]bool)
+	s.totalScore = 0
+}
+
+func (s *QueryScorer) TokenScore() float32 {
+	s.position += s.posIncAtt.PositionIncrement()
+	text := termText(s.termAtt)
+
+	t, ok := s.terms[text]
+	if !ok {
+		return 0
+	}
+	if t.positionSensitive && !t.CheckPosition(s.position) {
+		return 0
+	}
+
+	score := t.Weight
+	// found a query term - is it unique in this doc?
+	if !s.foundTerms[text] {
+		s.totalScore += score
+		s.foundTerms[text] = true
+	}
+	return score
+}
+
+func (s *QueryScorer) FragmentScore() float32 {
+	return s.totalScore
+}
@@ -0,0 +1,168 @@
+package highlight
+
+import (
+	"github.com/gzg1984/golucene/core/search"
+	"sort"
+)
+
+// search/highlight/QueryTermExtractor.java
+
+/*
+Extracts terms from a query, ignoring any position information: the
+terms of phrase queries are returned like the ones of term queries.
+Only the terms of fieldName are returned, unless it is "". If
+prohibited is true, the terms of prohibited clauses are extracted too.
+
+Multi-term queries, like PrefixQuery or WildcardQuery, must be
+rewritten (see Query.Rewrite()) into the terms they match before
+extracting their terms.
+*/
+func GetTerms(query search.Query, prohibited bool, fieldName string) []*WeightedTerm {
+	var terms []*WeightedTerm
+	byText := make(map[string]*WeightedTerm)
+	add := func(field, text string, boost float32) {
+		if fieldName != "" && field != fieldName {
+			return
+		}
+		if t, ok := byText[text]; !ok {
+			byText[text] = &WeightedTerm{boost, text}
+			terms = append(terms, byText[text])
+		} else if boost > t.Weight {
+			t.Weight = boost
+		}
+	}
+	walkQuery(query, prohibited, add, func(p *phrase) {
+		for _, alternatives := range p.terms {
+			for _, text := range alternatives {
+				add(p.field, text, p.boost)
+			}
+		}
+	})
+	return terms
+}
+
+/*
+A phrase of a PhraseQuery or MultiPhraseQuery: the alternative terms
+expected at each of its positions.
+*/
+type phrase struct {
+	field     string
+	terms     [][]string
+	positions []int
+	slop      int
+	boost     float32
+}
+
+/*
+Visits the leaf queries of query: calls visitTerm for the term of
+each TermQuery, and visitPhrase for each phrase query. Clauses of
+BooleanQuerys are only visited if they are not prohibited, unless
+prohibited is true. Other queries are ignored.
+*/
+func walkQuery(query search.Query, prohibited bool,
+	visitTerm func(field, text string, boost float32), visitPhrase func(*phrase)) {
+
+	switch q := query.(type) {
+	case *search.BooleanQuery:
+		for _, clause := range q.Clauses() {
+			if prohibited || !clause.IsProhibited() {
+				walkQuery(clause.Query(), prohibited, visitTerm, visitPhrase)
+			}
+		}
+	case *search.TermQuery:
+		visitTerm(q.Term().Field, string(q.Term().Bytes), q.Boost())
+	case *search.PhraseQuery:
+		if terms := q.Terms(); len(terms) > 0 {
+			p := &phrase{terms[0].Field, nil, q.Positions(), q.Slop(), q.Boost()}
+			for _, term := range terms {
+				p.terms = append(p.terms, []string{string(term.Bytes)})
+			}
+			visitPhrase(p)
+		}
+	case *search.MultiPhraseQuery:
+		if termArrays := q.TermArrays(); len(termArrays) > 0 {
+			p := &phrase{termArrays[0][0].Field, nil, q.Positions(), q.Slop(), q.Boost()}
+			for _, terms := range termArrays {
+				alternatives := make([]string, len(terms))
+				for i, term := range terms {
+					alternatives[i] = string(term.Bytes)
+				}
+				p.terms = append(p.terms, alternatives)
+			}
+			visitPhrase(p)
+		}
+	case *search.ConstantScoreQuery:
+		if inner := q.Query(); inner != nil {
+			walkQuery(inner, prohibited, visitTerm, visitPhrase)
+		}
+	case *search.FilteredQuery:
+		walkQuery(q.Query(), prohibited, visitTerm, visitPhrase)
+	}
+}
+
+/*
+Returns the spans of positions where this phrase matches, given the
+query terms found at each position of a text, in increasing order.
+
+An exact phrase (slop 0) must find its terms at their exact relative
+positions. A sloppy phrase must find all its terms, in any order,
+within a window of its length plus slop positions; this approximates
+the matches of a SpanNearQuery.
+*/
+func (p *phrase) match(termsAt map[int][]string) []PositionSpan {
+	minPos, maxPos := p.positions[0], p.positions[0]
+	for _, pos := range p.positions {
+		minPos, maxPos = minInt(minPos, pos), maxInt(maxPos, pos)
+	}
+	width := maxPos - minPos
+
+	starts := make([]int, 0, len(termsAt))
+	for position := range termsAt {
+		starts = append(starts, position)
+	}
+	sort.Ints(starts)
+
+	var spans []PositionSpan
+	for _, start := range starts {
+		if p.slop == 0 {
+			// anchor the phrase on its first term
+			start -= p.positions[0] - minPos
+			matches := true
+			for i, alternatives := range p.terms {
+				matches = matches && containsAny(termsAt[start+p.positions[i]-minPos], alternatives)
+			}
+			if matches {
+				spans = append(spans, PositionSpan{start, start + width})
+			}
+			continue
+		}
+		// sloppy: find each term at its first position in the window
+		end, matches := start, true
+		for _, alternatives := range p.terms {
+			found := false
+			for pos := start; pos <= start+width+p.slop && !found; pos++ {
+				if found = containsAny(termsAt[pos], alternatives); found {
+					end = maxInt(end, pos)
+				}
+			}
+			matches = matches && found
+		}
+		if matches && containsAny(termsAt[start], p.terms...) {
+			spans = append(spans, PositionSpan{start, end})
+		}
+	}
+	return spans
+}
+
+func containsAny(terms []string, alternatives ...[]string) bool {
+	for _, term := range terms {
+		for _, alts := range alternatives {
+			for _, alt := range alts {
+				if term == alt {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package highlight
+
+import (
+	"github.com/gzg1984/golucene/core/analysis"
+)
+
+// search/highlight/Scorer.java
+
+/*
+A Scorer is responsible for scoring a stream of tokens. These token
+scores can then be used to compute TextFragment scores.
+*/
+type Scorer interface {
+	// Called to init the Scorer with a TokenStream. You can grab
+	// references to the attributes you are interested in here and
+	// access them from TokenScore().
+	//
+	// Returns either a TokenStream that the Highlighter should continue
+	// using (eg if you read tokenStream in this method), or nil to
+	// continue using the same TokenStream that was passed in.
+	Init(tokenStream analysis.TokenStream) (analysis.TokenStream, error)
+	// Called when a new fragment is started for consideration.
+	StartFragment(newFragment *TextFragment)
+	// Called for each token in the current fragment. The Highlighter
+	// will increment the TokenStream passed to Init() on every call.
+	//
+	// Returns a score which is passed to the Highlighter class to
+	// influence the mark-up of the text (this return value is NOT used
+	// to score the fragment).
+	TokenScore() float32
+	// Called when the Highlighter has no more tokens for the current
+	// fragment - the Scorer returns the weighting it has derived for
+	// the most recent fragment, typically based on the results of
+	// TokenScore().
+	FragmentScore() float32
+}
@@ -0,0 +1,54 @@
+package highlight
+
+import (
+	"strings"
+)
+
+// search/highlight/TextFragment.java
+
+/*
+Low-level class used to record information about a section of a
+document with a score.
+*/
+type TextFragment struct {
+	markedUpText *strings.Builder
+	fragNum      int
+	textStartPos int
+	textEndPos   int
+	score        float32
+}
+
+func newTextFragment(markedUpText *strings.Builder, textStartPos, fragNum int) *TextFragment {
+	return &TextFragment{
+		markedUpText: markedUpText,
+		textStartPos: textStartPos,
+		fragNum:      fragNum,
+	}
+}
+
+func (f *TextFragment) Score() float32 {
+	return f.score
+}
+
+// Merges frag2, which must follow f, into f.
+func (f *TextFragment) merge(frag2 *TextFragment) {
+	f.textEndPos = frag2.textEndPos
+	if frag2.score > f.score {
+		f.score = frag2.score
+	}
+}
+
+// Returns true if f directly follows fragment in the text.
+func (f *TextFragment) follows(fragment *TextFragment) bool {
+	return f.textStartPos == fragment.textEndPos
+}
+
+// Returns the fragment sequence number.
+func (f *TextFragment) FragNum() int {
+	return f.fragNum
+}
+
+// Returns the marked-up text of this fragment.
+func (f *TextFragment) String() string {
+	return f.markedUpText.String()[f.textStartPos:f.textEndPos]
+}
@@ -0,0 +1,130 @@
+package highlight
+
+import (
+	"github.com/gzg1984/golucene/core/analysis"
+	ta "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+)
+
+// search/highlight/TokenGroup.java
+
+const MAX_NUM_TOKENS_PER_GROUP = 50
+
+/* A token of a TokenGroup. */
+type groupToken struct {
+	term                   string
+	startOffset, endOffset int
+}
+
+/*
+One, or several overlapping tokens, along with the score(s) and the
+scope of the original text.
+*/
+type TokenGroup struct {
+	tokens           []groupToken
+	scores           []float32
+	startOffset      int
+	endOffset        int
+	tot              float32
+	matchStartOffset int
+	matchEndOffset   int
+
+	offsetAtt ta.OffsetAttribute
+	termAtt   ta.CharTermAttribute
+}
+
+func newTokenGroup(tokenStream analysis.TokenStream) *TokenGroup {
+	return &TokenGroup{
+		tokens:    make([]groupToken, 0, MAX_NUM_TOKENS_PER_GROUP),
+		scores:    make([]float32, 0, MAX_NUM_TOKENS_PER_GROUP),
+		offsetAtt: tokenStream.Attributes().Add("OffsetAttribute").(ta.OffsetAttribute),
+		termAtt:   tokenStream.Attributes().Add("CharTermAttribute").(ta.CharTermAttribute),
+	}
+}
+
+func (g *TokenGroup) addToken(score float32) {
+	if len(g.tokens) >= MAX_NUM_TOKENS_PER_GROUP {
+		return
+	}
+	termStartOffset := g.offsetAtt.StartOffset()
+	termEndOffset := g.offsetAtt.EndOffset()
+	if len(g.tokens) == 0 {
+		g.startOffset, g.matchStartOffset = termStartOffset, termStartOffset
+		g.endOffset, g.matchEndOffset = termEndOffset, termEndOffset
+		g.tot += score
+	} else {
+		g.startOffset = minInt(g.startOffset, termStartOffset)
+		g.endOffset = maxInt(g.endOffset, termEndOffset)
+		if score > 0 {
+			if g.tot == 0 {
+				g.matchStartOffset = termStartOffset
+				g.matchEndOffset = termEndOffset
+			} else {
+				g.matchStartOffset = minInt(g.matchStartOffset, termStartOffset)
+				g.matchEndOffset = maxInt(g.matchEndOffset, termEndOffset)
+			}
+			g.tot += score
+		}
+	}
+	g.tokens = append(g.tokens, groupToken{termText(g.termAtt), termStartOffset, termEndOffset})
+	g.scores = append(g.scores, score)
+}
+
+// Returns true if the current token doesn't overlap the group.
+func (g *TokenGroup) isDistinct() bool {
+	return g.offsetAtt.StartOffset() >= g.endOffset
+}
+
+func (g *TokenGroup) clear() {
+	g.tokens = g.tokens[:0]
+	g.scores = g.scores[:0]
+	g.tot = 0
+}
+
+// Returns the term text of the token at the given index.
+func (g *TokenGroup) Term(index int) string {
+	return g.tokens[index].term
+}
+
+// Returns the score of the token at the given index.
+func (g *TokenGroup) Score(index int) float32 {
+	return g.scores[index]
+}
+
+// Returns the number of tokens in this group.
+func (g *TokenGroup) NumTokens() int {
+	return len(g.tokens)
+}
+
+// Returns the start position in the original text.
+func (g *TokenGroup) StartOffset() int {
+	return g.startOffset
+}
+
+// Returns the end position in the original text.
+func (g *TokenGroup) EndOffset() int {
+	return g.endOffset
+}
+
+// Returns all tokens' scores summed up.
+func (g *TokenGroup) TotalScore() float32 {
+	return g.tot
+}
+
+// Returns the text of the current token of a CharTermAttribute.
+func termText(termAtt ta.CharTermAttribute) string {
+	return string(termAtt.Buffer()[:termAtt.Length()])
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
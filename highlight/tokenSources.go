@@ -0,0 +1,185 @@
+package highlight
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gzg1984/golucene/core/analysis"
+	ta "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	"github.com/gzg1984/golucene/core/index"
+	. "github.com/gzg1984/golucene/core/index/model"
+	"sort"
+)
+
+// search/highlight/TokenSources.java
+
+/*
+A convenience method that tries to first get a TokenStream from term
+vectors with offsets, and then, if that fails, re-analyzes the stored
+field of the document with analyzer.
+*/
+func GetAnyTokenStream(reader index.IndexReader, docId int,
+	field string, analyzer analysis.Analyzer) (analysis.TokenStream, error) {
+
+	ts, err := GetTokenStreamWithOffsets(reader, docId, field)
+	if err != nil || ts != nil {
+		return ts, err
+	}
+	// No token info stored so fall back to analyzing raw content
+	return GetTokenStreamFromStoredField(reader, docId, field, analyzer)
+}
+
+/*
+Returns a TokenStream with positions and offsets built from the term
+vector of field in the document, or nil if the document has no such
+term vector, or if it was indexed without positions or offsets.
+*/
+func GetTokenStreamWithOffsets(reader index.IndexReader, docId int,
+	field string) (analysis.TokenStream, error) {
+
+	vectors, err := reader.TermVectors(docId)
+	if err != nil || vectors == nil {
+		return nil, err
+	}
+	vector := vectors.Terms(field)
+	if vector == nil {
+		return nil, nil
+	}
+	ts, err := GetTokenStreamFromTerms(vector)
+	if err == errNoOffsets {
+		return nil, nil
+	}
+	return ts, err
+}
+
+var errNoOffsets = errors.New("no offsets found in term vector")
+
+/*
+Low level api. Returns a token stream generated from a Terms of a
+term vector, which must include offsets. The tokens are sorted by
+their start offset, then by their position.
+*/
+func GetTokenStreamFromTerms(vector Terms) (analysis.TokenStream, error) {
+	var tokens []vectorToken
+	termsEnum := vector.Iterator(nil)
+	var dpEnum DocsAndPositionsEnum
+	for {
+		text, err := termsEnum.Next()
+		if err != nil {
+			return nil, err
+		} else if text == nil {
+			break
+		}
+		term := string(text)
+		if dpEnum, err = termsEnum.DocsAndPositionsByFlags(nil, dpEnum,
+			DOCS_POSITIONS_ENUM_FLAG_OFF_SETS); err != nil {
+			return nil, err
+		} else if dpEnum == nil {
+			return nil, errNoOffsets
+		}
+		if _, err = dpEnum.NextDoc(); err != nil {
+			return nil, err
+		}
+		freq, err := dpEnum.Freq()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < freq; i++ {
+			pos, err := dpEnum.NextPosition()
+			if err != nil {
+				return nil, err
+			}
+			startOffset, err := dpEnum.StartOffset()
+			if err != nil {
+				return nil, err
+			}
+			endOffset, err := dpEnum.EndOffset()
+			if err != nil {
+				return nil, err
+			}
+			if startOffset < 0 {
+				return nil, errNoOffsets
+			}
+			tokens = append(tokens, vectorToken{term, pos, startOffset, endOffset})
+		}
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		if tokens[i].startOffset != tokens[j].startOffset {
+			return tokens[i].startOffset < tokens[j].startOffset
+		}
+		return tokens[i].position < tokens[j].position
+	})
+	return newStoredTokenStream(tokens), nil
+}
+
+/*
+Re-analyzes the stored value of field in the document with analyzer.
+Returns an error if the field is not stored.
+*/
+func GetTokenStreamFromStoredField(reader index.IndexReader, docId int,
+	field string, analyzer analysis.Analyzer) (analysis.TokenStream, error) {
+
+	doc, err := reader.Document(docId)
+	if err != nil {
+		return nil, err
+	}
+	contents := doc.Get(field)
+	if contents == "" {
+		return nil, fmt.Errorf("Field %v in document #%v is not stored and cannot be analyzed",
+			field, docId)
+	}
+	return GetTokenStream(field, contents, analyzer)
+}
+
+// Returns the tokens of contents, analyzed with analyzer.
+func GetTokenStream(field, contents string, analyzer analysis.Analyzer) (analysis.TokenStream, error) {
+	return analyzer.TokenStreamForString(field, contents)
+}
+
+type vectorToken struct {
+	term                   string
+	position               int
+	startOffset, endOffset int
+}
+
+/* A TokenStream replaying the tokens read from a term vector. */
+type storedTokenStream struct {
+	*analysis.TokenStreamImpl
+	tokens    []vectorToken
+	current   int
+	position  int
+	termAtt   ta.CharTermAttribute
+	offsetAtt ta.OffsetAttribute
+	posIncAtt ta.PositionIncrementAttribute
+}
+
+func newStoredTokenStream(tokens []vectorToken) *storedTokenStream {
+	ans := &storedTokenStream{
+		TokenStreamImpl: analysis.NewTokenStream(),
+		tokens:          tokens,
+		position:        -1,
+	}
+	ans.termAtt = ans.Attributes().Add("CharTermAttribute").(ta.CharTermAttribute)
+	ans.offsetAtt = ans.Attributes().Add("OffsetAttribute").(ta.OffsetAttribute)
+	ans.posIncAtt = ans.Attributes().Add("PositionIncrementAttribute").(ta.PositionIncrementAttribute)
+	return ans
+}
+
+func (ts *storedTokenStream) IncrementToken() (bool, error) {
+	if ts.current >= len(ts.tokens) {
+		return false, nil
+	}
+	ts.Attributes().Clear()
+	token := ts.tokens[ts.current]
+	ts.current++
+	ts.termAtt.CopyBuffer([]rune(token.term))
+	ts.offsetAtt.SetOffset(token.startOffset, token.endOffset)
+	// tokens stacked on a position have an increment of 0
+	ts.posIncAtt.SetPositionIncrement(maxInt(0, token.position-ts.position))
+	ts.position = maxInt(ts.position, token.position)
+	return true, nil
+}
+
+func (ts *storedTokenStream) Reset() error {
+	ts.current, ts.position = 0, -1
+	return ts.TokenStreamImpl.Reset()
+}
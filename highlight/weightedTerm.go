@@ -0,0 +1,62 @@
+package highlight
+
+// search/highlight/WeightedTerm.java
+
+/*
+Lightweight class to hold term and a weight value used for scoring
+this term.
+*/
+type WeightedTerm struct {
+	Weight float32 // multiplier
+	Term   string  // stemmed form
+}
+
+// search/highlight/WeightedSpanTerm.java
+
+/*
+Lightweight class to hold term, weight, and positions used for
+scoring this term. The term of a phrase query is only valid within
+the positions where the phrase matched: it is position sensitive.
+*/
+type WeightedSpanTerm struct {
+	WeightedTerm
+	positionSensitive bool
+	positionSpans     []PositionSpan
+}
+
+/*
+Checks to see if this term is valid at position.
+
+Returns true if this term is a hit at this position.
+*/
+func (t *WeightedSpanTerm) CheckPosition(position int) bool {
+	for _, span := range t.positionSpans {
+		if position >= span.Start && position <= span.End {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *WeightedSpanTerm) addPositionSpan(span PositionSpan) {
+	t.positionSpans = append(t.positionSpans, span)
+}
+
+func (t *WeightedSpanTerm) IsPositionSensitive() bool {
+	return t.positionSensitive
+}
+
+// Returns the spans of positions where this term is valid.
+func (t *WeightedSpanTerm) PositionSpans() []PositionSpan {
+	return t.positionSpans
+}
+
+// search/highlight/PositionSpan.java
+
+/*
+Utility class to store a Span, from Start to End position both
+inclusive.
+*/
+type PositionSpan struct {
+	Start, End int
+}
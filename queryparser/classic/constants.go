@@ -30,8 +30,8 @@ const (
 	RANGEEX_START
 	NUMBER
 	RANGE_TO
-	RANGEiN_END
-	RAGEEX_END
+	RANGEIN_END
+	RANGEEX_END
 	RANGE_QUOTED
 	RANGE_GOOP
 )
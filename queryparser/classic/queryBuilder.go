@@ -21,7 +21,7 @@ func newQueryBuilder() *QueryBuilder {
 
 // L193
 func (qp *QueryBuilder) createFieldQuery(analyzer analysis.Analyzer,
-	operator search.Occur, field, queryText string, quoted bool, phraseSlop int) search.Query {
+	operator search.Occur, field, queryText string, quoted bool, phraseSlop int) (search.Query, error) {
 
 	assert(operator == search.SHOULD || operator == search.MUST)
 	assert(analyzer != nil)
@@ -69,7 +69,7 @@ func (qp *QueryBuilder) createFieldQuery(analyzer analysis.Analyzer,
 		}
 		return nil
 	}(); err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	// rewind the buffer stream
@@ -81,20 +81,34 @@ func (qp *QueryBuilder) createFieldQuery(analyzer analysis.Analyzer,
 	}
 
 	if numTokens == 0 {
-		return nil
+		return nil, nil
 	} else if numTokens == 1 {
 		if hasNext, err := buffer.IncrementToken(); err == nil {
 			assert(hasNext)
 			termAtt.FillBytesRef()
 		} // safe to ignore error, because we know the number of tokens
-		return qp.newTermQuery(index.NewTermFromBytes(field, util.DeepCopyOf(bytes).ToBytes()))
+		return qp.newTermQuery(index.NewTermFromBytes(field, util.DeepCopyOf(bytes).ToBytes())), nil
 	} else {
 		if severalTokensAtSamePosition || !quoted {
 			if positionCount == 1 || !quoted {
 				// no phrase query:
 
 				if positionCount == 1 {
-					panic("not implemented yet")
+					// simple case: only one position, with synonyms
+					q := qp.newBooleanQuery(true)
+					for i := 0; i < numTokens; i++ {
+						hasNext, err := buffer.IncrementToken()
+						if err != nil {
+							continue // safe to ignore error, because we know the number of tokens
+						}
+						assert(hasNext)
+						termAtt.FillBytesRef()
+						currentQuery := qp.newTermQuery(index.NewTermFromBytes(field, util.DeepCopyOf(bytes).ToBytes()))
+						if err = q.Add(currentQuery, search.SHOULD); err != nil {
+							return nil, err
+						}
+					}
+					return q, nil
 				} else {
 					// multiple positions
 					q := qp.newBooleanQuery(false)
@@ -108,24 +122,90 @@ func (qp *QueryBuilder) createFieldQuery(analyzer analysis.Analyzer,
 						termAtt.FillBytesRef()
 
 						if posIncrAtt != nil && posIncrAtt.PositionIncrement() == 0 {
-							panic("not implemented yet")
+							bq, ok := currentQuery.(*search.BooleanQuery)
+							if !ok {
+								bq = qp.newBooleanQuery(true)
+								if err = bq.Add(currentQuery, search.SHOULD); err != nil {
+									return nil, err
+								}
+								currentQuery = bq
+							}
+							if err = bq.Add(qp.newTermQuery(index.NewTermFromBytes(field, util.DeepCopyOf(bytes).ToBytes())), search.SHOULD); err != nil {
+								return nil, err
+							}
 						} else {
 							if currentQuery != nil {
-								q.Add(currentQuery, operator)
+								if err = q.Add(currentQuery, operator); err != nil {
+									return nil, err
+								}
 							}
 							currentQuery = qp.newTermQuery(index.NewTermFromBytes(field, util.DeepCopyOf(bytes).ToBytes()))
 						}
 					}
-					q.Add(currentQuery, operator)
-					return q
+					if err := q.Add(currentQuery, operator); err != nil {
+						return nil, err
+					}
+					return q, nil
 				}
 			} else {
-				panic("not implemented yet")
+				// phrase query:
+				mpq := qp.newMultiPhraseQuery()
+				mpq.SetSlop(phraseSlop)
+				var multiTerms []*index.Term
+				position := -1
+				for i := 0; i < numTokens; i++ {
+					positionIncrement := 1
+					if hasNext, err := buffer.IncrementToken(); err == nil {
+						assert(hasNext)
+						termAtt.FillBytesRef()
+						if posIncrAtt != nil {
+							positionIncrement = posIncrAtt.PositionIncrement()
+						}
+					} // safe to ignore error, because we know the number of tokens
+
+					if positionIncrement > 0 && len(multiTerms) > 0 {
+						if qp.enablePositionIncrements {
+							mpq.AddTermsAt(multiTerms, position)
+						} else {
+							mpq.AddTerms(multiTerms...)
+						}
+						multiTerms = nil
+					}
+					position += positionIncrement
+					multiTerms = append(multiTerms, index.NewTermFromBytes(field, util.DeepCopyOf(bytes).ToBytes()))
+				}
+				if qp.enablePositionIncrements {
+					mpq.AddTermsAt(multiTerms, position)
+				} else {
+					mpq.AddTerms(multiTerms...)
+				}
+				return mpq, nil
 			}
 		} else {
-			panic("not implemented yet")
+			pq := qp.newPhraseQuery()
+			pq.SetSlop(phraseSlop)
+			position := -1
+
+			for i := 0; i < numTokens; i++ {
+				positionIncrement := 1
+				if hasNext, err := buffer.IncrementToken(); err == nil {
+					assert(hasNext)
+					termAtt.FillBytesRef()
+					if posIncrAtt != nil {
+						positionIncrement = posIncrAtt.PositionIncrement()
+					}
+				} // safe to ignore error, because we know the number of tokens
+
+				term := index.NewTermFromBytes(field, util.DeepCopyOf(bytes).ToBytes())
+				if qp.enablePositionIncrements {
+					position += positionIncrement
+					pq.AddAt(term, position)
+				} else {
+					pq.Add(term)
+				}
+			}
+			return pq, nil
 		}
-		panic("should not be here")
 	}
 }
 
@@ -134,6 +214,14 @@ func (qp *QueryBuilder) newBooleanQuery(disableCoord bool) *search.BooleanQuery
 	return search.NewBooleanQueryDisableCoord(disableCoord)
 }
 
+func (qp *QueryBuilder) newPhraseQuery() *search.PhraseQuery {
+	return search.NewPhraseQuery()
+}
+
+func (qp *QueryBuilder) newMultiPhraseQuery() *search.MultiPhraseQuery {
+	return search.NewMultiPhraseQuery()
+}
+
 func (qp *QueryBuilder) newTermQuery(term *index.Term) search.Query {
 	return search.NewTermQuery(term)
 }
@@ -2,7 +2,7 @@ package classic
 
 import (
 	"errors"
-	// "fmt"
+	"fmt"
 	"github.com/gzg1984/golucene/core/analysis"
 	"github.com/gzg1984/golucene/core/search"
 	"github.com/gzg1984/golucene/core/util"
@@ -29,7 +29,6 @@ type QueryParser struct {
 	jj_la1                 []int
 
 	jj_2_rtns []*JJCalls
-	jj_rescan bool
 	jj_gc     int
 }
 
@@ -55,7 +54,27 @@ func (qp *QueryParser) conjunction() (int, error) {
 	}
 	switch qp.jj_ntk {
 	case AND, OR:
-		panic("niy")
+		if qp.jj_ntk == -1 {
+			qp.get_jj_ntk()
+		}
+		switch qp.jj_ntk {
+		case AND:
+			if _, err := qp.jj_consume_token(AND); err != nil {
+				return 0, err
+			}
+			ret = CONJ_AND
+		case OR:
+			if _, err := qp.jj_consume_token(OR); err != nil {
+				return 0, err
+			}
+			ret = CONJ_OR
+		default:
+			qp.jj_la1[0] = qp.jj_gen
+			if _, err := qp.jj_consume_token(-1); err != nil {
+				return 0, err
+			}
+			return 0, errors.New("parse error")
+		}
 	default:
 		qp.jj_la1[1] = qp.jj_gen
 	}
@@ -69,7 +88,32 @@ func (qp *QueryParser) modifiers() (ret int, err error) {
 	}
 	switch qp.jj_ntk {
 	case NOT, PLUS, MINUS:
-		panic("not implemented yet")
+		if qp.jj_ntk == -1 {
+			qp.get_jj_ntk()
+		}
+		switch qp.jj_ntk {
+		case PLUS:
+			if _, err = qp.jj_consume_token(PLUS); err != nil {
+				return
+			}
+			ret = MOD_REQ
+		case MINUS:
+			if _, err = qp.jj_consume_token(MINUS); err != nil {
+				return
+			}
+			ret = MOD_NOT
+		case NOT:
+			if _, err = qp.jj_consume_token(NOT); err != nil {
+				return
+			}
+			ret = MOD_NOT
+		default:
+			qp.jj_la1[2] = qp.jj_gen
+			if _, err = qp.jj_consume_token(-1); err != nil {
+				return
+			}
+			return 0, errors.New("parse error")
+		}
 	default:
 		qp.jj_la1[3] = qp.jj_gen
 	}
@@ -134,7 +178,36 @@ func (qp *QueryParser) Query(field string) (q search.Query, err error) {
 
 func (qp *QueryParser) clause(field string) (q search.Query, err error) {
 	if qp.jj_2_1(2) {
-		panic("not implemented yet")
+		if qp.jj_ntk == -1 {
+			qp.get_jj_ntk()
+		}
+		switch qp.jj_ntk {
+		case TERM:
+			var fieldToken *Token
+			if fieldToken, err = qp.jj_consume_token(TERM); err != nil {
+				return nil, err
+			}
+			if _, err = qp.jj_consume_token(COLON); err != nil {
+				return nil, err
+			}
+			if field, err = qp.discardEscapeChar(fieldToken.image); err != nil {
+				return nil, err
+			}
+		case STAR:
+			if _, err = qp.jj_consume_token(STAR); err != nil {
+				return nil, err
+			}
+			if _, err = qp.jj_consume_token(COLON); err != nil {
+				return nil, err
+			}
+			field = "*"
+		default:
+			qp.jj_la1[5] = qp.jj_gen
+			if _, err = qp.jj_consume_token(-1); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("parse error")
+		}
 	}
 	if qp.jj_ntk == -1 {
 		qp.get_jj_ntk()
@@ -147,7 +220,29 @@ func (qp *QueryParser) clause(field string) (q search.Query, err error) {
 			return nil, err
 		}
 	case LPAREN:
-		panic("not implemented yet")
+		if _, err = qp.jj_consume_token(LPAREN); err != nil {
+			return nil, err
+		}
+		if q, err = qp.Query(field); err != nil {
+			return nil, err
+		}
+		if _, err = qp.jj_consume_token(RPAREN); err != nil {
+			return nil, err
+		}
+		if qp.jj_ntk == -1 {
+			qp.get_jj_ntk()
+		}
+		switch qp.jj_ntk {
+		case CARAT:
+			if _, err = qp.jj_consume_token(CARAT); err != nil {
+				return nil, err
+			}
+			if boost, err = qp.jj_consume_token(NUMBER); err != nil {
+				return nil, err
+			}
+		default:
+			qp.jj_la1[6] = qp.jj_gen
+		}
 	default:
 		qp.jj_la1[7] = qp.jj_gen
 		if _, err = qp.jj_consume_token(-1); err != nil {
@@ -159,8 +254,8 @@ func (qp *QueryParser) clause(field string) (q search.Query, err error) {
 }
 
 func (qp *QueryParser) term(field string) (q search.Query, err error) {
-	var term, boost, fuzzySlop /*, goop1, goop2*/ *Token
-	var prefix, wildcard, fuzzy, regexp /*, startInc, endInc*/ bool
+	var term, boost, fuzzySlop, goop1, goop2 *Token
+	var prefix, wildcard, fuzzy, regexp, startInc, endInc bool
 	if qp.jj_ntk == -1 {
 		qp.get_jj_ntk()
 	}
@@ -175,26 +270,50 @@ func (qp *QueryParser) term(field string) (q search.Query, err error) {
 				return nil, err
 			}
 		case STAR:
-			panic("not implemented yet")
+			if term, err = qp.jj_consume_token(STAR); err != nil {
+				return nil, err
+			}
+			wildcard = true
 		case PREFIXTERM:
-			panic("not implemented yet")
+			if term, err = qp.jj_consume_token(PREFIXTERM); err != nil {
+				return nil, err
+			}
+			prefix = true
 		case WILDTERM:
-			panic("not implemented yet")
+			if term, err = qp.jj_consume_token(WILDTERM); err != nil {
+				return nil, err
+			}
+			wildcard = true
 		case REGEXPTERM:
-			panic("not implemented yet")
+			if term, err = qp.jj_consume_token(REGEXPTERM); err != nil {
+				return nil, err
+			}
+			regexp = true
 		case NUMBER:
-			panic("not implemented yet")
+			if term, err = qp.jj_consume_token(NUMBER); err != nil {
+				return nil, err
+			}
 		case BAREOPER:
-			panic("not implemented yet")
+			if term, err = qp.jj_consume_token(BAREOPER); err != nil {
+				return nil, err
+			}
+			term.image = term.image[:1]
 		default:
-			panic("not implemented yet")
+			qp.jj_la1[8] = qp.jj_gen
+			if _, err = qp.jj_consume_token(-1); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("parse error")
 		}
 		if qp.jj_ntk == -1 {
 			qp.get_jj_ntk()
 		}
 		switch qp.jj_ntk {
 		case FUZZY_SLOP:
-			panic("not implemented yet")
+			if fuzzySlop, err = qp.jj_consume_token(FUZZY_SLOP); err != nil {
+				return nil, err
+			}
+			fuzzy = true
 		default:
 			qp.jj_la1[9] = qp.jj_gen
 		}
@@ -203,7 +322,24 @@ func (qp *QueryParser) term(field string) (q search.Query, err error) {
 		}
 		switch qp.jj_ntk {
 		case CARAT:
-			panic("not implemented yet")
+			if _, err = qp.jj_consume_token(CARAT); err != nil {
+				return nil, err
+			}
+			if boost, err = qp.jj_consume_token(NUMBER); err != nil {
+				return nil, err
+			}
+			if qp.jj_ntk == -1 {
+				qp.get_jj_ntk()
+			}
+			switch qp.jj_ntk {
+			case FUZZY_SLOP:
+				if fuzzySlop, err = qp.jj_consume_token(FUZZY_SLOP); err != nil {
+					return nil, err
+				}
+				fuzzy = true
+			default:
+				qp.jj_la1[10] = qp.jj_gen
+			}
 		default:
 			qp.jj_la1[11] = qp.jj_gen
 		}
@@ -212,26 +348,178 @@ func (qp *QueryParser) term(field string) (q search.Query, err error) {
 		}
 
 	case RANGEIN_START, RANGEEX_START:
-		panic("not implemented yet")
+		if qp.jj_ntk == -1 {
+			qp.get_jj_ntk()
+		}
+		switch qp.jj_ntk {
+		case RANGEIN_START:
+			if _, err = qp.jj_consume_token(RANGEIN_START); err != nil {
+				return nil, err
+			}
+			startInc = true
+		case RANGEEX_START:
+			if _, err = qp.jj_consume_token(RANGEEX_START); err != nil {
+				return nil, err
+			}
+		default:
+			qp.jj_la1[12] = qp.jj_gen
+			if _, err = qp.jj_consume_token(-1); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("parse error")
+		}
+		if goop1, err = qp.rangeGoop(13); err != nil {
+			return nil, err
+		}
+		if qp.jj_ntk == -1 {
+			qp.get_jj_ntk()
+		}
+		switch qp.jj_ntk {
+		case RANGE_TO:
+			if _, err = qp.jj_consume_token(RANGE_TO); err != nil {
+				return nil, err
+			}
+		default:
+			qp.jj_la1[14] = qp.jj_gen
+		}
+		if goop2, err = qp.rangeGoop(15); err != nil {
+			return nil, err
+		}
+		if qp.jj_ntk == -1 {
+			qp.get_jj_ntk()
+		}
+		switch qp.jj_ntk {
+		case RANGEIN_END:
+			if _, err = qp.jj_consume_token(RANGEIN_END); err != nil {
+				return nil, err
+			}
+			endInc = true
+		case RANGEEX_END:
+			if _, err = qp.jj_consume_token(RANGEEX_END); err != nil {
+				return nil, err
+			}
+		default:
+			qp.jj_la1[16] = qp.jj_gen
+			if _, err = qp.jj_consume_token(-1); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("parse error")
+		}
+		if qp.jj_ntk == -1 {
+			qp.get_jj_ntk()
+		}
+		switch qp.jj_ntk {
+		case CARAT:
+			if _, err = qp.jj_consume_token(CARAT); err != nil {
+				return nil, err
+			}
+			if boost, err = qp.jj_consume_token(NUMBER); err != nil {
+				return nil, err
+			}
+		default:
+			qp.jj_la1[17] = qp.jj_gen
+		}
+		var part1, part2 []byte
+		if part1, err = qp.rangePart(goop1); err != nil {
+			return nil, err
+		}
+		if part2, err = qp.rangePart(goop2); err != nil {
+			return nil, err
+		}
+		if q, err = qp.getRangeQuery(field, part1, part2, startInc, endInc); err != nil {
+			return nil, err
+		}
 	case QUOTED:
-		panic("not implemented yet")
+		if term, err = qp.jj_consume_token(QUOTED); err != nil {
+			return nil, err
+		}
+		if qp.jj_ntk == -1 {
+			qp.get_jj_ntk()
+		}
+		switch qp.jj_ntk {
+		case FUZZY_SLOP:
+			if fuzzySlop, err = qp.jj_consume_token(FUZZY_SLOP); err != nil {
+				return nil, err
+			}
+		default:
+			qp.jj_la1[18] = qp.jj_gen
+		}
+		if qp.jj_ntk == -1 {
+			qp.get_jj_ntk()
+		}
+		switch qp.jj_ntk {
+		case CARAT:
+			if _, err = qp.jj_consume_token(CARAT); err != nil {
+				return nil, err
+			}
+			if boost, err = qp.jj_consume_token(NUMBER); err != nil {
+				return nil, err
+			}
+		default:
+			qp.jj_la1[19] = qp.jj_gen
+		}
+		if q, err = qp.handleQuotedTerm(field, term, fuzzySlop); err != nil {
+			return nil, err
+		}
 	default:
-		panic("not implemented yet")
+		qp.jj_la1[20] = qp.jj_gen
+		if _, err = qp.jj_consume_token(-1); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("parse error")
 	}
 	return qp.handleBoost(q, boost), nil
 }
 
+// Consumes one end point of a range, either a RANGE_GOOP or a
+// RANGE_QUOTED token.
+func (qp *QueryParser) rangeGoop(la1 int) (goop *Token, err error) {
+	if qp.jj_ntk == -1 {
+		qp.get_jj_ntk()
+	}
+	switch qp.jj_ntk {
+	case RANGE_GOOP:
+		return qp.jj_consume_token(RANGE_GOOP)
+	case RANGE_QUOTED:
+		return qp.jj_consume_token(RANGE_QUOTED)
+	default:
+		qp.jj_la1[la1] = qp.jj_gen
+		if _, err = qp.jj_consume_token(-1); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("parse error")
+	}
+}
+
+// Returns the unescaped text of a range end point, or nil if it is
+// open ("*").
+func (qp *QueryParser) rangePart(goop *Token) ([]byte, error) {
+	image := goop.image
+	if goop.kind == RANGE_QUOTED {
+		image = image[1 : len(image)-1]
+	} else if image == "*" {
+		return nil, nil
+	}
+	part, err := qp.discardEscapeChar(image)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(part), nil
+}
+
 // L473
 func (qp *QueryParser) jj_2_1(xla int) (ok bool) {
 	qp.jj_la = xla
 	qp.jj_lastpos = qp.token
 	qp.jj_scanpos = qp.token
 	defer func() {
-		// Disable following recover() to deal with dev panic
-		if err := recover(); err == lookAheadSuccess {
+		err := recover()
+		qp.jj_save(0, xla)
+		if err == lookAheadSuccess {
 			ok = true
+		} else if err != nil {
+			panic(err) // only the look ahead success is expected here
 		}
-		qp.jj_save(0, xla)
 	}()
 	return !qp.jj_3_1()
 }
@@ -285,12 +573,29 @@ func (qp *QueryParser) jj_consume_token(kind int) (*Token, error) {
 		qp.jj_gen++
 		if qp.jj_gc++; qp.jj_gc > 100 {
 			qp.jj_gc = 0
-			panic("not implemented yet")
+			for _, c := range qp.jj_2_rtns {
+				for ; c != nil; c = c.next {
+					if c.gen < qp.jj_gen {
+						c.first = nil
+					}
+				}
+			}
 		}
 		return qp.token, nil
 	}
 	qp.token = oldToken
-	panic("not implemented yet")
+	return nil, qp.generateParseException()
+}
+
+// Reports the token the parser could not consume.
+func (qp *QueryParser) generateParseException() error {
+	tok := qp.token.next
+	image := fmt.Sprintf("%q", tok.image)
+	if tok.kind == EOF {
+		image = "<EOF>"
+	}
+	return errors.New(fmt.Sprintf("Encountered %v at line %v, column %v.",
+		image, tok.beginLine, tok.beginColumn))
 }
 
 type LookAheadSuccess bool
@@ -307,14 +612,11 @@ func (qp *QueryParser) jj_scan_token(kind int) bool {
 			qp.jj_lastpos = nextToken
 		} else {
 			qp.jj_scanpos = qp.jj_scanpos.next
-			qp.jj_lastpos = qp.jj_scanpos.next
+			qp.jj_lastpos = qp.jj_scanpos
 		}
 	} else {
 		qp.jj_scanpos = qp.jj_scanpos.next
 	}
-	if qp.jj_rescan {
-		panic("niy")
-	}
 	if qp.jj_scanpos.kind != kind {
 		return true
 	}
@@ -341,8 +643,8 @@ func (qp *QueryParser) jj_save(index, xla int) {
 	p := qp.jj_2_rtns[index]
 	for p.gen > qp.jj_gen {
 		if p.next == nil {
-			p = new(JJCalls)
-			p.next = p
+			p.next = new(JJCalls)
+			p = p.next
 			break
 		}
 		p = p.next
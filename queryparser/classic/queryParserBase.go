@@ -1,11 +1,15 @@
 package classic
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/gzg1984/golucene/core/analysis"
+	"github.com/gzg1984/golucene/core/index"
 	"github.com/gzg1984/golucene/core/search"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 const (
@@ -36,18 +40,35 @@ type QueryParserBase struct {
 	phraseSlop int
 
 	autoGeneratePhraseQueries bool
+
+	lowercaseExpandedTerms bool
+	allowLeadingWildcard   bool
+
+	fuzzyMinSim       float32
+	fuzzyPrefixLength int
 }
 
 func newQueryParserBase(spi QueryParserBaseSPI) *QueryParserBase {
 	return &QueryParserBase{
-		QueryBuilder: newQueryBuilder(),
-		spi:          spi,
-		operator:     OP_OR,
+		QueryBuilder:           newQueryBuilder(),
+		spi:                    spi,
+		operator:               OP_OR,
+		lowercaseExpandedTerms: true,
+		fuzzyMinSim:            search.FUZZY_DEFAULT_MAX_EDITS,
+		fuzzyPrefixLength:      search.FUZZY_DEFAULT_PREFIX_LENGTH,
 	}
 }
 
 // L116
 func (qp *QueryParserBase) Parse(query string) (res search.Query, err error) {
+	defer func() {
+		// the token manager reports lexical errors by panicking with a
+		// TokenManagerError; whatever else goes wrong while parsing user
+		// input is reported as a failed parse as well
+		if r := recover(); r != nil {
+			res, err = nil, errors.New(fmt.Sprintf("Cannot parse '%v': %v", query, r))
+		}
+	}()
 	qp.spi.ReInit(newFastCharStream(strings.NewReader(query)))
 	if res, err = qp.spi.TopLevelQuery(qp.field); err != nil {
 		return nil, errors.New(fmt.Sprintf("Cannot parse '%v': %v", query, err))
@@ -58,6 +79,92 @@ func (qp *QueryParserBase) Parse(query string) (res search.Query, err error) {
 	return qp.newBooleanQuery(false), nil
 }
 
+/*
+Sets the boolean operator of the QueryParser. In default mode
+(OP_OR) terms without any modifiers are considered optional: for
+example capital of Hungary is equal to capital OR of OR Hungary.
+In OP_AND mode terms are considered to be in conjunction: the
+above mentioned query is parsed as capital AND of AND Hungary.
+*/
+func (qp *QueryParserBase) SetDefaultOperator(op Operator) {
+	qp.operator = op
+}
+
+// Gets implicit operator setting, which will be either OP_AND or OP_OR.
+func (qp *QueryParserBase) DefaultOperator() Operator {
+	return qp.operator
+}
+
+// L214
+
+// Get the minimal similarity for fuzzy queries.
+func (qp *QueryParserBase) FuzzyMinSim() float32 {
+	return qp.fuzzyMinSim
+}
+
+/*
+Set the minimum similarity for fuzzy queries. Default is 2f: values
+>= 1 are taken as a number of edits, values < 1 as the deprecated
+similarity fraction.
+*/
+func (qp *QueryParserBase) SetFuzzyMinSim(fuzzyMinSim float32) {
+	qp.fuzzyMinSim = fuzzyMinSim
+}
+
+// Get the prefix length for fuzzy queries.
+func (qp *QueryParserBase) FuzzyPrefixLength() int {
+	return qp.fuzzyPrefixLength
+}
+
+// Set the prefix length for fuzzy queries. Default is 0.
+func (qp *QueryParserBase) SetFuzzyPrefixLength(fuzzyPrefixLength int) {
+	qp.fuzzyPrefixLength = fuzzyPrefixLength
+}
+
+/*
+Sets the default slop for phrases. If zero, then exact phrase matches
+are required. Default value is zero.
+*/
+func (qp *QueryParserBase) SetPhraseSlop(phraseSlop int) {
+	qp.phraseSlop = phraseSlop
+}
+
+// Gets the default slop for phrases.
+func (qp *QueryParserBase) PhraseSlop() int {
+	return qp.phraseSlop
+}
+
+// L254
+
+/*
+Set to true to allow leading wildcard characters.
+
+When set, * or ? are allowed as the first character of a
+PrefixQuery and WildcardQuery. Note that this can produce very slow
+queries on big indexes.
+
+Default: false.
+*/
+func (qp *QueryParserBase) SetAllowLeadingWildcard(allowLeadingWildcard bool) {
+	qp.allowLeadingWildcard = allowLeadingWildcard
+}
+
+func (qp *QueryParserBase) AllowLeadingWildcard() bool {
+	return qp.allowLeadingWildcard
+}
+
+/*
+Whether terms of wildcard, prefix, fuzzy and range queries are to be
+automatically lower-cased or not. Default is true.
+*/
+func (qp *QueryParserBase) SetLowercaseExpandedTerms(lowercaseExpandedTerms bool) {
+	qp.lowercaseExpandedTerms = lowercaseExpandedTerms
+}
+
+func (qp *QueryParserBase) LowercaseExpandedTerms() bool {
+	return qp.lowercaseExpandedTerms
+}
+
 // L408
 func (qp *QueryParserBase) addClause(clauses []*search.BooleanClause,
 	conj, mods int, q search.Query) []*search.BooleanClause {
@@ -66,11 +173,20 @@ func (qp *QueryParserBase) addClause(clauses []*search.BooleanClause,
 	// If this term is introduced by AND, make the preceding term required,
 	// unless it's already prohibited
 	if len(clauses) > 0 && conj == CONJ_AND {
-		panic("not implemented yet")
+		if c := clauses[len(clauses)-1]; !c.IsProhibited() {
+			c.SetOccur(search.MUST)
+		}
 	}
 
 	if len(clauses) > 0 && qp.operator == OP_AND && conj == CONJ_OR {
-		panic("not implemented yet")
+		// If this term is introduced by OR, make the preceding term
+		// optional, unless it's prohibited (that means we leave -a OR b
+		// but +a OR b-->a OR b). Notice if the input is a OR b, first
+		// term is parsed as required; without this modification a OR b
+		// would parsed as +a OR b
+		if c := clauses[len(clauses)-1]; !c.IsProhibited() {
+			c.SetOccur(search.SHOULD)
+		}
 	}
 
 	// We might have been passed an empty query; the term might have been
@@ -88,24 +204,46 @@ func (qp *QueryParserBase) addClause(clauses []*search.BooleanClause,
 			required = true
 		}
 	} else {
-		panic("not implemented yet")
+		// We set PROHIBITED if we're introduced by NOT or -; We set
+		// REQUIRED if not PROHIBITED and not introduced by OR
+		prohibited = (mods == MOD_NOT)
+		required = (!prohibited && conj != CONJ_OR)
 	}
 	if required {
-		panic("not implemented yet")
+		return append(clauses, qp.newBooleanClause(q, search.MUST))
 	} else if !prohibited {
 		return append(clauses, qp.newBooleanClause(q, search.SHOULD))
 	} else {
-		panic("not implemented yet")
+		return append(clauses, qp.newBooleanClause(q, search.MUST_NOT))
 	}
 }
 
 // L461
-func (qp *QueryParserBase) fieldQuery(field, queryText string, quoted bool) search.Query {
+func (qp *QueryParserBase) fieldQuery(field, queryText string, quoted bool) (search.Query, error) {
 	return qp.newFieldQuery(qp.analyzer, field, queryText, quoted)
 }
 
+/*
+Base implementation delegates to fieldQuery(field, queryText, true).
+This method may be overridden, for example, to return a SpanNearQuery
+instead of a PhraseQuery.
+*/
+func (qp *QueryParserBase) fieldQueryWithSlop(field, queryText string, slop int) (search.Query, error) {
+	query, err := qp.fieldQuery(field, queryText, true)
+	if err != nil {
+		return nil, err
+	}
+	switch q := query.(type) {
+	case *search.PhraseQuery:
+		q.SetSlop(slop)
+	case *search.MultiPhraseQuery:
+		q.SetSlop(slop)
+	}
+	return query, nil
+}
+
 func (qp *QueryParserBase) newFieldQuery(analyzer analysis.Analyzer,
-	field, queryText string, quoted bool) search.Query {
+	field, queryText string, quoted bool) (search.Query, error) {
 
 	var occur search.Occur
 	if qp.operator == OP_AND {
@@ -123,6 +261,57 @@ func (qp *QueryParserBase) newBooleanClause(q search.Query, occur search.Occur)
 	return search.NewBooleanClause(q, occur)
 }
 
+// L604
+
+/*
+Builds a new PrefixQuery instance
+*/
+func (qp *QueryParserBase) newPrefixQuery(prefix *index.Term) search.Query {
+	return search.NewPrefixQuery(prefix)
+}
+
+/*
+Builds a new MatchAllDocsQuery instance
+*/
+func (qp *QueryParserBase) newMatchAllDocsQuery() search.Query {
+	return search.NewMatchAllDocsQuery()
+}
+
+/*
+Builds a new WildcardQuery instance
+*/
+func (qp *QueryParserBase) newWildcardQuery(t *index.Term) search.Query {
+	return search.NewWildcardQuery(t)
+}
+
+/*
+Builds a new RegexpQuery instance
+*/
+func (qp *QueryParserBase) newRegexpQuery(regexp *index.Term) search.Query {
+	return search.NewRegexpQuery(regexp)
+}
+
+/*
+Builds a new FuzzyQuery instance
+*/
+func (qp *QueryParserBase) newFuzzyQuery(term *index.Term,
+	minimumSimilarity float32, prefixLength int) search.Query {
+
+	// FuzzyQuery doesn't yet allow constant score rewrite
+	numEdits := search.FloatToEdits(minimumSimilarity, utf8.RuneCount(term.Bytes))
+	return search.NewFuzzyQueryWith(term, numEdits, prefixLength,
+		search.FUZZY_DEFAULT_MAX_EXPANSIONS, search.FUZZY_DEFAULT_TRANSPOSITIONS)
+}
+
+/*
+Builds a new TermRangeQuery instance
+*/
+func (qp *QueryParserBase) newRangeQuery(field string, part1, part2 []byte,
+	startInclusive, endInclusive bool) search.Query {
+
+	return search.NewTermRangeQuery(field, part1, part2, startInclusive, endInclusive)
+}
+
 // L676
 /*
 Factory method for generating query, given a set of clauses.
@@ -141,11 +330,115 @@ func (qp *QueryParserBase) booleanQueryDisableCoord(clauses []*search.BooleanCla
 	}
 	query := qp.newBooleanQuery(disableCoord)
 	for _, clause := range clauses {
-		query.AddClause(clause)
+		if err := query.AddClause(clause); err != nil {
+			return nil, err
+		}
 	}
 	return query, nil
 }
 
+// L716
+
+/*
+Factory method for generating a query. Called when parser parses an
+input term token that contains one or more wildcard characters (?
+and *), but is not a prefix term token (one that has just a single *
+character at the end).
+
+Depending on settings, prefix term may be lower-cased automatically.
+It will not go through the default Analyzer, however, since normal
+Analyzers are unlikely to work properly with wildcard templates.
+
+Can be overridden by extending classes, to provide custom handling
+for wildcard queries, which may be necessary due to missing analyzer
+calls.
+*/
+func (qp *QueryParserBase) getWildcardQuery(field, termStr string) (search.Query, error) {
+	if field == "*" && termStr == "*" {
+		return qp.newMatchAllDocsQuery(), nil
+	}
+	if !qp.allowLeadingWildcard && (strings.HasPrefix(termStr, "*") || strings.HasPrefix(termStr, "?")) {
+		return nil, errors.New("'*' or '?' not allowed as first character in WildcardQuery")
+	}
+	if qp.lowercaseExpandedTerms {
+		termStr = strings.ToLower(termStr)
+	}
+	return qp.newWildcardQuery(index.NewTerm(field, termStr)), nil
+}
+
+/*
+Factory method for generating a query. Called when parser parses an
+input term token that contains a regular expression query.
+
+Depending on settings, pattern term may be lower-cased automatically.
+It will not go through the default Analyzer, however, since normal
+Analyzers are unlikely to work properly with regular expression
+templates.
+*/
+func (qp *QueryParserBase) getRegexpQuery(field, termStr string) (search.Query, error) {
+	if qp.lowercaseExpandedTerms {
+		termStr = strings.ToLower(termStr)
+	}
+	return qp.newRegexpQuery(index.NewTerm(field, termStr)), nil
+}
+
+/*
+Factory method for generating a query (similar to getWildcardQuery).
+Called when parser parses an input term token that uses prefix
+notation; that is, contains a single '*' wildcard character as its
+last character. Since this is a special case of generic wildcard
+term, and such a query can be optimized easily, this usually results
+in a different query object.
+
+Depending on settings, a prefix term may be lower-cased
+automatically. It will not go through the default Analyzer, however,
+since normal Analyzers are unlikely to work properly with wildcard
+templates.
+*/
+func (qp *QueryParserBase) getPrefixQuery(field, termStr string) (search.Query, error) {
+	if !qp.allowLeadingWildcard && strings.HasPrefix(termStr, "*") {
+		return nil, errors.New("'*' not allowed as first character in PrefixQuery")
+	}
+	if qp.lowercaseExpandedTerms {
+		termStr = strings.ToLower(termStr)
+	}
+	return qp.newPrefixQuery(index.NewTerm(field, termStr)), nil
+}
+
+/*
+Factory method for generating a query for a range of terms. Called
+when parser parses a range like [a TO b] or {a TO b}. A nil part
+means the range is open on that side.
+
+Depending on settings, the end points may be lower-cased
+automatically. They will not go through the default Analyzer.
+*/
+func (qp *QueryParserBase) getRangeQuery(field string, part1, part2 []byte,
+	startInclusive, endInclusive bool) (search.Query, error) {
+
+	if qp.lowercaseExpandedTerms {
+		if part1 != nil {
+			part1 = bytes.ToLower(part1)
+		}
+		if part2 != nil {
+			part2 = bytes.ToLower(part2)
+		}
+	}
+	return qp.newRangeQuery(field, part1, part2, startInclusive, endInclusive), nil
+}
+
+/*
+Factory method for generating a query (similar to getWildcardQuery).
+Called when parser parses an input term token that has the fuzzy
+suffix (~) appended.
+*/
+func (qp *QueryParserBase) getFuzzyQuery(field, termStr string, minSimilarity float32) (search.Query, error) {
+	if qp.lowercaseExpandedTerms {
+		termStr = strings.ToLower(termStr)
+	}
+	return qp.newFuzzyQuery(index.NewTerm(field, termStr), minSimilarity, qp.fuzzyPrefixLength), nil
+}
+
 // L827
 func (qp *QueryParserBase) handleBareTokenQuery(qField string,
 	term, fuzzySlop *Token, prefix, wildcard, fuzzy, regexp bool) (q search.Query, err error) {
@@ -155,22 +448,64 @@ func (qp *QueryParserBase) handleBareTokenQuery(qField string,
 		return nil, err
 	}
 	if wildcard {
-		panic("not implemented yet")
+		return qp.getWildcardQuery(qField, term.image)
 	} else if prefix {
-		panic("not implemented yet")
+		var prefixImage string
+		if prefixImage, err = qp.discardEscapeChar(term.image[:len(term.image)-1]); err != nil {
+			return nil, err
+		}
+		return qp.getPrefixQuery(qField, prefixImage)
 	} else if regexp {
-		panic("not implemented yet")
+		return qp.getRegexpQuery(qField, term.image[1:len(term.image)-1])
 	} else if fuzzy {
-		panic("not implemented yet")
+		return qp.handleBareFuzzy(qField, fuzzySlop, termImage)
 	} else {
-		return qp.fieldQuery(qField, termImage, false), nil
+		return qp.fieldQuery(qField, termImage, false)
+	}
+}
+
+func (qp *QueryParserBase) handleBareFuzzy(qField string,
+	fuzzySlop *Token, termImage string) (search.Query, error) {
+
+	fms := qp.fuzzyMinSim
+	if v, err := strconv.ParseFloat(fuzzySlop.image[1:], 32); err == nil {
+		fms = float32(v)
+	}
+	if fms < 0 {
+		return nil, errors.New("Minimum similarity for a FuzzyQuery has to be between 0.0f and 1.0f !")
+	} else if fms >= 1 && fms != float32(int(fms)) {
+		return nil, errors.New("Fractional edit distances are not allowed!")
+	}
+	return qp.getFuzzyQuery(qField, termImage, fms)
+}
+
+func (qp *QueryParserBase) handleQuotedTerm(qField string,
+	term, fuzzySlop *Token) (search.Query, error) {
+
+	s := qp.phraseSlop // default
+	if fuzzySlop != nil {
+		if v, err := strconv.ParseFloat(fuzzySlop.image[1:], 32); err == nil {
+			s = int(v)
+		}
+	}
+	termImage, err := qp.discardEscapeChar(term.image[1 : len(term.image)-1])
+	if err != nil {
+		return nil, err
 	}
+	return qp.fieldQueryWithSlop(qField, termImage, s)
 }
 
 // L876
 func (qp *QueryParserBase) handleBoost(q search.Query, boost *Token) search.Query {
 	if boost != nil {
-		panic("not implemented yet")
+		f := float64(1)
+		if v, err := strconv.ParseFloat(boost.image, 32); err == nil {
+			f = v
+		}
+		// avoid boosting null queries, such as those caused by stop words
+		if q != nil {
+			q.SetBoost(float32(f))
+		}
 	}
 	return q
 }
@@ -185,13 +520,31 @@ func (qp *QueryParserBase) discardEscapeChar(input string) (string, error) {
 
 	codePointMultiplier := 0
 
-	// codePoint := 0
+	codePoint := 0
 
 	for _, curChar := range input {
 		if codePointMultiplier > 0 {
-			panic("not implemented yet")
+			n, err := hexToInt(curChar)
+			if err != nil {
+				return "", err
+			}
+			codePoint += n * codePointMultiplier
+			codePointMultiplier >>= 4
+			if codePointMultiplier == 0 {
+				output[length] = rune(codePoint)
+				length++
+				codePoint = 0
+			}
 		} else if lastCharWasEscapeChar {
-			panic("not implemented yet")
+			if curChar == 'u' {
+				// prepare for next 4 chars to be the unicode escape sequence
+				codePointMultiplier = 16 * 16 * 16
+			} else {
+				// this character was escaped
+				output[length] = curChar
+				length++
+			}
+			lastCharWasEscapeChar = false
 		} else {
 			if curChar == '\\' {
 				lastCharWasEscapeChar = true
@@ -208,5 +561,19 @@ func (qp *QueryParserBase) discardEscapeChar(input string) (string, error) {
 	if lastCharWasEscapeChar {
 		return "", errors.New("Term can not end with escape character.")
 	}
-	return string(output), nil
+	return string(output[:length]), nil
+}
+
+// Returns the numeric value of the hexadecimal character
+func hexToInt(c rune) (int, error) {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0'), nil
+	case 'a' <= c && c <= 'f':
+		return int(c - 'a' + 10), nil
+	case 'A' <= c && c <= 'F':
+		return int(c - 'A' + 10), nil
+	default:
+		return 0, fmt.Errorf("Non-hex character in Unicode escape sequence: %c", c)
+	}
 }
@@ -0,0 +1,356 @@
+package classic
+
+import (
+	"fmt"
+	std "github.com/gzg1984/golucene/analysis/standard"
+	"github.com/gzg1984/golucene/core/search"
+	"github.com/gzg1984/golucene/core/util"
+	"strings"
+	"testing"
+)
+
+func TestParseMultiTermQueries(t *testing.T) {
+	qp := NewQueryParser(util.VERSION_49, "content", std.NewStandardAnalyzer())
+	for _, c := range []struct {
+		text     string
+		expected string
+	}{
+		{"bat", "bat"},
+		{"fru*", "fru*"},
+		{"b?t", "b?t"},
+		{"gu*no", "gu*no"},
+		{"GU*No", "gu*no"},
+		{"b\\*t*", "b*t*"},
+		{"/s[a-z]nar/", "/s[a-z]nar/"},
+		{"/a\\/b/", "/a\\/b/"},
+	} {
+		q, err := qp.Parse(c.text)
+		if err != nil {
+			t.Errorf("%v: %v", c.text, err)
+			continue
+		}
+		if s := q.ToString("content"); s != c.expected {
+			t.Errorf("%v: expected '%v', but '%v'", c.text, c.expected, s)
+		}
+	}
+
+	q, err := qp.Parse("fru*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := q.(*search.PrefixQuery); !ok {
+		t.Errorf("fru*: expected PrefixQuery, but %T", q)
+	}
+	if q, err = qp.Parse("b?t"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := q.(*search.WildcardQuery); !ok {
+		t.Errorf("b?t: expected WildcardQuery, but %T", q)
+	}
+	if q, err = qp.Parse("/s[a-z]nar/"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := q.(*search.RegexpQuery); !ok {
+		t.Errorf("/s[a-z]nar/: expected RegexpQuery, but %T", q)
+	}
+}
+
+func TestParseLeadingWildcard(t *testing.T) {
+	qp := NewQueryParser(util.VERSION_49, "content", std.NewStandardAnalyzer())
+	if _, err := qp.Parse("*uano"); err == nil {
+		t.Error("leading wildcard should be rejected by default")
+	}
+	qp.SetAllowLeadingWildcard(true)
+	q, err := qp.Parse("*uano")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := q.ToString("content"); s != "*uano" {
+		t.Errorf("expected '*uano', but '%v'", s)
+	}
+}
+
+func TestParseFuzzyQueries(t *testing.T) {
+	qp := NewQueryParser(util.VERSION_49, "content", std.NewStandardAnalyzer())
+	for _, c := range []struct {
+		text     string
+		expected string
+	}{
+		{"guana~", "guana~2"},
+		{"bat~1", "bat~1"},
+		{"Guana~1", "guana~1"},
+		{"guano~0.7", "guano~1"},
+		{"guano~0.5", "guano~2"},
+		{"bat~0", "bat~0"},
+	} {
+		q, err := qp.Parse(c.text)
+		if err != nil {
+			t.Errorf("%v: %v", c.text, err)
+			continue
+		}
+		if _, ok := q.(*search.FuzzyQuery); !ok {
+			t.Errorf("%v: expected FuzzyQuery, but %T", c.text, q)
+			continue
+		}
+		if s := q.ToString("content"); s != c.expected {
+			t.Errorf("%v: expected '%v', but '%v'", c.text, c.expected, s)
+		}
+	}
+
+	if _, err := qp.Parse("bat~1.5"); err == nil {
+		t.Error("fractional edit distances should be rejected")
+	}
+}
+
+func TestParseRangeQueries(t *testing.T) {
+	qp := NewQueryParser(util.VERSION_49, "content", std.NewStandardAnalyzer())
+	for _, c := range []struct {
+		text     string
+		expected string
+	}{
+		{"[bat TO fruit]", "[bat TO fruit]"},
+		{"{bat TO fruit}", "{bat TO fruit}"},
+		{"[bat TO fruit}", "[bat TO fruit}"},
+		{"{Bat TO FRUIT]", "{bat TO fruit]"},
+		{"[bat fruit]", "[bat TO fruit]"},
+		{"[* TO guano]", "[* TO guano]"},
+		{"{sonar TO *]", "{sonar TO *]"},
+		{"[\"b a t\" TO \"TO\"]", "[b a t TO to]"},
+		{"[a TO TOP]", "[a TO top]"},
+		{"[\\* TO z]", "[\\* TO z]"},
+		{"[bat TO fruit]^2", "[bat TO fruit]^2"},
+		{"price:{a TO b]^0.5", "price:{a TO b]^0.5"},
+	} {
+		q, err := qp.Parse(c.text)
+		if err != nil {
+			t.Errorf("%v: %v", c.text, err)
+			continue
+		}
+		if _, ok := q.(*search.TermRangeQuery); !ok {
+			t.Errorf("%v: expected TermRangeQuery, but %T", c.text, q)
+		}
+		if s := q.ToString("content"); s != c.expected {
+			t.Errorf("%v: expected '%v', but '%v'", c.text, c.expected, s)
+		}
+	}
+
+	q, err := qp.Parse("[a TO c]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rq := q.(*search.TermRangeQuery)
+	if string(rq.LowerTerm()) != "a" || string(rq.UpperTerm()) != "c" {
+		t.Errorf("unexpected bounds: %v", rq)
+	}
+	if !rq.IncludesLower() || !rq.IncludesUpper() {
+		t.Errorf("expected inclusive bounds: %v", rq)
+	}
+	if q, err = qp.Parse("{* TO c}"); err != nil {
+		t.Fatal(err)
+	}
+	if rq = q.(*search.TermRangeQuery); rq.LowerTerm() != nil || rq.IncludesLower() {
+		t.Errorf("expected open exclusive lower bound: %v", rq)
+	}
+}
+
+func TestParseTooManyClauses(t *testing.T) {
+	qp := NewQueryParser(util.VERSION_49, "content", std.NewStandardAnalyzer())
+	defer search.SetMaxClauseCount(search.MaxClauseCount())
+	search.SetMaxClauseCount(2)
+	if _, err := qp.Parse("bat fruit"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := qp.Parse("bat fruit guano"); err == nil {
+		t.Error("expected too many clauses to be rejected")
+	}
+	// a single field query analyzed into many terms
+	if _, err := qp.Parse("bat-fruit-guano"); err == nil {
+		t.Error("expected too many clauses to be rejected")
+	}
+}
+
+func TestParseMatchAllAndUnicodeEscapes(t *testing.T) {
+	qp := NewQueryParser(util.VERSION_49, "content", std.NewStandardAnalyzer())
+	for _, c := range []struct {
+		text     string
+		expected string
+	}{
+		{"*:*", "*:*"},
+		{"title:bat", "title:bat"},
+		{"\\u0062at", "bat"},
+		{"fr\\u0075it*", "fruit*"},
+	} {
+		q, err := qp.Parse(c.text)
+		if err != nil {
+			t.Fatalf("%v: %v", c.text, err)
+		}
+		if s := q.ToString("content"); s != c.expected {
+			t.Errorf("%v: expected '%v', but '%v'", c.text, c.expected, s)
+		}
+	}
+	for _, text := range []string{"fr\\u00*", "\\u00g2at*"} {
+		if _, err := qp.Parse(text); err == nil {
+			t.Errorf("%v: expected invalid unicode escape to be rejected", text)
+		}
+	}
+}
+
+func TestParseBoost(t *testing.T) {
+	qp := NewQueryParser(util.VERSION_49, "content", std.NewStandardAnalyzer())
+	for _, c := range []struct {
+		text     string
+		expected string
+	}{
+		{"bat^2", "bat^2"},
+		{"title:bat^0.5", "title:bat^0.5"},
+		{"fru*^3", "fru*^3"},
+		{"price:[a TO b]^2", "price:[a TO b]^2"},
+	} {
+		q, err := qp.Parse(c.text)
+		if err != nil {
+			t.Fatalf("%v: %v", c.text, err)
+		}
+		if s := q.ToString("content"); s != c.expected {
+			t.Errorf("%v: expected '%v', but '%v'", c.text, c.expected, s)
+		}
+	}
+	for _, text := range []string{"bat^", "price:[a TO b]^", "bat^x"} {
+		if _, err := qp.Parse(text); err == nil {
+			t.Errorf("%v: expected a missing boost to be rejected", text)
+		}
+	}
+}
+
+func TestParsePhraseQueries(t *testing.T) {
+	qp := NewQueryParser(util.VERSION_49, "content", std.NewStandardAnalyzer())
+	for _, c := range []struct {
+		text     string
+		expected string
+	}{
+		{`"bat fruit"`, `"bat fruit"`},
+		{`"Bat Fruit"~2`, `"bat fruit"~2`},
+		{`"bat fruit"~2.7`, `"bat fruit"~2`},
+		{`title:"bat fruit"`, `title:"bat fruit"`},
+		{`"bat fruit"^3`, `"bat fruit"^3`},
+		{`"bat fruit"~1^3`, `"bat fruit"~1^3`},
+		{`"bat \"fruit\""`, `"bat fruit"`},
+		{`"bat the fruit"`, `"bat ? fruit"`},
+		{`"été fruit"`, `"été fruit"`},
+	} {
+		q, err := qp.Parse(c.text)
+		if err != nil {
+			t.Errorf("%v: %v", c.text, err)
+			continue
+		}
+		if _, ok := q.(*search.PhraseQuery); !ok {
+			t.Errorf("%v: expected PhraseQuery, but %T", c.text, q)
+			continue
+		}
+		if s := q.ToString("content"); s != c.expected {
+			t.Errorf("%v: expected '%v', but '%v'", c.text, c.expected, s)
+		}
+	}
+
+	q, err := qp.Parse(`"bat"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := q.(*search.TermQuery); !ok {
+		t.Errorf(`"bat": expected TermQuery, but %T`, q)
+	}
+
+	qp.SetPhraseSlop(5)
+	if q, err = qp.Parse(`"bat fruit"`); err != nil {
+		t.Fatal(err)
+	}
+	if s := q.ToString("content"); s != `"bat fruit"~5` {
+		t.Errorf(`expected '"bat fruit"~5', but '%v'`, s)
+	}
+
+	if _, err = qp.Parse(`"bat fruit`); err == nil {
+		t.Error("expected an unterminated phrase to be rejected")
+	}
+}
+
+func TestParseBooleanQueries(t *testing.T) {
+	qp := NewQueryParser(util.VERSION_49, "content", std.NewStandardAnalyzer())
+	for _, c := range []struct {
+		text     string
+		expected string
+	}{
+		{"+bat +fruit", "+bat +fruit"},
+		{"bat -fruit", "bat -fruit"},
+		{"-bat", "-bat"},
+		{"bat !fruit", "bat -fruit"},
+		{"bat NOT fruit", "bat -fruit"},
+		{"bat AND fruit", "+bat +fruit"},
+		{"bat && fruit", "+bat +fruit"},
+		{"bat OR fruit", "bat fruit"},
+		{"bat || fruit", "bat fruit"},
+		{"-bat AND fruit", "-bat +fruit"},
+		{"(bat fruit)", "bat fruit"},
+		{"+(bat fruit) -guano", "+(bat fruit) -guano"},
+		{"title:(bat fruit)^2", "(title:bat title:fruit)^2"},
+		{"(bat)", "bat"},
+		{`+"bat fruit" guano`, `+"bat fruit" guano`},
+		{"bat + fruit", "bat fruit"},
+		{"bat-fruit", "bat fruit"},
+		{"ANDY", "andy"},
+	} {
+		q, err := qp.Parse(c.text)
+		if err != nil {
+			t.Errorf("%v: %v", c.text, err)
+			continue
+		}
+		if s := q.ToString("content"); s != c.expected {
+			t.Errorf("%v: expected '%v', but '%v'", c.text, c.expected, s)
+		}
+	}
+
+	qp.SetDefaultOperator(OP_AND)
+	for _, c := range []struct {
+		text     string
+		expected string
+	}{
+		{"bat fruit", "+bat +fruit"},
+		{"bat OR fruit", "bat fruit"},
+		{"bat -fruit", "+bat -fruit"},
+	} {
+		q, err := qp.Parse(c.text)
+		if err != nil {
+			t.Errorf("%v: %v", c.text, err)
+			continue
+		}
+		if s := q.ToString("content"); s != c.expected {
+			t.Errorf("%v: expected '%v', but '%v'", c.text, c.expected, s)
+		}
+	}
+
+	for _, text := range []string{"(bat fruit", "bat)", "bat AND", "()"} {
+		if _, err := qp.Parse(text); err == nil {
+			t.Errorf("%v: expected a parse error", text)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	qp := NewQueryParser(util.VERSION_49, "content", std.NewStandardAnalyzer())
+	for _, text := range []string{"bat]", "}", `"bat`, ":bat", ")", "[a TO", "~2", "bat^2^3", "AND bat"} {
+		if q, err := qp.Parse(text); err == nil {
+			t.Errorf("%v: expected a parse error, but %v", text, q)
+		}
+	}
+
+	// long queries outlive the parser's look ahead bookkeeping
+	terms := make([]string, 150)
+	for i := range terms {
+		terms[i] = fmt.Sprintf("t%v", i)
+	}
+	q, err := qp.Parse(strings.Join(terms, " "))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(q.(*search.BooleanQuery).Clauses()); n != len(terms) {
+		t.Errorf("expected %v clauses, but %v", len(terms), n)
+	}
+}
@@ -5,6 +5,12 @@ import (
 )
 
 var jjbitVec0 = []int64{1, 0, 0, 0}
+var jjbitVec1 = []uint64{
+	0xfffffffffffffffe, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff,
+}
+var jjbitVec3 = []uint64{
+	0x0, 0x0, 0xffffffffffffffff, 0xffffffffffffffff,
+}
 var jjbitVec4 = []uint64{
 	0xfffefffffffffffe, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff,
 }
@@ -16,7 +22,7 @@ var jjnextStates = []int{
 }
 
 var jjstrLiteralImages = map[int]string{
-	0: "", 11: "\u0053", 12: "\055",
+	0: "", 11: "\053", 12: "\055",
 	14: "\050", 15: "\051", 16: "\072", 17: "\052", 18: "\136",
 	25: "\133", 26: "\173", 28: "\124\117", 29: "\135", 30: "\175",
 }
@@ -57,28 +63,48 @@ func newTokenManager(stream CharStream) *TokenManager {
 func (tm *TokenManager) jjMoveStringLiteralDfa0_2() int {
 	switch tm.curChar {
 	case 40:
-		panic("not implemented yet")
+		return tm.jjStopAtPos(0, 14)
 	case 41:
-		panic("not implemented yet")
+		return tm.jjStopAtPos(0, 15)
 	case 42:
-		panic("not implemented yet")
+		return tm.jjStartNfaWithStates_2(0, 17, 49)
 	case 43:
-		panic("not implemented yet")
+		return tm.jjStartNfaWithStates_2(0, 11, 15)
 	case 45:
-		panic("not implemented yet")
+		return tm.jjStartNfaWithStates_2(0, 12, 15)
 	case 58:
-		panic("not implemented yet")
+		return tm.jjStopAtPos(0, 16)
 	case 91:
-		panic("not implemented yet")
+		return tm.jjStopAtPos(0, 25)
 	case 94:
-		panic("not implemented yet")
+		return tm.jjStopAtPos(0, 18)
 	case 123:
-		panic("not implemented yet")
+		return tm.jjStopAtPos(0, 26)
 	default:
 		return tm.jjMoveNfa_2(0, 0)
 	}
 }
 
+// L72
+
+func (tm *TokenManager) jjStopAtPos(pos, kind int) int {
+	tm.jjmatchedKind = kind
+	tm.jjmatchedPos = pos
+	return pos + 1
+}
+
+// L79
+
+func (tm *TokenManager) jjStartNfaWithStates_2(pos, kind, state int) int {
+	tm.jjmatchedKind = kind
+	tm.jjmatchedPos = pos
+	var err error
+	if tm.curChar, err = tm.input_stream.readChar(); err != nil {
+		return pos + 1
+	}
+	return tm.jjMoveNfa_2(state, pos+1)
+}
+
 // L87
 
 func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
@@ -114,11 +140,12 @@ func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
 							kind = 7
 						}
 					} else if (0x280200000000 & l) != 0 {
-						panic("not implemented yet")
+						tm.jjstateSet[tm.jjnewStateCnt] = 15
+						tm.jjnewStateCnt++
 					} else if tm.curChar == 47 {
-						panic("not implemented yet")
+						tm.jjCheckNAddStates(0, 2)
 					} else if tm.curChar == 34 {
-						panic("not implemented yet")
+						tm.jjCheckNAddStates(3, 5)
 					}
 					if (0x7bff50f8ffffd9ff & l) != 0 {
 						if kind > 20 {
@@ -126,62 +153,118 @@ func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
 						}
 						tm.jjCheckNAddStates(6, 10)
 					} else if tm.curChar == 42 {
-						panic("not implemented yet")
+						if kind > 22 {
+							kind = 22
+						}
 					} else if tm.curChar == 33 {
-						panic("not implemented yet")
+						if kind > 10 {
+							kind = 10
+						}
 					}
 					if tm.curChar == 38 {
-						panic("not implemented yet")
+						tm.jjstateSet[tm.jjnewStateCnt] = 4
+						tm.jjnewStateCnt++
 					}
 
 				case 4:
-					panic("not implemented yet")
+					if tm.curChar == 38 && kind > 8 {
+						kind = 8
+					}
 				case 5:
-					panic("not implemented yet")
+					if tm.curChar == 38 {
+						tm.jjstateSet[tm.jjnewStateCnt] = 4
+						tm.jjnewStateCnt++
+					}
 				case 13:
-					panic("not implemented yet")
+					if tm.curChar == 33 && kind > 10 {
+						kind = 10
+					}
 				case 14:
-					panic("not implemented yet")
+					if (0x280200000000 & l) != 0 {
+						tm.jjstateSet[tm.jjnewStateCnt] = 15
+						tm.jjnewStateCnt++
+					}
 				case 15:
-					panic("not implemented yet")
+					if (0x100002600&l) != 0 && kind > 13 {
+						kind = 13
+					}
 				case 16:
-					panic("not implemented yet")
+					if tm.curChar == 34 {
+						tm.jjCheckNAddStates(3, 5)
+					}
 				case 17:
-					panic("not implemented yet")
+					if (0xfffffffbffffffff & uint64(l)) != 0 {
+						tm.jjCheckNAddStates(3, 5)
+					}
 				case 19:
-					panic("not implemented yet")
+					tm.jjCheckNAddStates(3, 5)
 				case 20:
-					panic("not implemented yet")
+					if tm.curChar == 34 && kind > 19 {
+						kind = 19
+					}
 				case 22:
-					panic("not implemented yet")
+					if (0x3ff000000000000 & l) != 0 {
+						if kind > 21 {
+							kind = 21
+						}
+						tm.jjCheckNAddStates(11, 14)
+					}
 				case 23:
-					panic("not implemented yet")
+					if tm.curChar == 46 {
+						tm.jjCheckNAdd(24)
+					}
 				case 24:
-					panic("not implemented yet")
+					if (0x3ff000000000000 & l) != 0 {
+						if kind > 21 {
+							kind = 21
+						}
+						tm.jjCheckNAddStates(15, 17)
+					}
 				case 25:
-					panic("not implemented yet")
+					if (0x7bff78f8ffffd9ff & l) != 0 {
+						if kind > 21 {
+							kind = 21
+						}
+						tm.jjCheckNAddTwoStates(25, 26)
+					}
 				case 27:
-					panic("not implemented yet")
+					if kind > 21 {
+						kind = 21
+					}
+					tm.jjCheckNAddTwoStates(25, 26)
 				case 28:
-					panic("not implemented yet")
+					if (0x7bff78f8ffffd9ff & l) != 0 {
+						if kind > 21 {
+							kind = 21
+						}
+						tm.jjCheckNAddTwoStates(28, 29)
+					}
 				case 30:
-					panic("not implemented yet")
+					if kind > 21 {
+						kind = 21
+					}
+					tm.jjCheckNAddTwoStates(28, 29)
 				case 31:
 					if tm.curChar == 42 && kind > 22 {
 						kind = 22
 					}
-				case 32:
-					panic("not implemented yet")
 				case 35:
-					panic("not implemented yet")
+					if kind > 23 {
+						kind = 23
+					}
+					tm.jjCheckNAddTwoStates(33, 34)
 				case 36, 38:
-					panic("not implemented yet")
+					if tm.curChar == 47 {
+						tm.jjCheckNAddStates(0, 2)
+					}
 				case 37:
-					panic("not implemented yet")
+					if (0xffff7fffffffffff & uint64(l)) != 0 {
+						tm.jjCheckNAddStates(0, 2)
+					}
 				case 40:
-					panic("not implemented yet")
-				case 41:
-					panic("not implemented yet")
+					if tm.curChar == 47 && kind > 24 {
+						kind = 24
+					}
 				case 42:
 					if (0x7bff78f8ffffd9ff & l) != 0 {
 						if kind > 20 {
@@ -190,13 +273,16 @@ func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
 						tm.jjCheckNAddTwoStates(42, 43)
 					}
 				case 44:
-					panic("not implemented yet")
+					if kind > 20 {
+						kind = 20
+					}
+					tm.jjCheckNAddTwoStates(42, 43)
 				case 45:
 					if (0x7bff78f8ffffd9ff & l) != 0 {
 						tm.jjCheckNAddStates(18, 20)
 					}
 				case 47:
-					panic("not implemented yet")
+					tm.jjCheckNAddStates(18, 20)
 				}
 				if i == startsAt {
 					break
@@ -208,7 +294,14 @@ func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
 				i--
 				switch tm.jjstateSet[i] {
 				case 49:
-					panic("niy")
+					if (0x97ffffff87ffffff & uint64(l)) != 0 {
+						if kind > 23 {
+							kind = 23
+						}
+						tm.jjCheckNAddTwoStates(33, 34)
+					} else if tm.curChar == 92 {
+						tm.jjCheckNAddTwoStates(35, 35)
+					}
 				case 0:
 					if (0x97ffffff87ffffff & uint64(l)) != 0 {
 						if kind > 20 {
@@ -216,9 +309,12 @@ func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
 						}
 						tm.jjCheckNAddStates(6, 10)
 					} else if tm.curChar == 92 {
-						panic("niy")
+						tm.jjCheckNAddStates(21, 23)
 					} else if tm.curChar == 126 {
-						panic("niy")
+						if kind > 21 {
+							kind = 21
+						}
+						tm.jjCheckNAddStates(24, 26)
 					}
 					if (0x97ffffff87ffffff & uint64(l)) != 0 {
 						if kind > 23 {
@@ -228,56 +324,107 @@ func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
 					}
 					switch tm.curChar {
 					case 78:
-						panic("niy")
+						tm.jjstateSet[tm.jjnewStateCnt] = 11
+						tm.jjnewStateCnt++
 					case 124:
-						panic("niy")
+						tm.jjstateSet[tm.jjnewStateCnt] = 8
+						tm.jjnewStateCnt++
 					case 79:
-						panic("niy")
+						tm.jjstateSet[tm.jjnewStateCnt] = 6
+						tm.jjnewStateCnt++
 					case 65:
-						panic("niy")
+						tm.jjstateSet[tm.jjnewStateCnt] = 2
+						tm.jjnewStateCnt++
 					}
 				case 1:
-					panic("niy")
+					if tm.curChar == 68 && kind > 8 {
+						kind = 8
+					}
 				case 2:
-					panic("niy")
+					if tm.curChar == 78 {
+						tm.jjstateSet[tm.jjnewStateCnt] = 1
+						tm.jjnewStateCnt++
+					}
 				case 3:
-					panic("niy")
+					if tm.curChar == 65 {
+						tm.jjstateSet[tm.jjnewStateCnt] = 2
+						tm.jjnewStateCnt++
+					}
 				case 6:
-					panic("niy")
+					if tm.curChar == 82 && kind > 9 {
+						kind = 9
+					}
 				case 7:
-					panic("niy")
+					if tm.curChar == 79 {
+						tm.jjstateSet[tm.jjnewStateCnt] = 6
+						tm.jjnewStateCnt++
+					}
 				case 8:
-					panic("niy")
+					if tm.curChar == 124 && kind > 9 {
+						kind = 9
+					}
 				case 9:
-					panic("niy")
+					if tm.curChar == 124 {
+						tm.jjstateSet[tm.jjnewStateCnt] = 8
+						tm.jjnewStateCnt++
+					}
 				case 10:
-					panic("niy")
+					if tm.curChar == 84 && kind > 10 {
+						kind = 10
+					}
 				case 11:
-					panic("niy")
+					if tm.curChar == 79 {
+						tm.jjstateSet[tm.jjnewStateCnt] = 10
+						tm.jjnewStateCnt++
+					}
 				case 12:
-					panic("niy")
+					if tm.curChar == 78 {
+						tm.jjstateSet[tm.jjnewStateCnt] = 11
+						tm.jjnewStateCnt++
+					}
 				case 17:
-					panic("niy")
+					if (0xffffffffefffffff & uint64(l)) != 0 {
+						tm.jjCheckNAddStates(3, 5)
+					}
 				case 18:
-					panic("niy")
+					if tm.curChar == 92 {
+						tm.jjstateSet[tm.jjnewStateCnt] = 19
+						tm.jjnewStateCnt++
+					}
 				case 19:
-					panic("niy")
-				case 21:
-					panic("niy")
+					tm.jjCheckNAddStates(3, 5)
 				case 25:
-					panic("niy")
+					if (0x97ffffff87ffffff & uint64(l)) != 0 {
+						if kind > 21 {
+							kind = 21
+						}
+						tm.jjCheckNAddTwoStates(25, 26)
+					}
 				case 26:
-					panic("niy")
+					if tm.curChar == 92 {
+						tm.jjCheckNAddTwoStates(27, 27)
+					}
 				case 27:
-					panic("niy")
+					if kind > 21 {
+						kind = 21
+					}
+					tm.jjCheckNAddTwoStates(25, 26)
 				case 28:
-					panic("niy")
+					if (0x97ffffff87ffffff & uint64(l)) != 0 {
+						if kind > 21 {
+							kind = 21
+						}
+						tm.jjCheckNAddTwoStates(28, 29)
+					}
 				case 29:
-					panic("niy")
+					if tm.curChar == 92 {
+						tm.jjCheckNAddTwoStates(30, 30)
+					}
 				case 30:
-					panic("niy")
-				case 32:
-					panic("niy")
+					if kind > 21 {
+						kind = 21
+					}
+					tm.jjCheckNAddTwoStates(28, 29)
 				case 33:
 					if (0x97ffffff87ffffff & uint64(l)) != 0 {
 						if kind > 23 {
@@ -290,13 +437,17 @@ func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
 						tm.jjCheckNAddTwoStates(35, 35)
 					}
 				case 35:
-					panic("niy")
+					if kind > 23 {
+						kind = 23
+					}
+					tm.jjCheckNAddTwoStates(33, 34)
 				case 37:
-					panic("niy")
+					tm.jjCheckNAddStates(0, 2)
 				case 39:
-					panic("niy")
-				case 41:
-					panic("niy")
+					if tm.curChar == 92 {
+						tm.jjstateSet[tm.jjnewStateCnt] = 38
+						tm.jjnewStateCnt++
+					}
 				case 42:
 					if (0x97ffffff87ffffff & uint64(l)) != 0 {
 						if kind > 20 {
@@ -309,7 +460,10 @@ func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
 						tm.jjCheckNAddTwoStates(44, 44)
 					}
 				case 44:
-					panic("niy")
+					if kind > 20 {
+						kind = 20
+					}
+					tm.jjCheckNAddTwoStates(42, 43)
 				case 45:
 					if (0x97ffffff87ffffff & uint64(l)) != 0 {
 						tm.jjCheckNAddStates(18, 20)
@@ -319,9 +473,7 @@ func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
 						tm.jjCheckNAddTwoStates(47, 47)
 					}
 				case 47:
-					panic("niy")
-				case 48:
-					panic("niy")
+					tm.jjCheckNAddStates(18, 20)
 				}
 				if i <= startsAt { // ==?
 					break
@@ -362,25 +514,52 @@ func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
 						tm.jjCheckNAddStates(6, 10)
 					}
 				case 15:
-					panic("not implemented yet")
+					if jjCanMove_0(hiByte, i1, i2, l1, l2) && kind > 13 {
+						kind = 13
+					}
 				case 17, 19:
-					panic("not implemented yet")
+					if jjCanMove_1(hiByte, i1, i2, l1, l2) {
+						tm.jjCheckNAddStates(3, 5)
+					}
 				case 25:
-					panic("not implemented yet")
+					if jjCanMove_2(hiByte, i1, i2, l1, l2) {
+						if kind > 21 {
+							kind = 21
+						}
+						tm.jjCheckNAddTwoStates(25, 26)
+					}
 				case 27:
-					panic("not implemented yet")
+					if jjCanMove_1(hiByte, i1, i2, l1, l2) {
+						if kind > 21 {
+							kind = 21
+						}
+						tm.jjCheckNAddTwoStates(25, 26)
+					}
 				case 28:
-					panic("not implemented yet")
+					if jjCanMove_2(hiByte, i1, i2, l1, l2) {
+						if kind > 21 {
+							kind = 21
+						}
+						tm.jjCheckNAddTwoStates(28, 29)
+					}
 				case 30:
-					panic("not implemented yet")
-				case 32:
-					panic("not implemented yet")
+					if jjCanMove_1(hiByte, i1, i2, l1, l2) {
+						if kind > 21 {
+							kind = 21
+						}
+						tm.jjCheckNAddTwoStates(28, 29)
+					}
 				case 35:
-					panic("not implemented yet")
+					if jjCanMove_1(hiByte, i1, i2, l1, l2) {
+						if kind > 23 {
+							kind = 23
+						}
+						tm.jjCheckNAddTwoStates(33, 34)
+					}
 				case 37:
-					panic("not implemented yet")
-				case 41:
-					panic("not implemented yet")
+					if jjCanMove_1(hiByte, i1, i2, l1, l2) {
+						tm.jjCheckNAddStates(0, 2)
+					}
 				case 42:
 					if jjCanMove_2(hiByte, i1, i2, l1, l2) {
 						if kind > 20 {
@@ -389,13 +568,20 @@ func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
 						tm.jjCheckNAddTwoStates(42, 43)
 					}
 				case 44:
-					panic("not implemented yet")
+					if jjCanMove_1(hiByte, i1, i2, l1, l2) {
+						if kind > 20 {
+							kind = 20
+						}
+						tm.jjCheckNAddTwoStates(42, 43)
+					}
 				case 45:
 					if jjCanMove_2(hiByte, i1, i2, l1, l2) {
 						tm.jjCheckNAddStates(18, 20)
 					}
 				case 47:
-					panic("not implemented yet")
+					if jjCanMove_1(hiByte, i1, i2, l1, l2) {
+						tm.jjCheckNAddStates(18, 20)
+					}
 				}
 				if i == startsAt {
 					break
@@ -419,23 +605,296 @@ func (tm *TokenManager) jjMoveNfa_2(startState, curPos int) int {
 			return curPos
 		}
 	}
-	panic("should not be here")
+}
+
+// L509
+
+func (tm *TokenManager) jjMoveStringLiteralDfa0_0() int {
+	return tm.jjMoveNfa_0(0, 0)
+}
+
+func (tm *TokenManager) jjMoveNfa_0(startState, curPos int) int {
+	startsAt := 0
+	tm.jjnewStateCnt = 3
+	i := 1
+	tm.jjstateSet[0] = startState
+	kind := 0x7fffffff
+	for {
+		if tm.jjround++; tm.jjround == 0x7fffffff {
+			tm.reInitRounds()
+		}
+		if tm.curChar < 64 {
+			l := int64(1) << uint(tm.curChar)
+			for {
+				i--
+				switch tm.jjstateSet[i] {
+				case 0:
+					if (0x3ff000000000000 & l) != 0 {
+						if kind > 27 {
+							kind = 27
+						}
+						tm.jjAddStates(31, 32)
+					}
+				case 1:
+					if tm.curChar == 46 {
+						tm.jjCheckNAdd(2)
+					}
+				case 2:
+					if (0x3ff000000000000 & l) != 0 {
+						if kind > 27 {
+							kind = 27
+						}
+						tm.jjCheckNAdd(2)
+					}
+				}
+				if i == startsAt {
+					break
+				}
+			}
+		} else {
+			// no state of the boost number accepts other characters
+			i = startsAt
+		}
+		if kind != 0x7fffffff {
+			tm.jjmatchedKind = kind
+			tm.jjmatchedPos = curPos
+			kind = 0x7fffffff
+		}
+		curPos++
+		i = tm.jjnewStateCnt
+		tm.jjnewStateCnt = startsAt
+		startsAt = 3 - tm.jjnewStateCnt
+		if i == startsAt {
+			return curPos
+		}
+		var err error
+		if tm.curChar, err = tm.input_stream.readChar(); err != nil {
+			return curPos
+		}
+	}
+}
+
+func (tm *TokenManager) jjStopStringLiteralDfa_1(pos int, active0 int64) int {
+	switch pos {
+	case 0:
+		if (active0 & 0x10000000) != 0 {
+			tm.jjmatchedKind = 32
+			return 6
+		}
+		return -1
+	default:
+		return -1
+	}
+}
+
+func (tm *TokenManager) jjStartNfa_1(pos int, active0 int64) int {
+	return tm.jjMoveNfa_1(tm.jjStopStringLiteralDfa_1(pos, active0), pos+1)
+}
+
+func (tm *TokenManager) jjMoveStringLiteralDfa0_1() int {
+	switch tm.curChar {
+	case 84:
+		return tm.jjMoveStringLiteralDfa1_1(0x10000000)
+	case 93:
+		return tm.jjStopAtPos(0, 29)
+	case 125:
+		return tm.jjStopAtPos(0, 30)
+	default:
+		return tm.jjMoveNfa_1(0, 0)
+	}
+}
+
+func (tm *TokenManager) jjMoveStringLiteralDfa1_1(active0 int64) int {
+	var err error
+	if tm.curChar, err = tm.input_stream.readChar(); err != nil {
+		tm.jjStopStringLiteralDfa_1(0, active0)
+		return 1
+	}
+	switch tm.curChar {
+	case 79:
+		if (active0 & 0x10000000) != 0 {
+			return tm.jjStartNfaWithStates_1(1, 28, 6)
+		}
+	}
+	return tm.jjStartNfa_1(0, active0)
+}
+
+func (tm *TokenManager) jjStartNfaWithStates_1(pos, kind, state int) int {
+	tm.jjmatchedKind = kind
+	tm.jjmatchedPos = pos
+	var err error
+	if tm.curChar, err = tm.input_stream.readChar(); err != nil {
+		return pos + 1
+	}
+	return tm.jjMoveNfa_1(state, pos+1)
+}
+
+func (tm *TokenManager) jjMoveNfa_1(startState, curPos int) int {
+	startsAt := 0
+	tm.jjnewStateCnt = 7
+	i := 1
+	tm.jjstateSet[0] = startState
+	kind := 0x7fffffff
+	for {
+		if tm.jjround++; tm.jjround == 0x7fffffff {
+			tm.reInitRounds()
+		}
+		if tm.curChar < 64 {
+			l := int64(1) << uint(tm.curChar)
+			for {
+				i--
+				switch tm.jjstateSet[i] {
+				case 0:
+					if (0xfffffffeffffffff & uint64(l)) != 0 {
+						if kind > 32 {
+							kind = 32
+						}
+						tm.jjCheckNAdd(6)
+					}
+					if (0x100002600 & l) != 0 {
+						if kind > 7 {
+							kind = 7
+						}
+					} else if tm.curChar == 34 {
+						tm.jjCheckNAddTwoStates(2, 4)
+					}
+				case 1:
+					if tm.curChar == 34 {
+						tm.jjCheckNAddTwoStates(2, 4)
+					}
+				case 2:
+					if (0xfffffffbffffffff & uint64(l)) != 0 {
+						tm.jjCheckNAddStates(33, 35)
+					}
+				case 3:
+					if tm.curChar == 34 {
+						tm.jjCheckNAddStates(33, 35)
+					}
+				case 5:
+					if tm.curChar == 34 && kind > 31 {
+						kind = 31
+					}
+				case 6:
+					if (0xfffffffeffffffff & uint64(l)) != 0 {
+						if kind > 32 {
+							kind = 32
+						}
+						tm.jjCheckNAdd(6)
+					}
+				}
+				if i == startsAt {
+					break
+				}
+			}
+		} else if tm.curChar < 128 {
+			l := int64(1) << (uint(tm.curChar) & 077)
+			for {
+				i--
+				switch tm.jjstateSet[i] {
+				case 0, 6:
+					if (0xdfffffffdfffffff & uint64(l)) != 0 {
+						if kind > 32 {
+							kind = 32
+						}
+						tm.jjCheckNAdd(6)
+					}
+				case 2:
+					tm.jjAddStates(33, 35)
+				case 4:
+					if tm.curChar == 92 {
+						tm.jjstateSet[tm.jjnewStateCnt] = 3
+						tm.jjnewStateCnt++
+					}
+				}
+				if i == startsAt {
+					break
+				}
+			}
+		} else {
+			hiByte := int(tm.curChar >> 8)
+			i1 := hiByte >> 6
+			l1 := int64(1 << (uint64(hiByte) & 077))
+			i2 := int((tm.curChar & 0xff) >> 6)
+			l2 := int64(1 << uint64(tm.curChar&077))
+			for {
+				i--
+				switch tm.jjstateSet[i] {
+				case 0:
+					if jjCanMove_0(hiByte, i1, i2, l1, l2) {
+						if kind > 7 {
+							kind = 7
+						}
+					}
+					if jjCanMove_1(hiByte, i1, i2, l1, l2) {
+						if kind > 32 {
+							kind = 32
+						}
+						tm.jjCheckNAdd(6)
+					}
+				case 2:
+					if jjCanMove_1(hiByte, i1, i2, l1, l2) {
+						tm.jjAddStates(33, 35)
+					}
+				case 6:
+					if jjCanMove_1(hiByte, i1, i2, l1, l2) {
+						if kind > 32 {
+							kind = 32
+						}
+						tm.jjCheckNAdd(6)
+					}
+				}
+				if i == startsAt {
+					break
+				}
+			}
+		}
+		if kind != 0x7fffffff {
+			tm.jjmatchedKind = kind
+			tm.jjmatchedPos = curPos
+			kind = 0x7fffffff
+		}
+		curPos++
+		i = tm.jjnewStateCnt
+		tm.jjnewStateCnt = startsAt
+		startsAt = 7 - tm.jjnewStateCnt
+		if i == startsAt {
+			return curPos
+		}
+		var err error
+		if tm.curChar, err = tm.input_stream.readChar(); err != nil {
+			return curPos
+		}
+	}
 }
 
 func jjCanMove_0(hiByte, i1, i2 int, l1, l2 int64) bool {
 	switch hiByte {
 	case 48:
-		return (jjbitVec0[i2] & 12) != 0
+		return (jjbitVec0[i2] & l2) != 0
 	}
 	return false
 }
 
+func jjCanMove_1(hiByte, i1, i2 int, l1, l2 int64) bool {
+	switch hiByte {
+	case 0:
+		return (jjbitVec3[i2] & uint64(l2)) != 0
+	}
+	if i1 >= len(jjbitVec1) {
+		return true // beyond the BMP
+	}
+	return (jjbitVec1[i1] & uint64(l1)) != 0
+}
+
 func jjCanMove_2(hiByte, i1, i2 int, l1, l2 int64) bool {
 	switch hiByte {
 	case 0:
-		panic("not implemented yet")
+		return (jjbitVec3[i2] & uint64(l2)) != 0
 	case 48:
-		panic("not implemented yet")
+		return (jjbitVec1[i2] & uint64(l2)) != 0
+	}
+	if i1 >= len(jjbitVec4) {
+		return true // beyond the BMP
 	}
 	return (jjbitVec4[i1] & uint64(l1)) != 0
 }
@@ -480,8 +939,7 @@ func (tm *TokenManager) jjFillToken() *Token {
 func (tm *TokenManager) nextToken() (matchedToken *Token) {
 	curPos := 0
 	var err error
-	var eof = false
-	for !eof {
+	for {
 		if tm.curChar, err = tm.input_stream.beginToken(); err != nil {
 			tm.jjmatchedKind = 0
 			matchedToken = tm.jjFillToken()
@@ -490,9 +948,13 @@ func (tm *TokenManager) nextToken() (matchedToken *Token) {
 
 		switch tm.curLexState {
 		case 0:
-			panic("not implemented yet")
+			tm.jjmatchedKind = 0x7fffffff
+			tm.jjmatchedPos = 0
+			curPos = tm.jjMoveStringLiteralDfa0_0()
 		case 1:
-			panic("not implemented yet")
+			tm.jjmatchedKind = 0x7fffffff
+			tm.jjmatchedPos = 0
+			curPos = tm.jjMoveStringLiteralDfa0_1()
 		case 2:
 			tm.jjmatchedKind = 0x7fffffff
 			tm.jjmatchedPos = 0
@@ -505,14 +967,13 @@ func (tm *TokenManager) nextToken() (matchedToken *Token) {
 			}
 			if (jjtoToken[tm.jjmatchedKind>>6] & (int64(1) << uint(tm.jjmatchedKind&077))) != 0 {
 				matchedToken = tm.jjFillToken()
-				if jjnewLexState[tm.jjmatchedKind] != -1 {
-					panic("not implemented yet")
+				if n := jjnewLexState[tm.jjmatchedKind]; n != -1 {
+					tm.curLexState = n
 				}
 				return matchedToken
 			} else {
 				if n := jjnewLexState[tm.jjmatchedKind]; n != -1 {
-					panic("niy")
-					// tm.curLexState = n
+					tm.curLexState = n
 				}
 				continue
 			}
@@ -541,7 +1002,6 @@ func (tm *TokenManager) nextToken() (matchedToken *Token) {
 		panic(newTokenMgrError(eofSeen, tm.curLexState, error_line,
 			error_column, error_after, tm.curChar, LEXICAL_ERROR))
 	}
-	panic("should not be here")
 }
 
 // L1137
@@ -560,16 +1020,27 @@ func (tm *TokenManager) jjCheckNAddTwoStates(state1, state2 int) {
 	tm.jjCheckNAdd(state2)
 }
 
+func (tm *TokenManager) jjAddStates(start, end int) {
+	for {
+		tm.jjstateSet[tm.jjnewStateCnt] = jjnextStates[start]
+		tm.jjnewStateCnt++
+		if start == end {
+			break
+		}
+		start++
+	}
+}
+
 func (tm *TokenManager) jjCheckNAddStates(start, end int) {
-	assert(start < end)
+	assert(start <= end)
 	assert(start >= 0)
-	assert(end <= len(jjnextStates))
+	assert(end < len(jjnextStates))
 	for {
 		tm.jjCheckNAdd(jjnextStates[start])
-		start++
-		if start >= end {
+		if start == end {
 			break
 		}
+		start++
 	}
 }
 
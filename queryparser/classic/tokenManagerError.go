@@ -1,5 +1,9 @@
 package classic
 
+import (
+	"fmt"
+)
+
 const (
 	LEXICAL_ERROR = iota
 	STATIC_LEXER_ERROR
@@ -7,14 +11,34 @@ const (
 	LOOP_DETECTED
 )
 
+// Raised by the token manager when the query has a lexical error.
 type TokenManagerError struct {
+	msg       string
+	errorCode int
 }
 
 func newTokenMgrError(eofSeen bool, lexState, errorLine, errorColumn int,
 	errorAfter string, curChar rune, reason int) *TokenManagerError {
-	panic("not implemented yet")
+	return &TokenManagerError{
+		lexicalError(eofSeen, lexState, errorLine, errorColumn, errorAfter, curChar),
+		reason,
+	}
+}
+
+/*
+Returns a detailed message for the error when it is thrown by the
+token manager to indicate a lexical error.
+*/
+func lexicalError(eofSeen bool, lexState, errorLine, errorColumn int,
+	errorAfter string, curChar rune) string {
+	encountered := "<EOF> "
+	if !eofSeen {
+		encountered = fmt.Sprintf("%q (%v), ", curChar, int(curChar))
+	}
+	return fmt.Sprintf("Lexical error at line %v, column %v.  Encountered: %vafter : %q",
+		errorLine, errorColumn, encountered, errorAfter)
 }
 
 func (err *TokenManagerError) Error() string {
-	panic("not implemented yet")
+	return err.msg
 }
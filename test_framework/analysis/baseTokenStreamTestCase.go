@@ -0,0 +1,69 @@
+package analysis
+
+import (
+	. "github.com/gzg1984/golucene/core/analysis"
+	. "github.com/gzg1984/golucene/core/analysis/tokenattributes"
+	. "github.com/gzg1984/gounit"
+	"testing"
+)
+
+// analysis/BaseTokenStreamTestCase.java
+
+/*
+Asserts the terms of the tokens of ts, and their start offsets, end
+offsets, types and position increments when the corresponding slices
+are not nil. The stream is reset, consumed, ended and closed.
+*/
+func AssertTokenStreamContents(t *testing.T, ts TokenStream, output []string,
+	startOffsets, endOffsets []int, types []string, posIncrements []int) {
+
+	termAtt := ts.Attributes().Add("CharTermAttribute").(CharTermAttribute)
+	offsetAtt := ts.Attributes().Add("OffsetAttribute").(OffsetAttribute)
+	typeAtt := ts.Attributes().Add("TypeAttribute").(TypeAttribute)
+	posIncAtt := ts.Attributes().Add("PositionIncrementAttribute").(PositionIncrementAttribute)
+
+	err := ts.Reset()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	var terms []string
+	for i := 0; ; i++ {
+		ok, err := ts.IncrementToken()
+		It(t).Should("has no error: %v", err).Assert(err == nil)
+		if !ok {
+			break
+		}
+		term := string(termAtt.Buffer()[:termAtt.Length()])
+		terms = append(terms, term)
+		if i >= len(output) {
+			continue
+		}
+		It(t).Should("term %v: expect %q, got %q", i, output[i], term).Verify(output[i] == term)
+		if startOffsets != nil {
+			It(t).Should("startOffset %v of %q: expect %v, got %v", i, term, startOffsets[i], offsetAtt.StartOffset()).
+				Verify(startOffsets[i] == offsetAtt.StartOffset())
+		}
+		if endOffsets != nil {
+			It(t).Should("endOffset %v of %q: expect %v, got %v", i, term, endOffsets[i], offsetAtt.EndOffset()).
+				Verify(endOffsets[i] == offsetAtt.EndOffset())
+		}
+		if types != nil {
+			It(t).Should("type %v of %q: expect %v, got %v", i, term, types[i], typeAtt.Type()).
+				Verify(types[i] == typeAtt.Type())
+		}
+		if posIncrements != nil {
+			It(t).Should("posIncrement %v of %q: expect %v, got %v", i, term, posIncrements[i], posIncAtt.PositionIncrement()).
+				Verify(posIncrements[i] == posIncAtt.PositionIncrement())
+		}
+	}
+	It(t).Should("expect %q, got %q", output, terms).Verify(len(output) == len(terms))
+	err = ts.End()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	err = ts.Close()
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+}
+
+/* Asserts the terms of the tokens of the analysis of input by a. */
+func AssertAnalyzesTo(t *testing.T, a Analyzer, input string, output []string) {
+	ts, err := a.TokenStreamForString("dummy", input)
+	It(t).Should("has no error: %v", err).Assert(err == nil)
+	AssertTokenStreamContents(t, ts, output, nil, nil, nil, nil)
+}
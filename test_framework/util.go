@@ -15,8 +15,8 @@ import (
 func CheckIndex(dir store.Directory, crossCheckTermVectors bool) *index.CheckIndexStatus {
 	var buf bytes.Buffer
 	checker := index.NewCheckIndex(dir, crossCheckTermVectors, &buf)
-	indexStatus := checker.CheckIndex(nil)
-	if indexStatus == nil || !indexStatus.Clean {
+	indexStatus, err := checker.CheckIndex(nil)
+	if err != nil || indexStatus == nil || !indexStatus.Clean {
 		fmt.Println("CheckIndex failed")
 		fmt.Println(buf.String())
 		panic("CheckIndex failed")